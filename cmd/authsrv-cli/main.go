@@ -37,16 +37,56 @@ func run(logger *log.Logger) error {
 		cmd = command.NewCreateScopeCommand(args, logger)
 	case "createrole":
 		cmd = command.NewCreateRoleCommand(args, logger)
+	case "listscopes":
+		cmd = command.NewListScopesCommand(args, logger)
+	case "deletescope":
+		cmd = command.NewDeleteScopeCommand(args, logger)
+	case "listroles":
+		cmd = command.NewListRolesCommand(args, logger)
+	case "deleterole":
+		cmd = command.NewDeleteRoleCommand(args, logger)
 	case "assignscope":
 		cmd = command.NewAssignScopeCommand(args, logger)
 	case "unassignscope":
 		cmd = command.NewUnassignScopeCommand(args, logger)
+	case "inheritrole":
+		cmd = command.NewInheritRoleCommand(args, logger)
+	case "disinheritrole":
+		cmd = command.NewDisinheritRoleCommand(args, logger)
 	case "assignrole":
 		cmd = command.NewAssignRoleCommand(args, logger)
 	case "unassignrole":
 		cmd = command.NewUnassignRoleCommand(args, logger)
+	case "createorg":
+		cmd = command.NewCreateOrgCommand(args, logger)
+	case "listorgs":
+		cmd = command.NewListOrgsCommand(args, logger)
+	case "addmember":
+		cmd = command.NewAddMemberCommand(args, logger)
+	case "removemember":
+		cmd = command.NewRemoveMemberCommand(args, logger)
+	case "creategroup":
+		cmd = command.NewCreateGroupCommand(args, logger)
+	case "listgroups":
+		cmd = command.NewListGroupsCommand(args, logger)
+	case "addgroupmember":
+		cmd = command.NewAddGroupMemberCommand(args, logger)
+	case "removegroupmember":
+		cmd = command.NewRemoveGroupMemberCommand(args, logger)
+	case "assigngrouprole":
+		cmd = command.NewAssignGroupRoleCommand(args, logger)
+	case "unassigngrouprole":
+		cmd = command.NewUnassignGroupRoleCommand(args, logger)
+	case "unlockuser":
+		cmd = command.NewUnlockUserCommand(args, logger)
+	case "createclient":
+		cmd = command.NewCreateClientCommand(args, logger)
+	case "rotateclientsecret":
+		cmd = command.NewRotateClientSecretCommand(args, logger)
 	case "genkeys":
 		cmd = command.NewGenKeysCommand(args, logger)
+	case "rotatekeys":
+		cmd = command.NewRotateKeysCommand(args, logger)
 	default:
 		cmd = command.NewHelpCommand(logger)
 	}
@@ -1,25 +1,32 @@
 package main
 
 import (
+	"context"
 	"log"
+	"time"
 
 	"github.com/pkg/errors"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-redis/redis/v8"
-	"github.com/golang-jwt/jwt/v4"
 	"github.com/jmoiron/sqlx"
+	"github.com/umalmyha/authsrv/internal/business/scope"
+	"github.com/umalmyha/authsrv/internal/business/user"
 	valueobj "github.com/umalmyha/authsrv/internal/business/value-object"
 	"github.com/umalmyha/authsrv/internal/infra"
 	"github.com/umalmyha/authsrv/internal/infra/handler"
 	"github.com/umalmyha/authsrv/internal/infra/service"
 	redisdb "github.com/umalmyha/authsrv/pkg/database/redis"
+	"github.com/umalmyha/authsrv/pkg/mail"
 	"github.com/umalmyha/authsrv/pkg/web"
 	"github.com/umalmyha/authsrv/pkg/web/middleware"
 	"github.com/umalmyha/authsrv/pkg/web/server"
 	"go.uber.org/zap"
 )
 
+// mailDrainTimeout limits how long shutdown waits for queued mail
+const mailDrainTimeout = 30 * time.Second
+
 func main() {
 	logger, err := infra.NewZapProductionLogger("authentication server")
 	if err != nil {
@@ -62,7 +69,39 @@ func start(logger *zap.SugaredLogger) error {
 
 func startServer(db *sqlx.DB, rdb *redis.Client, logger *zap.SugaredLogger) error {
 	stdLoger := zap.NewStdLog(logger.Desugar())
-	handler, err := handlerV1(db, rdb, stdLoger)
+
+	sweepInterval, err := infra.RoleExpirySweepInterval()
+	if err != nil {
+		return errors.Wrap(err, "failed to build role expiry sweep interval")
+	}
+
+	// expired role assignments are swept while server is running
+	sweeperCtx, stopSweeper := context.WithCancel(context.Background())
+	defer stopSweeper()
+
+	roleExpiredFn := func(expired user.ExpiredRoleAssignmentDto) {
+		logger.Infow("role assignment expired", "username", expired.Username, "role", expired.RoleName, "validUntil", expired.ValidUntil)
+	}
+	go service.NewRoleExpirySweeper(db, sweepInterval, roleExpiredFn, stdLoger).Run(sweeperCtx)
+
+	// mail is sent after user data is already stored, so delivery must neither delay response nor fail it
+	mailer, err := infra.NewAsyncMailer(stdLoger)
+	if err != nil {
+		return errors.Wrap(err, "failed to build mailer")
+	}
+	defer drainMailer(mailer, stdLoger)
+
+	jwtCfg, keyringWatcher, err := infra.ReloadingJwtConfig(stdLoger)
+	if err != nil {
+		return errors.Wrap(err, "failed to build jwt config")
+	}
+
+	// rotated signing keys are published and activated while server is running
+	if keyringWatcher != nil {
+		go keyringWatcher.Run(sweeperCtx)
+	}
+
+	handler, err := handlerV1(db, rdb, jwtCfg, mailer, stdLoger)
 	if err != nil {
 		return errors.Wrap(err, "failed to build handler")
 	}
@@ -85,13 +124,18 @@ func startServer(db *sqlx.DB, rdb *redis.Client, logger *zap.SugaredLogger) erro
 	return nil
 }
 
-func handlerV1(db *sqlx.DB, rdb *redis.Client, logger *log.Logger) (*chi.Mux, error) {
-	r := chi.NewRouter()
+// drainMailer delivers mail queued before shutdown
+func drainMailer(mailer *mail.AsyncMailer, logger *log.Logger) {
+	ctx, cancel := context.WithTimeout(context.Background(), mailDrainTimeout)
+	defer cancel()
 
-	jwtCfg, err := infra.JwtConfig()
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to JWT config")
+	if err := mailer.Close(ctx); err != nil {
+		logger.Printf("failed to drain mail queue: %v", err)
 	}
+}
+
+func handlerV1(db *sqlx.DB, rdb *redis.Client, jwtCfg valueobj.JwtConfig, mailer mail.Mailer, logger *log.Logger) (*chi.Mux, error) {
+	r := chi.NewRouter()
 
 	rfrCfg, err := infra.RefreshTokenConfig()
 	if err != nil {
@@ -103,9 +147,56 @@ func handlerV1(db *sqlx.DB, rdb *redis.Client, logger *log.Logger) (*chi.Mux, er
 		return nil, errors.Wrap(err, "failed to build password config")
 	}
 
+	oauthCfg, err := infra.OAuthConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build oauth config")
+	}
+
+	denylistCfg, err := infra.DenylistConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build denylist config")
+	}
+
+	mfaCfg, err := infra.MfaConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build mfa config")
+	}
+
+	lockoutCfg, err := infra.LockoutConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build lockout config")
+	}
+
+	webauthnCfg, err := infra.WebauthnConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build webauthn config")
+	}
+
+	resetCfg, err := infra.PasswordResetConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build password reset config")
+	}
+
+	emailCfg, err := infra.EmailVerificationConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build email verification config")
+	}
+
+	authRateLimiter, err := infra.AuthRateLimiter(rdb)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build rate limiter")
+	}
+
+	clientRateLimiter, err := infra.ClientRateLimiter(rdb)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build client rate limiter")
+	}
+
 	// servcices and handlers
-	authService := service.NewAuthService(db, rdb, jwtCfg, rfrCfg, passCfg)
-	authHandler := handler.NewAuthHandler(authService, rfrCfg)
+	authService := service.NewAuthService(db, rdb, jwtCfg, rfrCfg, passCfg, mfaCfg, lockoutCfg, emailCfg)
+	emailService := service.NewEmailService(db, rdb, emailCfg, mailer)
+	authHandler := handler.NewAuthHandler(authService, emailService, rfrCfg)
+	emailHandler := handler.NewEmailHandler(emailService)
 
 	scopeService := service.NewScopeService(db)
 	scopeHandler := handler.NewScopeHandler(scopeService)
@@ -113,56 +204,205 @@ func handlerV1(db *sqlx.DB, rdb *redis.Client, logger *log.Logger) (*chi.Mux, er
 	roleService := service.NewRoleService(db)
 	roleHandler := handler.NewRoleHandler(roleService)
 
+	orgService := service.NewOrganizationService(db)
+	orgHandler := handler.NewOrganizationHandler(orgService)
+
+	groupService := service.NewGroupService(db)
+	groupHandler := handler.NewGroupHandler(groupService)
+
 	userService := service.NewUserService(db, rdb)
-	userHandler := handler.NewUserHandler(userService)
+	userHandler := handler.NewUserHandler(userService, emailService)
+
+	sessionService := service.NewSessionService(db, rdb)
+	sessionHandler := handler.NewSessionHandler(sessionService, rfrCfg)
+
+	oauthService := service.NewOAuthService(db, rdb, jwtCfg, rfrCfg, passCfg, mfaCfg, oauthCfg, lockoutCfg, emailCfg)
+	oauthHandler := handler.NewOAuthHandler(oauthService)
+
+	webauthnService := service.NewWebauthnService(db, rdb, jwtCfg, rfrCfg, webauthnCfg, emailCfg)
+	webauthnHandler := handler.NewWebauthnHandler(webauthnService, rfrCfg)
+
+	passwordService := service.NewPasswordService(db, rdb, passCfg, resetCfg, mailer)
+	passwordHandler := handler.NewPasswordHandler(passwordService, rfrCfg)
+
+	wellKnownHandler := handler.NewWellKnownHandler(jwtCfg)
 
 	// middleware
 	loggerMw := middleware.RequestLogger(logger)
 
 	jwtValidator := func(rawToken string) (middleware.AuthClaimsProvider, error) {
-		var claims valueobj.JwtClaims
-		parser := jwt.NewParser(jwt.WithValidMethods([]string{jwtCfg.Algorithm()}))
+		return valueobj.ParseJwt(rawToken, jwtCfg)
+	}
 
-		keyFunc := func(token *jwt.Token) (any, error) {
-			if token.Method.Alg() != jwtCfg.Algorithm() {
-				return nil, errors.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
-			return jwtCfg.PublicKey(), nil
-		}
+	var tokenRevokedFn middleware.TokenRevokedFn
+	if denylistCfg.Enabled() {
+		tokenRevokedFn = service.NewDenylistService(rdb, denylistCfg, jwtCfg).IsDenied
+	}
 
-		if _, err := parser.ParseWithClaims(rawToken, &claims, keyFunc); err != nil {
-			return nil, err
+	jwtAuthMw := middleware.JwtAuthenticationWithRevocation(jwtValidator, tokenRevokedFn)
+
+	// administration routes require built-in scopes, superuser is authorized without them; write scopes grant reading too
+	readUsersMw := middleware.Authorize(middleware.Permission{ScopeExpr: scope.UsersRead + " || " + scope.UsersManage})
+	manageUsersMw := middleware.Authorize(middleware.Permission{Scopes: []string{scope.UsersManage}})
+	manageSessionsMw := middleware.Authorize(middleware.Permission{Scopes: []string{scope.SessionsManage}})
+	readRolesMw := middleware.Authorize(middleware.Permission{ScopeExpr: scope.RolesRead + " || " + scope.RolesWrite})
+	writeRolesMw := middleware.Authorize(middleware.Permission{Scopes: []string{scope.RolesWrite}})
+	readScopesMw := middleware.Authorize(middleware.Permission{ScopeExpr: scope.ScopesRead + " || " + scope.ScopesWrite})
+	writeScopesMw := middleware.Authorize(middleware.Permission{Scopes: []string{scope.ScopesWrite}})
+	readOrgsMw := middleware.Authorize(middleware.Permission{ScopeExpr: scope.OrganizationsRead + " || " + scope.OrganizationsWrite})
+	writeOrgsMw := middleware.Authorize(middleware.Permission{Scopes: []string{scope.OrganizationsWrite}})
+	readOrgMw := middleware.Authorize(middleware.Permission{ScopeExpr: scope.OrganizationsRead + " || " + scope.OrganizationsWrite, OrganizationParam: "org"})
+	writeOrgMw := middleware.Authorize(middleware.Permission{Scopes: []string{scope.OrganizationsWrite}, OrganizationParam: "org"})
+	readOrgUsersMw := middleware.Authorize(middleware.Permission{ScopeExpr: scope.UsersRead + " || " + scope.UsersManage, OrganizationScoped: true})
+	readGroupsMw := middleware.Authorize(middleware.Permission{ScopeExpr: scope.GroupsRead + " || " + scope.GroupsWrite})
+	writeGroupsMw := middleware.Authorize(middleware.Permission{Scopes: []string{scope.GroupsWrite}})
+
+	// credentials are accepted by these routes, so they are limited per client address,
+	// every route group has own budget, so e.g. password resets don't lock users out of sign in
+	authRateLimitMw := func(route string) middleware.MiddlewareFn {
+		if authRateLimiter == nil {
+			return nil
 		}
+		return middleware.RateLimit(authRateLimiter, middleware.RateLimitPerRoute(route, middleware.RateLimitByIp))
+	}
 
-		return claims, nil
+	// current password is verified on password and email change, so guessing it with stolen access token is limited per user
+	var userRateLimitMw middleware.MiddlewareFn
+	if authRateLimiter != nil {
+		userRateLimitMw = middleware.RateLimit(authRateLimiter, middleware.RateLimitPerRoute("password", middleware.RateLimitByUsername))
 	}
 
-	jwtAuthMw := middleware.JwtAuthentication(jwtValidator)
+	// services request tokens with client credentials, so token requests are limited per client
+	var clientRateLimitMw middleware.MiddlewareFn
+	if clientRateLimiter != nil {
+		clientRateLimitMw = middleware.RateLimit(clientRateLimiter, middleware.RateLimitPerRoute("token", handler.RateLimitByClientId))
+	}
 
 	r.Route("/api", func(r chi.Router) {
 		r.Route("/auth", func(r chi.Router) {
-			r.Post("/signup", web.HttpHandlerFunc(middleware.Wrap(authHandler.Signup, middleware.RequestId, loggerMw)))
-			r.Post("/signin", web.HttpHandlerFunc(middleware.Wrap(authHandler.Signin, middleware.RequestId, loggerMw)))
+			r.Post("/signup", web.HttpHandlerFunc(middleware.Wrap(authHandler.Signup, middleware.RequestId, loggerMw, authRateLimitMw("signup"))))
+			r.Post("/signin", web.HttpHandlerFunc(middleware.Wrap(authHandler.Signin, middleware.RequestId, loggerMw, authRateLimitMw("signin"))))
 			r.Post("/logout", web.HttpHandlerFunc(middleware.Wrap(authHandler.Logout, middleware.RequestId, loggerMw, jwtAuthMw)))
 			r.Post("/refresh", web.HttpHandlerFunc(middleware.Wrap(authHandler.RefreshSession, middleware.RequestId, loggerMw)))
+
+			r.Route("/email", func(r chi.Router) {
+				r.Post("/verify", web.HttpHandlerFunc(middleware.Wrap(emailHandler.VerifyEmail, middleware.RequestId, loggerMw, authRateLimitMw("email"))))
+				r.Post("/resend", web.HttpHandlerFunc(middleware.Wrap(emailHandler.ResendVerification, middleware.RequestId, loggerMw, authRateLimitMw("email"))))
+			})
+
+			r.Route("/password", func(r chi.Router) {
+				r.Post("/forgot", web.HttpHandlerFunc(middleware.Wrap(passwordHandler.ForgotPassword, middleware.RequestId, loggerMw, authRateLimitMw("password-reset"))))
+				r.Post("/reset", web.HttpHandlerFunc(middleware.Wrap(passwordHandler.ResetPassword, middleware.RequestId, loggerMw, authRateLimitMw("password-reset"))))
+			})
+
+			r.Route("/mfa", func(r chi.Router) {
+				r.Post("/verify", web.HttpHandlerFunc(middleware.Wrap(authHandler.VerifyMfa, middleware.RequestId, loggerMw, authRateLimitMw("mfa"))))
+				r.Post("/totp", web.HttpHandlerFunc(middleware.Wrap(authHandler.EnrollTotp, middleware.RequestId, loggerMw, jwtAuthMw)))
+				r.Post("/totp/confirm", web.HttpHandlerFunc(middleware.Wrap(authHandler.ConfirmTotp, middleware.RequestId, loggerMw, jwtAuthMw)))
+				r.Delete("/", web.HttpHandlerFunc(middleware.Wrap(authHandler.DisableMfa, middleware.RequestId, loggerMw, jwtAuthMw)))
+			})
+
+			if webauthnCfg.Enabled() {
+				r.Route("/webauthn", func(r chi.Router) {
+					r.Post("/register/begin", web.HttpHandlerFunc(middleware.Wrap(webauthnHandler.BeginRegistration, middleware.RequestId, loggerMw, jwtAuthMw)))
+					r.Post("/register/finish", web.HttpHandlerFunc(middleware.Wrap(webauthnHandler.FinishRegistration, middleware.RequestId, loggerMw, jwtAuthMw)))
+					r.Post("/login/begin", web.HttpHandlerFunc(middleware.Wrap(webauthnHandler.BeginLogin, middleware.RequestId, loggerMw, authRateLimitMw("passkey"))))
+					r.Post("/login/finish", web.HttpHandlerFunc(middleware.Wrap(webauthnHandler.FinishLogin, middleware.RequestId, loggerMw, authRateLimitMw("passkey"))))
+					r.Get("/credentials", web.HttpHandlerFunc(middleware.Wrap(webauthnHandler.Credentials, middleware.RequestId, loggerMw, jwtAuthMw)))
+					r.Delete("/credentials/{id}", web.HttpHandlerFunc(middleware.Wrap(webauthnHandler.RemoveCredential, middleware.RequestId, loggerMw, jwtAuthMw)))
+				})
+			}
 		})
 
 		r.Route("/scopes", func(r chi.Router) {
-			r.Post("/", web.HttpHandlerFunc(middleware.Wrap(scopeHandler.CreateScope, middleware.RequestId, loggerMw, jwtAuthMw)))
+			r.Post("/", web.HttpHandlerFunc(middleware.Wrap(scopeHandler.CreateScope, middleware.RequestId, loggerMw, jwtAuthMw, writeScopesMw)))
+			r.Get("/", web.HttpHandlerFunc(middleware.Wrap(scopeHandler.Scopes, middleware.RequestId, loggerMw, jwtAuthMw, readScopesMw)))
+			r.Get("/{scopeId}", web.HttpHandlerFunc(middleware.Wrap(scopeHandler.Scope, middleware.RequestId, loggerMw, jwtAuthMw, readScopesMw)))
+			r.Patch("/{scopeId}", web.HttpHandlerFunc(middleware.Wrap(scopeHandler.UpdateScope, middleware.RequestId, loggerMw, jwtAuthMw, writeScopesMw)))
+			r.Delete("/{scopeId}", web.HttpHandlerFunc(middleware.Wrap(scopeHandler.DeleteScope, middleware.RequestId, loggerMw, jwtAuthMw, writeScopesMw)))
 		})
 
 		r.Route("/roles", func(r chi.Router) {
-			r.Post("/", web.HttpHandlerFunc(middleware.Wrap(roleHandler.CreateRole, middleware.RequestId, loggerMw, jwtAuthMw)))
-			r.Post("/assign", web.HttpHandlerFunc(middleware.Wrap(roleHandler.AssignScope, middleware.RequestId, loggerMw, jwtAuthMw)))
-			r.Post("/unassign", web.HttpHandlerFunc(middleware.Wrap(roleHandler.UnassignScope, middleware.RequestId, loggerMw, jwtAuthMw)))
+			r.Post("/", web.HttpHandlerFunc(middleware.Wrap(roleHandler.CreateRole, middleware.RequestId, loggerMw, jwtAuthMw, writeRolesMw)))
+			r.Post("/assign", web.HttpHandlerFunc(middleware.Wrap(roleHandler.AssignScope, middleware.RequestId, loggerMw, jwtAuthMw, writeRolesMw)))
+			r.Post("/unassign", web.HttpHandlerFunc(middleware.Wrap(roleHandler.UnassignScope, middleware.RequestId, loggerMw, jwtAuthMw, writeRolesMw)))
+			r.Post("/inherit", web.HttpHandlerFunc(middleware.Wrap(roleHandler.InheritRole, middleware.RequestId, loggerMw, jwtAuthMw, writeRolesMw)))
+			r.Post("/disinherit", web.HttpHandlerFunc(middleware.Wrap(roleHandler.DisinheritRole, middleware.RequestId, loggerMw, jwtAuthMw, writeRolesMw)))
+			r.Get("/", web.HttpHandlerFunc(middleware.Wrap(roleHandler.Roles, middleware.RequestId, loggerMw, jwtAuthMw, readRolesMw)))
+			r.Get("/{roleId}", web.HttpHandlerFunc(middleware.Wrap(roleHandler.Role, middleware.RequestId, loggerMw, jwtAuthMw, readRolesMw)))
+			r.Patch("/{roleId}", web.HttpHandlerFunc(middleware.Wrap(roleHandler.UpdateRole, middleware.RequestId, loggerMw, jwtAuthMw, writeRolesMw)))
+			r.Delete("/{roleId}", web.HttpHandlerFunc(middleware.Wrap(roleHandler.DeleteRole, middleware.RequestId, loggerMw, jwtAuthMw, writeRolesMw)))
+		})
+
+		r.Route("/organizations", func(r chi.Router) {
+			r.Post("/", web.HttpHandlerFunc(middleware.Wrap(orgHandler.CreateOrganization, middleware.RequestId, loggerMw, jwtAuthMw, writeOrgsMw)))
+			r.Get("/", web.HttpHandlerFunc(middleware.Wrap(orgHandler.Organizations, middleware.RequestId, loggerMw, jwtAuthMw, readOrgsMw)))
+			r.Get("/{org}", web.HttpHandlerFunc(middleware.Wrap(orgHandler.Organization, middleware.RequestId, loggerMw, jwtAuthMw, readOrgMw)))
+			r.Delete("/{org}", web.HttpHandlerFunc(middleware.Wrap(orgHandler.DeleteOrganization, middleware.RequestId, loggerMw, jwtAuthMw, writeOrgsMw)))
+			r.Get("/{org}/members", web.HttpHandlerFunc(middleware.Wrap(orgHandler.Members, middleware.RequestId, loggerMw, jwtAuthMw, readOrgMw)))
+			r.Post("/{org}/members", web.HttpHandlerFunc(middleware.Wrap(orgHandler.AddMember, middleware.RequestId, loggerMw, jwtAuthMw, writeOrgMw)))
+			r.Delete("/{org}/members/{username}", web.HttpHandlerFunc(middleware.Wrap(orgHandler.RemoveMember, middleware.RequestId, loggerMw, jwtAuthMw, writeOrgMw)))
+			r.Post("/{org}/members/{username}/roles", web.HttpHandlerFunc(middleware.Wrap(orgHandler.AssignRole, middleware.RequestId, loggerMw, jwtAuthMw, writeOrgMw)))
+			r.Delete("/{org}/members/{username}/roles/{role}", web.HttpHandlerFunc(middleware.Wrap(orgHandler.UnassignRole, middleware.RequestId, loggerMw, jwtAuthMw, writeOrgMw)))
+		})
+
+		r.Route("/groups", func(r chi.Router) {
+			r.Post("/", web.HttpHandlerFunc(middleware.Wrap(groupHandler.CreateGroup, middleware.RequestId, loggerMw, jwtAuthMw, writeGroupsMw)))
+			r.Get("/", web.HttpHandlerFunc(middleware.Wrap(groupHandler.Groups, middleware.RequestId, loggerMw, jwtAuthMw, readGroupsMw)))
+			r.Get("/{group}", web.HttpHandlerFunc(middleware.Wrap(groupHandler.Group, middleware.RequestId, loggerMw, jwtAuthMw, readGroupsMw)))
+			r.Patch("/{group}", web.HttpHandlerFunc(middleware.Wrap(groupHandler.UpdateGroup, middleware.RequestId, loggerMw, jwtAuthMw, writeGroupsMw)))
+			r.Delete("/{group}", web.HttpHandlerFunc(middleware.Wrap(groupHandler.DeleteGroup, middleware.RequestId, loggerMw, jwtAuthMw, writeGroupsMw)))
+			r.Post("/{group}/members", web.HttpHandlerFunc(middleware.Wrap(groupHandler.AddMember, middleware.RequestId, loggerMw, jwtAuthMw, writeGroupsMw)))
+			r.Delete("/{group}/members/{username}", web.HttpHandlerFunc(middleware.Wrap(groupHandler.RemoveMember, middleware.RequestId, loggerMw, jwtAuthMw, writeGroupsMw)))
+			r.Post("/{group}/roles", web.HttpHandlerFunc(middleware.Wrap(groupHandler.AssignRole, middleware.RequestId, loggerMw, jwtAuthMw, writeGroupsMw)))
+			r.Delete("/{group}/roles/{role}", web.HttpHandlerFunc(middleware.Wrap(groupHandler.UnassignRole, middleware.RequestId, loggerMw, jwtAuthMw, writeGroupsMw)))
 		})
 
 		r.Route("/users", func(r chi.Router) {
-			r.Post("/assign", web.HttpHandlerFunc(middleware.Wrap(userHandler.AssignRole, middleware.RequestId, loggerMw, jwtAuthMw)))
-			r.Post("/unassign", web.HttpHandlerFunc(middleware.Wrap(userHandler.UnassignRole, middleware.RequestId, loggerMw, jwtAuthMw)))
+			r.Get("/", web.HttpHandlerFunc(middleware.Wrap(userHandler.Users, middleware.RequestId, loggerMw, jwtAuthMw, readOrgUsersMw)))
+			r.Get("/me", web.HttpHandlerFunc(middleware.Wrap(userHandler.Profile, middleware.RequestId, loggerMw, jwtAuthMw)))
+			r.Patch("/me", web.HttpHandlerFunc(middleware.Wrap(userHandler.UpdateProfile, middleware.RequestId, loggerMw, jwtAuthMw, userRateLimitMw)))
+			r.Put("/me/password", web.HttpHandlerFunc(middleware.Wrap(passwordHandler.ChangePassword, middleware.RequestId, loggerMw, jwtAuthMw, userRateLimitMw)))
+			r.Put("/me/email", web.HttpHandlerFunc(middleware.Wrap(emailHandler.ChangeEmail, middleware.RequestId, loggerMw, jwtAuthMw, userRateLimitMw)))
+			r.Get("/me/organizations", web.HttpHandlerFunc(middleware.Wrap(orgHandler.MyOrganizations, middleware.RequestId, loggerMw, jwtAuthMw)))
+			r.Post("/assign", web.HttpHandlerFunc(middleware.Wrap(userHandler.AssignRole, middleware.RequestId, loggerMw, jwtAuthMw, manageUsersMw)))
+			r.Post("/unassign", web.HttpHandlerFunc(middleware.Wrap(userHandler.UnassignRole, middleware.RequestId, loggerMw, jwtAuthMw, manageUsersMw)))
+			r.Get("/{userId}", web.HttpHandlerFunc(middleware.Wrap(userHandler.User, middleware.RequestId, loggerMw, jwtAuthMw, readUsersMw)))
+			r.Patch("/{userId}", web.HttpHandlerFunc(middleware.Wrap(userHandler.UpdateUser, middleware.RequestId, loggerMw, jwtAuthMw, manageUsersMw)))
+			r.Delete("/{userId}", web.HttpHandlerFunc(middleware.Wrap(userHandler.DeleteUser, middleware.RequestId, loggerMw, jwtAuthMw, manageUsersMw)))
+			r.Post("/{userId}/disable", web.HttpHandlerFunc(middleware.Wrap(userHandler.DisableUser, middleware.RequestId, loggerMw, jwtAuthMw, manageUsersMw)))
+			r.Post("/{userId}/enable", web.HttpHandlerFunc(middleware.Wrap(userHandler.EnableUser, middleware.RequestId, loggerMw, jwtAuthMw, manageUsersMw)))
+			r.Post("/{userId}/unlock", web.HttpHandlerFunc(middleware.Wrap(userHandler.UnlockUser, middleware.RequestId, loggerMw, jwtAuthMw, manageUsersMw)))
+			r.Get("/{userId}/sessions", web.HttpHandlerFunc(middleware.Wrap(sessionHandler.UserSessions, middleware.RequestId, loggerMw, jwtAuthMw, manageSessionsMw)))
+			r.Delete("/{userId}/sessions", web.HttpHandlerFunc(middleware.Wrap(sessionHandler.RevokeAllUserSessions, middleware.RequestId, loggerMw, jwtAuthMw, manageSessionsMw)))
+			r.Delete("/{userId}/sessions/{id}", web.HttpHandlerFunc(middleware.Wrap(sessionHandler.RevokeUserSession, middleware.RequestId, loggerMw, jwtAuthMw, manageSessionsMw)))
+		})
+
+		r.Route("/sessions", func(r chi.Router) {
+			r.Get("/", web.HttpHandlerFunc(middleware.Wrap(sessionHandler.Sessions, middleware.RequestId, loggerMw, jwtAuthMw)))
+			r.Delete("/", web.HttpHandlerFunc(middleware.Wrap(sessionHandler.RevokeAllSessions, middleware.RequestId, loggerMw, jwtAuthMw)))
+			r.Delete("/{id}", web.HttpHandlerFunc(middleware.Wrap(sessionHandler.RevokeSession, middleware.RequestId, loggerMw, jwtAuthMw)))
 		})
 	})
 
+	r.Route("/.well-known", func(r chi.Router) {
+		r.Get("/jwks.json", web.HttpHandlerFunc(middleware.Wrap(wellKnownHandler.Jwks, middleware.RequestId, loggerMw)))
+		r.Get("/openid-configuration", web.HttpHandlerFunc(middleware.Wrap(wellKnownHandler.OpenIdConfiguration, middleware.RequestId, loggerMw)))
+	})
+
+	r.Route("/oauth", func(r chi.Router) {
+		// user signs in on authsrv pages and approves authorization there, credentials are accepted from sign in form only
+		r.Get("/authorize", web.HttpHandlerFunc(middleware.Wrap(oauthHandler.Authorize, middleware.RequestId, loggerMw, authRateLimitMw("authorize"))))
+		r.Post("/authorize", web.HttpHandlerFunc(middleware.Wrap(oauthHandler.Consent, middleware.RequestId, loggerMw, authRateLimitMw("authorize"))))
+		r.Get("/signin", web.HttpHandlerFunc(middleware.Wrap(oauthHandler.SignInPage, middleware.RequestId, loggerMw, authRateLimitMw("authorize"))))
+		r.Post("/signin", web.HttpHandlerFunc(middleware.Wrap(oauthHandler.SignIn, middleware.RequestId, loggerMw, authRateLimitMw("signin"))))
+		r.Post("/signout", web.HttpHandlerFunc(middleware.Wrap(oauthHandler.SignOut, middleware.RequestId, loggerMw)))
+		r.Post("/token", web.HttpHandlerFunc(middleware.Wrap(oauthHandler.Token, middleware.RequestId, loggerMw, clientRateLimitMw)))
+		// gateways introspect every request, client authentication is required, so they aren't limited per address
+		r.Post("/introspect", web.HttpHandlerFunc(middleware.Wrap(oauthHandler.Introspect, middleware.RequestId, loggerMw)))
+		r.Post("/revoke", web.HttpHandlerFunc(middleware.Wrap(oauthHandler.Revoke, middleware.RequestId, loggerMw)))
+	})
+
 	return r, nil
 }
 
@@ -0,0 +1,140 @@
+package authcode
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"regexp"
+	"time"
+
+	"github.com/umalmyha/authsrv/pkg/errors"
+)
+
+const (
+	CodeChallengeMethodPlain = "plain"
+	CodeChallengeMethodS256  = "S256"
+)
+
+var pkceValueRegexp = regexp.MustCompile(`^[A-Za-z0-9\-._~]{43,128}$`)
+
+var (
+	AuthorizationCodeExpiredErr = errors.NewBusinessErr(
+		"code",
+		"authorization code already expired",
+		errors.ViolationSeverityErr,
+		"AUTH_CODE_EXPIRED",
+	)
+	AuthorizationCodeMismatchErr = errors.NewBusinessErr(
+		"code",
+		"authorization code was issued to another client or redirect uri",
+		errors.ViolationSeverityErr,
+		"AUTH_CODE_MISMATCH",
+	)
+	CodeVerifierMismatchErr = errors.NewBusinessErr(
+		"code_verifier",
+		"code verifier doesn't match code challenge",
+		errors.ViolationSeverityErr,
+		"AUTH_CODE_VERIFIER_MISMATCH",
+	)
+)
+
+type AuthorizationCode struct {
+	code                string
+	clientId            string
+	redirectUri         string
+	redirectUriProvided bool
+	userId              string
+	username            string
+	scopes              []string
+	nonce               string
+	codeChallenge       string
+	codeChallengeMethod string
+	authTime            time.Time
+	amr                 []string
+	expiresAt           time.Time
+}
+
+// Code returns plain code value, it is available only for newly created codes
+func (ac *AuthorizationCode) Code() string {
+	return ac.code
+}
+
+func (ac *AuthorizationCode) ClientId() string {
+	return ac.clientId
+}
+
+func (ac *AuthorizationCode) UserId() string {
+	return ac.userId
+}
+
+func (ac *AuthorizationCode) Username() string {
+	return ac.username
+}
+
+func (ac *AuthorizationCode) Scopes() []string {
+	return ac.scopes
+}
+
+func (ac *AuthorizationCode) Nonce() string {
+	return ac.nonce
+}
+
+func (ac *AuthorizationCode) AuthTime() time.Time {
+	return ac.authTime
+}
+
+// Amr returns authentication methods user was authenticated with on authorization
+func (ac *AuthorizationCode) Amr() []string {
+	return ac.amr
+}
+
+// Redeem verifies that code can be exchanged for tokens by the client with provided redirect uri and PKCE verifier
+func (ac *AuthorizationCode) Redeem(clientId string, redirectUri string, verifier string, now time.Time) error {
+	if ac.expiresAt.Before(now) {
+		return AuthorizationCodeExpiredErr
+	}
+
+	if ac.clientId != clientId {
+		return AuthorizationCodeMismatchErr
+	}
+
+	if ac.redirectUriProvided && ac.redirectUri != redirectUri {
+		return AuthorizationCodeMismatchErr
+	}
+
+	if !pkceValueRegexp.MatchString(verifier) {
+		return CodeVerifierMismatchErr
+	}
+
+	var expected string
+	switch ac.codeChallengeMethod {
+	case CodeChallengeMethodS256:
+		sum := sha256.Sum256([]byte(verifier))
+		expected = base64.RawURLEncoding.EncodeToString(sum[:])
+	default:
+		expected = verifier
+	}
+
+	if subtle.ConstantTimeCompare([]byte(expected), []byte(ac.codeChallenge)) != 1 {
+		return CodeVerifierMismatchErr
+	}
+
+	return nil
+}
+
+func (ac *AuthorizationCode) Dto() AuthorizationCodeDto {
+	return AuthorizationCodeDto{
+		ClientId:            ac.clientId,
+		RedirectUri:         ac.redirectUri,
+		RedirectUriProvided: ac.redirectUriProvided,
+		UserId:              ac.userId,
+		Username:            ac.username,
+		Scopes:              ac.scopes,
+		Nonce:               ac.nonce,
+		CodeChallenge:       ac.codeChallenge,
+		CodeChallengeMethod: ac.codeChallengeMethod,
+		AuthTime:            ac.authTime,
+		Amr:                 ac.amr,
+		ExpiresAt:           ac.expiresAt,
+	}
+}
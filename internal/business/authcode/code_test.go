@@ -0,0 +1,101 @@
+package authcode
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	valueobj "github.com/umalmyha/authsrv/internal/business/value-object"
+)
+
+const (
+	success = "\u2713"
+	failed  = "\u2717"
+)
+
+// RFC 7636 Appendix B example
+const (
+	rfcVerifier  = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	rfcChallenge = "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+)
+
+func newTestCode(method string, challenge string, fatalFn func(error)) (*AuthorizationCode, time.Time) {
+	cfg, err := valueobj.NewOAuthConfig(time.Minute, time.Hour)
+	if err != nil {
+		fatalFn(err)
+	}
+
+	issuedAt := time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC)
+	code, err := NewAuthorizationCode(NewAuthorizationCodeDto{
+		ClientId:            "client",
+		RedirectUri:         "https://app.example.com/callback",
+		RedirectUriProvided: true,
+		UserId:              "user-id",
+		Username:            "user",
+		CodeChallenge:       challenge,
+		CodeChallengeMethod: method,
+	}, issuedAt, cfg)
+	if err != nil {
+		fatalFn(err)
+	}
+
+	return code, issuedAt
+}
+
+func TestAuthorizationCodeRedeem(t *testing.T) {
+	fatalFn := func(err error) { t.Fatal(err) }
+	redirectUri := "https://app.example.com/callback"
+
+	t.Log("Given the need to test authorization code redemption")
+	{
+		t.Logf("\tTest 1:\tWhen S256 code challenge is used")
+		{
+			code, issuedAt := newTestCode(CodeChallengeMethodS256, rfcChallenge, fatalFn)
+
+			if err := code.Redeem("client", redirectUri, rfcVerifier, issuedAt); err != nil {
+				t.Fatalf("\t%s\tCode must be redeemed with valid verifier, but got %v", failed, err)
+			}
+			t.Logf("\t%s\tCode must be redeemed with valid verifier", success)
+
+			if err := code.Redeem("client", redirectUri, rfcChallenge, issuedAt); !errors.Is(err, CodeVerifierMismatchErr) {
+				t.Fatalf("\t%s\tChallenge itself mustn't be accepted as verifier", failed)
+			}
+			t.Logf("\t%s\tChallenge itself mustn't be accepted as verifier", success)
+		}
+
+		t.Logf("\tTest 2:\tWhen plain code challenge is used")
+		{
+			code, issuedAt := newTestCode("", rfcVerifier, fatalFn)
+
+			if err := code.Redeem("client", redirectUri, rfcVerifier, issuedAt); err != nil {
+				t.Fatalf("\t%s\tCode must be redeemed with verifier equal to challenge, but got %v", failed, err)
+			}
+			t.Logf("\t%s\tCode must be redeemed with verifier equal to challenge", success)
+		}
+
+		t.Logf("\tTest 3:\tWhen code is redeemed by another client or with another redirect uri")
+		{
+			code, issuedAt := newTestCode(CodeChallengeMethodS256, rfcChallenge, fatalFn)
+
+			if err := code.Redeem("other", redirectUri, rfcVerifier, issuedAt); !errors.Is(err, AuthorizationCodeMismatchErr) {
+				t.Fatalf("\t%s\tCode mustn't be redeemed by another client", failed)
+			}
+			t.Logf("\t%s\tCode mustn't be redeemed by another client", success)
+
+			if err := code.Redeem("client", "https://evil.example.com", rfcVerifier, issuedAt); !errors.Is(err, AuthorizationCodeMismatchErr) {
+				t.Fatalf("\t%s\tCode mustn't be redeemed with another redirect uri", failed)
+			}
+			t.Logf("\t%s\tCode mustn't be redeemed with another redirect uri", success)
+		}
+
+		t.Logf("\tTest 4:\tWhen code is expired")
+		{
+			code, issuedAt := newTestCode(CodeChallengeMethodS256, rfcChallenge, fatalFn)
+
+			if err := code.Redeem("client", redirectUri, rfcVerifier, issuedAt.Add(2*time.Minute)); !errors.Is(err, AuthorizationCodeExpiredErr) {
+				t.Fatalf("\t%s\tExpired code mustn't be redeemed", failed)
+			}
+			t.Logf("\t%s\tExpired code mustn't be redeemed", success)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package authcode
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+	dbredis "github.com/umalmyha/authsrv/pkg/database/redis"
+)
+
+const keyPrefix = "authcode:"
+
+type AuthorizationCodeDao struct {
+	*dbredis.Store
+}
+
+func NewAuthorizationCodeDao(rdb *redis.Client) *AuthorizationCodeDao {
+	return &AuthorizationCodeDao{
+		Store: dbredis.NewStore(rdb),
+	}
+}
+
+func (dao *AuthorizationCodeDao) Save(ctx context.Context, code string, dto AuthorizationCodeDto) error {
+	encoded, err := dbredis.EncodeGob(dto)
+	if err != nil {
+		return errors.Wrap(err, "failed to serialize authorization code to gob format")
+	}
+
+	ttl := time.Until(dto.ExpiresAt)
+	if ttl <= 0 {
+		return errors.New("authorization code is already expired")
+	}
+
+	if err := dao.Client().Set(ctx, codeKey(code), encoded, ttl).Err(); err != nil {
+		return errors.Wrap(err, "failed to save authorization code")
+	}
+	return nil
+}
+
+// Consume reads authorization code and removes it atomically, so every code can be redeemed only once
+func (dao *AuthorizationCodeDao) Consume(ctx context.Context, code string) (AuthorizationCodeDto, error) {
+	var dto AuthorizationCodeDto
+
+	encoded, err := dao.Client().GetDel(ctx, codeKey(code)).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return dto, nil
+		}
+		return dto, errors.Wrap(err, "failed to read authorization code")
+	}
+
+	if err := dbredis.DecodeGob([]byte(encoded), &dto); err != nil {
+		return dto, errors.Wrap(err, "failed to deserialize authorization code from gob format")
+	}
+	return dto, nil
+}
+
+// codes are stored by hash, so plain values never reach the storage
+func codeKey(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return keyPrefix + hex.EncodeToString(sum[:])
+}
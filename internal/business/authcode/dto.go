@@ -0,0 +1,54 @@
+package authcode
+
+import "time"
+
+type AuthorizationCodeDto struct {
+	ClientId            string
+	RedirectUri         string
+	RedirectUriProvided bool
+	UserId              string
+	Username            string
+	Scopes              []string
+	Nonce               string
+	CodeChallenge       string
+	CodeChallengeMethod string
+	AuthTime            time.Time
+	Amr                 []string
+	ExpiresAt           time.Time
+}
+
+func (dto AuthorizationCodeDto) IsPresent() bool {
+	return dto.ClientId != "" && dto.UserId != ""
+}
+
+func (dto AuthorizationCodeDto) ToAuthorizationCode() *AuthorizationCode {
+	return &AuthorizationCode{
+		clientId:            dto.ClientId,
+		redirectUri:         dto.RedirectUri,
+		redirectUriProvided: dto.RedirectUriProvided,
+		userId:              dto.UserId,
+		username:            dto.Username,
+		scopes:              dto.Scopes,
+		nonce:               dto.Nonce,
+		codeChallenge:       dto.CodeChallenge,
+		codeChallengeMethod: dto.CodeChallengeMethod,
+		authTime:            dto.AuthTime,
+		amr:                 dto.Amr,
+		expiresAt:           dto.ExpiresAt,
+	}
+}
+
+type NewAuthorizationCodeDto struct {
+	ClientId            string
+	RedirectUri         string
+	RedirectUriProvided bool
+	UserId              string
+	Username            string
+	Scopes              []string
+	Nonce               string
+	CodeChallenge       string
+	CodeChallengeMethod string
+	// AuthTime is when user was authenticated, code issuance time is used if it isn't provided
+	AuthTime time.Time
+	Amr      []string
+}
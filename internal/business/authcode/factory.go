@@ -0,0 +1,72 @@
+package authcode
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"time"
+
+	pkgerrors "github.com/pkg/errors"
+	valueobj "github.com/umalmyha/authsrv/internal/business/value-object"
+	"github.com/umalmyha/authsrv/pkg/errors"
+)
+
+const codeLength = 32
+
+func NewAuthorizationCode(dto NewAuthorizationCodeDto, issuedAt time.Time, cfg valueobj.OAuthConfig) (*AuthorizationCode, error) {
+	validation := errors.NewValidation()
+
+	if dto.ClientId == "" {
+		validation.Add(errors.NewBusinessErr("client_id", "client id is mandatory", errors.ViolationSeverityErr, errors.CodeValidationFailed))
+	}
+
+	if dto.UserId == "" || dto.Username == "" {
+		validation.Add(errors.NewBusinessErr("user", "authorized user is mandatory", errors.ViolationSeverityErr, errors.CodeValidationFailed))
+	}
+
+	if dto.RedirectUri == "" {
+		validation.Add(errors.NewBusinessErr("redirect_uri", "redirect uri is mandatory", errors.ViolationSeverityErr, errors.CodeValidationFailed))
+	}
+
+	method := dto.CodeChallengeMethod
+	if method == "" {
+		method = CodeChallengeMethodPlain
+	}
+
+	if method != CodeChallengeMethodPlain && method != CodeChallengeMethodS256 {
+		validation.Add(errors.NewBusinessErr("code_challenge_method", "code challenge method must be S256 or plain", errors.ViolationSeverityErr, errors.CodeValidationFailed))
+	}
+
+	if !pkceValueRegexp.MatchString(dto.CodeChallenge) {
+		validation.Add(errors.NewBusinessErr("code_challenge", "code challenge is missing or malformed", errors.ViolationSeverityErr, errors.CodeValidationFailed))
+	}
+
+	if validation.HasError() {
+		return nil, pkgerrors.Wrap(validation.RaiseValidationErr(errors.ViolationSeverityErr), "validation failed for authorization code creation")
+	}
+
+	authTime := dto.AuthTime
+	if authTime.IsZero() {
+		authTime = issuedAt
+	}
+
+	code := make([]byte, codeLength)
+	if _, err := rand.Read(code); err != nil {
+		return nil, pkgerrors.Wrap(err, "failed to generate authorization code")
+	}
+
+	return &AuthorizationCode{
+		code:                base64.RawURLEncoding.EncodeToString(code),
+		clientId:            dto.ClientId,
+		redirectUri:         dto.RedirectUri,
+		redirectUriProvided: dto.RedirectUriProvided,
+		userId:              dto.UserId,
+		username:            dto.Username,
+		scopes:              dto.Scopes,
+		nonce:               dto.Nonce,
+		codeChallenge:       dto.CodeChallenge,
+		codeChallengeMethod: method,
+		authTime:            authTime,
+		amr:                 dto.Amr,
+		expiresAt:           issuedAt.Add(cfg.CodeTimeToLive()),
+	}, nil
+}
@@ -0,0 +1,164 @@
+package client
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+
+	"github.com/pkg/errors"
+	"github.com/umalmyha/authsrv/internal/business/scope"
+	valueobj "github.com/umalmyha/authsrv/internal/business/value-object"
+	"github.com/umalmyha/authsrv/pkg/authz"
+	"github.com/umalmyha/authsrv/pkg/helpers"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	GrantTypeAuthorizationCode = "authorization_code"
+	GrantTypeRefreshToken      = "refresh_token"
+	GrantTypeClientCredentials = "client_credentials"
+)
+
+const secretLength = 32
+
+var supportedGrantTypes = map[string]bool{
+	GrantTypeAuthorizationCode: true,
+	GrantTypeRefreshToken:      true,
+	GrantTypeClientCredentials: true,
+}
+
+type ScopeFinderByNameFn func(string) (scope.ScopeDto, error)
+
+type clientScope struct {
+	id   valueobj.ScopeId
+	name string
+}
+
+type Client struct {
+	id           string
+	name         valueobj.SolidString
+	secretHash   valueobj.NilString
+	redirectUris []string
+	grantTypes   []string
+	scopes       []clientScope
+}
+
+func (c *Client) Id() string {
+	return c.id
+}
+
+func (c *Client) Name() string {
+	return c.name.String()
+}
+
+// IsConfidential reports whether client has secret and must authenticate itself
+func (c *Client) IsConfidential() bool {
+	return c.secretHash.String() != ""
+}
+
+// RotateSecret replaces client secret with newly generated one, plain secret is returned only once
+func (c *Client) RotateSecret(hasher valueobj.PasswordHasher) (string, error) {
+	secret, hash, err := generateSecret(hasher)
+	if err != nil {
+		return "", err
+	}
+
+	c.secretHash = valueobj.NewNilString(hash)
+	return secret, nil
+}
+
+func (c *Client) VerifySecret(secret string) (bool, error) {
+	if !c.IsConfidential() {
+		return false, errors.Errorf("client %s is public and has no secret", c.name)
+	}
+
+	if secret == "" {
+		return false, nil
+	}
+
+	return valueobj.VerifyPasswordHash(secret, c.secretHash.String())
+}
+
+func (c *Client) AllowsGrantType(grantType string) bool {
+	return slices.Contains(c.grantTypes, grantType)
+}
+
+// GrantScopes checks that requested scopes are allowed for the client, nil requested scopes means all allowed scopes.
+// Wildcard scope of the client allows every matching scope.
+func (c *Client) GrantScopes(requested []string) ([]string, error) {
+	allowed := helpers.Map(c.scopes, func(sc clientScope, _ int, _ []clientScope) string {
+		return sc.name
+	})
+
+	if requested == nil {
+		return allowed, nil
+	}
+
+	granted := make([]string, 0)
+	for _, name := range requested {
+		if !authz.Granted(allowed, name) {
+			return nil, errors.Errorf("scope %s is not allowed for client %s", name, c.name)
+		}
+		granted = append(granted, name)
+	}
+	return granted, nil
+}
+
+// ResolveRedirectUri returns redirect uri to be used for authorization response,
+// requested uri must exactly match one of registered, if it is omitted the only registered one is used
+func (c *Client) ResolveRedirectUri(requested string) (string, error) {
+	if requested == "" {
+		if len(c.redirectUris) != 1 {
+			return "", errors.New("redirect uri must be provided, since client has several or no registered")
+		}
+		return c.redirectUris[0], nil
+	}
+
+	for _, uri := range c.redirectUris {
+		if uri == requested {
+			return uri, nil
+		}
+	}
+
+	return "", errors.Errorf("redirect uri %s is not registered for client %s", requested, c.name)
+}
+
+func (c *Client) ToDto() ClientDto {
+	return ClientDto{
+		Id:         c.id,
+		Name:       c.name.String(),
+		SecretHash: c.secretHash.Ptr(),
+	}
+}
+
+func (c *Client) RedirectUrisDto() []RedirectUriDto {
+	return helpers.Map(c.redirectUris, func(uri string, _ int, _ []string) RedirectUriDto {
+		return RedirectUriDto{ClientId: c.id, Uri: uri}
+	})
+}
+
+func (c *Client) GrantTypesDto() []GrantTypeDto {
+	return helpers.Map(c.grantTypes, func(gt string, _ int, _ []string) GrantTypeDto {
+		return GrantTypeDto{ClientId: c.id, GrantType: gt}
+	})
+}
+
+func (c *Client) ScopesDto() []ScopeAssignmentDto {
+	return helpers.Map(c.scopes, func(sc clientScope, _ int, _ []clientScope) ScopeAssignmentDto {
+		return ScopeAssignmentDto{ClientId: c.id, ScopeId: sc.id.String(), ScopeName: sc.name}
+	})
+}
+
+func generateSecret(hasher valueobj.PasswordHasher) (string, string, error) {
+	raw := make([]byte, secretLength)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", errors.Wrap(err, "failed to generate client secret")
+	}
+	secret := base64.RawURLEncoding.EncodeToString(raw)
+
+	hash, err := hasher.Hash(secret)
+	if err != nil {
+		return "", "", errors.Wrap(err, "failed to hash client secret")
+	}
+
+	return secret, hash, nil
+}
@@ -0,0 +1,103 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/umalmyha/authsrv/internal/business/scope"
+	valueobj "github.com/umalmyha/authsrv/internal/business/value-object"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	success = "\u2713"
+	failed  = "\u2717"
+)
+
+func newTestClient(dto NewClientDto, fatalFn func(error)) (*Client, string, error) {
+	hasher, err := valueobj.NewBcryptHasher(bcrypt.MinCost)
+	if err != nil {
+		fatalFn(err)
+	}
+
+	existFn := func(string) (bool, error) { return false, nil }
+	scopes := map[string]scope.ScopeDto{
+		"users:read":  {Id: "6b1f5e0c-5b7e-4a34-9d3e-0a7c8f1f3f01", Name: "users:read"},
+		"users:write": {Id: "6b1f5e0c-5b7e-4a34-9d3e-0a7c8f1f3f02", Name: "users:write"},
+	}
+	finderFn := func(name string) (scope.ScopeDto, error) { return scopes[name], nil }
+
+	return FromNewClientDto(dto, hasher, existFn, finderFn)
+}
+
+func TestClientCredentials(t *testing.T) {
+	fatalFn := func(err error) { t.Fatal(err) }
+
+	t.Log("Given the need to test confidential clients")
+	{
+		t.Logf("\tTest 1:\tWhen public client requests client credentials grant")
+		{
+			_, _, err := newTestClient(NewClientDto{Name: "public", GrantTypes: []string{GrantTypeClientCredentials}}, fatalFn)
+			if err == nil {
+				t.Fatalf("\t%s\tPublic client mustn't be allowed to use client credentials grant", failed)
+			}
+			t.Logf("\t%s\tPublic client mustn't be allowed to use client credentials grant", success)
+		}
+
+		t.Logf("\tTest 2:\tWhen confidential client is created and its secret is rotated")
+		{
+			c, secret, err := newTestClient(NewClientDto{
+				Name:         "billing",
+				Confidential: true,
+				GrantTypes:   []string{GrantTypeClientCredentials},
+				Scopes:       []string{"users:read"},
+			}, fatalFn)
+			if err != nil {
+				t.Fatalf("\t%s\tUnexpected error on client creation: %v", failed, err)
+			}
+
+			if verified, err := c.VerifySecret(secret); err != nil || !verified {
+				t.Fatalf("\t%s\tGenerated secret must be verified", failed)
+			}
+			t.Logf("\t%s\tGenerated secret must be verified", success)
+
+			hasher, _ := valueobj.NewBcryptHasher(bcrypt.MinCost)
+			rotated, err := c.RotateSecret(hasher)
+			if err != nil {
+				t.Fatalf("\t%s\tUnexpected error on secret rotation: %v", failed, err)
+			}
+
+			if verified, _ := c.VerifySecret(secret); verified {
+				t.Fatalf("\t%s\tOld secret mustn't be verified after rotation", failed)
+			}
+			t.Logf("\t%s\tOld secret mustn't be verified after rotation", success)
+
+			if verified, err := c.VerifySecret(rotated); err != nil || !verified {
+				t.Fatalf("\t%s\tRotated secret must be verified", failed)
+			}
+			t.Logf("\t%s\tRotated secret must be verified", success)
+		}
+
+		t.Logf("\tTest 3:\tWhen client requests scopes")
+		{
+			c, _, err := newTestClient(NewClientDto{
+				Name:         "billing",
+				Confidential: true,
+				GrantTypes:   []string{GrantTypeClientCredentials},
+				Scopes:       []string{"users:read"},
+			}, fatalFn)
+			if err != nil {
+				t.Fatalf("\t%s\tUnexpected error on client creation: %v", failed, err)
+			}
+
+			if granted, err := c.GrantScopes(nil); err != nil || len(granted) != 1 || granted[0] != "users:read" {
+				t.Fatalf("\t%s\tAll allowed scopes must be granted if none requested, got %v", failed, granted)
+			}
+			t.Logf("\t%s\tAll allowed scopes must be granted if none requested", success)
+
+			if _, err := c.GrantScopes([]string{"users:write"}); err == nil {
+				t.Fatalf("\t%s\tScope which is not allowed mustn't be granted", failed)
+			}
+			t.Logf("\t%s\tScope which is not allowed mustn't be granted", success)
+		}
+	}
+}
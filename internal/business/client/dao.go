@@ -0,0 +1,233 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	"github.com/umalmyha/authsrv/pkg/database/rdb"
+)
+
+type ClientDao struct {
+	ec sqlx.ExtContext
+}
+
+func NewClientDao(ec sqlx.ExtContext) *ClientDao {
+	return &ClientDao{
+		ec: ec,
+	}
+}
+
+func (dao *ClientDao) CreateMulti(ctx context.Context, clients []ClientDto) error {
+	applier := func(c ClientDto) []any {
+		return []any{c.Id, c.Name, c.SecretHash}
+	}
+
+	q, params, err := rdb.BulkInsertQuery("OAUTH_CLIENTS", []string{"ID", "NAME", "SECRET_HASH"}, clients, applier)
+	if err != nil {
+		return errors.Wrap(err, "failed to build bulk insert SQL query for clients creation")
+	}
+
+	if _, err := dao.ec.ExecContext(ctx, q, params...); err != nil {
+		return errors.Wrap(err, "failed to create clients")
+	}
+
+	return nil
+}
+
+func (dao *ClientDao) DeleteWhereIdsIn(ctx context.Context, ids []string) error {
+	inRange, params, err := rdb.WhereIn(ids)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate SQL where clause for clients deletion")
+	}
+
+	q := fmt.Sprintf("DELETE FROM OAUTH_CLIENTS WHERE ID IN %s", inRange)
+	if _, err := dao.ec.ExecContext(ctx, q, params...); err != nil {
+		return errors.Wrap(err, "failed to delete clients")
+	}
+
+	return nil
+}
+
+func (dao *ClientDao) Update(ctx context.Context, c ClientDto) error {
+	q := "UPDATE OAUTH_CLIENTS SET SECRET_HASH = $1 WHERE ID = $2"
+	if _, err := dao.ec.ExecContext(ctx, q, c.SecretHash, c.Id); err != nil {
+		return errors.Wrap(err, "failed to update client")
+	}
+	return nil
+}
+
+func (dao *ClientDao) FindById(ctx context.Context, id string) (ClientDto, error) {
+	var c ClientDto
+	q := "SELECT ID, NAME, SECRET_HASH FROM OAUTH_CLIENTS WHERE ID = $1"
+	if err := sqlx.GetContext(ctx, dao.ec, &c, q, id); err != nil {
+		return c, errors.Wrap(err, "failed to find client by id")
+	}
+	return c, nil
+}
+
+func (dao *ClientDao) FindByName(ctx context.Context, name string) (ClientDto, error) {
+	var c ClientDto
+	q := "SELECT ID, NAME, SECRET_HASH FROM OAUTH_CLIENTS WHERE NAME = $1"
+	if err := sqlx.GetContext(ctx, dao.ec, &c, q, name); err != nil {
+		return c, errors.Wrap(err, "failed to find client by name")
+	}
+	return c, nil
+}
+
+type RedirectUriDao struct {
+	ec sqlx.ExtContext
+}
+
+func NewRedirectUriDao(ec sqlx.ExtContext) *RedirectUriDao {
+	return &RedirectUriDao{
+		ec: ec,
+	}
+}
+
+func (dao *RedirectUriDao) CreateMulti(ctx context.Context, uris []RedirectUriDto) error {
+	applier := func(uri RedirectUriDto) []any {
+		return []any{uri.ClientId, uri.Uri}
+	}
+
+	q, params, err := rdb.BulkInsertQuery("OAUTH_CLIENT_REDIRECT_URIS", []string{"CLIENT_ID", "URI"}, uris, applier)
+	if err != nil {
+		return errors.Wrap(err, "failed to build bulk insert SQL query for redirect uris creation")
+	}
+
+	if _, err := dao.ec.ExecContext(ctx, q, params...); err != nil {
+		return errors.Wrap(err, "failed to create redirect uris")
+	}
+
+	return nil
+}
+
+func (dao *RedirectUriDao) DeleteByClientIdAndUrisIn(ctx context.Context, clientId string, uris []string) error {
+	inRange, params, err := rdb.WhereIn(uris)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate SQL where clause for redirect uris deletion")
+	}
+
+	params = append(params, clientId)
+	q := fmt.Sprintf("DELETE FROM OAUTH_CLIENT_REDIRECT_URIS WHERE URI IN %s AND CLIENT_ID = $%d", inRange, len(params))
+	if _, err := dao.ec.ExecContext(ctx, q, params...); err != nil {
+		return errors.Wrap(err, "failed to delete redirect uris")
+	}
+
+	return nil
+}
+
+func (dao *RedirectUriDao) FindAllForClient(ctx context.Context, clientId string) ([]RedirectUriDto, error) {
+	uris := make([]RedirectUriDto, 0)
+	q := "SELECT CLIENT_ID, URI FROM OAUTH_CLIENT_REDIRECT_URIS WHERE CLIENT_ID = $1"
+	if err := sqlx.SelectContext(ctx, dao.ec, &uris, q, clientId); err != nil {
+		return nil, errors.Wrap(err, "failed to read client redirect uris")
+	}
+	return uris, nil
+}
+
+type GrantTypeDao struct {
+	ec sqlx.ExtContext
+}
+
+func NewGrantTypeDao(ec sqlx.ExtContext) *GrantTypeDao {
+	return &GrantTypeDao{
+		ec: ec,
+	}
+}
+
+func (dao *GrantTypeDao) CreateMulti(ctx context.Context, grantTypes []GrantTypeDto) error {
+	applier := func(gt GrantTypeDto) []any {
+		return []any{gt.ClientId, gt.GrantType}
+	}
+
+	q, params, err := rdb.BulkInsertQuery("OAUTH_CLIENT_GRANT_TYPES", []string{"CLIENT_ID", "GRANT_TYPE"}, grantTypes, applier)
+	if err != nil {
+		return errors.Wrap(err, "failed to build bulk insert SQL query for grant types creation")
+	}
+
+	if _, err := dao.ec.ExecContext(ctx, q, params...); err != nil {
+		return errors.Wrap(err, "failed to create grant types")
+	}
+
+	return nil
+}
+
+func (dao *GrantTypeDao) DeleteByClientIdAndGrantTypesIn(ctx context.Context, clientId string, grantTypes []string) error {
+	inRange, params, err := rdb.WhereIn(grantTypes)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate SQL where clause for grant types deletion")
+	}
+
+	params = append(params, clientId)
+	q := fmt.Sprintf("DELETE FROM OAUTH_CLIENT_GRANT_TYPES WHERE GRANT_TYPE IN %s AND CLIENT_ID = $%d", inRange, len(params))
+	if _, err := dao.ec.ExecContext(ctx, q, params...); err != nil {
+		return errors.Wrap(err, "failed to delete grant types")
+	}
+
+	return nil
+}
+
+func (dao *GrantTypeDao) FindAllForClient(ctx context.Context, clientId string) ([]GrantTypeDto, error) {
+	grantTypes := make([]GrantTypeDto, 0)
+	q := "SELECT CLIENT_ID, GRANT_TYPE FROM OAUTH_CLIENT_GRANT_TYPES WHERE CLIENT_ID = $1"
+	if err := sqlx.SelectContext(ctx, dao.ec, &grantTypes, q, clientId); err != nil {
+		return nil, errors.Wrap(err, "failed to read client grant types")
+	}
+	return grantTypes, nil
+}
+
+type ScopeAssignmentDao struct {
+	ec sqlx.ExtContext
+}
+
+func NewScopeAssignmentDao(ec sqlx.ExtContext) *ScopeAssignmentDao {
+	return &ScopeAssignmentDao{
+		ec: ec,
+	}
+}
+
+func (dao *ScopeAssignmentDao) CreateMulti(ctx context.Context, scopes []ScopeAssignmentDto) error {
+	applier := func(scope ScopeAssignmentDto) []any {
+		return []any{scope.ClientId, scope.ScopeId}
+	}
+
+	q, params, err := rdb.BulkInsertQuery("OAUTH_CLIENT_SCOPES", []string{"CLIENT_ID", "SCOPE_ID"}, scopes, applier)
+	if err != nil {
+		return errors.Wrap(err, "failed to build bulk insert SQL query for client scope assignments creation")
+	}
+
+	if _, err := dao.ec.ExecContext(ctx, q, params...); err != nil {
+		return errors.Wrap(err, "failed to create client scope assignments")
+	}
+
+	return nil
+}
+
+func (dao *ScopeAssignmentDao) DeleteByClientIdAndScopeIdsIn(ctx context.Context, clientId string, scopeIds []string) error {
+	inRange, params, err := rdb.WhereIn(scopeIds)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate SQL where clause for client scope assignments deletion")
+	}
+
+	params = append(params, clientId)
+	q := fmt.Sprintf("DELETE FROM OAUTH_CLIENT_SCOPES WHERE SCOPE_ID IN %s AND CLIENT_ID = $%d", inRange, len(params))
+	if _, err := dao.ec.ExecContext(ctx, q, params...); err != nil {
+		return errors.Wrap(err, "failed to delete client scope assignments")
+	}
+
+	return nil
+}
+
+func (dao *ScopeAssignmentDao) FindAllForClient(ctx context.Context, clientId string) ([]ScopeAssignmentDto, error) {
+	scopes := make([]ScopeAssignmentDto, 0)
+	q := `SELECT CS.CLIENT_ID, CS.SCOPE_ID, S.NAME AS SCOPE_NAME
+		FROM OAUTH_CLIENT_SCOPES CS
+		JOIN SCOPES S ON S.ID = CS.SCOPE_ID
+		WHERE CS.CLIENT_ID = $1`
+	if err := sqlx.SelectContext(ctx, dao.ec, &scopes, q, clientId); err != nil {
+		return nil, errors.Wrap(err, "failed to read client scope assignments")
+	}
+	return scopes, nil
+}
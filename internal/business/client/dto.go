@@ -0,0 +1,106 @@
+package client
+
+import (
+	"fmt"
+
+	"github.com/umalmyha/authsrv/pkg/helpers"
+)
+
+type ClientDto struct {
+	Id         string  `db:"id" json:"id"`
+	Name       string  `db:"name" json:"name"`
+	SecretHash *string `db:"secret_hash" json:"-"`
+}
+
+func (dto ClientDto) Key() string {
+	return dto.Id
+}
+
+func (dto ClientDto) IsPresent() bool {
+	return dto.Id != ""
+}
+
+func (dto ClientDto) Equal(other ClientDto) bool {
+	return dto.Name == other.Name && helpers.EqualValues(dto.SecretHash, other.SecretHash)
+}
+
+func (dto ClientDto) Clone() ClientDto {
+	return ClientDto{
+		Id:         dto.Id,
+		Name:       dto.Name,
+		SecretHash: helpers.CopyValue(dto.SecretHash),
+	}
+}
+
+type RedirectUriDto struct {
+	ClientId string `db:"client_id"`
+	Uri      string `db:"uri"`
+}
+
+func (dto RedirectUriDto) Key() string {
+	return fmt.Sprintf("%s-%s", dto.ClientId, dto.Uri)
+}
+
+func (dto RedirectUriDto) IsPresent() bool {
+	return dto.ClientId != "" && dto.Uri != ""
+}
+
+func (dto RedirectUriDto) Equal(other RedirectUriDto) bool {
+	return dto.ClientId == other.ClientId && dto.Uri == other.Uri
+}
+
+func (dto RedirectUriDto) Clone() RedirectUriDto {
+	return dto
+}
+
+type GrantTypeDto struct {
+	ClientId  string `db:"client_id"`
+	GrantType string `db:"grant_type"`
+}
+
+func (dto GrantTypeDto) Key() string {
+	return fmt.Sprintf("%s-%s", dto.ClientId, dto.GrantType)
+}
+
+func (dto GrantTypeDto) IsPresent() bool {
+	return dto.ClientId != "" && dto.GrantType != ""
+}
+
+func (dto GrantTypeDto) Equal(other GrantTypeDto) bool {
+	return dto.ClientId == other.ClientId && dto.GrantType == other.GrantType
+}
+
+func (dto GrantTypeDto) Clone() GrantTypeDto {
+	return dto
+}
+
+// ScopeAssignmentDto carries scope name for reading only, assignment itself is identified by ids
+type ScopeAssignmentDto struct {
+	ClientId  string `db:"client_id"`
+	ScopeId   string `db:"scope_id"`
+	ScopeName string `db:"scope_name"`
+}
+
+func (dto ScopeAssignmentDto) Key() string {
+	return fmt.Sprintf("%s-%s", dto.ClientId, dto.ScopeId)
+}
+
+func (dto ScopeAssignmentDto) IsPresent() bool {
+	return dto.ClientId != "" && dto.ScopeId != ""
+}
+
+func (dto ScopeAssignmentDto) Equal(other ScopeAssignmentDto) bool {
+	return dto.ClientId == other.ClientId && dto.ScopeId == other.ScopeId
+}
+
+func (dto ScopeAssignmentDto) Clone() ScopeAssignmentDto {
+	return dto
+}
+
+type NewClientDto struct {
+	Name         string   `json:"name"`
+	Confidential bool     `json:"confidential"`
+	RedirectUris []string `json:"redirectUris"`
+	GrantTypes   []string `json:"grantTypes"`
+	Scopes       []string `json:"scopes"`
+}
@@ -0,0 +1,191 @@
+package client
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/google/uuid"
+	pkgerrors "github.com/pkg/errors"
+	valueobj "github.com/umalmyha/authsrv/internal/business/value-object"
+	"github.com/umalmyha/authsrv/pkg/errors"
+	"golang.org/x/exp/slices"
+)
+
+type isExistingClientNameFn func(string) (bool, error)
+
+// FromNewClientDto builds new client, plain secret is returned for confidential clients only
+func FromNewClientDto(dto NewClientDto, hasher valueobj.PasswordHasher, existFn isExistingClientNameFn, finderFn ScopeFinderByNameFn) (*Client, string, error) {
+	validation := errors.NewValidation()
+	if dto.Name == "" {
+		validation.Add(
+			errors.NewBusinessErr("name", "client name can not be empty", errors.ViolationSeverityErr, errors.CodeValidationFailed),
+		)
+	}
+
+	clientName, err := valueobj.NewSolidString(dto.Name)
+	if err != nil {
+		validation.Add(
+			errors.NewBusinessErr("name", err.Error(), errors.ViolationSeverityErr, errors.CodeValidationFailed),
+		)
+	}
+
+	exist, err := existFn(dto.Name)
+	if err != nil {
+		return nil, "", pkgerrors.Wrap(err, "failed to check client existence")
+	} else if exist {
+		validation.Add(
+			errors.NewBusinessErr(
+				"name",
+				fmt.Sprintf("client with name %s already exists", dto.Name),
+				errors.ViolationSeverityErr,
+				errors.CodeValidationFailed,
+			),
+		)
+	}
+
+	grantTypes := make([]string, 0)
+	for _, gt := range dto.GrantTypes {
+		if !supportedGrantTypes[gt] {
+			validation.Add(
+				errors.NewBusinessErr("grantTypes", fmt.Sprintf("grant type %s is not supported", gt), errors.ViolationSeverityErr, errors.CodeValidationFailed),
+			)
+			continue
+		}
+
+		if !slices.Contains(grantTypes, gt) {
+			grantTypes = append(grantTypes, gt)
+		}
+	}
+
+	if len(dto.GrantTypes) == 0 {
+		grantTypes = append(grantTypes, GrantTypeAuthorizationCode, GrantTypeRefreshToken)
+	}
+
+	if slices.Contains(grantTypes, GrantTypeClientCredentials) && !dto.Confidential {
+		validation.Add(
+			errors.NewBusinessErr("confidential", "client credentials grant is allowed for confidential clients only", errors.ViolationSeverityErr, errors.CodeValidationFailed),
+		)
+	}
+
+	if slices.Contains(grantTypes, GrantTypeAuthorizationCode) && len(dto.RedirectUris) == 0 {
+		validation.Add(
+			errors.NewBusinessErr("redirectUris", "at least one redirect uri must be registered for authorization code grant", errors.ViolationSeverityErr, errors.CodeValidationFailed),
+		)
+	}
+
+	redirectUris := make([]string, 0)
+	for _, uri := range dto.RedirectUris {
+		if err := validateRedirectUri(uri); err != nil {
+			validation.Add(
+				errors.NewBusinessErr(
+					"redirectUris",
+					fmt.Sprintf("redirect uri %s is invalid: %s", uri, err.Error()),
+					errors.ViolationSeverityErr,
+					errors.CodeValidationFailed,
+				),
+			)
+			continue
+		}
+
+		if !slices.Contains(redirectUris, uri) {
+			redirectUris = append(redirectUris, uri)
+		}
+	}
+
+	scopes := make([]clientScope, 0)
+	for _, name := range dto.Scopes {
+		sc, err := finderFn(name)
+		if err != nil {
+			return nil, "", pkgerrors.Wrap(err, "failed to find scope")
+		}
+
+		if !sc.IsPresent() {
+			validation.Add(
+				errors.NewBusinessErr("scopes", fmt.Sprintf("scope %s doesn't exist", name), errors.ViolationSeverityErr, errors.CodeValidationFailed),
+			)
+			continue
+		}
+
+		scopeIdent, err := valueobj.NewScopeId(sc.Id)
+		if err != nil {
+			return nil, "", pkgerrors.Wrap(err, "failed to build scope identifier")
+		}
+
+		if slices.IndexFunc(scopes, func(assigned clientScope) bool { return assigned.id.Equal(scopeIdent) }) == -1 {
+			scopes = append(scopes, clientScope{id: scopeIdent, name: sc.Name})
+		}
+	}
+
+	if validation.HasError() {
+		return nil, "", pkgerrors.Wrap(validation.RaiseValidationErr(errors.ViolationSeverityErr), "validation failed for client creation")
+	}
+
+	c := &Client{
+		id:           uuid.NewString(),
+		name:         clientName,
+		redirectUris: redirectUris,
+		grantTypes:   grantTypes,
+		scopes:       scopes,
+	}
+
+	var secret string
+	if dto.Confidential {
+		if secret, err = c.RotateSecret(hasher); err != nil {
+			return nil, "", err
+		}
+	}
+
+	return c, secret, nil
+}
+
+func fromDbDtos(clientDto ClientDto, urisDto []RedirectUriDto, grantTypesDto []GrantTypeDto, scopesDto []ScopeAssignmentDto) (*Client, error) {
+	name, err := valueobj.NewSolidString(clientDto.Name)
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "failed to build client name from db entry")
+	}
+
+	redirectUris := make([]string, 0)
+	for _, uri := range urisDto {
+		redirectUris = append(redirectUris, uri.Uri)
+	}
+
+	grantTypes := make([]string, 0)
+	for _, gt := range grantTypesDto {
+		grantTypes = append(grantTypes, gt.GrantType)
+	}
+
+	scopes := make([]clientScope, 0)
+	for _, sc := range scopesDto {
+		scopeIdent, err := valueobj.NewScopeId(sc.ScopeId)
+		if err != nil {
+			return nil, pkgerrors.Wrap(err, "failed to build scope identifier from db entry")
+		}
+		scopes = append(scopes, clientScope{id: scopeIdent, name: sc.ScopeName})
+	}
+
+	return &Client{
+		id:           clientDto.Id,
+		name:         name,
+		secretHash:   valueobj.NewNilStringFromPtr(clientDto.SecretHash),
+		redirectUris: redirectUris,
+		grantTypes:   grantTypes,
+		scopes:       scopes,
+	}, nil
+}
+
+func validateRedirectUri(uri string) error {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return err
+	}
+
+	if !u.IsAbs() || u.Host == "" {
+		return pkgerrors.New("absolute uri is expected")
+	}
+
+	if u.Fragment != "" {
+		return pkgerrors.New("fragment is not allowed")
+	}
+
+	return nil
+}
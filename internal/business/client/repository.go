@@ -0,0 +1,84 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+type Repository struct {
+	uow *unitOfWork
+}
+
+func NewRepository(u *unitOfWork) *Repository {
+	return &Repository{
+		uow: u,
+	}
+}
+
+func (repo *Repository) Add(c *Client) error {
+	return repo.uow.RegisterNew(c)
+}
+
+func (repo *Repository) Update(c *Client) error {
+	return repo.uow.RegisterAmended(c)
+}
+
+func (repo *Repository) FindById(ctx context.Context, id string) (*Client, error) {
+	notPresentFn := func() (ClientDto, error) {
+		return NewClientDao(repo.uow.ExtContext()).FindById(ctx, id)
+	}
+
+	c, err := repo.uow.clients.FindByKey(id).IfNotPresent(notPresentFn)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read client aggregate by id")
+	}
+
+	if !c.IsPresent() {
+		return nil, fmt.Errorf("client with id %s doesn't exist", id)
+	}
+
+	return repo.loadClient(ctx, c)
+}
+
+func (repo *Repository) FindByName(ctx context.Context, name string) (*Client, error) {
+	notPresentFn := func() (ClientDto, error) {
+		return NewClientDao(repo.uow.ExtContext()).FindByName(ctx, name)
+	}
+
+	c, err := repo.uow.clients.Find(func(dto ClientDto) bool { return dto.Name == name }).IfNotPresent(notPresentFn)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read client aggregate by name")
+	}
+
+	if !c.IsPresent() {
+		return nil, fmt.Errorf("client %s doesn't exist", name)
+	}
+
+	return repo.loadClient(ctx, c)
+}
+
+func (repo *Repository) loadClient(ctx context.Context, c ClientDto) (*Client, error) {
+	uris, err := NewRedirectUriDao(repo.uow.ExtContext()).FindAllForClient(ctx, c.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	grantTypes, err := NewGrantTypeDao(repo.uow.ExtContext()).FindAllForClient(ctx, c.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	scopes, err := NewScopeAssignmentDao(repo.uow.ExtContext()).FindAllForClient(ctx, c.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	cl, err := fromDbDtos(c, uris, grantTypes, scopes)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build client aggregate from db DTOs")
+	}
+
+	return cl, repo.uow.RegisterClean(cl)
+}
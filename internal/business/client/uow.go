@@ -0,0 +1,224 @@
+package client
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	"github.com/umalmyha/authsrv/pkg/ddd/uow"
+	"github.com/umalmyha/authsrv/pkg/helpers"
+)
+
+type unitOfWork struct {
+	*uow.SqlxUnitOfWork
+	clients        *uow.ChangeSet[ClientDto]
+	redirectUris   *uow.ChangeSet[RedirectUriDto]
+	grantTypes     *uow.ChangeSet[GrantTypeDto]
+	assignedScopes *uow.ChangeSet[ScopeAssignmentDto]
+}
+
+func NewUnitOfWork(db *sqlx.DB) *unitOfWork {
+	return &unitOfWork{
+		SqlxUnitOfWork: uow.NewSqlxUnitOfWork(db),
+		clients:        uow.NewChangeSet[ClientDto](),
+		redirectUris:   uow.NewChangeSet[RedirectUriDto](),
+		grantTypes:     uow.NewChangeSet[GrantTypeDto](),
+		assignedScopes: uow.NewChangeSet[ScopeAssignmentDto](),
+	}
+}
+
+func (uow *unitOfWork) RegisterClean(c *Client) error {
+	uow.clients.Attach(c.ToDto())
+	uow.redirectUris.AttachRange(c.RedirectUrisDto()...)
+	uow.grantTypes.AttachRange(c.GrantTypesDto()...)
+	uow.assignedScopes.AttachRange(c.ScopesDto()...)
+	return nil
+}
+
+func (uow *unitOfWork) RegisterNew(c *Client) error {
+	if err := uow.clients.Add(c.ToDto()); err != nil {
+		return errors.Wrap(err, "failed to add client DTO to changeset")
+	}
+
+	if err := uow.redirectUris.AddRange(c.RedirectUrisDto()...); err != nil {
+		return errors.Wrap(err, "failed to add redirect uris DTOs to changeset")
+	}
+
+	if err := uow.grantTypes.AddRange(c.GrantTypesDto()...); err != nil {
+		return errors.Wrap(err, "failed to add grant types DTOs to changeset")
+	}
+
+	if err := uow.assignedScopes.AddRange(c.ScopesDto()...); err != nil {
+		return errors.Wrap(err, "failed to add scope assignments DTOs to changeset")
+	}
+
+	return nil
+}
+
+func (uow *unitOfWork) RegisterDeleted(c *Client) error {
+	if err := uow.clients.Remove(c.ToDto()); err != nil {
+		return errors.Wrap(err, "failed to delete client DTO in changeset")
+	}
+
+	if err := uow.redirectUris.RemoveRange(c.RedirectUrisDto()...); err != nil {
+		return errors.Wrap(err, "failed to delete redirect uris DTOs in changeset")
+	}
+
+	if err := uow.grantTypes.RemoveRange(c.GrantTypesDto()...); err != nil {
+		return errors.Wrap(err, "failed to delete grant types DTOs in changeset")
+	}
+
+	if err := uow.assignedScopes.RemoveRange(c.ScopesDto()...); err != nil {
+		return errors.Wrap(err, "failed to delete scope assignments DTOs in changeset")
+	}
+
+	return nil
+}
+
+func (uow *unitOfWork) RegisterAmended(c *Client) error {
+	clientDto := c.ToDto()
+	if err := uow.clients.Update(clientDto); err != nil {
+		return errors.Wrap(err, "failed to update client DTO in changeset")
+	}
+
+	createdUris, _, deletedUris := uow.redirectUris.DeltaWithMatched(c.RedirectUrisDto(), func(uri RedirectUriDto) bool {
+		return uri.ClientId == clientDto.Id
+	})
+
+	if err := uow.redirectUris.AddRange(createdUris...); err != nil {
+		return errors.Wrap(err, "failed to add redirect uris DTOs to changeset")
+	}
+
+	if err := uow.redirectUris.RemoveRange(deletedUris...); err != nil {
+		return errors.Wrap(err, "failed to delete redirect uris DTOs in changeset")
+	}
+
+	createdGrantTypes, _, deletedGrantTypes := uow.grantTypes.DeltaWithMatched(c.GrantTypesDto(), func(gt GrantTypeDto) bool {
+		return gt.ClientId == clientDto.Id
+	})
+
+	if err := uow.grantTypes.AddRange(createdGrantTypes...); err != nil {
+		return errors.Wrap(err, "failed to add grant types DTOs to changeset")
+	}
+
+	if err := uow.grantTypes.RemoveRange(deletedGrantTypes...); err != nil {
+		return errors.Wrap(err, "failed to delete grant types DTOs in changeset")
+	}
+
+	createdScopes, _, deletedScopes := uow.assignedScopes.DeltaWithMatched(c.ScopesDto(), func(sc ScopeAssignmentDto) bool {
+		return sc.ClientId == clientDto.Id
+	})
+
+	if err := uow.assignedScopes.AddRange(createdScopes...); err != nil {
+		return errors.Wrap(err, "failed to add scope assignments DTOs to changeset")
+	}
+
+	if err := uow.assignedScopes.RemoveRange(deletedScopes...); err != nil {
+		return errors.Wrap(err, "failed to delete scope assignments DTOs in changeset")
+	}
+
+	return nil
+}
+
+func (uow *unitOfWork) Flush(ctx context.Context) error {
+	tx, err := uow.Tx(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to open transaction")
+	}
+	defer tx.Rollback()
+
+	clientDao := NewClientDao(tx)
+	uriDao := NewRedirectUriDao(tx)
+	grantTypeDao := NewGrantTypeDao(tx)
+	scopeDao := NewScopeAssignmentDao(tx)
+
+	if rmUris := uow.redirectUris.Deleted(); len(rmUris) > 0 {
+		urisGroup := helpers.GroupBy(rmUris, func(uri RedirectUriDto, _ int, _ []RedirectUriDto) (string, string) {
+			return uri.ClientId, uri.Uri
+		})
+
+		for clientId, uris := range urisGroup {
+			if err := uriDao.DeleteByClientIdAndUrisIn(ctx, clientId, uris); err != nil {
+				return errors.Wrap(err, "failed to process redirect uris deletion")
+			}
+		}
+	}
+
+	if rmGrantTypes := uow.grantTypes.Deleted(); len(rmGrantTypes) > 0 {
+		grantTypesGroup := helpers.GroupBy(rmGrantTypes, func(gt GrantTypeDto, _ int, _ []GrantTypeDto) (string, string) {
+			return gt.ClientId, gt.GrantType
+		})
+
+		for clientId, grantTypes := range grantTypesGroup {
+			if err := grantTypeDao.DeleteByClientIdAndGrantTypesIn(ctx, clientId, grantTypes); err != nil {
+				return errors.Wrap(err, "failed to process grant types deletion")
+			}
+		}
+	}
+
+	if rmScopes := uow.assignedScopes.Deleted(); len(rmScopes) > 0 {
+		scopesGroup := helpers.GroupBy(rmScopes, func(sc ScopeAssignmentDto, _ int, _ []ScopeAssignmentDto) (string, string) {
+			return sc.ClientId, sc.ScopeId
+		})
+
+		for clientId, scopeIds := range scopesGroup {
+			if err := scopeDao.DeleteByClientIdAndScopeIdsIn(ctx, clientId, scopeIds); err != nil {
+				return errors.Wrap(err, "failed to process scope assignments deletion")
+			}
+		}
+	}
+
+	if rmClients := uow.clients.Deleted(); len(rmClients) > 0 {
+		mapper := func(c ClientDto, _ int, _ []ClientDto) string {
+			return c.Id
+		}
+		if err := clientDao.DeleteWhereIdsIn(ctx, helpers.Map(rmClients, mapper)); err != nil {
+			return errors.Wrap(err, "failed to process clients deletion")
+		}
+	}
+
+	if createdClients := uow.clients.Created(); len(createdClients) > 0 {
+		if err := clientDao.CreateMulti(ctx, createdClients); err != nil {
+			return errors.Wrap(err, "failed to process clients creation")
+		}
+	}
+
+	if createdUris := uow.redirectUris.Created(); len(createdUris) > 0 {
+		if err := uriDao.CreateMulti(ctx, createdUris); err != nil {
+			return errors.Wrap(err, "failed to process redirect uris creation")
+		}
+	}
+
+	if createdGrantTypes := uow.grantTypes.Created(); len(createdGrantTypes) > 0 {
+		if err := grantTypeDao.CreateMulti(ctx, createdGrantTypes); err != nil {
+			return errors.Wrap(err, "failed to process grant types creation")
+		}
+	}
+
+	if createdScopes := uow.assignedScopes.Created(); len(createdScopes) > 0 {
+		if err := scopeDao.CreateMulti(ctx, createdScopes); err != nil {
+			return errors.Wrap(err, "failed to process scope assignments creation")
+		}
+	}
+
+	if updatedClients := uow.clients.Updated(); len(updatedClients) > 0 {
+		for _, updClient := range updatedClients {
+			if err := clientDao.Update(ctx, updClient); err != nil {
+				return errors.Wrap(err, "failed to process clients update")
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "failed to commit transaction")
+	}
+	return uow.Dispose()
+}
+
+func (uow *unitOfWork) Dispose() error {
+	uow.clients.Cleanup()
+	uow.redirectUris.Cleanup()
+	uow.grantTypes.Cleanup()
+	uow.assignedScopes.Cleanup()
+	return nil
+}
@@ -0,0 +1,50 @@
+package denylist
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+	dbredis "github.com/umalmyha/authsrv/pkg/database/redis"
+)
+
+const keyPrefix = "denied_jti:"
+
+type DenylistDao struct {
+	*dbredis.Store
+}
+
+func NewDenylistDao(rdb *redis.Client) *DenylistDao {
+	return &DenylistDao{
+		Store: dbredis.NewStore(rdb),
+	}
+}
+
+// Deny puts token id to denylist until token expires
+func (dao *DenylistDao) Deny(ctx context.Context, tokenId string, expiresAt time.Time, now time.Time) error {
+	if err := DenyWith(ctx, dao.Client(), tokenId, expiresAt, now); err != nil {
+		return errors.Wrap(err, "failed to deny token")
+	}
+	return nil
+}
+
+// DenyWith puts token id to denylist via provided client or pipeline, already expired tokens are ignored
+func DenyWith(ctx context.Context, cmd redis.Cmdable, tokenId string, expiresAt time.Time, now time.Time) error {
+	if tokenId == "" || !expiresAt.After(now) {
+		return nil
+	}
+	return cmd.Set(ctx, Key(tokenId), 1, expiresAt.Sub(now)).Err()
+}
+
+func (dao *DenylistDao) IsDenied(ctx context.Context, tokenId string) (bool, error) {
+	n, err := dao.Client().Exists(ctx, Key(tokenId)).Result()
+	if err != nil {
+		return false, errors.Wrap(err, "failed to check token in denylist")
+	}
+	return n > 0, nil
+}
+
+func Key(tokenId string) string {
+	return keyPrefix + tokenId
+}
@@ -0,0 +1,219 @@
+package group
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	"github.com/umalmyha/authsrv/pkg/database/rdb"
+)
+
+type GroupDao struct {
+	ec sqlx.ExtContext
+}
+
+func NewGroupDao(ec sqlx.ExtContext) *GroupDao {
+	return &GroupDao{
+		ec: ec,
+	}
+}
+
+func (dao *GroupDao) CreateMulti(ctx context.Context, groups []GroupDto) error {
+	applier := func(g GroupDto) []any {
+		return []any{g.Id, g.Name, g.Description}
+	}
+
+	q, params, err := rdb.BulkInsertQuery("GROUPS", []string{"ID", "NAME", "DESCRIPTION"}, groups, applier)
+	if err != nil {
+		return errors.Wrap(err, "failed to build bulk insert SQL query for groups creation")
+	}
+
+	if _, err := dao.ec.ExecContext(ctx, q, params...); err != nil {
+		return errors.Wrap(err, "failed to create groups")
+	}
+
+	return nil
+}
+
+func (dao *GroupDao) DeleteWhereIdsIn(ctx context.Context, ids []string) error {
+	inRange, params, err := rdb.WhereIn(ids)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate SQL where clause for groups deletion")
+	}
+
+	q := fmt.Sprintf("DELETE FROM GROUPS WHERE ID IN %s", inRange)
+	if _, err := dao.ec.ExecContext(ctx, q, params...); err != nil {
+		return errors.Wrap(err, "failed to delete groups")
+	}
+
+	return nil
+}
+
+func (dao *GroupDao) Update(ctx context.Context, g GroupDto) error {
+	q := "UPDATE GROUPS SET DESCRIPTION = $1 WHERE ID = $2"
+	if _, err := dao.ec.ExecContext(ctx, q, g.Description, g.Id); err != nil {
+		return errors.Wrap(err, "failed to update group")
+	}
+	return nil
+}
+
+func (dao *GroupDao) FindByName(ctx context.Context, name string) (GroupDto, error) {
+	var g GroupDto
+	q := "SELECT ID, NAME, DESCRIPTION FROM GROUPS WHERE NAME = $1"
+	if err := sqlx.GetContext(ctx, dao.ec, &g, q, name); err != nil {
+		return g, errors.Wrap(err, "failed to find group by name")
+	}
+	return g, nil
+}
+
+func (dao *GroupDao) FindAll(ctx context.Context) ([]GroupDto, error) {
+	groups := make([]GroupDto, 0)
+	q := "SELECT ID, NAME, DESCRIPTION FROM GROUPS ORDER BY NAME"
+	if err := sqlx.SelectContext(ctx, dao.ec, &groups, q); err != nil {
+		return nil, errors.Wrap(err, "failed to read groups")
+	}
+	return groups, nil
+}
+
+type MemberDao struct {
+	ec sqlx.ExtContext
+}
+
+func NewMemberDao(ec sqlx.ExtContext) *MemberDao {
+	return &MemberDao{
+		ec: ec,
+	}
+}
+
+func (dao *MemberDao) CreateMulti(ctx context.Context, members []MemberDto) error {
+	applier := func(member MemberDto) []any {
+		return []any{member.GroupId, member.UserId}
+	}
+
+	q, params, err := rdb.BulkInsertQuery("GROUPS_MEMBERS", []string{"GROUP_ID", "USER_ID"}, members, applier)
+	if err != nil {
+		return errors.Wrap(err, "failed to build bulk insert SQL query for group members creation")
+	}
+
+	if _, err := dao.ec.ExecContext(ctx, q, params...); err != nil {
+		return errors.Wrap(err, "failed to create group members")
+	}
+
+	return nil
+}
+
+func (dao *MemberDao) FindAllForGroup(ctx context.Context, groupId string) ([]MemberDto, error) {
+	members := make([]MemberDto, 0)
+	q := "SELECT GROUP_ID, USER_ID FROM GROUPS_MEMBERS WHERE GROUP_ID = $1"
+	if err := sqlx.SelectContext(ctx, dao.ec, &members, q, groupId); err != nil {
+		return nil, errors.Wrap(err, "failed to read members of group")
+	}
+	return members, nil
+}
+
+func (dao *MemberDao) FindUsernamesWhereGroupIdsIn(ctx context.Context, groupIds []string) ([]GroupMemberNameDto, error) {
+	members := make([]GroupMemberNameDto, 0)
+	if len(groupIds) == 0 {
+		return members, nil
+	}
+
+	inRange, params, err := rdb.WhereIn(groupIds)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate SQL where clause")
+	}
+
+	q := fmt.Sprintf(`SELECT GM.GROUP_ID, U.USERNAME FROM GROUPS_MEMBERS AS GM
+		INNER JOIN USERS AS U ON U.ID = GM.USER_ID
+		WHERE GM.GROUP_ID IN %s ORDER BY U.USERNAME`, inRange)
+	if err := sqlx.SelectContext(ctx, dao.ec, &members, q, params...); err != nil {
+		return nil, errors.Wrap(err, "failed to read member names of groups")
+	}
+	return members, nil
+}
+
+func (dao *MemberDao) DeleteByGroupIdAndUserIdsIn(ctx context.Context, groupId string, userIds []string) error {
+	inRange, params, err := rdb.WhereIn(userIds)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate SQL where clause for group members deletion")
+	}
+
+	params = append(params, groupId)
+	q := fmt.Sprintf("DELETE FROM GROUPS_MEMBERS WHERE USER_ID IN %s AND GROUP_ID = $%d", inRange, len(params))
+	if _, err := dao.ec.ExecContext(ctx, q, params...); err != nil {
+		return errors.Wrap(err, "failed to delete group members")
+	}
+
+	return nil
+}
+
+type RoleAssignmentDao struct {
+	ec sqlx.ExtContext
+}
+
+func NewRoleAssignmentDao(ec sqlx.ExtContext) *RoleAssignmentDao {
+	return &RoleAssignmentDao{
+		ec: ec,
+	}
+}
+
+func (dao *RoleAssignmentDao) CreateMulti(ctx context.Context, roles []RoleAssignmentDto) error {
+	applier := func(assigned RoleAssignmentDto) []any {
+		return []any{assigned.GroupId, assigned.RoleId}
+	}
+
+	q, params, err := rdb.BulkInsertQuery("GROUPS_ROLES", []string{"GROUP_ID", "ROLE_ID"}, roles, applier)
+	if err != nil {
+		return errors.Wrap(err, "failed to build bulk insert SQL query for group role assignments creation")
+	}
+
+	if _, err := dao.ec.ExecContext(ctx, q, params...); err != nil {
+		return errors.Wrap(err, "failed to create group role assignments")
+	}
+
+	return nil
+}
+
+func (dao *RoleAssignmentDao) FindAllForGroup(ctx context.Context, groupId string) ([]RoleAssignmentDto, error) {
+	roles := make([]RoleAssignmentDto, 0)
+	q := "SELECT GROUP_ID, ROLE_ID FROM GROUPS_ROLES WHERE GROUP_ID = $1"
+	if err := sqlx.SelectContext(ctx, dao.ec, &roles, q, groupId); err != nil {
+		return nil, errors.Wrap(err, "failed to read role assignments of group")
+	}
+	return roles, nil
+}
+
+func (dao *RoleAssignmentDao) FindRoleNamesWhereGroupIdsIn(ctx context.Context, groupIds []string) ([]GroupRoleNameDto, error) {
+	roles := make([]GroupRoleNameDto, 0)
+	if len(groupIds) == 0 {
+		return roles, nil
+	}
+
+	inRange, params, err := rdb.WhereIn(groupIds)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate SQL where clause")
+	}
+
+	q := fmt.Sprintf(`SELECT GR.GROUP_ID, R.NAME AS ROLE_NAME FROM GROUPS_ROLES AS GR
+		INNER JOIN ROLES AS R ON R.ID = GR.ROLE_ID
+		WHERE GR.GROUP_ID IN %s ORDER BY R.NAME`, inRange)
+	if err := sqlx.SelectContext(ctx, dao.ec, &roles, q, params...); err != nil {
+		return nil, errors.Wrap(err, "failed to read role names of groups")
+	}
+	return roles, nil
+}
+
+func (dao *RoleAssignmentDao) DeleteByGroupIdAndRoleIdsIn(ctx context.Context, groupId string, roleIds []string) error {
+	inRange, params, err := rdb.WhereIn(roleIds)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate SQL where clause for group role assignments deletion")
+	}
+
+	params = append(params, groupId)
+	q := fmt.Sprintf("DELETE FROM GROUPS_ROLES WHERE ROLE_ID IN %s AND GROUP_ID = $%d", inRange, len(params))
+	if _, err := dao.ec.ExecContext(ctx, q, params...); err != nil {
+		return errors.Wrap(err, "failed to delete group role assignments")
+	}
+
+	return nil
+}
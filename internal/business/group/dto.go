@@ -0,0 +1,124 @@
+package group
+
+import (
+	"fmt"
+
+	"github.com/umalmyha/authsrv/pkg/helpers"
+)
+
+type GroupDto struct {
+	Id          string  `db:"id" json:"id"`
+	Name        string  `db:"name" json:"name"`
+	Description *string `db:"description" json:"description"`
+}
+
+func (dto GroupDto) Key() string {
+	return dto.Id
+}
+
+func (dto GroupDto) IsPresent() bool {
+	return dto.Id != ""
+}
+
+func (dto GroupDto) Equal(other GroupDto) bool {
+	return dto.Name == other.Name && helpers.EqualValues(dto.Description, other.Description)
+}
+
+func (dto GroupDto) Clone() GroupDto {
+	return GroupDto{
+		Id:          dto.Id,
+		Name:        dto.Name,
+		Description: helpers.CopyValue(dto.Description),
+	}
+}
+
+// Details returns group with given member and role names, names are read separately
+func (dto GroupDto) Details(members []string, roles []string) GroupDetailsDto {
+	if members == nil {
+		members = make([]string, 0)
+	}
+
+	if roles == nil {
+		roles = make([]string, 0)
+	}
+
+	return GroupDetailsDto{
+		Id:          dto.Id,
+		Name:        dto.Name,
+		Description: dto.Description,
+		Members:     members,
+		Roles:       roles,
+	}
+}
+
+type MemberDto struct {
+	GroupId string `db:"group_id"`
+	UserId  string `db:"user_id"`
+}
+
+func (dto MemberDto) Key() string {
+	return fmt.Sprintf("%s-%s", dto.GroupId, dto.UserId)
+}
+
+func (dto MemberDto) IsPresent() bool {
+	return dto.GroupId != "" && dto.UserId != ""
+}
+
+func (dto MemberDto) Equal(other MemberDto) bool {
+	return dto.GroupId == other.GroupId && dto.UserId == other.UserId
+}
+
+func (dto MemberDto) Clone() MemberDto {
+	return dto
+}
+
+// RoleAssignmentDto is role granted to every member of group
+type RoleAssignmentDto struct {
+	GroupId string `db:"group_id"`
+	RoleId  string `db:"role_id"`
+}
+
+func (dto RoleAssignmentDto) Key() string {
+	return fmt.Sprintf("%s-%s", dto.GroupId, dto.RoleId)
+}
+
+func (dto RoleAssignmentDto) IsPresent() bool {
+	return dto.GroupId != "" && dto.RoleId != ""
+}
+
+func (dto RoleAssignmentDto) Equal(other RoleAssignmentDto) bool {
+	return dto.GroupId == other.GroupId && dto.RoleId == other.RoleId
+}
+
+func (dto RoleAssignmentDto) Clone() RoleAssignmentDto {
+	return dto
+}
+
+type NewGroupDto struct {
+	Name        string  `json:"name"`
+	Description *string `json:"description"`
+}
+
+// UpdateGroupDto holds fields to change, missing description is left unchanged and empty one clears it
+type UpdateGroupDto struct {
+	Description *string `json:"description"`
+}
+
+// GroupDetailsDto is group together with usernames of members and names of assigned roles
+type GroupDetailsDto struct {
+	Id          string   `json:"id"`
+	Name        string   `json:"name"`
+	Description *string  `json:"description"`
+	Members     []string `json:"members"`
+	Roles       []string `json:"roles"`
+}
+
+type GroupMemberNameDto struct {
+	GroupId  string `db:"group_id"`
+	Username string `db:"username"`
+}
+
+type GroupRoleNameDto struct {
+	GroupId  string `db:"group_id"`
+	RoleName string `db:"role_name"`
+}
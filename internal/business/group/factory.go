@@ -0,0 +1,84 @@
+package group
+
+import (
+	"container/list"
+	"fmt"
+
+	"github.com/google/uuid"
+	pkgerrors "github.com/pkg/errors"
+	valueobj "github.com/umalmyha/authsrv/internal/business/value-object"
+	"github.com/umalmyha/authsrv/pkg/errors"
+	"github.com/umalmyha/authsrv/pkg/helpers"
+)
+
+type isExistingGroupNameFn func(string) (bool, error)
+
+func FromNewGroupDto(dto NewGroupDto, existFn isExistingGroupNameFn) (*Group, error) {
+	validation := errors.NewValidation()
+	if dto.Name == "" {
+		validation.Add(
+			errors.NewBusinessErr("group name can not be empty", "name", errors.ViolationSeverityErr, errors.CodeValidationFailed),
+		)
+	}
+
+	groupName, err := valueobj.NewSolidString(dto.Name)
+	if err != nil {
+		validation.Add(
+			errors.NewBusinessErr(err.Error(), "name", errors.ViolationSeverityErr, errors.CodeValidationFailed),
+		)
+	}
+
+	exist, err := existFn(dto.Name)
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "failed to check existence of group by name")
+	} else if exist {
+		validation.Add(
+			errors.NewBusinessErr(
+				fmt.Sprintf("group with name %s already exists", dto.Name),
+				"name",
+				errors.ViolationSeverityErr,
+				errors.CodeValidationFailed,
+			),
+		)
+	}
+
+	if validation.HasError() {
+		return nil, pkgerrors.Wrap(validation.RaiseValidationErr(errors.ViolationSeverityErr), "validation failed for group creation")
+	}
+
+	return &Group{
+		id:          uuid.NewString(),
+		name:        groupName,
+		description: valueobj.NewNilStringFromPtr(dto.Description),
+		members:     list.New(),
+		roles:       list.New(),
+	}, nil
+}
+
+func fromDbDtos(groupDto GroupDto, membersDto []MemberDto, rolesDto []RoleAssignmentDto) (*Group, error) {
+	name, err := valueobj.NewSolidString(groupDto.Name)
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "failed to build group name from db entry")
+	}
+
+	members := helpers.Map(membersDto, func(member MemberDto, _ int, _ []MemberDto) string {
+		return member.UserId
+	})
+
+	roleIds := make([]valueobj.RoleId, 0)
+	for _, assigned := range rolesDto {
+		roleId, err := valueobj.NewRoleId(assigned.RoleId)
+		if err != nil {
+			return nil, pkgerrors.Wrap(err, "failed to build role identifier from db entry")
+		}
+		roleIds = append(roleIds, roleId)
+	}
+
+	return &Group{
+		id:          groupDto.Id,
+		name:        name,
+		description: valueobj.NewNilStringFromPtr(groupDto.Description),
+		members:     helpers.ToList(members),
+		roles:       helpers.ToList(roleIds),
+	}, nil
+}
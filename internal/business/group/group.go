@@ -0,0 +1,166 @@
+package group
+
+import (
+	"container/list"
+
+	"github.com/pkg/errors"
+	"github.com/umalmyha/authsrv/internal/business/role"
+	"github.com/umalmyha/authsrv/internal/business/user"
+	valueobj "github.com/umalmyha/authsrv/internal/business/value-object"
+	"github.com/umalmyha/authsrv/pkg/helpers"
+)
+
+var (
+	MemberExistsErr = errors.New("user is already member of group")
+	NotMemberErr    = errors.New("user is not member of group")
+)
+
+type UserFinderByNameFn func(string) (user.UserDto, error)
+type RoleFinderByNameFn func(string) (role.RoleDto, error)
+
+// Group grants its roles to all members, so roles are assigned to teams rather than user by user
+type Group struct {
+	id          string
+	name        valueobj.SolidString
+	description valueobj.NilString
+	members     *list.List
+	roles       *list.List
+}
+
+func (g *Group) Id() string {
+	return g.id
+}
+
+func (g *Group) Name() string {
+	return g.name.String()
+}
+
+func (g *Group) ChangeDescription(descr string) {
+	g.description = valueobj.NewNilString(descr)
+}
+
+func (g *Group) AddMember(username string, finderFn UserFinderByNameFn) error {
+	usr, err := g.findUser(username, finderFn)
+	if err != nil {
+		return err
+	}
+
+	if g.findMemberElem(usr.Id) != nil {
+		return errors.Wrapf(MemberExistsErr, "user %s is already member of %s", username, g.name)
+	}
+
+	g.members.PushBack(usr.Id)
+	return nil
+}
+
+func (g *Group) RemoveMember(username string, finderFn UserFinderByNameFn) error {
+	usr, err := g.findUser(username, finderFn)
+	if err != nil {
+		return err
+	}
+
+	elem := g.findMemberElem(usr.Id)
+	if elem == nil {
+		return errors.Wrapf(NotMemberErr, "user %s is not member of %s", username, g.name)
+	}
+
+	g.members.Remove(elem)
+	return nil
+}
+
+func (g *Group) AssignRole(name string, finderFn RoleFinderByNameFn) error {
+	roleIdent, err := g.findRoleId(name, finderFn)
+	if err != nil {
+		return err
+	}
+
+	if g.findRoleElem(roleIdent) != nil {
+		return errors.Errorf("role %s is already assigned to group %s", name, g.name)
+	}
+
+	g.roles.PushBack(roleIdent)
+	return nil
+}
+
+func (g *Group) UnassignRole(name string, finderFn RoleFinderByNameFn) error {
+	roleIdent, err := g.findRoleId(name, finderFn)
+	if err != nil {
+		return err
+	}
+
+	elem := g.findRoleElem(roleIdent)
+	if elem == nil {
+		return errors.Errorf("role %s is not assigned to group %s", name, g.name)
+	}
+
+	g.roles.Remove(elem)
+	return nil
+}
+
+func (g *Group) ToDto() GroupDto {
+	return GroupDto{
+		Id:          g.id,
+		Name:        g.name.String(),
+		Description: g.description.Ptr(),
+	}
+}
+
+func (g *Group) MembersDto() []MemberDto {
+	return helpers.FromListWithReducer(g.members, func(userId string) MemberDto {
+		return MemberDto{GroupId: g.id, UserId: userId}
+	})
+}
+
+func (g *Group) RolesDto() []RoleAssignmentDto {
+	return helpers.FromListWithReducer(g.roles, func(roleId valueobj.RoleId) RoleAssignmentDto {
+		return RoleAssignmentDto{GroupId: g.id, RoleId: roleId.String()}
+	})
+}
+
+func (g *Group) findUser(username string, finderFn UserFinderByNameFn) (user.UserDto, error) {
+	usr, err := finderFn(username)
+	if err != nil {
+		return usr, errors.Wrap(err, "failed to find user")
+	}
+
+	if !usr.IsPresent() {
+		return usr, errors.Errorf("user %s doesn't exist", username)
+	}
+	return usr, nil
+}
+
+func (g *Group) findRoleId(name string, finderFn RoleFinderByNameFn) (valueobj.RoleId, error) {
+	r, err := finderFn(name)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to find role")
+	}
+
+	if !r.IsPresent() {
+		return "", errors.Errorf("role %s doesn't exist", name)
+	}
+
+	roleIdent, err := valueobj.NewRoleId(r.Id)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to build role identifier")
+	}
+	return roleIdent, nil
+}
+
+func (g *Group) findMemberElem(userId string) *list.Element {
+	for elem := g.members.Front(); elem != nil; elem = elem.Next() {
+		if memberId, _ := elem.Value.(string); memberId == userId {
+			return elem
+		}
+	}
+	return nil
+}
+
+func (g *Group) findRoleElem(roleId valueobj.RoleId) *list.Element {
+	for elem := g.roles.Front(); elem != nil; elem = elem.Next() {
+		assignedRoleId, _ := elem.Value.(valueobj.RoleId)
+		if assignedRoleId.Equal(roleId) {
+			return elem
+		}
+	}
+	return nil
+}
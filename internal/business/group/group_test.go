@@ -0,0 +1,87 @@
+package group
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/umalmyha/authsrv/internal/business/role"
+	"github.com/umalmyha/authsrv/internal/business/user"
+)
+
+const (
+	success = "\u2713"
+	failed  = "\u2717"
+)
+
+func TestGroupMembersAndRoles(t *testing.T) {
+	users := map[string]user.UserDto{
+		"alice": {Id: "7c2f0b5d-3a4e-4f7b-8c8d-9eaf10b2c301", Username: "alice"},
+	}
+	userFinderFn := func(name string) (user.UserDto, error) { return users[name], nil }
+
+	roles := map[string]role.RoleDto{
+		"editor": {Id: "7c2f0b5d-3a4e-4f7b-8c8d-9eaf10b2c311", Name: "editor"},
+	}
+	roleFinderFn := func(name string) (role.RoleDto, error) { return roles[name], nil }
+
+	newGroup := func() *Group {
+		g, err := fromDbDtos(GroupDto{Id: "7c2f0b5d-3a4e-4f7b-8c8d-9eaf10b2c321", Name: "editors"}, nil, nil)
+		if err != nil {
+			t.Fatalf("\t%s\tUnexpected error on group creation: %v", failed, err)
+		}
+		return g
+	}
+
+	t.Log("Given the need to test group membership and role assignment")
+	{
+		t.Logf("\tTest 1:\tWhen member is added twice")
+		{
+			g := newGroup()
+			if err := g.AddMember("alice", userFinderFn); err != nil {
+				t.Fatalf("\t%s\tUnexpected error on member addition: %v", failed, err)
+			}
+
+			if err := g.AddMember("alice", userFinderFn); !errors.Is(err, MemberExistsErr) {
+				t.Fatalf("\t%s\tDuplicate member must be rejected, got %v", failed, err)
+			}
+			t.Logf("\t%s\tDuplicate member must be rejected", success)
+		}
+
+		t.Logf("\tTest 2:\tWhen user who isn't member is removed")
+		{
+			if err := newGroup().RemoveMember("alice", userFinderFn); !errors.Is(err, NotMemberErr) {
+				t.Fatalf("\t%s\tRemoval must be rejected, got %v", failed, err)
+			}
+			t.Logf("\t%s\tRemoval must be rejected", success)
+		}
+
+		t.Logf("\tTest 3:\tWhen role is assigned and unassigned")
+		{
+			g := newGroup()
+			if err := g.AssignRole("editor", roleFinderFn); err != nil {
+				t.Fatalf("\t%s\tRole must be assigned, got %v", failed, err)
+			}
+
+			if assigned := g.RolesDto(); len(assigned) != 1 || assigned[0].RoleId != roles["editor"].Id {
+				t.Fatalf("\t%s\tRole must be assigned to group, got %+v", failed, assigned)
+			}
+
+			if err := g.AssignRole("editor", roleFinderFn); err == nil {
+				t.Fatalf("\t%s\tDuplicate assignment must be rejected", failed)
+			}
+
+			if err := g.UnassignRole("editor", roleFinderFn); err != nil || len(g.RolesDto()) != 0 {
+				t.Fatalf("\t%s\tRole must be unassigned, got %v", failed, err)
+			}
+			t.Logf("\t%s\tRole must be assigned to group once and unassigned", success)
+		}
+
+		t.Logf("\tTest 4:\tWhen unknown role is assigned")
+		{
+			if err := newGroup().AssignRole("viewer", roleFinderFn); err == nil {
+				t.Fatalf("\t%s\tAssignment of unknown role must be rejected", failed)
+			}
+			t.Logf("\t%s\tAssignment of unknown role must be rejected", success)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package group
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+type Repository struct {
+	uow *unitOfWork
+}
+
+func NewRepository(u *unitOfWork) *Repository {
+	return &Repository{
+		uow: u,
+	}
+}
+
+func (repo *Repository) Add(g *Group) error {
+	return repo.uow.RegisterNew(g)
+}
+
+func (repo *Repository) Update(g *Group) error {
+	return repo.uow.RegisterAmended(g)
+}
+
+// Remove deletes group, its memberships and roles assigned to it
+func (repo *Repository) Remove(g *Group) error {
+	return repo.uow.RegisterDeleted(g)
+}
+
+func (repo *Repository) FindByName(ctx context.Context, name string) (*Group, error) {
+	notPresentFn := func() (GroupDto, error) {
+		return NewGroupDao(repo.uow.ExtContext()).FindByName(ctx, name)
+	}
+
+	matchFn := func(dto GroupDto) bool {
+		return dto.Name == name
+	}
+
+	g, err := repo.uow.groups.Find(matchFn).IfNotPresent(notPresentFn)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read group aggregate by name")
+	}
+
+	if !g.IsPresent() {
+		return nil, fmt.Errorf("group %s doesn't exist", name)
+	}
+
+	return repo.loadGroup(ctx, g)
+}
+
+func (repo *Repository) loadGroup(ctx context.Context, g GroupDto) (*Group, error) {
+	members, err := NewMemberDao(repo.uow.ExtContext()).FindAllForGroup(ctx, g.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	roles, err := NewRoleAssignmentDao(repo.uow.ExtContext()).FindAllForGroup(ctx, g.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	grp, err := fromDbDtos(g, members, roles)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build group aggregate from db DTOs")
+	}
+
+	return grp, repo.uow.RegisterClean(grp)
+}
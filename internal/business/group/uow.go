@@ -0,0 +1,182 @@
+package group
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	"github.com/umalmyha/authsrv/pkg/ddd/uow"
+	"github.com/umalmyha/authsrv/pkg/helpers"
+)
+
+type unitOfWork struct {
+	*uow.SqlxUnitOfWork
+	groups  *uow.ChangeSet[GroupDto]
+	members *uow.ChangeSet[MemberDto]
+	roles   *uow.ChangeSet[RoleAssignmentDto]
+}
+
+func NewUnitOfWork(db *sqlx.DB) *unitOfWork {
+	return &unitOfWork{
+		SqlxUnitOfWork: uow.NewSqlxUnitOfWork(db),
+		groups:         uow.NewChangeSet[GroupDto](),
+		members:        uow.NewChangeSet[MemberDto](),
+		roles:          uow.NewChangeSet[RoleAssignmentDto](),
+	}
+}
+
+func (uow *unitOfWork) RegisterClean(g *Group) error {
+	uow.groups.Attach(g.ToDto())
+	uow.members.AttachRange(g.MembersDto()...)
+	uow.roles.AttachRange(g.RolesDto()...)
+	return nil
+}
+
+func (uow *unitOfWork) RegisterNew(g *Group) error {
+	if err := uow.groups.Add(g.ToDto()); err != nil {
+		return errors.Wrap(err, "failed to add group DTO to changeset")
+	}
+
+	if err := uow.members.AddRange(g.MembersDto()...); err != nil {
+		return errors.Wrap(err, "failed to add group members DTOs to changeset")
+	}
+
+	if err := uow.roles.AddRange(g.RolesDto()...); err != nil {
+		return errors.Wrap(err, "failed to add group roles DTOs to changeset")
+	}
+
+	return nil
+}
+
+// RegisterDeleted deletes group together with its members and roles assigned to it
+func (uow *unitOfWork) RegisterDeleted(g *Group) error {
+	if err := uow.groups.Remove(g.ToDto()); err != nil {
+		return errors.Wrap(err, "failed to delete group DTO in changeset")
+	}
+
+	if err := uow.members.RemoveRange(g.MembersDto()...); err != nil {
+		return errors.Wrap(err, "failed to delete group members DTOs in changeset")
+	}
+
+	if err := uow.roles.RemoveRange(g.RolesDto()...); err != nil {
+		return errors.Wrap(err, "failed to delete group roles DTOs in changeset")
+	}
+
+	return nil
+}
+
+func (uow *unitOfWork) RegisterAmended(g *Group) error {
+	groupDto := g.ToDto()
+	if err := uow.groups.Update(groupDto); err != nil {
+		return errors.Wrap(err, "failed to update group DTO in changeset")
+	}
+
+	createdMembers, _, deletedMembers := uow.members.DeltaWithMatched(g.MembersDto(), func(member MemberDto) bool {
+		return member.GroupId == groupDto.Id
+	})
+
+	if err := uow.members.AddRange(createdMembers...); err != nil {
+		return errors.Wrap(err, "failed to add group members DTOs to changeset")
+	}
+
+	if err := uow.members.RemoveRange(deletedMembers...); err != nil {
+		return errors.Wrap(err, "failed to delete group members DTOs in changeset")
+	}
+
+	createdRoles, _, deletedRoles := uow.roles.DeltaWithMatched(g.RolesDto(), func(assigned RoleAssignmentDto) bool {
+		return assigned.GroupId == groupDto.Id
+	})
+
+	if err := uow.roles.AddRange(createdRoles...); err != nil {
+		return errors.Wrap(err, "failed to add group roles DTOs to changeset")
+	}
+
+	if err := uow.roles.RemoveRange(deletedRoles...); err != nil {
+		return errors.Wrap(err, "failed to delete group roles DTOs in changeset")
+	}
+
+	return nil
+}
+
+func (uow *unitOfWork) Flush(ctx context.Context) error {
+	tx, err := uow.Tx(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to open transaction")
+	}
+	defer tx.Rollback()
+
+	groupDao := NewGroupDao(tx)
+	memberDao := NewMemberDao(tx)
+	roleDao := NewRoleAssignmentDao(tx)
+
+	if rmRoles := uow.roles.Deleted(); len(rmRoles) > 0 {
+		rolesGroup := helpers.GroupBy(rmRoles, func(assigned RoleAssignmentDto, _ int, _ []RoleAssignmentDto) (string, string) {
+			return assigned.GroupId, assigned.RoleId
+		})
+
+		for groupId, roleIds := range rolesGroup {
+			if err := roleDao.DeleteByGroupIdAndRoleIdsIn(ctx, groupId, roleIds); err != nil {
+				return errors.Wrap(err, "failed to process group roles deletion")
+			}
+		}
+	}
+
+	if rmMembers := uow.members.Deleted(); len(rmMembers) > 0 {
+		membersGroup := helpers.GroupBy(rmMembers, func(member MemberDto, _ int, _ []MemberDto) (string, string) {
+			return member.GroupId, member.UserId
+		})
+
+		for groupId, userIds := range membersGroup {
+			if err := memberDao.DeleteByGroupIdAndUserIdsIn(ctx, groupId, userIds); err != nil {
+				return errors.Wrap(err, "failed to process group members deletion")
+			}
+		}
+	}
+
+	if rmGroups := uow.groups.Deleted(); len(rmGroups) > 0 {
+		mapper := func(g GroupDto, _ int, _ []GroupDto) string {
+			return g.Id
+		}
+		if err := groupDao.DeleteWhereIdsIn(ctx, helpers.Map(rmGroups, mapper)); err != nil {
+			return errors.Wrap(err, "failed to process groups deletion")
+		}
+	}
+
+	if createdGroups := uow.groups.Created(); len(createdGroups) > 0 {
+		if err := groupDao.CreateMulti(ctx, createdGroups); err != nil {
+			return errors.Wrap(err, "failed to process groups creation")
+		}
+	}
+
+	if createdMembers := uow.members.Created(); len(createdMembers) > 0 {
+		if err := memberDao.CreateMulti(ctx, createdMembers); err != nil {
+			return errors.Wrap(err, "failed to process group members creation")
+		}
+	}
+
+	if createdRoles := uow.roles.Created(); len(createdRoles) > 0 {
+		if err := roleDao.CreateMulti(ctx, createdRoles); err != nil {
+			return errors.Wrap(err, "failed to process group roles creation")
+		}
+	}
+
+	if updatedGroups := uow.groups.Updated(); len(updatedGroups) > 0 {
+		for _, updGroup := range updatedGroups {
+			if err := groupDao.Update(ctx, updGroup); err != nil {
+				return errors.Wrap(err, "failed to process groups update")
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "failed to commit transaction")
+	}
+	return uow.Dispose()
+}
+
+func (uow *unitOfWork) Dispose() error {
+	uow.groups.Cleanup()
+	uow.members.Cleanup()
+	uow.roles.Cleanup()
+	return nil
+}
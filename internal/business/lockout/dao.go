@@ -0,0 +1,88 @@
+package lockout
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+	dbredis "github.com/umalmyha/authsrv/pkg/database/redis"
+)
+
+const (
+	failuresKeyPrefix = "signin_failures:"
+	blockKeyPrefix    = "signin_block:"
+)
+
+type AttemptDao struct {
+	*dbredis.Store
+}
+
+func NewAttemptDao(rdb *redis.Client) *AttemptDao {
+	return &AttemptDao{
+		Store: dbredis.NewStore(rdb),
+	}
+}
+
+// RetryAfter returns how long subject must wait before next attempt, zero means attempt is allowed
+func (dao *AttemptDao) RetryAfter(ctx context.Context, subject string) (time.Duration, error) {
+	ttl, err := dao.Client().PTTL(ctx, blockKey(subject)).Result()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to read sign in block")
+	}
+
+	// negative ttl signals missing key or key without expiration, block is always set with expiration
+	if ttl < 0 {
+		return 0, nil
+	}
+	return ttl, nil
+}
+
+// RecordFailure increments failures counter, counter expires once no failures happen within window
+func (dao *AttemptDao) RecordFailure(ctx context.Context, subject string, window time.Duration) (int, error) {
+	var incr *redis.IntCmd
+
+	_, err := dao.Client().TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		incr = pipe.Incr(ctx, failuresKey(subject))
+		pipe.PExpire(ctx, failuresKey(subject), window)
+		return nil
+	})
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to record sign in failure")
+	}
+	return int(incr.Val()), nil
+}
+
+func (dao *AttemptDao) Block(ctx context.Context, subject string, duration time.Duration) error {
+	if duration <= 0 {
+		return nil
+	}
+
+	if err := dao.Client().Set(ctx, blockKey(subject), 1, duration).Err(); err != nil {
+		return errors.Wrap(err, "failed to block sign in")
+	}
+	return nil
+}
+
+func (dao *AttemptDao) Reset(ctx context.Context, subject string) error {
+	if err := dao.Client().Del(ctx, failuresKey(subject), blockKey(subject)).Err(); err != nil {
+		return errors.Wrap(err, "failed to reset sign in failures")
+	}
+	return nil
+}
+
+// subjects are stored by hash, so usernames and addresses don't appear in keys
+func failuresKey(subject string) string {
+	return failuresKeyPrefix + hashSubject(subject)
+}
+
+func blockKey(subject string) string {
+	return blockKeyPrefix + hashSubject(subject)
+}
+
+func hashSubject(subject string) string {
+	sum := sha256.Sum256([]byte(subject))
+	return hex.EncodeToString(sum[:])
+}
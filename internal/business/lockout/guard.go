@@ -0,0 +1,115 @@
+package lockout
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	valueobj "github.com/umalmyha/authsrv/internal/business/value-object"
+)
+
+// ThrottledErr is returned when sign in attempt is rejected before credentials are checked,
+// it is the same for existing and unknown usernames
+type ThrottledErr struct {
+	retryAfter time.Duration
+}
+
+func NewThrottledErr(retryAfter time.Duration) *ThrottledErr {
+	return &ThrottledErr{retryAfter: retryAfter}
+}
+
+func (e *ThrottledErr) Error() string {
+	return "too many failed sign in attempts, try again later"
+}
+
+func (e *ThrottledErr) RetryAfter() time.Duration {
+	return e.retryAfter
+}
+
+// Guard counts failed sign in attempts per account and per ip address, account is id of existing user
+// or login which didn't match any user
+type Guard struct {
+	dao *AttemptDao
+	cfg valueobj.LockoutConfig
+}
+
+func NewGuard(rdb *redis.Client, cfg valueobj.LockoutConfig) *Guard {
+	return &Guard{
+		dao: NewAttemptDao(rdb),
+		cfg: cfg,
+	}
+}
+
+// Check returns ThrottledErr if account or ip must wait before next attempt
+func (g *Guard) Check(ctx context.Context, account string, ip string) error {
+	for _, subject := range subjects(account, ip) {
+		retryAfter, err := g.dao.RetryAfter(ctx, subject)
+		if err != nil {
+			return err
+		}
+
+		if retryAfter > 0 {
+			return NewThrottledErr(retryAfter)
+		}
+	}
+	return nil
+}
+
+// Fail records failed attempt and delays next one, returned duration is positive if account must be locked
+func (g *Guard) Fail(ctx context.Context, account string, ip string) (time.Duration, error) {
+	failures, err := g.dao.RecordFailure(ctx, AccountSubject(account), g.cfg.Window())
+	if err != nil {
+		return 0, err
+	}
+
+	lockFor := time.Duration(0)
+	delay := g.cfg.Backoff(failures)
+	if failures >= g.cfg.MaxFailures() {
+		lockFor = g.cfg.LockDuration()
+		delay = lockFor
+	}
+
+	if err := g.dao.Block(ctx, AccountSubject(account), delay); err != nil {
+		return 0, err
+	}
+
+	if ip != "" {
+		ipFailures, err := g.dao.RecordFailure(ctx, IpSubject(ip), g.cfg.Window())
+		if err != nil {
+			return 0, err
+		}
+
+		// ip is shared by many users behind NAT, so it is blocked only after much more failures and without backoff
+		if ipFailures >= g.cfg.IpMaxFailures() {
+			if err := g.dao.Block(ctx, IpSubject(ip), g.cfg.Window()); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	return lockFor, nil
+}
+
+// Reset forgets failures of account, it is called on successful sign in and on unlock by administrator.
+// Ip failures are kept, so attacker can't reset them by signing in to own account.
+func (g *Guard) Reset(ctx context.Context, account string) error {
+	return g.dao.Reset(ctx, AccountSubject(account))
+}
+
+func subjects(account string, ip string) []string {
+	if ip == "" {
+		return []string{AccountSubject(account)}
+	}
+	return []string{AccountSubject(account), IpSubject(ip)}
+}
+
+// AccountSubject identifies failures counter of account
+func AccountSubject(account string) string {
+	return "user:" + account
+}
+
+// IpSubject identifies failures counter of ip address
+func IpSubject(ip string) string {
+	return "ip:" + ip
+}
@@ -0,0 +1,105 @@
+package mfa
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"time"
+
+	pkgerrors "github.com/pkg/errors"
+	"github.com/umalmyha/authsrv/internal/business/refresh"
+	valueobj "github.com/umalmyha/authsrv/internal/business/value-object"
+	"github.com/umalmyha/authsrv/pkg/errors"
+)
+
+const tokenLength = 32
+
+var ChallengeExpiredErr = errors.NewBusinessErr(
+	"challengeToken",
+	"mfa challenge already expired, sign in again",
+	errors.ViolationSeverityErr,
+	"MFA_CHALLENGE_EXPIRED",
+)
+
+// Challenge is issued when password is verified for user with enabled MFA, sign in is completed
+// by presenting challenge token together with second factor
+type Challenge struct {
+	token        string
+	userId       string
+	fingerprint  string
+	organization string
+	client       refresh.ClientInfo
+	attemptsLeft int
+	expiresAt    time.Time
+}
+
+// NewChallenge starts second sign in step, session is issued for organization once challenge is passed
+func NewChallenge(userId string, fingerprint string, organization string, client refresh.ClientInfo, issuedAt time.Time, cfg valueobj.MfaConfig) (*Challenge, error) {
+	if userId == "" {
+		return nil, pkgerrors.New("user is mandatory for mfa challenge")
+	}
+
+	token := make([]byte, tokenLength)
+	if _, err := rand.Read(token); err != nil {
+		return nil, pkgerrors.Wrap(err, "failed to generate mfa challenge token")
+	}
+
+	return &Challenge{
+		token:        base64.RawURLEncoding.EncodeToString(token),
+		userId:       userId,
+		fingerprint:  fingerprint,
+		organization: organization,
+		client:       client,
+		attemptsLeft: cfg.MaxAttempts(),
+		expiresAt:    issuedAt.Add(cfg.ChallengeTimeToLive()),
+	}, nil
+}
+
+// Token returns plain token value, it is available only for newly created challenges
+func (c *Challenge) Token() string {
+	return c.token
+}
+
+func (c *Challenge) UserId() string {
+	return c.userId
+}
+
+func (c *Challenge) Fingerprint() string {
+	return c.fingerprint
+}
+
+func (c *Challenge) Organization() string {
+	return c.organization
+}
+
+func (c *Challenge) Client() refresh.ClientInfo {
+	return c.client
+}
+
+func (c *Challenge) ExpiresAt() time.Time {
+	return c.expiresAt
+}
+
+func (c *Challenge) VerifyNotExpired(now time.Time) error {
+	if c.expiresAt.Before(now) {
+		return ChallengeExpiredErr
+	}
+	return nil
+}
+
+// Fail registers failed attempt and reports if challenge can be used once more
+func (c *Challenge) Fail() bool {
+	c.attemptsLeft--
+	return c.attemptsLeft > 0
+}
+
+func (c *Challenge) Dto() ChallengeDto {
+	return ChallengeDto{
+		UserId:       c.userId,
+		Fingerprint:  c.fingerprint,
+		Organization: c.organization,
+		IpAddress:    c.client.IpAddress,
+		UserAgent:    c.client.UserAgent,
+		AttemptsLeft: c.attemptsLeft,
+		ExpiresAt:    c.expiresAt,
+	}
+}
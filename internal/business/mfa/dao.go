@@ -0,0 +1,65 @@
+package mfa
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+	dbredis "github.com/umalmyha/authsrv/pkg/database/redis"
+)
+
+const keyPrefix = "mfa_challenge:"
+
+type ChallengeDao struct {
+	*dbredis.Store
+}
+
+func NewChallengeDao(rdb *redis.Client) *ChallengeDao {
+	return &ChallengeDao{
+		Store: dbredis.NewStore(rdb),
+	}
+}
+
+func (dao *ChallengeDao) Save(ctx context.Context, token string, dto ChallengeDto) error {
+	encoded, err := dbredis.EncodeGob(dto)
+	if err != nil {
+		return errors.Wrap(err, "failed to serialize mfa challenge to gob format")
+	}
+
+	ttl := time.Until(dto.ExpiresAt)
+	if ttl <= 0 {
+		return errors.New("mfa challenge is already expired")
+	}
+
+	if err := dao.Client().Set(ctx, challengeKey(token), encoded, ttl).Err(); err != nil {
+		return errors.Wrap(err, "failed to save mfa challenge")
+	}
+	return nil
+}
+
+// Consume reads challenge and removes it atomically, so concurrent attempts can't exceed attempts limit
+func (dao *ChallengeDao) Consume(ctx context.Context, token string) (ChallengeDto, error) {
+	var dto ChallengeDto
+
+	encoded, err := dao.Client().GetDel(ctx, challengeKey(token)).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return dto, nil
+		}
+		return dto, errors.Wrap(err, "failed to read mfa challenge")
+	}
+
+	if err := dbredis.DecodeGob([]byte(encoded), &dto); err != nil {
+		return dto, errors.Wrap(err, "failed to deserialize mfa challenge from gob format")
+	}
+	return dto, nil
+}
+
+// tokens are stored by hash, so plain values never reach the storage
+func challengeKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return keyPrefix + hex.EncodeToString(sum[:])
+}
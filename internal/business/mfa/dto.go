@@ -0,0 +1,32 @@
+package mfa
+
+import (
+	"time"
+
+	"github.com/umalmyha/authsrv/internal/business/refresh"
+)
+
+type ChallengeDto struct {
+	UserId       string
+	Fingerprint  string
+	Organization string
+	IpAddress    string
+	UserAgent    string
+	AttemptsLeft int
+	ExpiresAt    time.Time
+}
+
+func (dto ChallengeDto) IsPresent() bool {
+	return dto.UserId != ""
+}
+
+func (dto ChallengeDto) ToChallenge() *Challenge {
+	return &Challenge{
+		userId:       dto.UserId,
+		fingerprint:  dto.Fingerprint,
+		organization: dto.Organization,
+		client:       refresh.ClientInfo{IpAddress: dto.IpAddress, UserAgent: dto.UserAgent},
+		attemptsLeft: dto.AttemptsLeft,
+		expiresAt:    dto.ExpiresAt,
+	}
+}
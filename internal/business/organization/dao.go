@@ -0,0 +1,210 @@
+package organization
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	"github.com/umalmyha/authsrv/pkg/database/rdb"
+)
+
+type OrganizationDao struct {
+	ec sqlx.ExtContext
+}
+
+func NewOrganizationDao(ec sqlx.ExtContext) *OrganizationDao {
+	return &OrganizationDao{
+		ec: ec,
+	}
+}
+
+func (dao *OrganizationDao) CreateMulti(ctx context.Context, orgs []OrganizationDto) error {
+	applier := func(org OrganizationDto) []any {
+		return []any{org.Id, org.Name, org.Description}
+	}
+
+	q, params, err := rdb.BulkInsertQuery("ORGANIZATIONS", []string{"ID", "NAME", "DESCRIPTION"}, orgs, applier)
+	if err != nil {
+		return errors.Wrap(err, "failed to build bulk insert SQL query for organizations creation")
+	}
+
+	if _, err := dao.ec.ExecContext(ctx, q, params...); err != nil {
+		return errors.Wrap(err, "failed to create organizations")
+	}
+
+	return nil
+}
+
+func (dao *OrganizationDao) DeleteWhereIdsIn(ctx context.Context, ids []string) error {
+	inRange, params, err := rdb.WhereIn(ids)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate SQL where clause for organizations deletion")
+	}
+
+	q := fmt.Sprintf("DELETE FROM ORGANIZATIONS WHERE ID IN %s", inRange)
+	if _, err := dao.ec.ExecContext(ctx, q, params...); err != nil {
+		return errors.Wrap(err, "failed to delete organizations")
+	}
+
+	return nil
+}
+
+func (dao *OrganizationDao) Update(ctx context.Context, org OrganizationDto) error {
+	q := "UPDATE ORGANIZATIONS SET DESCRIPTION = $1 WHERE ID = $2"
+	if _, err := dao.ec.ExecContext(ctx, q, org.Description, org.Id); err != nil {
+		return errors.Wrap(err, "failed to update organization")
+	}
+	return nil
+}
+
+func (dao *OrganizationDao) FindByName(ctx context.Context, name string) (OrganizationDto, error) {
+	var org OrganizationDto
+	q := "SELECT ID, NAME, DESCRIPTION FROM ORGANIZATIONS WHERE NAME = $1"
+	if err := sqlx.GetContext(ctx, dao.ec, &org, q, name); err != nil {
+		return org, errors.Wrap(err, "failed to find organization by name")
+	}
+	return org, nil
+}
+
+func (dao *OrganizationDao) FindAll(ctx context.Context) ([]OrganizationDto, error) {
+	orgs := make([]OrganizationDto, 0)
+	q := "SELECT ID, NAME, DESCRIPTION FROM ORGANIZATIONS ORDER BY NAME"
+	if err := sqlx.SelectContext(ctx, dao.ec, &orgs, q); err != nil {
+		return nil, errors.Wrap(err, "failed to read organizations")
+	}
+	return orgs, nil
+}
+
+// FindAllForUser reads organizations user is member of
+func (dao *OrganizationDao) FindAllForUser(ctx context.Context, userId string) ([]OrganizationDto, error) {
+	orgs := make([]OrganizationDto, 0)
+	q := `SELECT O.ID, O.NAME, O.DESCRIPTION FROM ORGANIZATIONS AS O
+		INNER JOIN ORGANIZATION_MEMBERS AS OM ON OM.ORGANIZATION_ID = O.ID
+		WHERE OM.USER_ID = $1 ORDER BY O.NAME`
+	if err := sqlx.SelectContext(ctx, dao.ec, &orgs, q, userId); err != nil {
+		return nil, errors.Wrap(err, "failed to read organizations of user")
+	}
+	return orgs, nil
+}
+
+type MemberDao struct {
+	ec sqlx.ExtContext
+}
+
+func NewMemberDao(ec sqlx.ExtContext) *MemberDao {
+	return &MemberDao{
+		ec: ec,
+	}
+}
+
+func (dao *MemberDao) CreateMulti(ctx context.Context, members []MemberDto) error {
+	applier := func(member MemberDto) []any {
+		return []any{member.OrganizationId, member.UserId}
+	}
+
+	q, params, err := rdb.BulkInsertQuery("ORGANIZATION_MEMBERS", []string{"ORGANIZATION_ID", "USER_ID"}, members, applier)
+	if err != nil {
+		return errors.Wrap(err, "failed to build bulk insert SQL query for members creation")
+	}
+
+	if _, err := dao.ec.ExecContext(ctx, q, params...); err != nil {
+		return errors.Wrap(err, "failed to create members")
+	}
+
+	return nil
+}
+
+func (dao *MemberDao) FindAllForOrganization(ctx context.Context, orgId string) ([]MemberDto, error) {
+	members := make([]MemberDto, 0)
+	q := "SELECT ORGANIZATION_ID, USER_ID FROM ORGANIZATION_MEMBERS WHERE ORGANIZATION_ID = $1"
+	if err := sqlx.SelectContext(ctx, dao.ec, &members, q, orgId); err != nil {
+		return nil, errors.Wrap(err, "failed to read members of organization")
+	}
+	return members, nil
+}
+
+// FindNamesForOrganization reads usernames of members together with names of roles assigned in organization
+func (dao *MemberDao) FindNamesForOrganization(ctx context.Context, orgId string) ([]MemberNameDto, error) {
+	members := make([]MemberNameDto, 0)
+	q := `SELECT U.USERNAME, COALESCE(R.NAME, '') AS ROLE_NAME FROM ORGANIZATION_MEMBERS AS OM
+		INNER JOIN USERS AS U ON U.ID = OM.USER_ID
+		LEFT JOIN ORGANIZATION_USER_ROLES AS OUR ON OUR.ORGANIZATION_ID = OM.ORGANIZATION_ID AND OUR.USER_ID = OM.USER_ID
+		LEFT JOIN ROLES AS R ON R.ID = OUR.ROLE_ID
+		WHERE OM.ORGANIZATION_ID = $1 ORDER BY U.USERNAME, R.NAME`
+	if err := sqlx.SelectContext(ctx, dao.ec, &members, q, orgId); err != nil {
+		return nil, errors.Wrap(err, "failed to read member names of organization")
+	}
+	return members, nil
+}
+
+func (dao *MemberDao) DeleteByOrganizationIdAndUserIdsIn(ctx context.Context, orgId string, userIds []string) error {
+	inRange, params, err := rdb.WhereIn(userIds)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate SQL where clause for members deletion")
+	}
+
+	params = append(params, orgId)
+	q := fmt.Sprintf("DELETE FROM ORGANIZATION_MEMBERS WHERE USER_ID IN %s AND ORGANIZATION_ID = $%d", inRange, len(params))
+	if _, err := dao.ec.ExecContext(ctx, q, params...); err != nil {
+		return errors.Wrap(err, "failed to delete members")
+	}
+
+	return nil
+}
+
+type MemberRoleDao struct {
+	ec sqlx.ExtContext
+}
+
+func NewMemberRoleDao(ec sqlx.ExtContext) *MemberRoleDao {
+	return &MemberRoleDao{
+		ec: ec,
+	}
+}
+
+func (dao *MemberRoleDao) CreateMulti(ctx context.Context, roles []MemberRoleDto) error {
+	applier := func(assigned MemberRoleDto) []any {
+		return []any{assigned.OrganizationId, assigned.UserId, assigned.RoleId}
+	}
+
+	q, params, err := rdb.BulkInsertQuery("ORGANIZATION_USER_ROLES", []string{"ORGANIZATION_ID", "USER_ID", "ROLE_ID"}, roles, applier)
+	if err != nil {
+		return errors.Wrap(err, "failed to build bulk insert SQL query for member roles creation")
+	}
+
+	if _, err := dao.ec.ExecContext(ctx, q, params...); err != nil {
+		return errors.Wrap(err, "failed to create member roles")
+	}
+
+	return nil
+}
+
+func (dao *MemberRoleDao) FindAllForOrganization(ctx context.Context, orgId string) ([]MemberRoleDto, error) {
+	roles := make([]MemberRoleDto, 0)
+	q := "SELECT ORGANIZATION_ID, USER_ID, ROLE_ID FROM ORGANIZATION_USER_ROLES WHERE ORGANIZATION_ID = $1"
+	if err := sqlx.SelectContext(ctx, dao.ec, &roles, q, orgId); err != nil {
+		return nil, errors.Wrap(err, "failed to read member roles of organization")
+	}
+	return roles, nil
+}
+
+func (dao *MemberRoleDao) DeleteByOrganizationIdAndUserIdAndRoleIdsIn(ctx context.Context, orgId string, userId string, roleIds []string) error {
+	inRange, params, err := rdb.WhereIn(roleIds)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate SQL where clause for member roles deletion")
+	}
+
+	params = append(params, orgId, userId)
+	q := fmt.Sprintf(
+		"DELETE FROM ORGANIZATION_USER_ROLES WHERE ROLE_ID IN %s AND ORGANIZATION_ID = $%d AND USER_ID = $%d",
+		inRange,
+		len(params)-1,
+		len(params),
+	)
+	if _, err := dao.ec.ExecContext(ctx, q, params...); err != nil {
+		return errors.Wrap(err, "failed to delete member roles")
+	}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+package organization
+
+import (
+	"fmt"
+
+	"github.com/umalmyha/authsrv/pkg/helpers"
+)
+
+type OrganizationDto struct {
+	Id          string  `db:"id" json:"id"`
+	Name        string  `db:"name" json:"name"`
+	Description *string `db:"description" json:"description"`
+}
+
+func (dto OrganizationDto) Key() string {
+	return dto.Id
+}
+
+func (dto OrganizationDto) IsPresent() bool {
+	return dto.Id != ""
+}
+
+func (dto OrganizationDto) Equal(other OrganizationDto) bool {
+	return dto.Name == other.Name && helpers.EqualValues(dto.Description, other.Description)
+}
+
+func (dto OrganizationDto) Clone() OrganizationDto {
+	return OrganizationDto{
+		Id:          dto.Id,
+		Name:        dto.Name,
+		Description: helpers.CopyValue(dto.Description),
+	}
+}
+
+// MemberDto links user to organization
+type MemberDto struct {
+	OrganizationId string `db:"organization_id"`
+	UserId         string `db:"user_id"`
+}
+
+func (dto MemberDto) Key() string {
+	return fmt.Sprintf("%s-%s", dto.OrganizationId, dto.UserId)
+}
+
+func (dto MemberDto) IsPresent() bool {
+	return dto.OrganizationId != "" && dto.UserId != ""
+}
+
+func (dto MemberDto) Equal(other MemberDto) bool {
+	return dto.OrganizationId == other.OrganizationId && dto.UserId == other.UserId
+}
+
+func (dto MemberDto) Clone() MemberDto {
+	return dto
+}
+
+// MemberRoleDto is role assigned to member within organization
+type MemberRoleDto struct {
+	OrganizationId string `db:"organization_id"`
+	UserId         string `db:"user_id"`
+	RoleId         string `db:"role_id"`
+}
+
+func (dto MemberRoleDto) Key() string {
+	return fmt.Sprintf("%s-%s-%s", dto.OrganizationId, dto.UserId, dto.RoleId)
+}
+
+func (dto MemberRoleDto) IsPresent() bool {
+	return dto.OrganizationId != "" && dto.UserId != "" && dto.RoleId != ""
+}
+
+func (dto MemberRoleDto) Equal(other MemberRoleDto) bool {
+	return dto.OrganizationId == other.OrganizationId && dto.UserId == other.UserId && dto.RoleId == other.RoleId
+}
+
+func (dto MemberRoleDto) Clone() MemberRoleDto {
+	return dto
+}
+
+type NewOrganizationDto struct {
+	Name        string  `json:"name"`
+	Description *string `json:"description"`
+}
+
+// MemberNameDto is member username with name of role assigned in organization, role is empty for member without roles
+type MemberNameDto struct {
+	Username string `db:"username"`
+	RoleName string `db:"role_name"`
+}
+
+// MemberDetailsDto is member of organization with names of roles assigned within it
+type MemberDetailsDto struct {
+	Username string   `json:"username"`
+	Roles    []string `json:"roles"`
+}
@@ -0,0 +1,84 @@
+package organization
+
+import (
+	"container/list"
+	"fmt"
+
+	"github.com/google/uuid"
+	pkgerrors "github.com/pkg/errors"
+	valueobj "github.com/umalmyha/authsrv/internal/business/value-object"
+	"github.com/umalmyha/authsrv/pkg/errors"
+	"github.com/umalmyha/authsrv/pkg/helpers"
+)
+
+type isExistingOrganizationNameFn func(string) (bool, error)
+
+func FromNewOrganizationDto(dto NewOrganizationDto, existFn isExistingOrganizationNameFn) (*Organization, error) {
+	validation := errors.NewValidation()
+	if dto.Name == "" {
+		validation.Add(
+			errors.NewBusinessErr("organization name can not be empty", "name", errors.ViolationSeverityErr, errors.CodeValidationFailed),
+		)
+	}
+
+	orgName, err := valueobj.NewSolidString(dto.Name)
+	if err != nil {
+		validation.Add(
+			errors.NewBusinessErr(err.Error(), "name", errors.ViolationSeverityErr, errors.CodeValidationFailed),
+		)
+	}
+
+	exist, err := existFn(dto.Name)
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "failed to check existence of organization by name")
+	} else if exist {
+		validation.Add(
+			errors.NewBusinessErr(
+				fmt.Sprintf("organization with name %s already exists", dto.Name),
+				"name",
+				errors.ViolationSeverityErr,
+				errors.CodeValidationFailed,
+			),
+		)
+	}
+
+	if validation.HasError() {
+		return nil, pkgerrors.Wrap(validation.RaiseValidationErr(errors.ViolationSeverityErr), "validation failed for organization creation")
+	}
+
+	return &Organization{
+		id:          uuid.NewString(),
+		name:        orgName,
+		description: valueobj.NewNilStringFromPtr(dto.Description),
+		members:     list.New(),
+		roles:       list.New(),
+	}, nil
+}
+
+func fromDbDtos(orgDto OrganizationDto, membersDto []MemberDto, rolesDto []MemberRoleDto) (*Organization, error) {
+	name, err := valueobj.NewSolidString(orgDto.Name)
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "failed to build organization name from db entry")
+	}
+
+	members := helpers.Map(membersDto, func(member MemberDto, _ int, _ []MemberDto) string {
+		return member.UserId
+	})
+
+	roles := make([]memberRole, 0)
+	for _, assigned := range rolesDto {
+		roleId, err := valueobj.NewRoleId(assigned.RoleId)
+		if err != nil {
+			return nil, pkgerrors.Wrap(err, "failed to build role identifier from db entry")
+		}
+		roles = append(roles, memberRole{userId: assigned.UserId, roleId: roleId})
+	}
+
+	return &Organization{
+		id:          orgDto.Id,
+		name:        name,
+		description: valueobj.NewNilStringFromPtr(orgDto.Description),
+		members:     helpers.ToList(members),
+		roles:       helpers.ToList(roles),
+	}, nil
+}
@@ -0,0 +1,195 @@
+package organization
+
+import (
+	"container/list"
+
+	"github.com/pkg/errors"
+	"github.com/umalmyha/authsrv/internal/business/role"
+	"github.com/umalmyha/authsrv/internal/business/user"
+	valueobj "github.com/umalmyha/authsrv/internal/business/value-object"
+	"github.com/umalmyha/authsrv/pkg/helpers"
+)
+
+var (
+	MemberExistsErr = errors.New("user is already member of organization")
+	NotMemberErr    = errors.New("user is not member of organization")
+)
+
+type UserFinderByNameFn func(string) (user.UserDto, error)
+type RoleFinderByNameFn func(string) (role.RoleDto, error)
+
+type memberRole struct {
+	userId string
+	roleId valueobj.RoleId
+}
+
+// Organization is tenant users are members of, roles are defined globally but assigned to members within organization
+type Organization struct {
+	id          string
+	name        valueobj.SolidString
+	description valueobj.NilString
+	members     *list.List
+	roles       *list.List
+}
+
+func (o *Organization) Id() string {
+	return o.id
+}
+
+func (o *Organization) Name() string {
+	return o.name.String()
+}
+
+func (o *Organization) ChangeDescription(descr string) {
+	o.description = valueobj.NewNilString(descr)
+}
+
+func (o *Organization) AddMember(username string, finderFn UserFinderByNameFn) error {
+	usr, err := o.findUser(username, finderFn)
+	if err != nil {
+		return err
+	}
+
+	if o.findMemberElem(usr.Id) != nil {
+		return errors.Wrapf(MemberExistsErr, "user %s is already member of %s", username, o.name)
+	}
+
+	o.members.PushBack(usr.Id)
+	return nil
+}
+
+// RemoveMember removes user from organization together with all roles assigned to it within organization
+func (o *Organization) RemoveMember(username string, finderFn UserFinderByNameFn) error {
+	usr, err := o.findUser(username, finderFn)
+	if err != nil {
+		return err
+	}
+
+	elem := o.findMemberElem(usr.Id)
+	if elem == nil {
+		return errors.Wrapf(NotMemberErr, "user %s is not member of %s", username, o.name)
+	}
+	o.members.Remove(elem)
+
+	var next *list.Element
+	for roleElem := o.roles.Front(); roleElem != nil; roleElem = next {
+		next = roleElem.Next()
+		assigned, _ := roleElem.Value.(memberRole)
+		if assigned.userId == usr.Id {
+			o.roles.Remove(roleElem)
+		}
+	}
+	return nil
+}
+
+// AssignRole assigns role to member, role is effective only in sessions issued for organization
+func (o *Organization) AssignRole(username string, roleName string, userFinderFn UserFinderByNameFn, roleFinderFn RoleFinderByNameFn) error {
+	usr, rl, err := o.findMemberAndRole(username, roleName, userFinderFn, roleFinderFn)
+	if err != nil {
+		return err
+	}
+
+	roleIdent, err := valueobj.NewRoleId(rl.Id)
+	if err != nil {
+		return errors.Wrap(err, "failed to build role identifier")
+	}
+
+	if o.findRoleElem(usr.Id, roleIdent) != nil {
+		return errors.Errorf("role %s is already assigned to %s in %s", roleName, username, o.name)
+	}
+
+	o.roles.PushBack(memberRole{userId: usr.Id, roleId: roleIdent})
+	return nil
+}
+
+func (o *Organization) UnassignRole(username string, roleName string, userFinderFn UserFinderByNameFn, roleFinderFn RoleFinderByNameFn) error {
+	usr, rl, err := o.findMemberAndRole(username, roleName, userFinderFn, roleFinderFn)
+	if err != nil {
+		return err
+	}
+
+	roleIdent, err := valueobj.NewRoleId(rl.Id)
+	if err != nil {
+		return errors.Wrap(err, "failed to build role identifier")
+	}
+
+	elem := o.findRoleElem(usr.Id, roleIdent)
+	if elem == nil {
+		return errors.Errorf("role %s is not assigned to %s in %s", roleName, username, o.name)
+	}
+
+	o.roles.Remove(elem)
+	return nil
+}
+
+func (o *Organization) ToDto() OrganizationDto {
+	return OrganizationDto{
+		Id:          o.id,
+		Name:        o.name.String(),
+		Description: o.description.Ptr(),
+	}
+}
+
+func (o *Organization) MembersDto() []MemberDto {
+	return helpers.FromListWithReducer(o.members, func(userId string) MemberDto {
+		return MemberDto{OrganizationId: o.id, UserId: userId}
+	})
+}
+
+func (o *Organization) RolesDto() []MemberRoleDto {
+	return helpers.FromListWithReducer(o.roles, func(assigned memberRole) MemberRoleDto {
+		return MemberRoleDto{OrganizationId: o.id, UserId: assigned.userId, RoleId: assigned.roleId.String()}
+	})
+}
+
+func (o *Organization) findMemberAndRole(username string, roleName string, userFinderFn UserFinderByNameFn, roleFinderFn RoleFinderByNameFn) (user.UserDto, role.RoleDto, error) {
+	usr, err := o.findUser(username, userFinderFn)
+	if err != nil {
+		return usr, role.RoleDto{}, err
+	}
+
+	if o.findMemberElem(usr.Id) == nil {
+		return usr, role.RoleDto{}, errors.Wrapf(NotMemberErr, "user %s is not member of %s", username, o.name)
+	}
+
+	rl, err := roleFinderFn(roleName)
+	if err != nil {
+		return usr, rl, errors.Wrap(err, "failed to find role")
+	}
+
+	if !rl.IsPresent() {
+		return usr, rl, errors.Errorf("role %s doesn't exist", roleName)
+	}
+	return usr, rl, nil
+}
+
+func (o *Organization) findUser(username string, finderFn UserFinderByNameFn) (user.UserDto, error) {
+	usr, err := finderFn(username)
+	if err != nil {
+		return usr, errors.Wrap(err, "failed to find user")
+	}
+
+	if !usr.IsPresent() {
+		return usr, errors.Errorf("user %s doesn't exist", username)
+	}
+	return usr, nil
+}
+
+func (o *Organization) findMemberElem(userId string) *list.Element {
+	for elem := o.members.Front(); elem != nil; elem = elem.Next() {
+		if memberId, _ := elem.Value.(string); memberId == userId {
+			return elem
+		}
+	}
+	return nil
+}
+
+func (o *Organization) findRoleElem(userId string, roleId valueobj.RoleId) *list.Element {
+	for elem := o.roles.Front(); elem != nil; elem = elem.Next() {
+		assigned, _ := elem.Value.(memberRole)
+		if assigned.userId == userId && assigned.roleId.Equal(roleId) {
+			return elem
+		}
+	}
+	return nil
+}
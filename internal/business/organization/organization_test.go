@@ -0,0 +1,98 @@
+package organization
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/umalmyha/authsrv/internal/business/role"
+	"github.com/umalmyha/authsrv/internal/business/user"
+)
+
+const (
+	success = "\u2713"
+	failed  = "\u2717"
+)
+
+func TestOrganizationMembers(t *testing.T) {
+	users := map[string]user.UserDto{
+		"alice": {Id: "5b1e9a4c-2f3d-4e6a-9b7c-8d9e0f1a2b01", Username: "alice"},
+		"bob":   {Id: "5b1e9a4c-2f3d-4e6a-9b7c-8d9e0f1a2b02", Username: "bob"},
+	}
+	userFinderFn := func(name string) (user.UserDto, error) { return users[name], nil }
+
+	roles := map[string]role.RoleDto{
+		"editor": {Id: "5b1e9a4c-2f3d-4e6a-9b7c-8d9e0f1a2b11", Name: "editor"},
+	}
+	roleFinderFn := func(name string) (role.RoleDto, error) { return roles[name], nil }
+
+	newOrganization := func() *Organization {
+		org, err := fromDbDtos(OrganizationDto{Id: "5b1e9a4c-2f3d-4e6a-9b7c-8d9e0f1a2b21", Name: "acme"}, nil, nil)
+		if err != nil {
+			t.Fatalf("\t%s\tUnexpected error on organization creation: %v", failed, err)
+		}
+		return org
+	}
+
+	t.Log("Given the need to test organization membership")
+	{
+		t.Logf("\tTest 1:\tWhen role is assigned to member")
+		{
+			org := newOrganization()
+			if err := org.AddMember("alice", userFinderFn); err != nil {
+				t.Fatalf("\t%s\tUnexpected error on member addition: %v", failed, err)
+			}
+
+			if err := org.AssignRole("alice", "editor", userFinderFn, roleFinderFn); err != nil {
+				t.Fatalf("\t%s\tRole must be assigned, got %v", failed, err)
+			}
+
+			assigned := org.RolesDto()
+			if len(assigned) != 1 || assigned[0].UserId != users["alice"].Id || assigned[0].RoleId != roles["editor"].Id {
+				t.Fatalf("\t%s\tRole must be assigned within organization, got %+v", failed, assigned)
+			}
+			t.Logf("\t%s\tRole must be assigned within organization", success)
+		}
+
+		t.Logf("\tTest 2:\tWhen role is assigned to user who isn't member")
+		{
+			if err := newOrganization().AssignRole("bob", "editor", userFinderFn, roleFinderFn); !errors.Is(err, NotMemberErr) {
+				t.Fatalf("\t%s\tAssignment must be rejected, got %v", failed, err)
+			}
+			t.Logf("\t%s\tAssignment must be rejected", success)
+		}
+
+		t.Logf("\tTest 3:\tWhen member is added twice")
+		{
+			org := newOrganization()
+			if err := org.AddMember("bob", userFinderFn); err != nil {
+				t.Fatalf("\t%s\tUnexpected error on member addition: %v", failed, err)
+			}
+
+			if err := org.AddMember("bob", userFinderFn); !errors.Is(err, MemberExistsErr) {
+				t.Fatalf("\t%s\tDuplicate member must be rejected, got %v", failed, err)
+			}
+			t.Logf("\t%s\tDuplicate member must be rejected", success)
+		}
+
+		t.Logf("\tTest 4:\tWhen member with roles is removed")
+		{
+			org := newOrganization()
+			if err := org.AddMember("alice", userFinderFn); err != nil {
+				t.Fatalf("\t%s\tUnexpected error on member addition: %v", failed, err)
+			}
+
+			if err := org.AssignRole("alice", "editor", userFinderFn, roleFinderFn); err != nil {
+				t.Fatalf("\t%s\tUnexpected error on role assignment: %v", failed, err)
+			}
+
+			if err := org.RemoveMember("alice", userFinderFn); err != nil {
+				t.Fatalf("\t%s\tMember must be removed, got %v", failed, err)
+			}
+
+			if len(org.MembersDto()) != 0 || len(org.RolesDto()) != 0 {
+				t.Fatalf("\t%s\tMember and its roles must be removed, got %+v and %+v", failed, org.MembersDto(), org.RolesDto())
+			}
+			t.Logf("\t%s\tMember and its roles must be removed", success)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package organization
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+type Repository struct {
+	uow *unitOfWork
+}
+
+func NewRepository(u *unitOfWork) *Repository {
+	return &Repository{
+		uow: u,
+	}
+}
+
+func (repo *Repository) Add(org *Organization) error {
+	return repo.uow.RegisterNew(org)
+}
+
+func (repo *Repository) Update(org *Organization) error {
+	return repo.uow.RegisterAmended(org)
+}
+
+// Remove deletes organization, its members and roles assigned to them within organization
+func (repo *Repository) Remove(org *Organization) error {
+	return repo.uow.RegisterDeleted(org)
+}
+
+func (repo *Repository) FindByName(ctx context.Context, name string) (*Organization, error) {
+	notPresentFn := func() (OrganizationDto, error) {
+		return NewOrganizationDao(repo.uow.ExtContext()).FindByName(ctx, name)
+	}
+
+	matchFn := func(dto OrganizationDto) bool {
+		return dto.Name == name
+	}
+
+	org, err := repo.uow.organizations.Find(matchFn).IfNotPresent(notPresentFn)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read organization aggregate by name")
+	}
+
+	if !org.IsPresent() {
+		return nil, fmt.Errorf("organization %s doesn't exist", name)
+	}
+
+	return repo.loadOrganization(ctx, org)
+}
+
+func (repo *Repository) loadOrganization(ctx context.Context, org OrganizationDto) (*Organization, error) {
+	members, err := NewMemberDao(repo.uow.ExtContext()).FindAllForOrganization(ctx, org.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	roles, err := NewMemberRoleDao(repo.uow.ExtContext()).FindAllForOrganization(ctx, org.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	o, err := fromDbDtos(org, members, roles)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build organization aggregate from db DTOs")
+	}
+
+	return o, repo.uow.RegisterClean(o)
+}
@@ -0,0 +1,188 @@
+package organization
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	"github.com/umalmyha/authsrv/pkg/ddd/uow"
+	"github.com/umalmyha/authsrv/pkg/helpers"
+)
+
+type unitOfWork struct {
+	*uow.SqlxUnitOfWork
+	organizations *uow.ChangeSet[OrganizationDto]
+	members       *uow.ChangeSet[MemberDto]
+	roles         *uow.ChangeSet[MemberRoleDto]
+}
+
+func NewUnitOfWork(db *sqlx.DB) *unitOfWork {
+	return &unitOfWork{
+		SqlxUnitOfWork: uow.NewSqlxUnitOfWork(db),
+		organizations:  uow.NewChangeSet[OrganizationDto](),
+		members:        uow.NewChangeSet[MemberDto](),
+		roles:          uow.NewChangeSet[MemberRoleDto](),
+	}
+}
+
+func (uow *unitOfWork) RegisterClean(org *Organization) error {
+	uow.organizations.Attach(org.ToDto())
+	uow.members.AttachRange(org.MembersDto()...)
+	uow.roles.AttachRange(org.RolesDto()...)
+	return nil
+}
+
+func (uow *unitOfWork) RegisterNew(org *Organization) error {
+	if err := uow.organizations.Add(org.ToDto()); err != nil {
+		return errors.Wrap(err, "failed to add organization DTO to changeset")
+	}
+
+	if err := uow.members.AddRange(org.MembersDto()...); err != nil {
+		return errors.Wrap(err, "failed to add members DTOs to changeset")
+	}
+
+	if err := uow.roles.AddRange(org.RolesDto()...); err != nil {
+		return errors.Wrap(err, "failed to add member roles DTOs to changeset")
+	}
+
+	return nil
+}
+
+// RegisterDeleted deletes organization together with its members and roles assigned to them
+func (uow *unitOfWork) RegisterDeleted(org *Organization) error {
+	if err := uow.organizations.Remove(org.ToDto()); err != nil {
+		return errors.Wrap(err, "failed to delete organization DTO in changeset")
+	}
+
+	if err := uow.members.RemoveRange(org.MembersDto()...); err != nil {
+		return errors.Wrap(err, "failed to delete members DTOs in changeset")
+	}
+
+	if err := uow.roles.RemoveRange(org.RolesDto()...); err != nil {
+		return errors.Wrap(err, "failed to delete member roles DTOs in changeset")
+	}
+
+	return nil
+}
+
+func (uow *unitOfWork) RegisterAmended(org *Organization) error {
+	orgDto := org.ToDto()
+	if err := uow.organizations.Update(orgDto); err != nil {
+		return errors.Wrap(err, "failed to update organization DTO in changeset")
+	}
+
+	createdMembers, _, deletedMembers := uow.members.DeltaWithMatched(org.MembersDto(), func(member MemberDto) bool {
+		return member.OrganizationId == orgDto.Id
+	})
+
+	if err := uow.members.AddRange(createdMembers...); err != nil {
+		return errors.Wrap(err, "failed to add members DTOs to changeset")
+	}
+
+	if err := uow.members.RemoveRange(deletedMembers...); err != nil {
+		return errors.Wrap(err, "failed to delete members DTOs in changeset")
+	}
+
+	createdRoles, _, deletedRoles := uow.roles.DeltaWithMatched(org.RolesDto(), func(assigned MemberRoleDto) bool {
+		return assigned.OrganizationId == orgDto.Id
+	})
+
+	if err := uow.roles.AddRange(createdRoles...); err != nil {
+		return errors.Wrap(err, "failed to add member roles DTOs to changeset")
+	}
+
+	if err := uow.roles.RemoveRange(deletedRoles...); err != nil {
+		return errors.Wrap(err, "failed to delete member roles DTOs in changeset")
+	}
+
+	return nil
+}
+
+func (uow *unitOfWork) Flush(ctx context.Context) error {
+	tx, err := uow.Tx(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to open transaction")
+	}
+	defer tx.Rollback()
+
+	orgDao := NewOrganizationDao(tx)
+	memberDao := NewMemberDao(tx)
+	roleDao := NewMemberRoleDao(tx)
+
+	// member roles reference members, so they are deleted first
+	if rmRoles := uow.roles.Deleted(); len(rmRoles) > 0 {
+		for key, roleIds := range groupMemberRoles(rmRoles) {
+			if err := roleDao.DeleteByOrganizationIdAndUserIdAndRoleIdsIn(ctx, key.OrganizationId, key.UserId, roleIds); err != nil {
+				return errors.Wrap(err, "failed to process member roles deletion")
+			}
+		}
+	}
+
+	if rmMembers := uow.members.Deleted(); len(rmMembers) > 0 {
+		membersGroup := helpers.GroupBy(rmMembers, func(member MemberDto, _ int, _ []MemberDto) (string, string) {
+			return member.OrganizationId, member.UserId
+		})
+
+		for orgId, userIds := range membersGroup {
+			if err := memberDao.DeleteByOrganizationIdAndUserIdsIn(ctx, orgId, userIds); err != nil {
+				return errors.Wrap(err, "failed to process members deletion")
+			}
+		}
+	}
+
+	if rmOrgs := uow.organizations.Deleted(); len(rmOrgs) > 0 {
+		mapper := func(org OrganizationDto, _ int, _ []OrganizationDto) string {
+			return org.Id
+		}
+		if err := orgDao.DeleteWhereIdsIn(ctx, helpers.Map(rmOrgs, mapper)); err != nil {
+			return errors.Wrap(err, "failed to process organizations deletion")
+		}
+	}
+
+	if createdOrgs := uow.organizations.Created(); len(createdOrgs) > 0 {
+		if err := orgDao.CreateMulti(ctx, createdOrgs); err != nil {
+			return errors.Wrap(err, "failed to process organizations creation")
+		}
+	}
+
+	if createdMembers := uow.members.Created(); len(createdMembers) > 0 {
+		if err := memberDao.CreateMulti(ctx, createdMembers); err != nil {
+			return errors.Wrap(err, "failed to process members creation")
+		}
+	}
+
+	if createdRoles := uow.roles.Created(); len(createdRoles) > 0 {
+		if err := roleDao.CreateMulti(ctx, createdRoles); err != nil {
+			return errors.Wrap(err, "failed to process member roles creation")
+		}
+	}
+
+	if updatedOrgs := uow.organizations.Updated(); len(updatedOrgs) > 0 {
+		for _, updOrg := range updatedOrgs {
+			if err := orgDao.Update(ctx, updOrg); err != nil {
+				return errors.Wrap(err, "failed to process organizations update")
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "failed to commit transaction")
+	}
+	return uow.Dispose()
+}
+
+func (uow *unitOfWork) Dispose() error {
+	uow.organizations.Cleanup()
+	uow.members.Cleanup()
+	uow.roles.Cleanup()
+	return nil
+}
+
+func groupMemberRoles(roles []MemberRoleDto) map[MemberDto][]string {
+	groups := make(map[MemberDto][]string)
+	for _, assigned := range roles {
+		member := MemberDto{OrganizationId: assigned.OrganizationId, UserId: assigned.UserId}
+		groups[member] = append(groups[member], assigned.RoleId)
+	}
+	return groups
+}
@@ -2,6 +2,7 @@ package refresh
 
 import (
 	"context"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -9,6 +10,11 @@ import (
 	dbredis "github.com/umalmyha/authsrv/pkg/database/redis"
 )
 
+const (
+	indexKeyPrefix    = "refresh_token:"
+	rotationKeyPrefix = "refresh_token_rotation:"
+)
+
 type RefreshTokenDao struct {
 	*dbredis.Store
 }
@@ -35,3 +41,63 @@ func (dao *RefreshTokenDao) FindAllForUser(ctx context.Context, userId string) (
 	}
 	return tokens, nil
 }
+
+// FindUserIdByTokenId looks up owner of the token, empty string is returned if token is unknown or expired
+func (dao *RefreshTokenDao) FindUserIdByTokenId(ctx context.Context, tokenId string) (string, error) {
+	userId, err := dao.Client().Get(ctx, IndexKey(tokenId)).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return "", nil
+		}
+		return "", errors.Wrap(err, "failed to read refresh token owner")
+	}
+	return userId, nil
+}
+
+// ClaimRotation atomically marks token as rotated, false is returned if token was already claimed by someone else,
+// so concurrent refreshes with the same token can't both succeed. Mark outlives token in user tokens, it is kept until token expires.
+func (dao *RefreshTokenDao) ClaimRotation(ctx context.Context, rotation RotationDto, now time.Time) (bool, error) {
+	ttl := rotation.ExpiresAt.Sub(now)
+	if ttl <= 0 {
+		// expired token is rejected on rotation anyway
+		return true, nil
+	}
+
+	encoded, err := dbredis.EncodeGob(rotation)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to serialize token rotation to gob format")
+	}
+
+	claimed, err := dao.Client().SetNX(ctx, rotationKey(rotation.TokenId), encoded, ttl).Result()
+	if err != nil {
+		return false, errors.Wrap(err, "failed to claim refresh token rotation")
+	}
+	return claimed, nil
+}
+
+// FindRotation returns rotation mark of token, zero value is returned if token was never rotated or already expired
+func (dao *RefreshTokenDao) FindRotation(ctx context.Context, tokenId string) (RotationDto, error) {
+	var rotation RotationDto
+
+	encoded, err := dao.Client().Get(ctx, rotationKey(tokenId)).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return rotation, nil
+		}
+		return rotation, errors.Wrap(err, "failed to read refresh token rotation")
+	}
+
+	if err := dbredis.DecodeGob([]byte(encoded), &rotation); err != nil {
+		return rotation, errors.Wrap(err, "failed to deserialize token rotation from gob format")
+	}
+	return rotation, nil
+}
+
+func rotationKey(tokenId string) string {
+	return rotationKeyPrefix + tokenId
+}
+
+// IndexKey is the key of token id -> user id entry, which allows to find token owner by token only
+func IndexKey(tokenId string) string {
+	return indexKeyPrefix + tokenId
+}
@@ -1,13 +1,29 @@
 package refresh
 
-import "time"
+import (
+	"time"
+
+	valueobj "github.com/umalmyha/authsrv/internal/business/value-object"
+	"golang.org/x/exp/slices"
+)
 
 type RefreshTokenDto struct {
 	Id          string
+	FamilyId    string
+	ParentId    string
 	Fingerprint string
+	Scopes      []string
 	UserId      string
 	IssuedAt    time.Time
 	ExpiresAt   time.Time
+	RotatedAt   time.Time
+	IpAddress   string
+	UserAgent   string
+	// AccessTokenId and AccessTokenExpiresAt describe access token issued together with refresh token
+	AccessTokenId        string
+	AccessTokenExpiresAt time.Time
+	Amr                  []string
+	Organization         string
 }
 
 func (dto RefreshTokenDto) Key() string {
@@ -20,9 +36,19 @@ func (dto RefreshTokenDto) IsPresent() bool {
 
 func (dto RefreshTokenDto) Equal(other RefreshTokenDto) bool {
 	return dto.Id == other.Id &&
+		dto.FamilyId == other.FamilyId &&
+		dto.ParentId == other.ParentId &&
 		dto.Fingerprint == other.Fingerprint &&
+		slices.Equal(dto.Scopes, other.Scopes) &&
 		dto.IssuedAt == other.IssuedAt &&
-		dto.ExpiresAt == other.ExpiresAt
+		dto.ExpiresAt == other.ExpiresAt &&
+		dto.RotatedAt == other.RotatedAt &&
+		dto.IpAddress == other.IpAddress &&
+		dto.UserAgent == other.UserAgent &&
+		dto.AccessTokenId == other.AccessTokenId &&
+		dto.AccessTokenExpiresAt == other.AccessTokenExpiresAt &&
+		slices.Equal(dto.Amr, other.Amr) &&
+		dto.Organization == other.Organization
 }
 
 func (dto RefreshTokenDto) Clone() RefreshTokenDto {
@@ -30,10 +56,44 @@ func (dto RefreshTokenDto) Clone() RefreshTokenDto {
 }
 
 func (dto RefreshTokenDto) ToRefreshToken() *RefreshToken {
+	// tokens stored before rotation was introduced start their own family
+	familyId := dto.FamilyId
+	if familyId == "" {
+		familyId = dto.Id
+	}
+
+	// tokens stored before amr was introduced were issued on password sign in
+	amr := dto.Amr
+	if amr == nil {
+		amr = []string{valueobj.AmrPassword}
+	}
+
 	return &RefreshToken{
 		id:          dto.Id,
+		familyId:    familyId,
+		parentId:    dto.ParentId,
 		fingerprint: dto.Fingerprint,
+		scopes:      dto.Scopes,
 		issuedAt:    dto.IssuedAt,
 		expiresAt:   dto.ExpiresAt,
+		rotatedAt:   dto.RotatedAt,
+		client:      ClientInfo{IpAddress: dto.IpAddress, UserAgent: dto.UserAgent},
+
+		accessTokenId:        dto.AccessTokenId,
+		accessTokenExpiresAt: dto.AccessTokenExpiresAt,
+		amr:                  amr,
+		org:                  dto.Organization,
 	}
 }
+
+// RotationDto marks rotated token, it lets detect reuse of tokens which are already pruned from user tokens
+type RotationDto struct {
+	TokenId   string
+	UserId    string
+	FamilyId  string
+	ExpiresAt time.Time
+}
+
+func (dto RotationDto) IsPresent() bool {
+	return dto.TokenId != ""
+}
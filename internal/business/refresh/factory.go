@@ -9,7 +9,14 @@ import (
 	"github.com/umalmyha/authsrv/pkg/errors"
 )
 
-func NewRefreshToken(fgrprint string, issuedAt time.Time, cfg valueobj.RefreshTokenConfig) (*RefreshToken, error) {
+const delegatedFingerprintPrefix = "oauth:"
+
+// NewDelegatedFingerprint builds unique fingerprint of session delegated to OAuth client, client id is recoverable from it
+func NewDelegatedFingerprint(clientId string) string {
+	return delegatedFingerprintPrefix + clientId + ":" + uuid.NewString()
+}
+
+func NewRefreshToken(fgrprint string, scopes []string, client ClientInfo, issuedAt time.Time, cfg valueobj.RefreshTokenConfig) (*RefreshToken, error) {
 	validation := errors.NewValidation()
 
 	if fgrprint == "" {
@@ -25,11 +32,15 @@ func NewRefreshToken(fgrprint string, issuedAt time.Time, cfg valueobj.RefreshTo
 	}
 
 	expiresAt := issuedAt.Add(cfg.TimeToLive())
+	id := uuid.NewString()
 
 	return &RefreshToken{
-		id:          uuid.NewString(),
+		id:          id,
+		familyId:    id,
 		fingerprint: fgrprint,
+		scopes:      scopes,
 		issuedAt:    issuedAt,
 		expiresAt:   expiresAt,
+		client:      client,
 	}, nil
 }
@@ -1,8 +1,11 @@
 package refresh
 
 import (
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
+	valueobj "github.com/umalmyha/authsrv/internal/business/value-object"
 	"github.com/umalmyha/authsrv/pkg/errors"
 )
 
@@ -13,21 +16,81 @@ var RefreshTokenExpiredErr = errors.NewBusinessErr(
 	"RFR_TOKEN_EXPIRED",
 )
 
+var RefreshTokenReusedErr = errors.NewBusinessErr(
+	"refreshToken",
+	"refresh token was already used, all tokens of the session are revoked",
+	errors.ViolationSeverityErr,
+	"RFR_TOKEN_REUSED",
+)
+
+// ClientInfo describes client which used the token
+type ClientInfo struct {
+	IpAddress string
+	UserAgent string
+}
+
+// RefreshToken belongs to family of tokens rotated from the same sign in, each rotation
+// issues child token and keeps parent marked as rotated to detect its reuse
 type RefreshToken struct {
 	id          string
+	familyId    string
+	parentId    string
 	fingerprint string
+	scopes      []string
 	issuedAt    time.Time
 	expiresAt   time.Time
+	rotatedAt   time.Time
+	client      ClientInfo
+	// access token issued together with refresh token, it is denied when token is revoked
+	accessTokenId        string
+	accessTokenExpiresAt time.Time
+	// authentication methods used on sign in, access tokens of the session are issued with them
+	amr []string
+	// organization access tokens of the session are issued for, empty means no organization
+	org string
 }
 
 func (rt *RefreshToken) Id() string {
 	return rt.id
 }
 
+func (rt *RefreshToken) FamilyId() string {
+	return rt.familyId
+}
+
+func (rt *RefreshToken) ParentId() string {
+	return rt.parentId
+}
+
 func (rt *RefreshToken) Fingerprint() string {
 	return rt.fingerprint
 }
 
+// Scopes returns scopes token was narrowed to on issue, nil means all user scopes for sessions which aren't delegated
+func (rt *RefreshToken) Scopes() []string {
+	return rt.scopes
+}
+
+// ClientId returns OAuth client session is delegated to, empty string is returned for sessions started on sign in
+func (rt *RefreshToken) ClientId() string {
+	rest := strings.TrimPrefix(rt.fingerprint, delegatedFingerprintPrefix)
+	if rest == rt.fingerprint {
+		return ""
+	}
+
+	clientId, _, found := strings.Cut(rest, ":")
+	if !found {
+		return ""
+	}
+	return clientId
+}
+
+// IsDelegated reports whether session was issued to OAuth client, access tokens of such session never carry
+// more than scopes granted on authorization
+func (rt *RefreshToken) IsDelegated() bool {
+	return rt.ClientId() != ""
+}
+
 func (rt *RefreshToken) IssuedAt() time.Time {
 	return rt.issuedAt
 }
@@ -36,6 +99,42 @@ func (rt *RefreshToken) ExpiresAt() time.Time {
 	return rt.expiresAt
 }
 
+func (rt *RefreshToken) RotatedAt() time.Time {
+	return rt.rotatedAt
+}
+
+func (rt *RefreshToken) Client() ClientInfo {
+	return rt.client
+}
+
+func (rt *RefreshToken) AccessTokenId() string {
+	return rt.accessTokenId
+}
+
+func (rt *RefreshToken) AccessTokenExpiresAt() time.Time {
+	return rt.accessTokenExpiresAt
+}
+
+// BindAccessToken remembers access token issued together with refresh token
+func (rt *RefreshToken) BindAccessToken(accessToken valueobj.Jwt) {
+	rt.accessTokenId = accessToken.Id()
+	rt.accessTokenExpiresAt = time.Unix(accessToken.ExpiresAt(), 0).UTC()
+	rt.amr = accessToken.Amr()
+	rt.org = accessToken.Organization()
+}
+
+func (rt *RefreshToken) Amr() []string {
+	return rt.amr
+}
+
+func (rt *RefreshToken) Organization() string {
+	return rt.org
+}
+
+func (rt *RefreshToken) IsRotated() bool {
+	return !rt.rotatedAt.IsZero()
+}
+
 func (rt *RefreshToken) UnixExpiresIn() int {
 	return int(rt.expiresAt.Unix() - rt.issuedAt.Unix())
 }
@@ -46,3 +145,29 @@ func (rt *RefreshToken) VerifyNotExpired(now time.Time) error {
 	}
 	return nil
 }
+
+// Rotate marks token as used and issues its child, child inherits family expiration,
+// so rotation can't prolong session beyond lifetime granted on sign in
+func (rt *RefreshToken) Rotate(now time.Time, client ClientInfo) (*RefreshToken, error) {
+	if rt.IsRotated() {
+		return nil, RefreshTokenReusedErr
+	}
+
+	if err := rt.VerifyNotExpired(now); err != nil {
+		return nil, err
+	}
+	rt.rotatedAt = now
+
+	return &RefreshToken{
+		id:          uuid.NewString(),
+		familyId:    rt.familyId,
+		parentId:    rt.id,
+		fingerprint: rt.fingerprint,
+		scopes:      rt.scopes,
+		issuedAt:    now,
+		expiresAt:   rt.expiresAt,
+		client:      client,
+		amr:         rt.amr,
+		org:         rt.org,
+	}, nil
+}
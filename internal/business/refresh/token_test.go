@@ -0,0 +1,121 @@
+package refresh
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	valueobj "github.com/umalmyha/authsrv/internal/business/value-object"
+)
+
+const (
+	success = "\u2713"
+	failed  = "\u2717"
+)
+
+func TestRotate(t *testing.T) {
+	cfg, err := valueobj.NewRefreshTokenConfig(time.Hour, 5, "refresh-token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issuedAt := time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)
+	token, err := NewRefreshToken("device", nil, ClientInfo{}, issuedAt, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Log("Given the need to test refresh token rotation")
+	{
+		t.Logf("\tTest 1:\tWhen valid token is rotated")
+		{
+			now := issuedAt.Add(10 * time.Minute)
+			child, err := token.Rotate(now, ClientInfo{IpAddress: "10.0.0.1"})
+			if err != nil {
+				t.Fatalf("\t%s\tUnexpected error occurred: %v", failed, err)
+			}
+
+			if child.FamilyId() != token.FamilyId() || child.ParentId() != token.Id() || child.Id() == token.Id() {
+				t.Fatalf("\t%s\tChild must be new token of the same family with parent id %s, got %+v", failed, token.Id(), child)
+			}
+
+			if !child.ExpiresAt().Equal(token.ExpiresAt()) {
+				t.Fatalf("\t%s\tChild must inherit family expiration %s, but got %s", failed, token.ExpiresAt(), child.ExpiresAt())
+			}
+
+			if !token.IsRotated() || child.IsRotated() {
+				t.Fatalf("\t%s\tParent must be marked as rotated and child must be active", failed)
+			}
+			t.Logf("\t%s\tChild token must be issued within the same family", success)
+		}
+
+		t.Logf("\tTest 2:\tWhen rotated token is presented again")
+		{
+			if _, err := token.Rotate(issuedAt.Add(20*time.Minute), ClientInfo{}); !errors.Is(err, RefreshTokenReusedErr) {
+				t.Fatalf("\t%s\tReuse must be detected, but got %v", failed, err)
+			}
+			t.Logf("\t%s\tReuse must be detected", success)
+		}
+
+		t.Logf("\tTest 3:\tWhen expired token is rotated")
+		{
+			expired, err := NewRefreshToken("device", nil, ClientInfo{}, issuedAt, cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := expired.Rotate(issuedAt.Add(2*time.Hour), ClientInfo{}); !errors.Is(err, RefreshTokenExpiredErr) {
+				t.Fatalf("\t%s\tExpiration error is expected, but got %v", failed, err)
+			}
+
+			if expired.IsRotated() {
+				t.Fatalf("\t%s\tExpired token must not be marked as rotated", failed)
+			}
+			t.Logf("\t%s\tExpired token must not be rotated", success)
+		}
+	}
+}
+
+func TestDelegatedSession(t *testing.T) {
+	cfg, err := valueobj.NewRefreshTokenConfig(time.Hour, 5, "refresh-token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issuedAt := time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)
+	clientId := "2d0f6f1e-8a7b-4c3d-9e2f-1a2b3c4d5e6f"
+
+	t.Log("Given the need to test sessions delegated to OAuth client")
+	{
+		t.Logf("\tTest 1:\tWhen token is issued to client")
+		{
+			token, err := NewRefreshToken(NewDelegatedFingerprint(clientId), []string{}, ClientInfo{}, issuedAt, cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			child, err := token.Rotate(issuedAt.Add(time.Minute), ClientInfo{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !child.IsDelegated() || child.ClientId() != clientId {
+				t.Fatalf("\t%s\tRotated token must stay delegated to %s, got %q", failed, clientId, child.ClientId())
+			}
+			t.Logf("\t%s\tRotated token must stay delegated to client", success)
+		}
+
+		t.Logf("\tTest 2:\tWhen token is issued on sign in")
+		{
+			token, err := NewRefreshToken("device", nil, ClientInfo{}, issuedAt, cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if token.IsDelegated() || token.ClientId() != "" {
+				t.Fatalf("\t%s\tToken mustn't be delegated, got client %q", failed, token.ClientId())
+			}
+			t.Logf("\t%s\tToken mustn't be delegated", success)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package reset
+
+import "time"
+
+type TokenDto struct {
+	UserId    string
+	ExpiresAt time.Time
+}
+
+func (dto TokenDto) IsPresent() bool {
+	return dto.UserId != ""
+}
+
+func (dto TokenDto) ToToken() *Token {
+	return &Token{
+		userId:    dto.UserId,
+		expiresAt: dto.ExpiresAt,
+	}
+}
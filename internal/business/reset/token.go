@@ -0,0 +1,61 @@
+package reset
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"time"
+
+	"github.com/pkg/errors"
+
+	valueobj "github.com/umalmyha/authsrv/internal/business/value-object"
+)
+
+const tokenLength = 32
+
+// Token is one-time password reset token, it is issued on request and is valid until used or expired
+type Token struct {
+	token     string
+	userId    string
+	expiresAt time.Time
+}
+
+func NewToken(userId string, issuedAt time.Time, cfg valueobj.PasswordResetConfig) (*Token, error) {
+	if userId == "" {
+		return nil, errors.New("user is mandatory for password reset token")
+	}
+
+	token := make([]byte, tokenLength)
+	if _, err := rand.Read(token); err != nil {
+		return nil, errors.Wrap(err, "failed to generate password reset token")
+	}
+
+	return &Token{
+		token:     base64.RawURLEncoding.EncodeToString(token),
+		userId:    userId,
+		expiresAt: issuedAt.Add(cfg.TokenTimeToLive()),
+	}, nil
+}
+
+// Token returns plain token value, it is available only for newly issued tokens
+func (t *Token) Token() string {
+	return t.token
+}
+
+func (t *Token) UserId() string {
+	return t.userId
+}
+
+func (t *Token) ExpiresAt() time.Time {
+	return t.expiresAt
+}
+
+func (t *Token) IsExpired(now time.Time) bool {
+	return !t.expiresAt.After(now)
+}
+
+func (t *Token) Dto() TokenDto {
+	return TokenDto{
+		UserId:    t.userId,
+		ExpiresAt: t.expiresAt,
+	}
+}
@@ -0,0 +1,8 @@
+package role
+
+// AdminRole is built-in role granting all administration scopes, it is created by migration and can't be deleted
+const AdminRole = "authsrv:admin"
+
+func IsBuiltin(name string) bool {
+	return name == AdminRole
+}
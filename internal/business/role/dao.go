@@ -67,6 +67,83 @@ func (dao *RoleDao) FindByName(ctx context.Context, name string) (RoleDto, error
 	return r, nil
 }
 
+func (dao *RoleDao) FindAll(ctx context.Context) ([]RoleDto, error) {
+	roles := make([]RoleDto, 0)
+	q := "SELECT ID, NAME, DESCRIPTION FROM ROLES ORDER BY NAME"
+	if err := sqlx.SelectContext(ctx, dao.ec, &roles, q); err != nil {
+		return nil, errors.Wrap(err, "failed to read roles")
+	}
+	return roles, nil
+}
+
+// FindAssignedUsernames reads names of users which have role assigned globally or in any organization
+func (dao *RoleDao) FindAssignedUsernames(ctx context.Context, roleId string) ([]string, error) {
+	usernames := make([]string, 0)
+	q := `SELECT U.USERNAME FROM USERS AS U WHERE
+		EXISTS(SELECT 1 FROM USER_ROLES AS UR WHERE UR.USER_ID = U.ID AND UR.ROLE_ID = $1 AND
+			(UR.VALID_FROM IS NULL OR UR.VALID_FROM <= CURRENT_TIMESTAMP) AND (UR.VALID_UNTIL IS NULL OR UR.VALID_UNTIL > CURRENT_TIMESTAMP)) OR
+		EXISTS(SELECT 1 FROM ORGANIZATION_USER_ROLES AS OUR WHERE OUR.USER_ID = U.ID AND OUR.ROLE_ID = $1)
+		ORDER BY U.USERNAME`
+	if err := sqlx.SelectContext(ctx, dao.ec, &usernames, q, roleId); err != nil {
+		return nil, errors.Wrap(err, "failed to read users assigned to role")
+	}
+	return usernames, nil
+}
+
+// FindAssignedGroupNames reads names of groups which have role assigned
+func (dao *RoleDao) FindAssignedGroupNames(ctx context.Context, roleId string) ([]string, error) {
+	names := make([]string, 0)
+	q := "SELECT G.NAME FROM GROUPS AS G INNER JOIN GROUPS_ROLES AS GR ON GR.GROUP_ID = G.ID WHERE GR.ROLE_ID = $1 ORDER BY G.NAME"
+	if err := sqlx.SelectContext(ctx, dao.ec, &names, q, roleId); err != nil {
+		return nil, errors.Wrap(err, "failed to read groups assigned to role")
+	}
+	return names, nil
+}
+
+// FindChildNames reads names of roles which directly inherit from role
+func (dao *RoleDao) FindChildNames(ctx context.Context, roleId string) ([]string, error) {
+	names := make([]string, 0)
+	q := "SELECT R.NAME FROM ROLES AS R INNER JOIN ROLES_PARENTS AS RP ON RP.ROLE_ID = R.ID WHERE RP.PARENT_ID = $1 ORDER BY R.NAME"
+	if err := sqlx.SelectContext(ctx, dao.ec, &names, q, roleId); err != nil {
+		return nil, errors.Wrap(err, "failed to read roles inheriting from role")
+	}
+	return names, nil
+}
+
+// FindAncestorIds reads ids of all roles which role inherits from directly or transitively
+func (dao *RoleDao) FindAncestorIds(ctx context.Context, roleId string) ([]string, error) {
+	ids := make([]string, 0)
+	q := "SELECT ANCESTOR_ID FROM ROLES_ANCESTORS WHERE ROLE_ID = $1 AND ANCESTOR_ID <> $1"
+	if err := sqlx.SelectContext(ctx, dao.ec, &ids, q, roleId); err != nil {
+		return nil, errors.Wrap(err, "failed to read role ancestors")
+	}
+	return ids, nil
+}
+
+func (dao *RoleDao) DeleteUserAssignmentsWhereRoleIdsIn(ctx context.Context, ids []string) error {
+	inRange, params, err := rdb.WhereIn(ids)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate SQL where clause for user assignments deletion")
+	}
+
+	q := fmt.Sprintf("DELETE FROM USER_ROLES WHERE ROLE_ID IN %s", inRange)
+	if _, err := dao.ec.ExecContext(ctx, q, params...); err != nil {
+		return errors.Wrap(err, "failed to delete user assignments of roles")
+	}
+
+	q = fmt.Sprintf("DELETE FROM ORGANIZATION_USER_ROLES WHERE ROLE_ID IN %s", inRange)
+	if _, err := dao.ec.ExecContext(ctx, q, params...); err != nil {
+		return errors.Wrap(err, "failed to delete organization user assignments of roles")
+	}
+
+	q = fmt.Sprintf("DELETE FROM GROUPS_ROLES WHERE ROLE_ID IN %s", inRange)
+	if _, err := dao.ec.ExecContext(ctx, q, params...); err != nil {
+		return errors.Wrap(err, "failed to delete group assignments of roles")
+	}
+
+	return nil
+}
+
 func (dao *RoleDao) FindById(ctx context.Context, id string) (RoleDto, error) {
 	var r RoleDto
 	q := "SELECT ID, NAME, DESCRIPTION FROM ROLES WHERE ID = $1"
@@ -103,6 +180,55 @@ func (dao *ScopeAssignmentDao) CreateMulti(ctx context.Context, scopes []ScopeAs
 	return nil
 }
 
+func (dao *ScopeAssignmentDao) FindAllForRole(ctx context.Context, roleId string) ([]ScopeAssignmentDto, error) {
+	scopes := make([]ScopeAssignmentDto, 0)
+	q := "SELECT ROLE_ID, SCOPE_ID FROM ROLES_SCOPES WHERE ROLE_ID = $1"
+	if err := sqlx.SelectContext(ctx, dao.ec, &scopes, q, roleId); err != nil {
+		return nil, errors.Wrap(err, "failed to read scope assignments of role")
+	}
+	return scopes, nil
+}
+
+// FindScopeNamesWhereRoleIdsIn reads names of scopes assigned to given roles
+func (dao *ScopeAssignmentDao) FindScopeNamesWhereRoleIdsIn(ctx context.Context, roleIds []string) ([]RoleScopeNameDto, error) {
+	scopes := make([]RoleScopeNameDto, 0)
+	if len(roleIds) == 0 {
+		return scopes, nil
+	}
+
+	inRange, params, err := rdb.WhereIn(roleIds)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate SQL where clause")
+	}
+
+	q := fmt.Sprintf("SELECT ROLE_ID, SCOPE_NAME FROM ROLES_SCOPES_DETAILS WHERE ROLE_ID IN %s ORDER BY SCOPE_NAME", inRange)
+	if err := sqlx.SelectContext(ctx, dao.ec, &scopes, q, params...); err != nil {
+		return nil, errors.Wrap(err, "failed to read scope names of roles")
+	}
+	return scopes, nil
+}
+
+// FindEffectiveScopeNamesWhereRoleIdsIn reads names of scopes assigned to given roles or to any of their ancestors
+func (dao *ScopeAssignmentDao) FindEffectiveScopeNamesWhereRoleIdsIn(ctx context.Context, roleIds []string) ([]RoleScopeNameDto, error) {
+	scopes := make([]RoleScopeNameDto, 0)
+	if len(roleIds) == 0 {
+		return scopes, nil
+	}
+
+	inRange, params, err := rdb.WhereIn(roleIds)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate SQL where clause")
+	}
+
+	q := fmt.Sprintf(`SELECT DISTINCT RA.ROLE_ID, RSD.SCOPE_NAME FROM ROLES_ANCESTORS AS RA
+		INNER JOIN ROLES_SCOPES_DETAILS AS RSD ON RSD.ROLE_ID = RA.ANCESTOR_ID
+		WHERE RA.ROLE_ID IN %s ORDER BY RSD.SCOPE_NAME`, inRange)
+	if err := sqlx.SelectContext(ctx, dao.ec, &scopes, q, params...); err != nil {
+		return nil, errors.Wrap(err, "failed to read effective scope names of roles")
+	}
+	return scopes, nil
+}
+
 func (dao *ScopeAssignmentDao) DeleteByRoleIdAndScopeIdsIn(ctx context.Context, roleId string, scopeIds []string) error {
 	inRange, params, err := rdb.WhereIn(scopeIds)
 	if err != nil {
@@ -117,3 +243,90 @@ func (dao *ScopeAssignmentDao) DeleteByRoleIdAndScopeIdsIn(ctx context.Context,
 
 	return nil
 }
+
+type ParentAssignmentDao struct {
+	ec sqlx.ExtContext
+}
+
+func NewParentAssignmentDao(ec sqlx.ExtContext) *ParentAssignmentDao {
+	return &ParentAssignmentDao{
+		ec: ec,
+	}
+}
+
+func (dao *ParentAssignmentDao) CreateMulti(ctx context.Context, parents []ParentAssignmentDto) error {
+	applier := func(parent ParentAssignmentDto) []any {
+		return []any{parent.RoleId, parent.ParentId}
+	}
+
+	q, params, err := rdb.BulkInsertQuery("ROLES_PARENTS", []string{"ROLE_ID", "PARENT_ID"}, parents, applier)
+	if err != nil {
+		return errors.Wrap(err, "failed to build bulk insert SQL query for parent assignments creation")
+	}
+
+	if _, err := dao.ec.ExecContext(ctx, q, params...); err != nil {
+		return errors.Wrap(err, "failed to create parent assignments")
+	}
+
+	return nil
+}
+
+func (dao *ParentAssignmentDao) FindAllForRole(ctx context.Context, roleId string) ([]ParentAssignmentDto, error) {
+	parents := make([]ParentAssignmentDto, 0)
+	q := "SELECT ROLE_ID, PARENT_ID FROM ROLES_PARENTS WHERE ROLE_ID = $1"
+	if err := sqlx.SelectContext(ctx, dao.ec, &parents, q, roleId); err != nil {
+		return nil, errors.Wrap(err, "failed to read parent assignments of role")
+	}
+	return parents, nil
+}
+
+// FindParentNamesWhereRoleIdsIn reads names of parent roles given roles directly inherit from
+func (dao *ParentAssignmentDao) FindParentNamesWhereRoleIdsIn(ctx context.Context, roleIds []string) ([]RoleParentNameDto, error) {
+	parents := make([]RoleParentNameDto, 0)
+	if len(roleIds) == 0 {
+		return parents, nil
+	}
+
+	inRange, params, err := rdb.WhereIn(roleIds)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate SQL where clause")
+	}
+
+	q := fmt.Sprintf(`SELECT RP.ROLE_ID, R.NAME AS PARENT_NAME FROM ROLES_PARENTS AS RP
+		INNER JOIN ROLES AS R ON R.ID = RP.PARENT_ID
+		WHERE RP.ROLE_ID IN %s ORDER BY R.NAME`, inRange)
+	if err := sqlx.SelectContext(ctx, dao.ec, &parents, q, params...); err != nil {
+		return nil, errors.Wrap(err, "failed to read parent names of roles")
+	}
+	return parents, nil
+}
+
+func (dao *ParentAssignmentDao) DeleteByRoleIdAndParentIdsIn(ctx context.Context, roleId string, parentIds []string) error {
+	inRange, params, err := rdb.WhereIn(parentIds)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate SQL where clause for parent assignments deletion")
+	}
+
+	params = append(params, roleId)
+	q := fmt.Sprintf("DELETE FROM ROLES_PARENTS WHERE PARENT_ID IN %s AND ROLE_ID = $%d", inRange, len(params))
+	if _, err := dao.ec.ExecContext(ctx, q, params...); err != nil {
+		return errors.Wrap(err, "failed to delete parent assignments")
+	}
+
+	return nil
+}
+
+// DeleteWhereParentIdsIn removes inheritance from given roles for all roles inheriting from them
+func (dao *ParentAssignmentDao) DeleteWhereParentIdsIn(ctx context.Context, parentIds []string) error {
+	inRange, params, err := rdb.WhereIn(parentIds)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate SQL where clause for parent assignments deletion")
+	}
+
+	q := fmt.Sprintf("DELETE FROM ROLES_PARENTS WHERE PARENT_ID IN %s", inRange)
+	if _, err := dao.ec.ExecContext(ctx, q, params...); err != nil {
+		return errors.Wrap(err, "failed to delete parent assignments of children")
+	}
+
+	return nil
+}
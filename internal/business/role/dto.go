@@ -27,11 +27,73 @@ func (dto ScopeAssignmentDto) Clone() ScopeAssignmentDto {
 	return dto
 }
 
+// ParentAssignmentDto links role to parent role it inherits scopes from
+type ParentAssignmentDto struct {
+	RoleId   string `db:"role_id"`
+	ParentId string `db:"parent_id"`
+}
+
+func (dto ParentAssignmentDto) Key() string {
+	return fmt.Sprintf("%s-%s", dto.RoleId, dto.ParentId)
+}
+
+func (dto ParentAssignmentDto) IsPresent() bool {
+	return dto.RoleId != "" && dto.ParentId != ""
+}
+
+func (dto ParentAssignmentDto) Equal(other ParentAssignmentDto) bool {
+	return dto.RoleId == other.RoleId && dto.ParentId == other.ParentId
+}
+
+func (dto ParentAssignmentDto) Clone() ParentAssignmentDto {
+	return dto
+}
+
 type NewRoleDto struct {
 	Name        string  `json:"name"`
 	Description *string `json:"description"`
 }
 
+// UpdateRoleDto holds fields to change, missing description is left unchanged and empty one clears it
+type UpdateRoleDto struct {
+	Description *string `json:"description"`
+}
+
+// RoleDetailsDto is role together with names of assigned scopes, parent roles and scopes effective through inheritance
+type RoleDetailsDto struct {
+	Id              string   `json:"id"`
+	Name            string   `json:"name"`
+	Description     *string  `json:"description"`
+	Scopes          []string `json:"scopes"`
+	Parents         []string `json:"parents"`
+	EffectiveScopes []string `json:"effectiveScopes"`
+}
+
+type RoleScopeNameDto struct {
+	RoleId    string `db:"role_id"`
+	ScopeName string `db:"scope_name"`
+}
+
+type RoleParentNameDto struct {
+	RoleId     string `db:"role_id"`
+	ParentName string `db:"parent_name"`
+}
+
+// DeletionReportDto lists users and groups which lost role and roles which stopped inheriting from it on deletion,
+// or which prevent deletion if it was rejected
+type DeletionReportDto struct {
+	Role     string   `json:"role"`
+	Cascaded bool     `json:"cascaded"`
+	Users    []string `json:"users"`
+	Groups   []string `json:"groups"`
+	Children []string `json:"children"`
+}
+
+// IsRejected reports if deletion must be rejected, i.e. role is assigned or inherited and deletion isn't cascaded
+func (dto DeletionReportDto) IsRejected() bool {
+	return (len(dto.Users) > 0 || len(dto.Groups) > 0 || len(dto.Children) > 0) && !dto.Cascaded
+}
+
 type RoleDto struct {
 	Id          string  `db:"id" json:"id"`
 	Name        string  `db:"name" json:"name"`
@@ -50,6 +112,30 @@ func (dto RoleDto) Equal(other RoleDto) bool {
 	return dto.Name == other.Name && helpers.EqualValues(dto.Description, other.Description)
 }
 
+// Details returns role with given scope and parent names, names are read separately
+func (dto RoleDto) Details(scopes []string, parents []string, effectiveScopes []string) RoleDetailsDto {
+	if scopes == nil {
+		scopes = make([]string, 0)
+	}
+
+	if parents == nil {
+		parents = make([]string, 0)
+	}
+
+	if effectiveScopes == nil {
+		effectiveScopes = make([]string, 0)
+	}
+
+	return RoleDetailsDto{
+		Id:              dto.Id,
+		Name:            dto.Name,
+		Description:     dto.Description,
+		Scopes:          scopes,
+		Parents:         parents,
+		EffectiveScopes: effectiveScopes,
+	}
+}
+
 func (dto RoleDto) Clone() RoleDto {
 	return RoleDto{
 		Id:          dto.Id,
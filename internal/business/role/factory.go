@@ -51,19 +51,39 @@ func FromNewRoleDto(dto NewRoleDto, existFn isExsitingRoleNameFn) (*Role, error)
 		name:        roleName,
 		description: valueobj.NewNilStringFromPtr(dto.Description),
 		scopes:      list.New(),
+		parents:     list.New(),
 	}, nil
 }
 
-func fromDbDtos(roleDto RoleDto, scopesDto []ScopeAssignmentDto) (*Role, error) {
+func fromDbDtos(roleDto RoleDto, scopesDto []ScopeAssignmentDto, parentsDto []ParentAssignmentDto) (*Role, error) {
 	name, err := valueobj.NewSolidString(roleDto.Name)
 	if err != nil {
 		return nil, pkgerrors.Wrap(err, "failed to build role name from db entry")
 	}
 
+	scopeIds := make([]valueobj.ScopeId, 0)
+	for _, sc := range scopesDto {
+		scopeId, err := valueobj.NewScopeId(sc.ScopeId)
+		if err != nil {
+			return nil, pkgerrors.Wrap(err, "failed to build scope identifier from db entry")
+		}
+		scopeIds = append(scopeIds, scopeId)
+	}
+
+	parentIds := make([]valueobj.RoleId, 0)
+	for _, parent := range parentsDto {
+		parentId, err := valueobj.NewRoleId(parent.ParentId)
+		if err != nil {
+			return nil, pkgerrors.Wrap(err, "failed to build parent role identifier from db entry")
+		}
+		parentIds = append(parentIds, parentId)
+	}
+
 	return &Role{
 		id:          roleDto.Id,
 		name:        name,
 		description: valueobj.NewNilStringFromPtr(roleDto.Description),
-		scopes:      helpers.ToList(scopesDto),
+		scopes:      helpers.ToList(scopeIds),
+		parents:     helpers.ToList(parentIds),
 	}, nil
 }
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/pkg/errors"
+	valueobj "github.com/umalmyha/authsrv/internal/business/value-object"
 )
 
 type Repository struct {
@@ -25,6 +26,12 @@ func (repo *Repository) Update(role *Role) error {
 	return repo.uow.RegisterAmended(role)
 }
 
+// Remove deletes role with its scope and parent assignments, assignments to users and inheritance
+// of child roles are deleted only if policy cascades
+func (repo *Repository) Remove(role *Role, policy valueobj.DeletePolicy) error {
+	return repo.uow.RegisterDeleted(role, policy)
+}
+
 func (repo *Repository) FindById(ctx context.Context, id string) (*Role, error) {
 	notPresentFn := func() (RoleDto, error) {
 		return NewRoleDao(repo.uow.ExtContext()).FindById(ctx, id)
@@ -39,16 +46,7 @@ func (repo *Repository) FindById(ctx context.Context, id string) (*Role, error)
 		return nil, fmt.Errorf("role with id %s doesn't exist", id)
 	}
 
-	assignedScopes := repo.uow.assignedScopes.Filter(func(dto ScopeAssignmentDto) bool {
-		return dto.RoleId == role.Id
-	})
-
-	r, err := fromDbDtos(role, assignedScopes)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to build role aggregate from db DTOs")
-	}
-
-	return r, repo.uow.RegisterClean(r)
+	return repo.loadRole(ctx, role)
 }
 
 func (repo *Repository) FindByName(ctx context.Context, name string) (*Role, error) {
@@ -65,11 +63,21 @@ func (repo *Repository) FindByName(ctx context.Context, name string) (*Role, err
 		return nil, fmt.Errorf("role %s doesn't exist", name)
 	}
 
-	assignedScopes := repo.uow.assignedScopes.Filter(func(dto ScopeAssignmentDto) bool {
-		return dto.RoleId == role.Id
-	})
+	return repo.loadRole(ctx, role)
+}
+
+func (repo *Repository) loadRole(ctx context.Context, role RoleDto) (*Role, error) {
+	assignedScopes, err := NewScopeAssignmentDao(repo.uow.ExtContext()).FindAllForRole(ctx, role.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	parents, err := NewParentAssignmentDao(repo.uow.ExtContext()).FindAllForRole(ctx, role.Id)
+	if err != nil {
+		return nil, err
+	}
 
-	r, err := fromDbDtos(role, assignedScopes)
+	r, err := fromDbDtos(role, assignedScopes, parents)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to build role aggregate from db DTOs")
 	}
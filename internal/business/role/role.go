@@ -9,13 +9,28 @@ import (
 	"github.com/umalmyha/authsrv/pkg/helpers"
 )
 
+var HierarchyCycleErr = errors.New("role hierarchy must not have cycles")
+
 type ScopeFinderByNameFn func(string) (scope.ScopeDto, error)
+type RoleFinderByNameFn func(string) (RoleDto, error)
+
+// AncestorsFinderFn returns ids of all roles which role with given id inherits from directly or transitively
+type AncestorsFinderFn func(string) ([]string, error)
 
 type Role struct {
 	id          string
 	name        valueobj.SolidString
 	description valueobj.NilString
 	scopes      *list.List
+	parents     *list.List
+}
+
+func (r *Role) Id() string {
+	return r.id
+}
+
+func (r *Role) Name() string {
+	return r.name.String()
 }
 
 func (r *Role) ChangeDescription(descr string) {
@@ -80,6 +95,75 @@ func (r *Role) UnassignScope(name string, finderFn ScopeFinderByNameFn) error {
 	return nil
 }
 
+// InheritFrom makes role inherit scopes of parent role, parent which already inherits from role is rejected
+func (r *Role) InheritFrom(name string, finderFn RoleFinderByNameFn, ancestorsFn AncestorsFinderFn) error {
+	parent, err := finderFn(name)
+	if err != nil {
+		return errors.Wrap(err, "failed to find parent role")
+	}
+
+	if !parent.IsPresent() {
+		return errors.Errorf("role %s doesn't exist", name)
+	}
+
+	parentIdent, err := valueobj.NewRoleId(parent.Id)
+	if err != nil {
+		return errors.Wrap(err, "failed to build role identifier")
+	}
+
+	if parent.Id == r.id {
+		return errors.Wrapf(HierarchyCycleErr, "role %s can't inherit from itself", r.name)
+	}
+
+	for elem := r.parents.Front(); elem != nil; elem = elem.Next() {
+		parentId, _ := elem.Value.(valueobj.RoleId)
+		if parentId.Equal(parentIdent) {
+			return errors.Errorf("role %s already inherits from %s", r.name, name)
+		}
+	}
+
+	ancestors, err := ancestorsFn(parent.Id)
+	if err != nil {
+		return errors.Wrap(err, "failed to find ancestors of parent role")
+	}
+
+	for _, ancestorId := range ancestors {
+		if ancestorId == r.id {
+			return errors.Wrapf(HierarchyCycleErr, "role %s already inherits from %s", name, r.name)
+		}
+	}
+
+	r.parents.PushBack(parentIdent)
+	return nil
+}
+
+func (r *Role) DisinheritFrom(name string, finderFn RoleFinderByNameFn) error {
+	parent, err := finderFn(name)
+	if err != nil {
+		return errors.Wrap(err, "failed to find parent role")
+	}
+
+	if !parent.IsPresent() {
+		return errors.Errorf("role %s doesn't exist", name)
+	}
+
+	var rmElem *list.Element
+	for elem := r.parents.Front(); elem != nil; elem = elem.Next() {
+		parentId, _ := elem.Value.(valueobj.RoleId)
+		if parentId.String() == parent.Id {
+			rmElem = elem
+			break
+		}
+	}
+
+	if rmElem == nil {
+		return errors.Errorf("role %s doesn't inherit from %s", r.name, name)
+	}
+
+	r.parents.Remove(rmElem)
+	return nil
+}
+
 func (r *Role) ToDto() RoleDto {
 	return RoleDto{
 		Id:          r.id,
@@ -93,3 +177,9 @@ func (r *Role) ScopesDto() []ScopeAssignmentDto {
 		return ScopeAssignmentDto{RoleId: r.id, ScopeId: scopeId.String()}
 	})
 }
+
+func (r *Role) ParentsDto() []ParentAssignmentDto {
+	return helpers.FromListWithReducer(r.parents, func(parentId valueobj.RoleId) ParentAssignmentDto {
+		return ParentAssignmentDto{RoleId: r.id, ParentId: parentId.String()}
+	})
+}
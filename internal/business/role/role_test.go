@@ -0,0 +1,165 @@
+package role
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	valueobj "github.com/umalmyha/authsrv/internal/business/value-object"
+)
+
+const (
+	success = "\u2713"
+	failed  = "\u2717"
+)
+
+func TestRoleInheritance(t *testing.T) {
+	roles := map[string]RoleDto{
+		"viewer": {Id: "0f8b7c2e-3d4a-4b5c-8d6e-7f8091a2b301", Name: "viewer"},
+		"editor": {Id: "0f8b7c2e-3d4a-4b5c-8d6e-7f8091a2b302", Name: "editor"},
+		"admin":  {Id: "0f8b7c2e-3d4a-4b5c-8d6e-7f8091a2b303", Name: "admin"},
+	}
+	finderFn := func(name string) (RoleDto, error) { return roles[name], nil }
+
+	// admin inherits from editor, editor inherits from viewer
+	ancestors := map[string][]string{
+		roles["admin"].Id:  {roles["editor"].Id, roles["viewer"].Id},
+		roles["editor"].Id: {roles["viewer"].Id},
+	}
+	ancestorsFn := func(roleId string) ([]string, error) { return ancestors[roleId], nil }
+
+	newRole := func(name string) *Role {
+		r, err := fromDbDtos(roles[name], nil, nil)
+		if err != nil {
+			t.Fatalf("\t%s\tUnexpected error on role creation: %v", failed, err)
+		}
+		return r
+	}
+
+	t.Log("Given the need to test role hierarchy")
+	{
+		t.Logf("\tTest 1:\tWhen role inherits from role without relation")
+		{
+			r := newRole("viewer")
+			if err := r.InheritFrom("admin", finderFn, func(string) ([]string, error) { return nil, nil }); err != nil {
+				t.Fatalf("\t%s\tInheritance must be accepted, got %v", failed, err)
+			}
+
+			parents := r.ParentsDto()
+			if len(parents) != 1 || parents[0].ParentId != roles["admin"].Id {
+				t.Fatalf("\t%s\tParent must be assigned, got %+v", failed, parents)
+			}
+			t.Logf("\t%s\tInheritance must be accepted", success)
+		}
+
+		t.Logf("\tTest 2:\tWhen role inherits from itself")
+		{
+			if err := newRole("editor").InheritFrom("editor", finderFn, ancestorsFn); !errors.Is(err, HierarchyCycleErr) {
+				t.Fatalf("\t%s\tCycle must be rejected, got %v", failed, err)
+			}
+			t.Logf("\t%s\tCycle must be rejected", success)
+		}
+
+		t.Logf("\tTest 3:\tWhen role inherits from its transitive descendant")
+		{
+			r := newRole("viewer")
+			if err := r.InheritFrom("admin", finderFn, ancestorsFn); !errors.Is(err, HierarchyCycleErr) {
+				t.Fatalf("\t%s\tCycle must be rejected, got %v", failed, err)
+			}
+
+			if len(r.ParentsDto()) != 0 {
+				t.Fatalf("\t%s\tRejected parent mustn't be assigned", failed)
+			}
+			t.Logf("\t%s\tCycle must be rejected", success)
+		}
+
+		t.Logf("\tTest 4:\tWhen inheritance is removed")
+		{
+			r := newRole("admin")
+			if err := r.InheritFrom("editor", finderFn, ancestorsFn); err != nil {
+				t.Fatalf("\t%s\tUnexpected error on inheritance: %v", failed, err)
+			}
+
+			if err := r.DisinheritFrom("editor", finderFn); err != nil || len(r.ParentsDto()) != 0 {
+				t.Fatalf("\t%s\tParent must be removed, got %v", failed, err)
+			}
+
+			if err := r.DisinheritFrom("editor", finderFn); err == nil {
+				t.Fatalf("\t%s\tRemoval of not inherited parent must be rejected", failed)
+			}
+			t.Logf("\t%s\tParent must be removed", success)
+		}
+	}
+}
+
+func TestDeletionReport(t *testing.T) {
+	cases := []struct {
+		when     string
+		report   DeletionReportDto
+		rejected bool
+	}{
+		{"role isn't used", DeletionReportDto{}, false},
+		{"role is assigned to user", DeletionReportDto{Users: []string{"john"}}, true},
+		{"role is assigned to group", DeletionReportDto{Groups: []string{"staff"}}, true},
+		{"role is inherited", DeletionReportDto{Children: []string{"admin"}}, true},
+		{"used role deletion is cascaded", DeletionReportDto{Cascaded: true, Users: []string{"john"}, Groups: []string{"staff"}, Children: []string{"admin"}}, false},
+		{"unused role deletion is cascaded", DeletionReportDto{Cascaded: true}, false},
+	}
+
+	t.Log("Given the need to test role delete policy")
+	{
+		for i, c := range cases {
+			t.Logf("\tTest %d:\tWhen %s", i+1, c.when)
+			{
+				if c.report.IsRejected() != c.rejected {
+					t.Fatalf("\t%s\tDeletion rejection must be %t", failed, c.rejected)
+				}
+				t.Logf("\t%s\tDeletion rejection must be %t", success, c.rejected)
+			}
+		}
+	}
+}
+
+func TestRegisterDeleted(t *testing.T) {
+	dto := RoleDto{Id: "0f8b7c2e-3d4a-4b5c-8d6e-7f8091a2b301", Name: "viewer"}
+	scopes := []ScopeAssignmentDto{{RoleId: dto.Id, ScopeId: "0f8b7c2e-3d4a-4b5c-8d6e-7f8091a2b3f1"}}
+	parents := []ParentAssignmentDto{{RoleId: dto.Id, ParentId: "0f8b7c2e-3d4a-4b5c-8d6e-7f8091a2b302"}}
+
+	cases := []struct {
+		policy   valueobj.DeletePolicy
+		cascaded bool
+	}{
+		{valueobj.DeleteReject, false},
+		{valueobj.DeleteCascade, true},
+	}
+
+	t.Log("Given the need to test role deletion in unit of work")
+	{
+		for i, c := range cases {
+			t.Logf("\tTest %d:\tWhen role is deleted with %s policy", i+1, c.policy)
+			{
+				r, err := fromDbDtos(dto, scopes, parents)
+				if err != nil {
+					t.Fatalf("\t%s\tUnexpected error on role creation: %v", failed, err)
+				}
+
+				u := NewUnitOfWork(nil)
+				if err := u.RegisterClean(r); err != nil {
+					t.Fatalf("\t%s\tUnexpected error on registration: %v", failed, err)
+				}
+
+				if err := u.RegisterDeleted(r, c.policy); err != nil {
+					t.Fatalf("\t%s\tUnexpected error on deletion: %v", failed, err)
+				}
+
+				if len(u.roles.Deleted()) != 1 || len(u.assignedScopes.Deleted()) != 1 || len(u.parents.Deleted()) != 1 {
+					t.Fatalf("\t%s\tRole must be deleted together with its own assignments", failed)
+				}
+
+				if u.cascaded[dto.Id] != c.cascaded {
+					t.Fatalf("\t%s\tAssignments to users and child roles must be deleted %t", failed, c.cascaded)
+				}
+				t.Logf("\t%s\tAssignments to users and child roles must be deleted %t", success, c.cascaded)
+			}
+		}
+	}
+}
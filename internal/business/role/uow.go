@@ -5,6 +5,7 @@ import (
 
 	"github.com/jmoiron/sqlx"
 	"github.com/pkg/errors"
+	valueobj "github.com/umalmyha/authsrv/internal/business/value-object"
 	"github.com/umalmyha/authsrv/pkg/ddd/uow"
 	"github.com/umalmyha/authsrv/pkg/helpers"
 )
@@ -13,6 +14,9 @@ type unitOfWork struct {
 	*uow.SqlxUnitOfWork
 	roles          *uow.ChangeSet[RoleDto]
 	assignedScopes *uow.ChangeSet[ScopeAssignmentDto]
+	parents        *uow.ChangeSet[ParentAssignmentDto]
+	// roles which are deleted together with their assignments to users and inheritance of child roles
+	cascaded map[string]bool
 }
 
 func NewUnitOfWork(db *sqlx.DB) *unitOfWork {
@@ -20,12 +24,15 @@ func NewUnitOfWork(db *sqlx.DB) *unitOfWork {
 		SqlxUnitOfWork: uow.NewSqlxUnitOfWork(db),
 		roles:          uow.NewChangeSet[RoleDto](),
 		assignedScopes: uow.NewChangeSet[ScopeAssignmentDto](),
+		parents:        uow.NewChangeSet[ParentAssignmentDto](),
+		cascaded:       make(map[string]bool),
 	}
 }
 
 func (uow *unitOfWork) RegisterClean(role *Role) error {
 	uow.roles.Attach(role.ToDto())
 	uow.assignedScopes.AttachRange(role.ScopesDto()...)
+	uow.parents.AttachRange(role.ParentsDto()...)
 	return nil
 }
 
@@ -38,18 +45,30 @@ func (uow *unitOfWork) RegisterNew(role *Role) error {
 		return errors.Wrap(err, "failed to add scope assignments DTOs to changeset")
 	}
 
+	if err := uow.parents.AddRange(role.ParentsDto()...); err != nil {
+		return errors.Wrap(err, "failed to add parent assignments DTOs to changeset")
+	}
+
 	return nil
 }
 
-func (uow *unitOfWork) RegisterDeleted(role *Role) error {
+func (uow *unitOfWork) RegisterDeleted(role *Role, policy valueobj.DeletePolicy) error {
 	if err := uow.roles.Remove(role.ToDto()); err != nil {
 		return errors.Wrap(err, "failed to delete role DTO in changeset")
 	}
 
+	if policy.IsCascade() {
+		uow.cascaded[role.id] = true
+	}
+
 	if err := uow.assignedScopes.RemoveRange(role.ScopesDto()...); err != nil {
 		return errors.Wrap(err, "failed to delete scope assignments DTOs in changeset")
 	}
 
+	if err := uow.parents.RemoveRange(role.ParentsDto()...); err != nil {
+		return errors.Wrap(err, "failed to delete parent assignments DTOs in changeset")
+	}
+
 	return nil
 }
 
@@ -72,6 +91,18 @@ func (uow *unitOfWork) RegisterAmended(role *Role) error {
 		return errors.Wrap(err, "failed to delete scope assignments DTOs in changeset")
 	}
 
+	createdParents, _, deletedParents := uow.parents.DeltaWithMatched(role.ParentsDto(), func(parentDto ParentAssignmentDto) bool {
+		return parentDto.RoleId == roleDto.Id
+	})
+
+	if err := uow.parents.AddRange(createdParents...); err != nil {
+		return errors.Wrap(err, "failed to add parent assignments DTOs to changeset")
+	}
+
+	if err := uow.parents.RemoveRange(deletedParents...); err != nil {
+		return errors.Wrap(err, "failed to delete parent assignments DTOs in changeset")
+	}
+
 	return nil
 }
 
@@ -84,6 +115,7 @@ func (uow *unitOfWork) Flush(ctx context.Context) error {
 
 	rolesDao := NewRoleDao(tx)
 	scopesDao := NewScopeAssignmentDao(tx)
+	parentsDao := NewParentAssignmentDao(tx)
 
 	if rmScopes := uow.assignedScopes.Deleted(); len(rmScopes) > 0 {
 		scopesGroup := helpers.GroupBy(rmScopes, func(scope ScopeAssignmentDto, _ int, _ []ScopeAssignmentDto) (string, string) {
@@ -97,6 +129,29 @@ func (uow *unitOfWork) Flush(ctx context.Context) error {
 		}
 	}
 
+	if rmParents := uow.parents.Deleted(); len(rmParents) > 0 {
+		parentsGroup := helpers.GroupBy(rmParents, func(parent ParentAssignmentDto, _ int, _ []ParentAssignmentDto) (string, string) {
+			return parent.RoleId, parent.ParentId
+		})
+
+		for roleId, parentIds := range parentsGroup {
+			if err := parentsDao.DeleteByRoleIdAndParentIdsIn(ctx, roleId, parentIds); err != nil {
+				return errors.Wrap(err, "failed to process parent assignments deletion")
+			}
+		}
+	}
+
+	if cascaded := helpers.Keys(uow.cascaded); len(cascaded) > 0 {
+		if err := rolesDao.DeleteUserAssignmentsWhereRoleIdsIn(ctx, cascaded); err != nil {
+			return errors.Wrap(err, "failed to process user assignments deletion")
+		}
+
+		if err := parentsDao.DeleteWhereParentIdsIn(ctx, cascaded); err != nil {
+			return errors.Wrap(err, "failed to process child roles inheritance deletion")
+		}
+	}
+
+	// roles assigned to users or inherited by other roles are protected by foreign key unless deletion is cascaded
 	if deletedRoles := uow.roles.Deleted(); len(deletedRoles) > 0 {
 		mapper := func(delRole RoleDto, _ int, _ []RoleDto) string {
 			return delRole.Id
@@ -118,6 +173,12 @@ func (uow *unitOfWork) Flush(ctx context.Context) error {
 		}
 	}
 
+	if createdParents := uow.parents.Created(); len(createdParents) > 0 {
+		if err := parentsDao.CreateMulti(ctx, createdParents); err != nil {
+			return errors.Wrap(err, "failed to process parent assignments creation")
+		}
+	}
+
 	if updatedRoles := uow.roles.Updated(); len(updatedRoles) > 0 {
 		for _, updRole := range updatedRoles {
 			if err := rolesDao.Update(ctx, updRole); err != nil {
@@ -135,5 +196,7 @@ func (uow *unitOfWork) Flush(ctx context.Context) error {
 func (uow *unitOfWork) Dispose() error {
 	uow.roles.Cleanup()
 	uow.assignedScopes.Cleanup()
+	uow.parents.Cleanup()
+	uow.cascaded = make(map[string]bool)
 	return nil
 }
@@ -0,0 +1,41 @@
+package scope
+
+// built-in scopes guarding administration API, they are created by migration and can't be deleted
+const (
+	UsersRead      = "authsrv:users:read"
+	UsersManage    = "authsrv:users:manage"
+	SessionsManage = "authsrv:sessions:manage"
+	RolesRead      = "authsrv:roles:read"
+	RolesWrite     = "authsrv:roles:write"
+	ScopesRead     = "authsrv:scopes:read"
+	ScopesWrite    = "authsrv:scopes:write"
+
+	OrganizationsRead  = "authsrv:organizations:read"
+	OrganizationsWrite = "authsrv:organizations:write"
+
+	GroupsRead  = "authsrv:groups:read"
+	GroupsWrite = "authsrv:groups:write"
+)
+
+var BuiltinScopes = []string{
+	UsersRead,
+	UsersManage,
+	SessionsManage,
+	RolesRead,
+	RolesWrite,
+	ScopesRead,
+	ScopesWrite,
+	OrganizationsRead,
+	OrganizationsWrite,
+	GroupsRead,
+	GroupsWrite,
+}
+
+func IsBuiltin(name string) bool {
+	for _, builtin := range BuiltinScopes {
+		if name == builtin {
+			return true
+		}
+	}
+	return false
+}
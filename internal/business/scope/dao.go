@@ -25,6 +25,79 @@ func (d *ScopeDao) Create(ctx context.Context, sc ScopeDto) error {
 	return nil
 }
 
+func (d *ScopeDao) Update(ctx context.Context, sc ScopeDto) error {
+	q := "UPDATE SCOPES SET DESCRIPTION = $1 WHERE ID = $2"
+	if _, err := d.ec.ExecContext(ctx, q, sc.Description, sc.Id); err != nil {
+		return errors.Wrap(err, "failed to update scope")
+	}
+	return nil
+}
+
+func (d *ScopeDao) Delete(ctx context.Context, id string) error {
+	if _, err := d.ec.ExecContext(ctx, "DELETE FROM SCOPES WHERE ID = $1", id); err != nil {
+		return errors.Wrap(err, "failed to delete scope")
+	}
+	return nil
+}
+
+func (d *ScopeDao) DeleteRoleAssignments(ctx context.Context, id string) error {
+	if _, err := d.ec.ExecContext(ctx, "DELETE FROM ROLES_SCOPES WHERE SCOPE_ID = $1", id); err != nil {
+		return errors.Wrap(err, "failed to delete role assignments of scope")
+	}
+	return nil
+}
+
+func (d *ScopeDao) FindAll(ctx context.Context) ([]ScopeDto, error) {
+	scopes := make([]ScopeDto, 0)
+	q := "SELECT ID, NAME, DESCRIPTION FROM SCOPES ORDER BY NAME"
+	if err := sqlx.SelectContext(ctx, d.ec, &scopes, q); err != nil {
+		return nil, errors.Wrap(err, "failed to read scopes")
+	}
+	return scopes, nil
+}
+
+func (d *ScopeDao) DeleteClientAssignments(ctx context.Context, id string) error {
+	if _, err := d.ec.ExecContext(ctx, "DELETE FROM OAUTH_CLIENT_SCOPES WHERE SCOPE_ID = $1", id); err != nil {
+		return errors.Wrap(err, "failed to delete client assignments of scope")
+	}
+	return nil
+}
+
+// FindAllowedClientNames reads names of OAuth clients which are allowed to request scope
+func (d *ScopeDao) FindAllowedClientNames(ctx context.Context, id string) ([]string, error) {
+	names := make([]string, 0)
+	q := "SELECT C.NAME FROM OAUTH_CLIENTS AS C INNER JOIN OAUTH_CLIENT_SCOPES AS CS ON CS.CLIENT_ID = C.ID WHERE CS.SCOPE_ID = $1 ORDER BY C.NAME"
+	if err := sqlx.SelectContext(ctx, d.ec, &names, q, id); err != nil {
+		return nil, errors.Wrap(err, "failed to read clients of scope")
+	}
+	return names, nil
+}
+
+// FindAssignedRoleNames reads names of roles which have scope assigned
+func (d *ScopeDao) FindAssignedRoleNames(ctx context.Context, id string) ([]string, error) {
+	names := make([]string, 0)
+	q := "SELECT R.NAME FROM ROLES AS R INNER JOIN ROLES_SCOPES AS RS ON RS.ROLE_ID = R.ID WHERE RS.SCOPE_ID = $1 ORDER BY R.NAME"
+	if err := sqlx.SelectContext(ctx, d.ec, &names, q, id); err != nil {
+		return nil, errors.Wrap(err, "failed to read roles of scope")
+	}
+	return names, nil
+}
+
+// FindGrantedUsernames reads names of users which are granted scope by any of their roles, inherited scopes
+// and roles assigned in organizations included
+func (d *ScopeDao) FindGrantedUsernames(ctx context.Context, id string) ([]string, error) {
+	usernames := make([]string, 0)
+	q := `SELECT U.USERNAME FROM USERS AS U WHERE
+		EXISTS(SELECT 1 FROM USER_AUTH AS UA WHERE UA.USER_ID = U.ID AND UA.SCOPE_ID = $1 AND
+			(UA.VALID_FROM IS NULL OR UA.VALID_FROM <= CURRENT_TIMESTAMP) AND (UA.VALID_UNTIL IS NULL OR UA.VALID_UNTIL > CURRENT_TIMESTAMP)) OR
+		EXISTS(SELECT 1 FROM ORGANIZATION_USER_AUTH AS OUA WHERE OUA.USER_ID = U.ID AND OUA.SCOPE_ID = $1)
+		ORDER BY U.USERNAME`
+	if err := sqlx.SelectContext(ctx, d.ec, &usernames, q, id); err != nil {
+		return nil, errors.Wrap(err, "failed to read users granted with scope")
+	}
+	return usernames, nil
+}
+
 func (d *ScopeDao) FindById(ctx context.Context, id string) (ScopeDto, error) {
 	var sc ScopeDto
 	q := "SELECT * FROM SCOPES WHERE ID = $1 LIMIT 1"
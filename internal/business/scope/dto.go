@@ -10,6 +10,27 @@ func (s ScopeDto) IsPresent() bool {
 	return s.Id != ""
 }
 
+// UpdateScopeDto holds fields to change, missing description is left unchanged and empty one clears it
+type UpdateScopeDto struct {
+	Description *string `json:"description"`
+}
+
+// DeletionReportDto lists roles which lost scope on deletion and users who were granted scope by them,
+// or roles and users which prevent deletion if it was rejected
+type DeletionReportDto struct {
+	Scope    string   `json:"scope"`
+	Cascaded bool     `json:"cascaded"`
+	Roles    []string `json:"roles"`
+	Clients  []string `json:"clients"`
+	Users    []string `json:"users"`
+}
+
+// IsRejected reports if deletion must be rejected, i.e. scope is assigned to roles or clients and deletion isn't cascaded.
+// Users are granted scope only through roles, so they don't block deletion on their own.
+func (dto DeletionReportDto) IsRejected() bool {
+	return (len(dto.Roles) > 0 || len(dto.Clients) > 0) && !dto.Cascaded
+}
+
 type NewScopeDto struct {
 	Name        string  `json:"name"`
 	Description *string `json:"description"`
@@ -6,6 +6,7 @@ import (
 	"github.com/google/uuid"
 	pkgerrors "github.com/pkg/errors"
 	valueobj "github.com/umalmyha/authsrv/internal/business/value-object"
+	"github.com/umalmyha/authsrv/pkg/authz"
 	"github.com/umalmyha/authsrv/pkg/errors"
 )
 
@@ -26,6 +27,12 @@ func FromNewScopeDto(dto NewScopeDto, existFn isExistingScopeNameFn) (*Scope, er
 		)
 	}
 
+	if err := authz.ValidateScope(dto.Name); dto.Name != "" && err != nil {
+		validation.Add(
+			errors.NewBusinessErr(err.Error(), "name", errors.ViolationSeverityErr, errors.CodeValidationFailed),
+		)
+	}
+
 	exist, err := existFn(dto.Name)
 	if err != nil {
 		return nil, pkgerrors.Wrap(err, "faield to check scope existence")
@@ -50,3 +57,16 @@ func FromNewScopeDto(dto NewScopeDto, existFn isExistingScopeNameFn) (*Scope, er
 		description: valueobj.NewNilStringFromPtr(dto.Description),
 	}, nil
 }
+
+func fromDbDto(dto ScopeDto) (*Scope, error) {
+	name, err := valueobj.NewSolidString(dto.Name)
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "failed to build scope name from db entry")
+	}
+
+	return &Scope{
+		id:          dto.Id,
+		name:        name,
+		description: valueobj.NewNilStringFromPtr(dto.Description),
+	}, nil
+}
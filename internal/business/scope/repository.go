@@ -5,6 +5,7 @@ import (
 
 	"github.com/jmoiron/sqlx"
 	"github.com/pkg/errors"
+	valueobj "github.com/umalmyha/authsrv/internal/business/value-object"
 )
 
 type Repository struct {
@@ -24,3 +25,57 @@ func (r *Repository) Create(ctx context.Context, scope *Scope) error {
 	}
 	return nil
 }
+
+func (r *Repository) Update(ctx context.Context, scope *Scope) error {
+	if err := NewScopeDao(r.db).Update(ctx, scope.Dto()); err != nil {
+		return errors.Wrap(err, "failed to update scope")
+	}
+	return nil
+}
+
+// Remove deletes scope, assignments to roles and OAuth clients are deleted only if policy cascades
+func (r *Repository) Remove(ctx context.Context, scope *Scope, policy valueobj.DeletePolicy) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to open transaction")
+	}
+	defer tx.Rollback()
+
+	dao := NewScopeDao(tx)
+
+	// scope assigned to roles or clients is protected by foreign key unless deletion is cascaded
+	if policy.IsCascade() {
+		if err := dao.DeleteRoleAssignments(ctx, scope.Id()); err != nil {
+			return err
+		}
+
+		if err := dao.DeleteClientAssignments(ctx, scope.Id()); err != nil {
+			return err
+		}
+	}
+
+	if err := dao.Delete(ctx, scope.Id()); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "failed to commit transaction")
+	}
+	return nil
+}
+
+func (r *Repository) FindById(ctx context.Context, id string) (*Scope, error) {
+	dto, err := NewScopeDao(r.db).FindById(ctx, id)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read scope by id")
+	}
+	return fromDbDto(dto)
+}
+
+func (r *Repository) FindByName(ctx context.Context, name string) (*Scope, error) {
+	dto, err := NewScopeDao(r.db).FindByName(ctx, name)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read scope by name")
+	}
+	return fromDbDto(dto)
+}
@@ -10,6 +10,14 @@ type Scope struct {
 	description valueobj.NilString
 }
 
+func (s *Scope) Id() string {
+	return s.id
+}
+
+func (s *Scope) Name() string {
+	return s.name.String()
+}
+
 func (s *Scope) ChangeDescription(descr string) {
 	s.description = valueobj.NewNilString(descr)
 }
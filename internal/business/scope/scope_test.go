@@ -0,0 +1,36 @@
+package scope
+
+import (
+	"testing"
+)
+
+const (
+	success = "\u2713"
+	failed  = "\u2717"
+)
+
+func TestDeletionReport(t *testing.T) {
+	cases := []struct {
+		when     string
+		report   DeletionReportDto
+		rejected bool
+	}{
+		{"scope isn't used", DeletionReportDto{}, false},
+		{"scope is assigned to role", DeletionReportDto{Roles: []string{"viewer"}, Users: []string{"john"}}, true},
+		{"scope is allowed for client", DeletionReportDto{Clients: []string{"web"}}, true},
+		{"used scope deletion is cascaded", DeletionReportDto{Cascaded: true, Roles: []string{"viewer"}, Clients: []string{"web"}}, false},
+	}
+
+	t.Log("Given the need to test scope delete policy")
+	{
+		for i, c := range cases {
+			t.Logf("\tTest %d:\tWhen %s", i+1, c.when)
+			{
+				if c.report.IsRejected() != c.rejected {
+					t.Fatalf("\t%s\tDeletion rejection must be %t", failed, c.rejected)
+				}
+				t.Logf("\t%s\tDeletion rejection must be %t", success, c.rejected)
+			}
+		}
+	}
+}
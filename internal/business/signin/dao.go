@@ -0,0 +1,71 @@
+package signin
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+	dbredis "github.com/umalmyha/authsrv/pkg/database/redis"
+)
+
+const sessionKeyPrefix = "signin_session:"
+
+type SessionDao struct {
+	*dbredis.Store
+}
+
+func NewSessionDao(rdb *redis.Client) *SessionDao {
+	return &SessionDao{
+		Store: dbredis.NewStore(rdb),
+	}
+}
+
+func (dao *SessionDao) Save(ctx context.Context, id string, dto SessionDto) error {
+	encoded, err := dbredis.EncodeGob(dto)
+	if err != nil {
+		return errors.Wrap(err, "failed to serialize sign in session to gob format")
+	}
+
+	ttl := time.Until(dto.ExpiresAt)
+	if ttl <= 0 {
+		return errors.New("sign in session is already expired")
+	}
+
+	if err := dao.Client().Set(ctx, sessionKey(id), encoded, ttl).Err(); err != nil {
+		return errors.Wrap(err, "failed to save sign in session")
+	}
+	return nil
+}
+
+func (dao *SessionDao) Find(ctx context.Context, id string) (SessionDto, error) {
+	var dto SessionDto
+
+	encoded, err := dao.Client().Get(ctx, sessionKey(id)).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return dto, nil
+		}
+		return dto, errors.Wrap(err, "failed to read sign in session")
+	}
+
+	if err := dbredis.DecodeGob([]byte(encoded), &dto); err != nil {
+		return dto, errors.Wrap(err, "failed to deserialize sign in session from gob format")
+	}
+	return dto, nil
+}
+
+func (dao *SessionDao) Delete(ctx context.Context, id string) error {
+	if err := dao.Client().Del(ctx, sessionKey(id)).Err(); err != nil {
+		return errors.Wrap(err, "failed to delete sign in session")
+	}
+	return nil
+}
+
+// sessions are stored by hash of id, so plain ids never reach the storage
+func sessionKey(id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return sessionKeyPrefix + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,23 @@
+package signin
+
+import "time"
+
+type SessionDto struct {
+	UserId    string
+	Amr       []string
+	AuthTime  time.Time
+	ExpiresAt time.Time
+}
+
+func (dto SessionDto) IsPresent() bool {
+	return dto.UserId != ""
+}
+
+func (dto SessionDto) ToSession() *Session {
+	return &Session{
+		userId:    dto.UserId,
+		amr:       dto.Amr,
+		authTime:  dto.AuthTime,
+		expiresAt: dto.ExpiresAt,
+	}
+}
@@ -0,0 +1,81 @@
+package signin
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"time"
+
+	"github.com/pkg/errors"
+
+	valueobj "github.com/umalmyha/authsrv/internal/business/value-object"
+)
+
+const sessionIdLength = 32
+
+// Session is sign in of user on authorization page, it lets user authorize clients without entering credentials
+// again until it expires. Plain id is known only to browser of user.
+type Session struct {
+	id        string
+	userId    string
+	amr       []string
+	authTime  time.Time
+	expiresAt time.Time
+}
+
+func NewSession(userId string, amr []string, authTime time.Time, cfg valueobj.OAuthConfig) (*Session, error) {
+	if userId == "" {
+		return nil, errors.New("user is mandatory for sign in session")
+	}
+
+	if len(amr) == 0 {
+		return nil, errors.New("authentication methods are mandatory for sign in session")
+	}
+
+	id := make([]byte, sessionIdLength)
+	if _, err := rand.Read(id); err != nil {
+		return nil, errors.Wrap(err, "failed to generate sign in session id")
+	}
+
+	return &Session{
+		id:        base64.RawURLEncoding.EncodeToString(id),
+		userId:    userId,
+		amr:       amr,
+		authTime:  authTime,
+		expiresAt: authTime.Add(cfg.SessionTimeToLive()),
+	}, nil
+}
+
+// Id returns plain session id, it is available only for newly created sessions
+func (s *Session) Id() string {
+	return s.id
+}
+
+func (s *Session) UserId() string {
+	return s.userId
+}
+
+// Amr returns authentication methods user was authenticated with on sign in
+func (s *Session) Amr() []string {
+	return s.amr
+}
+
+func (s *Session) AuthTime() time.Time {
+	return s.authTime
+}
+
+func (s *Session) ExpiresAt() time.Time {
+	return s.expiresAt
+}
+
+func (s *Session) IsExpired(now time.Time) bool {
+	return !s.expiresAt.After(now)
+}
+
+func (s *Session) Dto() SessionDto {
+	return SessionDto{
+		UserId:    s.userId,
+		Amr:       s.amr,
+		AuthTime:  s.authTime,
+		ExpiresAt: s.expiresAt,
+	}
+}
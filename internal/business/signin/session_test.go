@@ -0,0 +1,65 @@
+package signin
+
+import (
+	"testing"
+	"time"
+
+	valueobj "github.com/umalmyha/authsrv/internal/business/value-object"
+)
+
+const (
+	success = "\u2713"
+	failed  = "\u2717"
+)
+
+func TestNewSession(t *testing.T) {
+	cfg, err := valueobj.NewOAuthConfig(time.Minute, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	authTime := time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	t.Log("Given the need to test sign in session on authorization page")
+	{
+		t.Logf("\tTest 1:\tWhen session is created for authenticated user")
+		{
+			session, err := NewSession("user-id", []string{valueobj.AmrPassword}, authTime, cfg)
+			if err != nil {
+				t.Fatalf("\t%s\tUnexpected error occurred: %v", failed, err)
+			}
+
+			other, err := NewSession("user-id", []string{valueobj.AmrPassword}, authTime, cfg)
+			if err != nil {
+				t.Fatalf("\t%s\tUnexpected error occurred: %v", failed, err)
+			}
+
+			if len(session.Id()) < 43 || session.Id() == other.Id() {
+				t.Fatalf("\t%s\tSession id must be random 256-bit value, got %q", failed, session.Id())
+			}
+			t.Logf("\t%s\tSession id must be random 256-bit value", success)
+
+			if session.IsExpired(authTime.Add(time.Hour-time.Second)) || !session.IsExpired(authTime.Add(time.Hour)) {
+				t.Fatalf("\t%s\tSession must expire once ttl passed", failed)
+			}
+			t.Logf("\t%s\tSession must expire once ttl passed", success)
+
+			restored := session.Dto().ToSession()
+			if restored.Id() != "" || restored.UserId() != "user-id" || !restored.AuthTime().Equal(authTime) {
+				t.Fatalf("\t%s\tStored session must keep user and authentication time, but never plain id", failed)
+			}
+			t.Logf("\t%s\tStored session must keep user and authentication time, but never plain id", success)
+		}
+
+		t.Logf("\tTest 2:\tWhen user or authentication methods are missing")
+		{
+			if _, err := NewSession("", []string{valueobj.AmrPassword}, authTime, cfg); err == nil {
+				t.Fatalf("\t%s\tSession without user must be rejected", failed)
+			}
+
+			if _, err := NewSession("user-id", nil, authTime, cfg); err == nil {
+				t.Fatalf("\t%s\tSession without authentication methods must be rejected", failed)
+			}
+			t.Logf("\t%s\tIncomplete session must be rejected", success)
+		}
+	}
+}
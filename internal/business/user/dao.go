@@ -3,9 +3,12 @@ package user
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/pkg/errors"
+	valueobj "github.com/umalmyha/authsrv/internal/business/value-object"
 	"github.com/umalmyha/authsrv/pkg/database/rdb"
 )
 
@@ -24,11 +27,17 @@ func (dao *UserDao) CreateMulti(ctx context.Context, users []UserDto) error {
 		"ID",
 		"USERNAME",
 		"EMAIL",
+		"EMAIL_VERIFIED_AT",
 		"PASSWORD_HASH",
 		"IS_SUPERUSER",
 		"FIRST_NAME",
 		"LAST_NAME",
 		"MIDDLE_NAME",
+		"MFA_ENABLED",
+		"TOTP_SECRET",
+		"TOTP_LAST_STEP",
+		"LOCKED_UNTIL",
+		"DISABLED_AT",
 	}
 
 	applier := func(user UserDto) []any {
@@ -36,11 +45,17 @@ func (dao *UserDao) CreateMulti(ctx context.Context, users []UserDto) error {
 			user.Id,
 			user.Username,
 			user.Email,
+			user.EmailVerifiedAt,
 			user.Password,
 			user.IsSuperuser,
 			user.FirstName,
 			user.LastName,
 			user.MiddleName,
+			user.MfaEnabled,
+			user.TotpSecret,
+			user.TotpLastStep,
+			user.LockedUntil,
+			user.DisabledAt,
 		}
 	}
 
@@ -73,13 +88,33 @@ func (dao *UserDao) DeleteWhereIdsIn(ctx context.Context, ids []string) error {
 func (dao *UserDao) Update(ctx context.Context, user UserDto) error {
 	q := `UPDATE USERS SET
 		EMAIL = $1,
-		FIRST_NAME = $2,
-		LAST_NAME = $3,
-		MIDDLE_NAME = $4,
-		IS_SUPERUSER = $5,
-		PASSWORD_HASH = $6 WHERE ID = $7`
+		EMAIL_VERIFIED_AT = $2,
+		FIRST_NAME = $3,
+		LAST_NAME = $4,
+		MIDDLE_NAME = $5,
+		IS_SUPERUSER = $6,
+		PASSWORD_HASH = $7,
+		MFA_ENABLED = $8,
+		TOTP_SECRET = $9,
+		TOTP_LAST_STEP = $10,
+		LOCKED_UNTIL = $11,
+		DISABLED_AT = $12 WHERE ID = $13`
 
-	params := []any{user.Email, user.FirstName, user.LastName, user.MiddleName, user.IsSuperuser, user.Password, user.Id}
+	params := []any{
+		user.Email,
+		user.EmailVerifiedAt,
+		user.FirstName,
+		user.LastName,
+		user.MiddleName,
+		user.IsSuperuser,
+		user.Password,
+		user.MfaEnabled,
+		user.TotpSecret,
+		user.TotpLastStep,
+		user.LockedUntil,
+		user.DisabledAt,
+		user.Id,
+	}
 	if _, err := dao.ec.ExecContext(ctx, q, params...); err != nil {
 		return errors.Wrap(err, "failed to update user")
 	}
@@ -96,6 +131,149 @@ func (dao *UserDao) FindByUsername(ctx context.Context, username string) (UserDt
 	return user, nil
 }
 
+// FindByVerifiedEmail looks up user by normalized email, unverified emails are ignored
+func (dao *UserDao) FindByVerifiedEmail(ctx context.Context, email string) (UserDto, error) {
+	var user UserDto
+	q := "SELECT * FROM USERS WHERE LOWER(TRIM(EMAIL)) = $1 AND EMAIL_VERIFIED_AT IS NOT NULL LIMIT 1"
+	if err := sqlx.GetContext(ctx, dao.ec, &user, q, valueobj.NormalizeEmail(email)); err != nil {
+		return user, errors.Wrap(err, "failed to read user by email")
+	}
+	return user, nil
+}
+
+// ExistsByEmail reports if email is verified by other user than given one, unverified email doesn't reserve address
+func (dao *UserDao) ExistsByEmail(ctx context.Context, email string, exceptUserId string) (bool, error) {
+	var exists bool
+	q := "SELECT EXISTS(SELECT 1 FROM USERS WHERE LOWER(TRIM(EMAIL)) = $1 AND EMAIL_VERIFIED_AT IS NOT NULL AND ID::TEXT <> $2)"
+	if err := sqlx.GetContext(ctx, dao.ec, &exists, q, valueobj.NormalizeEmail(email), exceptUserId); err != nil {
+		return false, errors.Wrap(err, "failed to check email existence")
+	}
+	return exists, nil
+}
+
+func (dao *UserDao) FindById(ctx context.Context, id string) (UserDto, error) {
+	var user UserDto
+	q := "SELECT * FROM USERS WHERE ID = $1 LIMIT 1"
+	if err := sqlx.GetContext(ctx, dao.ec, &user, q, id); err != nil {
+		return user, errors.Wrap(err, "failed to read user by id")
+	}
+	return user, nil
+}
+
+// userSortColumns maps sort fields accepted by users list to columns, so sort input never reaches SQL as is
+var userSortColumns = map[string]string{
+	"username":  "USERNAME",
+	"email":     "EMAIL",
+	"firstName": "FIRST_NAME",
+	"lastName":  "LAST_NAME",
+}
+
+// IsUserSortField reports if users list can be sorted by given field
+func IsUserSortField(field string) bool {
+	_, ok := userSortColumns[field]
+	return ok
+}
+
+// FindAll reads page of users matching filter, users are sorted by username unless other field is requested
+func (dao *UserDao) FindAll(ctx context.Context, filter UserFilterDto) ([]UserDto, error) {
+	where, params := userFilterWhere(filter)
+
+	sortCol, ok := userSortColumns[filter.SortBy]
+	if !ok {
+		sortCol = "USERNAME"
+	}
+
+	direction := "ASC"
+	if filter.SortDesc {
+		direction = "DESC"
+	}
+
+	params = append(params, filter.Limit, filter.Offset)
+	q := fmt.Sprintf(
+		"SELECT * FROM USERS%s ORDER BY %s %s NULLS LAST, ID LIMIT $%d OFFSET $%d",
+		where,
+		sortCol,
+		direction,
+		len(params)-1,
+		len(params),
+	)
+
+	users := make([]UserDto, 0)
+	if err := sqlx.SelectContext(ctx, dao.ec, &users, q, params...); err != nil {
+		return nil, errors.Wrap(err, "failed to read users")
+	}
+	return users, nil
+}
+
+// Count returns number of users matching filter, pagination of filter is ignored
+func (dao *UserDao) Count(ctx context.Context, filter UserFilterDto) (int, error) {
+	where, params := userFilterWhere(filter)
+
+	var count int
+	q := "SELECT COUNT(*) FROM USERS" + where
+	if err := sqlx.GetContext(ctx, dao.ec, &count, q, params...); err != nil {
+		return 0, errors.Wrap(err, "failed to count users")
+	}
+	return count, nil
+}
+
+// activeUserAuth limits USER_AUTH rows to assignments effective right now, so expired ones which aren't swept yet
+// and ones which aren't effective yet are ignored, roles of groups have no validity
+const activeUserAuth = "(VALID_FROM IS NULL OR VALID_FROM <= CURRENT_TIMESTAMP) AND (VALID_UNTIL IS NULL OR VALID_UNTIL > CURRENT_TIMESTAMP)"
+
+func userFilterWhere(filter UserFilterDto) (string, []any) {
+	conds := make([]string, 0)
+	params := make([]any, 0)
+
+	if filter.Username != "" {
+		params = append(params, likePattern(filter.Username))
+		conds = append(conds, fmt.Sprintf("USERNAME ILIKE $%d", len(params)))
+	}
+
+	if filter.Email != "" {
+		params = append(params, likePattern(filter.Email))
+		conds = append(conds, fmt.Sprintf("EMAIL ILIKE $%d", len(params)))
+	}
+
+	if filter.Organization != "" {
+		params = append(params, filter.Organization)
+		orgParam := len(params)
+
+		if filter.Role != "" {
+			params = append(params, filter.Role)
+			conds = append(conds, fmt.Sprintf(
+				"EXISTS(SELECT 1 FROM ORGANIZATION_USER_AUTH AS OUA WHERE OUA.USER_ID = USERS.ID AND OUA.ORGANIZATION_NAME = $%d AND OUA.ROLE_NAME = $%d)",
+				orgParam,
+				len(params),
+			))
+		} else {
+			conds = append(conds, fmt.Sprintf("EXISTS(SELECT 1 FROM ORGANIZATION_USER_AUTH AS OUA WHERE OUA.USER_ID = USERS.ID AND OUA.ORGANIZATION_NAME = $%d)", orgParam))
+		}
+	} else if filter.Role != "" {
+		params = append(params, filter.Role)
+		conds = append(conds, fmt.Sprintf("EXISTS(SELECT 1 FROM USER_AUTH AS UA WHERE UA.USER_ID = USERS.ID AND UA.ROLE_NAME = $%d AND %s)", len(params), activeUserAuth))
+	}
+
+	if filter.Disabled != nil {
+		if *filter.Disabled {
+			conds = append(conds, "DISABLED_AT IS NOT NULL")
+		} else {
+			conds = append(conds, "DISABLED_AT IS NULL")
+		}
+	}
+
+	if len(conds) == 0 {
+		return "", params
+	}
+	return " WHERE " + strings.Join(conds, " AND "), params
+}
+
+// likePattern matches value as substring, LIKE wildcards in value are matched literally
+func likePattern(value string) string {
+	escaper := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+	return "%" + escaper.Replace(value) + "%"
+}
+
 type RoleAssignmentDao struct {
 	ec sqlx.ExtContext
 }
@@ -108,10 +286,10 @@ func NewRoleAssignmentDao(ec sqlx.ExtContext) *RoleAssignmentDao {
 
 func (dao *RoleAssignmentDao) CreateMulti(ctx context.Context, roles []RoleAssignmentDto) error {
 	applier := func(role RoleAssignmentDto) []any {
-		return []any{role.UserId, role.RoleId}
+		return []any{role.UserId, role.RoleId, role.ValidFrom, role.ValidUntil}
 	}
 
-	q, params, err := rdb.BulkInsertQuery("USER_ROLES", []string{"USER_ID", "ROLE_ID"}, roles, applier)
+	q, params, err := rdb.BulkInsertQuery("USER_ROLES", []string{"USER_ID", "ROLE_ID", "VALID_FROM", "VALID_UNTIL"}, roles, applier)
 	if err != nil {
 		return errors.Wrap(err, "failed to build bulk insert SQL query for role assignments creation")
 	}
@@ -138,6 +316,90 @@ func (dao *RoleAssignmentDao) DeleteByUserIdAndRoleIdsIn(ctx context.Context, us
 	return nil
 }
 
+func (dao *RoleAssignmentDao) DeleteWhereUserIdsIn(ctx context.Context, userIds []string) error {
+	inRange, params, err := rdb.WhereIn(userIds)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate SQL where clause")
+	}
+
+	q := fmt.Sprintf("DELETE FROM USER_ROLES WHERE USER_ID IN %s", inRange)
+	if _, err := dao.ec.ExecContext(ctx, q, params...); err != nil {
+		return errors.Wrap(err, "failed to delete role assignments of users")
+	}
+
+	return nil
+}
+
+// Update changes validity of role assignment, assignment swept concurrently is created again
+func (dao *RoleAssignmentDao) Update(ctx context.Context, role RoleAssignmentDto) error {
+	q := `INSERT INTO USER_ROLES(USER_ID, ROLE_ID, VALID_FROM, VALID_UNTIL) VALUES($1, $2, $3, $4)
+		ON CONFLICT(USER_ID, ROLE_ID) DO UPDATE SET VALID_FROM = EXCLUDED.VALID_FROM, VALID_UNTIL = EXCLUDED.VALID_UNTIL`
+	if _, err := dao.ec.ExecContext(ctx, q, role.UserId, role.RoleId, role.ValidFrom, role.ValidUntil); err != nil {
+		return errors.Wrap(err, "failed to update role assignment")
+	}
+	return nil
+}
+
+// DeleteExpired deletes role assignments which validity ended by given moment and returns them
+func (dao *RoleAssignmentDao) DeleteExpired(ctx context.Context, now time.Time) ([]ExpiredRoleAssignmentDto, error) {
+	expired := make([]ExpiredRoleAssignmentDto, 0)
+	q := `WITH EXPIRED AS (
+			DELETE FROM USER_ROLES WHERE VALID_UNTIL <= $1 RETURNING USER_ID, ROLE_ID, VALID_UNTIL
+		)
+		SELECT E.USER_ID, U.USERNAME, E.ROLE_ID, R.NAME AS ROLE_NAME, E.VALID_UNTIL FROM EXPIRED AS E
+		INNER JOIN USERS AS U ON U.ID = E.USER_ID
+		INNER JOIN ROLES AS R ON R.ID = E.ROLE_ID
+		ORDER BY E.VALID_UNTIL`
+	if err := sqlx.SelectContext(ctx, dao.ec, &expired, q, now); err != nil {
+		return nil, errors.Wrap(err, "failed to delete expired role assignments")
+	}
+	return expired, nil
+}
+
+// FindRoleNamesWhereUserIdsIn reads names of roles effective for given users
+func (dao *RoleAssignmentDao) FindRoleNamesWhereUserIdsIn(ctx context.Context, userIds []string) ([]UserRoleNameDto, error) {
+	roles := make([]UserRoleNameDto, 0)
+	if len(userIds) == 0 {
+		return roles, nil
+	}
+
+	inRange, params, err := rdb.WhereIn(userIds)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate SQL where clause")
+	}
+
+	q := fmt.Sprintf("SELECT DISTINCT USER_ID, ROLE_NAME FROM USER_AUTH WHERE USER_ID IN %s AND %s ORDER BY ROLE_NAME", inRange, activeUserAuth)
+	if err := sqlx.SelectContext(ctx, dao.ec, &roles, q, params...); err != nil {
+		return nil, errors.Wrap(err, "failed to read role names of users")
+	}
+	return roles, nil
+}
+
+// FindOrganizationRoleNamesWhereUserIdsIn reads names of roles given users have in organization only
+func (dao *RoleAssignmentDao) FindOrganizationRoleNamesWhereUserIdsIn(ctx context.Context, organization string, userIds []string) ([]UserRoleNameDto, error) {
+	roles := make([]UserRoleNameDto, 0)
+	if len(userIds) == 0 {
+		return roles, nil
+	}
+
+	inRange, params, err := rdb.WhereIn(userIds)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate SQL where clause")
+	}
+	params = append(params, organization)
+
+	// member without roles has single row without role
+	q := fmt.Sprintf(
+		"SELECT DISTINCT USER_ID, ROLE_NAME FROM ORGANIZATION_USER_AUTH WHERE USER_ID IN %s AND ORGANIZATION_NAME = $%d AND ROLE_NAME IS NOT NULL ORDER BY ROLE_NAME",
+		inRange,
+		len(params),
+	)
+	if err := sqlx.SelectContext(ctx, dao.ec, &roles, q, params...); err != nil {
+		return nil, errors.Wrap(err, "failed to read organization role names of users")
+	}
+	return roles, nil
+}
+
 type UserAuthDao struct {
 	ec sqlx.ExtContext
 }
@@ -150,10 +412,124 @@ func NewUserAuthDao(ec sqlx.ExtContext) *UserAuthDao {
 
 func (dao *UserAuthDao) FindAllForUser(ctx context.Context, userId string) ([]UserAuthDto, error) {
 	userAuth := make([]UserAuthDto, 0)
-	q := "SELECT ROLE_ID, ROLE_NAME, SCOPE_ID, SCOPE_NAME FROM USER_AUTH WHERE USER_ID = $1"
+	// role without scopes has no scope columns in view
+	q := `SELECT ROLE_ID, ROLE_NAME, COALESCE(GROUP_ID::TEXT, '') AS GROUP_ID, COALESCE(SCOPE_ID::TEXT, '') AS SCOPE_ID, COALESCE(SCOPE_NAME, '') AS SCOPE_NAME,
+		VALID_FROM, VALID_UNTIL FROM USER_AUTH WHERE USER_ID = $1`
 
 	if err := sqlx.SelectContext(ctx, dao.ec, &userAuth, q, userId); err != nil {
 		return nil, errors.Wrap(err, "failed to read users auth data")
 	}
 	return userAuth, nil
 }
+
+// DeleteGroupMembershipsWhereUserIdsIn removes users from all groups
+func (dao *UserAuthDao) DeleteGroupMembershipsWhereUserIdsIn(ctx context.Context, userIds []string) error {
+	inRange, params, err := rdb.WhereIn(userIds)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate SQL where clause for group memberships deletion")
+	}
+
+	if _, err := dao.ec.ExecContext(ctx, fmt.Sprintf("DELETE FROM GROUPS_MEMBERS WHERE USER_ID IN %s", inRange), params...); err != nil {
+		return errors.Wrap(err, "failed to delete group memberships of users")
+	}
+	return nil
+}
+
+type OrganizationAuthDao struct {
+	ec sqlx.ExtContext
+}
+
+func NewOrganizationAuthDao(ec sqlx.ExtContext) *OrganizationAuthDao {
+	return &OrganizationAuthDao{
+		ec: ec,
+	}
+}
+
+func (dao *OrganizationAuthDao) FindAllForUser(ctx context.Context, userId string) ([]OrganizationAuthDto, error) {
+	orgAuth := make([]OrganizationAuthDto, 0)
+	q := `SELECT ORGANIZATION_NAME, COALESCE(ROLE_NAME, '') AS ROLE_NAME, COALESCE(SCOPE_NAME, '') AS SCOPE_NAME
+		FROM ORGANIZATION_USER_AUTH WHERE USER_ID = $1`
+
+	if err := sqlx.SelectContext(ctx, dao.ec, &orgAuth, q, userId); err != nil {
+		return nil, errors.Wrap(err, "failed to read users organization auth data")
+	}
+	return orgAuth, nil
+}
+
+// DeleteMembershipsWhereUserIdsIn removes users from all organizations together with roles assigned in them
+func (dao *OrganizationAuthDao) DeleteMembershipsWhereUserIdsIn(ctx context.Context, userIds []string) error {
+	inRange, params, err := rdb.WhereIn(userIds)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate SQL where clause for memberships deletion")
+	}
+
+	if _, err := dao.ec.ExecContext(ctx, fmt.Sprintf("DELETE FROM ORGANIZATION_USER_ROLES WHERE USER_ID IN %s", inRange), params...); err != nil {
+		return errors.Wrap(err, "failed to delete organization roles of users")
+	}
+
+	if _, err := dao.ec.ExecContext(ctx, fmt.Sprintf("DELETE FROM ORGANIZATION_MEMBERS WHERE USER_ID IN %s", inRange), params...); err != nil {
+		return errors.Wrap(err, "failed to delete organization memberships of users")
+	}
+	return nil
+}
+
+type RecoveryCodeDao struct {
+	ec sqlx.ExtContext
+}
+
+func NewRecoveryCodeDao(ec sqlx.ExtContext) *RecoveryCodeDao {
+	return &RecoveryCodeDao{
+		ec: ec,
+	}
+}
+
+func (dao *RecoveryCodeDao) CreateMulti(ctx context.Context, codes []RecoveryCodeDto) error {
+	applier := func(code RecoveryCodeDto) []any {
+		return []any{code.Id, code.UserId, code.CodeHash}
+	}
+
+	q, params, err := rdb.BulkInsertQuery("USER_RECOVERY_CODES", []string{"ID", "USER_ID", "CODE_HASH"}, codes, applier)
+	if err != nil {
+		return errors.Wrap(err, "failed to build bulk insert SQL query for recovery codes creation")
+	}
+
+	if _, err := dao.ec.ExecContext(ctx, q, params...); err != nil {
+		return errors.Wrap(err, "failed to create recovery codes")
+	}
+
+	return nil
+}
+
+func (dao *RecoveryCodeDao) DeleteWhereIdsIn(ctx context.Context, ids []string) error {
+	inRange, params, err := rdb.WhereIn(ids)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate SQL where clause for recovery codes deletion")
+	}
+
+	q := fmt.Sprintf("DELETE FROM USER_RECOVERY_CODES WHERE ID IN %s", inRange)
+	res, err := dao.ec.ExecContext(ctx, q, params...)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete recovery codes")
+	}
+
+	// code deleted concurrently was consumed by another request, so it mustn't be accepted twice
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "failed to read number of deleted recovery codes")
+	}
+
+	if deleted != int64(len(ids)) {
+		return RecoveryCodeUsedErr
+	}
+	return nil
+}
+
+func (dao *RecoveryCodeDao) FindAllForUser(ctx context.Context, userId string) ([]RecoveryCodeDto, error) {
+	codes := make([]RecoveryCodeDto, 0)
+	q := "SELECT ID, USER_ID, CODE_HASH FROM USER_RECOVERY_CODES WHERE USER_ID = $1"
+
+	if err := sqlx.SelectContext(ctx, dao.ec, &codes, q, userId); err != nil {
+		return nil, errors.Wrap(err, "failed to read user recovery codes")
+	}
+	return codes, nil
+}
@@ -0,0 +1,94 @@
+package user
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUserFilterWhere(t *testing.T) {
+	disabled, enabled := true, false
+
+	cases := []struct {
+		when   string
+		filter UserFilterDto
+		where  string
+		params []any
+	}{
+		{"filter is empty", UserFilterDto{}, "", []any{}},
+		{
+			"username and email are filtered",
+			UserFilterDto{Username: "jo", Email: "example"},
+			" WHERE USERNAME ILIKE $1 AND EMAIL ILIKE $2",
+			[]any{"%jo%", "%example%"},
+		},
+		{
+			"like wildcards are provided",
+			UserFilterDto{Username: `j_o%n\`},
+			" WHERE USERNAME ILIKE $1",
+			[]any{`%j\_o\%n\\%`},
+		},
+		{
+			"role is filtered",
+			UserFilterDto{Role: "admin"},
+			" WHERE EXISTS(SELECT 1 FROM USER_AUTH AS UA WHERE UA.USER_ID = USERS.ID AND UA.ROLE_NAME = $1 AND " + activeUserAuth + ")",
+			[]any{"admin"},
+		},
+		{
+			"role is filtered within organization",
+			UserFilterDto{Organization: "acme", Role: "admin"},
+			" WHERE EXISTS(SELECT 1 FROM ORGANIZATION_USER_AUTH AS OUA WHERE OUA.USER_ID = USERS.ID AND OUA.ORGANIZATION_NAME = $1 AND OUA.ROLE_NAME = $2)",
+			[]any{"acme", "admin"},
+		},
+		{
+			"organization is filtered",
+			UserFilterDto{Username: "jo", Organization: "acme"},
+			" WHERE USERNAME ILIKE $1 AND EXISTS(SELECT 1 FROM ORGANIZATION_USER_AUTH AS OUA WHERE OUA.USER_ID = USERS.ID AND OUA.ORGANIZATION_NAME = $2)",
+			[]any{"%jo%", "acme"},
+		},
+		{"disabled users are filtered", UserFilterDto{Disabled: &disabled}, " WHERE DISABLED_AT IS NOT NULL", []any{}},
+		{"enabled users are filtered", UserFilterDto{Disabled: &enabled}, " WHERE DISABLED_AT IS NULL", []any{}},
+	}
+
+	t.Log("Given the need to test users list filter")
+	{
+		for i, c := range cases {
+			t.Logf("\tTest %d:\tWhen %s", i+1, c.when)
+			{
+				where, params := userFilterWhere(c.filter)
+				if where != c.where || !reflect.DeepEqual(params, c.params) {
+					t.Fatalf("\t%s\tCondition must be %q with %v, got %q with %v", failed, c.where, c.params, where, params)
+				}
+				t.Logf("\t%s\tCondition must be built with parameters only", success)
+			}
+		}
+	}
+}
+
+func TestIsUserSortField(t *testing.T) {
+	cases := []struct {
+		field    string
+		sortable bool
+	}{
+		{"username", true},
+		{"email", true},
+		{"firstName", true},
+		{"lastName", true},
+		{"password", false},
+		{"USERNAME", false},
+		{"username; DROP TABLE USERS", false},
+		{"", false},
+	}
+
+	t.Log("Given the need to test users list sort whitelist")
+	{
+		for i, c := range cases {
+			t.Logf("\tTest %d:\tWhen users are sorted by %q", i+1, c.field)
+			{
+				if IsUserSortField(c.field) != c.sortable {
+					t.Fatalf("\t%s\tSorting must be allowed %t", failed, c.sortable)
+				}
+				t.Logf("\t%s\tSorting must be allowed %t", success, c.sortable)
+			}
+		}
+	}
+}
@@ -2,19 +2,32 @@ package user
 
 import (
 	"fmt"
+	"time"
 
+	"github.com/umalmyha/authsrv/internal/business/refresh"
+	"github.com/umalmyha/authsrv/internal/business/webauthn"
 	"github.com/umalmyha/authsrv/pkg/helpers"
 )
 
 type UserDto struct {
-	Id          string  `db:"id"`
-	Username    string  `db:"username"`
-	Email       *string `db:"email"`
-	Password    string  `db:"password_hash"`
-	IsSuperuser bool    `db:"is_superuser"`
-	FirstName   *string `db:"first_name"`
-	LastName    *string `db:"last_name"`
-	MiddleName  *string `db:"middle_name"`
+	Id       string  `db:"id"`
+	Username string  `db:"username"`
+	Email    *string `db:"email"`
+	// EmailVerifiedAt is set once user follows verification link, it is reset on email change
+	EmailVerifiedAt *time.Time `db:"email_verified_at"`
+	Password        string     `db:"password_hash"`
+	IsSuperuser     bool       `db:"is_superuser"`
+	FirstName       *string    `db:"first_name"`
+	LastName        *string    `db:"last_name"`
+	MiddleName      *string    `db:"middle_name"`
+	// MfaEnabled is set once TOTP enrollment is confirmed, secret of not confirmed enrollment is kept as well
+	MfaEnabled   bool    `db:"mfa_enabled"`
+	TotpSecret   *string `db:"totp_secret"`
+	TotpLastStep int64   `db:"totp_last_step"`
+	// LockedUntil is set when too many sign in attempts failed, expired value means account is unlocked
+	LockedUntil *time.Time `db:"locked_until"`
+	// DisabledAt is set when administrator disables account, disabled user can't sign in or refresh session
+	DisabledAt *time.Time `db:"disabled_at"`
 }
 
 func (dto UserDto) Key() string {
@@ -30,27 +43,65 @@ func (dto UserDto) Equal(other UserDto) bool {
 		dto.Password == other.Password &&
 		dto.IsSuperuser == other.IsSuperuser &&
 		helpers.EqualValues(dto.Email, other.Email) &&
+		equalTimes(dto.EmailVerifiedAt, other.EmailVerifiedAt) &&
 		helpers.EqualValues(dto.FirstName, other.FirstName) &&
 		helpers.EqualValues(dto.LastName, other.LastName) &&
-		helpers.EqualValues(dto.MiddleName, other.MiddleName)
+		helpers.EqualValues(dto.MiddleName, other.MiddleName) &&
+		dto.MfaEnabled == other.MfaEnabled &&
+		helpers.EqualValues(dto.TotpSecret, other.TotpSecret) &&
+		dto.TotpLastStep == other.TotpLastStep &&
+		equalTimes(dto.LockedUntil, other.LockedUntil) &&
+		equalTimes(dto.DisabledAt, other.DisabledAt)
 }
 
 func (dto UserDto) Clone() UserDto {
 	return UserDto{
-		Id:          dto.Id,
-		Username:    dto.Username,
-		IsSuperuser: dto.IsSuperuser,
-		Password:    dto.Password,
-		Email:       helpers.CopyValue(dto.Email),
-		FirstName:   helpers.CopyValue(dto.FirstName),
-		LastName:    helpers.CopyValue(dto.LastName),
-		MiddleName:  helpers.CopyValue(dto.MiddleName),
+		Id:              dto.Id,
+		Username:        dto.Username,
+		IsSuperuser:     dto.IsSuperuser,
+		Password:        dto.Password,
+		Email:           helpers.CopyValue(dto.Email),
+		EmailVerifiedAt: copyTime(dto.EmailVerifiedAt),
+		FirstName:       helpers.CopyValue(dto.FirstName),
+		LastName:        helpers.CopyValue(dto.LastName),
+		MiddleName:      helpers.CopyValue(dto.MiddleName),
+
+		MfaEnabled:   dto.MfaEnabled,
+		TotpSecret:   helpers.CopyValue(dto.TotpSecret),
+		TotpLastStep: dto.TotpLastStep,
+		LockedUntil:  copyTime(dto.LockedUntil),
+		DisabledAt:   copyTime(dto.DisabledAt),
+	}
+}
+
+// Summary returns user data available for administration, role names are read separately
+func (dto UserDto) Summary(roles []string) UserSummaryDto {
+	if roles == nil {
+		roles = make([]string, 0)
+	}
+
+	return UserSummaryDto{
+		Id:            dto.Id,
+		Username:      dto.Username,
+		Email:         dto.Email,
+		EmailVerified: dto.Email != nil && dto.EmailVerifiedAt != nil,
+		FirstName:     dto.FirstName,
+		MiddleName:    dto.MiddleName,
+		LastName:      dto.LastName,
+		IsSuperuser:   dto.IsSuperuser,
+		MfaEnabled:    dto.MfaEnabled,
+		Roles:         roles,
+		LockedUntil:   dto.LockedUntil,
+		DisabledAt:    dto.DisabledAt,
 	}
 }
 
+// RoleAssignmentDto is role assigned to user directly, empty bounds mean assignment is active without time limit
 type RoleAssignmentDto struct {
-	UserId string `db:"user_id"`
-	RoleId string `db:"role_id"`
+	UserId     string     `db:"user_id"`
+	RoleId     string     `db:"role_id"`
+	ValidFrom  *time.Time `db:"valid_from"`
+	ValidUntil *time.Time `db:"valid_until"`
 }
 
 func (dto RoleAssignmentDto) Key() string {
@@ -62,10 +113,49 @@ func (dto RoleAssignmentDto) IsPresent() bool {
 }
 
 func (dto RoleAssignmentDto) Equal(other RoleAssignmentDto) bool {
-	return dto.UserId == other.UserId && dto.RoleId == other.RoleId
+	return dto.UserId == other.UserId &&
+		dto.RoleId == other.RoleId &&
+		equalTimes(dto.ValidFrom, other.ValidFrom) &&
+		equalTimes(dto.ValidUntil, other.ValidUntil)
 }
 
 func (dto RoleAssignmentDto) Clone() RoleAssignmentDto {
+	return RoleAssignmentDto{
+		UserId:     dto.UserId,
+		RoleId:     dto.RoleId,
+		ValidFrom:  copyTime(dto.ValidFrom),
+		ValidUntil: copyTime(dto.ValidUntil),
+	}
+}
+
+// ExpiredRoleAssignmentDto is role assignment removed by sweeper once its validity ended
+type ExpiredRoleAssignmentDto struct {
+	UserId     string    `db:"user_id"`
+	Username   string    `db:"username"`
+	RoleId     string    `db:"role_id"`
+	RoleName   string    `db:"role_name"`
+	ValidUntil time.Time `db:"valid_until"`
+}
+
+type RecoveryCodeDto struct {
+	Id       string `db:"id"`
+	UserId   string `db:"user_id"`
+	CodeHash string `db:"code_hash"`
+}
+
+func (dto RecoveryCodeDto) Key() string {
+	return dto.Id
+}
+
+func (dto RecoveryCodeDto) IsPresent() bool {
+	return dto.Id != ""
+}
+
+func (dto RecoveryCodeDto) Equal(other RecoveryCodeDto) bool {
+	return dto.Id == other.Id && dto.UserId == other.UserId && dto.CodeHash == other.CodeHash
+}
+
+func (dto RecoveryCodeDto) Clone() RecoveryCodeDto {
 	return dto
 }
 
@@ -80,28 +170,173 @@ type NewUserDto struct {
 	IsSuperuser     bool    `json:"-"`
 }
 
+// UserAuthDto is role of user with its effective scope, group is empty for role assigned directly
 type UserAuthDto struct {
-	UserId    string `db:"user_id"`
-	RoleId    string `db:"role_id"`
-	RoleName  string `db:"role_name"`
-	ScopeId   string `db:"scope_id"`
-	ScopeName string `db:"scope_name"`
+	UserId     string     `db:"user_id"`
+	RoleId     string     `db:"role_id"`
+	RoleName   string     `db:"role_name"`
+	GroupId    string     `db:"group_id"`
+	ScopeId    string     `db:"scope_id"`
+	ScopeName  string     `db:"scope_name"`
+	ValidFrom  *time.Time `db:"valid_from"`
+	ValidUntil *time.Time `db:"valid_until"`
+}
+
+func (dto UserAuthDto) IsDirect() bool {
+	return dto.GroupId == ""
+}
+
+// OrganizationAuthDto is role of user in organization with its effective scope, role and scope are empty
+// for member without roles
+type OrganizationAuthDto struct {
+	OrganizationName string `db:"organization_name"`
+	RoleName         string `db:"role_name"`
+	ScopeName        string `db:"scope_name"`
 }
 
+// SigninDto is sign in with password, verified email is accepted in place of username
+// Organization selects organization session is issued for, token carries only roles user has in it then
 type SigninDto struct {
-	Username    string `json:"-"`
-	Password    string `json:"-"`
-	Fingerprint string `json:"fingerprint"`
+	Username     string             `json:"-"`
+	Password     string             `json:"-"`
+	Fingerprint  string             `json:"fingerprint"`
+	Organization string             `json:"organization"`
+	Client       refresh.ClientInfo `json:"-"`
+}
+
+// MfaVerifyDto completes sign in started with password, code is either TOTP or recovery code
+type MfaVerifyDto struct {
+	ChallengeToken string `json:"challengeToken"`
+	Code           string `json:"code"`
+}
+
+// PasskeySigninDto is sign in with WebAuthn assertion, it replaces both password and second factor
+type PasskeySigninDto struct {
+	Fingerprint string                        `json:"fingerprint"`
+	Credential  webauthn.AssertionResponseDto `json:"credential"`
+	Client      refresh.ClientInfo            `json:"-"`
+}
+
+// PasswordResetDto sets new password with token delivered by email
+type PasswordResetDto struct {
+	Token           string `json:"token"`
+	Password        string `json:"password"`
+	ConfirmPassword string `json:"confirmPassword"`
+}
+
+// PasswordChangeDto changes password of signed in user, other sessions are kept unless revocation is requested
+type PasswordChangeDto struct {
+	CurrentPassword     string `json:"currentPassword"`
+	Password            string `json:"password"`
+	ConfirmPassword     string `json:"confirmPassword"`
+	RevokeOtherSessions bool   `json:"revokeOtherSessions"`
+}
+
+// ProfileDto is user data available for self-service
+type ProfileDto struct {
+	Id            string   `json:"id"`
+	Username      string   `json:"username"`
+	Email         *string  `json:"email"`
+	EmailVerified bool     `json:"emailVerified"`
+	FirstName     *string  `json:"firstName"`
+	MiddleName    *string  `json:"middleName"`
+	LastName      *string  `json:"lastName"`
+	MfaEnabled    bool     `json:"mfaEnabled"`
+	Organizations []string `json:"organizations"`
+}
+
+// ProfileUpdateDto holds fields to change, missing fields are left unchanged and empty strings clear them.
+// Current password is required when user changes own email.
+type ProfileUpdateDto struct {
+	Email           *string `json:"email"`
+	FirstName       *string `json:"firstName"`
+	MiddleName      *string `json:"middleName"`
+	LastName        *string `json:"lastName"`
+	CurrentPassword string  `json:"currentPassword"`
+}
+
+// UserSummaryDto is user data available for administration
+type UserSummaryDto struct {
+	Id            string     `json:"id"`
+	Username      string     `json:"username"`
+	Email         *string    `json:"email"`
+	EmailVerified bool       `json:"emailVerified"`
+	FirstName     *string    `json:"firstName"`
+	MiddleName    *string    `json:"middleName"`
+	LastName      *string    `json:"lastName"`
+	IsSuperuser   bool       `json:"isSuperuser"`
+	MfaEnabled    bool       `json:"mfaEnabled"`
+	Roles         []string   `json:"roles"`
+	LockedUntil   *time.Time `json:"lockedUntil"`
+	DisabledAt    *time.Time `json:"disabledAt"`
+}
+
+// UserFilterDto narrows users list, username and email are matched by case insensitive substring
+// UserFilterDto filters users, role is looked up among roles in organization if organization is set
+type UserFilterDto struct {
+	Username     string
+	Email        string
+	Organization string
+	Role         string
+	Disabled     *bool
+	SortBy       string
+	SortDesc     bool
+	Limit        int
+	Offset       int
+}
+
+type UserPageDto struct {
+	Users  []UserSummaryDto `json:"users"`
+	Total  int              `json:"total"`
+	Limit  int              `json:"limit"`
+	Offset int              `json:"offset"`
+}
+
+type UserRoleNameDto struct {
+	UserId   string `db:"user_id"`
+	RoleName string `db:"role_name"`
 }
 
 type LogoutDto struct {
-	Username       string `json:"user"`
-	Fingerprint    string `json:"fingerprint"`
-	RefreshTokenId string `json:"-"`
+	Username             string    `json:"user"`
+	Fingerprint          string    `json:"fingerprint"`
+	RefreshTokenId       string    `json:"-"`
+	AccessTokenId        string    `json:"-"`
+	AccessTokenExpiresAt time.Time `json:"-"`
 }
 
+// RefreshDto rotates refresh token, session is switched to organization if it is provided
+// and empty organization switches session back to global roles
 type RefreshDto struct {
-	Username       string `json:"user"`
-	Fingerprint    string `json:"fingerprint"`
-	RefreshTokenId string `json:"-"`
+	Username       string             `json:"user"`
+	Fingerprint    string             `json:"fingerprint"`
+	Organization   *string            `json:"organization"`
+	RefreshTokenId string             `json:"-"`
+	Client         refresh.ClientInfo `json:"-"`
+}
+
+// SessionDto describes sign in session, which is the family of refresh tokens rotated one from another
+type SessionDto struct {
+	Id          string    `json:"id"`
+	Fingerprint string    `json:"fingerprint"`
+	IssuedAt    time.Time `json:"issuedAt"`
+	ExpiresAt   time.Time `json:"expiresAt"`
+	LastUsedAt  time.Time `json:"lastUsedAt"`
+	IpAddress   string    `json:"ipAddress"`
+	UserAgent   string    `json:"userAgent"`
+}
+
+func equalTimes(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Equal(*b)
+}
+
+func copyTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	copy := *t
+	return &copy
 }
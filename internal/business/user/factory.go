@@ -8,13 +8,15 @@ import (
 	pkgerrors "github.com/pkg/errors"
 	"github.com/umalmyha/authsrv/internal/business/refresh"
 	valueobj "github.com/umalmyha/authsrv/internal/business/value-object"
+	"github.com/umalmyha/authsrv/internal/business/webauthn"
 	"github.com/umalmyha/authsrv/pkg/errors"
 	"github.com/umalmyha/authsrv/pkg/helpers"
 )
 
 type isExistingUsernameFn func(string) (bool, error)
+type isExistingEmailFn func(string) (bool, error)
 
-func FromNewUserDto(dto NewUserDto, cfg valueobj.PasswordConfig, existFn isExistingUsernameFn) (*User, error) {
+func FromNewUserDto(dto NewUserDto, cfg valueobj.PasswordConfig, existFn isExistingUsernameFn, existEmailFn isExistingEmailFn) (*User, error) {
 	validation := errors.NewValidation()
 
 	if dto.Username == "" {
@@ -55,6 +57,21 @@ func FromNewUserDto(dto NewUserDto, cfg valueobj.PasswordConfig, existFn isExist
 		)
 	}
 
+	if email.String() != "" {
+		if exist, err := existEmailFn(email.String()); err != nil {
+			return nil, pkgerrors.Wrap(err, "failed to check email existence")
+		} else if exist {
+			validation.Add(
+				errors.NewBusinessErr(
+					fmt.Sprintf("email '%s' is already used", email.String()),
+					"email",
+					errors.ViolationSeverityErr,
+					errors.CodeValidationFailed,
+				),
+			)
+		}
+	}
+
 	if dto.Password != dto.ConfirmPassword {
 		validation.Add(
 			errors.NewBusinessErr("passwords don't match", "confirmPassword", errors.ViolationSeverityErr, errors.CodeValidationFailed),
@@ -89,10 +106,15 @@ func FromNewUserDto(dto NewUserDto, cfg valueobj.PasswordConfig, existFn isExist
 		roles:       list.New(),
 		tokens:      list.New(),
 		auth:        valueobj.NewUserAuth(nil, nil),
+
+		organizations: make(map[string]valueobj.UserAuth),
+
+		recoveryCodes: list.New(),
+		credentials:   list.New(),
 	}, nil
 }
 
-func fromDbDtos(user UserDto, roleIds []valueobj.RoleId, tokens []*refresh.RefreshToken, auth valueobj.UserAuth) (*User, error) {
+func fromDbDtos(user UserDto, roles []roleAssignment, tokens []*refresh.RefreshToken, recoveryCodes []valueobj.RecoveryCode, credentials []*webauthn.Credential, auth valueobj.UserAuth, organizations map[string]valueobj.UserAuth) (*User, error) {
 	username, err := valueobj.NewSolidString(user.Username)
 	if err != nil {
 		return nil, pkgerrors.Wrap(err, "failed to build username")
@@ -103,17 +125,34 @@ func fromDbDtos(user UserDto, roleIds []valueobj.RoleId, tokens []*refresh.Refre
 		return nil, pkgerrors.Wrap(err, "failed to build user email")
 	}
 
+	var totpSecret valueobj.TotpSecret
+	if user.TotpSecret != nil {
+		if totpSecret, err = valueobj.TotpSecretFromString(*user.TotpSecret); err != nil {
+			return nil, pkgerrors.Wrap(err, "failed to build user totp secret")
+		}
+	}
+
 	return &User{
-		id:          user.Id,
-		username:    username,
-		email:       email,
-		password:    valueobj.PasswordFromHash(user.Password),
-		isSuperuser: user.IsSuperuser,
-		firstName:   valueobj.NewNilStringFromPtr(user.FirstName),
-		lastName:    valueobj.NewNilStringFromPtr(user.LastName),
-		middleName:  valueobj.NewNilStringFromPtr(user.MiddleName),
-		roles:       helpers.ToList(roleIds),
-		tokens:      helpers.ToList(tokens),
-		auth:        auth,
+		id:              user.Id,
+		username:        username,
+		email:           email,
+		password:        valueobj.PasswordFromHash(user.Password),
+		emailVerifiedAt: user.EmailVerifiedAt,
+		isSuperuser:     user.IsSuperuser,
+		firstName:       valueobj.NewNilStringFromPtr(user.FirstName),
+		lastName:        valueobj.NewNilStringFromPtr(user.LastName),
+		middleName:      valueobj.NewNilStringFromPtr(user.MiddleName),
+		roles:           helpers.ToList(roles),
+		tokens:          helpers.ToList(tokens),
+		auth:            auth,
+		organizations:   organizations,
+
+		mfaEnabled:    user.MfaEnabled,
+		totpSecret:    totpSecret,
+		totpLastStep:  user.TotpLastStep,
+		lockedUntil:   user.LockedUntil,
+		disabledAt:    user.DisabledAt,
+		recoveryCodes: helpers.ToList(recoveryCodes),
+		credentials:   helpers.ToList(credentials),
 	}, nil
 }
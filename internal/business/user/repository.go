@@ -2,11 +2,13 @@ package user
 
 import (
 	"context"
+	"time"
 
 	"github.com/pkg/errors"
 
 	"github.com/umalmyha/authsrv/internal/business/refresh"
 	valueobj "github.com/umalmyha/authsrv/internal/business/value-object"
+	"github.com/umalmyha/authsrv/internal/business/webauthn"
 	"github.com/umalmyha/authsrv/pkg/helpers"
 )
 
@@ -28,6 +30,11 @@ func (repo *Repository) Update(user *User) error {
 	return repo.uow.RegisterAmended(user)
 }
 
+// Remove deletes user together with role assignments, recovery codes, passkeys and refresh tokens
+func (repo *Repository) Remove(user *User) error {
+	return repo.uow.RegisterDeleted(user)
+}
+
 func (repo *Repository) FindByUsername(ctx context.Context, username string) (*User, error) {
 	notPresentFn := func() (UserDto, error) {
 		return NewUserDao(repo.uow.ExtContext()).FindByUsername(ctx, username)
@@ -42,17 +49,76 @@ func (repo *Repository) FindByUsername(ctx context.Context, username string) (*U
 		return nil, errors.Errorf("user %s doesn't exist", username)
 	}
 
+	return repo.loadUser(ctx, user)
+}
+
+// FindByVerifiedEmail finds user by email, which user proved to own
+func (repo *Repository) FindByVerifiedEmail(ctx context.Context, email string) (*User, error) {
+	notPresentFn := func() (UserDto, error) {
+		return NewUserDao(repo.uow.ExtContext()).FindByVerifiedEmail(ctx, email)
+	}
+
+	normalized := valueobj.NormalizeEmail(email)
+	matchFn := func(user UserDto) bool {
+		return user.Email != nil && user.EmailVerifiedAt != nil && valueobj.NormalizeEmail(*user.Email) == normalized
+	}
+
+	user, err := repo.uow.users.Find(matchFn).IfNotPresent(notPresentFn)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read user aggregate")
+	}
+
+	if !user.IsPresent() {
+		return nil, errors.Errorf("user with email %s doesn't exist", email)
+	}
+
+	return repo.loadUser(ctx, user)
+}
+
+func (repo *Repository) FindById(ctx context.Context, id string) (*User, error) {
+	notPresentFn := func() (UserDto, error) {
+		return NewUserDao(repo.uow.ExtContext()).FindById(ctx, id)
+	}
+
+	user, err := repo.uow.users.FindByKey(id).IfNotPresent(notPresentFn)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read user aggregate")
+	}
+
+	if !user.IsPresent() {
+		return nil, errors.Errorf("user with id %s doesn't exist", id)
+	}
+
+	return repo.loadUser(ctx, user)
+}
+
+func (repo *Repository) loadUser(ctx context.Context, user UserDto) (*User, error) {
 	userAuth, err := NewUserAuthDao(repo.uow.ExtContext()).FindAllForUser(ctx, user.Id)
 	if err != nil {
 		return nil, err
 	}
 
+	orgAuth, err := NewOrganizationAuthDao(repo.uow.ExtContext()).FindAllForUser(ctx, user.Id)
+	if err != nil {
+		return nil, err
+	}
+
 	tokens, err := refresh.NewRefreshTokenDao(repo.uow.rdb).FindAllForUser(ctx, user.Id)
 	if err != nil {
 		return nil, err
 	}
 
-	u, err := repo.buildUser(user, userAuth, tokens)
+	recoveryCodes, err := NewRecoveryCodeDao(repo.uow.ExtContext()).FindAllForUser(ctx, user.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	credentials, err := webauthn.NewCredentialDao(repo.uow.ExtContext()).FindAllForUser(ctx, user.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := repo.buildUser(user, userAuth, orgAuth, tokens, recoveryCodes, credentials)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to build user from db DTOs")
 	}
@@ -60,32 +126,83 @@ func (repo *Repository) FindByUsername(ctx context.Context, username string) (*U
 	return u, repo.uow.RegisterClean(u)
 }
 
-func (repo *Repository) buildUser(user UserDto, userAuthDto []UserAuthDto, tokens []refresh.RefreshTokenDto) (*User, error) {
+func (repo *Repository) buildUser(user UserDto, userAuthDto []UserAuthDto, orgAuthDto []OrganizationAuthDto, tokens []refresh.RefreshTokenDto, recoveryCodesDto []RecoveryCodeDto, credentialsDto []webauthn.CredentialDto) (*User, error) {
 	uniqueScopeNames := make(map[string]bool)
-	uniqueRolesWithNames := make(map[string]string)
-	roles := make([]string, 0)
-	roleIds := make([]valueobj.RoleId, 0)
+	uniqueRoleNames := make(map[string]bool)
+	directRoles := make(map[string]UserAuthDto)
+	roles := make([]roleAssignment, 0)
+	now := time.Now().UTC()
 
+	// roles of groups are effective for user, but only direct assignments belong to aggregate,
+	// assignments outside of validity window are kept in aggregate but grant nothing
 	for _, auth := range userAuthDto {
-		uniqueScopeNames[auth.ScopeName] = true
-		uniqueRolesWithNames[auth.RoleId] = auth.RoleName
-	}
+		if auth.IsDirect() {
+			directRoles[auth.RoleId] = auth
+		}
+
+		validity, err := valueobj.NewValidity(auth.ValidFrom, auth.ValidUntil)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to build role assignment validity")
+		}
 
-	userAuth := valueobj.NewUserAuth(roles, helpers.Keys(uniqueScopeNames))
+		if !validity.IsActive(now) {
+			continue
+		}
 
-	for roleId, roleName := range uniqueRolesWithNames {
-		roles = append(roles, roleName)
+		if auth.ScopeName != "" {
+			uniqueScopeNames[auth.ScopeName] = true
+		}
+		uniqueRoleNames[auth.RoleName] = true
+	}
 
+	for roleId, auth := range directRoles {
 		roleIdent, err := valueobj.NewRoleId(roleId)
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to build role identifier")
 		}
-		roleIds = append(roleIds, roleIdent)
+
+		validity, err := valueobj.NewValidity(auth.ValidFrom, auth.ValidUntil)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to build role assignment validity")
+		}
+		roles = append(roles, roleAssignment{roleId: roleIdent, validity: validity})
+	}
+
+	userAuth := valueobj.NewUserAuth(helpers.Keys(uniqueRoleNames), helpers.Keys(uniqueScopeNames))
+
+	orgRoles := make(map[string]map[string]bool)
+	orgScopes := make(map[string]map[string]bool)
+	for _, auth := range orgAuthDto {
+		if _, ok := orgRoles[auth.OrganizationName]; !ok {
+			orgRoles[auth.OrganizationName] = make(map[string]bool)
+			orgScopes[auth.OrganizationName] = make(map[string]bool)
+		}
+
+		if auth.RoleName != "" {
+			orgRoles[auth.OrganizationName][auth.RoleName] = true
+		}
+
+		if auth.ScopeName != "" {
+			orgScopes[auth.OrganizationName][auth.ScopeName] = true
+		}
+	}
+
+	organizations := make(map[string]valueobj.UserAuth)
+	for org, roleNames := range orgRoles {
+		organizations[org] = valueobj.NewUserAuth(helpers.Keys(roleNames), helpers.Keys(orgScopes[org]))
 	}
 
 	refreshTokens := helpers.Map(tokens, func(token refresh.RefreshTokenDto, _ int, _ []refresh.RefreshTokenDto) *refresh.RefreshToken {
 		return token.ToRefreshToken()
 	})
 
-	return fromDbDtos(user, roleIds, refreshTokens, userAuth)
+	recoveryCodes := helpers.Map(recoveryCodesDto, func(code RecoveryCodeDto, _ int, _ []RecoveryCodeDto) valueobj.RecoveryCode {
+		return valueobj.RecoveryCodeFromHash(code.Id, code.CodeHash)
+	})
+
+	credentials := helpers.Map(credentialsDto, func(cred webauthn.CredentialDto, _ int, _ []webauthn.CredentialDto) *webauthn.Credential {
+		return cred.ToCredential()
+	})
+
+	return fromDbDtos(user, roles, refreshTokens, recoveryCodes, credentials, userAuth, organizations)
 }
@@ -2,11 +2,14 @@ package user
 
 import (
 	"context"
+	"time"
 
 	"github.com/go-redis/redis/v8"
 	"github.com/jmoiron/sqlx"
 	"github.com/pkg/errors"
+	"github.com/umalmyha/authsrv/internal/business/denylist"
 	"github.com/umalmyha/authsrv/internal/business/refresh"
+	"github.com/umalmyha/authsrv/internal/business/webauthn"
 	dbredis "github.com/umalmyha/authsrv/pkg/database/redis"
 	"github.com/umalmyha/authsrv/pkg/ddd/uow"
 	"github.com/umalmyha/authsrv/pkg/helpers"
@@ -18,6 +21,8 @@ type unitOfWork struct {
 	users         *uow.ChangeSet[UserDto]
 	assignedRoles *uow.ChangeSet[RoleAssignmentDto]
 	tokens        *uow.ChangeSet[refresh.RefreshTokenDto]
+	recoveryCodes *uow.ChangeSet[RecoveryCodeDto]
+	credentials   *uow.ChangeSet[webauthn.CredentialDto]
 }
 
 func NewUnitOfWork(db *sqlx.DB, rdb *redis.Client) *unitOfWork {
@@ -27,6 +32,8 @@ func NewUnitOfWork(db *sqlx.DB, rdb *redis.Client) *unitOfWork {
 		users:          uow.NewChangeSet[UserDto](),
 		assignedRoles:  uow.NewChangeSet[RoleAssignmentDto](),
 		tokens:         uow.NewChangeSet[refresh.RefreshTokenDto](),
+		recoveryCodes:  uow.NewChangeSet[RecoveryCodeDto](),
+		credentials:    uow.NewChangeSet[webauthn.CredentialDto](),
 	}
 }
 
@@ -34,6 +41,8 @@ func (uow *unitOfWork) RegisterClean(user *User) error {
 	uow.users.Attach(user.ToDto())
 	uow.assignedRoles.AttachRange(user.RolesDto()...)
 	uow.tokens.AttachRange(user.TokensDto()...)
+	uow.recoveryCodes.AttachRange(user.RecoveryCodesDto()...)
+	uow.credentials.AttachRange(user.CredentialsDto()...)
 	return nil
 }
 
@@ -50,6 +59,14 @@ func (uow *unitOfWork) RegisterNew(user *User) error {
 		return errors.Wrap(err, "failed to add tokens DTOs to changeset")
 	}
 
+	if err := uow.recoveryCodes.AddRange(user.RecoveryCodesDto()...); err != nil {
+		return errors.Wrap(err, "failed to add recovery codes DTOs to changeset")
+	}
+
+	if err := uow.credentials.AddRange(user.CredentialsDto()...); err != nil {
+		return errors.Wrap(err, "failed to add webauthn credentials DTOs to changeset")
+	}
+
 	return nil
 }
 
@@ -66,6 +83,14 @@ func (uow *unitOfWork) RegisterDeleted(user *User) error {
 		return errors.Wrap(err, "failed to delete tokens DTOs in changeset")
 	}
 
+	if err := uow.recoveryCodes.RemoveRange(user.RecoveryCodesDto()...); err != nil {
+		return errors.Wrap(err, "failed to delete recovery codes DTOs in changeset")
+	}
+
+	if err := uow.credentials.RemoveRange(user.CredentialsDto()...); err != nil {
+		return errors.Wrap(err, "failed to delete webauthn credentials DTOs in changeset")
+	}
+
 	return nil
 }
 
@@ -75,7 +100,7 @@ func (uow *unitOfWork) RegisterAmended(user *User) error {
 		return errors.Wrap(err, "failed to update user DTO in changeset")
 	}
 
-	createdRoles, _, deletedRoles := uow.assignedRoles.DeltaWithMatched(user.RolesDto(), func(role RoleAssignmentDto) bool {
+	createdRoles, updatedRoles, deletedRoles := uow.assignedRoles.DeltaWithMatched(user.RolesDto(), func(role RoleAssignmentDto) bool {
 		return role.UserId == userDto.Id
 	})
 
@@ -83,11 +108,15 @@ func (uow *unitOfWork) RegisterAmended(user *User) error {
 		return errors.Wrap(err, "failed to add roles assignments DTOs to changeset")
 	}
 
+	if err := uow.assignedRoles.UpdateRange(updatedRoles...); err != nil {
+		return errors.Wrap(err, "failed to update roles assignments DTOs in changeset")
+	}
+
 	if err := uow.assignedRoles.RemoveRange(deletedRoles...); err != nil {
 		return errors.Wrap(err, "failed to remove roles assignments DTOs in changeset")
 	}
 
-	createdTokens, _, deletedTokens := uow.tokens.DeltaWithMatched(user.TokensDto(), func(token refresh.RefreshTokenDto) bool {
+	createdTokens, updatedTokens, deletedTokens := uow.tokens.DeltaWithMatched(user.TokensDto(), func(token refresh.RefreshTokenDto) bool {
 		return token.UserId == userDto.Id
 	})
 
@@ -95,10 +124,43 @@ func (uow *unitOfWork) RegisterAmended(user *User) error {
 		return errors.Wrap(err, "failed to add tokens DTOs to changeset")
 	}
 
+	if err := uow.tokens.UpdateRange(updatedTokens...); err != nil {
+		return errors.Wrap(err, "failed to update tokens DTOs in changeset")
+	}
+
 	if err := uow.tokens.RemoveRange(deletedTokens...); err != nil {
 		return errors.Wrap(err, "failed to delete tokens DTOs in changeset")
 	}
 
+	// recovery codes are never changed, they are only generated or consumed
+	createdCodes, _, deletedCodes := uow.recoveryCodes.DeltaWithMatched(user.RecoveryCodesDto(), func(code RecoveryCodeDto) bool {
+		return code.UserId == userDto.Id
+	})
+
+	if err := uow.recoveryCodes.AddRange(createdCodes...); err != nil {
+		return errors.Wrap(err, "failed to add recovery codes DTOs to changeset")
+	}
+
+	if err := uow.recoveryCodes.RemoveRange(deletedCodes...); err != nil {
+		return errors.Wrap(err, "failed to delete recovery codes DTOs in changeset")
+	}
+
+	createdCreds, updatedCreds, deletedCreds := uow.credentials.DeltaWithMatched(user.CredentialsDto(), func(cred webauthn.CredentialDto) bool {
+		return cred.UserId == userDto.Id
+	})
+
+	if err := uow.credentials.AddRange(createdCreds...); err != nil {
+		return errors.Wrap(err, "failed to add webauthn credentials DTOs to changeset")
+	}
+
+	if err := uow.credentials.UpdateRange(updatedCreds...); err != nil {
+		return errors.Wrap(err, "failed to update webauthn credentials DTOs in changeset")
+	}
+
+	if err := uow.credentials.RemoveRange(deletedCreds...); err != nil {
+		return errors.Wrap(err, "failed to delete webauthn credentials DTOs in changeset")
+	}
+
 	return nil
 }
 
@@ -111,6 +173,8 @@ func (uow *unitOfWork) Flush(ctx context.Context) error {
 
 	userDao := NewUserDao(tx)
 	roleDao := NewRoleAssignmentDao(tx)
+	codeDao := NewRecoveryCodeDao(tx)
+	credDao := webauthn.NewCredentialDao(tx)
 	tokenDao := refresh.NewRefreshTokenDao(uow.rdb)
 
 	userTokens, err := uow.usersEncodedTokens()
@@ -130,11 +194,44 @@ func (uow *unitOfWork) Flush(ctx context.Context) error {
 		}
 	}
 
+	if rmCodes := uow.recoveryCodes.Deleted(); len(rmCodes) > 0 {
+		mapper := func(code RecoveryCodeDto, _ int, _ []RecoveryCodeDto) string {
+			return code.Id
+		}
+		if err := codeDao.DeleteWhereIdsIn(ctx, helpers.Map(rmCodes, mapper)); err != nil {
+			return errors.Wrap(err, "failed to process recovery codes deletion")
+		}
+	}
+
+	if rmCreds := uow.credentials.Deleted(); len(rmCreds) > 0 {
+		mapper := func(cred webauthn.CredentialDto, _ int, _ []webauthn.CredentialDto) string {
+			return cred.Id
+		}
+		if err := credDao.DeleteWhereIdsIn(ctx, helpers.Map(rmCreds, mapper)); err != nil {
+			return errors.Wrap(err, "failed to process webauthn credentials deletion")
+		}
+	}
+
 	if rmUsers := uow.users.Deleted(); len(rmUsers) > 0 {
 		mapper := func(user UserDto, _ int, _ []UserDto) string {
 			return user.Id
 		}
-		if err := userDao.DeleteWhereIdsIn(ctx, helpers.Map(rmUsers, mapper)); err != nil {
+		userIds := helpers.Map(rmUsers, mapper)
+
+		// assignments are removed with aggregate already, but role could be assigned concurrently
+		if err := roleDao.DeleteWhereUserIdsIn(ctx, userIds); err != nil {
+			return errors.Wrap(err, "failed to process roles assignments deletion of deleted users")
+		}
+
+		if err := NewOrganizationAuthDao(tx).DeleteMembershipsWhereUserIdsIn(ctx, userIds); err != nil {
+			return errors.Wrap(err, "failed to process organization memberships deletion of deleted users")
+		}
+
+		if err := NewUserAuthDao(tx).DeleteGroupMembershipsWhereUserIdsIn(ctx, userIds); err != nil {
+			return errors.Wrap(err, "failed to process group memberships deletion of deleted users")
+		}
+
+		if err := userDao.DeleteWhereIdsIn(ctx, userIds); err != nil {
 			return errors.Wrap(err, "failed to process users deletion")
 		}
 	}
@@ -151,6 +248,34 @@ func (uow *unitOfWork) Flush(ctx context.Context) error {
 		}
 	}
 
+	if updatedRoles := uow.assignedRoles.Updated(); len(updatedRoles) > 0 {
+		for _, updRole := range updatedRoles {
+			if err := roleDao.Update(ctx, updRole); err != nil {
+				return errors.Wrap(err, "failed to process roles assignments update")
+			}
+		}
+	}
+
+	if createdCodes := uow.recoveryCodes.Created(); len(createdCodes) > 0 {
+		if err := codeDao.CreateMulti(ctx, createdCodes); err != nil {
+			return errors.Wrap(err, "failed to process recovery codes creation")
+		}
+	}
+
+	if createdCreds := uow.credentials.Created(); len(createdCreds) > 0 {
+		if err := credDao.CreateMulti(ctx, createdCreds); err != nil {
+			return errors.Wrap(err, "failed to process webauthn credentials creation")
+		}
+	}
+
+	if updatedCreds := uow.credentials.Updated(); len(updatedCreds) > 0 {
+		for _, updCred := range updatedCreds {
+			if err := credDao.Update(ctx, updCred); err != nil {
+				return errors.Wrap(err, "failed to process webauthn credentials update")
+			}
+		}
+	}
+
 	if updatedUsers := uow.users.Updated(); len(updatedUsers) > 0 {
 		for _, updUser := range updatedUsers {
 			if err := userDao.Update(ctx, updUser); err != nil {
@@ -161,12 +286,44 @@ func (uow *unitOfWork) Flush(ctx context.Context) error {
 
 	if len(userTokens) > 0 {
 		userIds := helpers.Keys(userTokens)
+		createdTokens := uow.tokens.Created()
+		deletedTokens := uow.tokens.Deleted()
+
 		pipeline := tokenDao.WithinTxWithAttempts(ctx, userIds, func(pipe redis.Pipeliner) error {
 			for userId, encodedToken := range userTokens {
+				if encodedToken == "" {
+					if err := pipe.Del(ctx, userId).Err(); err != nil {
+						return errors.Wrap(err, "failed to delete refresh tokens")
+					}
+					continue
+				}
+
 				if err := pipe.Set(ctx, userId, encodedToken, 0).Err(); err != nil {
 					return errors.Wrap(err, "failed to update refresh token")
 				}
 			}
+
+			for _, token := range createdTokens {
+				if err := pipe.Set(ctx, refresh.IndexKey(token.Id), token.UserId, 0).Err(); err != nil {
+					return errors.Wrap(err, "failed to index refresh token")
+				}
+
+				if err := pipe.ExpireAt(ctx, refresh.IndexKey(token.Id), token.ExpiresAt).Err(); err != nil {
+					return errors.Wrap(err, "failed to set refresh token index expiration")
+				}
+			}
+
+			now := time.Now()
+			for _, token := range deletedTokens {
+				if err := pipe.Del(ctx, refresh.IndexKey(token.Id)).Err(); err != nil {
+					return errors.Wrap(err, "failed to delete refresh token index")
+				}
+
+				// revoked session must not stay usable via access token issued for it
+				if err := denylist.DenyWith(ctx, pipe, token.AccessTokenId, token.AccessTokenExpiresAt, now); err != nil {
+					return errors.Wrap(err, "failed to deny access token of deleted refresh token")
+				}
+			}
 			return nil
 		})
 
@@ -185,6 +342,8 @@ func (uow *unitOfWork) Dispose() error {
 	uow.users.Cleanup()
 	uow.assignedRoles.Cleanup()
 	uow.tokens.Cleanup()
+	uow.recoveryCodes.Cleanup()
+	uow.credentials.Cleanup()
 	return nil
 }
 
@@ -203,5 +362,17 @@ func (uow *unitOfWork) usersEncodedTokens() (map[string]string, error) {
 			userTokensEncoded[userId] = string(encodedToken)
 		}
 	}
+
+	// user has no tokens left, so empty value signals that stored tokens must be dropped
+	for _, token := range uow.tokens.Deleted() {
+		if _, ok := userTokensEncoded[token.UserId]; !ok {
+			userTokensEncoded[token.UserId] = ""
+		}
+	}
+
+	// tokens of deleted user are dropped even if user had none on read
+	for _, user := range uow.users.Deleted() {
+		userTokensEncoded[user.Id] = ""
+	}
 	return userTokensEncoded, nil
 }
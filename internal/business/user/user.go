@@ -2,6 +2,7 @@ package user
 
 import (
 	"container/list"
+	"sort"
 	"time"
 
 	"github.com/pkg/errors"
@@ -9,28 +10,243 @@ import (
 	"github.com/umalmyha/authsrv/internal/business/refresh"
 	"github.com/umalmyha/authsrv/internal/business/role"
 	valueobj "github.com/umalmyha/authsrv/internal/business/value-object"
+	"github.com/umalmyha/authsrv/internal/business/webauthn"
+	"github.com/umalmyha/authsrv/pkg/authz"
 	"github.com/umalmyha/authsrv/pkg/helpers"
 )
 
 type roleExistFn func(string) (bool, error)
 
+var SessionNotFoundErr = errors.New("session not found")
+
+var (
+	MfaAlreadyEnabledErr = errors.New("mfa is already enabled")
+	MfaNotEnabledErr     = errors.New("mfa is not enabled")
+	InvalidMfaCodeErr    = errors.New("mfa code is invalid")
+	// RecoveryCodeUsedErr is returned on flush if recovery code was consumed by concurrent request
+	RecoveryCodeUsedErr = errors.New("recovery code is already used")
+)
+
+// InvalidPasswordErr is returned when new password violates password policy
+var InvalidPasswordErr = errors.New("password doesn't satisfy password policy")
+
+var (
+	WrongPasswordErr  = errors.New("current password is wrong")
+	InvalidProfileErr = errors.New("profile is invalid")
+)
+
+const maxNameLength = 200
+
+var (
+	EmailExistsErr  = errors.New("email is already used by another user")
+	InvalidEmailErr = errors.New("email is invalid")
+	EmailChangedErr = errors.New("email was changed after verification was requested")
+	EmailNotSetErr  = errors.New("email is not set")
+)
+
+// UserDisabledErr is returned when disabled user tries to sign in or refresh session
+var UserDisabledErr = errors.New("user is disabled")
+
+// NotOrganizationMemberErr is returned when session is requested for organization user doesn't belong to
+var NotOrganizationMemberErr = errors.New("user is not member of organization")
+
+var (
+	CredentialNotFoundErr = errors.New("webauthn credential not found")
+	CredentialExistsErr   = errors.New("webauthn credential is already registered")
+)
+
+// roleAssignment is role assigned to user directly together with window it is effective within
+type roleAssignment struct {
+	roleId   valueobj.RoleId
+	validity valueobj.Validity
+}
+
 type User struct {
-	id          string
-	username    valueobj.SolidString
-	email       valueobj.NilEmail
-	password    valueobj.Password
-	isSuperuser bool
-	firstName   valueobj.NilString
-	lastName    valueobj.NilString
-	middleName  valueobj.NilString
-	roles       *list.List
-	tokens      *list.List
-	auth        valueobj.UserAuth
+	id       string
+	username valueobj.SolidString
+	email    valueobj.NilEmail
+	// email is verified once user follows link sent to it, changed email must be verified again
+	emailVerifiedAt *time.Time
+	password        valueobj.Password
+	isSuperuser     bool
+	firstName       valueobj.NilString
+	lastName        valueobj.NilString
+	middleName      valueobj.NilString
+	roles           *list.List
+	tokens          *list.List
+	auth            valueobj.UserAuth
+	// roles and scopes user has in organizations, keyed by organization name
+	organizations map[string]valueobj.UserAuth
+	// TOTP secret is set on enrollment, but MFA is enabled only after first code is confirmed
+	mfaEnabled    bool
+	totpSecret    valueobj.TotpSecret
+	totpLastStep  int64
+	recoveryCodes *list.List
+	credentials   *list.List
+	lockedUntil   *time.Time
+	disabledAt    *time.Time
 }
 
 type RoleFinderByNameFn func(string) (role.RoleDto, error)
 
-func (u *User) AssignRole(name string, finderFn RoleFinderByNameFn) error {
+func (u *User) Id() string {
+	return u.id
+}
+
+func (u *User) Username() string {
+	return u.username.String()
+}
+
+// Email returns email of user, empty string is returned if email is not set
+func (u *User) Email() string {
+	return u.email.String()
+}
+
+func (u *User) EmailVerified() bool {
+	return u.email.String() != "" && u.emailVerifiedAt != nil
+}
+
+// ChangeEmail replaces email, new email is unverified until user follows verification link
+func (u *User) ChangeEmail(email string, existFn isExistingEmailFn) error {
+	if valueobj.NormalizeEmail(email) == valueobj.NormalizeEmail(u.email.String()) {
+		return nil
+	}
+
+	newEmail, err := valueobj.NewNilEmail(email)
+	if err != nil {
+		return errors.Wrap(InvalidEmailErr, err.Error())
+	}
+
+	if email != "" {
+		exist, err := existFn(email)
+		if err != nil {
+			return errors.Wrap(err, "failed to check email existence")
+		}
+
+		if exist {
+			return EmailExistsErr
+		}
+	}
+
+	u.email = newEmail
+	u.emailVerifiedAt = nil
+
+	return nil
+}
+
+// ChangeOwnEmail is self-service email change, current password must be confirmed, so stolen session alone
+// can't redirect password reset mail to another address
+func (u *User) ChangeOwnEmail(email string, currentPassword string, existFn isExistingEmailFn) error {
+	if valueobj.NormalizeEmail(email) == valueobj.NormalizeEmail(u.email.String()) {
+		return nil
+	}
+
+	if err := u.confirmPassword(currentPassword); err != nil {
+		return err
+	}
+	return u.ChangeEmail(email, existFn)
+}
+
+// UpdateOwnProfile is self-service profile change, current password is required only if email is changed
+func (u *User) UpdateOwnProfile(update ProfileUpdateDto, existFn isExistingEmailFn) (bool, error) {
+	if update.Email != nil && valueobj.NormalizeEmail(*update.Email) != valueobj.NormalizeEmail(u.email.String()) {
+		if err := u.confirmPassword(update.CurrentPassword); err != nil {
+			return false, err
+		}
+	}
+	return u.UpdateProfile(update, existFn)
+}
+
+// UpdateProfile applies provided fields only, empty string clears field. Returns true if email was changed,
+// so verification link must be sent to new address.
+func (u *User) UpdateProfile(update ProfileUpdateDto, existFn isExistingEmailFn) (bool, error) {
+	for _, name := range []*string{update.FirstName, update.MiddleName, update.LastName} {
+		if name != nil && len(*name) > maxNameLength {
+			return false, errors.Wrapf(InvalidProfileErr, "name can't exceed %d characters", maxNameLength)
+		}
+	}
+
+	emailChanged := false
+	if update.Email != nil {
+		previous := u.email.String()
+		if err := u.ChangeEmail(*update.Email, existFn); err != nil {
+			return false, err
+		}
+		emailChanged = u.email.String() != previous
+	}
+
+	if update.FirstName != nil {
+		u.firstName = valueobj.NewNilString(*update.FirstName)
+	}
+
+	if update.MiddleName != nil {
+		u.middleName = valueobj.NewNilString(*update.MiddleName)
+	}
+
+	if update.LastName != nil {
+		u.lastName = valueobj.NewNilString(*update.LastName)
+	}
+
+	return emailChanged, nil
+}
+
+func (u *User) Profile() ProfileDto {
+	return ProfileDto{
+		Id:            u.id,
+		Username:      u.username.String(),
+		Email:         u.email.Ptr(),
+		EmailVerified: u.EmailVerified(),
+		FirstName:     u.firstName.Ptr(),
+		MiddleName:    u.middleName.Ptr(),
+		LastName:      u.lastName.Ptr(),
+		MfaEnabled:    u.mfaEnabled,
+		Organizations: u.Organizations(),
+	}
+}
+
+// Organizations returns sorted names of organizations user is member of
+func (u *User) Organizations() []string {
+	orgs := helpers.Keys(u.organizations)
+	sort.Strings(orgs)
+	return orgs
+}
+
+func (u *User) IsMemberOf(org string) bool {
+	_, ok := u.organizations[org]
+	return ok
+}
+
+// VerifyEmail marks email as verified, verification is accepted only for email it was sent to.
+// Unverified emails don't reserve address, so it fails if other user has verified the same email already.
+func (u *User) VerifyEmail(email string, now time.Time, existFn isExistingEmailFn) error {
+	if u.email.String() == "" {
+		return EmailNotSetErr
+	}
+
+	if valueobj.NormalizeEmail(email) != valueobj.NormalizeEmail(u.email.String()) {
+		return EmailChangedErr
+	}
+
+	if u.emailVerifiedAt != nil {
+		return nil
+	}
+
+	exist, err := existFn(email)
+	if err != nil {
+		return errors.Wrap(err, "failed to check email existence")
+	}
+
+	if exist {
+		return EmailExistsErr
+	}
+
+	u.emailVerifiedAt = &now
+	return nil
+}
+
+// AssignRole assigns role to user, role is effective only within validity window.
+// Expired assignment which isn't swept yet is replaced with new one.
+func (u *User) AssignRole(name string, validity valueobj.Validity, now time.Time, finderFn RoleFinderByNameFn) error {
 	r, err := finderFn(name)
 	if err != nil {
 		return errors.Wrap(err, "failed to read user")
@@ -46,13 +262,18 @@ func (u *User) AssignRole(name string, finderFn RoleFinderByNameFn) error {
 	}
 
 	for elem := u.roles.Front(); elem != nil; elem = elem.Next() {
-		assignedRoleId, _ := elem.Value.(valueobj.RoleId)
-		if assignedRoleId.Equal(roleIdent) {
-			return errors.Errorf("role %s is already assigned", name)
+		assigned, _ := elem.Value.(roleAssignment)
+		if assigned.roleId.Equal(roleIdent) {
+			if !assigned.validity.IsExpired(now) {
+				return errors.Errorf("role %s is already assigned", name)
+			}
+
+			elem.Value = roleAssignment{roleId: roleIdent, validity: validity}
+			return nil
 		}
 	}
 
-	u.roles.PushBack(roleIdent)
+	u.roles.PushBack(roleAssignment{roleId: roleIdent, validity: validity})
 	return nil
 }
 
@@ -73,8 +294,8 @@ func (u *User) UnassignRole(name string, finderFn RoleFinderByNameFn) error {
 
 	var rmElem *list.Element
 	for elem := u.roles.Front(); elem != nil; elem = elem.Next() {
-		assignedRoleId, _ := elem.Value.(valueobj.RoleId)
-		if assignedRoleId.Equal(roleIdent) {
+		assigned, _ := elem.Value.(roleAssignment)
+		if assigned.roleId.Equal(roleIdent) {
 			rmElem = elem
 			break
 		}
@@ -88,24 +309,115 @@ func (u *User) UnassignRole(name string, finderFn RoleFinderByNameFn) error {
 	return nil
 }
 
-func (u *User) GenerateJwt(issuedAt time.Time, cfg valueobj.JwtConfig) (valueobj.Jwt, error) {
-	return valueobj.NewJwt(u.username.String(), issuedAt, u.auth.Roles(), u.auth.Scopes(), cfg)
+// GenerateOrganizationJwt issues JWT carrying only roles and scopes user has in organization,
+// empty organization means global roles and scopes
+func (u *User) GenerateOrganizationJwt(issuedAt time.Time, org string, amr []string, cfg valueobj.JwtConfig) (valueobj.Jwt, error) {
+	return u.generateJwt(issuedAt, org, "", nil, amr, cfg)
+}
+
+// GenerateScopedJwt issues JWT delegated to OAuth client, scopes are narrowed to requested ones, roles are omitted
+// and superuser claim is never emitted, so delegated tokens never bypass scope checks.
+func (u *User) GenerateScopedJwt(issuedAt time.Time, clientId string, requested []string, amr []string, cfg valueobj.JwtConfig) (valueobj.Jwt, error) {
+	if clientId == "" {
+		return valueobj.Jwt{}, errors.New("client id is mandatory for delegated token")
+	}
+	return u.generateJwt(issuedAt, "", clientId, requested, amr, cfg)
+}
+
+// GrantedScopes returns requested scopes which are really granted to user, wildcard scopes of user grant matching ones
+func (u *User) GrantedScopes(requested []string) []string {
+	return grantedScopes(u.auth, requested)
+}
+
+// SessionScopes returns scopes access tokens of session carry, delegated session never gets more than it was granted
+func (u *User) SessionScopes(token *refresh.RefreshToken) []string {
+	return grantedScopes(u.auth, sessionScopes(token))
+}
+
+// generateJwt issues JWT for organization, token is delegated to client if client id is provided.
+// Nil requested scopes means all scopes for tokens which aren't delegated, superuser claim is emitted only
+// for sessions user started itself.
+func (u *User) generateJwt(issuedAt time.Time, org string, clientId string, requested []string, amr []string, cfg valueobj.JwtConfig) (valueobj.Jwt, error) {
+	auth := u.auth
+	if org != "" {
+		orgAuth, ok := u.organizations[org]
+		if !ok {
+			return valueobj.Jwt{}, NotOrganizationMemberErr
+		}
+		auth = orgAuth
+	}
+
+	delegated := clientId != ""
+	roles := auth.Roles()
+	if delegated {
+		roles = nil
+		if requested == nil {
+			requested = make([]string, 0)
+		}
+	}
+
+	superuser := u.isSuperuser && !delegated
+	return valueobj.NewJwt(u.username.String(), issuedAt, org, clientId, roles, grantedScopes(auth, requested), superuser, amr, cfg)
+}
+
+// sessionScopes returns scopes session was narrowed to, stored empty scopes of delegated session are read back as nil
+func sessionScopes(token *refresh.RefreshToken) []string {
+	if token.IsDelegated() && token.Scopes() == nil {
+		return make([]string, 0)
+	}
+	return token.Scopes()
+}
+
+func grantedScopes(auth valueobj.UserAuth, requested []string) []string {
+	if requested == nil {
+		return auth.Scopes()
+	}
+
+	granted := make([]string, 0)
+	for _, scope := range requested {
+		if authz.Granted(auth.Scopes(), scope) {
+			granted = append(granted, scope)
+		}
+	}
+	return granted
 }
 
-func (u *User) GenerateRefreshToken(fgrprint string, issuedAt time.Time, cfg valueobj.RefreshTokenConfig) (*refresh.RefreshToken, error) {
+func (u *User) RefreshToken(tokenId string) *refresh.RefreshToken {
+	elem := u.findRefreshTokenElemById(tokenId)
+	if elem == nil {
+		return nil
+	}
+
+	token, _ := elem.Value.(*refresh.RefreshToken)
+	return token
+}
+
+func (u *User) GenerateRefreshToken(fgrprint string, scopes []string, client refresh.ClientInfo, issuedAt time.Time, cfg valueobj.RefreshTokenConfig) (*refresh.RefreshToken, error) {
+	if u.Disabled() {
+		return nil, UserDisabledErr
+	}
+
+	u.removeExpiredTokens(issuedAt)
+
+	activeCount := 0
 	for elem := u.tokens.Front(); elem != nil; elem = elem.Next() {
 		token, _ := elem.Value.(*refresh.RefreshToken)
+		if token.IsRotated() {
+			continue
+		}
+
 		if token.Fingerprint() == fgrprint {
 			return nil, errors.Errorf("refresh token for device %s is generated already", fgrprint)
 		}
+		activeCount++
 	}
 
-	token, err := refresh.NewRefreshToken(fgrprint, issuedAt, cfg)
+	token, err := refresh.NewRefreshToken(fgrprint, scopes, client, issuedAt, cfg)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create refresh token")
 	}
 
-	if u.tokens.Len() == cfg.MaxTokensCount() {
+	if activeCount == cfg.MaxTokensCount() {
 		u.removeTokenClosestToExpiration()
 	}
 	u.tokens.PushBack(token)
@@ -122,45 +434,326 @@ func (u *User) DiscardRefreshToken(logout LogoutDto) error {
 		return errors.New("fingerprint must be provided")
 	}
 
-	rmElem := u.findRefreshTokenElemById(logout.RefreshTokenId)
-	if rmElem == nil {
+	elem := u.findRefreshTokenElemById(logout.RefreshTokenId)
+	if elem == nil {
 		return errors.Errorf("provided refresh token doesn't exist or doesn't belong to user %s", u.username)
 	}
 
-	token, _ := rmElem.Value.(*refresh.RefreshToken)
+	token, _ := elem.Value.(*refresh.RefreshToken)
 	if token.Fingerprint() != logout.Fingerprint {
 		return errors.New("provided fingerprint doesn't belong to provided refresh token")
 	}
 
-	u.tokens.Remove(rmElem)
+	u.removeTokenFamily(token.FamilyId())
 	return nil
 }
 
-func (u *User) RefreshSession(rfr RefreshDto, now time.Time, cfg valueobj.JwtConfig) (valueobj.Jwt, error) {
+// RefreshSession rotates provided refresh token and issues new access token. Reuse of already rotated
+// token means it was leaked, so the whole token family is revoked (OAuth 2.0 Security BCP 4.14.2).
+// Session stays in organization of refresh token unless another organization is requested.
+func (u *User) RefreshSession(rfr RefreshDto, now time.Time, cfg valueobj.JwtConfig) (valueobj.Jwt, *refresh.RefreshToken, error) {
 	if rfr.RefreshTokenId == "" {
-		return valueobj.Jwt{}, errors.New("refresh token id can't be initial")
+		return valueobj.Jwt{}, nil, errors.New("refresh token id can't be initial")
 	}
 
 	if rfr.Fingerprint == "" {
-		return valueobj.Jwt{}, errors.New("fingerprint must be provided")
+		return valueobj.Jwt{}, nil, errors.New("fingerprint must be provided")
+	}
+
+	if u.Disabled() {
+		return valueobj.Jwt{}, nil, UserDisabledErr
 	}
 
 	tokenElem := u.findRefreshTokenElemById(rfr.RefreshTokenId)
 	if tokenElem == nil {
-		return valueobj.Jwt{}, errors.Errorf("provided refresh token doesn't exist or doesn't belong to user %s", u.username)
+		return valueobj.Jwt{}, nil, errors.Errorf("provided refresh token doesn't exist or doesn't belong to user %s", u.username)
 	}
 
 	token, _ := tokenElem.Value.(*refresh.RefreshToken)
 	if token.Fingerprint() != rfr.Fingerprint {
-		return valueobj.Jwt{}, errors.New("provided fingerprint doesn't belong to provided refresh token")
+		return valueobj.Jwt{}, nil, errors.New("provided fingerprint doesn't belong to provided refresh token")
+	}
+
+	org := token.Organization()
+	if rfr.Organization != nil {
+		org = *rfr.Organization
+	}
+
+	if org != "" && !u.IsMemberOf(org) {
+		return valueobj.Jwt{}, nil, NotOrganizationMemberErr
+	}
+
+	rotated, err := token.Rotate(now, rfr.Client)
+	if err != nil {
+		u.removeTokenFamily(token.FamilyId())
+		return valueobj.Jwt{}, nil, err
+	}
+	u.removeExpiredTokens(now)
+	u.pruneRotatedTokens(token)
+	u.tokens.PushBack(rotated)
+
+	jwt, err := u.generateJwt(now, org, rotated.ClientId(), sessionScopes(rotated), rotated.Amr(), cfg)
+	if err != nil {
+		return valueobj.Jwt{}, nil, err
+	}
+	rotated.BindAccessToken(jwt)
+
+	return jwt, rotated, nil
+}
+
+// Sessions returns active sessions, session is identified by its token family
+func (u *User) Sessions() []SessionDto {
+	roots := make(map[string]*refresh.RefreshToken)
+	for elem := u.tokens.Front(); elem != nil; elem = elem.Next() {
+		token, _ := elem.Value.(*refresh.RefreshToken)
+		if token.ParentId() == "" {
+			roots[token.FamilyId()] = token
+		}
+	}
+
+	sessions := make([]SessionDto, 0)
+	for elem := u.tokens.Front(); elem != nil; elem = elem.Next() {
+		token, _ := elem.Value.(*refresh.RefreshToken)
+		if token.IsRotated() {
+			continue
+		}
+
+		// family root is kept until family expires, but fallback to the active token itself just in case
+		issuedAt := token.IssuedAt()
+		if root, ok := roots[token.FamilyId()]; ok {
+			issuedAt = root.IssuedAt()
+		}
+
+		sessions = append(sessions, SessionDto{
+			Id:          token.FamilyId(),
+			Fingerprint: token.Fingerprint(),
+			IssuedAt:    issuedAt,
+			ExpiresAt:   token.ExpiresAt(),
+			LastUsedAt:  token.IssuedAt(),
+			IpAddress:   token.Client().IpAddress,
+			UserAgent:   token.Client().UserAgent,
+		})
+	}
+	return sessions
+}
+
+func (u *User) RevokeSession(sessionId string) error {
+	if sessionId == "" {
+		return errors.New("session id can't be initial")
+	}
+
+	found := false
+	for elem := u.tokens.Front(); elem != nil; elem = elem.Next() {
+		token, _ := elem.Value.(*refresh.RefreshToken)
+		if token.FamilyId() == sessionId {
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		return errors.Wrapf(SessionNotFoundErr, "session %s doesn't exist or doesn't belong to user %s", sessionId, u.username)
+	}
+
+	u.removeTokenFamily(sessionId)
+	return nil
+}
+
+// RevokeReusedSession revokes session of refresh token which reuse is detected by rotation mark,
+// i.e. token was rotated concurrently or is already pruned
+func (u *User) RevokeReusedSession(rotation refresh.RotationDto) {
+	u.removeTokenFamily(rotation.FamilyId)
+}
+
+func (u *User) RevokeAllSessions() {
+	u.tokens.Init()
+}
+
+// RevokeOtherSessions revokes all sessions except one given refresh token belongs to,
+// all sessions are revoked if token is unknown
+func (u *User) RevokeOtherSessions(tokenId string) {
+	current := u.RefreshToken(tokenId)
+	if current == nil {
+		u.RevokeAllSessions()
+		return
+	}
+
+	u.removeTokensWhere(func(token *refresh.RefreshToken) bool {
+		return token.FamilyId() != current.FamilyId()
+	})
+}
+
+func (u *User) MfaEnabled() bool {
+	return u.mfaEnabled
+}
+
+// EnrollTotp generates new TOTP secret, enrollment which is not confirmed yet is restarted
+func (u *User) EnrollTotp() (valueobj.TotpSecret, error) {
+	if u.mfaEnabled {
+		return valueobj.TotpSecret{}, MfaAlreadyEnabledErr
+	}
+
+	secret, err := valueobj.GenerateTotpSecret()
+	if err != nil {
+		return secret, err
+	}
+	u.totpSecret = secret
+	u.totpLastStep = 0
+
+	return secret, nil
+}
+
+// ConfirmTotp enables MFA if code matches enrolled secret and returns plain recovery codes, they can't be read later
+func (u *User) ConfirmTotp(code string, now time.Time, cfg valueobj.MfaConfig) ([]string, error) {
+	if u.mfaEnabled {
+		return nil, MfaAlreadyEnabledErr
+	}
+
+	if u.totpSecret.IsZero() {
+		return nil, errors.New("totp enrollment is not started")
+	}
+
+	if !u.verifyTotp(code, now) {
+		return nil, InvalidMfaCodeErr
+	}
+
+	plain, codes, err := valueobj.GenerateRecoveryCodes(valueobj.RecoveryCodesCount, cfg)
+	if err != nil {
+		return nil, err
+	}
+	u.recoveryCodes = helpers.ToList(codes)
+	u.mfaEnabled = true
+
+	return plain, nil
+}
+
+// VerifyMfa checks second factor, code is either TOTP or recovery code which is consumed on success
+func (u *User) VerifyMfa(code string, now time.Time, cfg valueobj.MfaConfig) error {
+	if !u.mfaEnabled {
+		return MfaNotEnabledErr
+	}
+
+	if u.verifyTotp(code, now) {
+		return nil
+	}
+
+	for elem := u.recoveryCodes.Front(); elem != nil; elem = elem.Next() {
+		recoveryCode, _ := elem.Value.(valueobj.RecoveryCode)
+		if recoveryCode.Verify(code, cfg) {
+			u.recoveryCodes.Remove(elem)
+			return nil
+		}
+	}
+
+	return InvalidMfaCodeErr
+}
+
+// DisableMfa turns MFA off, it requires valid second factor, so stolen session alone can't downgrade account
+func (u *User) DisableMfa(code string, now time.Time, cfg valueobj.MfaConfig) error {
+	if err := u.VerifyMfa(code, now, cfg); err != nil {
+		return err
+	}
+
+	u.mfaEnabled = false
+	u.totpSecret = valueobj.TotpSecret{}
+	u.totpLastStep = 0
+	u.recoveryCodes.Init()
+	return nil
+}
+
+func (u *User) RecoveryCodesLeft() int {
+	return u.recoveryCodes.Len()
+}
+
+// RegisterCredential adds passkey verified during registration ceremony
+func (u *User) RegisterCredential(cred *webauthn.Credential) error {
+	if cred.UserId() != u.id {
+		return errors.New("credential was registered for another user")
+	}
+
+	if u.Credential(cred.Id()) != nil {
+		return CredentialExistsErr
+	}
+
+	u.credentials.PushBack(cred)
+	return nil
+}
+
+// VerifyAssertion signs user in with passkey, credential sign count is advanced on success
+func (u *User) VerifyAssertion(session *webauthn.Session, resp webauthn.AssertionResponseDto, cfg valueobj.WebauthnConfig, now time.Time) error {
+	credId, err := webauthn.CredentialIdFromResponse(resp)
+	if err != nil {
+		return err
 	}
 
-	u.tokens.Remove(tokenElem)
+	cred := u.Credential(credId)
+	if cred == nil {
+		return CredentialNotFoundErr
+	}
+	return cred.VerifyAssertion(session, resp, cfg, now)
+}
 
-	if err := token.VerifyNotExpired(now); err != nil {
-		return valueobj.Jwt{}, err
+func (u *User) Credential(id string) *webauthn.Credential {
+	for elem := u.credentials.Front(); elem != nil; elem = elem.Next() {
+		cred, _ := elem.Value.(*webauthn.Credential)
+		if cred.Id() == id {
+			return cred
+		}
 	}
-	return u.GenerateJwt(now, cfg)
+	return nil
+}
+
+func (u *User) Credentials() []*webauthn.Credential {
+	return helpers.FromList[*webauthn.Credential](u.credentials)
+}
+
+func (u *User) RemoveCredential(id string) error {
+	for elem := u.credentials.Front(); elem != nil; elem = elem.Next() {
+		cred, _ := elem.Value.(*webauthn.Credential)
+		if cred.Id() == id {
+			u.credentials.Remove(elem)
+			return nil
+		}
+	}
+	return CredentialNotFoundErr
+}
+
+// Lock blocks sign in until given time, it is applied after too many failed attempts
+func (u *User) Lock(until time.Time) {
+	u.lockedUntil = &until
+}
+
+func (u *User) Unlock() {
+	u.lockedUntil = nil
+}
+
+// LockedFor returns time left until account is unlocked, zero means account is not locked
+func (u *User) LockedFor(now time.Time) time.Duration {
+	if u.lockedUntil == nil || !u.lockedUntil.After(now) {
+		return 0
+	}
+	return u.lockedUntil.Sub(now)
+}
+
+func (u *User) Disabled() bool {
+	return u.disabledAt != nil
+}
+
+// Disable blocks sign in and refresh, all sessions are revoked as well
+func (u *User) Disable(now time.Time) {
+	if u.disabledAt == nil {
+		u.disabledAt = &now
+	}
+	u.RevokeAllSessions()
+}
+
+func (u *User) Enable() {
+	u.disabledAt = nil
+}
+
+// Summary returns user data available for administration
+func (u *User) Summary() UserSummaryDto {
+	return u.ToDto().Summary(u.auth.Roles())
 }
 
 func (u *User) VerifyPassword(password string) (bool, error) {
@@ -168,30 +761,115 @@ func (u *User) VerifyPassword(password string) (bool, error) {
 		return false, errors.New("password for verification can't be initial")
 	}
 
-	hash, err := valueobj.GenerateHash([]byte(password))
+	verified, err := u.password.Verify(password)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to verify password hash")
+	}
+
+	return verified, nil
+}
+
+// SetPassword replaces password with new one, policy violations are reported as InvalidPasswordErr
+func (u *User) SetPassword(password string, confirmPassword string, cfg valueobj.PasswordConfig) error {
+	if password != confirmPassword {
+		return errors.Wrap(InvalidPasswordErr, "passwords don't match")
+	}
+
+	generated, err := valueobj.GeneratePassword(password, cfg)
 	if err != nil {
-		return false, errors.Wrap(err, "failed to generate password hash")
+		return errors.Wrap(InvalidPasswordErr, err.Error())
 	}
+	u.password = generated
+
+	return nil
+}
 
-	return hash == u.password.Hash(), nil
+// ChangePassword replaces password, current password must be confirmed first
+func (u *User) ChangePassword(current string, password string, confirmPassword string, cfg valueobj.PasswordConfig) error {
+	if err := u.confirmPassword(current); err != nil {
+		return err
+	}
+	return u.SetPassword(password, confirmPassword, cfg)
+}
+
+func (u *User) confirmPassword(current string) error {
+	if current == "" {
+		return WrongPasswordErr
+	}
+
+	verified, err := u.VerifyPassword(current)
+	if err != nil {
+		return err
+	}
+
+	if !verified {
+		return WrongPasswordErr
+	}
+	return nil
+}
+
+// UpgradePasswordHash rehashes already verified password if its hash was produced
+// by other algorithm or with outdated parameters, returns true if hash was changed
+func (u *User) UpgradePasswordHash(password string, cfg valueobj.PasswordConfig) (bool, error) {
+	if !u.password.NeedsRehash(cfg) {
+		return false, nil
+	}
+
+	upgraded, err := valueobj.HashPassword(password, cfg)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to rehash password")
+	}
+	u.password = upgraded
+
+	return true, nil
 }
 
 func (u *User) ToDto() UserDto {
+	var totpSecret *string
+	if !u.totpSecret.IsZero() {
+		secret := u.totpSecret.String()
+		totpSecret = &secret
+	}
+
 	return UserDto{
-		Id:          u.id,
-		Username:    u.username.String(),
-		Email:       u.email.Ptr(),
-		Password:    u.password.Hash(),
-		IsSuperuser: u.isSuperuser,
-		FirstName:   u.firstName.Ptr(),
-		LastName:    u.lastName.Ptr(),
-		MiddleName:  u.middleName.Ptr(),
+		Id:              u.id,
+		Username:        u.username.String(),
+		Email:           u.email.Ptr(),
+		EmailVerifiedAt: u.emailVerifiedAt,
+		Password:        u.password.Hash(),
+		IsSuperuser:     u.isSuperuser,
+		FirstName:       u.firstName.Ptr(),
+		LastName:        u.lastName.Ptr(),
+		MiddleName:      u.middleName.Ptr(),
+
+		MfaEnabled:   u.mfaEnabled,
+		TotpSecret:   totpSecret,
+		TotpLastStep: u.totpLastStep,
+		LockedUntil:  u.lockedUntil,
+		DisabledAt:   u.disabledAt,
 	}
 }
 
 func (u *User) RolesDto() []RoleAssignmentDto {
-	return helpers.FromListWithReducer(u.roles, func(roleId valueobj.RoleId) RoleAssignmentDto {
-		return RoleAssignmentDto{UserId: u.id, RoleId: roleId.String()}
+	return helpers.FromListWithReducer(u.roles, func(assigned roleAssignment) RoleAssignmentDto {
+		return RoleAssignmentDto{
+			UserId:     u.id,
+			RoleId:     assigned.roleId.String(),
+			ValidFrom:  assigned.validity.From(),
+			ValidUntil: assigned.validity.Until(),
+		}
+	})
+}
+
+func (u *User) RecoveryCodesDto() []RecoveryCodeDto {
+	return helpers.FromListWithReducer(u.recoveryCodes, func(code valueobj.RecoveryCode) RecoveryCodeDto {
+		return RecoveryCodeDto{Id: code.Id(), UserId: u.id, CodeHash: code.Hash()}
+	})
+}
+
+func (u *User) CredentialsDto() []webauthn.CredentialDto {
+	return helpers.FromListWithReducer(u.credentials, func(cred *webauthn.Credential) webauthn.CredentialDto {
+		return cred.Dto()
 	})
 }
 
@@ -199,35 +877,83 @@ func (u *User) TokensDto() []refresh.RefreshTokenDto {
 	return helpers.FromListWithReducer(u.tokens, func(token *refresh.RefreshToken) refresh.RefreshTokenDto {
 		return refresh.RefreshTokenDto{
 			Id:          token.Id(),
+			FamilyId:    token.FamilyId(),
+			ParentId:    token.ParentId(),
 			Fingerprint: token.Fingerprint(),
+			Scopes:      token.Scopes(),
 			UserId:      u.id,
 			IssuedAt:    token.IssuedAt(),
 			ExpiresAt:   token.ExpiresAt(),
+			RotatedAt:   token.RotatedAt(),
+			IpAddress:   token.Client().IpAddress,
+			UserAgent:   token.Client().UserAgent,
+
+			AccessTokenId:        token.AccessTokenId(),
+			AccessTokenExpiresAt: token.AccessTokenExpiresAt(),
+			Amr:                  token.Amr(),
+			Organization:         token.Organization(),
 		}
 	})
 }
 
+// removeTokenClosestToExpiration revokes session which expires first, rotated tokens are not sessions on their own
 func (u *User) removeTokenClosestToExpiration() {
-	var rmElem *list.Element
-
-	tokenExpiresEarlier := func(rm *list.Element, curr *list.Element) bool {
-		rmToken, _ := rm.Value.(*refresh.RefreshToken)
-		currToken, _ := curr.Value.(*refresh.RefreshToken)
-		return currToken.ExpiresAt().Before(rmToken.ExpiresAt())
-	}
-
+	var rmToken *refresh.RefreshToken
 	for elem := u.tokens.Front(); elem != nil; elem = elem.Next() {
-		if rmElem == nil {
-			rmElem = elem
+		token, _ := elem.Value.(*refresh.RefreshToken)
+		if token.IsRotated() {
 			continue
 		}
 
-		if tokenExpiresEarlier(rmElem, elem) {
-			rmElem = elem
+		if rmToken == nil || token.ExpiresAt().Before(rmToken.ExpiresAt()) {
+			rmToken = token
 		}
 	}
 
-	u.tokens.Remove(rmElem)
+	if rmToken != nil {
+		u.removeTokenFamily(rmToken.FamilyId())
+	}
+}
+
+func (u *User) removeTokenFamily(familyId string) {
+	u.removeTokensWhere(func(token *refresh.RefreshToken) bool {
+		return token.FamilyId() == familyId
+	})
+}
+
+// pruneRotatedTokens drops rotated tokens of family except its root and just rotated parent,
+// so tokens don't pile up on every rotation, reuse of pruned tokens is detected by rotation mark
+func (u *User) pruneRotatedTokens(parent *refresh.RefreshToken) {
+	u.removeTokensWhere(func(token *refresh.RefreshToken) bool {
+		return token.FamilyId() == parent.FamilyId() && token.IsRotated() && token.ParentId() != "" && token.Id() != parent.Id()
+	})
+}
+
+func (u *User) removeExpiredTokens(now time.Time) {
+	u.removeTokensWhere(func(token *refresh.RefreshToken) bool {
+		return token.ExpiresAt().Before(now)
+	})
+}
+
+func (u *User) removeTokensWhere(matchFn func(*refresh.RefreshToken) bool) {
+	for elem := u.tokens.Front(); elem != nil; {
+		next := elem.Next()
+		if token, _ := elem.Value.(*refresh.RefreshToken); matchFn(token) {
+			u.tokens.Remove(elem)
+		}
+		elem = next
+	}
+}
+
+// verifyTotp accepts every time step only once, so intercepted code can't be replayed (RFC 6238 5.2)
+func (u *User) verifyTotp(code string, now time.Time) bool {
+	step, ok := u.totpSecret.Verify(code, now)
+	if !ok || step <= u.totpLastStep {
+		return false
+	}
+
+	u.totpLastStep = step
+	return true
 }
 
 func (u *User) findRefreshTokenElemById(tokenId string) *list.Element {
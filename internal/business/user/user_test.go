@@ -0,0 +1,380 @@
+package user
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/umalmyha/authsrv/internal/business/refresh"
+	valueobj "github.com/umalmyha/authsrv/internal/business/value-object"
+)
+
+const (
+	success = "\u2713"
+	failed  = "\u2717"
+)
+
+const userPassword = "Secret123"
+
+func testPasswordConfig(t *testing.T) valueobj.PasswordConfig {
+	hasher, err := valueobj.NewBcryptHasher(4)
+	if err != nil {
+		t.Fatalf("\t%s\tUnexpected error on hasher creation: %v", failed, err)
+	}
+
+	cfg, err := valueobj.NewPasswordConfig(8, 0, true, true, hasher)
+	if err != nil {
+		t.Fatalf("\t%s\tUnexpected error on password config creation: %v", failed, err)
+	}
+	return cfg
+}
+
+// newTestUser creates user with given email, email is verified if verifiedAt is provided
+func newTestUser(t *testing.T, email string, verifiedAt *time.Time) *User {
+	dto := NewUserDto{Username: "john", Password: userPassword, ConfirmPassword: userPassword}
+	if email != "" {
+		dto.Email = &email
+	}
+
+	u, err := FromNewUserDto(dto, testPasswordConfig(t), existFn(false, nil), existFn(false, nil))
+	if err != nil {
+		t.Fatalf("\t%s\tUnexpected error on user creation: %v", failed, err)
+	}
+	u.emailVerifiedAt = verifiedAt
+
+	return u
+}
+
+// existFn reports username or email existence as given and counts calls
+func existFn(exist bool, calls *int) func(string) (bool, error) {
+	return func(string) (bool, error) {
+		if calls != nil {
+			*calls++
+		}
+		return exist, nil
+	}
+}
+
+func TestChangeEmail(t *testing.T) {
+	verifiedAt := time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		when      string
+		email     string
+		exist     bool
+		err       error
+		wantEmail string
+		verified  bool
+		checked   bool
+	}{
+		{"email differs only in case", " John@Example.COM", false, nil, "john@example.com", true, false},
+		{"email is invalid", "not-an-email", false, InvalidEmailErr, "john@example.com", true, false},
+		{"email is verified by other user", "jane@example.com", true, EmailExistsErr, "john@example.com", true, true},
+		{"email is free", "jane@example.com", false, nil, "jane@example.com", false, true},
+		{"email is cleared", "", false, nil, "", false, false},
+	}
+
+	t.Log("Given the need to test email change of user with verified email")
+	{
+		for i, c := range cases {
+			t.Logf("\tTest %d:\tWhen %s", i+1, c.when)
+			{
+				u := newTestUser(t, "john@example.com", &verifiedAt)
+
+				calls := 0
+				if err := u.ChangeEmail(c.email, existFn(c.exist, &calls)); !errors.Is(err, c.err) {
+					t.Fatalf("\t%s\tError must be %v, got %v", failed, c.err, err)
+				}
+
+				if u.Email() != c.wantEmail || u.EmailVerified() != c.verified {
+					t.Fatalf("\t%s\tEmail must be %q with verified %t, got %q with verified %t", failed, c.wantEmail, c.verified, u.Email(), u.EmailVerified())
+				}
+
+				if (calls > 0) != c.checked {
+					t.Fatalf("\t%s\tEmail existence check must be done %t", failed, c.checked)
+				}
+				t.Logf("\t%s\tEmail must be %q with verified %t", success, c.wantEmail, c.verified)
+			}
+		}
+	}
+}
+
+func TestChangeOwnEmail(t *testing.T) {
+	cases := []struct {
+		when      string
+		email     string
+		password  string
+		err       error
+		wantEmail string
+	}{
+		{"password isn't provided", "jane@example.com", "", WrongPasswordErr, "john@example.com"},
+		{"password is wrong", "jane@example.com", "Wrong123", WrongPasswordErr, "john@example.com"},
+		{"email isn't changed", "JOHN@example.com", "", nil, "john@example.com"},
+		{"password is confirmed", "jane@example.com", userPassword, nil, "jane@example.com"},
+	}
+
+	t.Log("Given the need to test self-service email change")
+	{
+		for i, c := range cases {
+			t.Logf("\tTest %d:\tWhen %s", i+1, c.when)
+			{
+				u := newTestUser(t, "john@example.com", nil)
+
+				if err := u.ChangeOwnEmail(c.email, c.password, existFn(false, nil)); !errors.Is(err, c.err) {
+					t.Fatalf("\t%s\tError must be %v, got %v", failed, c.err, err)
+				}
+
+				if u.Email() != c.wantEmail {
+					t.Fatalf("\t%s\tEmail must be %q, got %q", failed, c.wantEmail, u.Email())
+				}
+				t.Logf("\t%s\tEmail must be %q", success, c.wantEmail)
+			}
+		}
+	}
+}
+
+func TestVerifyEmail(t *testing.T) {
+	now := time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)
+	verifiedAt := now.Add(-time.Hour)
+
+	cases := []struct {
+		when       string
+		email      string
+		verifiedAt *time.Time
+		verify     string
+		exist      bool
+		err        error
+		verified   bool
+	}{
+		{"email isn't set", "", nil, "john@example.com", false, EmailNotSetErr, false},
+		{"email was changed after verification was requested", "john@example.com", nil, "old@example.com", false, EmailChangedErr, false},
+		{"email is verified by other user", "john@example.com", nil, "john@example.com", true, EmailExistsErr, false},
+		{"email differs only in case", "john@example.com", nil, "John@Example.com", false, nil, true},
+		{"email is verified already", "john@example.com", &verifiedAt, "john@example.com", true, nil, true},
+	}
+
+	t.Log("Given the need to test email verification")
+	{
+		for i, c := range cases {
+			t.Logf("\tTest %d:\tWhen %s", i+1, c.when)
+			{
+				u := newTestUser(t, c.email, c.verifiedAt)
+
+				if err := u.VerifyEmail(c.verify, now, existFn(c.exist, nil)); !errors.Is(err, c.err) {
+					t.Fatalf("\t%s\tError must be %v, got %v", failed, c.err, err)
+				}
+
+				if u.EmailVerified() != c.verified {
+					t.Fatalf("\t%s\tEmail verified must be %t", failed, c.verified)
+				}
+				t.Logf("\t%s\tEmail verified must be %t", success, c.verified)
+			}
+		}
+	}
+}
+
+func TestUpdateProfile(t *testing.T) {
+	str := func(s string) *string { return &s }
+
+	cases := []struct {
+		when         string
+		update       ProfileUpdateDto
+		err          error
+		emailChanged bool
+		profile      func(ProfileDto) bool
+	}{
+		{
+			"names are provided",
+			ProfileUpdateDto{FirstName: str("John"), LastName: str("Doe")},
+			nil,
+			false,
+			func(p ProfileDto) bool { return *p.FirstName == "John" && *p.LastName == "Doe" && p.MiddleName == nil },
+		},
+		{
+			"name is cleared with empty string",
+			ProfileUpdateDto{FirstName: str("")},
+			nil,
+			false,
+			func(p ProfileDto) bool { return p.FirstName == nil && *p.LastName == "Smith" },
+		},
+		{
+			"name is too long",
+			ProfileUpdateDto{FirstName: str(strings.Repeat("a", maxNameLength+1))},
+			InvalidProfileErr,
+			false,
+			func(p ProfileDto) bool { return *p.FirstName == "Jack" },
+		},
+		{
+			"email is changed",
+			ProfileUpdateDto{Email: str("jane@example.com")},
+			nil,
+			true,
+			func(p ProfileDto) bool { return *p.Email == "jane@example.com" && !p.EmailVerified },
+		},
+		{
+			"email differs only in case",
+			ProfileUpdateDto{Email: str("JOHN@example.com")},
+			nil,
+			false,
+			func(p ProfileDto) bool { return *p.Email == "john@example.com" },
+		},
+	}
+
+	t.Log("Given the need to test profile update")
+	{
+		for i, c := range cases {
+			t.Logf("\tTest %d:\tWhen %s", i+1, c.when)
+			{
+				u := newTestUser(t, "john@example.com", nil)
+				u.firstName = valueobj.NewNilString("Jack")
+				u.lastName = valueobj.NewNilString("Smith")
+
+				emailChanged, err := u.UpdateProfile(c.update, existFn(false, nil))
+				if !errors.Is(err, c.err) {
+					t.Fatalf("\t%s\tError must be %v, got %v", failed, c.err, err)
+				}
+
+				if emailChanged != c.emailChanged {
+					t.Fatalf("\t%s\tEmail change must be reported %t", failed, c.emailChanged)
+				}
+
+				if !c.profile(u.Profile()) {
+					t.Fatalf("\t%s\tProfile is updated unexpectedly: %+v", failed, u.Profile())
+				}
+				t.Logf("\t%s\tProfile must be updated as requested", success)
+			}
+		}
+	}
+}
+
+func TestUpdateOwnProfile(t *testing.T) {
+	str := func(s string) *string { return &s }
+
+	cases := []struct {
+		when   string
+		update ProfileUpdateDto
+		err    error
+	}{
+		{"only names are changed", ProfileUpdateDto{FirstName: str("John")}, nil},
+		{"email is changed without password", ProfileUpdateDto{Email: str("jane@example.com")}, WrongPasswordErr},
+		{"email is changed with wrong password", ProfileUpdateDto{Email: str("jane@example.com"), CurrentPassword: "Wrong1234"}, WrongPasswordErr},
+		{"email is changed with password", ProfileUpdateDto{Email: str("jane@example.com"), CurrentPassword: userPassword}, nil},
+	}
+
+	t.Log("Given the need to test self-service profile update")
+	{
+		for i, c := range cases {
+			t.Logf("\tTest %d:\tWhen %s", i+1, c.when)
+			{
+				u := newTestUser(t, "john@example.com", nil)
+
+				if _, err := u.UpdateOwnProfile(c.update, existFn(false, nil)); !errors.Is(err, c.err) {
+					t.Fatalf("\t%s\tError must be %v, got %v", failed, c.err, err)
+				}
+
+				if c.err != nil && (u.Email() != "john@example.com" || u.Profile().FirstName != nil) {
+					t.Fatalf("\t%s\tRejected update mustn't change profile", failed)
+				}
+				t.Logf("\t%s\tError must be %v", success, c.err)
+			}
+		}
+	}
+}
+
+func TestChangePassword(t *testing.T) {
+	cfg := testPasswordConfig(t)
+
+	cases := []struct {
+		when     string
+		current  string
+		password string
+		confirm  string
+		err      error
+	}{
+		{"current password isn't provided", "", "NewSecret1", "NewSecret1", WrongPasswordErr},
+		{"current password is wrong", "Wrong1234", "NewSecret1", "NewSecret1", WrongPasswordErr},
+		{"passwords don't match", userPassword, "NewSecret1", "NewSecret2", InvalidPasswordErr},
+		{"password violates policy", userPassword, "short", "short", InvalidPasswordErr},
+		{"password is valid", userPassword, "NewSecret1", "NewSecret1", nil},
+	}
+
+	t.Log("Given the need to test password change")
+	{
+		for i, c := range cases {
+			t.Logf("\tTest %d:\tWhen %s", i+1, c.when)
+			{
+				u := newTestUser(t, "", nil)
+
+				if err := u.ChangePassword(c.current, c.password, c.confirm, cfg); !errors.Is(err, c.err) {
+					t.Fatalf("\t%s\tError must be %v, got %v", failed, c.err, err)
+				}
+
+				expected := userPassword
+				if c.err == nil {
+					expected = c.password
+				}
+
+				if verified, err := u.VerifyPassword(expected); err != nil || !verified {
+					t.Fatalf("\t%s\tPassword must be %q, got %v", failed, expected, err)
+				}
+				t.Logf("\t%s\tPassword must be %q", success, expected)
+			}
+		}
+	}
+}
+
+func TestDisable(t *testing.T) {
+	cfg, err := valueobj.NewRefreshTokenConfig(time.Hour, 5, "refresh-token")
+	if err != nil {
+		t.Fatalf("\t%s\tUnexpected error on refresh config creation: %v", failed, err)
+	}
+	now := time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Log("Given the need to test user disabling")
+	{
+		u := newTestUser(t, "", nil)
+		for _, device := range []string{"laptop", "phone"} {
+			if _, err := u.GenerateRefreshToken(device, nil, refresh.ClientInfo{}, now, cfg); err != nil {
+				t.Fatalf("\t%s\tUnexpected error on session creation: %v", failed, err)
+			}
+		}
+
+		t.Logf("\tTest 1:\tWhen user with sessions is disabled")
+		{
+			u.Disable(now)
+
+			if !u.Disabled() || len(u.Sessions()) != 0 || len(u.TokensDto()) != 0 {
+				t.Fatalf("\t%s\tUser must be disabled and all sessions must be revoked, got %d sessions", failed, len(u.Sessions()))
+			}
+
+			if _, err := u.GenerateRefreshToken("tablet", nil, refresh.ClientInfo{}, now, cfg); !errors.Is(err, UserDisabledErr) {
+				t.Fatalf("\t%s\tNew session must be rejected, got %v", failed, err)
+			}
+			t.Logf("\t%s\tUser must be disabled and all sessions must be revoked", success)
+		}
+
+		t.Logf("\tTest 2:\tWhen user is disabled again")
+		{
+			u.Disable(now.Add(time.Hour))
+			if disabledAt := u.ToDto().DisabledAt; disabledAt == nil || !disabledAt.Equal(now) {
+				t.Fatalf("\t%s\tDisabling time must be kept, got %v", failed, disabledAt)
+			}
+			t.Logf("\t%s\tDisabling time must be kept", success)
+		}
+
+		t.Logf("\tTest 3:\tWhen user is enabled")
+		{
+			u.Enable()
+
+			if u.Disabled() {
+				t.Fatalf("\t%s\tUser must be enabled", failed)
+			}
+
+			if _, err := u.GenerateRefreshToken("laptop", nil, refresh.ClientInfo{}, now, cfg); err != nil {
+				t.Fatalf("\t%s\tNew session must be accepted, got %v", failed, err)
+			}
+			t.Logf("\t%s\tUser must be enabled and allowed to sign in", success)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package valueobj
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	defaultArgon2Memory      = 64 * 1024
+	defaultArgon2Iterations  = 3
+	defaultArgon2Parallelism = 2
+	argon2SaltLength         = 16
+	argon2KeyLength          = 32
+)
+
+type Argon2idHasher struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+}
+
+func NewArgon2idHasher(memory uint32, iterations uint32, parallelism uint8) (Argon2idHasher, error) {
+	var h Argon2idHasher
+
+	if iterations == 0 {
+		return h, errors.New("argon2id iterations must be positive number")
+	}
+	h.iterations = iterations
+
+	if parallelism == 0 {
+		return h, errors.New("argon2id parallelism must be positive number")
+	}
+	h.parallelism = parallelism
+
+	if memory < 8*uint32(parallelism) {
+		return h, errors.Errorf("argon2id memory must be at least %d KiB", 8*uint32(parallelism))
+	}
+	h.memory = memory
+
+	return h, nil
+}
+
+func (h Argon2idHasher) Algorithm() string {
+	return HashAlgorithmArgon2id
+}
+
+func (h Argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, argon2SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", errors.Wrap(err, "failed to generate salt")
+	}
+
+	key := argon2.IDKey([]byte(password), salt, h.iterations, h.memory, h.parallelism, argon2KeyLength)
+
+	phc := phcHash{
+		algorithm: HashAlgorithmArgon2id,
+		version:   strconv.Itoa(argon2.Version),
+		params: []phcParam{
+			{name: "m", value: strconv.FormatUint(uint64(h.memory), 10)},
+			{name: "t", value: strconv.FormatUint(uint64(h.iterations), 10)},
+			{name: "p", value: strconv.FormatUint(uint64(h.parallelism), 10)},
+		},
+		salt: salt,
+		key:  key,
+	}
+	return phc.String(), nil
+}
+
+func (h Argon2idHasher) Verify(password string, encoded string) (bool, error) {
+	phc, memory, iterations, parallelism, err := h.decode(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	key := argon2.IDKey([]byte(password), phc.salt, iterations, memory, parallelism, uint32(len(phc.key)))
+	return subtle.ConstantTimeCompare(key, phc.key) == 1, nil
+}
+
+func (h Argon2idHasher) NeedsRehash(encoded string) bool {
+	phc, memory, iterations, parallelism, err := h.decode(encoded)
+	if err != nil {
+		return true
+	}
+
+	return memory != h.memory ||
+		iterations != h.iterations ||
+		parallelism != h.parallelism ||
+		len(phc.salt) != argon2SaltLength ||
+		len(phc.key) != argon2KeyLength
+}
+
+func (h Argon2idHasher) decode(encoded string) (phcHash, uint32, uint32, uint8, error) {
+	phc, err := decodePhcHash(encoded)
+	if err != nil {
+		return phc, 0, 0, 0, err
+	}
+
+	if phc.algorithm != HashAlgorithmArgon2id {
+		return phc, 0, 0, 0, errors.Errorf("expected argon2id hash, got %s", phc.algorithm)
+	}
+
+	if phc.version != strconv.Itoa(argon2.Version) {
+		return phc, 0, 0, 0, errors.Errorf("unsupported argon2id version %s", phc.version)
+	}
+
+	memory, err := phc.intParam("m")
+	if err != nil {
+		return phc, 0, 0, 0, err
+	}
+
+	iterations, err := phc.intParam("t")
+	if err != nil {
+		return phc, 0, 0, 0, err
+	}
+
+	parallelism, err := phc.intParam("p")
+	if err != nil {
+		return phc, 0, 0, 0, err
+	}
+
+	if memory <= 0 || iterations <= 0 || parallelism <= 0 || parallelism > 255 {
+		return phc, 0, 0, 0, errors.New("argon2id hash has invalid parameters")
+	}
+
+	if len(phc.key) == 0 {
+		return phc, 0, 0, 0, errors.New("argon2id hash value is empty")
+	}
+
+	return phc, uint32(memory), uint32(iterations), uint8(parallelism), nil
+}
@@ -0,0 +1,58 @@
+package valueobj
+
+import (
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const defaultBcryptCost = bcrypt.DefaultCost
+
+type BcryptHasher struct {
+	cost int
+}
+
+func NewBcryptHasher(cost int) (BcryptHasher, error) {
+	var h BcryptHasher
+
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		return h, errors.Errorf("bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+	}
+	h.cost = cost
+
+	return h, nil
+}
+
+func (h BcryptHasher) Algorithm() string {
+	return HashAlgorithmBcrypt
+}
+
+func (h BcryptHasher) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.cost)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to generate bcrypt hash")
+	}
+	return string(hash), nil
+}
+
+func (h BcryptHasher) Verify(password string, encoded string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+	if err != nil {
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, nil
+		}
+		return false, errors.Wrap(err, "failed to compare bcrypt hash")
+	}
+	return true, nil
+}
+
+func (h BcryptHasher) NeedsRehash(encoded string) bool {
+	if alg, err := HashAlgorithmOf(encoded); err != nil || alg != HashAlgorithmBcrypt {
+		return true
+	}
+
+	cost, err := bcrypt.Cost([]byte(encoded))
+	if err != nil {
+		return true
+	}
+	return cost != h.cost
+}
@@ -0,0 +1,28 @@
+package valueobj
+
+import (
+	"github.com/pkg/errors"
+)
+
+// DeletePolicy decides what happens to references of deleted entry, either deletion is rejected or references are removed too
+type DeletePolicy string
+
+const (
+	DeleteReject  DeletePolicy = "reject"
+	DeleteCascade DeletePolicy = "cascade"
+)
+
+// ParseDeletePolicy reads delete policy, empty value means rejection
+func ParseDeletePolicy(policy string) (DeletePolicy, error) {
+	switch DeletePolicy(policy) {
+	case "", DeleteReject:
+		return DeleteReject, nil
+	case DeleteCascade:
+		return DeleteCascade, nil
+	}
+	return "", errors.Errorf("delete policy must be %s or %s", DeleteReject, DeleteCascade)
+}
+
+func (p DeletePolicy) IsCascade() bool {
+	return p == DeleteCascade
+}
@@ -0,0 +1,37 @@
+package valueobj
+
+import (
+	"testing"
+)
+
+func TestParseDeletePolicy(t *testing.T) {
+	cases := []struct {
+		policy  string
+		parsed  DeletePolicy
+		invalid bool
+	}{
+		{"", DeleteReject, false},
+		{"reject", DeleteReject, false},
+		{"cascade", DeleteCascade, false},
+		{"CASCADE", "", true},
+		{"force", "", true},
+	}
+
+	t.Log("Given the need to test delete policy parsing")
+	{
+		for i, c := range cases {
+			t.Logf("\tTest %d:\tWhen policy %q is parsed", i+1, c.policy)
+			{
+				parsed, err := ParseDeletePolicy(c.policy)
+				if (err != nil) != c.invalid || parsed != c.parsed {
+					t.Fatalf("\t%s\tPolicy must be parsed to %q, got %q with error %v", failed, c.parsed, parsed, err)
+				}
+
+				if parsed.IsCascade() != (c.parsed == DeleteCascade) {
+					t.Fatalf("\t%s\tOnly cascade policy must cascade", failed)
+				}
+				t.Logf("\t%s\tPolicy must be parsed to %q", success, c.parsed)
+			}
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package valueobj
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type DenylistConfig struct {
+	enabled   bool
+	cacheTtl  time.Duration
+	cacheSize int
+}
+
+// NewDenylistConfig builds config of access tokens denylist check, cache ttl limits how long
+// not revoked token is trusted by the instance without asking redis
+func NewDenylistConfig(enabled bool, cacheTtl time.Duration, cacheSize int) (DenylistConfig, error) {
+	var cfg DenylistConfig
+
+	if cacheTtl < 0 {
+		return cfg, errors.New("denylist cache ttl can't be negative")
+	}
+
+	if cacheSize < 0 {
+		return cfg, errors.New("denylist cache size can't be negative")
+	}
+
+	cfg.enabled = enabled
+	cfg.cacheTtl = cacheTtl
+	cfg.cacheSize = cacheSize
+
+	return cfg, nil
+}
+
+func (cfg DenylistConfig) Enabled() bool {
+	return cfg.enabled
+}
+
+func (cfg DenylistConfig) CacheTimeToLive() time.Duration {
+	return cfg.cacheTtl
+}
+
+func (cfg DenylistConfig) CacheSize() int {
+	return cfg.cacheSize
+}
@@ -1,6 +1,9 @@
 package valueobj
 
-import "net/mail"
+import (
+	"net/mail"
+	"strings"
+)
 
 type Email struct {
 	str string
@@ -17,3 +20,8 @@ func NewEmail(s string) (Email, error) {
 func (e Email) String() string {
 	return e.str
 }
+
+// NormalizeEmail returns form emails are compared and indexed by, so addresses differing in case are the same
+func NormalizeEmail(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
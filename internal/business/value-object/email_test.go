@@ -0,0 +1,54 @@
+package valueobj
+
+import (
+	"testing"
+)
+
+func TestNormalizeEmail(t *testing.T) {
+	cases := []struct {
+		email      string
+		normalized string
+	}{
+		{"john@example.com", "john@example.com"},
+		{"John@Example.COM", "john@example.com"},
+		{"  john@example.com\t", "john@example.com"},
+		{"", ""},
+	}
+
+	t.Log("Given the need to test email normalization")
+	{
+		for i, c := range cases {
+			t.Logf("\tTest %d:\tWhen %q is normalized", i+1, c.email)
+			{
+				if normalized := NormalizeEmail(c.email); normalized != c.normalized {
+					t.Fatalf("\t%s\tEmail must be normalized to %q, got %q", failed, c.normalized, normalized)
+				}
+				t.Logf("\t%s\tEmail must be normalized to %q", success, c.normalized)
+			}
+		}
+	}
+}
+
+func TestNewNilEmail(t *testing.T) {
+	t.Log("Given the need to test optional email")
+	{
+		t.Logf("\tTest 1:\tWhen email is empty")
+		{
+			email, err := NewNilEmail("")
+			if err != nil || email.Ptr() != nil {
+				t.Fatalf("\t%s\tEmpty email must be accepted as unset, got %v", failed, err)
+			}
+			t.Logf("\t%s\tEmpty email must be accepted as unset", success)
+		}
+
+		t.Logf("\tTest 2:\tWhen email is invalid")
+		{
+			for _, s := range []string{"john", "john@", "@example.com"} {
+				if _, err := NewNilEmail(s); err == nil {
+					t.Fatalf("\t%s\tEmail %q must be rejected", failed, s)
+				}
+			}
+			t.Logf("\t%s\tInvalid emails must be rejected", success)
+		}
+	}
+}
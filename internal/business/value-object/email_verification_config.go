@@ -0,0 +1,50 @@
+package valueobj
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type EmailVerificationConfig struct {
+	tokenTtl  time.Duration
+	verifyUrl *url.URL
+	required  bool
+}
+
+// NewEmailVerificationConfig builds config of email verification, verify url is page of client application
+// which receives token in query. If verification is required, sign in is blocked until email is verified.
+func NewEmailVerificationConfig(tokenTtl time.Duration, verifyUrl string, required bool) (EmailVerificationConfig, error) {
+	var cfg EmailVerificationConfig
+
+	if tokenTtl <= 0 {
+		return cfg, errors.New("email verification token ttl must be provided")
+	}
+	cfg.tokenTtl = tokenTtl
+
+	u, err := parseLinkUrl(verifyUrl)
+	if err != nil {
+		return cfg, errors.Wrap(err, "invalid email verification url")
+	}
+	cfg.verifyUrl = u
+	cfg.required = required
+
+	return cfg, nil
+}
+
+func (cfg EmailVerificationConfig) TokenTimeToLive() time.Duration {
+	return cfg.tokenTtl
+}
+
+func (cfg EmailVerificationConfig) Required() bool {
+	return cfg.required
+}
+
+// VerifyLink returns link with token in query, empty string is returned if verify url is not configured
+func (cfg EmailVerificationConfig) VerifyLink(token string) string {
+	if cfg.verifyUrl == nil {
+		return ""
+	}
+	return linkWithToken(cfg.verifyUrl, token)
+}
@@ -0,0 +1,131 @@
+package valueobj
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+
+	"github.com/pkg/errors"
+)
+
+// Jwk is public key representation defined by RFC 7517 (OKP key type is defined by RFC 8037)
+type Jwk struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	Crv string `json:"crv,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+type JwkSet struct {
+	Keys []Jwk `json:"keys"`
+}
+
+func NewJwk(pub crypto.PublicKey, alg string) (Jwk, error) {
+	jwk, err := publicJwkMembers(pub)
+	if err != nil {
+		return Jwk{}, err
+	}
+
+	kid, err := Thumbprint(pub)
+	if err != nil {
+		return Jwk{}, err
+	}
+
+	jwk.Use = "sig"
+	jwk.Alg = alg
+	jwk.Kid = kid
+	return jwk, nil
+}
+
+// Thumbprint calculates RFC 7638 thumbprint of the key, it is used as key id
+func Thumbprint(pub crypto.PublicKey) (string, error) {
+	jwk, err := publicJwkMembers(pub)
+	if err != nil {
+		return "", err
+	}
+
+	// members must be ordered lexicographically and have no whitespaces, struct fields order guarantees it
+	var canonical []byte
+	switch jwk.Kty {
+	case "RSA":
+		canonical, err = json.Marshal(struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{E: jwk.E, Kty: jwk.Kty, N: jwk.N})
+	case "EC":
+		canonical, err = json.Marshal(struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		}{Crv: jwk.Crv, Kty: jwk.Kty, X: jwk.X, Y: jwk.Y})
+	default:
+		canonical, err = json.Marshal(struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{Crv: jwk.Crv, Kty: jwk.Kty, X: jwk.X})
+	}
+	if err != nil {
+		return "", errors.Wrap(err, "failed to build canonical JWK for thumbprint")
+	}
+
+	sum := sha256.Sum256(canonical)
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+func publicJwkMembers(pub crypto.PublicKey) (Jwk, error) {
+	switch key := pub.(type) {
+	case *rsa.PublicKey:
+		if key == nil {
+			return Jwk{}, errors.New("public key can't be initial")
+		}
+
+		return Jwk{
+			Kty: "RSA",
+			N:   encodeBigInt(key.N),
+			E:   encodeBigInt(big.NewInt(int64(key.E))),
+		}, nil
+	case *ecdsa.PublicKey:
+		if key == nil {
+			return Jwk{}, errors.New("public key can't be initial")
+		}
+
+		params := key.Curve.Params()
+		// coordinates must be padded to the curve size (RFC 7518 6.2.1.2)
+		size := (params.BitSize + 7) / 8
+		return Jwk{
+			Kty: "EC",
+			Crv: params.Name,
+			X:   base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, size))),
+			Y:   base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, size))),
+		}, nil
+	case ed25519.PublicKey:
+		if len(key) != ed25519.PublicKeySize {
+			return Jwk{}, errors.New("public key can't be initial")
+		}
+
+		return Jwk{
+			Kty: "OKP",
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(key),
+		}, nil
+	default:
+		return Jwk{}, errors.Errorf("unsupported public key type %T", pub)
+	}
+}
+
+func encodeBigInt(n *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(n.Bytes())
+}
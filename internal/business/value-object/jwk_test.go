@@ -0,0 +1,82 @@
+package valueobj
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+	"testing"
+)
+
+// RFC 7638 section 3.1 example key
+const (
+	rfcModulus    = "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw"
+	rfcThumbprint = "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs"
+)
+
+// RFC 8037 appendix A example key
+const (
+	rfcOkpX          = "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"
+	rfcOkpThumbprint = "kPrK_qmxVWaYVA9wwBF6Iuo3vVzz7TxHCTwXBygrS4k"
+)
+
+func TestRsaThumbprint(t *testing.T) {
+	modulus, err := base64.RawURLEncoding.DecodeString(rfcModulus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub := &rsa.PublicKey{N: new(big.Int).SetBytes(modulus), E: 65537}
+
+	t.Log("Given the need to test JWK thumbprint calculation")
+	{
+		t.Logf("\tTest 1:\tWhen thumbprint of RFC 7638 example key is calculated")
+		{
+			kid, err := Thumbprint(pub)
+			if err != nil {
+				t.Fatalf("\t%s\tUnexpected error occurred: %v", failed, err)
+			}
+
+			if kid != rfcThumbprint {
+				t.Fatalf("\t%s\tThumbprint must be %s, but got %s", failed, rfcThumbprint, kid)
+			}
+			t.Logf("\t%s\tThumbprint must match RFC example", success)
+		}
+
+		t.Logf("\tTest 2:\tWhen JWK is built for the key")
+		{
+			jwk, err := NewJwk(pub, "RS256")
+			if err != nil {
+				t.Fatalf("\t%s\tUnexpected error occurred: %v", failed, err)
+			}
+
+			if jwk.Kid != rfcThumbprint || jwk.N != rfcModulus || jwk.E != "AQAB" {
+				t.Fatalf("\t%s\tJWK must carry thumbprint as kid and base64url encoded key members, got %+v", failed, jwk)
+			}
+			t.Logf("\t%s\tJWK must carry thumbprint as kid and base64url encoded key members", success)
+		}
+	}
+}
+
+func TestOkpThumbprint(t *testing.T) {
+	x, err := base64.RawURLEncoding.DecodeString(rfcOkpX)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub := ed25519.PublicKey(x)
+
+	t.Log("Given the need to test JWK thumbprint calculation for Ed25519 keys")
+	{
+		t.Logf("\tTest 1:\tWhen JWK is built for RFC 8037 example key")
+		{
+			jwk, err := NewJwk(pub, "EdDSA")
+			if err != nil {
+				t.Fatalf("\t%s\tUnexpected error occurred: %v", failed, err)
+			}
+
+			if jwk.Kid != rfcOkpThumbprint || jwk.Kty != "OKP" || jwk.Crv != "Ed25519" || jwk.X != rfcOkpX {
+				t.Fatalf("\t%s\tJWK must be OKP key with RFC thumbprint as kid, got %+v", failed, jwk)
+			}
+			t.Logf("\t%s\tJWK must be OKP key with RFC thumbprint as kid", success)
+		}
+	}
+}
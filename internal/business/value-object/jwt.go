@@ -1,7 +1,7 @@
 package valueobj
 
 import (
-	"crypto/rsa"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
@@ -10,13 +10,31 @@ import (
 	"github.com/google/uuid"
 )
 
+// authentication methods references (RFC 8176)
+const (
+	AmrPassword = "pwd"
+	AmrOtp      = "otp"
+	AmrMfa      = "mfa"
+	AmrHardware = "hwk"
+	AmrUser     = "user"
+)
+
+// AccessTokenType is typ header of access tokens (RFC 9068), it distinguishes them from ID tokens signed with the same keys
+const AccessTokenType = "at+jwt"
+
 type Jwt struct {
+	id        string
 	signed    string
 	tokenType string
 	expiresAt time.Time
+	amr       []string
+	org       string
 }
 
-func NewJwt(user string, issuedAt time.Time, roles []string, scopes []string, cfg JwtConfig) (Jwt, error) {
+// NewJwt builds signed access token, amr lists methods used to authenticate user and is omitted if empty,
+// superuser claim is emitted only if it is set. Token issued for organization carries its name in org claim,
+// roles and scopes must belong to that organization then. Token issued to OAuth client carries its id in client_id claim.
+func NewJwt(user string, issuedAt time.Time, org string, clientId string, roles []string, scopes []string, superuser bool, amr []string, cfg JwtConfig) (Jwt, error) {
 	var accessToken Jwt
 
 	if user == "" {
@@ -31,7 +49,9 @@ func NewJwt(user string, issuedAt time.Time, roles []string, scopes []string, cf
 		scopes = make([]string, 0)
 	}
 
-	method := jwt.GetSigningMethod(cfg.algorithm)
+	// keyring can be replaced concurrently, so algorithm, kid and signing key are taken from the same key
+	active := cfg.keyring.Keyring().Active()
+	method := jwt.GetSigningMethod(active.Algorithm())
 
 	if issuedAt.IsZero() {
 		return accessToken, errors.New("issued timestamp is mandatory")
@@ -39,21 +59,30 @@ func NewJwt(user string, issuedAt time.Time, roles []string, scopes []string, cf
 
 	expiresAt := issuedAt.Add(cfg.ttl)
 	accessToken.expiresAt = expiresAt
+	accessToken.id = uuid.NewString()
+	accessToken.amr = amr
+	accessToken.org = org
 
 	claims := JwtClaims{
 		RegisteredClaims: jwt.RegisteredClaims{
-			ID:        uuid.NewString(),
+			ID:        accessToken.id,
 			Issuer:    cfg.issuer,
 			Subject:   user,
 			IssuedAt:  jwt.NewNumericDate(issuedAt),
 			ExpiresAt: jwt.NewNumericDate(expiresAt),
 		},
-		SubjRoles:  roles,
-		SubjScopes: scopes,
+		Org:         org,
+		Client:      clientId,
+		SubjRoles:   roles,
+		SubjScopes:  scopes,
+		Superuser:   superuser,
+		AuthMethods: amr,
 	}
 
 	token := jwt.NewWithClaims(method, claims)
-	signed, err := token.SignedString(cfg.privateKey)
+	token.Header["typ"] = AccessTokenType
+	token.Header["kid"] = active.KeyId()
+	signed, err := token.SignedString(active.PrivateKey())
 	if err != nil {
 		return accessToken, err
 	}
@@ -64,6 +93,108 @@ func NewJwt(user string, issuedAt time.Time, roles []string, scopes []string, cf
 	return accessToken, nil
 }
 
+// NewIdToken builds OpenID Connect ID token for the client (audience), nonce is echoed back if provided on authorization
+func NewIdToken(user string, audience string, nonce string, issuedAt time.Time, authTime time.Time, amr []string, cfg JwtConfig) (Jwt, error) {
+	var idToken Jwt
+
+	if user == "" {
+		return idToken, errors.New("user is mandatory for ID token generation (used as subject)")
+	}
+
+	if audience == "" {
+		return idToken, errors.New("audience is mandatory for ID token generation")
+	}
+
+	if issuedAt.IsZero() {
+		return idToken, errors.New("issued timestamp is mandatory")
+	}
+
+	expiresAt := issuedAt.Add(cfg.ttl)
+	idToken.expiresAt = expiresAt
+	idToken.id = uuid.NewString()
+
+	claims := IdTokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        idToken.id,
+			Issuer:    cfg.issuer,
+			Subject:   user,
+			Audience:  jwt.ClaimStrings{audience},
+			IssuedAt:  jwt.NewNumericDate(issuedAt),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+		Nonce:       nonce,
+		AuthTime:    jwt.NewNumericDate(authTime),
+		AuthMethods: amr,
+	}
+
+	active := cfg.keyring.Keyring().Active()
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(active.Algorithm()), claims)
+	token.Header["kid"] = active.KeyId()
+	signed, err := token.SignedString(active.PrivateKey())
+	if err != nil {
+		return idToken, err
+	}
+	idToken.signed = signed
+
+	return idToken, nil
+}
+
+// Id returns token identifier (jti claim)
+func (jwt Jwt) Id() string {
+	return jwt.id
+}
+
+// ParseJwt verifies token signature with keyring key referenced by kid and validates registered claims,
+// only access tokens issued by configured issuer are accepted, so ID tokens can't be used as access tokens
+func ParseJwt(rawToken string, cfg JwtConfig) (JwtClaims, error) {
+	var claims JwtClaims
+	keyring := cfg.keyring.Keyring()
+	parser := jwt.NewParser(jwt.WithValidMethods(keyring.Algorithms()))
+
+	keyFunc := func(token *jwt.Token) (any, error) {
+		if typ, _ := token.Header["typ"].(string); !isAccessTokenType(typ) {
+			return nil, errors.Errorf("unexpected token type: %v", token.Header["typ"])
+		}
+
+		kid, ok := token.Header["kid"].(string)
+		if !ok {
+			return nil, errors.New("signing key id is missing")
+		}
+
+		// retired key is accepted only until its removal time, even if process wasn't restarted since
+		key, ok := keyring.VerificationKey(kid, jwt.TimeFunc())
+		if !ok {
+			return nil, errors.Errorf("unknown signing key: %s", kid)
+		}
+
+		// algorithm is bound to the key, so token can't be verified with the key of another type
+		if token.Method.Alg() != key.Algorithm() {
+			return nil, errors.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return key.PublicKey(), nil
+	}
+
+	if _, err := parser.ParseWithClaims(rawToken, &claims, keyFunc); err != nil {
+		return claims, err
+	}
+
+	if !claims.VerifyIssuer(cfg.issuer, true) {
+		return claims, errors.Errorf("unexpected issuer: %s", claims.Issuer)
+	}
+
+	if len(claims.Audience) > 0 || claims.Nonce != "" {
+		return claims, errors.New("audience and nonce are never issued in access tokens")
+	}
+
+	return claims, nil
+}
+
+// isAccessTokenType accepts both short and full media type form of access token type (RFC 9068 2.1)
+func isAccessTokenType(typ string) bool {
+	typ = strings.ToLower(typ)
+	return typ == AccessTokenType || typ == "application/"+AccessTokenType
+}
+
 func (jwt Jwt) String() string {
 	return jwt.signed
 }
@@ -76,10 +207,38 @@ func (jwt Jwt) TokenType() string {
 	return jwt.tokenType
 }
 
+// Amr returns authentication methods references the token was issued with
+func (jwt Jwt) Amr() []string {
+	return jwt.amr
+}
+
+// Organization returns organization the token was issued for, empty string means no organization
+func (jwt Jwt) Organization() string {
+	return jwt.org
+}
+
 type JwtClaims struct {
 	jwt.RegisteredClaims
-	SubjRoles  []string `json:"roles"`
-	SubjScopes []string `json:"scopes"`
+	Org         string   `json:"org,omitempty"`
+	Client      string   `json:"client_id,omitempty"`
+	SubjRoles   []string `json:"roles"`
+	SubjScopes  []string `json:"scopes"`
+	Superuser   bool     `json:"superuser,omitempty"`
+	AuthMethods []string `json:"amr,omitempty"`
+	// Nonce is never issued in access tokens, it is parsed only to reject ID tokens
+	Nonce string `json:"nonce,omitempty"`
+}
+
+func (c JwtClaims) TokenId() string {
+	return c.ID
+}
+
+// Expiry returns expiration time, zero time is returned if token has no expiration
+func (c JwtClaims) Expiry() time.Time {
+	if c.ExpiresAt == nil {
+		return time.Time{}
+	}
+	return c.ExpiresAt.Time
 }
 
 func (c JwtClaims) Username() string {
@@ -94,31 +253,44 @@ func (c JwtClaims) Scopes() []string {
 	return c.SubjScopes
 }
 
-type JwtConfig struct {
-	algorithm  string
-	issuer     string
-	privateKey *rsa.PrivateKey
-	publicKey  *rsa.PublicKey
-	ttl        time.Duration
+func (c JwtClaims) Organization() string {
+	return c.Org
 }
 
-func NewJwtConfig(alg string, issuer string, rsaPrivate *rsa.PrivateKey, rsaPublic *rsa.PublicKey, ttl time.Duration) (JwtConfig, error) {
-	var cfg JwtConfig
+// ClientId returns OAuth client token was issued to, empty string is returned for sessions user started itself
+func (c JwtClaims) ClientId() string {
+	return c.Client
+}
 
-	if jwt.GetSigningMethod(alg) == nil {
-		return cfg, errors.Errorf("%s invalid alogrithm for JWT generation", alg)
-	}
-	cfg.algorithm = alg
+func (c JwtClaims) IsSuperuser() bool {
+	return c.Superuser
+}
 
-	if rsaPrivate == nil {
-		return cfg, errors.New("private key can't be initial")
-	}
-	cfg.privateKey = rsaPrivate
+func (c JwtClaims) Amr() []string {
+	return c.AuthMethods
+}
 
-	if rsaPublic == nil {
-		return cfg, errors.New("public key can't be initial")
+type IdTokenClaims struct {
+	jwt.RegisteredClaims
+	Nonce       string           `json:"nonce,omitempty"`
+	AuthTime    *jwt.NumericDate `json:"auth_time,omitempty"`
+	AuthMethods []string         `json:"amr,omitempty"`
+}
+
+type JwtConfig struct {
+	issuer  string
+	keyring KeyringSource
+	ttl     time.Duration
+}
+
+// NewJwtConfig builds config, tokens are signed with algorithm of the current keyring active key
+func NewJwtConfig(issuer string, keyring KeyringSource, ttl time.Duration) (JwtConfig, error) {
+	var cfg JwtConfig
+
+	if keyring == nil || keyring.Keyring().Active().PrivateKey() == nil {
+		return cfg, errors.New("keyring must have active signing key")
 	}
-	cfg.publicKey = rsaPublic
+	cfg.keyring = keyring
 
 	if issuer == "" {
 		return cfg, errors.New("issuer can't be initial")
@@ -134,19 +306,37 @@ func NewJwtConfig(alg string, issuer string, rsaPrivate *rsa.PrivateKey, rsaPubl
 }
 
 func (cfg JwtConfig) Algorithm() string {
-	return cfg.algorithm
+	return cfg.keyring.Keyring().Active().Algorithm()
 }
 
 func (cfg JwtConfig) Issuer() string {
 	return cfg.issuer
 }
 
-func (cfg JwtConfig) PrivateKey() *rsa.PrivateKey {
-	return cfg.privateKey
+func (cfg JwtConfig) Keyring() Keyring {
+	return cfg.keyring.Keyring()
+}
+
+func (cfg JwtConfig) KeyId() string {
+	return cfg.keyring.Keyring().Active().KeyId()
+}
+
+// VerificationKey returns key by key id, either active or retired one which isn't removed yet
+func (cfg JwtConfig) VerificationKey(keyId string, at time.Time) (SigningKey, bool) {
+	return cfg.keyring.Keyring().VerificationKey(keyId, at)
 }
 
-func (cfg JwtConfig) PublicKey() *rsa.PublicKey {
-	return cfg.publicKey
+// Jwks returns public keys of the current keyring in JWK Set format, keys are published ahead of activation
+func (cfg JwtConfig) Jwks(at time.Time) (JwkSet, error) {
+	keys := make([]Jwk, 0)
+	for _, key := range cfg.keyring.Keyring().Keys(at) {
+		jwk, err := NewJwk(key.PublicKey(), key.Algorithm())
+		if err != nil {
+			return JwkSet{}, err
+		}
+		keys = append(keys, jwk)
+	}
+	return JwkSet{Keys: keys}, nil
 }
 
 func (cfg JwtConfig) TimeToLive() time.Duration {
@@ -0,0 +1,116 @@
+package valueobj
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+	"time"
+)
+
+func newTestJwtConfig(t *testing.T) JwtConfig {
+	private, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := NewSigningKey("ES256", private, private.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kr, err := NewKeyring(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := NewJwtConfig("authsrv", kr, 15*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+func TestParseJwt(t *testing.T) {
+	cfg := newTestJwtConfig(t)
+
+	t.Log("Given the need to test JWT parsing")
+	{
+		t.Logf("\tTest 1:\tWhen token signed with keyring key is parsed")
+		{
+			token, err := NewJwt("john", time.Now(), "acme", "web", []string{"admin"}, []string{"users:read"}, true, []string{AmrPassword}, cfg)
+			if err != nil {
+				t.Fatalf("\t%s\tUnexpected error occurred: %v", failed, err)
+			}
+
+			claims, err := ParseJwt(token.String(), cfg)
+			if err != nil {
+				t.Fatalf("\t%s\tUnexpected error occurred: %v", failed, err)
+			}
+
+			if claims.TokenId() != token.Id() || claims.Username() != "john" || claims.Expiry().Unix() != token.ExpiresAt() ||
+				len(claims.Amr()) != 1 || claims.Amr()[0] != AmrPassword || !claims.IsSuperuser() ||
+				claims.Organization() != "acme" || token.Organization() != "acme" || claims.ClientId() != "web" {
+				t.Fatalf("\t%s\tClaims must match issued token, got %+v", failed, claims)
+			}
+			t.Logf("\t%s\tClaims must match issued token", success)
+		}
+
+		t.Logf("\tTest 2:\tWhen token signed with unknown key is parsed")
+		{
+			token, err := NewJwt("john", time.Now(), "", "", nil, nil, false, nil, newTestJwtConfig(t))
+			if err != nil {
+				t.Fatalf("\t%s\tUnexpected error occurred: %v", failed, err)
+			}
+
+			if _, err := ParseJwt(token.String(), cfg); err == nil {
+				t.Fatalf("\t%s\tToken must be rejected", failed)
+			}
+			t.Logf("\t%s\tToken must be rejected", success)
+		}
+
+		t.Logf("\tTest 3:\tWhen expired token is parsed")
+		{
+			token, err := NewJwt("john", time.Now().Add(-time.Hour), "", "", nil, nil, false, nil, cfg)
+			if err != nil {
+				t.Fatalf("\t%s\tUnexpected error occurred: %v", failed, err)
+			}
+
+			if _, err := ParseJwt(token.String(), cfg); err == nil {
+				t.Fatalf("\t%s\tToken must be rejected", failed)
+			}
+			t.Logf("\t%s\tToken must be rejected", success)
+		}
+
+		t.Logf("\tTest 4:\tWhen ID token signed with the same key is parsed")
+		{
+			token, err := NewIdToken("john", "client", "nonce", time.Now(), time.Now(), nil, cfg)
+			if err != nil {
+				t.Fatalf("\t%s\tUnexpected error occurred: %v", failed, err)
+			}
+
+			if _, err := ParseJwt(token.String(), cfg); err == nil {
+				t.Fatalf("\t%s\tToken must be rejected", failed)
+			}
+			t.Logf("\t%s\tToken must be rejected", success)
+		}
+
+		t.Logf("\tTest 5:\tWhen token of another issuer is parsed")
+		{
+			otherCfg, err := NewJwtConfig("other", cfg.Keyring(), cfg.TimeToLive())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			token, err := NewJwt("john", time.Now(), "", "", nil, nil, false, nil, otherCfg)
+			if err != nil {
+				t.Fatalf("\t%s\tUnexpected error occurred: %v", failed, err)
+			}
+
+			if _, err := ParseJwt(token.String(), cfg); err == nil {
+				t.Fatalf("\t%s\tToken must be rejected", failed)
+			}
+			t.Logf("\t%s\tToken must be rejected", success)
+		}
+	}
+}
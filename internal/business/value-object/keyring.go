@@ -0,0 +1,178 @@
+package valueobj
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/pkg/errors"
+	"golang.org/x/exp/slices"
+)
+
+type SigningKey struct {
+	keyId       string
+	algorithm   string
+	privateKey  crypto.Signer
+	publicKey   crypto.PublicKey
+	removeAfter time.Time
+}
+
+// NewSigningKey builds key identified by its thumbprint, private key is optional for verification-only keys
+func NewSigningKey(alg string, private crypto.Signer, public crypto.PublicKey) (SigningKey, error) {
+	var key SigningKey
+
+	if public == nil {
+		return key, errors.New("public key can't be initial")
+	}
+
+	if private != nil {
+		pub, ok := private.Public().(interface{ Equal(crypto.PublicKey) bool })
+		if !ok || !pub.Equal(public) {
+			return key, errors.New("private key doesn't match public key")
+		}
+	}
+
+	if err := verifyKeyAlgorithm(alg, public); err != nil {
+		return key, err
+	}
+
+	keyId, err := Thumbprint(public)
+	if err != nil {
+		return key, errors.Wrap(err, "failed to calculate key id")
+	}
+
+	key.keyId = keyId
+	key.algorithm = alg
+	key.privateKey = private
+	key.publicKey = public
+
+	return key, nil
+}
+
+func (k SigningKey) KeyId() string {
+	return k.keyId
+}
+
+func (k SigningKey) Algorithm() string {
+	return k.algorithm
+}
+
+func (k SigningKey) PrivateKey() crypto.Signer {
+	return k.privateKey
+}
+
+func (k SigningKey) PublicKey() crypto.PublicKey {
+	return k.publicKey
+}
+
+// WithRemoveAfter returns copy of retired key which isn't accepted after given moment
+func (k SigningKey) WithRemoveAfter(removeAfter time.Time) SigningKey {
+	k.removeAfter = removeAfter
+	return k
+}
+
+// IsRemoved reports if retired key passed its removal time, keys without removal time are never removed
+func (k SigningKey) IsRemoved(at time.Time) bool {
+	return !k.removeAfter.IsZero() && k.removeAfter.Before(at)
+}
+
+// KeyringSource provides current keyring, keyring loaded from keystore is replaced while server is running
+type KeyringSource interface {
+	Keyring() Keyring
+}
+
+// Keyring holds the only active key used for signing and keys which are accepted on verification only:
+// retired keys until their removal time and keys published ahead of their activation
+type Keyring struct {
+	active  SigningKey
+	retired []SigningKey
+}
+
+func NewKeyring(active SigningKey, retired ...SigningKey) (Keyring, error) {
+	var kr Keyring
+
+	if active.privateKey == nil {
+		return kr, errors.New("active key must have private key")
+	}
+	kr.active = active
+
+	kr.retired = make([]SigningKey, 0)
+	for _, key := range retired {
+		if key.keyId == active.keyId {
+			continue
+		}
+		kr.retired = append(kr.retired, key)
+	}
+
+	return kr, nil
+}
+
+// Keyring is static source of itself
+func (kr Keyring) Keyring() Keyring {
+	return kr
+}
+
+func (kr Keyring) Active() SigningKey {
+	return kr.active
+}
+
+// Keys returns active key followed by other keys which aren't removed at given moment
+func (kr Keyring) Keys(at time.Time) []SigningKey {
+	keys := []SigningKey{kr.active}
+	for _, key := range kr.retired {
+		if !key.IsRemoved(at) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// VerificationKey returns key by key id, retired key is returned only until its removal time
+func (kr Keyring) VerificationKey(keyId string, at time.Time) (SigningKey, bool) {
+	for _, key := range kr.Keys(at) {
+		if key.keyId == keyId {
+			return key, true
+		}
+	}
+	return SigningKey{}, false
+}
+
+// Algorithms returns distinct algorithms of the keyring keys
+func (kr Keyring) Algorithms() []string {
+	algs := make([]string, 0)
+	for _, key := range append([]SigningKey{kr.active}, kr.retired...) {
+		if !slices.Contains(algs, key.algorithm) {
+			algs = append(algs, key.algorithm)
+		}
+	}
+	return algs
+}
+
+// verifyKeyAlgorithm checks key can be used with JWS algorithm, symmetric algorithms are not supported
+func verifyKeyAlgorithm(alg string, public crypto.PublicKey) error {
+	switch method := jwt.GetSigningMethod(alg).(type) {
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+		if _, ok := public.(*rsa.PublicKey); !ok {
+			return errors.Errorf("algorithm %s requires RSA key", alg)
+		}
+	case *jwt.SigningMethodECDSA:
+		key, ok := public.(*ecdsa.PublicKey)
+		if !ok {
+			return errors.Errorf("algorithm %s requires EC key", alg)
+		}
+
+		if key.Curve.Params().BitSize != method.CurveBits {
+			return errors.Errorf("algorithm %s requires %d bit curve, but key has %s curve", alg, method.CurveBits, key.Curve.Params().Name)
+		}
+	case *jwt.SigningMethodEd25519:
+		if _, ok := public.(ed25519.PublicKey); !ok {
+			return errors.Errorf("algorithm %s requires Ed25519 key", alg)
+		}
+	default:
+		return errors.Errorf("%s is not supported algorithm for JWT signing", alg)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package valueobj
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type LockoutConfig struct {
+	maxFailures   int
+	ipMaxFailures int
+	window        time.Duration
+	backoff       time.Duration
+	lockDuration  time.Duration
+}
+
+// NewLockoutConfig builds config of sign in throttling, failures are counted within window per username and per ip,
+// every failure delays next attempt exponentially and max failures lock account for lock duration
+func NewLockoutConfig(maxFailures int, ipMaxFailures int, window time.Duration, backoff time.Duration, lockDuration time.Duration) (LockoutConfig, error) {
+	var cfg LockoutConfig
+
+	if maxFailures <= 0 || ipMaxFailures <= 0 {
+		return cfg, errors.New("lockout max failures must be positive")
+	}
+	cfg.maxFailures = maxFailures
+	cfg.ipMaxFailures = ipMaxFailures
+
+	if window <= 0 {
+		return cfg, errors.New("lockout failures window must be provided")
+	}
+	cfg.window = window
+
+	if backoff < 0 {
+		return cfg, errors.New("lockout backoff can't be negative")
+	}
+	cfg.backoff = backoff
+
+	if lockDuration <= 0 {
+		return cfg, errors.New("lockout duration must be provided")
+	}
+	cfg.lockDuration = lockDuration
+
+	return cfg, nil
+}
+
+func (cfg LockoutConfig) MaxFailures() int {
+	return cfg.maxFailures
+}
+
+func (cfg LockoutConfig) IpMaxFailures() int {
+	return cfg.ipMaxFailures
+}
+
+func (cfg LockoutConfig) Window() time.Duration {
+	return cfg.window
+}
+
+func (cfg LockoutConfig) LockDuration() time.Duration {
+	return cfg.lockDuration
+}
+
+// Backoff returns delay before next attempt after given number of consecutive failures, it doubles with
+// every failure and never exceeds lock duration
+func (cfg LockoutConfig) Backoff(failures int) time.Duration {
+	if failures <= 0 || cfg.backoff == 0 {
+		return 0
+	}
+
+	delay := cfg.backoff
+	for i := 1; i < failures && delay < cfg.lockDuration; i++ {
+		delay *= 2
+	}
+
+	if delay > cfg.lockDuration {
+		return cfg.lockDuration
+	}
+	return delay
+}
@@ -0,0 +1,32 @@
+package valueobj
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLockoutConfigBackoff(t *testing.T) {
+	cfg, err := NewLockoutConfig(5, 50, 15*time.Minute, time.Second, 10*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Log("Given the need to test sign in backoff")
+	{
+		t.Logf("\tTest 1:\tWhen failures are accumulated")
+		{
+			expected := []time.Duration{0, time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second}
+			for failures, delay := range expected {
+				if got := cfg.Backoff(failures); got != delay {
+					t.Fatalf("\t%s\tBackoff after %d failures must be %s, but got %s", failed, failures, delay, got)
+				}
+			}
+			t.Logf("\t%s\tBackoff must double with every failure and never exceed lock duration", success)
+
+			if got := cfg.Backoff(1000); got != 10*time.Second {
+				t.Fatalf("\t%s\tBackoff mustn't overflow on many failures, but got %s", failed, got)
+			}
+			t.Logf("\t%s\tBackoff mustn't overflow on many failures", success)
+		}
+	}
+}
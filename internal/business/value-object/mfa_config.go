@@ -0,0 +1,47 @@
+package valueobj
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type MfaConfig struct {
+	challengeTtl    time.Duration
+	maxAttempts     int
+	recoveryCodeKey []byte
+}
+
+// minRecoveryCodeKeySize is size of SHA-256 output, shorter key weakens HMAC
+const minRecoveryCodeKeySize = 32
+
+// NewMfaConfig builds config of second sign in step, challenge is dropped after max failed attempts.
+// Recovery codes are hashed with HMAC keyed by recovery code key, so leaked hashes are useless without it.
+func NewMfaConfig(challengeTtl time.Duration, maxAttempts int, recoveryCodeKey []byte) (MfaConfig, error) {
+	var cfg MfaConfig
+
+	if challengeTtl <= 0 {
+		return cfg, errors.New("mfa challenge ttl must be provided")
+	}
+	cfg.challengeTtl = challengeTtl
+
+	if maxAttempts <= 0 {
+		return cfg, errors.New("mfa max attempts must be positive")
+	}
+	cfg.maxAttempts = maxAttempts
+
+	if len(recoveryCodeKey) < minRecoveryCodeKeySize {
+		return cfg, errors.Errorf("mfa recovery code key must be at least %d bytes", minRecoveryCodeKeySize)
+	}
+	cfg.recoveryCodeKey = recoveryCodeKey
+
+	return cfg, nil
+}
+
+func (cfg MfaConfig) ChallengeTimeToLive() time.Duration {
+	return cfg.challengeTtl
+}
+
+func (cfg MfaConfig) MaxAttempts() int {
+	return cfg.maxAttempts
+}
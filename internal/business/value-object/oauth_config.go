@@ -0,0 +1,38 @@
+package valueobj
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type OAuthConfig struct {
+	codeTtl    time.Duration
+	sessionTtl time.Duration
+}
+
+// NewOAuthConfig builds config, session ttl is how long user signed in on authorization page can authorize
+// clients without entering credentials again
+func NewOAuthConfig(codeTtl time.Duration, sessionTtl time.Duration) (OAuthConfig, error) {
+	var cfg OAuthConfig
+
+	if codeTtl <= 0 {
+		return cfg, errors.New("authorization code ttl must be provided")
+	}
+	cfg.codeTtl = codeTtl
+
+	if sessionTtl <= 0 {
+		return cfg, errors.New("sign in session ttl must be provided")
+	}
+	cfg.sessionTtl = sessionTtl
+
+	return cfg, nil
+}
+
+func (cfg OAuthConfig) CodeTimeToLive() time.Duration {
+	return cfg.codeTtl
+}
+
+func (cfg OAuthConfig) SessionTimeToLive() time.Duration {
+	return cfg.sessionTtl
+}
@@ -6,7 +6,6 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/umalmyha/authsrv/pkg/helpers"
-	"golang.org/x/crypto/bcrypt"
 )
 
 type Password struct {
@@ -40,7 +39,19 @@ func GeneratePassword(password string, cfg PasswordConfig) (Password, error) {
 		return p, errors.New("must contain at least one uppercase character")
 	}
 
-	hash, err := GenerateHash([]byte(password))
+	return HashPassword(password, cfg)
+}
+
+// HashPassword hashes password with configured hasher without applying password policy,
+// it is intended for already accepted passwords, e.g. on hash upgrade.
+func HashPassword(password string, cfg PasswordConfig) (Password, error) {
+	var p Password
+
+	if cfg.hasher == nil {
+		return p, errors.New("password hasher is not configured")
+	}
+
+	hash, err := cfg.hasher.Hash(password)
 	if err != nil {
 		return p, err
 	}
@@ -49,14 +60,6 @@ func GeneratePassword(password string, cfg PasswordConfig) (Password, error) {
 	return p, nil
 }
 
-func GenerateHash(password []byte) (string, error) {
-	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	if err != nil {
-		return "", err
-	}
-	return string(hash), nil
-}
-
 func PasswordFromHash(hash string) Password {
 	return Password{hash: hash}
 }
@@ -65,14 +68,30 @@ func (p Password) Hash() string {
 	return p.hash
 }
 
+func (p Password) Verify(password string) (bool, error) {
+	return VerifyPasswordHash(password, p.hash)
+}
+
+func (p Password) NeedsRehash(cfg PasswordConfig) bool {
+	if cfg.hasher == nil {
+		return false
+	}
+	return cfg.hasher.NeedsRehash(p.hash)
+}
+
+// dummyPassword is hashed once per config, its hash is verified for unknown users
+const dummyPassword = "authsrv-dummy-password"
+
 type PasswordConfig struct {
 	min          int
 	max          int
 	hasDigit     bool
 	hasUppercase bool
+	hasher       PasswordHasher
+	dummy        Password
 }
 
-func NewPasswordConfig(min int, max int, hasDigit bool, hasUppercase bool) (PasswordConfig, error) {
+func NewPasswordConfig(min int, max int, hasDigit bool, hasUppercase bool, hasher PasswordHasher) (PasswordConfig, error) {
 	var cfg PasswordConfig
 
 	if max < 0 || min < 0 {
@@ -83,14 +102,34 @@ func NewPasswordConfig(min int, max int, hasDigit bool, hasUppercase bool) (Pass
 		return cfg, errors.New("minimum length must be less than maximum length")
 	}
 
+	if hasher == nil {
+		return cfg, errors.New("password hasher must be provided")
+	}
+
+	dummyHash, err := hasher.Hash(dummyPassword)
+	if err != nil {
+		return cfg, errors.Wrap(err, "failed to hash dummy password")
+	}
+
 	return PasswordConfig{
 		min:          min,
 		max:          max,
 		hasDigit:     hasDigit,
 		hasUppercase: hasUppercase,
+		hasher:       hasher,
+		dummy:        PasswordFromHash(dummyHash),
 	}, nil
 }
 
+// VerifyDummy verifies password against hash which matches no user, it takes as long as verification of real
+// password hashed with configured hasher, so response time doesn't reveal whether user exists
+func (cfg PasswordConfig) VerifyDummy(password string) {
+	if cfg.dummy.Hash() == "" {
+		return
+	}
+	_, _ = cfg.dummy.Verify(password)
+}
+
 func (cfg PasswordConfig) Min() int {
 	return cfg.min
 }
@@ -106,3 +145,7 @@ func (cfg PasswordConfig) HasDigit() bool {
 func (cfg PasswordConfig) HasUppercase() bool {
 	return cfg.hasUppercase
 }
+
+func (cfg PasswordConfig) Hasher() PasswordHasher {
+	return cfg.hasher
+}
@@ -0,0 +1,170 @@
+package valueobj
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	HashAlgorithmBcrypt   = "bcrypt"
+	HashAlgorithmArgon2id = "argon2id"
+	HashAlgorithmScrypt   = "scrypt"
+)
+
+// PasswordHasher produces self-describing password hashes, so verification
+// never depends on the currently configured parameters.
+type PasswordHasher interface {
+	Algorithm() string
+	Hash(password string) (string, error)
+	Verify(password string, encoded string) (bool, error)
+	NeedsRehash(encoded string) bool
+}
+
+func NewPasswordHasher(alg string) (PasswordHasher, error) {
+	switch alg {
+	case HashAlgorithmBcrypt:
+		return NewBcryptHasher(defaultBcryptCost)
+	case HashAlgorithmArgon2id:
+		return NewArgon2idHasher(defaultArgon2Memory, defaultArgon2Iterations, defaultArgon2Parallelism)
+	case HashAlgorithmScrypt:
+		return NewScryptHasher(defaultScryptCostLog2, defaultScryptBlockSize, defaultScryptParallelism)
+	default:
+		return nil, errors.Errorf("unsupported password hash algorithm %s", alg)
+	}
+}
+
+func HashAlgorithmOf(encoded string) (string, error) {
+	switch {
+	case strings.HasPrefix(encoded, "$2a$"), strings.HasPrefix(encoded, "$2b$"), strings.HasPrefix(encoded, "$2y$"):
+		return HashAlgorithmBcrypt, nil
+	case strings.HasPrefix(encoded, "$argon2id$"):
+		return HashAlgorithmArgon2id, nil
+	case strings.HasPrefix(encoded, "$scrypt$"):
+		return HashAlgorithmScrypt, nil
+	default:
+		return "", errors.New("unknown password hash format")
+	}
+}
+
+// VerifyPasswordHash checks password against a hash produced by any of the
+// supported hashers, the algorithm and its parameters are taken from the hash.
+func VerifyPasswordHash(password string, encoded string) (bool, error) {
+	alg, err := HashAlgorithmOf(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	hasher, err := NewPasswordHasher(alg)
+	if err != nil {
+		return false, err
+	}
+
+	return hasher.Verify(password, encoded)
+}
+
+// phcHash is a parsed hash in PHC string format: $<id>[$v=<version>][$<param>=<value>(,<param>=<value>)*][$<salt>[$<hash>]]
+type phcHash struct {
+	algorithm string
+	version   string
+	params    []phcParam
+	salt      []byte
+	key       []byte
+}
+
+type phcParam struct {
+	name  string
+	value string
+}
+
+func (h phcHash) String() string {
+	var sb strings.Builder
+	sb.WriteString("$")
+	sb.WriteString(h.algorithm)
+
+	if h.version != "" {
+		sb.WriteString(fmt.Sprintf("$v=%s", h.version))
+	}
+
+	sb.WriteString("$")
+	sb.WriteString(encodePhcParams(h.params))
+	sb.WriteString("$")
+	sb.WriteString(base64.RawStdEncoding.EncodeToString(h.salt))
+	sb.WriteString("$")
+	sb.WriteString(base64.RawStdEncoding.EncodeToString(h.key))
+
+	return sb.String()
+}
+
+func (h phcHash) intParam(name string) (int, error) {
+	for _, p := range h.params {
+		if p.name == name {
+			n, err := strconv.Atoi(p.value)
+			if err != nil {
+				return 0, errors.Wrapf(err, "parameter %s of %s hash is not a number", name, h.algorithm)
+			}
+			return n, nil
+		}
+	}
+	return 0, errors.Errorf("parameter %s is missing in %s hash", name, h.algorithm)
+}
+
+func decodePhcHash(encoded string) (phcHash, error) {
+	var h phcHash
+
+	parts := strings.Split(encoded, "$")
+	if len(parts) < 5 || parts[0] != "" {
+		return h, errors.New("hash doesn't have PHC string format")
+	}
+
+	h.algorithm = parts[1]
+	rest := parts[2:]
+
+	if strings.HasPrefix(rest[0], "v=") {
+		h.version = strings.TrimPrefix(rest[0], "v=")
+		rest = rest[1:]
+	}
+
+	if len(rest) != 3 {
+		return h, errors.New("hash doesn't have PHC string format")
+	}
+
+	params, err := decodePhcParams(rest[0])
+	if err != nil {
+		return h, err
+	}
+	h.params = params
+
+	if h.salt, err = base64.RawStdEncoding.DecodeString(rest[1]); err != nil {
+		return h, errors.Wrap(err, "failed to decode hash salt")
+	}
+
+	if h.key, err = base64.RawStdEncoding.DecodeString(rest[2]); err != nil {
+		return h, errors.Wrap(err, "failed to decode hash value")
+	}
+
+	return h, nil
+}
+
+func encodePhcParams(params []phcParam) string {
+	pairs := make([]string, 0, len(params))
+	for _, p := range params {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", p.name, p.value))
+	}
+	return strings.Join(pairs, ",")
+}
+
+func decodePhcParams(s string) ([]phcParam, error) {
+	params := make([]phcParam, 0)
+	for _, pair := range strings.Split(s, ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, errors.Errorf("malformed hash parameter %s", pair)
+		}
+		params = append(params, phcParam{name: kv[0], value: kv[1]})
+	}
+	return params, nil
+}
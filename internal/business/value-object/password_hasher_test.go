@@ -0,0 +1,155 @@
+package valueobj
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	success = "\u2713"
+	failed  = "\u2717"
+)
+
+func testHashers(fatalFn func(error)) []PasswordHasher {
+	bcryptHasher, err := NewBcryptHasher(bcrypt.MinCost)
+	if err != nil {
+		fatalFn(err)
+	}
+
+	argon2Hasher, err := NewArgon2idHasher(1024, 1, 1)
+	if err != nil {
+		fatalFn(err)
+	}
+
+	scryptHasher, err := NewScryptHasher(4, 8, 1)
+	if err != nil {
+		fatalFn(err)
+	}
+
+	return []PasswordHasher{bcryptHasher, argon2Hasher, scryptHasher}
+}
+
+func TestPasswordHashers(t *testing.T) {
+	hashers := testHashers(func(err error) { t.Fatal(err) })
+
+	t.Log("Given the need to test password hashers")
+	{
+		for i, hasher := range hashers {
+			testId := i + 1
+			t.Logf("\tTest %d:\tWhen password is hashed with %s", testId, hasher.Algorithm())
+			{
+				hash, err := hasher.Hash("Secret123")
+				if err != nil {
+					t.Fatalf("\t%s\tUnexpected error occurred on hashing: %v", failed, err)
+				}
+
+				alg, err := HashAlgorithmOf(hash)
+				if err != nil || alg != hasher.Algorithm() {
+					t.Fatalf("\t%s\tHash %s must be recognized as %s", failed, hash, hasher.Algorithm())
+				}
+				t.Logf("\t%s\tHash must be self-describing", success)
+
+				verified, err := VerifyPasswordHash("Secret123", hash)
+				if err != nil {
+					t.Fatalf("\t%s\tUnexpected error occurred on verification: %v", failed, err)
+				}
+
+				if !verified {
+					t.Fatalf("\t%s\tCorrect password wasn't verified", failed)
+				}
+				t.Logf("\t%s\tCorrect password must be verified", success)
+
+				verified, err = VerifyPasswordHash("Secret124", hash)
+				if err != nil {
+					t.Fatalf("\t%s\tUnexpected error occurred on verification: %v", failed, err)
+				}
+
+				if verified {
+					t.Fatalf("\t%s\tWrong password was verified", failed)
+				}
+				t.Logf("\t%s\tWrong password mustn't be verified", success)
+
+				if hasher.NeedsRehash(hash) {
+					t.Fatalf("\t%s\tHash produced with current parameters requires rehash", failed)
+				}
+				t.Logf("\t%s\tHash produced with current parameters mustn't require rehash", success)
+			}
+		}
+	}
+}
+
+func TestPasswordHashersNeedsRehash(t *testing.T) {
+	hashers := testHashers(func(err error) { t.Fatal(err) })
+	bcryptHasher, argon2Hasher, scryptHasher := hashers[0], hashers[1], hashers[2]
+
+	t.Log("Given the need to test password hash upgrade detection")
+	{
+		testId := 1
+		t.Logf("\tTest %d:\tWhen hash is produced by other algorithm", testId)
+		{
+			hash, err := bcryptHasher.Hash("Secret123")
+			if err != nil {
+				t.Fatalf("\t%s\tUnexpected error occurred on hashing: %v", failed, err)
+			}
+
+			if !argon2Hasher.NeedsRehash(hash) || !scryptHasher.NeedsRehash(hash) {
+				t.Fatalf("\t%s\tHash of other algorithm doesn't require rehash", failed)
+			}
+			t.Logf("\t%s\tHash of other algorithm must require rehash", success)
+		}
+
+		testId++
+		t.Logf("\tTest %d:\tWhen hash is produced with outdated parameters", testId)
+		{
+			hash, err := argon2Hasher.Hash("Secret123")
+			if err != nil {
+				t.Fatalf("\t%s\tUnexpected error occurred on hashing: %v", failed, err)
+			}
+
+			stronger, err := NewArgon2idHasher(2048, 2, 1)
+			if err != nil {
+				t.Fatalf("\t%s\tUnexpected error occurred on hasher creation: %v", failed, err)
+			}
+
+			if !stronger.NeedsRehash(hash) {
+				t.Fatalf("\t%s\tHash with outdated parameters doesn't require rehash", failed)
+			}
+			t.Logf("\t%s\tHash with outdated parameters must require rehash", success)
+		}
+
+		testId++
+		t.Logf("\tTest %d:\tWhen hash is upgraded via password", testId)
+		{
+			cfg, err := NewPasswordConfig(0, 0, false, false, argon2Hasher)
+			if err != nil {
+				t.Fatalf("\t%s\tUnexpected error occurred on config creation: %v", failed, err)
+			}
+
+			hash, err := bcryptHasher.Hash("Secret123")
+			if err != nil {
+				t.Fatalf("\t%s\tUnexpected error occurred on hashing: %v", failed, err)
+			}
+
+			password := PasswordFromHash(hash)
+			if !password.NeedsRehash(cfg) {
+				t.Fatalf("\t%s\tLegacy bcrypt password doesn't require rehash", failed)
+			}
+
+			upgraded, err := HashPassword("Secret123", cfg)
+			if err != nil {
+				t.Fatalf("\t%s\tUnexpected error occurred on rehash: %v", failed, err)
+			}
+
+			if !strings.HasPrefix(upgraded.Hash(), "$argon2id$v=19$m=1024,t=1,p=1$") {
+				t.Fatalf("\t%s\tUpgraded hash has unexpected format %s", failed, upgraded.Hash())
+			}
+
+			if verified, err := upgraded.Verify("Secret123"); err != nil || !verified {
+				t.Fatalf("\t%s\tUpgraded hash doesn't verify original password", failed)
+			}
+			t.Logf("\t%s\tUpgraded hash must be produced by configured hasher and verify original password", success)
+		}
+	}
+}
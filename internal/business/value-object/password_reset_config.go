@@ -0,0 +1,70 @@
+package valueobj
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type PasswordResetConfig struct {
+	tokenTtl time.Duration
+	resetUrl *url.URL
+}
+
+// NewPasswordResetConfig builds config of password reset, reset url is page of client application
+// which receives token in query, token is sent as is if url is not provided
+func NewPasswordResetConfig(tokenTtl time.Duration, resetUrl string) (PasswordResetConfig, error) {
+	var cfg PasswordResetConfig
+
+	if tokenTtl <= 0 {
+		return cfg, errors.New("password reset token ttl must be provided")
+	}
+	cfg.tokenTtl = tokenTtl
+
+	u, err := parseLinkUrl(resetUrl)
+	if err != nil {
+		return cfg, errors.Wrap(err, "invalid password reset url")
+	}
+	cfg.resetUrl = u
+
+	return cfg, nil
+}
+
+func (cfg PasswordResetConfig) TokenTimeToLive() time.Duration {
+	return cfg.tokenTtl
+}
+
+// ResetLink returns link with token in query, empty string is returned if reset url is not configured
+func (cfg PasswordResetConfig) ResetLink(token string) string {
+	if cfg.resetUrl == nil {
+		return ""
+	}
+	return linkWithToken(cfg.resetUrl, token)
+}
+
+func linkWithToken(base *url.URL, token string) string {
+	link := *base
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+
+	return link.String()
+}
+
+// parseLinkUrl parses url of client application page, links are sent by email, so url must be absolute
+func parseLinkUrl(raw string) (*url.URL, error) {
+	if raw == "" {
+		return nil, nil
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	if !u.IsAbs() {
+		return nil, errors.New("url must be absolute")
+	}
+	return u, nil
+}
@@ -0,0 +1,37 @@
+package valueobj
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPasswordResetConfigLink(t *testing.T) {
+	t.Log("Given the need to test password reset link")
+	{
+		t.Logf("\tTest 1:\tWhen reset url has its own query")
+		{
+			cfg, err := NewPasswordResetConfig(time.Hour, "https://app.example.com/reset?lang=en")
+			if err != nil {
+				t.Fatalf("\t%s\tShould be able to build config: %v", failed, err)
+			}
+
+			if link := cfg.ResetLink("a+b/c"); link != "https://app.example.com/reset?lang=en&token=a%2Bb%2Fc" {
+				t.Fatalf("\t%s\tToken must be added to query and escaped, got %s", failed, link)
+			}
+			t.Logf("\t%s\tToken must be added to existing query", success)
+		}
+
+		t.Logf("\tTest 2:\tWhen reset url is not configured or relative")
+		{
+			cfg, _ := NewPasswordResetConfig(time.Hour, "")
+			if link := cfg.ResetLink("token"); link != "" {
+				t.Fatalf("\t%s\tLink must be empty without reset url, got %s", failed, link)
+			}
+
+			if _, err := NewPasswordResetConfig(time.Hour, "/reset"); err == nil {
+				t.Fatalf("\t%s\tRelative reset url must be rejected", failed)
+			}
+			t.Logf("\t%s\tLink must be built for absolute url only", success)
+		}
+	}
+}
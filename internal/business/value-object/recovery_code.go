@@ -0,0 +1,80 @@
+package valueobj
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+const (
+	RecoveryCodesCount = 10
+	recoveryCodeSize   = 10
+)
+
+var recoveryCodeEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// RecoveryCode is one-time code which replaces second factor if it is lost, only its keyed hash is kept.
+// Code is random enough to resist brute force, so slow password hashing would only make every guess expensive.
+type RecoveryCode struct {
+	id   string
+	hash string
+}
+
+// GenerateRecoveryCodes returns plain codes to be shown to user once and their hashed counterparts
+func GenerateRecoveryCodes(count int, cfg MfaConfig) ([]string, []RecoveryCode, error) {
+	plain := make([]string, 0, count)
+	codes := make([]RecoveryCode, 0, count)
+
+	for i := 0; i < count; i++ {
+		raw := make([]byte, recoveryCodeSize)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, nil, errors.Wrap(err, "failed to generate recovery code")
+		}
+
+		code := recoveryCodeEncoding.EncodeToString(raw)[:recoveryCodeSize]
+		hash := hex.EncodeToString(recoveryCodeMac(code, cfg))
+
+		plain = append(plain, code[:recoveryCodeSize/2]+"-"+code[recoveryCodeSize/2:])
+		codes = append(codes, RecoveryCode{id: uuid.NewString(), hash: hash})
+	}
+
+	return plain, codes, nil
+}
+
+func RecoveryCodeFromHash(id string, hash string) RecoveryCode {
+	return RecoveryCode{id: id, hash: hash}
+}
+
+func (c RecoveryCode) Id() string {
+	return c.id
+}
+
+func (c RecoveryCode) Hash() string {
+	return c.hash
+}
+
+// Verify compares code ignoring case, spaces and dashes, so code can be typed the way it was displayed
+func (c RecoveryCode) Verify(code string, cfg MfaConfig) bool {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	if len(normalized) != recoveryCodeSize {
+		return false
+	}
+
+	expected, err := hex.DecodeString(c.hash)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal(recoveryCodeMac(normalized, cfg), expected)
+}
+
+func recoveryCodeMac(code string, cfg MfaConfig) []byte {
+	mac := hmac.New(sha256.New, cfg.recoveryCodeKey)
+	mac.Write([]byte(code))
+	return mac.Sum(nil)
+}
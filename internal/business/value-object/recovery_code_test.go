@@ -0,0 +1,56 @@
+package valueobj
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecoveryCodeVerify(t *testing.T) {
+	t.Log("Given the need to test recovery codes verification")
+	{
+		cfg, err := NewMfaConfig(time.Minute, 5, bytes.Repeat([]byte{1}, minRecoveryCodeKeySize))
+		if err != nil {
+			t.Fatalf("\t%s\tShould be able to build config: %v", failed, err)
+		}
+
+		plain, codes, err := GenerateRecoveryCodes(2, cfg)
+		if err != nil {
+			t.Fatalf("\t%s\tShould be able to generate recovery codes: %v", failed, err)
+		}
+
+		t.Logf("\tTest 1:\tWhen code is typed the way it was displayed or without dash in upper case")
+		{
+			if !codes[0].Verify(plain[0], cfg) {
+				t.Fatalf("\t%s\tDisplayed code must be accepted", failed)
+			}
+
+			if !codes[0].Verify(strings.ToUpper(strings.ReplaceAll(plain[0], "-", " ")), cfg) {
+				t.Fatalf("\t%s\tCode must be accepted regardless of case, spaces and dashes", failed)
+			}
+			t.Logf("\t%s\tCode must be accepted", success)
+		}
+
+		t.Logf("\tTest 2:\tWhen code belongs to another code or key is different")
+		{
+			if codes[1].Verify(plain[0], cfg) {
+				t.Fatalf("\t%s\tCode mustn't match hash of another code", failed)
+			}
+
+			otherCfg, _ := NewMfaConfig(time.Minute, 5, bytes.Repeat([]byte{2}, minRecoveryCodeKeySize))
+			if codes[0].Verify(plain[0], otherCfg) {
+				t.Fatalf("\t%s\tCode mustn't match hash made with another key", failed)
+			}
+			t.Logf("\t%s\tCode must be rejected", success)
+		}
+
+		t.Logf("\tTest 3:\tWhen recovery code key is too short")
+		{
+			if _, err := NewMfaConfig(time.Minute, 5, []byte("short")); err == nil {
+				t.Fatalf("\t%s\tShort key must be rejected", failed)
+			}
+			t.Logf("\t%s\tShort key must be rejected", success)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package valueobj
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	defaultScryptCostLog2    = 15
+	defaultScryptBlockSize   = 8
+	defaultScryptParallelism = 1
+	scryptSaltLength         = 16
+	scryptKeyLength          = 32
+)
+
+type ScryptHasher struct {
+	costLog2    int
+	blockSize   int
+	parallelism int
+}
+
+func NewScryptHasher(costLog2 int, blockSize int, parallelism int) (ScryptHasher, error) {
+	var h ScryptHasher
+
+	if costLog2 <= 0 || costLog2 >= 32 {
+		return h, errors.New("scrypt cost (log2 N) must be between 1 and 31")
+	}
+	h.costLog2 = costLog2
+
+	if blockSize <= 0 {
+		return h, errors.New("scrypt block size must be positive number")
+	}
+	h.blockSize = blockSize
+
+	if parallelism <= 0 {
+		return h, errors.New("scrypt parallelism must be positive number")
+	}
+	h.parallelism = parallelism
+
+	return h, nil
+}
+
+func (h ScryptHasher) Algorithm() string {
+	return HashAlgorithmScrypt
+}
+
+func (h ScryptHasher) Hash(password string) (string, error) {
+	salt := make([]byte, scryptSaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", errors.Wrap(err, "failed to generate salt")
+	}
+
+	key, err := scrypt.Key([]byte(password), salt, 1<<h.costLog2, h.blockSize, h.parallelism, scryptKeyLength)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to generate scrypt hash")
+	}
+
+	phc := phcHash{
+		algorithm: HashAlgorithmScrypt,
+		params: []phcParam{
+			{name: "ln", value: strconv.Itoa(h.costLog2)},
+			{name: "r", value: strconv.Itoa(h.blockSize)},
+			{name: "p", value: strconv.Itoa(h.parallelism)},
+		},
+		salt: salt,
+		key:  key,
+	}
+	return phc.String(), nil
+}
+
+func (h ScryptHasher) Verify(password string, encoded string) (bool, error) {
+	phc, costLog2, blockSize, parallelism, err := h.decode(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	key, err := scrypt.Key([]byte(password), phc.salt, 1<<costLog2, blockSize, parallelism, len(phc.key))
+	if err != nil {
+		return false, errors.Wrap(err, "failed to generate scrypt hash")
+	}
+	return subtle.ConstantTimeCompare(key, phc.key) == 1, nil
+}
+
+func (h ScryptHasher) NeedsRehash(encoded string) bool {
+	phc, costLog2, blockSize, parallelism, err := h.decode(encoded)
+	if err != nil {
+		return true
+	}
+
+	return costLog2 != h.costLog2 ||
+		blockSize != h.blockSize ||
+		parallelism != h.parallelism ||
+		len(phc.salt) != scryptSaltLength ||
+		len(phc.key) != scryptKeyLength
+}
+
+func (h ScryptHasher) decode(encoded string) (phcHash, int, int, int, error) {
+	phc, err := decodePhcHash(encoded)
+	if err != nil {
+		return phc, 0, 0, 0, err
+	}
+
+	if phc.algorithm != HashAlgorithmScrypt {
+		return phc, 0, 0, 0, errors.Errorf("expected scrypt hash, got %s", phc.algorithm)
+	}
+
+	costLog2, err := phc.intParam("ln")
+	if err != nil {
+		return phc, 0, 0, 0, err
+	}
+
+	blockSize, err := phc.intParam("r")
+	if err != nil {
+		return phc, 0, 0, 0, err
+	}
+
+	parallelism, err := phc.intParam("p")
+	if err != nil {
+		return phc, 0, 0, 0, err
+	}
+
+	if costLog2 <= 0 || costLog2 >= 32 || blockSize <= 0 || parallelism <= 0 {
+		return phc, 0, 0, 0, errors.New("scrypt hash has invalid parameters")
+	}
+
+	if len(phc.key) == 0 {
+		return phc, 0, 0, 0, errors.New("scrypt hash value is empty")
+	}
+
+	return phc, costLog2, blockSize, parallelism, nil
+}
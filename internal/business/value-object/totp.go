@@ -0,0 +1,119 @@
+package valueobj
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	totpSecretSize = 20
+	totpDigits     = 6
+	totpPeriod     = 30
+	// codes of adjacent time steps are accepted to tolerate clock drift (RFC 6238 5.2)
+	totpSkew = 1
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// TotpSecret is shared key of RFC 6238 time-based one-time passwords (HMAC-SHA1, 6 digits, 30 seconds step)
+type TotpSecret struct {
+	key []byte
+}
+
+func GenerateTotpSecret() (TotpSecret, error) {
+	key := make([]byte, totpSecretSize)
+	if _, err := rand.Read(key); err != nil {
+		return TotpSecret{}, errors.Wrap(err, "failed to generate totp secret")
+	}
+	return TotpSecret{key: key}, nil
+}
+
+// TotpSecretFromString decodes base32 secret, empty string means no secret
+func TotpSecretFromString(secret string) (TotpSecret, error) {
+	if secret == "" {
+		return TotpSecret{}, nil
+	}
+
+	key, err := totpEncoding.DecodeString(strings.ToUpper(strings.TrimRight(secret, "=")))
+	if err != nil {
+		return TotpSecret{}, errors.Wrap(err, "failed to decode totp secret")
+	}
+	return TotpSecret{key: key}, nil
+}
+
+func (s TotpSecret) IsZero() bool {
+	return len(s.key) == 0
+}
+
+// String returns secret in base32 without padding, as authenticator apps expect it
+func (s TotpSecret) String() string {
+	return totpEncoding.EncodeToString(s.key)
+}
+
+// Uri builds otpauth URI for enrollment in authenticator app (usually rendered as QR code)
+func (s TotpSecret) Uri(issuer string, account string) string {
+	params := url.Values{}
+	params.Set("secret", s.String())
+	params.Set("issuer", issuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprint(totpDigits))
+	params.Set("period", fmt.Sprint(totpPeriod))
+
+	label := url.PathEscape(issuer + ":" + account)
+	return fmt.Sprintf("otpauth://totp/%s?%s", label, params.Encode())
+}
+
+// Code returns one-time password valid at the moment
+func (s TotpSecret) Code(at time.Time) string {
+	return s.codeForStep(totpStep(at))
+}
+
+// Verify checks code against time steps around the moment and returns matched step,
+// caller must reject steps which were already used to prevent code replay
+func (s TotpSecret) Verify(code string, at time.Time) (int64, bool) {
+	if s.IsZero() || len(code) != totpDigits {
+		return 0, false
+	}
+
+	step := totpStep(at)
+	for i := -totpSkew; i <= totpSkew; i++ {
+		candidate := step + int64(i)
+		if subtle.ConstantTimeCompare([]byte(s.codeForStep(candidate)), []byte(code)) == 1 {
+			return candidate, true
+		}
+	}
+	return 0, false
+}
+
+// codeForStep implements HOTP dynamic truncation (RFC 4226 5.3)
+func (s TotpSecret) codeForStep(step int64) string {
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, uint64(step))
+
+	mac := hmac.New(sha1.New, s.key)
+	mac.Write(msg)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	binCode := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < totpDigits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", totpDigits, binCode%mod)
+}
+
+func totpStep(at time.Time) int64 {
+	return at.Unix() / totpPeriod
+}
@@ -0,0 +1,69 @@
+package valueobj
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// RFC 6238 appendix B SHA1 test vectors truncated to 6 digits
+var rfcTotpVectors = []struct {
+	unix int64
+	code string
+}{
+	{59, "287082"},
+	{1111111109, "081804"},
+	{1111111111, "050471"},
+	{1234567890, "005924"},
+	{2000000000, "279037"},
+	{20000000000, "353130"},
+}
+
+func TestTotp(t *testing.T) {
+	secret := TotpSecret{key: []byte("12345678901234567890")}
+
+	t.Log("Given the need to test TOTP generation and verification")
+	{
+		t.Logf("\tTest 1:\tWhen codes are generated for RFC 6238 test vectors")
+		{
+			for _, v := range rfcTotpVectors {
+				if code := secret.Code(time.Unix(v.unix, 0)); code != v.code {
+					t.Fatalf("\t%s\tCode at %d must be %s, but got %s", failed, v.unix, v.code, code)
+				}
+			}
+			t.Logf("\t%s\tCodes must match RFC 6238 test vectors", success)
+		}
+
+		t.Logf("\tTest 2:\tWhen code of adjacent and distant time steps is verified")
+		{
+			now := time.Unix(1111111111, 0)
+
+			if step, ok := secret.Verify(secret.Code(now.Add(-30*time.Second)), now); !ok || step != totpStep(now)-1 {
+				t.Fatalf("\t%s\tCode of previous step must be accepted", failed)
+			}
+
+			if _, ok := secret.Verify(secret.Code(now.Add(-90*time.Second)), now); ok {
+				t.Fatalf("\t%s\tCode outside of allowed skew must be rejected", failed)
+			}
+			t.Logf("\t%s\tOnly codes within allowed skew must be accepted", success)
+		}
+
+		t.Logf("\tTest 3:\tWhen secret is encoded to string and otpauth uri")
+		{
+			decoded, err := TotpSecretFromString(secret.String())
+			if err != nil {
+				t.Fatalf("\t%s\tUnexpected error occurred: %v", failed, err)
+			}
+
+			if decoded.String() != "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ" {
+				t.Fatalf("\t%s\tSecret must be encoded in base32 without padding, but got %s", failed, decoded.String())
+			}
+
+			uri := secret.Uri("authsrv", "john")
+			if !strings.HasPrefix(uri, "otpauth://totp/authsrv:john?") || !strings.Contains(uri, "secret="+secret.String()) {
+				t.Fatalf("\t%s\tUnexpected otpauth uri %s", failed, uri)
+			}
+			t.Logf("\t%s\tSecret must be encoded for authenticator apps", success)
+		}
+	}
+}
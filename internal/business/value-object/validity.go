@@ -0,0 +1,59 @@
+package valueobj
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var InvalidValidityErr = errors.New("validity window is invalid")
+
+// Validity is time window assignment is active within, missing bound means window is open from that side
+type Validity struct {
+	from  *time.Time
+	until *time.Time
+}
+
+func NewValidity(from *time.Time, until *time.Time) (Validity, error) {
+	if from != nil && until != nil && !until.After(*from) {
+		return Validity{}, errors.Wrap(InvalidValidityErr, "valid until must be after valid from")
+	}
+
+	return Validity{
+		from:  copyTime(from),
+		until: copyTime(until),
+	}, nil
+}
+
+// NewUnboundedValidity builds window which is always active
+func NewUnboundedValidity() Validity {
+	return Validity{}
+}
+
+func (v Validity) From() *time.Time {
+	return copyTime(v.from)
+}
+
+func (v Validity) Until() *time.Time {
+	return copyTime(v.until)
+}
+
+// IsActive reports whether moment is within window, lower bound is inclusive and upper bound is exclusive
+func (v Validity) IsActive(at time.Time) bool {
+	if v.from != nil && at.Before(*v.from) {
+		return false
+	}
+	return v.until == nil || at.Before(*v.until)
+}
+
+func (v Validity) IsExpired(at time.Time) bool {
+	return v.until != nil && !at.Before(*v.until)
+}
+
+func copyTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	copied := t.UTC()
+	return &copied
+}
@@ -0,0 +1,51 @@
+package valueobj
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestValidity(t *testing.T) {
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	until := from.Add(24 * time.Hour)
+
+	t.Log("Given the need to test validity window of assignment")
+	{
+		t.Logf("\tTest 1:\tWhen window is bounded from both sides")
+		{
+			v, err := NewValidity(&from, &until)
+			if err != nil {
+				t.Fatalf("\t%s\tUnexpected error on window creation: %v", failed, err)
+			}
+
+			if v.IsActive(from.Add(-time.Second)) || !v.IsActive(from) || !v.IsActive(until.Add(-time.Second)) || v.IsActive(until) {
+				t.Fatalf("\t%s\tWindow must be active from lower bound inclusive to upper bound exclusive", failed)
+			}
+			t.Logf("\t%s\tWindow must be active from lower bound inclusive to upper bound exclusive", success)
+
+			if v.IsExpired(from) || !v.IsExpired(until) {
+				t.Fatalf("\t%s\tWindow must expire on upper bound", failed)
+			}
+			t.Logf("\t%s\tWindow must expire on upper bound", success)
+		}
+
+		t.Logf("\tTest 2:\tWhen window is unbounded")
+		{
+			v := NewUnboundedValidity()
+			if !v.IsActive(from) || v.IsExpired(until) {
+				t.Fatalf("\t%s\tUnbounded window must be always active", failed)
+			}
+			t.Logf("\t%s\tUnbounded window must be always active", success)
+		}
+
+		t.Logf("\tTest 3:\tWhen upper bound isn't after lower bound")
+		{
+			if _, err := NewValidity(&until, &from); !errors.Is(err, InvalidValidityErr) {
+				t.Fatalf("\t%s\tWindow must be rejected, got %v", failed, err)
+			}
+			t.Logf("\t%s\tWindow must be rejected", success)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package valueobj
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/exp/slices"
+)
+
+type WebauthnConfig struct {
+	rpId    string
+	rpName  string
+	origins []string
+	timeout time.Duration
+}
+
+// NewWebauthnConfig builds relying party config, empty rp id disables passkeys
+func NewWebauthnConfig(rpId string, rpName string, origins []string, timeout time.Duration) (WebauthnConfig, error) {
+	var cfg WebauthnConfig
+
+	if rpId == "" {
+		return cfg, nil
+	}
+	cfg.rpId = rpId
+
+	if len(origins) == 0 {
+		return cfg, errors.New("at least one webauthn origin must be provided")
+	}
+	cfg.origins = origins
+
+	if timeout <= 0 {
+		return cfg, errors.New("webauthn ceremony timeout must be provided")
+	}
+	cfg.timeout = timeout
+
+	cfg.rpName = rpName
+	if cfg.rpName == "" {
+		cfg.rpName = rpId
+	}
+
+	return cfg, nil
+}
+
+func (cfg WebauthnConfig) Enabled() bool {
+	return cfg.rpId != ""
+}
+
+func (cfg WebauthnConfig) RpId() string {
+	return cfg.rpId
+}
+
+func (cfg WebauthnConfig) RpName() string {
+	return cfg.rpName
+}
+
+func (cfg WebauthnConfig) AllowsOrigin(origin string) bool {
+	return slices.Contains(cfg.origins, origin)
+}
+
+func (cfg WebauthnConfig) Timeout() time.Duration {
+	return cfg.timeout
+}
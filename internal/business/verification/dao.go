@@ -0,0 +1,84 @@
+package verification
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+	dbredis "github.com/umalmyha/authsrv/pkg/database/redis"
+)
+
+const (
+	tokenKeyPrefix     = "email_verification:"
+	userTokenKeyPrefix = "email_verification_user:"
+)
+
+type TokenDao struct {
+	*dbredis.Store
+}
+
+func NewTokenDao(rdb *redis.Client) *TokenDao {
+	return &TokenDao{
+		Store: dbredis.NewStore(rdb),
+	}
+}
+
+// Save stores token and drops token issued to the same user before, so only link from the latest email is valid
+func (dao *TokenDao) Save(ctx context.Context, token string, dto TokenDto) error {
+	encoded, err := dbredis.EncodeGob(dto)
+	if err != nil {
+		return errors.Wrap(err, "failed to serialize email verification token to gob format")
+	}
+
+	ttl := time.Until(dto.ExpiresAt)
+	if ttl <= 0 {
+		return errors.New("email verification token is already expired")
+	}
+
+	hash := hashToken(token)
+
+	previous, err := dao.Client().GetSet(ctx, userTokenKeyPrefix+dto.UserId, hash).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return errors.Wrap(err, "failed to replace email verification token")
+	}
+
+	_, err = dao.Client().TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, tokenKeyPrefix+hash, encoded, ttl)
+		pipe.PExpire(ctx, userTokenKeyPrefix+dto.UserId, ttl)
+		if previous != "" {
+			pipe.Del(ctx, tokenKeyPrefix+previous)
+		}
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to save email verification token")
+	}
+	return nil
+}
+
+// Consume reads token and removes it atomically, so token can be used only once
+func (dao *TokenDao) Consume(ctx context.Context, token string) (TokenDto, error) {
+	var dto TokenDto
+
+	encoded, err := dao.Client().GetDel(ctx, tokenKeyPrefix+hashToken(token)).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return dto, nil
+		}
+		return dto, errors.Wrap(err, "failed to read email verification token")
+	}
+
+	if err := dbredis.DecodeGob([]byte(encoded), &dto); err != nil {
+		return dto, errors.Wrap(err, "failed to deserialize email verification token from gob format")
+	}
+	return dto, nil
+}
+
+// tokens are stored by hash, so plain values never reach the storage
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
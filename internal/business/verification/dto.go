@@ -0,0 +1,21 @@
+package verification
+
+import "time"
+
+type TokenDto struct {
+	UserId    string
+	Email     string
+	ExpiresAt time.Time
+}
+
+func (dto TokenDto) IsPresent() bool {
+	return dto.UserId != ""
+}
+
+func (dto TokenDto) ToToken() *Token {
+	return &Token{
+		userId:    dto.UserId,
+		email:     dto.Email,
+		expiresAt: dto.ExpiresAt,
+	}
+}
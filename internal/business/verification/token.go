@@ -0,0 +1,68 @@
+package verification
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"time"
+
+	"github.com/pkg/errors"
+
+	valueobj "github.com/umalmyha/authsrv/internal/business/value-object"
+)
+
+const tokenLength = 32
+
+// Token confirms that user owns email, it is bound to address it was sent to, so it is useless once email is changed
+type Token struct {
+	token     string
+	userId    string
+	email     string
+	expiresAt time.Time
+}
+
+func NewToken(userId string, email string, issuedAt time.Time, cfg valueobj.EmailVerificationConfig) (*Token, error) {
+	if userId == "" || email == "" {
+		return nil, errors.New("user and email are mandatory for email verification token")
+	}
+
+	token := make([]byte, tokenLength)
+	if _, err := rand.Read(token); err != nil {
+		return nil, errors.Wrap(err, "failed to generate email verification token")
+	}
+
+	return &Token{
+		token:     base64.RawURLEncoding.EncodeToString(token),
+		userId:    userId,
+		email:     email,
+		expiresAt: issuedAt.Add(cfg.TokenTimeToLive()),
+	}, nil
+}
+
+// Token returns plain token value, it is available only for newly issued tokens
+func (t *Token) Token() string {
+	return t.token
+}
+
+func (t *Token) UserId() string {
+	return t.userId
+}
+
+func (t *Token) Email() string {
+	return t.email
+}
+
+func (t *Token) ExpiresAt() time.Time {
+	return t.expiresAt
+}
+
+func (t *Token) IsExpired(now time.Time) bool {
+	return !t.expiresAt.After(now)
+}
+
+func (t *Token) Dto() TokenDto {
+	return TokenDto{
+		UserId:    t.userId,
+		Email:     t.email,
+		ExpiresAt: t.expiresAt,
+	}
+}
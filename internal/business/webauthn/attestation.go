@@ -0,0 +1,230 @@
+package webauthn
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/asn1"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/exp/slices"
+
+	valueobj "github.com/umalmyha/authsrv/internal/business/value-object"
+	"github.com/umalmyha/authsrv/pkg/cbor"
+)
+
+const (
+	AttestationNone   = "none"
+	AttestationPacked = "packed"
+
+	maxCredentialNameLen = 64
+)
+
+// id-fido-gen-ce-aaguid extension of packed attestation certificate (WebAuthn 8.2.1)
+var aaguidExtensionOid = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 45724, 1, 1, 4}
+
+// VerifyRegistration checks attestation returned by authenticator and builds new credential for session user.
+// Packed attestation certificates are checked against WebAuthn requirements, but their chain isn't validated
+// against trust anchors, so attestation proves key possession only and not authenticator model.
+func VerifyRegistration(session *Session, resp RegistrationResponseDto, cfg valueobj.WebauthnConfig, now time.Time) (*Credential, error) {
+	if err := session.verify(CeremonyRegistration, now); err != nil {
+		return nil, err
+	}
+
+	if resp.Type != publicKeyCredentialType {
+		return nil, errors.Wrapf(VerificationFailedErr, "unexpected credential type %s", resp.Type)
+	}
+
+	rawId, err := credentialIdOf(resp.Id, resp.RawId)
+	if err != nil {
+		return nil, errors.Wrap(VerificationFailedErr, err.Error())
+	}
+
+	clientDataJson, err := decodeBase64Url(resp.Response.ClientDataJson)
+	if err != nil {
+		return nil, errors.Wrap(VerificationFailedErr, "client data JSON is not base64url encoded")
+	}
+
+	clientData, err := ParseClientData(clientDataJson)
+	if err != nil {
+		return nil, errors.Wrap(VerificationFailedErr, err.Error())
+	}
+
+	if err := clientData.verify(CeremonyRegistration, session.challenge, cfg); err != nil {
+		return nil, err
+	}
+
+	rawAttestation, err := decodeBase64Url(resp.Response.AttestationObject)
+	if err != nil {
+		return nil, errors.Wrap(VerificationFailedErr, "attestation object is not base64url encoded")
+	}
+
+	format, attStmt, rawAuthData, err := parseAttestationObject(rawAttestation)
+	if err != nil {
+		return nil, errors.Wrap(VerificationFailedErr, err.Error())
+	}
+
+	authData, err := ParseAuthenticatorData(rawAuthData)
+	if err != nil {
+		return nil, errors.Wrap(VerificationFailedErr, err.Error())
+	}
+
+	if err := verifyRpIdHash(authData, cfg); err != nil {
+		return nil, err
+	}
+
+	if err := session.verifyFlags(authData); err != nil {
+		return nil, err
+	}
+
+	if !authData.HasAttestedCredential() {
+		return nil, errors.Wrap(VerificationFailedErr, "attested credential data is missing")
+	}
+
+	if !bytes.Equal(authData.CredentialId, rawId) {
+		return nil, errors.Wrap(VerificationFailedErr, "credential id doesn't match attested credential")
+	}
+
+	pub, err := ParsePublicKey(authData.CredentialPublicKey)
+	if err != nil {
+		return nil, errors.Wrap(VerificationFailedErr, err.Error())
+	}
+
+	if !slices.Contains(SupportedAlgorithms, pub.Algorithm()) {
+		return nil, errors.Wrapf(VerificationFailedErr, "credential algorithm %d is not supported", pub.Algorithm())
+	}
+
+	switch format {
+	case AttestationNone:
+		if len(attStmt) != 0 {
+			return nil, errors.Wrap(VerificationFailedErr, "none attestation must have empty statement")
+		}
+	case AttestationPacked:
+		if err := verifyPackedAttestation(attStmt, signedData(rawAuthData, clientDataJson), authData, pub); err != nil {
+			return nil, errors.Wrap(VerificationFailedErr, err.Error())
+		}
+	default:
+		return nil, errors.Wrapf(VerificationFailedErr, "attestation format %s is not supported", format)
+	}
+
+	name := strings.TrimSpace(resp.Name)
+	if name == "" {
+		name = "Passkey"
+	}
+	if len(name) > maxCredentialNameLen {
+		return nil, errors.Errorf("credential name must not exceed %d characters", maxCredentialNameLen)
+	}
+
+	return &Credential{
+		id:                encodeBase64Url(rawId),
+		userId:            session.userId,
+		name:              name,
+		publicKey:         authData.CredentialPublicKey,
+		signCount:         authData.SignCount,
+		aaguid:            authData.Aaguid,
+		attestationFormat: format,
+		transports:        resp.Response.Transports,
+		createdAt:         now,
+	}, nil
+}
+
+func parseAttestationObject(raw []byte) (string, map[any]any, []byte, error) {
+	decoded, err := cbor.Unmarshal(raw)
+	if err != nil {
+		return "", nil, nil, errors.Wrap(err, "failed to decode attestation object")
+	}
+
+	obj, ok := decoded.(map[any]any)
+	if !ok {
+		return "", nil, nil, errors.New("attestation object must be a map")
+	}
+
+	format, _ := obj["fmt"].(string)
+	attStmt, ok := obj["attStmt"].(map[any]any)
+	if !ok {
+		return "", nil, nil, errors.New("attestation statement must be a map")
+	}
+
+	authData, ok := obj["authData"].([]byte)
+	if !ok {
+		return "", nil, nil, errors.New("attestation object has no authenticator data")
+	}
+	return format, attStmt, authData, nil
+}
+
+// verifyPackedAttestation implements packed attestation verification procedure (WebAuthn 8.2)
+func verifyPackedAttestation(attStmt map[any]any, signed []byte, authData AuthenticatorData, pub PublicKey) error {
+	alg, ok := attStmt["alg"].(int64)
+	if !ok {
+		return errors.New("packed attestation has no algorithm")
+	}
+
+	sig, ok := attStmt["sig"].([]byte)
+	if !ok {
+		return errors.New("packed attestation has no signature")
+	}
+
+	x5c, hasX5c := attStmt["x5c"].([]any)
+	if !hasX5c {
+		// self attestation is signed by credential private key itself
+		if alg != pub.Algorithm() {
+			return errors.New("self attestation algorithm doesn't match credential algorithm")
+		}
+		return pub.Verify(signed, sig)
+	}
+
+	if len(x5c) == 0 {
+		return errors.New("packed attestation certificate chain is empty")
+	}
+
+	der, ok := x5c[0].([]byte)
+	if !ok {
+		return errors.New("packed attestation certificate must be a byte string")
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse packed attestation certificate")
+	}
+
+	if err := verifySignature(alg, cert.PublicKey, signed, sig); err != nil {
+		return err
+	}
+	return verifyPackedCertificate(cert, authData.Aaguid)
+}
+
+// verifyPackedCertificate checks attestation certificate requirements (WebAuthn 8.2.1)
+func verifyPackedCertificate(cert *x509.Certificate, aaguid []byte) error {
+	if cert.Version != 3 {
+		return errors.New("attestation certificate must be version 3")
+	}
+
+	if cert.IsCA {
+		return errors.New("attestation certificate must not be CA")
+	}
+
+	if !slices.Contains(cert.Subject.OrganizationalUnit, "Authenticator Attestation") {
+		return errors.New("attestation certificate subject OU must be Authenticator Attestation")
+	}
+
+	for _, ext := range cert.Extensions {
+		if !ext.Id.Equal(aaguidExtensionOid) {
+			continue
+		}
+
+		if ext.Critical {
+			return errors.New("aaguid extension must not be critical")
+		}
+
+		var certAaguid []byte
+		if _, err := asn1.Unmarshal(ext.Value, &certAaguid); err != nil {
+			return errors.Wrap(err, "failed to parse aaguid extension")
+		}
+
+		if !bytes.Equal(certAaguid, aaguid) {
+			return errors.New("attestation certificate aaguid doesn't match authenticator data")
+		}
+	}
+	return nil
+}
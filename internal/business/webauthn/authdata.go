@@ -0,0 +1,92 @@
+package webauthn
+
+import (
+	"encoding/binary"
+
+	"github.com/pkg/errors"
+
+	"github.com/umalmyha/authsrv/pkg/cbor"
+)
+
+const (
+	flagUserPresent  = 0x01
+	flagUserVerified = 0x04
+	flagAttestedData = 0x40
+	flagExtensions   = 0x80
+
+	rpIdHashSize      = 32
+	authDataMinSize   = rpIdHashSize + 1 + 4
+	aaguidSize        = 16
+	maxCredentialSize = 1023
+)
+
+// AuthenticatorData is binary structure signed by authenticator (WebAuthn 6.1)
+type AuthenticatorData struct {
+	RpIdHash  []byte
+	Flags     byte
+	SignCount uint32
+	// attested credential data is present on registration only
+	Aaguid              []byte
+	CredentialId        []byte
+	CredentialPublicKey []byte
+}
+
+func ParseAuthenticatorData(raw []byte) (AuthenticatorData, error) {
+	var data AuthenticatorData
+
+	if len(raw) < authDataMinSize {
+		return data, errors.New("authenticator data is too short")
+	}
+
+	data.RpIdHash = raw[:rpIdHashSize]
+	data.Flags = raw[rpIdHashSize]
+	data.SignCount = binary.BigEndian.Uint32(raw[rpIdHashSize+1 : authDataMinSize])
+	rest := raw[authDataMinSize:]
+
+	if data.HasAttestedCredential() {
+		if len(rest) < aaguidSize+2 {
+			return data, errors.New("attested credential data is too short")
+		}
+		data.Aaguid = rest[:aaguidSize]
+
+		idLen := int(binary.BigEndian.Uint16(rest[aaguidSize : aaguidSize+2]))
+		rest = rest[aaguidSize+2:]
+		if idLen > maxCredentialSize || len(rest) < idLen {
+			return data, errors.New("credential id length is invalid")
+		}
+		data.CredentialId = rest[:idLen]
+		rest = rest[idLen:]
+
+		_, n, err := cbor.Decode(rest)
+		if err != nil {
+			return data, errors.Wrap(err, "failed to decode credential public key")
+		}
+		data.CredentialPublicKey = rest[:n]
+		rest = rest[n:]
+	}
+
+	if data.Flags&flagExtensions != 0 {
+		_, n, err := cbor.Decode(rest)
+		if err != nil {
+			return data, errors.Wrap(err, "failed to decode authenticator extensions")
+		}
+		rest = rest[n:]
+	}
+
+	if len(rest) != 0 {
+		return data, errors.New("authenticator data has trailing bytes")
+	}
+	return data, nil
+}
+
+func (d AuthenticatorData) UserPresent() bool {
+	return d.Flags&flagUserPresent != 0
+}
+
+func (d AuthenticatorData) UserVerified() bool {
+	return d.Flags&flagUserVerified != 0
+}
+
+func (d AuthenticatorData) HasAttestedCredential() bool {
+	return d.Flags&flagAttestedData != 0
+}
@@ -0,0 +1,83 @@
+package webauthn
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	valueobj "github.com/umalmyha/authsrv/internal/business/value-object"
+)
+
+// CollectedClientData is JSON prepared by browser for the ceremony (WebAuthn 5.8.1)
+type CollectedClientData struct {
+	Type        string `json:"type"`
+	Challenge   string `json:"challenge"`
+	Origin      string `json:"origin"`
+	CrossOrigin bool   `json:"crossOrigin"`
+}
+
+func ParseClientData(raw []byte) (CollectedClientData, error) {
+	var data CollectedClientData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return data, errors.Wrap(err, "failed to parse client data JSON")
+	}
+	return data, nil
+}
+
+// ChallengeFromClientData extracts challenge, it is used to find ceremony session before response is verified
+func ChallengeFromClientData(encoded string) (string, error) {
+	raw, err := decodeBase64Url(encoded)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to decode client data JSON")
+	}
+
+	data, err := ParseClientData(raw)
+	if err != nil {
+		return "", err
+	}
+
+	if data.Challenge == "" {
+		return "", errors.New("client data has no challenge")
+	}
+	return data.Challenge, nil
+}
+
+func (d CollectedClientData) verify(ceremony string, challenge string, cfg valueobj.WebauthnConfig) error {
+	if d.Type != ceremony {
+		return errors.Wrapf(VerificationFailedErr, "unexpected client data type %s", d.Type)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(d.Challenge), []byte(challenge)) != 1 {
+		return errors.Wrap(VerificationFailedErr, "challenge doesn't match")
+	}
+
+	if !cfg.AllowsOrigin(d.Origin) {
+		return errors.Wrapf(VerificationFailedErr, "origin %s is not allowed", d.Origin)
+	}
+
+	if d.CrossOrigin {
+		return errors.Wrap(VerificationFailedErr, "cross origin ceremonies are not allowed")
+	}
+	return nil
+}
+
+func verifyRpIdHash(authData AuthenticatorData, cfg valueobj.WebauthnConfig) error {
+	expected := sha256.Sum256([]byte(cfg.RpId()))
+	if subtle.ConstantTimeCompare(authData.RpIdHash, expected[:]) != 1 {
+		return errors.Wrap(VerificationFailedErr, "rp id hash doesn't match")
+	}
+	return nil
+}
+
+// browsers encode binary fields in base64url without padding, but padded values are tolerated
+func decodeBase64Url(encoded string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(encoded, "="))
+}
+
+func encodeBase64Url(raw []byte) string {
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
@@ -0,0 +1,142 @@
+package webauthn
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"math/big"
+
+	"github.com/pkg/errors"
+
+	"github.com/umalmyha/authsrv/pkg/cbor"
+)
+
+// COSE algorithms (RFC 8152, RFC 8812) supported for credentials
+const (
+	AlgES256 int64 = -7
+	AlgEdDSA int64 = -8
+	AlgRS256 int64 = -257
+)
+
+var SupportedAlgorithms = []int64{AlgES256, AlgEdDSA, AlgRS256}
+
+const (
+	coseKeyKty = 1
+	coseKeyAlg = 3
+	// curve and coordinates of OKP and EC2 keys, modulus and exponent of RSA keys share labels
+	coseKeyCrv = -1
+	coseKeyX   = -2
+	coseKeyY   = -3
+	coseKeyN   = -1
+	coseKeyE   = -2
+
+	coseKtyOkp = 1
+	coseKtyEc2 = 2
+	coseKtyRsa = 3
+
+	coseCrvP256    = 1
+	coseCrvEd25519 = 6
+)
+
+// PublicKey is credential public key decoded from COSE_Key structure
+type PublicKey struct {
+	alg int64
+	key crypto.PublicKey
+}
+
+func ParsePublicKey(coseKey []byte) (PublicKey, error) {
+	decoded, err := cbor.Unmarshal(coseKey)
+	if err != nil {
+		return PublicKey{}, errors.Wrap(err, "failed to decode COSE key")
+	}
+
+	m, ok := decoded.(map[any]any)
+	if !ok {
+		return PublicKey{}, errors.New("COSE key must be a map")
+	}
+
+	kty, _ := m[int64(coseKeyKty)].(int64)
+	alg, _ := m[int64(coseKeyAlg)].(int64)
+
+	switch {
+	case kty == coseKtyEc2 && alg == AlgES256:
+		crv, _ := m[int64(coseKeyCrv)].(int64)
+		x, _ := m[int64(coseKeyX)].([]byte)
+		y, _ := m[int64(coseKeyY)].([]byte)
+		if crv != coseCrvP256 || len(x) != 32 || len(y) != 32 {
+			return PublicKey{}, errors.New("ES256 key must be P-256 point")
+		}
+
+		pub := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !pub.Curve.IsOnCurve(pub.X, pub.Y) {
+			return PublicKey{}, errors.New("ES256 key point is not on curve")
+		}
+		return PublicKey{alg: alg, key: pub}, nil
+	case kty == coseKtyOkp && alg == AlgEdDSA:
+		crv, _ := m[int64(coseKeyCrv)].(int64)
+		x, _ := m[int64(coseKeyX)].([]byte)
+		if crv != coseCrvEd25519 || len(x) != ed25519.PublicKeySize {
+			return PublicKey{}, errors.New("EdDSA key must be Ed25519 key")
+		}
+		return PublicKey{alg: alg, key: ed25519.PublicKey(x)}, nil
+	case kty == coseKtyRsa && alg == AlgRS256:
+		n, _ := m[int64(coseKeyN)].([]byte)
+		e, _ := m[int64(coseKeyE)].([]byte)
+		if len(n) < 256 || len(e) == 0 || len(e) > 4 {
+			return PublicKey{}, errors.New("RS256 key must have at least 2048 bits modulus")
+		}
+		return PublicKey{alg: alg, key: &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}}, nil
+	default:
+		return PublicKey{}, errors.Errorf("unsupported COSE key type %d with algorithm %d", kty, alg)
+	}
+}
+
+func (k PublicKey) Algorithm() int64 {
+	return k.alg
+}
+
+func (k PublicKey) Verify(data []byte, sig []byte) error {
+	return verifySignature(k.alg, k.key, data, sig)
+}
+
+func verifySignature(alg int64, key crypto.PublicKey, data []byte, sig []byte) error {
+	switch alg {
+	case AlgES256:
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return errors.New("ES256 signature requires ECDSA key")
+		}
+
+		digest := sha256.Sum256(data)
+		if !ecdsa.VerifyASN1(pub, digest[:], sig) {
+			return errors.New("ES256 signature is invalid")
+		}
+		return nil
+	case AlgEdDSA:
+		pub, ok := key.(ed25519.PublicKey)
+		if !ok {
+			return errors.New("EdDSA signature requires Ed25519 key")
+		}
+
+		if !ed25519.Verify(pub, data, sig) {
+			return errors.New("EdDSA signature is invalid")
+		}
+		return nil
+	case AlgRS256:
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return errors.New("RS256 signature requires RSA key")
+		}
+
+		digest := sha256.Sum256(data)
+		if err := rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], sig); err != nil {
+			return errors.Wrap(err, "RS256 signature is invalid")
+		}
+		return nil
+	default:
+		return errors.Errorf("unsupported signature algorithm %d", alg)
+	}
+}
@@ -0,0 +1,192 @@
+package webauthn
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/exp/slices"
+
+	valueobj "github.com/umalmyha/authsrv/internal/business/value-object"
+)
+
+const publicKeyCredentialType = "public-key"
+
+var CredentialClonedErr = errors.New("credential sign count went backwards, authenticator might be cloned")
+
+// Credential is public key credential registered by user authenticator
+type Credential struct {
+	id                string
+	userId            string
+	name              string
+	publicKey         []byte
+	signCount         uint32
+	aaguid            []byte
+	attestationFormat string
+	transports        []string
+	createdAt         time.Time
+	lastUsedAt        *time.Time
+}
+
+func (c *Credential) Id() string {
+	return c.id
+}
+
+func (c *Credential) UserId() string {
+	return c.userId
+}
+
+func (c *Credential) Name() string {
+	return c.name
+}
+
+func (c *Credential) SignCount() uint32 {
+	return c.signCount
+}
+
+func (c *Credential) AttestationFormat() string {
+	return c.attestationFormat
+}
+
+// VerifyAssertion checks authenticator response signed with this credential and advances sign count
+func (c *Credential) VerifyAssertion(session *Session, resp AssertionResponseDto, cfg valueobj.WebauthnConfig, now time.Time) error {
+	if err := session.verify(CeremonyAssertion, now); err != nil {
+		return err
+	}
+
+	if resp.Type != publicKeyCredentialType {
+		return errors.Wrapf(VerificationFailedErr, "unexpected credential type %s", resp.Type)
+	}
+
+	credId, err := CredentialIdFromResponse(resp)
+	if err != nil {
+		return err
+	}
+
+	if credId != c.id {
+		return errors.Wrap(VerificationFailedErr, "credential id doesn't match")
+	}
+
+	if len(session.allowedIds) > 0 && !slices.Contains(session.allowedIds, c.id) {
+		return errors.Wrap(VerificationFailedErr, "credential is not allowed for this ceremony")
+	}
+
+	if session.userId != "" && session.userId != c.userId {
+		return errors.Wrap(VerificationFailedErr, "credential belongs to another user")
+	}
+
+	if resp.Response.UserHandle != "" {
+		userHandle, err := decodeBase64Url(resp.Response.UserHandle)
+		if err != nil || subtle.ConstantTimeCompare(userHandle, []byte(c.userId)) != 1 {
+			return errors.Wrap(VerificationFailedErr, "user handle doesn't match credential owner")
+		}
+	}
+
+	clientDataJson, err := decodeBase64Url(resp.Response.ClientDataJson)
+	if err != nil {
+		return errors.Wrap(VerificationFailedErr, "client data JSON is not base64url encoded")
+	}
+
+	clientData, err := ParseClientData(clientDataJson)
+	if err != nil {
+		return errors.Wrap(VerificationFailedErr, err.Error())
+	}
+
+	if err := clientData.verify(CeremonyAssertion, session.challenge, cfg); err != nil {
+		return err
+	}
+
+	rawAuthData, err := decodeBase64Url(resp.Response.AuthenticatorData)
+	if err != nil {
+		return errors.Wrap(VerificationFailedErr, "authenticator data is not base64url encoded")
+	}
+
+	authData, err := ParseAuthenticatorData(rawAuthData)
+	if err != nil {
+		return errors.Wrap(VerificationFailedErr, err.Error())
+	}
+
+	if err := verifyRpIdHash(authData, cfg); err != nil {
+		return err
+	}
+
+	if err := session.verifyFlags(authData); err != nil {
+		return err
+	}
+
+	sig, err := decodeBase64Url(resp.Response.Signature)
+	if err != nil {
+		return errors.Wrap(VerificationFailedErr, "signature is not base64url encoded")
+	}
+
+	pub, err := ParsePublicKey(c.publicKey)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse stored credential public key")
+	}
+
+	if err := pub.Verify(signedData(rawAuthData, clientDataJson), sig); err != nil {
+		return errors.Wrap(VerificationFailedErr, err.Error())
+	}
+
+	// authenticators without counter always report zero, otherwise counter must strictly grow (WebAuthn 7.2 step 21)
+	if authData.SignCount != 0 || c.signCount != 0 {
+		if authData.SignCount <= c.signCount {
+			return CredentialClonedErr
+		}
+	}
+
+	c.signCount = authData.SignCount
+	c.lastUsedAt = &now
+	return nil
+}
+
+func (c *Credential) Descriptor() CredentialDescriptorDto {
+	return CredentialDescriptorDto{
+		Type:       publicKeyCredentialType,
+		Id:         c.id,
+		Transports: c.transports,
+	}
+}
+
+func (c *Credential) Info() CredentialInfoDto {
+	return CredentialInfoDto{
+		Id:         c.id,
+		Name:       c.name,
+		CreatedAt:  c.createdAt,
+		LastUsedAt: c.lastUsedAt,
+	}
+}
+
+func (c *Credential) Dto() CredentialDto {
+	return CredentialDto{
+		Id:                c.id,
+		UserId:            c.userId,
+		Name:              c.name,
+		PublicKey:         c.publicKey,
+		SignCount:         int64(c.signCount),
+		Aaguid:            c.aaguid,
+		AttestationFormat: c.attestationFormat,
+		Transports:        strings.Join(c.transports, ","),
+		CreatedAt:         c.createdAt,
+		LastUsedAt:        c.lastUsedAt,
+	}
+}
+
+// CredentialIdFromResponse extracts credential id, it is used to find credential before assertion is verified
+func CredentialIdFromResponse(resp AssertionResponseDto) (string, error) {
+	rawId, err := credentialIdOf(resp.Id, resp.RawId)
+	if err != nil {
+		return "", errors.Wrap(VerificationFailedErr, err.Error())
+	}
+	return encodeBase64Url(rawId), nil
+}
+
+// signedData is concatenation of authenticator data and client data hash which authenticator signs
+func signedData(rawAuthData []byte, clientDataJson []byte) []byte {
+	clientDataHash := sha256.Sum256(clientDataJson)
+	data := make([]byte, 0, len(rawAuthData)+len(clientDataHash))
+	data = append(data, rawAuthData...)
+	return append(data, clientDataHash[:]...)
+}
@@ -0,0 +1,161 @@
+package webauthn
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	"github.com/umalmyha/authsrv/pkg/database/rdb"
+	dbredis "github.com/umalmyha/authsrv/pkg/database/redis"
+)
+
+const keyPrefix = "webauthn_session:"
+
+type SessionDao struct {
+	*dbredis.Store
+}
+
+func NewSessionDao(rdb *redis.Client) *SessionDao {
+	return &SessionDao{
+		Store: dbredis.NewStore(rdb),
+	}
+}
+
+func (dao *SessionDao) Save(ctx context.Context, dto SessionDto) error {
+	encoded, err := dbredis.EncodeGob(dto)
+	if err != nil {
+		return errors.Wrap(err, "failed to serialize webauthn session to gob format")
+	}
+
+	ttl := time.Until(dto.ExpiresAt)
+	if ttl <= 0 {
+		return errors.New("webauthn session is already expired")
+	}
+
+	if err := dao.Client().Set(ctx, sessionKey(dto.Challenge), encoded, ttl).Err(); err != nil {
+		return errors.Wrap(err, "failed to save webauthn session")
+	}
+	return nil
+}
+
+// Consume reads session by challenge and removes it atomically, so every challenge can be answered only once
+func (dao *SessionDao) Consume(ctx context.Context, challenge string) (SessionDto, error) {
+	var dto SessionDto
+
+	encoded, err := dao.Client().GetDel(ctx, sessionKey(challenge)).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return dto, nil
+		}
+		return dto, errors.Wrap(err, "failed to read webauthn session")
+	}
+
+	if err := dbredis.DecodeGob([]byte(encoded), &dto); err != nil {
+		return dto, errors.Wrap(err, "failed to deserialize webauthn session from gob format")
+	}
+	return dto, nil
+}
+
+func sessionKey(challenge string) string {
+	sum := sha256.Sum256([]byte(challenge))
+	return keyPrefix + hex.EncodeToString(sum[:])
+}
+
+type CredentialDao struct {
+	ec sqlx.ExtContext
+}
+
+func NewCredentialDao(ec sqlx.ExtContext) *CredentialDao {
+	return &CredentialDao{
+		ec: ec,
+	}
+}
+
+func (dao *CredentialDao) CreateMulti(ctx context.Context, credentials []CredentialDto) error {
+	cols := []string{
+		"ID",
+		"USER_ID",
+		"NAME",
+		"PUBLIC_KEY",
+		"SIGN_COUNT",
+		"AAGUID",
+		"ATTESTATION_FORMAT",
+		"TRANSPORTS",
+		"CREATED_AT",
+		"LAST_USED_AT",
+	}
+
+	applier := func(cred CredentialDto) []any {
+		return []any{
+			cred.Id,
+			cred.UserId,
+			cred.Name,
+			cred.PublicKey,
+			cred.SignCount,
+			cred.Aaguid,
+			cred.AttestationFormat,
+			cred.Transports,
+			cred.CreatedAt,
+			cred.LastUsedAt,
+		}
+	}
+
+	q, params, err := rdb.BulkInsertQuery("USER_WEBAUTHN_CREDENTIALS", cols, credentials, applier)
+	if err != nil {
+		return errors.Wrap(err, "failed to build bulk insert SQL query for webauthn credentials creation")
+	}
+
+	if _, err := dao.ec.ExecContext(ctx, q, params...); err != nil {
+		return errors.Wrap(err, "failed to create webauthn credentials")
+	}
+
+	return nil
+}
+
+func (dao *CredentialDao) Update(ctx context.Context, cred CredentialDto) error {
+	q := "UPDATE USER_WEBAUTHN_CREDENTIALS SET NAME = $1, SIGN_COUNT = $2, LAST_USED_AT = $3 WHERE ID = $4"
+	if _, err := dao.ec.ExecContext(ctx, q, cred.Name, cred.SignCount, cred.LastUsedAt, cred.Id); err != nil {
+		return errors.Wrap(err, "failed to update webauthn credential")
+	}
+	return nil
+}
+
+func (dao *CredentialDao) DeleteWhereIdsIn(ctx context.Context, ids []string) error {
+	inRange, params, err := rdb.WhereIn(ids)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate SQL where clause for webauthn credentials deletion")
+	}
+
+	q := fmt.Sprintf("DELETE FROM USER_WEBAUTHN_CREDENTIALS WHERE ID IN %s", inRange)
+	if _, err := dao.ec.ExecContext(ctx, q, params...); err != nil {
+		return errors.Wrap(err, "failed to delete webauthn credentials")
+	}
+
+	return nil
+}
+
+func (dao *CredentialDao) FindAllForUser(ctx context.Context, userId string) ([]CredentialDto, error) {
+	credentials := make([]CredentialDto, 0)
+	q := "SELECT * FROM USER_WEBAUTHN_CREDENTIALS WHERE USER_ID = $1 ORDER BY CREATED_AT"
+
+	if err := sqlx.SelectContext(ctx, dao.ec, &credentials, q, userId); err != nil {
+		return nil, errors.Wrap(err, "failed to read user webauthn credentials")
+	}
+	return credentials, nil
+}
+
+// FindUserIdById resolves owner of discoverable credential
+func (dao *CredentialDao) FindUserIdById(ctx context.Context, id string) (string, error) {
+	var userId string
+	q := "SELECT USER_ID FROM USER_WEBAUTHN_CREDENTIALS WHERE ID = $1"
+
+	if err := sqlx.GetContext(ctx, dao.ec, &userId, q, id); err != nil {
+		return "", errors.Wrap(err, "failed to read webauthn credential owner")
+	}
+	return userId, nil
+}
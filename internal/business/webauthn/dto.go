@@ -0,0 +1,211 @@
+package webauthn
+
+import (
+	"bytes"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type SessionDto struct {
+	Challenge        string
+	Ceremony         string
+	UserId           string
+	AllowedIds       []string
+	UserVerification string
+	ExpiresAt        time.Time
+}
+
+func (dto SessionDto) IsPresent() bool {
+	return dto.Challenge != ""
+}
+
+func (dto SessionDto) ToSession() *Session {
+	return &Session{
+		challenge:        dto.Challenge,
+		ceremony:         dto.Ceremony,
+		userId:           dto.UserId,
+		allowedIds:       dto.AllowedIds,
+		userVerification: dto.UserVerification,
+		expiresAt:        dto.ExpiresAt,
+	}
+}
+
+// CredentialDto is stored credential, id is base64url encoded credential id
+type CredentialDto struct {
+	Id                string     `db:"id"`
+	UserId            string     `db:"user_id"`
+	Name              string     `db:"name"`
+	PublicKey         []byte     `db:"public_key"`
+	SignCount         int64      `db:"sign_count"`
+	Aaguid            []byte     `db:"aaguid"`
+	AttestationFormat string     `db:"attestation_format"`
+	Transports        string     `db:"transports"`
+	CreatedAt         time.Time  `db:"created_at"`
+	LastUsedAt        *time.Time `db:"last_used_at"`
+}
+
+func (dto CredentialDto) Key() string {
+	return dto.Id
+}
+
+func (dto CredentialDto) IsPresent() bool {
+	return dto.Id != ""
+}
+
+func (dto CredentialDto) Equal(other CredentialDto) bool {
+	return dto.Id == other.Id &&
+		dto.UserId == other.UserId &&
+		dto.Name == other.Name &&
+		bytes.Equal(dto.PublicKey, other.PublicKey) &&
+		dto.SignCount == other.SignCount &&
+		bytes.Equal(dto.Aaguid, other.Aaguid) &&
+		dto.AttestationFormat == other.AttestationFormat &&
+		dto.Transports == other.Transports &&
+		dto.CreatedAt.Equal(other.CreatedAt) &&
+		equalTimes(dto.LastUsedAt, other.LastUsedAt)
+}
+
+func (dto CredentialDto) Clone() CredentialDto {
+	clone := dto
+	clone.PublicKey = append([]byte(nil), dto.PublicKey...)
+	clone.Aaguid = append([]byte(nil), dto.Aaguid...)
+	if dto.LastUsedAt != nil {
+		lastUsedAt := *dto.LastUsedAt
+		clone.LastUsedAt = &lastUsedAt
+	}
+	return clone
+}
+
+// CredentialInfoDto describes registered credential to its owner
+type CredentialInfoDto struct {
+	Id         string     `json:"id"`
+	Name       string     `json:"name"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+}
+
+type RelyingPartyDto struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type UserEntityDto struct {
+	Id          string `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+type CredentialParameterDto struct {
+	Type string `json:"type"`
+	Alg  int64  `json:"alg"`
+}
+
+type CredentialDescriptorDto struct {
+	Type       string   `json:"type"`
+	Id         string   `json:"id"`
+	Transports []string `json:"transports,omitempty"`
+}
+
+type AuthenticatorSelectionDto struct {
+	ResidentKey      string `json:"residentKey"`
+	UserVerification string `json:"userVerification"`
+}
+
+// CreationOptionsDto is PublicKeyCredentialCreationOptions in JSON form, binary values are base64url encoded
+type CreationOptionsDto struct {
+	Rp                     RelyingPartyDto           `json:"rp"`
+	User                   UserEntityDto             `json:"user"`
+	Challenge              string                    `json:"challenge"`
+	PubKeyCredParams       []CredentialParameterDto  `json:"pubKeyCredParams"`
+	Timeout                int64                     `json:"timeout"`
+	ExcludeCredentials     []CredentialDescriptorDto `json:"excludeCredentials"`
+	AuthenticatorSelection AuthenticatorSelectionDto `json:"authenticatorSelection"`
+	Attestation            string                    `json:"attestation"`
+}
+
+// RequestOptionsDto is PublicKeyCredentialRequestOptions in JSON form, binary values are base64url encoded
+type RequestOptionsDto struct {
+	Challenge        string                    `json:"challenge"`
+	Timeout          int64                     `json:"timeout"`
+	RpId             string                    `json:"rpId"`
+	AllowCredentials []CredentialDescriptorDto `json:"allowCredentials"`
+	UserVerification string                    `json:"userVerification"`
+}
+
+type AttestationResponseDto struct {
+	ClientDataJson    string   `json:"clientDataJSON"`
+	AttestationObject string   `json:"attestationObject"`
+	Transports        []string `json:"transports"`
+}
+
+// RegistrationResponseDto is PublicKeyCredential returned by navigator.credentials.create()
+type RegistrationResponseDto struct {
+	Id       string                 `json:"id"`
+	RawId    string                 `json:"rawId"`
+	Type     string                 `json:"type"`
+	Response AttestationResponseDto `json:"response"`
+	// Name is label given to credential by user
+	Name string `json:"name"`
+}
+
+type AssertionDataDto struct {
+	ClientDataJson    string `json:"clientDataJSON"`
+	AuthenticatorData string `json:"authenticatorData"`
+	Signature         string `json:"signature"`
+	UserHandle        string `json:"userHandle"`
+}
+
+// AssertionResponseDto is PublicKeyCredential returned by navigator.credentials.get()
+type AssertionResponseDto struct {
+	Id       string           `json:"id"`
+	RawId    string           `json:"rawId"`
+	Type     string           `json:"type"`
+	Response AssertionDataDto `json:"response"`
+}
+
+func (dto CredentialDto) ToCredential() *Credential {
+	var transports []string
+	if dto.Transports != "" {
+		transports = strings.Split(dto.Transports, ",")
+	}
+
+	return &Credential{
+		id:                dto.Id,
+		userId:            dto.UserId,
+		name:              dto.Name,
+		publicKey:         dto.PublicKey,
+		signCount:         uint32(dto.SignCount),
+		aaguid:            dto.Aaguid,
+		attestationFormat: dto.AttestationFormat,
+		transports:        transports,
+		createdAt:         dto.CreatedAt,
+		lastUsedAt:        dto.LastUsedAt,
+	}
+}
+
+func equalTimes(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Equal(*b)
+}
+
+// credentialIdOf returns raw credential id, rawId is preferred and id is fallback for clients which omit it
+func credentialIdOf(id string, rawId string) ([]byte, error) {
+	encoded := rawId
+	if encoded == "" {
+		encoded = id
+	}
+
+	raw, err := decodeBase64Url(encoded)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode credential id")
+	}
+
+	if len(raw) == 0 {
+		return nil, errors.New("credential id is missing")
+	}
+	return raw, nil
+}
@@ -0,0 +1,143 @@
+package webauthn
+
+import (
+	"crypto/rand"
+	"time"
+
+	"github.com/pkg/errors"
+
+	valueobj "github.com/umalmyha/authsrv/internal/business/value-object"
+)
+
+const (
+	CeremonyRegistration = "webauthn.create"
+	CeremonyAssertion    = "webauthn.get"
+
+	UserVerificationRequired  = "required"
+	UserVerificationPreferred = "preferred"
+
+	challengeSize = 32
+)
+
+var (
+	VerificationFailedErr = errors.New("webauthn verification failed")
+	SessionExpiredErr     = errors.New("webauthn ceremony session expired")
+)
+
+// Session keeps state of started ceremony between options issue and response verification
+type Session struct {
+	challenge        string
+	ceremony         string
+	userId           string
+	allowedIds       []string
+	userVerification string
+	expiresAt        time.Time
+}
+
+// NewRegistrationSession starts registration of new credential for authenticated user
+func NewRegistrationSession(userId string, issuedAt time.Time, cfg valueobj.WebauthnConfig) (*Session, error) {
+	if userId == "" {
+		return nil, errors.New("user is mandatory for credential registration")
+	}
+	return newSession(CeremonyRegistration, userId, nil, UserVerificationPreferred, issuedAt, cfg)
+}
+
+// NewAssertionSession starts sign in, empty user means discoverable credential flow where user is identified by credential.
+// User verification is required, so passkey replaces both password and second factor
+func NewAssertionSession(userId string, allowedIds []string, issuedAt time.Time, cfg valueobj.WebauthnConfig) (*Session, error) {
+	return newSession(CeremonyAssertion, userId, allowedIds, UserVerificationRequired, issuedAt, cfg)
+}
+
+func newSession(ceremony string, userId string, allowedIds []string, uv string, issuedAt time.Time, cfg valueobj.WebauthnConfig) (*Session, error) {
+	challenge := make([]byte, challengeSize)
+	if _, err := rand.Read(challenge); err != nil {
+		return nil, errors.Wrap(err, "failed to generate webauthn challenge")
+	}
+
+	return &Session{
+		challenge:        encodeBase64Url(challenge),
+		ceremony:         ceremony,
+		userId:           userId,
+		allowedIds:       allowedIds,
+		userVerification: uv,
+		expiresAt:        issuedAt.Add(cfg.Timeout()),
+	}, nil
+}
+
+func (s *Session) Challenge() string {
+	return s.challenge
+}
+
+func (s *Session) UserId() string {
+	return s.userId
+}
+
+func (s *Session) ExpiresAt() time.Time {
+	return s.expiresAt
+}
+
+// CreationOptions builds options passed to navigator.credentials.create(), user handle is user id
+func (s *Session) CreationOptions(username string, exclude []CredentialDescriptorDto, cfg valueobj.WebauthnConfig) CreationOptionsDto {
+	params := make([]CredentialParameterDto, len(SupportedAlgorithms))
+	for i, alg := range SupportedAlgorithms {
+		params[i] = CredentialParameterDto{Type: publicKeyCredentialType, Alg: alg}
+	}
+
+	return CreationOptionsDto{
+		Rp:                 RelyingPartyDto{Id: cfg.RpId(), Name: cfg.RpName()},
+		User:               UserEntityDto{Id: encodeBase64Url([]byte(s.userId)), Name: username, DisplayName: username},
+		Challenge:          s.challenge,
+		PubKeyCredParams:   params,
+		Timeout:            cfg.Timeout().Milliseconds(),
+		ExcludeCredentials: exclude,
+		AuthenticatorSelection: AuthenticatorSelectionDto{
+			ResidentKey:      "preferred",
+			UserVerification: s.userVerification,
+		},
+		Attestation: "direct",
+	}
+}
+
+// RequestOptions builds options passed to navigator.credentials.get()
+func (s *Session) RequestOptions(allow []CredentialDescriptorDto, cfg valueobj.WebauthnConfig) RequestOptionsDto {
+	return RequestOptionsDto{
+		Challenge:        s.challenge,
+		Timeout:          cfg.Timeout().Milliseconds(),
+		RpId:             cfg.RpId(),
+		AllowCredentials: allow,
+		UserVerification: s.userVerification,
+	}
+}
+
+func (s *Session) verify(ceremony string, now time.Time) error {
+	if s.ceremony != ceremony {
+		return errors.Wrapf(VerificationFailedErr, "session was started for %s ceremony", s.ceremony)
+	}
+
+	if s.expiresAt.Before(now) {
+		return SessionExpiredErr
+	}
+	return nil
+}
+
+func (s *Session) verifyFlags(authData AuthenticatorData) error {
+	if !authData.UserPresent() {
+		return errors.Wrap(VerificationFailedErr, "user presence is not confirmed")
+	}
+
+	if s.userVerification == UserVerificationRequired && !authData.UserVerified() {
+		return errors.Wrap(VerificationFailedErr, "user verification is required")
+	}
+	return nil
+}
+
+func (s *Session) Dto() SessionDto {
+	return SessionDto{
+		Challenge:        s.challenge,
+		Ceremony:         s.ceremony,
+		UserId:           s.userId,
+		AllowedIds:       s.allowedIds,
+		UserVerification: s.userVerification,
+		ExpiresAt:        s.expiresAt,
+	}
+}
@@ -0,0 +1,368 @@
+package webauthn
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	valueobj "github.com/umalmyha/authsrv/internal/business/value-object"
+	"github.com/umalmyha/authsrv/pkg/cbor"
+)
+
+const (
+	success = "\u2713"
+	failed  = "\u2717"
+)
+
+const (
+	testRpId   = "auth.example.com"
+	testOrigin = "https://auth.example.com"
+	testUserId = "5f1b6b8e-2c1d-4d1a-9a57-1f0b8a3c9e11"
+)
+
+var testAaguid = []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f, 0x10}
+
+// softAuthenticator emulates platform authenticator holding single P-256 credential
+type softAuthenticator struct {
+	key       *ecdsa.PrivateKey
+	id        []byte
+	signCount uint32
+	rpId      string
+	origin    string
+}
+
+func newSoftAuthenticator(fatalFn func(error)) *softAuthenticator {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		fatalFn(err)
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		fatalFn(err)
+	}
+	return &softAuthenticator{key: key, id: id, rpId: testRpId, origin: testOrigin}
+}
+
+func (a *softAuthenticator) coseKey() []byte {
+	x := make([]byte, 32)
+	y := make([]byte, 32)
+	a.key.X.FillBytes(x)
+	a.key.Y.FillBytes(y)
+
+	encoded, _ := cbor.Marshal(map[any]any{
+		int64(coseKeyKty): int64(coseKtyEc2),
+		int64(coseKeyAlg): AlgES256,
+		int64(coseKeyCrv): int64(coseCrvP256),
+		int64(coseKeyX):   x,
+		int64(coseKeyY):   y,
+	})
+	return encoded
+}
+
+func (a *softAuthenticator) authData(flags byte, attested bool) []byte {
+	rpIdHash := sha256.Sum256([]byte(a.rpId))
+	data := append([]byte{}, rpIdHash[:]...)
+	data = append(data, flags)
+
+	counter := make([]byte, 4)
+	binary.BigEndian.PutUint32(counter, a.signCount)
+	data = append(data, counter...)
+
+	if attested {
+		idLen := make([]byte, 2)
+		binary.BigEndian.PutUint16(idLen, uint16(len(a.id)))
+		data = append(data, testAaguid...)
+		data = append(data, idLen...)
+		data = append(data, a.id...)
+		data = append(data, a.coseKey()...)
+	}
+	return data
+}
+
+func (a *softAuthenticator) clientData(ceremony string, challenge string) []byte {
+	data, _ := json.Marshal(CollectedClientData{Type: ceremony, Challenge: challenge, Origin: a.origin})
+	return data
+}
+
+func (a *softAuthenticator) sign(authData []byte, clientData []byte, fatalFn func(error)) []byte {
+	digest := sha256.Sum256(signedData(authData, clientData))
+	sig, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	if err != nil {
+		fatalFn(err)
+	}
+	return sig
+}
+
+func (a *softAuthenticator) create(challenge string, format string, attStmtFn func([]byte, []byte) map[any]any, fatalFn func(error)) RegistrationResponseDto {
+	clientData := a.clientData(CeremonyRegistration, challenge)
+	authData := a.authData(flagUserPresent|flagUserVerified|flagAttestedData, true)
+
+	attStmt := map[any]any{}
+	if attStmtFn != nil {
+		attStmt = attStmtFn(authData, clientData)
+	}
+
+	attestation, err := cbor.Marshal(map[any]any{"fmt": format, "attStmt": attStmt, "authData": authData})
+	if err != nil {
+		fatalFn(err)
+	}
+
+	return RegistrationResponseDto{
+		Id:    encodeBase64Url(a.id),
+		RawId: encodeBase64Url(a.id),
+		Type:  publicKeyCredentialType,
+		Response: AttestationResponseDto{
+			ClientDataJson:    encodeBase64Url(clientData),
+			AttestationObject: encodeBase64Url(attestation),
+			Transports:        []string{"internal"},
+		},
+		Name: "Laptop",
+	}
+}
+
+func (a *softAuthenticator) get(challenge string, flags byte, fatalFn func(error)) AssertionResponseDto {
+	a.signCount++
+	clientData := a.clientData(CeremonyAssertion, challenge)
+	authData := a.authData(flags, false)
+
+	return AssertionResponseDto{
+		Id:    encodeBase64Url(a.id),
+		RawId: encodeBase64Url(a.id),
+		Type:  publicKeyCredentialType,
+		Response: AssertionDataDto{
+			ClientDataJson:    encodeBase64Url(clientData),
+			AuthenticatorData: encodeBase64Url(authData),
+			Signature:         encodeBase64Url(a.sign(authData, clientData, fatalFn)),
+			UserHandle:        encodeBase64Url([]byte(testUserId)),
+		},
+	}
+}
+
+func newTestConfig(fatalFn func(error)) valueobj.WebauthnConfig {
+	cfg, err := valueobj.NewWebauthnConfig(testRpId, "Auth", []string{testOrigin}, time.Minute)
+	if err != nil {
+		fatalFn(err)
+	}
+	return cfg
+}
+
+func registerTestCredential(a *softAuthenticator, now time.Time, cfg valueobj.WebauthnConfig, fatalFn func(error)) *Credential {
+	session, err := NewRegistrationSession(testUserId, now, cfg)
+	if err != nil {
+		fatalFn(err)
+	}
+
+	cred, err := VerifyRegistration(session, a.create(session.Challenge(), AttestationNone, nil, fatalFn), cfg, now)
+	if err != nil {
+		fatalFn(err)
+	}
+	return cred
+}
+
+func TestVerifyRegistration(t *testing.T) {
+	fatalFn := func(err error) { t.Fatal(err) }
+	cfg := newTestConfig(fatalFn)
+	now := time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	t.Log("Given the need to test webauthn credential registration")
+	{
+		t.Logf("\tTest 1:\tWhen authenticator returns none attestation")
+		{
+			auth := newSoftAuthenticator(fatalFn)
+			session, err := NewRegistrationSession(testUserId, now, cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			cred, err := VerifyRegistration(session, auth.create(session.Challenge(), AttestationNone, nil, fatalFn), cfg, now)
+			if err != nil {
+				t.Fatalf("\t%s\tCredential must be registered, but got %v", failed, err)
+			}
+			t.Logf("\t%s\tCredential must be registered", success)
+
+			if cred.Id() != encodeBase64Url(auth.id) || cred.UserId() != testUserId || cred.Name() != "Laptop" {
+				t.Fatalf("\t%s\tCredential must keep id, owner and name", failed)
+			}
+			t.Logf("\t%s\tCredential must keep id, owner and name", success)
+		}
+
+		t.Logf("\tTest 2:\tWhen authenticator returns packed self attestation")
+		{
+			auth := newSoftAuthenticator(fatalFn)
+			session, _ := NewRegistrationSession(testUserId, now, cfg)
+
+			selfAttestation := func(authData []byte, clientData []byte) map[any]any {
+				return map[any]any{"alg": AlgES256, "sig": auth.sign(authData, clientData, fatalFn)}
+			}
+
+			if _, err := VerifyRegistration(session, auth.create(session.Challenge(), AttestationPacked, selfAttestation, fatalFn), cfg, now); err != nil {
+				t.Fatalf("\t%s\tSelf attestation must be accepted, but got %v", failed, err)
+			}
+			t.Logf("\t%s\tSelf attestation must be accepted", success)
+
+			forged := func(authData []byte, clientData []byte) map[any]any {
+				other := newSoftAuthenticator(fatalFn)
+				return map[any]any{"alg": AlgES256, "sig": other.sign(authData, clientData, fatalFn)}
+			}
+
+			session, _ = NewRegistrationSession(testUserId, now, cfg)
+			if _, err := VerifyRegistration(session, auth.create(session.Challenge(), AttestationPacked, forged, fatalFn), cfg, now); !errors.Is(err, VerificationFailedErr) {
+				t.Fatalf("\t%s\tSelf attestation signed by another key must be rejected", failed)
+			}
+			t.Logf("\t%s\tSelf attestation signed by another key must be rejected", success)
+		}
+
+		t.Logf("\tTest 3:\tWhen authenticator returns packed attestation with certificate")
+		{
+			auth := newSoftAuthenticator(fatalFn)
+			attKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			aaguidExt, _ := asn1.Marshal(testAaguid)
+			tmpl := &x509.Certificate{
+				SerialNumber:          big.NewInt(1),
+				Subject:               pkix.Name{Organization: []string{"Soft"}, OrganizationalUnit: []string{"Authenticator Attestation"}, CommonName: "Soft Authenticator"},
+				NotBefore:             now.Add(-time.Hour),
+				NotAfter:              now.Add(time.Hour),
+				BasicConstraintsValid: true,
+				ExtraExtensions:       []pkix.Extension{{Id: aaguidExtensionOid, Value: aaguidExt}},
+			}
+
+			der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &attKey.PublicKey, attKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			fullAttestation := func(authData []byte, clientData []byte) map[any]any {
+				digest := sha256.Sum256(signedData(authData, clientData))
+				sig, err := ecdsa.SignASN1(rand.Reader, attKey, digest[:])
+				if err != nil {
+					t.Fatal(err)
+				}
+				return map[any]any{"alg": AlgES256, "sig": sig, "x5c": []any{der}}
+			}
+
+			session, _ := NewRegistrationSession(testUserId, now, cfg)
+			if _, err := VerifyRegistration(session, auth.create(session.Challenge(), AttestationPacked, fullAttestation, fatalFn), cfg, now); err != nil {
+				t.Fatalf("\t%s\tAttestation signed by certificate key must be accepted, but got %v", failed, err)
+			}
+			t.Logf("\t%s\tAttestation signed by certificate key must be accepted", success)
+		}
+
+		t.Logf("\tTest 4:\tWhen response doesn't match registration session")
+		{
+			auth := newSoftAuthenticator(fatalFn)
+			session, _ := NewRegistrationSession(testUserId, now, cfg)
+
+			if _, err := VerifyRegistration(session, auth.create("other-challenge", AttestationNone, nil, fatalFn), cfg, now); !errors.Is(err, VerificationFailedErr) {
+				t.Fatalf("\t%s\tResponse to another challenge must be rejected", failed)
+			}
+			t.Logf("\t%s\tResponse to another challenge must be rejected", success)
+
+			auth.origin = "https://evil.example.com"
+			if _, err := VerifyRegistration(session, auth.create(session.Challenge(), AttestationNone, nil, fatalFn), cfg, now); !errors.Is(err, VerificationFailedErr) {
+				t.Fatalf("\t%s\tResponse from unknown origin must be rejected", failed)
+			}
+			t.Logf("\t%s\tResponse from unknown origin must be rejected", success)
+
+			auth.origin = testOrigin
+			auth.rpId = "evil.example.com"
+			if _, err := VerifyRegistration(session, auth.create(session.Challenge(), AttestationNone, nil, fatalFn), cfg, now); !errors.Is(err, VerificationFailedErr) {
+				t.Fatalf("\t%s\tCredential scoped to another rp id must be rejected", failed)
+			}
+			t.Logf("\t%s\tCredential scoped to another rp id must be rejected", success)
+
+			auth.rpId = testRpId
+			if _, err := VerifyRegistration(session, auth.create(session.Challenge(), AttestationNone, nil, fatalFn), cfg, now.Add(2*time.Minute)); !errors.Is(err, SessionExpiredErr) {
+				t.Fatalf("\t%s\tResponse after session expiration must be rejected", failed)
+			}
+			t.Logf("\t%s\tResponse after session expiration must be rejected", success)
+		}
+	}
+}
+
+func TestVerifyAssertion(t *testing.T) {
+	fatalFn := func(err error) { t.Fatal(err) }
+	cfg := newTestConfig(fatalFn)
+	now := time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	t.Log("Given the need to test webauthn assertion")
+	{
+		t.Logf("\tTest 1:\tWhen registered authenticator signs challenge")
+		{
+			auth := newSoftAuthenticator(fatalFn)
+			cred := registerTestCredential(auth, now, cfg, fatalFn)
+
+			session, _ := NewAssertionSession("", nil, now, cfg)
+			if err := cred.VerifyAssertion(session, auth.get(session.Challenge(), flagUserPresent|flagUserVerified, fatalFn), cfg, now); err != nil {
+				t.Fatalf("\t%s\tAssertion must be verified, but got %v", failed, err)
+			}
+			t.Logf("\t%s\tAssertion must be verified", success)
+
+			if cred.SignCount() != auth.signCount || cred.Info().LastUsedAt == nil {
+				t.Fatalf("\t%s\tSign count and last usage must be updated", failed)
+			}
+			t.Logf("\t%s\tSign count and last usage must be updated", success)
+		}
+
+		t.Logf("\tTest 2:\tWhen user isn't verified or another credential answers")
+		{
+			auth := newSoftAuthenticator(fatalFn)
+			cred := registerTestCredential(auth, now, cfg, fatalFn)
+
+			session, _ := NewAssertionSession(testUserId, []string{cred.Id()}, now, cfg)
+			if err := cred.VerifyAssertion(session, auth.get(session.Challenge(), flagUserPresent, fatalFn), cfg, now); !errors.Is(err, VerificationFailedErr) {
+				t.Fatalf("\t%s\tAssertion without user verification must be rejected", failed)
+			}
+			t.Logf("\t%s\tAssertion without user verification must be rejected", success)
+
+			other := newSoftAuthenticator(fatalFn)
+			if err := cred.VerifyAssertion(session, other.get(session.Challenge(), flagUserPresent|flagUserVerified, fatalFn), cfg, now); !errors.Is(err, VerificationFailedErr) {
+				t.Fatalf("\t%s\tAssertion of another credential must be rejected", failed)
+			}
+			t.Logf("\t%s\tAssertion of another credential must be rejected", success)
+
+			other.id = auth.id
+			if err := cred.VerifyAssertion(session, other.get(session.Challenge(), flagUserPresent|flagUserVerified, fatalFn), cfg, now); !errors.Is(err, VerificationFailedErr) {
+				t.Fatalf("\t%s\tAssertion signed by another key must be rejected", failed)
+			}
+			t.Logf("\t%s\tAssertion signed by another key must be rejected", success)
+		}
+
+		t.Logf("\tTest 3:\tWhen sign count doesn't grow")
+		{
+			auth := newSoftAuthenticator(fatalFn)
+			cred := registerTestCredential(auth, now, cfg, fatalFn)
+
+			session, _ := NewAssertionSession("", nil, now, cfg)
+			auth.signCount = 10
+			if err := cred.VerifyAssertion(session, auth.get(session.Challenge(), flagUserPresent|flagUserVerified, fatalFn), cfg, now); err != nil {
+				t.Fatal(err)
+			}
+
+			auth.signCount = 5
+			if err := cred.VerifyAssertion(session, auth.get(session.Challenge(), flagUserPresent|flagUserVerified, fatalFn), cfg, now); !errors.Is(err, CredentialClonedErr) {
+				t.Fatalf("\t%s\tAssertion with lower sign count must be rejected as cloned", failed)
+			}
+			t.Logf("\t%s\tAssertion with lower sign count must be rejected as cloned", success)
+
+			if cred.SignCount() != 11 {
+				t.Fatalf("\t%s\tRejected assertion mustn't change sign count", failed)
+			}
+			t.Logf("\t%s\tRejected assertion mustn't change sign count", success)
+		}
+	}
+}
@@ -9,3 +9,14 @@ func KeyValue(arg string) (string, string) {
 	}
 	return keValue[0], keValue[1]
 }
+
+// ListValue splits comma-separated option value, empty items are skipped
+func ListValue(value string) []string {
+	list := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
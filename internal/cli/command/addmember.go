@@ -0,0 +1,105 @@
+package command
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/umalmyha/authsrv/internal/cli/args"
+	"github.com/umalmyha/authsrv/internal/cli/input"
+	"github.com/umalmyha/authsrv/internal/infra"
+	"github.com/umalmyha/authsrv/internal/infra/service"
+)
+
+type addMemberCommand struct {
+	*LoggingCommand
+	args args.ParsedArgs
+}
+
+type addMemberCommandOptions struct {
+	org      string
+	username string
+	help     bool
+}
+
+func NewAddMemberCommand(args args.ParsedArgs, logger *log.Logger) Executor {
+	return &addMemberCommand{
+		LoggingCommand: &LoggingCommand{logger: logger},
+		args:           args,
+	}
+}
+
+func (c *addMemberCommand) Run() error {
+	options := c.extractOptions()
+	if options.help {
+		c.Help()
+		return nil
+	}
+
+	var err error
+	username := options.username
+	if username == "" {
+		username, err = input.NewSimpleInput(input.Config{Prompt: "username", IsMandatory: true}).Read()
+		if err != nil {
+			return err
+		}
+	}
+
+	org := options.org
+	if org == "" {
+		org, err = input.NewSimpleInput(input.Config{Prompt: "to organization", IsMandatory: true}).Read()
+		if err != nil {
+			return err
+		}
+	}
+
+	db, err := infra.ConnectToDb()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := service.NewOrganizationService(db).AddMember(ctx, org, username); err != nil {
+		return err
+	}
+
+	logger := c.Logger()
+	logger.Printf("user %s is added to organization %s successfully", username, org)
+	logger.Println()
+
+	return nil
+}
+
+func (c *addMemberCommand) Help() {
+	logger := c.Logger()
+	logger.Println("addmember - command adds user to organization")
+	logger.Println("options:")
+	logger.Println("  --help - show help")
+	logger.Println("  --user - specify username")
+	logger.Println("  --to - specify organization name")
+	logger.Println("example:")
+	logger.Println("  addmember --user=username1 --to=org1")
+}
+
+func (c *addMemberCommand) extractOptions() addMemberCommandOptions {
+	options := addMemberCommandOptions{}
+
+	iter := c.args.Iterator()
+	for iter.HasNext() {
+		nextOpt := iter.Next()
+		option, value := args.KeyValue(nextOpt)
+		switch option {
+		case "--help":
+			options.help = true
+		case "--user":
+			options.username = value
+		case "--to":
+			options.org = value
+		}
+	}
+
+	return options
+}
@@ -0,0 +1,105 @@
+package command
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/umalmyha/authsrv/internal/cli/args"
+	"github.com/umalmyha/authsrv/internal/cli/input"
+	"github.com/umalmyha/authsrv/internal/infra"
+	"github.com/umalmyha/authsrv/internal/infra/service"
+)
+
+type assignGroupRoleCommand struct {
+	*LoggingCommand
+	args args.ParsedArgs
+}
+
+type assignGroupRoleCommandOptions struct {
+	role  string
+	group string
+	help  bool
+}
+
+func NewAssignGroupRoleCommand(args args.ParsedArgs, logger *log.Logger) Executor {
+	return &assignGroupRoleCommand{
+		LoggingCommand: &LoggingCommand{logger: logger},
+		args:           args,
+	}
+}
+
+func (c *assignGroupRoleCommand) Run() error {
+	options := c.extractOptions()
+	if options.help {
+		c.Help()
+		return nil
+	}
+
+	var err error
+	roleName := options.role
+	if roleName == "" {
+		roleName, err = input.NewSimpleInput(input.Config{Prompt: "role", IsMandatory: true}).Read()
+		if err != nil {
+			return err
+		}
+	}
+
+	grp := options.group
+	if grp == "" {
+		grp, err = input.NewSimpleInput(input.Config{Prompt: "to group", IsMandatory: true}).Read()
+		if err != nil {
+			return err
+		}
+	}
+
+	db, err := infra.ConnectToDb()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := service.NewGroupService(db).AssignRole(ctx, grp, roleName); err != nil {
+		return err
+	}
+
+	logger := c.Logger()
+	logger.Printf("role '%s' is assigned to group %s successfully", roleName, grp)
+	logger.Println()
+
+	return nil
+}
+
+func (c *assignGroupRoleCommand) Help() {
+	logger := c.Logger()
+	logger.Println("assigngrouprole - command assigns role to group, role becomes effective for all members of group")
+	logger.Println("options:")
+	logger.Println("  --help - show help")
+	logger.Println("  --role - specify role name")
+	logger.Println("  --to - specify group name")
+	logger.Println("example:")
+	logger.Println("  assigngrouprole --role=role1 --to=group1")
+}
+
+func (c *assignGroupRoleCommand) extractOptions() assignGroupRoleCommandOptions {
+	options := assignGroupRoleCommandOptions{}
+
+	iter := c.args.Iterator()
+	for iter.HasNext() {
+		nextOpt := iter.Next()
+		option, value := args.KeyValue(nextOpt)
+		switch option {
+		case "--help":
+			options.help = true
+		case "--role":
+			options.role = value
+		case "--to":
+			options.group = value
+		}
+	}
+
+	return options
+}
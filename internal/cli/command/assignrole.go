@@ -5,6 +5,8 @@ import (
 	"log"
 	"time"
 
+	"github.com/pkg/errors"
+
 	"github.com/umalmyha/authsrv/internal/cli/args"
 	"github.com/umalmyha/authsrv/internal/cli/input"
 	"github.com/umalmyha/authsrv/internal/infra"
@@ -18,9 +20,12 @@ type assignRoleCommand struct {
 }
 
 type assignRoleCommandOptions struct {
-	role     string
-	username string
-	help     bool
+	role       string
+	username   string
+	org        string
+	validFrom  string
+	validUntil string
+	help       bool
 }
 
 func NewAssignRoleCommand(args args.ParsedArgs, logger *log.Logger) Executor {
@@ -54,6 +59,20 @@ func (c *assignRoleCommand) Run() error {
 		}
 	}
 
+	validFrom, err := parseOptionalTime(options.validFrom)
+	if err != nil {
+		return errors.Wrap(err, "valid from must be in RFC3339 format")
+	}
+
+	validUntil, err := parseOptionalTime(options.validUntil)
+	if err != nil {
+		return errors.Wrap(err, "valid until must be in RFC3339 format")
+	}
+
+	if options.org != "" && (validFrom != nil || validUntil != nil) {
+		return errors.New("validity window is supported only for roles assigned outside of organization")
+	}
+
 	db, err := infra.ConnectToDb()
 	if err != nil {
 		return err
@@ -73,11 +92,23 @@ func (c *assignRoleCommand) Run() error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	if err := service.NewUserService(db, rdb).AssignRole(ctx, username, roleName); err != nil {
+	logger := c.Logger()
+
+	// role assigned within organization is effective only in sessions issued for it
+	if options.org != "" {
+		if err := service.NewOrganizationService(db).AssignRole(ctx, options.org, username, roleName); err != nil {
+			return err
+		}
+
+		logger.Printf("role '%s' is assigned to user %s in organization %s successfully", roleName, username, options.org)
+		logger.Println()
+		return nil
+	}
+
+	if err := service.NewUserService(db, rdb).AssignRole(ctx, username, roleName, validFrom, validUntil); err != nil {
 		return err
 	}
 
-	logger := c.Logger()
 	logger.Printf("role '%s' is assigned to user %s successfully", roleName, username)
 	logger.Println()
 
@@ -91,8 +122,13 @@ func (c *assignRoleCommand) Help() {
 	logger.Println("  --help - show help")
 	logger.Println("  --role - specify role name")
 	logger.Println("  --to - specify username")
+	logger.Println("  --org - specify organization, role is assigned within it")
+	logger.Println("  --valid-from - specify moment in RFC3339 format role becomes effective at")
+	logger.Println("  --valid-until - specify moment in RFC3339 format role expires at")
 	logger.Println("example:")
 	logger.Println("  assignrole --role=role1 --to=username1")
+	logger.Println("  assignrole --role=role1 --to=username1 --org=org1")
+	logger.Println("  assignrole --role=role1 --to=username1 --valid-until=2030-01-01T00:00:00Z")
 }
 
 func (c *assignRoleCommand) extractOptions() assignRoleCommandOptions {
@@ -109,8 +145,26 @@ func (c *assignRoleCommand) extractOptions() assignRoleCommandOptions {
 			options.role = value
 		case "--to":
 			options.username = value
+		case "--org":
+			options.org = value
+		case "--valid-from":
+			options.validFrom = value
+		case "--valid-until":
+			options.validUntil = value
 		}
 	}
 
 	return options
 }
+
+func parseOptionalTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
@@ -0,0 +1,130 @@
+package command
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/umalmyha/authsrv/internal/business/client"
+	"github.com/umalmyha/authsrv/internal/cli/args"
+	"github.com/umalmyha/authsrv/internal/cli/input"
+	"github.com/umalmyha/authsrv/internal/infra"
+	"github.com/umalmyha/authsrv/internal/infra/service"
+)
+
+type createClientCommand struct {
+	*LoggingCommand
+	args args.ParsedArgs
+}
+
+type createClientCommandOptions struct {
+	name         string
+	confidential bool
+	redirectUris []string
+	grantTypes   []string
+	scopes       []string
+	help         bool
+}
+
+func NewCreateClientCommand(args args.ParsedArgs, logger *log.Logger) Executor {
+	return &createClientCommand{
+		LoggingCommand: &LoggingCommand{logger: logger},
+		args:           args,
+	}
+}
+
+func (c *createClientCommand) Run() error {
+	options := c.extractOptions()
+	if options.help {
+		c.Help()
+		return nil
+	}
+
+	var err error
+	name := options.name
+	if name == "" {
+		name, err = input.NewSimpleInput(input.Config{Prompt: "name", IsMandatory: true}).Read()
+		if err != nil {
+			return err
+		}
+	}
+
+	passCfg, err := infra.PasswordConfig()
+	if err != nil {
+		return err
+	}
+
+	db, err := infra.ConnectToDb()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	nc := client.NewClientDto{
+		Name:         name,
+		Confidential: options.confidential,
+		RedirectUris: options.redirectUris,
+		GrantTypes:   options.grantTypes,
+		Scopes:       options.scopes,
+	}
+
+	srv := service.NewClientService(db, passCfg)
+	clientId, secret, err := srv.CreateClient(ctx, nc)
+	if err != nil {
+		return err
+	}
+
+	logger := c.Logger()
+	logger.Printf("client '%s' is created successfully, client id: %s", name, clientId)
+	if secret != "" {
+		logger.Printf("client secret: %s", secret)
+		logger.Println("store the secret now, it can't be shown again")
+	}
+	logger.Println()
+
+	return nil
+}
+
+func (c *createClientCommand) Help() {
+	logger := c.Logger()
+	logger.Println("createclient - command registers new OAuth client")
+	logger.Println("options:")
+	logger.Println("  --help - show help")
+	logger.Println("  --name - specify client name")
+	logger.Println("  --confidential - generate client secret, required for client_credentials grant")
+	logger.Println("  --redirect - specify comma-separated list of allowed redirect uris")
+	logger.Println("  --grants - specify comma-separated list of grant types (authorization_code,refresh_token by default)")
+	logger.Println("  --scopes - specify comma-separated list of scopes client is allowed to request for itself")
+	logger.Println("example:")
+	logger.Println("  createclient --name=spa --redirect=https://app.example.com/callback")
+	logger.Println("  createclient --name=billing --confidential --grants=client_credentials --scopes=users:read")
+}
+
+func (c *createClientCommand) extractOptions() createClientCommandOptions {
+	options := createClientCommandOptions{}
+
+	iter := c.args.Iterator()
+	for iter.HasNext() {
+		nextOpt := iter.Next()
+		option, value := args.KeyValue(nextOpt)
+		switch option {
+		case "--help":
+			options.help = true
+		case "--name":
+			options.name = value
+		case "--confidential":
+			options.confidential = true
+		case "--redirect":
+			options.redirectUris = args.ListValue(value)
+		case "--grants":
+			options.grantTypes = args.ListValue(value)
+		case "--scopes":
+			options.scopes = args.ListValue(value)
+		}
+	}
+
+	return options
+}
@@ -0,0 +1,103 @@
+package command
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/umalmyha/authsrv/internal/business/group"
+	"github.com/umalmyha/authsrv/internal/cli/args"
+	"github.com/umalmyha/authsrv/internal/cli/input"
+	"github.com/umalmyha/authsrv/internal/infra"
+	"github.com/umalmyha/authsrv/internal/infra/service"
+)
+
+type createGroupCommand struct {
+	*LoggingCommand
+	args args.ParsedArgs
+}
+
+type createGroupCommandOptions struct {
+	name        string
+	description string
+	help        bool
+}
+
+func NewCreateGroupCommand(args args.ParsedArgs, logger *log.Logger) Executor {
+	return &createGroupCommand{
+		LoggingCommand: &LoggingCommand{logger: logger},
+		args:           args,
+	}
+}
+
+func (c *createGroupCommand) Run() error {
+	options := c.extractOptions()
+	if options.help {
+		c.Help()
+		return nil
+	}
+
+	var err error
+	name := options.name
+	if name == "" {
+		name, err = input.NewSimpleInput(input.Config{Prompt: "name", IsMandatory: true}).Read()
+		if err != nil {
+			return err
+		}
+	}
+
+	db, err := infra.ConnectToDb()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	ng := group.NewGroupDto{Name: name}
+	if options.description != "" {
+		ng.Description = &options.description
+	}
+
+	if _, err := service.NewGroupService(db).CreateGroup(ctx, ng); err != nil {
+		return err
+	}
+
+	logger := c.Logger()
+	logger.Printf("group '%s' is created successfully", name)
+	logger.Println()
+
+	return nil
+}
+
+func (c *createGroupCommand) Help() {
+	logger := c.Logger()
+	logger.Println("creategroup - command creates new group of users")
+	logger.Println("options:")
+	logger.Println("  --help - show help")
+	logger.Println("  --name - specify group name")
+	logger.Println("  --description - specify group description")
+	logger.Println("example:")
+	logger.Println("  creategroup --name=group1 --description=\"First group\"")
+}
+
+func (c *createGroupCommand) extractOptions() createGroupCommandOptions {
+	options := createGroupCommandOptions{}
+
+	iter := c.args.Iterator()
+	for iter.HasNext() {
+		nextOpt := iter.Next()
+		option, value := args.KeyValue(nextOpt)
+		switch option {
+		case "--help":
+			options.help = true
+		case "--name":
+			options.name = value
+		case "--description":
+			options.description = value
+		}
+	}
+
+	return options
+}
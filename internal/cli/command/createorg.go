@@ -0,0 +1,103 @@
+package command
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/umalmyha/authsrv/internal/business/organization"
+	"github.com/umalmyha/authsrv/internal/cli/args"
+	"github.com/umalmyha/authsrv/internal/cli/input"
+	"github.com/umalmyha/authsrv/internal/infra"
+	"github.com/umalmyha/authsrv/internal/infra/service"
+)
+
+type createOrgCommand struct {
+	*LoggingCommand
+	args args.ParsedArgs
+}
+
+type createOrgCommandOptions struct {
+	name        string
+	description string
+	help        bool
+}
+
+func NewCreateOrgCommand(args args.ParsedArgs, logger *log.Logger) Executor {
+	return &createOrgCommand{
+		LoggingCommand: &LoggingCommand{logger: logger},
+		args:           args,
+	}
+}
+
+func (c *createOrgCommand) Run() error {
+	options := c.extractOptions()
+	if options.help {
+		c.Help()
+		return nil
+	}
+
+	var err error
+	name := options.name
+	if name == "" {
+		name, err = input.NewSimpleInput(input.Config{Prompt: "name", IsMandatory: true}).Read()
+		if err != nil {
+			return err
+		}
+	}
+
+	db, err := infra.ConnectToDb()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	no := organization.NewOrganizationDto{Name: name}
+	if options.description != "" {
+		no.Description = &options.description
+	}
+
+	if _, err := service.NewOrganizationService(db).CreateOrganization(ctx, no); err != nil {
+		return err
+	}
+
+	logger := c.Logger()
+	logger.Printf("organization '%s' is created successfully", name)
+	logger.Println()
+
+	return nil
+}
+
+func (c *createOrgCommand) Help() {
+	logger := c.Logger()
+	logger.Println("createorg - command creates new organization")
+	logger.Println("options:")
+	logger.Println("  --help - show help")
+	logger.Println("  --name - specify organization name")
+	logger.Println("  --description - specify organization description")
+	logger.Println("example:")
+	logger.Println("  createorg --name=org1 --description=\"First organization\"")
+}
+
+func (c *createOrgCommand) extractOptions() createOrgCommandOptions {
+	options := createOrgCommandOptions{}
+
+	iter := c.args.Iterator()
+	for iter.HasNext() {
+		nextOpt := iter.Next()
+		option, value := args.KeyValue(nextOpt)
+		switch option {
+		case "--help":
+			options.help = true
+		case "--name":
+			options.name = value
+		case "--description":
+			options.description = value
+		}
+	}
+
+	return options
+}
@@ -87,15 +87,30 @@ func (c *createUserCommand) Run() error {
 		return err
 	}
 
+	mfaCfg, err := infra.MfaConfig()
+	if err != nil {
+		return err
+	}
+
+	lockoutCfg, err := infra.LockoutConfig()
+	if err != nil {
+		return err
+	}
+
+	emailCfg, err := infra.EmailVerificationConfig()
+	if err != nil {
+		return err
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	srv := service.NewAuthService(db, rdb, jwtCfg, rfrCfg, passCfg)
+	srv := service.NewAuthService(db, rdb, jwtCfg, rfrCfg, passCfg, mfaCfg, lockoutCfg, emailCfg)
 	nu := user.NewUserDto{
 		Username:        username,
 		Password:        password,
 		ConfirmPassword: password,
-		IsSuperuser:     false,
+		IsSuperuser:     options.isSuper,
 	}
 	if err := srv.Signup(ctx, nu); err != nil {
 		return err
@@ -0,0 +1,123 @@
+package command
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	valueobj "github.com/umalmyha/authsrv/internal/business/value-object"
+	"github.com/umalmyha/authsrv/internal/cli/args"
+	"github.com/umalmyha/authsrv/internal/cli/input"
+	"github.com/umalmyha/authsrv/internal/infra"
+	"github.com/umalmyha/authsrv/internal/infra/service"
+)
+
+type deleteRoleCommand struct {
+	*LoggingCommand
+	args args.ParsedArgs
+}
+
+type deleteRoleCommandOptions struct {
+	name    string
+	cascade bool
+	help    bool
+}
+
+func NewDeleteRoleCommand(args args.ParsedArgs, logger *log.Logger) Executor {
+	return &deleteRoleCommand{
+		LoggingCommand: &LoggingCommand{logger: logger},
+		args:           args,
+	}
+}
+
+func (c *deleteRoleCommand) Run() error {
+	options := c.extractOptions()
+	if options.help {
+		c.Help()
+		return nil
+	}
+
+	var err error
+	name := options.name
+	if name == "" {
+		name, err = input.NewSimpleInput(input.Config{Prompt: "name", IsMandatory: true}).Read()
+		if err != nil {
+			return err
+		}
+	}
+
+	db, err := infra.ConnectToDb()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	policy := valueobj.DeleteReject
+	if options.cascade {
+		policy = valueobj.DeleteCascade
+	}
+
+	logger := c.Logger()
+
+	report, err := service.NewRoleService(db).DeleteRoleByName(ctx, name, policy)
+	if err != nil {
+		if errors.Is(err, service.RoleInUseErr) {
+			logger.Printf("role '%s' is assigned to users: %s", name, strings.Join(report.Users, ","))
+			logger.Printf("role '%s' is assigned to groups: %s", name, strings.Join(report.Groups, ","))
+			logger.Printf("role '%s' is inherited by roles: %s", name, strings.Join(report.Children, ","))
+			logger.Println("use --cascade to unassign it from users and groups, remove inheritance and delete anyway")
+		}
+		return err
+	}
+
+	logger.Printf("role '%s' is deleted successfully", name)
+	if len(report.Users) > 0 {
+		logger.Printf("role is unassigned from users: %s", strings.Join(report.Users, ","))
+	}
+	if len(report.Groups) > 0 {
+		logger.Printf("role is unassigned from groups: %s", strings.Join(report.Groups, ","))
+	}
+	if len(report.Children) > 0 {
+		logger.Printf("roles don't inherit from role anymore: %s", strings.Join(report.Children, ","))
+	}
+	logger.Println()
+
+	return nil
+}
+
+func (c *deleteRoleCommand) Help() {
+	logger := c.Logger()
+	logger.Println("deleterole - command deletes role, role assigned to users or groups or inherited by roles is deleted only with --cascade")
+	logger.Println("options:")
+	logger.Println("  --help - show help")
+	logger.Println("  --name - specify role name")
+	logger.Println("  --cascade - unassign role from users and remove inheritance of child roles before deletion")
+	logger.Println("example:")
+	logger.Println("  deleterole --name=role1 --cascade")
+}
+
+func (c *deleteRoleCommand) extractOptions() deleteRoleCommandOptions {
+	options := deleteRoleCommandOptions{}
+
+	iter := c.args.Iterator()
+	for iter.HasNext() {
+		nextOpt := iter.Next()
+		option, value := args.KeyValue(nextOpt)
+		switch option {
+		case "--help":
+			options.help = true
+		case "--name":
+			options.name = value
+		case "--cascade":
+			options.cascade = true
+		}
+	}
+
+	return options
+}
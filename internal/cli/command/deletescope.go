@@ -0,0 +1,123 @@
+package command
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	valueobj "github.com/umalmyha/authsrv/internal/business/value-object"
+	"github.com/umalmyha/authsrv/internal/cli/args"
+	"github.com/umalmyha/authsrv/internal/cli/input"
+	"github.com/umalmyha/authsrv/internal/infra"
+	"github.com/umalmyha/authsrv/internal/infra/service"
+)
+
+type deleteScopeCommand struct {
+	*LoggingCommand
+	args args.ParsedArgs
+}
+
+type deleteScopeCommandOptions struct {
+	name    string
+	cascade bool
+	help    bool
+}
+
+func NewDeleteScopeCommand(args args.ParsedArgs, logger *log.Logger) Executor {
+	return &deleteScopeCommand{
+		LoggingCommand: &LoggingCommand{logger: logger},
+		args:           args,
+	}
+}
+
+func (c *deleteScopeCommand) Run() error {
+	options := c.extractOptions()
+	if options.help {
+		c.Help()
+		return nil
+	}
+
+	var err error
+	name := options.name
+	if name == "" {
+		name, err = input.NewSimpleInput(input.Config{Prompt: "name", IsMandatory: true}).Read()
+		if err != nil {
+			return err
+		}
+	}
+
+	db, err := infra.ConnectToDb()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	policy := valueobj.DeleteReject
+	if options.cascade {
+		policy = valueobj.DeleteCascade
+	}
+
+	logger := c.Logger()
+
+	report, err := service.NewScopeService(db).DeleteScopeByName(ctx, name, policy)
+	if err != nil {
+		if errors.Is(err, service.ScopeInUseErr) {
+			logger.Printf("scope '%s' is assigned to roles: %s", name, strings.Join(report.Roles, ","))
+			logger.Printf("clients allowed to request scope: %s", strings.Join(report.Clients, ","))
+			logger.Printf("users granted with scope: %s", strings.Join(report.Users, ","))
+			logger.Println("use --cascade to unassign it from roles and clients and delete anyway")
+		}
+		return err
+	}
+
+	logger.Printf("scope '%s' is deleted successfully", name)
+	if len(report.Roles) > 0 {
+		logger.Printf("scope is unassigned from roles: %s", strings.Join(report.Roles, ","))
+	}
+	if len(report.Clients) > 0 {
+		logger.Printf("scope is unassigned from clients: %s", strings.Join(report.Clients, ","))
+	}
+	if len(report.Users) > 0 {
+		logger.Printf("scope is revoked from users: %s", strings.Join(report.Users, ","))
+	}
+	logger.Println()
+
+	return nil
+}
+
+func (c *deleteScopeCommand) Help() {
+	logger := c.Logger()
+	logger.Println("deletescope - command deletes scope, scope assigned to roles or clients is deleted only with --cascade")
+	logger.Println("options:")
+	logger.Println("  --help - show help")
+	logger.Println("  --name - specify scope name")
+	logger.Println("  --cascade - unassign scope from roles and clients before deletion")
+	logger.Println("example:")
+	logger.Println("  deletescope --name=scope1 --cascade")
+}
+
+func (c *deleteScopeCommand) extractOptions() deleteScopeCommandOptions {
+	options := deleteScopeCommandOptions{}
+
+	iter := c.args.Iterator()
+	for iter.HasNext() {
+		nextOpt := iter.Next()
+		option, value := args.KeyValue(nextOpt)
+		switch option {
+		case "--help":
+			options.help = true
+		case "--name":
+			options.name = value
+		case "--cascade":
+			options.cascade = true
+		}
+	}
+
+	return options
+}
@@ -0,0 +1,105 @@
+package command
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/umalmyha/authsrv/internal/cli/args"
+	"github.com/umalmyha/authsrv/internal/cli/input"
+	"github.com/umalmyha/authsrv/internal/infra"
+	"github.com/umalmyha/authsrv/internal/infra/service"
+)
+
+type disinheritRoleCommand struct {
+	*LoggingCommand
+	args args.ParsedArgs
+}
+
+type disinheritRoleCommandOptions struct {
+	role   string
+	parent string
+	help   bool
+}
+
+func NewDisinheritRoleCommand(args args.ParsedArgs, logger *log.Logger) Executor {
+	return &disinheritRoleCommand{
+		LoggingCommand: &LoggingCommand{logger: logger},
+		args:           args,
+	}
+}
+
+func (c *disinheritRoleCommand) Run() error {
+	options := c.extractOptions()
+	if options.help {
+		c.Help()
+		return nil
+	}
+
+	var err error
+	roleName := options.role
+	if roleName == "" {
+		roleName, err = input.NewSimpleInput(input.Config{Prompt: "role", IsMandatory: true}).Read()
+		if err != nil {
+			return err
+		}
+	}
+
+	parentName := options.parent
+	if parentName == "" {
+		parentName, err = input.NewSimpleInput(input.Config{Prompt: "parent role", IsMandatory: true}).Read()
+		if err != nil {
+			return err
+		}
+	}
+
+	db, err := infra.ConnectToDb()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := service.NewRoleService(db).DisinheritRole(ctx, roleName, parentName); err != nil {
+		return err
+	}
+
+	logger := c.Logger()
+	logger.Printf("role '%s' doesn't inherit from role %s anymore", roleName, parentName)
+	logger.Println()
+
+	return nil
+}
+
+func (c *disinheritRoleCommand) Help() {
+	logger := c.Logger()
+	logger.Println("disinheritrole - command stops role inheriting scopes of parent role")
+	logger.Println("options:")
+	logger.Println("  --help - show help")
+	logger.Println("  --role - specify role name")
+	logger.Println("  --from - specify parent role name")
+	logger.Println("example:")
+	logger.Println("  disinheritrole --role=role1 --from=role2")
+}
+
+func (c *disinheritRoleCommand) extractOptions() disinheritRoleCommandOptions {
+	options := disinheritRoleCommandOptions{}
+
+	iter := c.args.Iterator()
+	for iter.HasNext() {
+		nextOpt := iter.Next()
+		option, value := args.KeyValue(nextOpt)
+		switch option {
+		case "--help":
+			options.help = true
+		case "--role":
+			options.role = value
+		case "--from":
+			options.parent = value
+		}
+	}
+
+	return options
+}
@@ -1,20 +1,21 @@
 package command
 
 import (
-	"crypto/rand"
-	"crypto/rsa"
-	"crypto/x509"
-	"encoding/pem"
 	"log"
-	"os"
+	"strconv"
 
+	"github.com/pkg/errors"
 	"github.com/umalmyha/authsrv/internal/cli/args"
+	"github.com/umalmyha/authsrv/internal/infra/keystore"
 )
 
 type genKeysCommandOptions struct {
 	help        bool
 	privateFile string
 	publicFile  string
+	keyType     string
+	bits        string
+	curve       string
 }
 
 type genKeysCommand struct {
@@ -44,41 +45,32 @@ func (c *genKeysCommand) Run() error {
 		options.publicFile = "public.pem"
 	}
 
-	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
-	if err != nil {
-		return err
+	if options.keyType == "" {
+		options.keyType = keystore.KeyTypeRsa
 	}
 
-	privateFile, err := os.Create(options.privateFile)
-	if err != nil {
-		return err
+	if options.curve == "" {
+		options.curve = "P-256"
 	}
-	defer privateFile.Close()
 
-	privateBlock := pem.Block{
-		Type:  "RSA PRIVATE KEY",
-		Bytes: x509.MarshalPKCS1PrivateKey(privateKey),
-	}
-	if err := pem.Encode(privateFile, &privateBlock); err != nil {
-		return err
+	bits := 2048
+	if options.bits != "" {
+		var err error
+		if bits, err = strconv.Atoi(options.bits); err != nil {
+			return errors.Wrap(err, "failed to parse --bits, check if number is provided")
+		}
 	}
 
-	publicKeyBytes, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
-	if err != nil {
-		return err
+	if options.keyType == keystore.KeyTypeRsa && bits < 2048 {
+		return errors.New("RSA key size must be at least 2048 bits")
 	}
 
-	publicFile, err := os.Create(options.publicFile)
+	key, err := keystore.GenerateKey(options.keyType, bits, options.curve)
 	if err != nil {
 		return err
 	}
-	defer publicFile.Close()
 
-	publicBlock := pem.Block{
-		Type:  "RSA PUBLIC KEY",
-		Bytes: publicKeyBytes,
-	}
-	if err := pem.Encode(publicFile, &publicBlock); err != nil {
+	if err := keystore.WriteKeyPair(options.privateFile, options.publicFile, key); err != nil {
 		return err
 	}
 
@@ -95,8 +87,12 @@ func (c *genKeysCommand) Help() {
 	logger.Println("  --help - show help")
 	logger.Println("  --privateFile - specify filename for private key (default is private.pem)")
 	logger.Println("  --publicFile - specify filename for public key (default is public.pem)")
+	logger.Println("  --type - specify key type rsa, ec or ed25519 (default is rsa)")
+	logger.Println("  --bits - specify RSA key size (default is 2048)")
+	logger.Println("  --curve - specify EC curve P-256 (ES256), P-384 (ES384) or P-521 (ES512) (default is P-256)")
 	logger.Println("example:")
 	logger.Println("  genkeys --privateFile=priv.pem --publicFile=pub.pem")
+	logger.Println("  genkeys --type=ec --curve=P-384")
 }
 
 func (c *genKeysCommand) extractOptions() genKeysCommandOptions {
@@ -113,6 +109,12 @@ func (c *genKeysCommand) extractOptions() genKeysCommandOptions {
 			options.privateFile = value
 		case "--publicFile":
 			options.publicFile = value
+		case "--type":
+			options.keyType = value
+		case "--bits":
+			options.bits = value
+		case "--curve":
+			options.curve = value
 		}
 	}
 
@@ -16,11 +16,31 @@ func NewHelpCommand(logger *log.Logger) Executor {
 			&createUserCommand{},
 			&createScopeCommand{},
 			&createRoleCommand{},
+			&listScopesCommand{},
+			&deleteScopeCommand{},
+			&listRolesCommand{},
+			&deleteRoleCommand{},
 			&assignScopeCommand{},
 			&unassignScopeCommand{},
+			&inheritRoleCommand{},
+			&disinheritRoleCommand{},
 			&assignRoleCommand{},
 			&unassignRoleCommand{},
+			&createOrgCommand{},
+			&listOrgsCommand{},
+			&addMemberCommand{},
+			&removeMemberCommand{},
+			&createGroupCommand{},
+			&listGroupsCommand{},
+			&addGroupMemberCommand{},
+			&removeGroupMemberCommand{},
+			&assignGroupRoleCommand{},
+			&unassignGroupRoleCommand{},
+			&unlockUserCommand{},
+			&createClientCommand{},
+			&rotateClientSecretCommand{},
 			&genKeysCommand{},
+			&rotateKeysCommand{},
 		},
 	}
 }
@@ -0,0 +1,105 @@
+package command
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/umalmyha/authsrv/internal/cli/args"
+	"github.com/umalmyha/authsrv/internal/cli/input"
+	"github.com/umalmyha/authsrv/internal/infra"
+	"github.com/umalmyha/authsrv/internal/infra/service"
+)
+
+type inheritRoleCommand struct {
+	*LoggingCommand
+	args args.ParsedArgs
+}
+
+type inheritRoleCommandOptions struct {
+	role   string
+	parent string
+	help   bool
+}
+
+func NewInheritRoleCommand(args args.ParsedArgs, logger *log.Logger) Executor {
+	return &inheritRoleCommand{
+		LoggingCommand: &LoggingCommand{logger: logger},
+		args:           args,
+	}
+}
+
+func (c *inheritRoleCommand) Run() error {
+	options := c.extractOptions()
+	if options.help {
+		c.Help()
+		return nil
+	}
+
+	var err error
+	roleName := options.role
+	if roleName == "" {
+		roleName, err = input.NewSimpleInput(input.Config{Prompt: "role", IsMandatory: true}).Read()
+		if err != nil {
+			return err
+		}
+	}
+
+	parentName := options.parent
+	if parentName == "" {
+		parentName, err = input.NewSimpleInput(input.Config{Prompt: "parent role", IsMandatory: true}).Read()
+		if err != nil {
+			return err
+		}
+	}
+
+	db, err := infra.ConnectToDb()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := service.NewRoleService(db).InheritRole(ctx, roleName, parentName); err != nil {
+		return err
+	}
+
+	logger := c.Logger()
+	logger.Printf("role '%s' inherits from role %s successfully", roleName, parentName)
+	logger.Println()
+
+	return nil
+}
+
+func (c *inheritRoleCommand) Help() {
+	logger := c.Logger()
+	logger.Println("inheritrole - command makes role inherit scopes of parent role")
+	logger.Println("options:")
+	logger.Println("  --help - show help")
+	logger.Println("  --role - specify role name")
+	logger.Println("  --from - specify parent role name")
+	logger.Println("example:")
+	logger.Println("  inheritrole --role=role1 --from=role2")
+}
+
+func (c *inheritRoleCommand) extractOptions() inheritRoleCommandOptions {
+	options := inheritRoleCommandOptions{}
+
+	iter := c.args.Iterator()
+	for iter.HasNext() {
+		nextOpt := iter.Next()
+		option, value := args.KeyValue(nextOpt)
+		switch option {
+		case "--help":
+			options.help = true
+		case "--role":
+			options.role = value
+		case "--from":
+			options.parent = value
+		}
+	}
+
+	return options
+}
@@ -0,0 +1,98 @@
+package command
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/umalmyha/authsrv/internal/business/group"
+	"github.com/umalmyha/authsrv/internal/cli/args"
+	"github.com/umalmyha/authsrv/internal/infra"
+	"github.com/umalmyha/authsrv/internal/infra/service"
+)
+
+type listGroupsCommand struct {
+	*LoggingCommand
+	args args.ParsedArgs
+}
+
+type listGroupsCommandOptions struct {
+	help bool
+}
+
+func NewListGroupsCommand(args args.ParsedArgs, logger *log.Logger) Executor {
+	return &listGroupsCommand{
+		LoggingCommand: &LoggingCommand{logger: logger},
+		args:           args,
+	}
+}
+
+func (c *listGroupsCommand) Run() error {
+	options := c.extractOptions()
+	if options.help {
+		c.Help()
+		return nil
+	}
+
+	db, err := infra.ConnectToDb()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	groups, err := service.NewGroupService(db).Groups(ctx)
+	if err != nil {
+		return err
+	}
+
+	logger := c.Logger()
+	for _, g := range groups {
+		logger.Printf(
+			"%s %s, description: '%s', members: %s, roles: %s",
+			g.Id,
+			g.Name,
+			groupDescription(g),
+			strings.Join(g.Members, ","),
+			strings.Join(g.Roles, ","),
+		)
+	}
+	logger.Printf("%d groups in total", len(groups))
+	logger.Println()
+
+	return nil
+}
+
+func (c *listGroupsCommand) Help() {
+	logger := c.Logger()
+	logger.Println("listgroups - command lists groups with their members and assigned roles")
+	logger.Println("options:")
+	logger.Println("  --help - show help")
+	logger.Println("example:")
+	logger.Println("  listgroups")
+}
+
+func (c *listGroupsCommand) extractOptions() listGroupsCommandOptions {
+	options := listGroupsCommandOptions{}
+
+	iter := c.args.Iterator()
+	for iter.HasNext() {
+		nextOpt := iter.Next()
+		option, _ := args.KeyValue(nextOpt)
+		if option == "--help" {
+			options.help = true
+		}
+	}
+
+	return options
+}
+
+func groupDescription(g group.GroupDetailsDto) string {
+	if g.Description == nil {
+		return ""
+	}
+	return *g.Description
+}
@@ -0,0 +1,114 @@
+package command
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/umalmyha/authsrv/internal/business/organization"
+	"github.com/umalmyha/authsrv/internal/cli/args"
+	"github.com/umalmyha/authsrv/internal/infra"
+	"github.com/umalmyha/authsrv/internal/infra/service"
+)
+
+type listOrgsCommand struct {
+	*LoggingCommand
+	args args.ParsedArgs
+}
+
+type listOrgsCommandOptions struct {
+	org  string
+	help bool
+}
+
+func NewListOrgsCommand(args args.ParsedArgs, logger *log.Logger) Executor {
+	return &listOrgsCommand{
+		LoggingCommand: &LoggingCommand{logger: logger},
+		args:           args,
+	}
+}
+
+func (c *listOrgsCommand) Run() error {
+	options := c.extractOptions()
+	if options.help {
+		c.Help()
+		return nil
+	}
+
+	db, err := infra.ConnectToDb()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	srv := service.NewOrganizationService(db)
+	logger := c.Logger()
+
+	// members are listed for single organization only
+	if options.org != "" {
+		members, err := srv.Members(ctx, options.org)
+		if err != nil {
+			return err
+		}
+
+		for _, member := range members {
+			logger.Printf("%s, roles: %s", member.Username, strings.Join(member.Roles, ","))
+		}
+		logger.Printf("%d members of organization %s in total", len(members), options.org)
+		logger.Println()
+		return nil
+	}
+
+	orgs, err := srv.Organizations(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, org := range orgs {
+		logger.Printf("%s %s, description: '%s'", org.Id, org.Name, orgDescription(org))
+	}
+	logger.Printf("%d organizations in total", len(orgs))
+	logger.Println()
+
+	return nil
+}
+
+func (c *listOrgsCommand) Help() {
+	logger := c.Logger()
+	logger.Println("listorgs - command lists organizations or members of organization with roles assigned within it")
+	logger.Println("options:")
+	logger.Println("  --help - show help")
+	logger.Println("  --org - specify organization to list members of")
+	logger.Println("example:")
+	logger.Println("  listorgs")
+	logger.Println("  listorgs --org=org1")
+}
+
+func (c *listOrgsCommand) extractOptions() listOrgsCommandOptions {
+	options := listOrgsCommandOptions{}
+
+	iter := c.args.Iterator()
+	for iter.HasNext() {
+		nextOpt := iter.Next()
+		option, value := args.KeyValue(nextOpt)
+		switch option {
+		case "--help":
+			options.help = true
+		case "--org":
+			options.org = value
+		}
+	}
+
+	return options
+}
+
+func orgDescription(org organization.OrganizationDto) string {
+	if org.Description == nil {
+		return ""
+	}
+	return *org.Description
+}
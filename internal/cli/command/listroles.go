@@ -0,0 +1,96 @@
+package command
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/umalmyha/authsrv/internal/cli/args"
+	"github.com/umalmyha/authsrv/internal/infra"
+	"github.com/umalmyha/authsrv/internal/infra/service"
+)
+
+type listRolesCommand struct {
+	*LoggingCommand
+	args args.ParsedArgs
+}
+
+type listRolesCommandOptions struct {
+	help bool
+}
+
+func NewListRolesCommand(args args.ParsedArgs, logger *log.Logger) Executor {
+	return &listRolesCommand{
+		LoggingCommand: &LoggingCommand{logger: logger},
+		args:           args,
+	}
+}
+
+func (c *listRolesCommand) Run() error {
+	options := c.extractOptions()
+	if options.help {
+		c.Help()
+		return nil
+	}
+
+	db, err := infra.ConnectToDb()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	roles, err := service.NewRoleService(db).Roles(ctx)
+	if err != nil {
+		return err
+	}
+
+	logger := c.Logger()
+	for _, r := range roles {
+		description := ""
+		if r.Description != nil {
+			description = *r.Description
+		}
+		logger.Printf(
+			"%s %s, description: '%s', scopes: %s, parents: %s, effective scopes: %s",
+			r.Id,
+			r.Name,
+			description,
+			strings.Join(r.Scopes, ","),
+			strings.Join(r.Parents, ","),
+			strings.Join(r.EffectiveScopes, ","),
+		)
+	}
+	logger.Printf("%d roles in total", len(roles))
+	logger.Println()
+
+	return nil
+}
+
+func (c *listRolesCommand) Help() {
+	logger := c.Logger()
+	logger.Println("listroles - command lists roles with assigned scopes, parent roles and effective scopes")
+	logger.Println("options:")
+	logger.Println("  --help - show help")
+	logger.Println("example:")
+	logger.Println("  listroles")
+}
+
+func (c *listRolesCommand) extractOptions() listRolesCommandOptions {
+	options := listRolesCommandOptions{}
+
+	iter := c.args.Iterator()
+	for iter.HasNext() {
+		nextOpt := iter.Next()
+		option, _ := args.KeyValue(nextOpt)
+		switch option {
+		case "--help":
+			options.help = true
+		}
+	}
+
+	return options
+}
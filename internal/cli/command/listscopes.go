@@ -0,0 +1,87 @@
+package command
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/umalmyha/authsrv/internal/cli/args"
+	"github.com/umalmyha/authsrv/internal/infra"
+	"github.com/umalmyha/authsrv/internal/infra/service"
+)
+
+type listScopesCommand struct {
+	*LoggingCommand
+	args args.ParsedArgs
+}
+
+type listScopesCommandOptions struct {
+	help bool
+}
+
+func NewListScopesCommand(args args.ParsedArgs, logger *log.Logger) Executor {
+	return &listScopesCommand{
+		LoggingCommand: &LoggingCommand{logger: logger},
+		args:           args,
+	}
+}
+
+func (c *listScopesCommand) Run() error {
+	options := c.extractOptions()
+	if options.help {
+		c.Help()
+		return nil
+	}
+
+	db, err := infra.ConnectToDb()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	scopes, err := service.NewScopeService(db).Scopes(ctx)
+	if err != nil {
+		return err
+	}
+
+	logger := c.Logger()
+	for _, sc := range scopes {
+		description := ""
+		if sc.Description != nil {
+			description = *sc.Description
+		}
+		logger.Printf("%s %s, description: '%s'", sc.Id, sc.Name, description)
+	}
+	logger.Printf("%d scopes in total", len(scopes))
+	logger.Println()
+
+	return nil
+}
+
+func (c *listScopesCommand) Help() {
+	logger := c.Logger()
+	logger.Println("listscopes - command lists scopes")
+	logger.Println("options:")
+	logger.Println("  --help - show help")
+	logger.Println("example:")
+	logger.Println("  listscopes")
+}
+
+func (c *listScopesCommand) extractOptions() listScopesCommandOptions {
+	options := listScopesCommandOptions{}
+
+	iter := c.args.Iterator()
+	for iter.HasNext() {
+		nextOpt := iter.Next()
+		option, _ := args.KeyValue(nextOpt)
+		switch option {
+		case "--help":
+			options.help = true
+		}
+	}
+
+	return options
+}
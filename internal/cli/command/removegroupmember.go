@@ -0,0 +1,105 @@
+package command
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/umalmyha/authsrv/internal/cli/args"
+	"github.com/umalmyha/authsrv/internal/cli/input"
+	"github.com/umalmyha/authsrv/internal/infra"
+	"github.com/umalmyha/authsrv/internal/infra/service"
+)
+
+type removeGroupMemberCommand struct {
+	*LoggingCommand
+	args args.ParsedArgs
+}
+
+type removeGroupMemberCommandOptions struct {
+	group    string
+	username string
+	help     bool
+}
+
+func NewRemoveGroupMemberCommand(args args.ParsedArgs, logger *log.Logger) Executor {
+	return &removeGroupMemberCommand{
+		LoggingCommand: &LoggingCommand{logger: logger},
+		args:           args,
+	}
+}
+
+func (c *removeGroupMemberCommand) Run() error {
+	options := c.extractOptions()
+	if options.help {
+		c.Help()
+		return nil
+	}
+
+	var err error
+	username := options.username
+	if username == "" {
+		username, err = input.NewSimpleInput(input.Config{Prompt: "username", IsMandatory: true}).Read()
+		if err != nil {
+			return err
+		}
+	}
+
+	grp := options.group
+	if grp == "" {
+		grp, err = input.NewSimpleInput(input.Config{Prompt: "from group", IsMandatory: true}).Read()
+		if err != nil {
+			return err
+		}
+	}
+
+	db, err := infra.ConnectToDb()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := service.NewGroupService(db).RemoveMember(ctx, grp, username); err != nil {
+		return err
+	}
+
+	logger := c.Logger()
+	logger.Printf("user %s is removed from group %s successfully", username, grp)
+	logger.Println()
+
+	return nil
+}
+
+func (c *removeGroupMemberCommand) Help() {
+	logger := c.Logger()
+	logger.Println("removegroupmember - command removes user from group, roles of group are no longer effective for user")
+	logger.Println("options:")
+	logger.Println("  --help - show help")
+	logger.Println("  --user - specify username")
+	logger.Println("  --from - specify group name")
+	logger.Println("example:")
+	logger.Println("  removegroupmember --user=username1 --from=group1")
+}
+
+func (c *removeGroupMemberCommand) extractOptions() removeGroupMemberCommandOptions {
+	options := removeGroupMemberCommandOptions{}
+
+	iter := c.args.Iterator()
+	for iter.HasNext() {
+		nextOpt := iter.Next()
+		option, value := args.KeyValue(nextOpt)
+		switch option {
+		case "--help":
+			options.help = true
+		case "--user":
+			options.username = value
+		case "--from":
+			options.group = value
+		}
+	}
+
+	return options
+}
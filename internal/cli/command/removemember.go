@@ -0,0 +1,105 @@
+package command
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/umalmyha/authsrv/internal/cli/args"
+	"github.com/umalmyha/authsrv/internal/cli/input"
+	"github.com/umalmyha/authsrv/internal/infra"
+	"github.com/umalmyha/authsrv/internal/infra/service"
+)
+
+type removeMemberCommand struct {
+	*LoggingCommand
+	args args.ParsedArgs
+}
+
+type removeMemberCommandOptions struct {
+	org      string
+	username string
+	help     bool
+}
+
+func NewRemoveMemberCommand(args args.ParsedArgs, logger *log.Logger) Executor {
+	return &removeMemberCommand{
+		LoggingCommand: &LoggingCommand{logger: logger},
+		args:           args,
+	}
+}
+
+func (c *removeMemberCommand) Run() error {
+	options := c.extractOptions()
+	if options.help {
+		c.Help()
+		return nil
+	}
+
+	var err error
+	username := options.username
+	if username == "" {
+		username, err = input.NewSimpleInput(input.Config{Prompt: "username", IsMandatory: true}).Read()
+		if err != nil {
+			return err
+		}
+	}
+
+	org := options.org
+	if org == "" {
+		org, err = input.NewSimpleInput(input.Config{Prompt: "from organization", IsMandatory: true}).Read()
+		if err != nil {
+			return err
+		}
+	}
+
+	db, err := infra.ConnectToDb()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := service.NewOrganizationService(db).RemoveMember(ctx, org, username); err != nil {
+		return err
+	}
+
+	logger := c.Logger()
+	logger.Printf("user %s is removed from organization %s successfully", username, org)
+	logger.Println()
+
+	return nil
+}
+
+func (c *removeMemberCommand) Help() {
+	logger := c.Logger()
+	logger.Println("removemember - command removes user from organization together with roles assigned within it")
+	logger.Println("options:")
+	logger.Println("  --help - show help")
+	logger.Println("  --user - specify username")
+	logger.Println("  --from - specify organization name")
+	logger.Println("example:")
+	logger.Println("  removemember --user=username1 --from=org1")
+}
+
+func (c *removeMemberCommand) extractOptions() removeMemberCommandOptions {
+	options := removeMemberCommandOptions{}
+
+	iter := c.args.Iterator()
+	for iter.HasNext() {
+		nextOpt := iter.Next()
+		option, value := args.KeyValue(nextOpt)
+		switch option {
+		case "--help":
+			options.help = true
+		case "--user":
+			options.username = value
+		case "--from":
+			options.org = value
+		}
+	}
+
+	return options
+}
@@ -0,0 +1,101 @@
+package command
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/umalmyha/authsrv/internal/cli/args"
+	"github.com/umalmyha/authsrv/internal/cli/input"
+	"github.com/umalmyha/authsrv/internal/infra"
+	"github.com/umalmyha/authsrv/internal/infra/service"
+)
+
+type rotateClientSecretCommand struct {
+	*LoggingCommand
+	args args.ParsedArgs
+}
+
+type rotateClientSecretCommandOptions struct {
+	name string
+	help bool
+}
+
+func NewRotateClientSecretCommand(args args.ParsedArgs, logger *log.Logger) Executor {
+	return &rotateClientSecretCommand{
+		LoggingCommand: &LoggingCommand{logger: logger},
+		args:           args,
+	}
+}
+
+func (c *rotateClientSecretCommand) Run() error {
+	options := c.extractOptions()
+	if options.help {
+		c.Help()
+		return nil
+	}
+
+	var err error
+	name := options.name
+	if name == "" {
+		name, err = input.NewSimpleInput(input.Config{Prompt: "client name", IsMandatory: true}).Read()
+		if err != nil {
+			return err
+		}
+	}
+
+	passCfg, err := infra.PasswordConfig()
+	if err != nil {
+		return err
+	}
+
+	db, err := infra.ConnectToDb()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	srv := service.NewClientService(db, passCfg)
+	secret, err := srv.RotateSecret(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	logger := c.Logger()
+	logger.Printf("secret of client '%s' is rotated successfully, new client secret: %s", name, secret)
+	logger.Println("store the secret now, it can't be shown again")
+	logger.Println()
+
+	return nil
+}
+
+func (c *rotateClientSecretCommand) Help() {
+	logger := c.Logger()
+	logger.Println("rotateclientsecret - command generates new secret for confidential client, old secret stops working")
+	logger.Println("options:")
+	logger.Println("  --help - show help")
+	logger.Println("  --name - specify client name")
+	logger.Println("example:")
+	logger.Println("  rotateclientsecret --name=billing")
+}
+
+func (c *rotateClientSecretCommand) extractOptions() rotateClientSecretCommandOptions {
+	options := rotateClientSecretCommandOptions{}
+
+	iter := c.args.Iterator()
+	for iter.HasNext() {
+		nextOpt := iter.Next()
+		option, value := args.KeyValue(nextOpt)
+		switch option {
+		case "--help":
+			options.help = true
+		case "--name":
+			options.name = value
+		}
+	}
+
+	return options
+}
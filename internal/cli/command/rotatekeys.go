@@ -0,0 +1,165 @@
+package command
+
+import (
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/pkg/errors"
+	"github.com/umalmyha/authsrv/internal/cli/args"
+	"github.com/umalmyha/authsrv/internal/infra"
+	"github.com/umalmyha/authsrv/internal/infra/keystore"
+)
+
+type rotateKeysCommandOptions struct {
+	help      bool
+	dir       string
+	alg       string
+	bits      string
+	retention string
+	delay     string
+}
+
+type rotateKeysCommand struct {
+	*LoggingCommand
+	args args.ParsedArgs
+}
+
+func NewRotateKeysCommand(args args.ParsedArgs, logger *log.Logger) Executor {
+	return &rotateKeysCommand{
+		LoggingCommand: &LoggingCommand{logger: logger},
+		args:           args,
+	}
+}
+
+func (c *rotateKeysCommand) Run() error {
+	options := c.extractOptions()
+	if options.help {
+		c.Help()
+		return nil
+	}
+
+	dir := options.dir
+	if dir == "" {
+		dir = infra.JwtKeyringDir()
+	}
+
+	if dir == "" {
+		return errors.New("keyring directory is not specified, provide --dir or AUTHSRV_JWT_KEYRING_DIR")
+	}
+
+	alg := options.alg
+	if alg == "" {
+		alg = infra.JwtAlgorithm()
+	}
+
+	if alg == "" {
+		alg = jwt.SigningMethodRS256.Alg()
+	}
+
+	bits := 2048
+	if options.bits != "" {
+		var err error
+		if bits, err = strconv.Atoi(options.bits); err != nil {
+			return errors.Wrap(err, "failed to parse --bits, check if number is provided")
+		}
+	}
+
+	if bits < 2048 {
+		return errors.New("RSA key size must be at least 2048 bits")
+	}
+
+	// retired key must outlive every token signed with it, so retention defaults to access token ttl
+	var retention time.Duration
+	if options.retention != "" {
+		minutes, err := strconv.Atoi(options.retention)
+		if err != nil {
+			return errors.Wrap(err, "failed to parse --retention, check if number is provided")
+		}
+		retention = time.Duration(minutes) * time.Minute
+	} else {
+		ttl, err := infra.JwtTimeToLive()
+		if err != nil {
+			return err
+		}
+		retention = ttl
+	}
+
+	// every instance must reload keyring and publish new key before any of them signs with it
+	reloadInterval, err := infra.JwtKeyringReloadInterval()
+	if err != nil {
+		return err
+	}
+
+	delay := 2 * reloadInterval
+	if options.delay != "" {
+		minutes, err := strconv.Atoi(options.delay)
+		if err != nil {
+			return errors.Wrap(err, "failed to parse --delay, check if number is provided")
+		}
+		delay = time.Duration(minutes) * time.Minute
+	}
+
+	if delay < reloadInterval {
+		return errors.Errorf("activation delay must be at least keyring reload interval %s", reloadInterval)
+	}
+
+	result, err := keystore.New(dir).Rotate(alg, bits, retention, delay, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	logger := c.Logger()
+	logger.Printf("new %s signing key %s is generated and published, it signs from %s", alg, result.ActiveKeyId, result.ActivateAt.Format(time.RFC3339))
+	if result.RetiredKeyId != "" {
+		logger.Printf("key %s signs until %s and will be removed after %s more", result.RetiredKeyId, result.ActivateAt.Format(time.RFC3339), delay+retention)
+	}
+
+	for _, kid := range result.RemovedKeyIds {
+		logger.Printf("expired key %s is removed", kid)
+	}
+	logger.Println("running server instances pick up keyring changes without restart")
+
+	return nil
+}
+
+func (c *rotateKeysCommand) Help() {
+	logger := c.Logger()
+	logger.Println("rotatekeys - command generates new JWT signing key, previous key is kept for verification until retention passes")
+	logger.Println("options:")
+	logger.Println("  --help - show help")
+	logger.Println("  --dir - specify keyring directory (default is AUTHSRV_JWT_KEYRING_DIR)")
+	logger.Println("  --alg - specify signing algorithm of the new key, e.g. RS256, ES256 or EdDSA (default is AUTHSRV_JWT_ALGORITHM or RS256)")
+	logger.Println("  --bits - specify RSA key size, ignored for other algorithms (default is 2048)")
+	logger.Println("  --retention - specify minutes retired key is accepted (default is AUTHSRV_JWT_TTL_MINUTES)")
+	logger.Println("  --delay - specify minutes new key is published before it signs, at least AUTHSRV_JWT_KEYRING_RELOAD_SECONDS (default is twice the reload interval)")
+	logger.Println("example:")
+	logger.Println("  rotatekeys --dir=/etc/authsrv/keys --bits=4096")
+}
+
+func (c *rotateKeysCommand) extractOptions() rotateKeysCommandOptions {
+	options := rotateKeysCommandOptions{}
+
+	iter := c.args.Iterator()
+	for iter.HasNext() {
+		nextOpt := iter.Next()
+		option, value := args.KeyValue(nextOpt)
+		switch option {
+		case "--help":
+			options.help = true
+		case "--dir":
+			options.dir = value
+		case "--alg":
+			options.alg = value
+		case "--bits":
+			options.bits = value
+		case "--retention":
+			options.retention = value
+		case "--delay":
+			options.delay = value
+		}
+	}
+
+	return options
+}
@@ -0,0 +1,105 @@
+package command
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/umalmyha/authsrv/internal/cli/args"
+	"github.com/umalmyha/authsrv/internal/cli/input"
+	"github.com/umalmyha/authsrv/internal/infra"
+	"github.com/umalmyha/authsrv/internal/infra/service"
+)
+
+type unassignGroupRoleCommand struct {
+	*LoggingCommand
+	args args.ParsedArgs
+}
+
+type unassignGroupRoleCommandOptions struct {
+	role  string
+	group string
+	help  bool
+}
+
+func NewUnassignGroupRoleCommand(args args.ParsedArgs, logger *log.Logger) Executor {
+	return &unassignGroupRoleCommand{
+		LoggingCommand: &LoggingCommand{logger: logger},
+		args:           args,
+	}
+}
+
+func (c *unassignGroupRoleCommand) Run() error {
+	options := c.extractOptions()
+	if options.help {
+		c.Help()
+		return nil
+	}
+
+	var err error
+	roleName := options.role
+	if roleName == "" {
+		roleName, err = input.NewSimpleInput(input.Config{Prompt: "role", IsMandatory: true}).Read()
+		if err != nil {
+			return err
+		}
+	}
+
+	grp := options.group
+	if grp == "" {
+		grp, err = input.NewSimpleInput(input.Config{Prompt: "from group", IsMandatory: true}).Read()
+		if err != nil {
+			return err
+		}
+	}
+
+	db, err := infra.ConnectToDb()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := service.NewGroupService(db).UnassignRole(ctx, grp, roleName); err != nil {
+		return err
+	}
+
+	logger := c.Logger()
+	logger.Printf("role '%s' is unassigned from group %s successfully", roleName, grp)
+	logger.Println()
+
+	return nil
+}
+
+func (c *unassignGroupRoleCommand) Help() {
+	logger := c.Logger()
+	logger.Println("unassigngrouprole - command unassigns role from group")
+	logger.Println("options:")
+	logger.Println("  --help - show help")
+	logger.Println("  --role - specify role name")
+	logger.Println("  --from - specify group name")
+	logger.Println("example:")
+	logger.Println("  unassigngrouprole --role=role1 --from=group1")
+}
+
+func (c *unassignGroupRoleCommand) extractOptions() unassignGroupRoleCommandOptions {
+	options := unassignGroupRoleCommandOptions{}
+
+	iter := c.args.Iterator()
+	for iter.HasNext() {
+		nextOpt := iter.Next()
+		option, value := args.KeyValue(nextOpt)
+		switch option {
+		case "--help":
+			options.help = true
+		case "--role":
+			options.role = value
+		case "--from":
+			options.group = value
+		}
+	}
+
+	return options
+}
@@ -20,6 +20,7 @@ type unassignRoleCommand struct {
 type unassignRoleCommandOptions struct {
 	role     string
 	username string
+	org      string
 	help     bool
 }
 
@@ -73,11 +74,23 @@ func (c *unassignRoleCommand) Run() error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	logger := c.Logger()
+
+	// role assigned within organization is effective only in sessions issued for it
+	if options.org != "" {
+		if err := service.NewOrganizationService(db).UnassignRole(ctx, options.org, username, roleName); err != nil {
+			return err
+		}
+
+		logger.Printf("role '%s' is unassigned from user %s in organization %s successfully", roleName, username, options.org)
+		logger.Println()
+		return nil
+	}
+
 	if err := service.NewUserService(db, rdb).UnassignRole(ctx, username, roleName); err != nil {
 		return err
 	}
 
-	logger := c.Logger()
 	logger.Printf("role '%s' is unassigned from user %s successfully", roleName, username)
 	logger.Println()
 
@@ -91,8 +104,10 @@ func (c *unassignRoleCommand) Help() {
 	logger.Println("  --help - show help")
 	logger.Println("  --role - specify role name")
 	logger.Println("  --from - specify username")
+	logger.Println("  --org - specify organization, role is unassigned within it")
 	logger.Println("example:")
 	logger.Println("  assignrole --role=role1 --from=username1")
+	logger.Println("  unassignrole --role=role1 --from=username1 --org=org1")
 }
 
 func (c *unassignRoleCommand) extractOptions() unassignRoleCommandOptions {
@@ -109,6 +124,8 @@ func (c *unassignRoleCommand) extractOptions() unassignRoleCommandOptions {
 			options.role = value
 		case "--from":
 			options.username = value
+		case "--org":
+			options.org = value
 		}
 	}
 
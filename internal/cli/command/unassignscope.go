@@ -62,7 +62,7 @@ func (c *unassignScopeCommand) Run() error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	if err := service.NewRoleService(db).AssignScope(ctx, roleName, scopeName); err != nil {
+	if err := service.NewRoleService(db).UnassignScope(ctx, roleName, scopeName); err != nil {
 		return err
 	}
 
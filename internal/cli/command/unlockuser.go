@@ -0,0 +1,104 @@
+package command
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/umalmyha/authsrv/internal/cli/args"
+	"github.com/umalmyha/authsrv/internal/cli/input"
+	"github.com/umalmyha/authsrv/internal/infra"
+	"github.com/umalmyha/authsrv/internal/infra/service"
+	dbredis "github.com/umalmyha/authsrv/pkg/database/redis"
+)
+
+type unlockUserCommand struct {
+	*LoggingCommand
+	args args.ParsedArgs
+}
+
+type unlockUserCommandOptions struct {
+	username string
+	help     bool
+}
+
+func NewUnlockUserCommand(args args.ParsedArgs, logger *log.Logger) Executor {
+	return &unlockUserCommand{
+		LoggingCommand: &LoggingCommand{logger: logger},
+		args:           args,
+	}
+}
+
+func (c *unlockUserCommand) Run() error {
+	options := c.extractOptions()
+	if options.help {
+		c.Help()
+		return nil
+	}
+
+	var err error
+	username := options.username
+	if username == "" {
+		username, err = input.NewSimpleInput(input.Config{Prompt: "username", IsMandatory: true}).Read()
+		if err != nil {
+			return err
+		}
+	}
+
+	db, err := infra.ConnectToDb()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	redisOpts, err := infra.RedisOptions()
+	if err != nil {
+		return err
+	}
+
+	rdb, err := dbredis.Connect(redisOpts)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := service.NewUserService(db, rdb).UnlockUserByUsername(ctx, username); err != nil {
+		return err
+	}
+
+	logger := c.Logger()
+	logger.Printf("user %s is unlocked successfully", username)
+	logger.Println()
+
+	return nil
+}
+
+func (c *unlockUserCommand) Help() {
+	logger := c.Logger()
+	logger.Println("unlockuser - command unlocks user locked after failed sign in attempts")
+	logger.Println("options:")
+	logger.Println("  --help - show help")
+	logger.Println("  --user - specify username")
+	logger.Println("example:")
+	logger.Println("  unlockuser --user=username1")
+}
+
+func (c *unlockUserCommand) extractOptions() unlockUserCommandOptions {
+	options := unlockUserCommandOptions{}
+
+	iter := c.args.Iterator()
+	for iter.HasNext() {
+		nextOpt := iter.Next()
+		option, value := args.KeyValue(nextOpt)
+		switch option {
+		case "--help":
+			options.help = true
+		case "--user":
+			options.username = value
+		}
+	}
+
+	return options
+}
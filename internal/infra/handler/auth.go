@@ -1,43 +1,56 @@
 package handler
 
 import (
+	"math"
 	"net/http"
+	"strconv"
 
 	"github.com/pkg/errors"
 
+	"github.com/umalmyha/authsrv/internal/business/lockout"
 	"github.com/umalmyha/authsrv/internal/business/refresh"
 	"github.com/umalmyha/authsrv/internal/business/user"
 	valueobj "github.com/umalmyha/authsrv/internal/business/value-object"
 	"github.com/umalmyha/authsrv/internal/infra/service"
 	webErrs "github.com/umalmyha/authsrv/pkg/web/errors"
+	"github.com/umalmyha/authsrv/pkg/web/middleware"
 	"github.com/umalmyha/authsrv/pkg/web/request"
 	"github.com/umalmyha/authsrv/pkg/web/response"
 )
 
 type AuthHandler struct {
-	authSrv *service.AuthService
-	rfrCfg  valueobj.RefreshTokenConfig
+	authSrv  *service.AuthService
+	emailSrv *service.EmailService
+	rfrCfg   valueobj.RefreshTokenConfig
 }
 
-func NewAuthHandler(authSrv *service.AuthService, rfrCfg valueobj.RefreshTokenConfig) *AuthHandler {
+func NewAuthHandler(authSrv *service.AuthService, emailSrv *service.EmailService, rfrCfg valueobj.RefreshTokenConfig) *AuthHandler {
 	return &AuthHandler{
-		authSrv: authSrv,
-		rfrCfg:  rfrCfg,
+		authSrv:  authSrv,
+		emailSrv: emailSrv,
+		rfrCfg:   rfrCfg,
 	}
 }
 
 func (h *AuthHandler) Signup(w http.ResponseWriter, r *http.Request) error {
 	var nu user.NewUserDto
-	if err := request.JsonReqBody(r, nu); err != nil {
+	if err := request.JsonReqBody(r, &nu); err != nil {
 		return err
 	}
 
-	return h.authSrv.Signup(r.Context(), nu)
+	if err := h.authSrv.Signup(r.Context(), nu); err != nil {
+		return err
+	}
+
+	if nu.Email == nil || *nu.Email == "" {
+		return nil
+	}
+	return h.emailSrv.RequestVerification(r.Context(), nu.Username)
 }
 
 func (h *AuthHandler) Signin(w http.ResponseWriter, r *http.Request) error {
 	var signin user.SigninDto
-	if err := request.JsonReqBody(r, signin); err != nil {
+	if err := request.JsonReqBody(r, &signin); err != nil {
 		return err
 	}
 
@@ -47,26 +60,23 @@ func (h *AuthHandler) Signin(w http.ResponseWriter, r *http.Request) error {
 	}
 	signin.Username = username
 	signin.Password = password
+	signin.Client = clientInfo(r)
 
-	refreshCookie := h.rfrCfg.CookieName()
-	if request.GetCookieValue(r, refreshCookie) != "" {
+	if request.GetCookieValue(r, h.rfrCfg.CookieName()) != "" {
 		return errors.New("refresh token cookie is set, logout first or refresh session")
 	}
 
-	jwt, rfrToken, err := h.authSrv.Signin(r.Context(), signin)
+	result, err := h.authSrv.Signin(r.Context(), signin)
 	if err != nil {
-		return err
+		return signinErr(w, err)
 	}
 
-	cookie := &http.Cookie{
-		Name:     h.rfrCfg.CookieName(),
-		Value:    rfrToken.Id(),
-		MaxAge:   rfrToken.UnixExpiresIn(),
-		HttpOnly: true,
+	if result.MfaRequired() {
+		return h.respondMfaRequired(w, result.Challenge)
 	}
-	response.SetCookie(w, cookie)
 
-	return h.respondJwt(w, jwt)
+	response.SetCookie(w, refreshCookie(h.rfrCfg, result.RefreshToken))
+	return respondJwt(w, result.AccessToken)
 }
 
 func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) error {
@@ -81,6 +91,11 @@ func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) error {
 	refreshTokenId := request.GetCookieValue(r, rfrTokenCookie)
 	logout.RefreshTokenId = refreshTokenId
 
+	if claims, ok := middleware.AuthClaims(r); ok {
+		logout.AccessTokenId = claims.TokenId()
+		logout.AccessTokenExpiresAt = claims.Expiry()
+	}
+
 	if err := h.authSrv.Logout(r.Context(), logout); err != nil {
 		return err
 	}
@@ -101,21 +116,68 @@ func (h *AuthHandler) RefreshSession(w http.ResponseWriter, r *http.Request) err
 		return err
 	}
 	rfr.RefreshTokenId = refreshTokenId
+	rfr.Client = clientInfo(r)
 
 	// TODO: Think of allowed errors
-	jwt, err := h.authSrv.RefreshSession(r.Context(), rfr)
+	jwt, rfrToken, err := h.authSrv.RefreshSession(r.Context(), rfr)
 	if err != nil {
-		if errors.Is(err, refresh.RefreshTokenExpiredErr) {
+		if errors.Is(err, refresh.RefreshTokenExpiredErr) || errors.Is(err, refresh.RefreshTokenReusedErr) {
 			response.DeleteCookie(r, w, h.rfrCfg.CookieName())
-			return response.RespondJson(w, http.StatusBadRequest, webErrs.HttpBadRequestErr("application/json", err))
+			return webErrs.HttpBadRequestJsonErr(err.Error())
+		}
+
+		if errors.Is(err, user.UserDisabledErr) {
+			response.DeleteCookie(r, w, h.rfrCfg.CookieName())
+			return errors.Wrap(webErrs.HttpForbiddenErr, err.Error())
+		}
+
+		// session is kept, so caller can continue in organization it is already in
+		if errors.Is(err, user.NotOrganizationMemberErr) {
+			return errors.Wrap(webErrs.HttpForbiddenErr, err.Error())
 		}
 		return err
 	}
 
-	return h.respondJwt(w, jwt)
+	response.SetCookie(w, refreshCookie(h.rfrCfg, rfrToken))
+	return respondJwt(w, jwt)
+}
+
+// signinErr doesn't reveal which credential was wrong, throttled attempts get delay before retry
+func signinErr(w http.ResponseWriter, err error) error {
+	var throttled *lockout.ThrottledErr
+	switch {
+	case errors.As(err, &throttled):
+		retryAfter := int(math.Ceil(throttled.RetryAfter().Seconds()))
+		response.SetHeader(w, "Retry-After", strconv.Itoa(retryAfter))
+		return errors.Wrap(webErrs.HttpTooManyRequestsErr, err.Error())
+	case errors.Is(err, service.EmailNotVerifiedErr),
+		errors.Is(err, user.UserDisabledErr),
+		errors.Is(err, user.NotOrganizationMemberErr):
+		return errors.Wrap(webErrs.HttpForbiddenErr, err.Error())
+	case errors.Is(err, service.InvalidCredentialsErr):
+		response.SetHeader(w, "WWW-Authenticate", `Basic realm="authsrv"`)
+		return errors.Wrap(webErrs.HttpUnauthorizedErr, err.Error())
+	}
+	return err
+}
+
+func clientInfo(r *http.Request) refresh.ClientInfo {
+	return refresh.ClientInfo{
+		IpAddress: request.ClientIp(r),
+		UserAgent: request.GetHeader(r, "User-Agent"),
+	}
+}
+
+func refreshCookie(rfrCfg valueobj.RefreshTokenConfig, rfrToken *refresh.RefreshToken) *http.Cookie {
+	return &http.Cookie{
+		Name:     rfrCfg.CookieName(),
+		Value:    rfrToken.Id(),
+		MaxAge:   rfrToken.UnixExpiresIn(),
+		HttpOnly: true,
+	}
 }
 
-func (h *AuthHandler) respondJwt(w http.ResponseWriter, jwt valueobj.Jwt) error {
+func respondJwt(w http.ResponseWriter, jwt valueobj.Jwt) error {
 	signinData := struct {
 		AccessToken string `json:"accessToken"`
 		ExpiresAt   int64  `json:"expiresAt"`
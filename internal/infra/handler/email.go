@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/pkg/errors"
+
+	"github.com/umalmyha/authsrv/internal/business/user"
+	"github.com/umalmyha/authsrv/internal/infra/service"
+	webErrs "github.com/umalmyha/authsrv/pkg/web/errors"
+	"github.com/umalmyha/authsrv/pkg/web/middleware"
+	"github.com/umalmyha/authsrv/pkg/web/request"
+	"github.com/umalmyha/authsrv/pkg/web/response"
+)
+
+type EmailHandler struct {
+	emailSrv *service.EmailService
+}
+
+func NewEmailHandler(emailSrv *service.EmailService) *EmailHandler {
+	return &EmailHandler{
+		emailSrv: emailSrv,
+	}
+}
+
+func (h *EmailHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) error {
+	var verify struct {
+		Token string `json:"token"`
+	}
+	if err := request.JsonReqBody(r, &verify); err != nil {
+		return err
+	}
+
+	if err := h.emailSrv.VerifyEmail(r.Context(), verify.Token); err != nil {
+		return emailErr(err)
+	}
+
+	response.RespondStatus(w, http.StatusNoContent)
+	return nil
+}
+
+// ResendVerification always responds with accepted status, so it can't be used to find out existing users
+func (h *EmailHandler) ResendVerification(w http.ResponseWriter, r *http.Request) error {
+	var resend struct {
+		Username string `json:"username"`
+	}
+	if err := request.JsonReqBody(r, &resend); err != nil {
+		return err
+	}
+
+	if err := h.emailSrv.RequestVerification(r.Context(), resend.Username); err != nil {
+		return err
+	}
+
+	response.RespondStatus(w, http.StatusAccepted)
+	return nil
+}
+
+func (h *EmailHandler) ChangeEmail(w http.ResponseWriter, r *http.Request) error {
+	var change struct {
+		Email           string `json:"email"`
+		CurrentPassword string `json:"currentPassword"`
+	}
+	if err := request.JsonReqBody(r, &change); err != nil {
+		return err
+	}
+
+	if err := h.emailSrv.ChangeEmail(r.Context(), middleware.AuthUsername(r), change.Email, change.CurrentPassword); err != nil {
+		return emailErr(err)
+	}
+
+	response.RespondStatus(w, http.StatusNoContent)
+	return nil
+}
+
+func emailErr(err error) error {
+	switch {
+	case errors.Is(err, service.VerificationTokenInvalidErr),
+		errors.Is(err, user.EmailChangedErr),
+		errors.Is(err, user.EmailNotSetErr),
+		errors.Is(err, user.InvalidEmailErr),
+		errors.Is(err, user.EmailExistsErr),
+		errors.Is(err, user.WrongPasswordErr):
+		return webErrs.HttpBadRequestJsonErr(err.Error())
+	case errors.Is(err, service.UserNotFoundErr):
+		return errors.Wrap(webErrs.HttpNotFoundErr, err.Error())
+	}
+	return err
+}
@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/pkg/errors"
+
+	"github.com/umalmyha/authsrv/internal/business/group"
+	"github.com/umalmyha/authsrv/internal/infra/service"
+	webErrs "github.com/umalmyha/authsrv/pkg/web/errors"
+	"github.com/umalmyha/authsrv/pkg/web/request"
+	"github.com/umalmyha/authsrv/pkg/web/response"
+)
+
+type GroupHandler struct {
+	groupSrv *service.GroupService
+}
+
+func NewGroupHandler(groupSrv *service.GroupService) *GroupHandler {
+	return &GroupHandler{
+		groupSrv: groupSrv,
+	}
+}
+
+func (h *GroupHandler) CreateGroup(w http.ResponseWriter, r *http.Request) error {
+	var ng group.NewGroupDto
+	if err := request.JsonReqBody(r, &ng); err != nil {
+		return err
+	}
+
+	details, err := h.groupSrv.CreateGroup(r.Context(), ng)
+	if err != nil {
+		return err
+	}
+	return response.RespondJson(w, http.StatusCreated, details)
+}
+
+func (h *GroupHandler) Groups(w http.ResponseWriter, r *http.Request) error {
+	groups, err := h.groupSrv.Groups(r.Context())
+	if err != nil {
+		return err
+	}
+	return response.RespondJson(w, http.StatusOK, groups)
+}
+
+func (h *GroupHandler) Group(w http.ResponseWriter, r *http.Request) error {
+	details, err := h.groupSrv.Group(r.Context(), request.PathParam(r, "group"))
+	if err != nil {
+		return groupErr(err)
+	}
+	return response.RespondJson(w, http.StatusOK, details)
+}
+
+func (h *GroupHandler) UpdateGroup(w http.ResponseWriter, r *http.Request) error {
+	var update group.UpdateGroupDto
+	if err := request.JsonReqBody(r, &update); err != nil {
+		return err
+	}
+
+	details, err := h.groupSrv.UpdateGroup(r.Context(), request.PathParam(r, "group"), update)
+	if err != nil {
+		return groupErr(err)
+	}
+	return response.RespondJson(w, http.StatusOK, details)
+}
+
+// DeleteGroup deletes group, its members lose roles granted by group with next issued token
+func (h *GroupHandler) DeleteGroup(w http.ResponseWriter, r *http.Request) error {
+	if err := h.groupSrv.DeleteGroup(r.Context(), request.PathParam(r, "group")); err != nil {
+		return groupErr(err)
+	}
+
+	response.RespondStatus(w, http.StatusNoContent)
+	return nil
+}
+
+func (h *GroupHandler) AddMember(w http.ResponseWriter, r *http.Request) error {
+	member := struct {
+		Username string `json:"username"`
+	}{}
+
+	if err := request.JsonReqBody(r, &member); err != nil {
+		return err
+	}
+
+	if err := h.groupSrv.AddMember(r.Context(), request.PathParam(r, "group"), member.Username); err != nil {
+		return groupErr(err)
+	}
+
+	response.RespondStatus(w, http.StatusNoContent)
+	return nil
+}
+
+func (h *GroupHandler) RemoveMember(w http.ResponseWriter, r *http.Request) error {
+	if err := h.groupSrv.RemoveMember(r.Context(), request.PathParam(r, "group"), request.PathParam(r, "username")); err != nil {
+		return groupErr(err)
+	}
+
+	response.RespondStatus(w, http.StatusNoContent)
+	return nil
+}
+
+func (h *GroupHandler) AssignRole(w http.ResponseWriter, r *http.Request) error {
+	assignment := struct {
+		RoleName string `json:"role"`
+	}{}
+
+	if err := request.JsonReqBody(r, &assignment); err != nil {
+		return err
+	}
+
+	if err := h.groupSrv.AssignRole(r.Context(), request.PathParam(r, "group"), assignment.RoleName); err != nil {
+		return groupErr(err)
+	}
+
+	response.RespondStatus(w, http.StatusNoContent)
+	return nil
+}
+
+func (h *GroupHandler) UnassignRole(w http.ResponseWriter, r *http.Request) error {
+	if err := h.groupSrv.UnassignRole(r.Context(), request.PathParam(r, "group"), request.PathParam(r, "role")); err != nil {
+		return groupErr(err)
+	}
+
+	response.RespondStatus(w, http.StatusNoContent)
+	return nil
+}
+
+func groupErr(err error) error {
+	switch {
+	case errors.Is(err, service.GroupNotFoundErr):
+		return errors.Wrap(webErrs.HttpNotFoundErr, err.Error())
+	case errors.Is(err, group.MemberExistsErr), errors.Is(err, group.NotMemberErr):
+		return webErrs.HttpBadRequestJsonErr(err.Error())
+	}
+	return err
+}
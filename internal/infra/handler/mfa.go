@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/pkg/errors"
+
+	"github.com/umalmyha/authsrv/internal/business/lockout"
+	"github.com/umalmyha/authsrv/internal/business/mfa"
+	"github.com/umalmyha/authsrv/internal/business/user"
+	"github.com/umalmyha/authsrv/internal/infra/service"
+	webErrs "github.com/umalmyha/authsrv/pkg/web/errors"
+	"github.com/umalmyha/authsrv/pkg/web/middleware"
+	"github.com/umalmyha/authsrv/pkg/web/request"
+	"github.com/umalmyha/authsrv/pkg/web/response"
+)
+
+const mfaRequiredStatus = "mfa_required"
+
+type mfaCodeDto struct {
+	Code string `json:"code"`
+}
+
+func (h *AuthHandler) EnrollTotp(w http.ResponseWriter, r *http.Request) error {
+	enrollment, err := h.authSrv.EnrollTotp(r.Context(), middleware.AuthUsername(r))
+	if err != nil {
+		return mfaErr(err)
+	}
+
+	response.SetHeader(w, "Cache-Control", "no-store")
+	return response.RespondJson(w, http.StatusOK, enrollment)
+}
+
+func (h *AuthHandler) ConfirmTotp(w http.ResponseWriter, r *http.Request) error {
+	var confirm mfaCodeDto
+	if err := request.JsonReqBody(r, &confirm); err != nil {
+		return err
+	}
+
+	recoveryCodes, err := h.authSrv.ConfirmTotp(r.Context(), middleware.AuthUsername(r), confirm.Code)
+	if err != nil {
+		return mfaErr(err)
+	}
+
+	response.SetHeader(w, "Cache-Control", "no-store")
+	return response.RespondJson(w, http.StatusOK, struct {
+		RecoveryCodes []string `json:"recoveryCodes"`
+	}{
+		RecoveryCodes: recoveryCodes,
+	})
+}
+
+func (h *AuthHandler) DisableMfa(w http.ResponseWriter, r *http.Request) error {
+	var disable mfaCodeDto
+	if err := request.JsonReqBody(r, &disable); err != nil {
+		return err
+	}
+
+	if err := h.authSrv.DisableMfa(r.Context(), middleware.AuthUsername(r), disable.Code); err != nil {
+		return mfaErr(err)
+	}
+
+	response.RespondStatus(w, http.StatusNoContent)
+	return nil
+}
+
+// VerifyMfa is the second sign in step, it issues the same tokens as sign in without MFA
+func (h *AuthHandler) VerifyMfa(w http.ResponseWriter, r *http.Request) error {
+	var verify user.MfaVerifyDto
+	if err := request.JsonReqBody(r, &verify); err != nil {
+		return err
+	}
+
+	if verify.ChallengeToken == "" || verify.Code == "" {
+		return webErrs.HttpBadRequestJsonErr("challenge token and code must be provided")
+	}
+
+	if request.GetCookieValue(r, h.rfrCfg.CookieName()) != "" {
+		return errors.New("refresh token cookie is set, logout first or refresh session")
+	}
+
+	jwt, rfrToken, err := h.authSrv.VerifyMfa(r.Context(), verify)
+	if err != nil {
+		var throttled *lockout.ThrottledErr
+		if errors.As(err, &throttled) {
+			return signinErr(w, err)
+		}
+		return mfaErr(err)
+	}
+
+	response.SetCookie(w, refreshCookie(h.rfrCfg, rfrToken))
+	return respondJwt(w, jwt)
+}
+
+func (h *AuthHandler) respondMfaRequired(w http.ResponseWriter, challenge *mfa.Challenge) error {
+	mfaData := struct {
+		Status         string `json:"status"`
+		ChallengeToken string `json:"challengeToken"`
+		ExpiresAt      int64  `json:"expiresAt"`
+	}{
+		Status:         mfaRequiredStatus,
+		ChallengeToken: challenge.Token(),
+		ExpiresAt:      challenge.ExpiresAt().Unix(),
+	}
+	return response.RespondJson(w, http.StatusOK, mfaData)
+}
+
+func mfaErr(err error) error {
+	switch {
+	case errors.Is(err, user.InvalidMfaCodeErr), errors.Is(err, service.MfaChallengeInvalidErr), errors.Is(err, mfa.ChallengeExpiredErr):
+		return errors.Wrap(webErrs.HttpUnauthorizedErr, err.Error())
+	case errors.Is(err, user.MfaAlreadyEnabledErr), errors.Is(err, user.MfaNotEnabledErr):
+		return webErrs.HttpBadRequestJsonErr(err.Error())
+	case errors.Is(err, user.UserDisabledErr), errors.Is(err, user.NotOrganizationMemberErr):
+		return errors.Wrap(webErrs.HttpForbiddenErr, err.Error())
+	case errors.Is(err, service.UserNotFoundErr):
+		return errors.Wrap(webErrs.HttpNotFoundErr, err.Error())
+	}
+	return err
+}
@@ -0,0 +1,312 @@
+package handler
+
+import (
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/umalmyha/authsrv/internal/business/lockout"
+	"github.com/umalmyha/authsrv/internal/business/user"
+	"github.com/umalmyha/authsrv/internal/infra/service"
+	webErrs "github.com/umalmyha/authsrv/pkg/web/errors"
+	"github.com/umalmyha/authsrv/pkg/web/middleware"
+	"github.com/umalmyha/authsrv/pkg/web/request"
+	"github.com/umalmyha/authsrv/pkg/web/response"
+)
+
+type OAuthHandler struct {
+	oauthSrv *service.OAuthService
+}
+
+func NewOAuthHandler(oauthSrv *service.OAuthService) *OAuthHandler {
+	return &OAuthHandler{
+		oauthSrv: oauthSrv,
+	}
+}
+
+// Authorize shows consent page of authorization request to signed in user, user who isn't signed in
+// is sent to sign in page first. Credentials are never accepted here.
+func (h *OAuthHandler) Authorize(w http.ResponseWriter, r *http.Request) error {
+	query := authorizeQuery(r.URL.Query())
+
+	authz := authorizeDto(query)
+	authz.SessionId = request.GetCookieValue(r, signInSessionCookie)
+
+	consent, err := h.oauthSrv.RequestConsent(r.Context(), authz)
+	if err != nil {
+		return h.authorizeErr(w, r, err, query)
+	}
+
+	csrf, err := csrfToken(w, r)
+	if err != nil {
+		return err
+	}
+
+	return respondPage(w, http.StatusOK, consentPage, consentPageData{
+		ClientName: consent.ClientName,
+		Username:   consent.Username,
+		Scopes:     consent.Scopes,
+		Csrf:       csrf,
+		Params:     query,
+	})
+}
+
+// Consent issues authorization code once signed in user approved authorization on consent page
+func (h *OAuthHandler) Consent(w http.ResponseWriter, r *http.Request) error {
+	if !verifyCsrfToken(r) {
+		return errors.Wrap(webErrs.HttpForbiddenErr, "csrf token is missing or invalid")
+	}
+	query := authorizeQuery(r.PostForm)
+
+	authz := authorizeDto(query)
+	authz.SessionId = request.GetCookieValue(r, signInSessionCookie)
+	authz.Approved = r.PostFormValue("decision") == "approve"
+
+	redirectUri, err := h.oauthSrv.Authorize(r.Context(), authz)
+	if err != nil {
+		return h.authorizeErr(w, r, err, query)
+	}
+
+	response.Redirect(w, r, redirectUri, http.StatusSeeOther)
+	return nil
+}
+
+// SignInPage shows sign in form, authorization request is resumed once user is signed in
+func (h *OAuthHandler) SignInPage(w http.ResponseWriter, r *http.Request) error {
+	csrf, err := csrfToken(w, r)
+	if err != nil {
+		return err
+	}
+
+	return respondPage(w, http.StatusOK, signInPage, signInPageData{
+		Csrf:   csrf,
+		Params: authorizeQuery(r.URL.Query()),
+	})
+}
+
+// SignIn authenticates user with credentials of sign in form and starts sign in session,
+// user is sent back to authorization request then
+func (h *OAuthHandler) SignIn(w http.ResponseWriter, r *http.Request) error {
+	if !verifyCsrfToken(r) {
+		return errors.Wrap(webErrs.HttpForbiddenErr, "csrf token is missing or invalid")
+	}
+	query := authorizeQuery(r.PostForm)
+
+	session, err := h.oauthSrv.SignIn(r.Context(), service.SignInDto{
+		Username: r.PostFormValue("username"),
+		Password: r.PostFormValue("password"),
+		MfaCode:  r.PostFormValue("mfa_code"),
+		ClientIp: request.ClientIp(r),
+	})
+	if err != nil {
+		status, message, ok := signInPageErr(w, err)
+		if !ok {
+			return err
+		}
+
+		return respondPage(w, status, signInPage, signInPageData{
+			Error:    message,
+			Csrf:     r.PostFormValue(csrfField),
+			Username: r.PostFormValue("username"),
+			Params:   query,
+		})
+	}
+
+	response.SetCookie(w, &http.Cookie{
+		Name:     signInSessionCookie,
+		Value:    session.Id(),
+		Path:     "/",
+		MaxAge:   int(time.Until(session.ExpiresAt()).Seconds()),
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	response.Redirect(w, r, "authorize?"+query.Encode(), http.StatusSeeOther)
+	return nil
+}
+
+// SignOut ends sign in session, user is sent to sign in page of authorization request
+func (h *OAuthHandler) SignOut(w http.ResponseWriter, r *http.Request) error {
+	if !verifyCsrfToken(r) {
+		return errors.Wrap(webErrs.HttpForbiddenErr, "csrf token is missing or invalid")
+	}
+
+	if err := h.oauthSrv.SignOut(r.Context(), request.GetCookieValue(r, signInSessionCookie)); err != nil {
+		return err
+	}
+	response.DeleteCookie(r, w, signInSessionCookie)
+
+	response.Redirect(w, r, "signin?"+authorizeQuery(r.PostForm).Encode(), http.StatusSeeOther)
+	return nil
+}
+
+// authorizeErr delivers error to client if redirect uri is trusted, user who isn't signed in is sent to sign in page
+func (h *OAuthHandler) authorizeErr(w http.ResponseWriter, r *http.Request, err error, query url.Values) error {
+	if errors.Is(err, service.SignInRequiredErr) {
+		response.Redirect(w, r, "signin?"+query.Encode(), http.StatusSeeOther)
+		return nil
+	}
+
+	var oauthErr *service.OAuthErr
+	if errors.As(err, &oauthErr) {
+		if uri := oauthErr.RedirectUri(); uri != "" {
+			response.Redirect(w, r, uri, http.StatusSeeOther)
+			return nil
+		}
+		return response.RespondJson(w, oauthErr.Status(), oauthErr)
+	}
+	return err
+}
+
+// signInPageErr maps sign in failure to status and message shown on sign in page
+func signInPageErr(w http.ResponseWriter, err error) (int, string, bool) {
+	var throttled *lockout.ThrottledErr
+	switch {
+	case errors.As(err, &throttled):
+		retryAfter := int(math.Ceil(throttled.RetryAfter().Seconds()))
+		response.SetHeader(w, "Retry-After", strconv.Itoa(retryAfter))
+		return http.StatusTooManyRequests, "Too many failed attempts, try again later.", true
+	case errors.Is(err, service.EmailNotVerifiedErr):
+		return http.StatusForbidden, "Verify your email before signing in.", true
+	case errors.Is(err, user.UserDisabledErr):
+		return http.StatusForbidden, "Your account is disabled.", true
+	case errors.Is(err, service.InvalidCredentialsErr):
+		return http.StatusUnauthorized, "Invalid username, password or verification code.", true
+	}
+	return 0, "", false
+}
+
+func authorizeDto(query url.Values) service.AuthorizeDto {
+	return service.AuthorizeDto{
+		ResponseType:        query.Get("response_type"),
+		ClientId:            query.Get("client_id"),
+		RedirectUri:         query.Get("redirect_uri"),
+		Scope:               query.Get("scope"),
+		State:               query.Get("state"),
+		Nonce:               query.Get("nonce"),
+		CodeChallenge:       query.Get("code_challenge"),
+		CodeChallengeMethod: query.Get("code_challenge_method"),
+	}
+}
+
+func (h *OAuthHandler) Token(w http.ResponseWriter, r *http.Request) error {
+	tkn := service.TokenDto{
+		GrantType:    r.PostFormValue("grant_type"),
+		ClientId:     r.PostFormValue("client_id"),
+		ClientSecret: r.PostFormValue("client_secret"),
+		Scope:        r.PostFormValue("scope"),
+		Code:         r.PostFormValue("code"),
+		RedirectUri:  r.PostFormValue("redirect_uri"),
+		CodeVerifier: r.PostFormValue("code_verifier"),
+		RefreshToken: r.PostFormValue("refresh_token"),
+		Client:       clientInfo(r),
+	}
+
+	basicAuth, err := clientCredentials(r, &tkn.ClientId, &tkn.ClientSecret)
+	if err != nil {
+		return err
+	}
+
+	response.SetHeader(w, "Cache-Control", "no-store")
+	response.SetHeader(w, "Pragma", "no-cache")
+
+	resp, err := h.oauthSrv.Token(r.Context(), tkn)
+	if err != nil {
+		return respondOAuthErr(w, err, basicAuth)
+	}
+
+	return response.RespondJson(w, http.StatusOK, resp)
+}
+
+func (h *OAuthHandler) Introspect(w http.ResponseWriter, r *http.Request) error {
+	intr := service.IntrospectDto{
+		ClientId:      r.PostFormValue("client_id"),
+		ClientSecret:  r.PostFormValue("client_secret"),
+		Token:         r.PostFormValue("token"),
+		TokenTypeHint: r.PostFormValue("token_type_hint"),
+	}
+
+	basicAuth, err := clientCredentials(r, &intr.ClientId, &intr.ClientSecret)
+	if err != nil {
+		return err
+	}
+
+	response.SetHeader(w, "Cache-Control", "no-store")
+
+	resp, err := h.oauthSrv.Introspect(r.Context(), intr)
+	if err != nil {
+		return respondOAuthErr(w, err, basicAuth)
+	}
+
+	return response.RespondJson(w, http.StatusOK, resp)
+}
+
+func (h *OAuthHandler) Revoke(w http.ResponseWriter, r *http.Request) error {
+	rvk := service.RevokeDto{
+		ClientId:      r.PostFormValue("client_id"),
+		ClientSecret:  r.PostFormValue("client_secret"),
+		Token:         r.PostFormValue("token"),
+		TokenTypeHint: r.PostFormValue("token_type_hint"),
+	}
+
+	basicAuth, err := clientCredentials(r, &rvk.ClientId, &rvk.ClientSecret)
+	if err != nil {
+		return err
+	}
+
+	if err := h.oauthSrv.Revoke(r.Context(), rvk); err != nil {
+		return respondOAuthErr(w, err, basicAuth)
+	}
+
+	response.RespondStatus(w, http.StatusOK)
+	return nil
+}
+
+// RateLimitByClientId counts requests by client_id of basic auth or body, so services don't share budget
+// with users behind the same address, requests without client are counted by ip
+func RateLimitByClientId(r *http.Request) string {
+	var clientId, clientSecret string
+	if _, err := clientCredentials(r, &clientId, &clientSecret); err != nil || clientId == "" {
+		clientId = r.PostFormValue("client_id")
+	}
+
+	if clientId == "" {
+		return middleware.RateLimitByIp(r)
+	}
+	return "client:" + clientId
+}
+
+// clientCredentials reads client_secret_basic credentials, they take precedence over credentials in body
+// and their values are form-encoded (RFC 6749 2.3.1), returns true if basic auth was used
+func clientCredentials(r *http.Request, clientId *string, clientSecret *string) (bool, error) {
+	id, secret, ok := r.BasicAuth()
+	if !ok {
+		return false, nil
+	}
+
+	var err error
+	if *clientId, err = url.QueryUnescape(id); err != nil {
+		return false, webErrs.HttpBadRequestJsonErr(err.Error())
+	}
+
+	if *clientSecret, err = url.QueryUnescape(secret); err != nil {
+		return false, webErrs.HttpBadRequestJsonErr(err.Error())
+	}
+	return true, nil
+}
+
+func respondOAuthErr(w http.ResponseWriter, err error, basicAuth bool) error {
+	var oauthErr *service.OAuthErr
+	if errors.As(err, &oauthErr) {
+		if oauthErr.Status() == http.StatusUnauthorized && basicAuth {
+			response.SetHeader(w, "WWW-Authenticate", `Basic realm="authsrv"`)
+		}
+		return response.RespondJson(w, oauthErr.Status(), oauthErr)
+	}
+	return err
+}
@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"html/template"
+	"net/http"
+	"net/url"
+
+	"github.com/pkg/errors"
+
+	"github.com/umalmyha/authsrv/pkg/web/request"
+	"github.com/umalmyha/authsrv/pkg/web/response"
+)
+
+const (
+	signInSessionCookie = "authsrv_session"
+	csrfCookie          = "authsrv_csrf"
+	csrfField           = "csrf_token"
+	csrfTokenLength     = 32
+)
+
+// authorizeParams are parameters of authorization request, they are carried through sign in and consent pages
+var authorizeParams = []string{
+	"response_type",
+	"client_id",
+	"redirect_uri",
+	"scope",
+	"state",
+	"nonce",
+	"code_challenge",
+	"code_challenge_method",
+}
+
+var signInPage = template.Must(template.New("signin").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head><meta charset="utf-8"><title>Sign in</title></head>
+<body>
+<h1>Sign in</h1>
+{{if .Error}}<p role="alert">{{.Error}}</p>{{end}}
+<form method="post" action="signin">
+<input type="hidden" name="csrf_token" value="{{.Csrf}}">
+{{range $name, $values := .Params}}{{range $values}}<input type="hidden" name="{{$name}}" value="{{.}}">
+{{end}}{{end}}<p><label>Username or email <input type="text" name="username" value="{{.Username}}" autocomplete="username" required autofocus></label></p>
+<p><label>Password <input type="password" name="password" autocomplete="current-password" required></label></p>
+<p><label>Verification or recovery code, if MFA is enabled <input type="text" name="mfa_code" autocomplete="one-time-code"></label></p>
+<p><button type="submit">Sign in</button></p>
+</form>
+</body>
+</html>
+`))
+
+var consentPage = template.Must(template.New("consent").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head><meta charset="utf-8"><title>Authorize {{.ClientName}}</title></head>
+<body>
+<h1>Authorize {{.ClientName}}</h1>
+<p>{{.ClientName}} requests access to account {{.Username}}.</p>
+{{if .Scopes}}<p>Requested scopes:</p>
+<ul>
+{{range .Scopes}}<li>{{.}}</li>
+{{end}}</ul>
+{{end}}<form method="post" action="authorize">
+<input type="hidden" name="csrf_token" value="{{.Csrf}}">
+{{range $name, $values := .Params}}{{range $values}}<input type="hidden" name="{{$name}}" value="{{.}}">
+{{end}}{{end}}<p><button type="submit" name="decision" value="approve">Allow</button> <button type="submit" name="decision" value="deny">Deny</button></p>
+</form>
+<form method="post" action="signout">
+<input type="hidden" name="csrf_token" value="{{.Csrf}}">
+{{range $name, $values := .Params}}{{range $values}}<input type="hidden" name="{{$name}}" value="{{.}}">
+{{end}}{{end}}<p>Not {{.Username}}? <button type="submit">Sign out</button></p>
+</form>
+</body>
+</html>
+`))
+
+type signInPageData struct {
+	Error    string
+	Csrf     string
+	Username string
+	Params   url.Values
+}
+
+type consentPageData struct {
+	ClientName string
+	Username   string
+	Scopes     []string
+	Csrf       string
+	Params     url.Values
+}
+
+// respondPage renders page of authorization flow, pages must never be cached or framed by other sites
+func respondPage(w http.ResponseWriter, status int, page *template.Template, data any) error {
+	var buf bytes.Buffer
+	if err := page.Execute(&buf, data); err != nil {
+		return errors.Wrapf(err, "failed to render %s page", page.Name())
+	}
+
+	response.SetHeader(w, "Cache-Control", "no-store")
+	response.SetHeader(w, "X-Frame-Options", "DENY")
+	response.SetHeader(w, "Content-Security-Policy", "default-src 'none'; frame-ancestors 'none'")
+	return response.RespondHtml(w, status, buf.Bytes())
+}
+
+// authorizeQuery keeps parameters of authorization request only, so nothing else is carried through pages
+func authorizeQuery(values url.Values) url.Values {
+	query := url.Values{}
+	for _, name := range authorizeParams {
+		if value := values.Get(name); value != "" {
+			query.Set(name, value)
+		}
+	}
+	return query
+}
+
+// csrfToken returns token forms of authorization pages are protected with, token is kept in cookie
+// other sites can neither read nor send along with their forms, so form must carry the same token
+func csrfToken(w http.ResponseWriter, r *http.Request) (string, error) {
+	if token := request.GetCookieValue(r, csrfCookie); token != "" {
+		return token, nil
+	}
+
+	raw := make([]byte, csrfTokenLength)
+	if _, err := rand.Read(raw); err != nil {
+		return "", errors.Wrap(err, "failed to generate csrf token")
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	response.SetCookie(w, &http.Cookie{
+		Name:     csrfCookie,
+		Value:    token,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteStrictMode,
+	})
+	return token, nil
+}
+
+// verifyCsrfToken compares token of form with token of cookie, form is parsed from request body only
+func verifyCsrfToken(r *http.Request) bool {
+	formToken := r.PostFormValue(csrfField)
+	token := request.GetCookieValue(r, csrfCookie)
+	return token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(formToken)) == 1
+}
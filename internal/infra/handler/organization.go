@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/pkg/errors"
+
+	"github.com/umalmyha/authsrv/internal/business/organization"
+	"github.com/umalmyha/authsrv/internal/infra/service"
+	webErrs "github.com/umalmyha/authsrv/pkg/web/errors"
+	"github.com/umalmyha/authsrv/pkg/web/middleware"
+	"github.com/umalmyha/authsrv/pkg/web/request"
+	"github.com/umalmyha/authsrv/pkg/web/response"
+)
+
+type OrganizationHandler struct {
+	orgSrv *service.OrganizationService
+}
+
+func NewOrganizationHandler(orgSrv *service.OrganizationService) *OrganizationHandler {
+	return &OrganizationHandler{
+		orgSrv: orgSrv,
+	}
+}
+
+func (h *OrganizationHandler) CreateOrganization(w http.ResponseWriter, r *http.Request) error {
+	var no organization.NewOrganizationDto
+	if err := request.JsonReqBody(r, &no); err != nil {
+		return err
+	}
+
+	org, err := h.orgSrv.CreateOrganization(r.Context(), no)
+	if err != nil {
+		return err
+	}
+	return response.RespondJson(w, http.StatusCreated, org)
+}
+
+func (h *OrganizationHandler) Organizations(w http.ResponseWriter, r *http.Request) error {
+	orgs, err := h.orgSrv.Organizations(r.Context())
+	if err != nil {
+		return err
+	}
+	return response.RespondJson(w, http.StatusOK, orgs)
+}
+
+func (h *OrganizationHandler) Organization(w http.ResponseWriter, r *http.Request) error {
+	org, err := h.orgSrv.Organization(r.Context(), request.PathParam(r, "org"))
+	if err != nil {
+		return organizationErr(err)
+	}
+	return response.RespondJson(w, http.StatusOK, org)
+}
+
+// MyOrganizations lists organizations caller is member of, so session can be switched to one of them
+func (h *OrganizationHandler) MyOrganizations(w http.ResponseWriter, r *http.Request) error {
+	orgs, err := h.orgSrv.UserOrganizations(r.Context(), middleware.AuthUsername(r))
+	if err != nil {
+		return organizationErr(err)
+	}
+	return response.RespondJson(w, http.StatusOK, orgs)
+}
+
+// DeleteOrganization deletes organization together with its members and roles assigned to them
+func (h *OrganizationHandler) DeleteOrganization(w http.ResponseWriter, r *http.Request) error {
+	if err := h.orgSrv.DeleteOrganization(r.Context(), request.PathParam(r, "org")); err != nil {
+		return organizationErr(err)
+	}
+
+	response.RespondStatus(w, http.StatusNoContent)
+	return nil
+}
+
+func (h *OrganizationHandler) Members(w http.ResponseWriter, r *http.Request) error {
+	members, err := h.orgSrv.Members(r.Context(), request.PathParam(r, "org"))
+	if err != nil {
+		return organizationErr(err)
+	}
+	return response.RespondJson(w, http.StatusOK, members)
+}
+
+func (h *OrganizationHandler) AddMember(w http.ResponseWriter, r *http.Request) error {
+	member := struct {
+		Username string `json:"username"`
+	}{}
+
+	if err := request.JsonReqBody(r, &member); err != nil {
+		return err
+	}
+
+	if err := h.orgSrv.AddMember(r.Context(), request.PathParam(r, "org"), member.Username); err != nil {
+		return organizationErr(err)
+	}
+
+	response.RespondStatus(w, http.StatusNoContent)
+	return nil
+}
+
+func (h *OrganizationHandler) RemoveMember(w http.ResponseWriter, r *http.Request) error {
+	if err := h.orgSrv.RemoveMember(r.Context(), request.PathParam(r, "org"), request.PathParam(r, "username")); err != nil {
+		return organizationErr(err)
+	}
+
+	response.RespondStatus(w, http.StatusNoContent)
+	return nil
+}
+
+// AssignRole assigns role to member within organization
+func (h *OrganizationHandler) AssignRole(w http.ResponseWriter, r *http.Request) error {
+	assignment := struct {
+		RoleName string `json:"role"`
+	}{}
+
+	if err := request.JsonReqBody(r, &assignment); err != nil {
+		return err
+	}
+
+	err := h.orgSrv.AssignRole(r.Context(), request.PathParam(r, "org"), request.PathParam(r, "username"), assignment.RoleName)
+	if err != nil {
+		return organizationErr(err)
+	}
+
+	response.RespondStatus(w, http.StatusNoContent)
+	return nil
+}
+
+func (h *OrganizationHandler) UnassignRole(w http.ResponseWriter, r *http.Request) error {
+	err := h.orgSrv.UnassignRole(r.Context(), request.PathParam(r, "org"), request.PathParam(r, "username"), request.PathParam(r, "role"))
+	if err != nil {
+		return organizationErr(err)
+	}
+
+	response.RespondStatus(w, http.StatusNoContent)
+	return nil
+}
+
+func organizationErr(err error) error {
+	switch {
+	case errors.Is(err, service.OrganizationNotFoundErr), errors.Is(err, service.UserNotFoundErr):
+		return errors.Wrap(webErrs.HttpNotFoundErr, err.Error())
+	case errors.Is(err, organization.MemberExistsErr), errors.Is(err, organization.NotMemberErr):
+		return webErrs.HttpBadRequestJsonErr(err.Error())
+	}
+	return err
+}
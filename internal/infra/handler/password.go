@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/pkg/errors"
+
+	"github.com/umalmyha/authsrv/internal/business/user"
+	valueobj "github.com/umalmyha/authsrv/internal/business/value-object"
+	"github.com/umalmyha/authsrv/internal/infra/service"
+	webErrs "github.com/umalmyha/authsrv/pkg/web/errors"
+	"github.com/umalmyha/authsrv/pkg/web/middleware"
+	"github.com/umalmyha/authsrv/pkg/web/request"
+	"github.com/umalmyha/authsrv/pkg/web/response"
+)
+
+type PasswordHandler struct {
+	passwordSrv *service.PasswordService
+	rfrCfg      valueobj.RefreshTokenConfig
+}
+
+func NewPasswordHandler(passwordSrv *service.PasswordService, rfrCfg valueobj.RefreshTokenConfig) *PasswordHandler {
+	return &PasswordHandler{
+		passwordSrv: passwordSrv,
+		rfrCfg:      rfrCfg,
+	}
+}
+
+// ForgotPassword always responds with accepted status, so it can't be used to find out existing users
+func (h *PasswordHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) error {
+	var forgot struct {
+		Username string `json:"username"`
+	}
+	if err := request.JsonReqBody(r, &forgot); err != nil {
+		return err
+	}
+
+	if err := h.passwordSrv.ForgotPassword(r.Context(), forgot.Username); err != nil {
+		return err
+	}
+
+	response.RespondStatus(w, http.StatusAccepted)
+	return nil
+}
+
+func (h *PasswordHandler) ResetPassword(w http.ResponseWriter, r *http.Request) error {
+	var reset user.PasswordResetDto
+	if err := request.JsonReqBody(r, &reset); err != nil {
+		return err
+	}
+
+	if err := h.passwordSrv.ResetPassword(r.Context(), reset); err != nil {
+		return passwordErr(err)
+	}
+
+	response.RespondStatus(w, http.StatusNoContent)
+	return nil
+}
+
+// ChangePassword changes password of caller, session of refresh token cookie survives revocation of other sessions
+func (h *PasswordHandler) ChangePassword(w http.ResponseWriter, r *http.Request) error {
+	var change user.PasswordChangeDto
+	if err := request.JsonReqBody(r, &change); err != nil {
+		return err
+	}
+
+	currentTokenId := request.GetCookieValue(r, h.rfrCfg.CookieName())
+	if err := h.passwordSrv.ChangePassword(r.Context(), middleware.AuthUsername(r), change, currentTokenId); err != nil {
+		return passwordErr(err)
+	}
+
+	response.RespondStatus(w, http.StatusNoContent)
+	return nil
+}
+
+func passwordErr(err error) error {
+	switch {
+	case errors.Is(err, service.ResetTokenInvalidErr),
+		errors.Is(err, user.InvalidPasswordErr),
+		errors.Is(err, user.WrongPasswordErr):
+		return webErrs.HttpBadRequestJsonErr(err.Error())
+	case errors.Is(err, service.UserNotFoundErr):
+		return errors.Wrap(webErrs.HttpNotFoundErr, err.Error())
+	}
+	return err
+}
@@ -3,9 +3,14 @@ package handler
 import (
 	"net/http"
 
+	"github.com/pkg/errors"
+
 	"github.com/umalmyha/authsrv/internal/business/role"
+	valueobj "github.com/umalmyha/authsrv/internal/business/value-object"
 	"github.com/umalmyha/authsrv/internal/infra/service"
+	webErrs "github.com/umalmyha/authsrv/pkg/web/errors"
 	"github.com/umalmyha/authsrv/pkg/web/request"
+	"github.com/umalmyha/authsrv/pkg/web/response"
 )
 
 type RoleHandler struct {
@@ -49,3 +54,99 @@ func (h *RoleHandler) UnassignScope(w http.ResponseWriter, r *http.Request) erro
 	}
 	return h.roleSrv.UnassignScope(r.Context(), assignment.RoleName, assignment.ScopeName)
 }
+
+// InheritRole makes role inherit scopes of parent role
+func (h *RoleHandler) InheritRole(w http.ResponseWriter, r *http.Request) error {
+	inheritance := struct {
+		RoleName   string `json:"role"`
+		ParentName string `json:"parent"`
+	}{}
+
+	if err := request.JsonReqBody(r, &inheritance); err != nil {
+		return err
+	}
+
+	if err := h.roleSrv.InheritRole(r.Context(), inheritance.RoleName, inheritance.ParentName); err != nil {
+		return roleErr(err)
+	}
+
+	response.RespondStatus(w, http.StatusNoContent)
+	return nil
+}
+
+func (h *RoleHandler) DisinheritRole(w http.ResponseWriter, r *http.Request) error {
+	inheritance := struct {
+		RoleName   string `json:"role"`
+		ParentName string `json:"parent"`
+	}{}
+
+	if err := request.JsonReqBody(r, &inheritance); err != nil {
+		return err
+	}
+
+	if err := h.roleSrv.DisinheritRole(r.Context(), inheritance.RoleName, inheritance.ParentName); err != nil {
+		return roleErr(err)
+	}
+
+	response.RespondStatus(w, http.StatusNoContent)
+	return nil
+}
+
+func (h *RoleHandler) Roles(w http.ResponseWriter, r *http.Request) error {
+	roles, err := h.roleSrv.Roles(r.Context())
+	if err != nil {
+		return err
+	}
+	return response.RespondJson(w, http.StatusOK, roles)
+}
+
+func (h *RoleHandler) Role(w http.ResponseWriter, r *http.Request) error {
+	details, err := h.roleSrv.Role(r.Context(), request.PathParam(r, "roleId"))
+	if err != nil {
+		return roleErr(err)
+	}
+	return response.RespondJson(w, http.StatusOK, details)
+}
+
+func (h *RoleHandler) UpdateRole(w http.ResponseWriter, r *http.Request) error {
+	var update role.UpdateRoleDto
+	if err := request.JsonReqBody(r, &update); err != nil {
+		return err
+	}
+
+	details, err := h.roleSrv.UpdateRole(r.Context(), request.PathParam(r, "roleId"), update)
+	if err != nil {
+		return roleErr(err)
+	}
+	return response.RespondJson(w, http.StatusOK, details)
+}
+
+// DeleteRole deletes role, role assigned to users or groups or inherited by roles is deleted only with query parameter policy=cascade
+func (h *RoleHandler) DeleteRole(w http.ResponseWriter, r *http.Request) error {
+	policy, err := valueobj.ParseDeletePolicy(request.UrlParam(r, "policy"))
+	if err != nil {
+		return webErrs.HttpBadRequestJsonErr(err.Error())
+	}
+
+	report, err := h.roleSrv.DeleteRole(r.Context(), request.PathParam(r, "roleId"), policy)
+	if err != nil {
+		if errors.Is(err, service.RoleInUseErr) {
+			return webErrs.HttpConflictJsonErr(struct {
+				Error string `json:"error"`
+				role.DeletionReportDto
+			}{Error: err.Error(), DeletionReportDto: report})
+		}
+		return roleErr(err)
+	}
+	return response.RespondJson(w, http.StatusOK, report)
+}
+
+func roleErr(err error) error {
+	switch {
+	case errors.Is(err, service.RoleNotFoundErr):
+		return errors.Wrap(webErrs.HttpNotFoundErr, err.Error())
+	case errors.Is(err, role.HierarchyCycleErr), errors.Is(err, service.RoleBuiltinErr):
+		return webErrs.HttpBadRequestJsonErr(err.Error())
+	}
+	return err
+}
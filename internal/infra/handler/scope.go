@@ -3,9 +3,14 @@ package handler
 import (
 	"net/http"
 
+	"github.com/pkg/errors"
+
 	"github.com/umalmyha/authsrv/internal/business/scope"
+	valueobj "github.com/umalmyha/authsrv/internal/business/value-object"
 	"github.com/umalmyha/authsrv/internal/infra/service"
+	webErrs "github.com/umalmyha/authsrv/pkg/web/errors"
 	"github.com/umalmyha/authsrv/pkg/web/request"
+	"github.com/umalmyha/authsrv/pkg/web/response"
 )
 
 type ScopeHandler struct {
@@ -25,3 +30,62 @@ func (h *ScopeHandler) CreateScope(w http.ResponseWriter, r *http.Request) error
 	}
 	return h.scopeSrv.CreateScope(r.Context(), ns)
 }
+
+func (h *ScopeHandler) Scopes(w http.ResponseWriter, r *http.Request) error {
+	scopes, err := h.scopeSrv.Scopes(r.Context())
+	if err != nil {
+		return err
+	}
+	return response.RespondJson(w, http.StatusOK, scopes)
+}
+
+func (h *ScopeHandler) Scope(w http.ResponseWriter, r *http.Request) error {
+	sc, err := h.scopeSrv.Scope(r.Context(), request.PathParam(r, "scopeId"))
+	if err != nil {
+		return scopeErr(err)
+	}
+	return response.RespondJson(w, http.StatusOK, sc)
+}
+
+func (h *ScopeHandler) UpdateScope(w http.ResponseWriter, r *http.Request) error {
+	var update scope.UpdateScopeDto
+	if err := request.JsonReqBody(r, &update); err != nil {
+		return err
+	}
+
+	sc, err := h.scopeSrv.UpdateScope(r.Context(), request.PathParam(r, "scopeId"), update)
+	if err != nil {
+		return scopeErr(err)
+	}
+	return response.RespondJson(w, http.StatusOK, sc)
+}
+
+// DeleteScope deletes scope, scope assigned to roles or clients is deleted only with query parameter policy=cascade
+func (h *ScopeHandler) DeleteScope(w http.ResponseWriter, r *http.Request) error {
+	policy, err := valueobj.ParseDeletePolicy(request.UrlParam(r, "policy"))
+	if err != nil {
+		return webErrs.HttpBadRequestJsonErr(err.Error())
+	}
+
+	report, err := h.scopeSrv.DeleteScope(r.Context(), request.PathParam(r, "scopeId"), policy)
+	if err != nil {
+		if errors.Is(err, service.ScopeInUseErr) {
+			return webErrs.HttpConflictJsonErr(struct {
+				Error string `json:"error"`
+				scope.DeletionReportDto
+			}{Error: err.Error(), DeletionReportDto: report})
+		}
+		return scopeErr(err)
+	}
+	return response.RespondJson(w, http.StatusOK, report)
+}
+
+func scopeErr(err error) error {
+	switch {
+	case errors.Is(err, service.ScopeNotFoundErr):
+		return errors.Wrap(webErrs.HttpNotFoundErr, err.Error())
+	case errors.Is(err, service.ScopeBuiltinErr):
+		return webErrs.HttpBadRequestJsonErr(err.Error())
+	}
+	return err
+}
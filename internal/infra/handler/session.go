@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/pkg/errors"
+
+	"github.com/umalmyha/authsrv/internal/business/user"
+	valueobj "github.com/umalmyha/authsrv/internal/business/value-object"
+	"github.com/umalmyha/authsrv/internal/infra/service"
+	webErrs "github.com/umalmyha/authsrv/pkg/web/errors"
+	"github.com/umalmyha/authsrv/pkg/web/middleware"
+	"github.com/umalmyha/authsrv/pkg/web/request"
+	"github.com/umalmyha/authsrv/pkg/web/response"
+)
+
+type SessionHandler struct {
+	sessionSrv *service.SessionService
+	rfrCfg     valueobj.RefreshTokenConfig
+}
+
+func NewSessionHandler(sessionSrv *service.SessionService, rfrCfg valueobj.RefreshTokenConfig) *SessionHandler {
+	return &SessionHandler{
+		sessionSrv: sessionSrv,
+		rfrCfg:     rfrCfg,
+	}
+}
+
+func (h *SessionHandler) Sessions(w http.ResponseWriter, r *http.Request) error {
+	sessions, err := h.sessionSrv.Sessions(r.Context(), middleware.AuthUsername(r))
+	if err != nil {
+		return sessionErr(err)
+	}
+	return response.RespondJson(w, http.StatusOK, sessions)
+}
+
+func (h *SessionHandler) RevokeSession(w http.ResponseWriter, r *http.Request) error {
+	if err := h.sessionSrv.RevokeSession(r.Context(), middleware.AuthUsername(r), request.PathParam(r, "id")); err != nil {
+		return sessionErr(err)
+	}
+
+	response.RespondStatus(w, http.StatusNoContent)
+	return nil
+}
+
+// RevokeAllSessions logs caller out everywhere including current device
+func (h *SessionHandler) RevokeAllSessions(w http.ResponseWriter, r *http.Request) error {
+	if err := h.sessionSrv.RevokeAllSessions(r.Context(), middleware.AuthUsername(r)); err != nil {
+		return sessionErr(err)
+	}
+
+	response.DeleteCookie(r, w, h.rfrCfg.CookieName())
+	response.RespondStatus(w, http.StatusNoContent)
+	return nil
+}
+
+func (h *SessionHandler) UserSessions(w http.ResponseWriter, r *http.Request) error {
+	sessions, err := h.sessionSrv.UserSessions(r.Context(), request.PathParam(r, "userId"))
+	if err != nil {
+		return sessionErr(err)
+	}
+	return response.RespondJson(w, http.StatusOK, sessions)
+}
+
+func (h *SessionHandler) RevokeUserSession(w http.ResponseWriter, r *http.Request) error {
+	if err := h.sessionSrv.RevokeUserSession(r.Context(), request.PathParam(r, "userId"), request.PathParam(r, "id")); err != nil {
+		return sessionErr(err)
+	}
+
+	response.RespondStatus(w, http.StatusNoContent)
+	return nil
+}
+
+func (h *SessionHandler) RevokeAllUserSessions(w http.ResponseWriter, r *http.Request) error {
+	if err := h.sessionSrv.RevokeAllUserSessions(r.Context(), request.PathParam(r, "userId")); err != nil {
+		return sessionErr(err)
+	}
+
+	response.RespondStatus(w, http.StatusNoContent)
+	return nil
+}
+
+func sessionErr(err error) error {
+	if errors.Is(err, service.UserNotFoundErr) || errors.Is(err, user.SessionNotFoundErr) {
+		return errors.Wrap(webErrs.HttpNotFoundErr, err.Error())
+	}
+	return err
+}
@@ -2,31 +2,164 @@ package handler
 
 import (
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/pkg/errors"
+
+	"github.com/umalmyha/authsrv/internal/business/user"
+	valueobj "github.com/umalmyha/authsrv/internal/business/value-object"
 	"github.com/umalmyha/authsrv/internal/infra/service"
+	webErrs "github.com/umalmyha/authsrv/pkg/web/errors"
+	"github.com/umalmyha/authsrv/pkg/web/middleware"
 	"github.com/umalmyha/authsrv/pkg/web/request"
+	"github.com/umalmyha/authsrv/pkg/web/response"
+)
+
+const (
+	defaultUsersLimit = 20
+	maxUsersLimit     = 100
 )
 
 type UserHandler struct {
-	userSrv *service.UserService
+	userSrv  *service.UserService
+	emailSrv *service.EmailService
 }
 
-func NewUserHandler(userSrv *service.UserService) *UserHandler {
+func NewUserHandler(userSrv *service.UserService, emailSrv *service.EmailService) *UserHandler {
 	return &UserHandler{
-		userSrv: userSrv,
+		userSrv:  userSrv,
+		emailSrv: emailSrv,
+	}
+}
+
+func (h *UserHandler) Profile(w http.ResponseWriter, r *http.Request) error {
+	profile, err := h.userSrv.Profile(r.Context(), middleware.AuthUsername(r))
+	if err != nil {
+		return profileErr(err)
+	}
+	return response.RespondJson(w, http.StatusOK, profile)
+}
+
+// UpdateProfile changes profile of caller, verification link is sent if email is changed
+func (h *UserHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) error {
+	var update user.ProfileUpdateDto
+	if err := request.JsonReqBody(r, &update); err != nil {
+		return err
+	}
+
+	username := middleware.AuthUsername(r)
+
+	profile, emailChanged, err := h.userSrv.UpdateProfile(r.Context(), username, update)
+	if err != nil {
+		return profileErr(err)
+	}
+
+	if emailChanged {
+		if err := h.emailSrv.RequestVerification(r.Context(), username); err != nil {
+			return err
+		}
+	}
+	return response.RespondJson(w, http.StatusOK, profile)
+}
+
+// Users lists users, supported query parameters are username, email, organization, role, disabled, sort (field name,
+// prefixed with "-" for descending order), limit and offset. Role is matched within organization if it is set.
+func (h *UserHandler) Users(w http.ResponseWriter, r *http.Request) error {
+	filter, err := userFilter(r)
+	if err != nil {
+		return webErrs.HttpBadRequestJsonErr(err.Error())
+	}
+
+	// token issued for organization sees only members of it
+	if org := middleware.AuthOrganization(r); org != "" {
+		if filter.Organization != "" && filter.Organization != org {
+			return errors.Wrapf(webErrs.HttpForbiddenErr, "users of organization %s can't be listed with token of organization %s", filter.Organization, org)
+		}
+		filter.Organization = org
+	}
+
+	page, err := h.userSrv.Users(r.Context(), filter)
+	if err != nil {
+		return err
+	}
+	return response.RespondJson(w, http.StatusOK, page)
+}
+
+func (h *UserHandler) User(w http.ResponseWriter, r *http.Request) error {
+	usr, err := h.userSrv.User(r.Context(), request.PathParam(r, "userId"))
+	if err != nil {
+		return profileErr(err)
+	}
+	return response.RespondJson(w, http.StatusOK, usr)
+}
+
+// UpdateUser changes profile of user on its behalf, verification link is sent if email is changed
+func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) error {
+	var update user.ProfileUpdateDto
+	if err := request.JsonReqBody(r, &update); err != nil {
+		return err
+	}
+
+	usr, emailChanged, err := h.userSrv.UpdateUser(r.Context(), request.PathParam(r, "userId"), update)
+	if err != nil {
+		return profileErr(err)
+	}
+
+	if emailChanged {
+		if err := h.emailSrv.RequestVerification(r.Context(), usr.Username); err != nil {
+			return err
+		}
+	}
+	return response.RespondJson(w, http.StatusOK, usr)
+}
+
+func (h *UserHandler) DisableUser(w http.ResponseWriter, r *http.Request) error {
+	if err := h.userSrv.DisableUser(r.Context(), request.PathParam(r, "userId")); err != nil {
+		return profileErr(err)
+	}
+
+	response.RespondStatus(w, http.StatusNoContent)
+	return nil
+}
+
+func (h *UserHandler) EnableUser(w http.ResponseWriter, r *http.Request) error {
+	if err := h.userSrv.EnableUser(r.Context(), request.PathParam(r, "userId")); err != nil {
+		return profileErr(err)
+	}
+
+	response.RespondStatus(w, http.StatusNoContent)
+	return nil
+}
+
+func (h *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) error {
+	if err := h.userSrv.DeleteUser(r.Context(), request.PathParam(r, "userId")); err != nil {
+		return profileErr(err)
 	}
+
+	response.RespondStatus(w, http.StatusNoContent)
+	return nil
 }
 
+// AssignRole assigns role to user, optional validFrom and validUntil limit time role is effective within
 func (h *UserHandler) AssignRole(w http.ResponseWriter, r *http.Request) error {
 	assingment := struct {
-		Username string `json:"username"`
-		RoleName string `json:"role"`
+		Username   string     `json:"username"`
+		RoleName   string     `json:"role"`
+		ValidFrom  *time.Time `json:"validFrom"`
+		ValidUntil *time.Time `json:"validUntil"`
 	}{}
 
 	if err := request.JsonReqBody(r, &assingment); err != nil {
 		return err
 	}
-	return h.userSrv.AssignRole(r.Context(), assingment.Username, assingment.RoleName)
+
+	err := h.userSrv.AssignRole(r.Context(), assingment.Username, assingment.RoleName, assingment.ValidFrom, assingment.ValidUntil)
+	if errors.Is(err, valueobj.InvalidValidityErr) {
+		return webErrs.HttpBadRequestJsonErr(err.Error())
+	}
+	return err
 }
 
 func (h *UserHandler) UnassignRole(w http.ResponseWriter, r *http.Request) error {
@@ -40,3 +173,72 @@ func (h *UserHandler) UnassignRole(w http.ResponseWriter, r *http.Request) error
 	}
 	return h.userSrv.UnassignRole(r.Context(), assingment.Username, assingment.RoleName)
 }
+
+func (h *UserHandler) UnlockUser(w http.ResponseWriter, r *http.Request) error {
+	if err := h.userSrv.UnlockUser(r.Context(), request.PathParam(r, "userId")); err != nil {
+		if errors.Is(err, service.UserNotFoundErr) {
+			return errors.Wrap(webErrs.HttpNotFoundErr, err.Error())
+		}
+		return err
+	}
+
+	response.RespondStatus(w, http.StatusNoContent)
+	return nil
+}
+
+func profileErr(err error) error {
+	switch {
+	case errors.Is(err, user.InvalidProfileErr),
+		errors.Is(err, user.InvalidEmailErr),
+		errors.Is(err, user.EmailExistsErr),
+		errors.Is(err, user.WrongPasswordErr):
+		return webErrs.HttpBadRequestJsonErr(err.Error())
+	case errors.Is(err, service.UserNotFoundErr):
+		return errors.Wrap(webErrs.HttpNotFoundErr, err.Error())
+	}
+	return err
+}
+
+func userFilter(r *http.Request) (user.UserFilterDto, error) {
+	filter := user.UserFilterDto{
+		Username:     request.UrlParam(r, "username"),
+		Email:        request.UrlParam(r, "email"),
+		Organization: request.UrlParam(r, "organization"),
+		Role:         request.UrlParam(r, "role"),
+		Limit:        defaultUsersLimit,
+	}
+
+	if sort := request.UrlParam(r, "sort"); sort != "" {
+		filter.SortBy = strings.TrimPrefix(sort, "-")
+		filter.SortDesc = strings.HasPrefix(sort, "-")
+		if !user.IsUserSortField(filter.SortBy) {
+			return filter, errors.Errorf("users can't be sorted by %s", filter.SortBy)
+		}
+	}
+
+	if disabled := request.UrlParam(r, "disabled"); disabled != "" {
+		value, err := strconv.ParseBool(disabled)
+		if err != nil {
+			return filter, errors.New("disabled must be boolean")
+		}
+		filter.Disabled = &value
+	}
+
+	if limit := request.UrlParam(r, "limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil || value < 1 || value > maxUsersLimit {
+			return filter, errors.Errorf("limit must be between 1 and %d", maxUsersLimit)
+		}
+		filter.Limit = value
+	}
+
+	if offset := request.UrlParam(r, "offset"); offset != "" {
+		value, err := strconv.Atoi(offset)
+		if err != nil || value < 0 {
+			return filter, errors.New("offset must be non-negative integer")
+		}
+		filter.Offset = value
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/pkg/errors"
+
+	"github.com/umalmyha/authsrv/internal/business/user"
+	valueobj "github.com/umalmyha/authsrv/internal/business/value-object"
+	"github.com/umalmyha/authsrv/internal/business/webauthn"
+	"github.com/umalmyha/authsrv/internal/infra/service"
+	webErrs "github.com/umalmyha/authsrv/pkg/web/errors"
+	"github.com/umalmyha/authsrv/pkg/web/middleware"
+	"github.com/umalmyha/authsrv/pkg/web/request"
+	"github.com/umalmyha/authsrv/pkg/web/response"
+)
+
+type WebauthnHandler struct {
+	webauthnSrv *service.WebauthnService
+	rfrCfg      valueobj.RefreshTokenConfig
+}
+
+func NewWebauthnHandler(webauthnSrv *service.WebauthnService, rfrCfg valueobj.RefreshTokenConfig) *WebauthnHandler {
+	return &WebauthnHandler{
+		webauthnSrv: webauthnSrv,
+		rfrCfg:      rfrCfg,
+	}
+}
+
+func (h *WebauthnHandler) BeginRegistration(w http.ResponseWriter, r *http.Request) error {
+	options, err := h.webauthnSrv.BeginRegistration(r.Context(), middleware.AuthUsername(r))
+	if err != nil {
+		return webauthnErr(err)
+	}
+
+	response.SetHeader(w, "Cache-Control", "no-store")
+	return response.RespondJson(w, http.StatusOK, struct {
+		PublicKey webauthn.CreationOptionsDto `json:"publicKey"`
+	}{
+		PublicKey: options,
+	})
+}
+
+func (h *WebauthnHandler) FinishRegistration(w http.ResponseWriter, r *http.Request) error {
+	var resp webauthn.RegistrationResponseDto
+	if err := request.JsonReqBody(r, &resp); err != nil {
+		return err
+	}
+
+	info, err := h.webauthnSrv.FinishRegistration(r.Context(), middleware.AuthUsername(r), resp)
+	if err != nil {
+		return webauthnErr(err)
+	}
+	return response.RespondJson(w, http.StatusCreated, info)
+}
+
+func (h *WebauthnHandler) BeginLogin(w http.ResponseWriter, r *http.Request) error {
+	var begin struct {
+		Username string `json:"username"`
+	}
+	if err := request.JsonReqBody(r, &begin); err != nil {
+		return err
+	}
+
+	options, err := h.webauthnSrv.BeginLogin(r.Context(), begin.Username)
+	if err != nil {
+		return webauthnErr(err)
+	}
+
+	response.SetHeader(w, "Cache-Control", "no-store")
+	return response.RespondJson(w, http.StatusOK, struct {
+		PublicKey webauthn.RequestOptionsDto `json:"publicKey"`
+	}{
+		PublicKey: options,
+	})
+}
+
+// FinishLogin is passkey sign in, it issues the same tokens as sign in with password
+func (h *WebauthnHandler) FinishLogin(w http.ResponseWriter, r *http.Request) error {
+	var signin user.PasskeySigninDto
+	if err := request.JsonReqBody(r, &signin); err != nil {
+		return err
+	}
+	signin.Client = clientInfo(r)
+
+	if request.GetCookieValue(r, h.rfrCfg.CookieName()) != "" {
+		return errors.New("refresh token cookie is set, logout first or refresh session")
+	}
+
+	jwt, rfrToken, err := h.webauthnSrv.FinishLogin(r.Context(), signin)
+	if err != nil {
+		return webauthnErr(err)
+	}
+
+	response.SetCookie(w, refreshCookie(h.rfrCfg, rfrToken))
+	return respondJwt(w, jwt)
+}
+
+func (h *WebauthnHandler) Credentials(w http.ResponseWriter, r *http.Request) error {
+	credentials, err := h.webauthnSrv.Credentials(r.Context(), middleware.AuthUsername(r))
+	if err != nil {
+		return webauthnErr(err)
+	}
+	return response.RespondJson(w, http.StatusOK, credentials)
+}
+
+func (h *WebauthnHandler) RemoveCredential(w http.ResponseWriter, r *http.Request) error {
+	if err := h.webauthnSrv.RemoveCredential(r.Context(), middleware.AuthUsername(r), request.PathParam(r, "id")); err != nil {
+		return webauthnErr(err)
+	}
+
+	response.RespondStatus(w, http.StatusNoContent)
+	return nil
+}
+
+func webauthnErr(err error) error {
+	switch {
+	case errors.Is(err, webauthn.VerificationFailedErr),
+		errors.Is(err, webauthn.SessionExpiredErr),
+		errors.Is(err, webauthn.CredentialClonedErr),
+		errors.Is(err, service.WebauthnSessionInvalidErr):
+		return errors.Wrap(webErrs.HttpUnauthorizedErr, err.Error())
+	case errors.Is(err, user.UserDisabledErr), errors.Is(err, service.EmailNotVerifiedErr):
+		return errors.Wrap(webErrs.HttpForbiddenErr, err.Error())
+	case errors.Is(err, user.CredentialExistsErr):
+		return webErrs.HttpBadRequestJsonErr(err.Error())
+	case errors.Is(err, user.CredentialNotFoundErr), errors.Is(err, service.UserNotFoundErr):
+		return errors.Wrap(webErrs.HttpNotFoundErr, err.Error())
+	}
+	return err
+}
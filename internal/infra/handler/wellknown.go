@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/umalmyha/authsrv/internal/business/client"
+	valueobj "github.com/umalmyha/authsrv/internal/business/value-object"
+	"github.com/umalmyha/authsrv/internal/infra/service"
+	"github.com/umalmyha/authsrv/pkg/web/response"
+)
+
+type openIdConfiguration struct {
+	Issuer                            string   `json:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint"`
+	TokenEndpoint                     string   `json:"token_endpoint"`
+	JwksUri                           string   `json:"jwks_uri"`
+	IntrospectionEndpoint             string   `json:"introspection_endpoint"`
+	RevocationEndpoint                string   `json:"revocation_endpoint"`
+	ResponseTypesSupported            []string `json:"response_types_supported"`
+	GrantTypesSupported               []string `json:"grant_types_supported"`
+	SubjectTypesSupported             []string `json:"subject_types_supported"`
+	IdTokenSigningAlgValuesSupported  []string `json:"id_token_signing_alg_values_supported"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported"`
+	ScopesSupported                   []string `json:"scopes_supported"`
+}
+
+type WellKnownHandler struct {
+	jwtCfg valueobj.JwtConfig
+}
+
+func NewWellKnownHandler(jwtCfg valueobj.JwtConfig) *WellKnownHandler {
+	return &WellKnownHandler{
+		jwtCfg: jwtCfg,
+	}
+}
+
+func (h *WellKnownHandler) Jwks(w http.ResponseWriter, r *http.Request) error {
+	jwks, err := h.jwtCfg.Jwks(time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	response.SetHeader(w, "Cache-Control", "public, max-age=300")
+	return response.RespondJson(w, http.StatusOK, jwks)
+}
+
+func (h *WellKnownHandler) OpenIdConfiguration(w http.ResponseWriter, r *http.Request) error {
+	// issuer must match iss claim of issued tokens exactly (OpenID Connect Discovery 4.3), only endpoints are normalized
+	issuer := h.jwtCfg.Issuer()
+	baseUrl := strings.TrimSuffix(issuer, "/")
+
+	cfg := openIdConfiguration{
+		Issuer:                 issuer,
+		AuthorizationEndpoint:  baseUrl + "/oauth/authorize",
+		TokenEndpoint:          baseUrl + "/oauth/token",
+		JwksUri:                baseUrl + "/.well-known/jwks.json",
+		IntrospectionEndpoint:  baseUrl + "/oauth/introspect",
+		RevocationEndpoint:     baseUrl + "/oauth/revoke",
+		ResponseTypesSupported: []string{service.ResponseTypeCode},
+		GrantTypesSupported: []string{
+			client.GrantTypeAuthorizationCode,
+			client.GrantTypeRefreshToken,
+			client.GrantTypeClientCredentials,
+		},
+		SubjectTypesSupported:             []string{"public"},
+		IdTokenSigningAlgValuesSupported:  []string{h.jwtCfg.Algorithm()},
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post", "none"},
+		CodeChallengeMethodsSupported:     []string{"S256", "plain"},
+		ScopesSupported:                   []string{service.ScopeOpenId},
+	}
+
+	response.SetHeader(w, "Cache-Control", "public, max-age=300")
+	return response.RespondJson(w, http.StatusOK, cfg)
+}
@@ -2,19 +2,24 @@ package infra
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
+	"log"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
-	"github.com/golang-jwt/jwt/v4"
 	"github.com/pkg/errors"
 
 	"github.com/go-redis/redis/v8"
 	"github.com/jmoiron/sqlx"
 	"github.com/joho/godotenv"
 	valueobj "github.com/umalmyha/authsrv/internal/business/value-object"
+	"github.com/umalmyha/authsrv/internal/infra/keystore"
 	"github.com/umalmyha/authsrv/pkg/database/rdb"
+	"github.com/umalmyha/authsrv/pkg/mail"
+	"github.com/umalmyha/authsrv/pkg/ratelimit"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
@@ -61,48 +66,118 @@ func LoadEnv() error {
 }
 
 func JwtConfig() (valueobj.JwtConfig, error) {
-	var cfg valueobj.JwtConfig
+	keyring, err := jwtKeyring()
+	if err != nil {
+		return valueobj.JwtConfig{}, err
+	}
+	return jwtConfig(keyring)
+}
 
-	privateKeyFile := os.Getenv("AUTHSRV_JWT_PRIVATE_KEY_FILE")
-	if privateKeyFile == "" {
-		return cfg, errors.New("private key file is not specified")
+// ReloadingJwtConfig builds jwt config which follows keyring rotated by rotatekeys command, watcher must be run
+// to pick up rotated keys. Watcher is nil if keyring dir is not set, since single key pair is never rotated.
+func ReloadingJwtConfig(logger *log.Logger) (valueobj.JwtConfig, *keystore.Watcher, error) {
+	dir := JwtKeyringDir()
+	if dir == "" {
+		cfg, err := JwtConfig()
+		return cfg, nil, err
 	}
 
-	publicKeyFile := os.Getenv("AUTHSRV_JWT_PUBLIC_KEY_FILE")
-	if privateKeyFile == "" {
-		return cfg, errors.New("public key file is not specified")
+	interval, err := JwtKeyringReloadInterval()
+	if err != nil {
+		return valueobj.JwtConfig{}, nil, err
 	}
 
-	privatePem, err := os.ReadFile(privateKeyFile)
+	watcher, err := keystore.NewWatcher(keystore.New(dir), interval, logger)
 	if err != nil {
-		return cfg, errors.Wrap(err, "failed to read private key file")
+		return valueobj.JwtConfig{}, nil, errors.Wrap(err, "failed to load jwt keyring")
 	}
 
-	publicPem, err := os.ReadFile(publicKeyFile)
+	cfg, err := jwtConfig(watcher)
 	if err != nil {
-		return cfg, errors.Wrap(err, "failed to read public key file")
+		return cfg, nil, err
 	}
+	return cfg, watcher, nil
+}
+
+func jwtConfig(keyring valueobj.KeyringSource) (valueobj.JwtConfig, error) {
+	issuer := os.Getenv("AUTHSRV_JWT_ISSUER")
 
-	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(privatePem)
+	ttl, err := JwtTimeToLive()
 	if err != nil {
-		return cfg, errors.Wrap(err, "failed to generate private key from PEM")
+		return valueobj.JwtConfig{}, err
 	}
 
-	publicKey, err := jwt.ParseRSAPublicKeyFromPEM(publicPem)
+	return valueobj.NewJwtConfig(issuer, keyring, ttl)
+}
+
+// JwtKeyringReloadInterval is how often keyring is reloaded, rotated key must not be activated earlier than that
+func JwtKeyringReloadInterval() (time.Duration, error) {
+	seconds, err := intEnv("AUTHSRV_JWT_KEYRING_RELOAD_SECONDS", 60)
 	if err != nil {
-		return cfg, errors.Wrap(err, "failed to generate public key from PEM")
+		return 0, err
 	}
 
-	algorithm := os.Getenv("AUTHSRV_JWT_ALGORITHM")
-	issuer := os.Getenv("AUTHSRV_JWT_ISSUER")
+	if seconds <= 0 {
+		return 0, errors.New("jwt keyring reload interval must be positive")
+	}
+	return time.Duration(seconds) * time.Second, nil
+}
 
+func JwtAlgorithm() string {
+	return os.Getenv("AUTHSRV_JWT_ALGORITHM")
+}
+
+func JwtTimeToLive() (time.Duration, error) {
 	ttlStr := os.Getenv("AUTHSRV_JWT_TTL_MINUTES")
 	ttl, err := time.ParseDuration(fmt.Sprintf("%sm", ttlStr))
 	if err != nil {
-		return cfg, errors.Wrap(err, "failed to parse jwt ttl in specified format")
+		return 0, errors.Wrap(err, "failed to parse jwt ttl in specified format")
+	}
+	return ttl, nil
+}
+
+func JwtKeyringDir() string {
+	return os.Getenv("AUTHSRV_JWT_KEYRING_DIR")
+}
+
+// jwtKeyring loads keyring maintained by rotatekeys command, single key pair from files is used if keyring dir is not set
+func jwtKeyring() (valueobj.Keyring, error) {
+	if dir := JwtKeyringDir(); dir != "" {
+		kr, err := keystore.New(dir).Load(time.Now().UTC())
+		if err != nil {
+			return kr, errors.Wrap(err, "failed to load jwt keyring")
+		}
+		return kr, nil
+	}
+
+	var kr valueobj.Keyring
+
+	privateKeyFile := os.Getenv("AUTHSRV_JWT_PRIVATE_KEY_FILE")
+	if privateKeyFile == "" {
+		return kr, errors.New("private key file is not specified")
+	}
+
+	publicKeyFile := os.Getenv("AUTHSRV_JWT_PUBLIC_KEY_FILE")
+	if publicKeyFile == "" {
+		return kr, errors.New("public key file is not specified")
+	}
+
+	privateKey, err := keystore.ReadPrivateKey(privateKeyFile)
+	if err != nil {
+		return kr, err
+	}
+
+	publicKey, err := keystore.ReadPublicKey(publicKeyFile)
+	if err != nil {
+		return kr, err
 	}
 
-	return valueobj.NewJwtConfig(algorithm, issuer, privateKey, publicKey, ttl)
+	key, err := valueobj.NewSigningKey(JwtAlgorithm(), privateKey, publicKey)
+	if err != nil {
+		return kr, err
+	}
+
+	return valueobj.NewKeyring(key)
 }
 
 func PasswordConfig() (valueobj.PasswordConfig, error) {
@@ -136,7 +211,66 @@ func PasswordConfig() (valueobj.PasswordConfig, error) {
 		hasUppercase = true
 	}
 
-	return valueobj.NewPasswordConfig(minLength, maxLength, hasDigit, hasUppercase)
+	hasher, err := passwordHasher()
+	if err != nil {
+		return valueobj.PasswordConfig{}, errors.Wrap(err, "failed to build password hasher")
+	}
+
+	return valueobj.NewPasswordConfig(minLength, maxLength, hasDigit, hasUppercase, hasher)
+}
+
+func passwordHasher() (valueobj.PasswordHasher, error) {
+	alg := os.Getenv("AUTHSRV_PASSWORD_HASH_ALGORITHM")
+	if alg == "" {
+		alg = valueobj.HashAlgorithmArgon2id
+	}
+
+	switch alg {
+	case valueobj.HashAlgorithmBcrypt:
+		cost, err := intEnv("AUTHSRV_PASSWORD_BCRYPT_COST", 10)
+		if err != nil {
+			return nil, err
+		}
+		return valueobj.NewBcryptHasher(cost)
+	case valueobj.HashAlgorithmArgon2id:
+		memory, err := intEnv("AUTHSRV_PASSWORD_ARGON2_MEMORY_KB", 64*1024)
+		if err != nil {
+			return nil, err
+		}
+
+		iterations, err := intEnv("AUTHSRV_PASSWORD_ARGON2_ITERATIONS", 3)
+		if err != nil {
+			return nil, err
+		}
+
+		parallelism, err := intEnv("AUTHSRV_PASSWORD_ARGON2_PARALLELISM", 2)
+		if err != nil {
+			return nil, err
+		}
+
+		if memory < 0 || iterations < 0 || parallelism < 0 || parallelism > 255 {
+			return nil, errors.New("argon2id parameters are out of range")
+		}
+		return valueobj.NewArgon2idHasher(uint32(memory), uint32(iterations), uint8(parallelism))
+	case valueobj.HashAlgorithmScrypt:
+		costLog2, err := intEnv("AUTHSRV_PASSWORD_SCRYPT_COST_LOG2", 15)
+		if err != nil {
+			return nil, err
+		}
+
+		blockSize, err := intEnv("AUTHSRV_PASSWORD_SCRYPT_BLOCK_SIZE", 8)
+		if err != nil {
+			return nil, err
+		}
+
+		parallelism, err := intEnv("AUTHSRV_PASSWORD_SCRYPT_PARALLELISM", 1)
+		if err != nil {
+			return nil, err
+		}
+		return valueobj.NewScryptHasher(costLog2, blockSize, parallelism)
+	default:
+		return nil, errors.Errorf("unsupported password hash algorithm %s", alg)
+	}
 }
 
 func RefreshTokenConfig() (valueobj.RefreshTokenConfig, error) {
@@ -157,6 +291,274 @@ func RefreshTokenConfig() (valueobj.RefreshTokenConfig, error) {
 	return valueobj.NewRefreshTokenConfig(ttl, maxCount, cookieName)
 }
 
+func OAuthConfig() (valueobj.OAuthConfig, error) {
+	codeTtl, err := intEnv("AUTHSRV_OAUTH_CODE_TTL_SECONDS", 60)
+	if err != nil {
+		return valueobj.OAuthConfig{}, err
+	}
+
+	sessionTtl, err := intEnv("AUTHSRV_OAUTH_SESSION_TTL_MINUTES", 60)
+	if err != nil {
+		return valueobj.OAuthConfig{}, err
+	}
+
+	return valueobj.NewOAuthConfig(time.Duration(codeTtl)*time.Second, time.Duration(sessionTtl)*time.Minute)
+}
+
+func DenylistConfig() (valueobj.DenylistConfig, error) {
+	enabled, err := boolEnv("AUTHSRV_JWT_DENYLIST_ENABLED", true)
+	if err != nil {
+		return valueobj.DenylistConfig{}, err
+	}
+
+	cacheTtl, err := intEnv("AUTHSRV_JWT_DENYLIST_CACHE_SECONDS", 5)
+	if err != nil {
+		return valueobj.DenylistConfig{}, err
+	}
+
+	cacheSize, err := intEnv("AUTHSRV_JWT_DENYLIST_CACHE_SIZE", 10000)
+	if err != nil {
+		return valueobj.DenylistConfig{}, err
+	}
+
+	return valueobj.NewDenylistConfig(enabled, time.Duration(cacheTtl)*time.Second, cacheSize)
+}
+
+// RoleExpirySweepInterval is how often expired role assignments are deleted
+func RoleExpirySweepInterval() (time.Duration, error) {
+	seconds, err := intEnv("AUTHSRV_ROLE_EXPIRY_SWEEP_SECONDS", 60)
+	if err != nil {
+		return 0, err
+	}
+
+	if seconds <= 0 {
+		return 0, errors.New("role expiry sweep interval must be positive")
+	}
+	return time.Duration(seconds) * time.Second, nil
+}
+
+func MfaConfig() (valueobj.MfaConfig, error) {
+	challengeTtl, err := intEnv("AUTHSRV_MFA_CHALLENGE_TTL_SECONDS", 300)
+	if err != nil {
+		return valueobj.MfaConfig{}, err
+	}
+
+	maxAttempts, err := intEnv("AUTHSRV_MFA_MAX_ATTEMPTS", 5)
+	if err != nil {
+		return valueobj.MfaConfig{}, err
+	}
+
+	recoveryCodeKey, err := base64.StdEncoding.DecodeString(os.Getenv("AUTHSRV_MFA_RECOVERY_CODE_KEY"))
+	if err != nil {
+		return valueobj.MfaConfig{}, errors.Wrap(err, "failed to decode AUTHSRV_MFA_RECOVERY_CODE_KEY, check if base64 is provided")
+	}
+
+	return valueobj.NewMfaConfig(time.Duration(challengeTtl)*time.Second, maxAttempts, recoveryCodeKey)
+}
+
+func LockoutConfig() (valueobj.LockoutConfig, error) {
+	maxFailures, err := intEnv("AUTHSRV_LOCKOUT_MAX_FAILURES", 5)
+	if err != nil {
+		return valueobj.LockoutConfig{}, err
+	}
+
+	ipMaxFailures, err := intEnv("AUTHSRV_LOCKOUT_IP_MAX_FAILURES", 50)
+	if err != nil {
+		return valueobj.LockoutConfig{}, err
+	}
+
+	window, err := intEnv("AUTHSRV_LOCKOUT_WINDOW_SECONDS", 900)
+	if err != nil {
+		return valueobj.LockoutConfig{}, err
+	}
+
+	backoff, err := intEnv("AUTHSRV_LOCKOUT_BACKOFF_SECONDS", 1)
+	if err != nil {
+		return valueobj.LockoutConfig{}, err
+	}
+
+	lockDuration, err := intEnv("AUTHSRV_LOCKOUT_DURATION_SECONDS", 900)
+	if err != nil {
+		return valueobj.LockoutConfig{}, err
+	}
+
+	return valueobj.NewLockoutConfig(
+		maxFailures,
+		ipMaxFailures,
+		time.Duration(window)*time.Second,
+		time.Duration(backoff)*time.Second,
+		time.Duration(lockDuration)*time.Second,
+	)
+}
+
+// WebauthnConfig reads relying party settings, passkeys are disabled if rp id is not set
+func WebauthnConfig() (valueobj.WebauthnConfig, error) {
+	timeout, err := intEnv("AUTHSRV_WEBAUTHN_TIMEOUT_SECONDS", 300)
+	if err != nil {
+		return valueobj.WebauthnConfig{}, err
+	}
+
+	var origins []string
+	for _, origin := range strings.Split(os.Getenv("AUTHSRV_WEBAUTHN_ORIGINS"), ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+
+	return valueobj.NewWebauthnConfig(
+		os.Getenv("AUTHSRV_WEBAUTHN_RP_ID"),
+		os.Getenv("AUTHSRV_WEBAUTHN_RP_NAME"),
+		origins,
+		time.Duration(timeout)*time.Second,
+	)
+}
+
+func PasswordResetConfig() (valueobj.PasswordResetConfig, error) {
+	ttl, err := intEnv("AUTHSRV_PASSWORD_RESET_TTL_SECONDS", 1800)
+	if err != nil {
+		return valueobj.PasswordResetConfig{}, err
+	}
+	return valueobj.NewPasswordResetConfig(time.Duration(ttl)*time.Second, os.Getenv("AUTHSRV_PASSWORD_RESET_URL"))
+}
+
+func EmailVerificationConfig() (valueobj.EmailVerificationConfig, error) {
+	ttl, err := intEnv("AUTHSRV_EMAIL_VERIFICATION_TTL_SECONDS", 86400)
+	if err != nil {
+		return valueobj.EmailVerificationConfig{}, err
+	}
+
+	required, err := boolEnv("AUTHSRV_EMAIL_VERIFICATION_REQUIRED", false)
+	if err != nil {
+		return valueobj.EmailVerificationConfig{}, err
+	}
+
+	return valueobj.NewEmailVerificationConfig(time.Duration(ttl)*time.Second, os.Getenv("AUTHSRV_EMAIL_VERIFICATION_URL"), required)
+}
+
+// NewMailer builds mailer of AUTHSRV_MAIL_TRANSPORT, smtp is used by default if smtp host is set
+// and log otherwise. Log and file transports are intended for development only.
+func NewMailer(logger *log.Logger) (mail.Mailer, error) {
+	from := os.Getenv("AUTHSRV_MAIL_FROM")
+	if from == "" {
+		from = "authsrv <noreply@localhost>"
+	}
+
+	transport := os.Getenv("AUTHSRV_MAIL_TRANSPORT")
+	if transport == "" {
+		transport = "log"
+		if os.Getenv("AUTHSRV_SMTP_HOST") != "" {
+			transport = "smtp"
+		}
+	}
+
+	switch transport {
+	case "smtp":
+		port, err := intEnv("AUTHSRV_SMTP_PORT", 587)
+		if err != nil {
+			return nil, err
+		}
+
+		requireTls, err := boolEnv("AUTHSRV_SMTP_REQUIRE_TLS", true)
+		if err != nil {
+			return nil, err
+		}
+
+		return mail.NewSmtpMailer(mail.SmtpConfig{
+			Host:       os.Getenv("AUTHSRV_SMTP_HOST"),
+			Port:       port,
+			Username:   os.Getenv("AUTHSRV_SMTP_USERNAME"),
+			Password:   os.Getenv("AUTHSRV_SMTP_PASSWORD"),
+			From:       from,
+			RequireTls: requireTls,
+		})
+	case "file":
+		return mail.NewFileMailer(from, os.Getenv("AUTHSRV_MAIL_DIR"))
+	case "log":
+		return mail.NewLogMailer(from, logger), nil
+	default:
+		return nil, errors.Errorf("unknown mail transport %q", transport)
+	}
+}
+
+// NewAsyncMailer wraps mailer of AUTHSRV_MAIL_TRANSPORT with queue of AUTHSRV_MAIL_QUEUE_SIZE messages
+// delivered by AUTHSRV_MAIL_WORKERS workers
+func NewAsyncMailer(logger *log.Logger) (*mail.AsyncMailer, error) {
+	mailer, err := NewMailer(logger)
+	if err != nil {
+		return nil, err
+	}
+
+	workers, err := intEnv("AUTHSRV_MAIL_WORKERS", 4)
+	if err != nil {
+		return nil, err
+	}
+
+	queueSize, err := intEnv("AUTHSRV_MAIL_QUEUE_SIZE", 100)
+	if err != nil {
+		return nil, err
+	}
+
+	if workers < 1 || queueSize < 0 {
+		return nil, errors.New("mail workers must be positive and mail queue size must not be negative")
+	}
+	return mail.NewAsyncMailer(mailer, workers, queueSize, logger), nil
+}
+
+// AuthRateLimiter limits requests to credential accepting endpoints, nil is returned if limiting is disabled.
+// Counters are kept in redis unless AUTHSRV_RATE_LIMIT_STORE is memory, which fits single instance deployments only.
+func AuthRateLimiter(rdb *redis.Client) (ratelimit.Limiter, error) {
+	return rateLimiter(rdb, "auth", "AUTHSRV_RATE_LIMIT", 30)
+}
+
+// ClientRateLimiter limits token requests of OAuth clients, it has own AUTHSRV_CLIENT_RATE_LIMIT_* budget,
+// since services request tokens much more often than users sign in
+func ClientRateLimiter(rdb *redis.Client) (ratelimit.Limiter, error) {
+	return rateLimiter(rdb, "client", "AUTHSRV_CLIENT_RATE_LIMIT", 600)
+}
+
+func rateLimiter(rdb *redis.Client, name string, envPrefix string, defRequests int) (ratelimit.Limiter, error) {
+	requests, err := intEnv(envPrefix+"_REQUESTS", defRequests)
+	if err != nil {
+		return nil, err
+	}
+
+	if requests == 0 {
+		return nil, nil
+	}
+
+	period, err := intEnv(envPrefix+"_PERIOD_SECONDS", 60)
+	if err != nil {
+		return nil, err
+	}
+
+	burst, err := intEnv(envPrefix+"_BURST", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	algorithm := ratelimit.SlidingWindow
+	if value := os.Getenv(envPrefix + "_ALGORITHM"); value != "" {
+		if algorithm, err = ratelimit.ParseAlgorithm(value); err != nil {
+			return nil, err
+		}
+	}
+
+	limit := ratelimit.Limit{
+		Requests: requests,
+		Period:   time.Duration(period) * time.Second,
+		Burst:    burst,
+	}
+
+	switch store := os.Getenv("AUTHSRV_RATE_LIMIT_STORE"); store {
+	case "", "redis":
+		return ratelimit.NewRedisLimiter(rdb, name, algorithm, limit)
+	case "memory":
+		return ratelimit.NewMemoryLimiter(algorithm, limit, 100000)
+	default:
+		return nil, errors.Errorf("unknown rate limit store %q", store)
+	}
+}
+
 func ConnectToDb() (*sqlx.DB, error) {
 	dbConfig := rdb.NewConfig(
 		rdb.DatabasePostgres,
@@ -204,3 +606,29 @@ func RedisOptions() (*redis.Options, error) {
 		WriteTimeout: writeTimeout,
 	}, nil
 }
+
+func intEnv(name string, def int) (int, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return def, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to parse %s, check if number is provided", name)
+	}
+	return n, nil
+}
+
+func boolEnv(name string, def bool) (bool, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return def, nil
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to parse %s, check if boolean is provided", name)
+	}
+	return b, nil
+}
@@ -0,0 +1,242 @@
+package keystore
+
+import (
+	"crypto"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/pkg/errors"
+	valueobj "github.com/umalmyha/authsrv/internal/business/value-object"
+)
+
+const manifestFile = "keyring.json"
+
+type manifestKey struct {
+	KeyId          string     `json:"kid"`
+	Algorithm      string     `json:"alg,omitempty"`
+	PrivateKeyFile string     `json:"privateKeyFile"`
+	PublicKeyFile  string     `json:"publicKeyFile"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	ActivateAt     *time.Time `json:"activateAt,omitempty"`
+	RetiredAt      *time.Time `json:"retiredAt,omitempty"`
+	RemoveAfter    *time.Time `json:"removeAfter,omitempty"`
+}
+
+// signs reports if key is the one used for signing at given moment: it is activated and not retired yet
+func (mk manifestKey) signs(at time.Time) bool {
+	return (mk.ActivateAt == nil || !mk.ActivateAt.After(at)) && (mk.RetiredAt == nil || mk.RetiredAt.After(at))
+}
+
+// manifest active is the latest rotated key, it signs only once its activation time comes
+type manifest struct {
+	Active string        `json:"active"`
+	Keys   []manifestKey `json:"keys"`
+}
+
+type RotationResult struct {
+	ActiveKeyId   string
+	ActivateAt    time.Time
+	RetiredKeyId  string
+	RemovedKeyIds []string
+}
+
+// Store keeps keyring in directory: PEM files per key and manifest which tells active key and retired keys lifetime
+type Store struct {
+	dir string
+}
+
+func New(dir string) *Store {
+	return &Store{
+		dir: dir,
+	}
+}
+
+// Load builds keyring valid at given moment: key which signs at that moment is active, keys waiting
+// for activation are published for verification only and retired keys are kept until their removal time
+func (s *Store) Load(now time.Time) (valueobj.Keyring, error) {
+	var kr valueobj.Keyring
+
+	m, err := s.readManifest()
+	if err != nil {
+		return kr, err
+	}
+
+	if m.Active == "" {
+		return kr, errors.Errorf("keyring in %s has no active key, run rotatekeys command first", s.dir)
+	}
+
+	signingKeyId := ""
+	for _, mk := range m.Keys {
+		if mk.signs(now) {
+			signingKeyId = mk.KeyId
+		}
+	}
+
+	if signingKeyId == "" {
+		return kr, errors.Errorf("keyring in %s has no key which signs at %s", s.dir, now.Format(time.RFC3339))
+	}
+
+	var active valueobj.SigningKey
+	others := make([]valueobj.SigningKey, 0)
+	for _, mk := range m.Keys {
+		if mk.KeyId != signingKeyId && mk.RemoveAfter != nil && mk.RemoveAfter.Before(now) {
+			continue
+		}
+
+		key, err := s.loadKey(mk, mk.KeyId == signingKeyId)
+		if err != nil {
+			return kr, errors.Wrapf(err, "failed to load key %s", mk.KeyId)
+		}
+
+		if key.KeyId() != mk.KeyId {
+			return kr, errors.Errorf("key id %s doesn't match key thumbprint %s", mk.KeyId, key.KeyId())
+		}
+
+		if mk.KeyId == signingKeyId {
+			active = key
+			continue
+		}
+
+		if mk.RemoveAfter != nil {
+			key = key.WithRemoveAfter(*mk.RemoveAfter)
+		}
+		others = append(others, key)
+	}
+
+	return valueobj.NewKeyring(active, others...)
+}
+
+// Rotate generates new key for algorithm, it is published right away, but signs only after activation delay,
+// so every instance reloading keyring within the delay accepts it before the first token is signed with it.
+// Previous key signs until new key is activated and is accepted until delay and retention pass after that.
+// Keys which passed removal time are removed. The very first key is activated immediately.
+func (s *Store) Rotate(alg string, bits int, retention time.Duration, delay time.Duration, now time.Time) (RotationResult, error) {
+	var result RotationResult
+
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return result, errors.Wrap(err, "failed to create keyring directory")
+	}
+
+	m, err := s.readManifest()
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return result, err
+	}
+
+	privateKey, err := GenerateKeyForAlgorithm(alg, bits)
+	if err != nil {
+		return result, err
+	}
+
+	key, err := valueobj.NewSigningKey(alg, privateKey, privateKey.Public())
+	if err != nil {
+		return result, err
+	}
+
+	activateAt := now
+	if m.Active != "" {
+		activateAt = now.Add(delay)
+	}
+
+	mk := manifestKey{
+		KeyId:          key.KeyId(),
+		Algorithm:      alg,
+		PrivateKeyFile: key.KeyId() + ".private.pem",
+		PublicKeyFile:  key.KeyId() + ".public.pem",
+		CreatedAt:      now,
+		ActivateAt:     &activateAt,
+	}
+
+	if err := WriteKeyPair(filepath.Join(s.dir, mk.PrivateKeyFile), filepath.Join(s.dir, mk.PublicKeyFile), privateKey); err != nil {
+		return result, err
+	}
+
+	// instance may sign with previous key until it reloads keyring, which happens within delay after activation
+	removeAfter := activateAt.Add(delay + retention)
+	keys := make([]manifestKey, 0)
+	for _, existing := range m.Keys {
+		if existing.KeyId == m.Active {
+			existing.RetiredAt = &activateAt
+			existing.RemoveAfter = &removeAfter
+			result.RetiredKeyId = existing.KeyId
+		}
+
+		if existing.RemoveAfter != nil && existing.RemoveAfter.Before(now) {
+			result.RemovedKeyIds = append(result.RemovedKeyIds, existing.KeyId)
+			continue
+		}
+		keys = append(keys, existing)
+	}
+
+	m.Active = mk.KeyId
+	m.Keys = append(keys, mk)
+	if err := s.writeManifest(m); err != nil {
+		return result, err
+	}
+
+	// files are removed only after manifest doesn't reference them anymore
+	for _, kid := range result.RemovedKeyIds {
+		os.Remove(filepath.Join(s.dir, kid+".private.pem"))
+		os.Remove(filepath.Join(s.dir, kid+".public.pem"))
+	}
+
+	result.ActiveKeyId = mk.KeyId
+	result.ActivateAt = activateAt
+	return result, nil
+}
+
+func (s *Store) loadKey(mk manifestKey, withPrivate bool) (valueobj.SigningKey, error) {
+	publicKey, err := ReadPublicKey(filepath.Join(s.dir, mk.PublicKeyFile))
+	if err != nil {
+		return valueobj.SigningKey{}, err
+	}
+
+	var privateKey crypto.Signer
+	if withPrivate {
+		if privateKey, err = ReadPrivateKey(filepath.Join(s.dir, mk.PrivateKeyFile)); err != nil {
+			return valueobj.SigningKey{}, err
+		}
+	}
+
+	// keys rotated before algorithm was tracked in manifest are RSA keys
+	alg := mk.Algorithm
+	if alg == "" {
+		alg = jwt.SigningMethodRS256.Alg()
+	}
+
+	return valueobj.NewSigningKey(alg, privateKey, publicKey)
+}
+
+func (s *Store) readManifest() (manifest, error) {
+	var m manifest
+
+	data, err := os.ReadFile(filepath.Join(s.dir, manifestFile))
+	if err != nil {
+		return m, errors.Wrap(err, "failed to read keyring manifest")
+	}
+
+	if err := json.Unmarshal(data, &m); err != nil {
+		return m, errors.Wrap(err, "failed to parse keyring manifest")
+	}
+	return m, nil
+}
+
+// writeManifest replaces manifest atomically, so running readers never see partially written file
+func (s *Store) writeManifest(m manifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to serialize keyring manifest")
+	}
+
+	tmp := filepath.Join(s.dir, manifestFile+".tmp")
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return errors.Wrap(err, "failed to write keyring manifest")
+	}
+
+	if err := os.Rename(tmp, filepath.Join(s.dir, manifestFile)); err != nil {
+		return errors.Wrap(err, "failed to replace keyring manifest")
+	}
+	return nil
+}
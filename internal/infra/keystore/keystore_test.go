@@ -0,0 +1,151 @@
+package keystore
+
+import (
+	"io"
+	"log"
+	"testing"
+	"time"
+)
+
+const (
+	success = "\u2713"
+	failed  = "\u2717"
+)
+
+func TestRotate(t *testing.T) {
+	store := New(t.TempDir())
+	now := time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)
+	retention := 15 * time.Minute
+	delay := 5 * time.Minute
+
+	t.Log("Given the need to test signing key rotation")
+	{
+		first, err := store.Rotate("RS256", 2048, retention, delay, now)
+		if err != nil {
+			t.Fatalf("\t%s\tUnexpected error occurred: %v", failed, err)
+		}
+
+		if !first.ActivateAt.Equal(now) {
+			t.Fatalf("\t%s\tThe very first key must be activated immediately, but activates at %s", failed, first.ActivateAt)
+		}
+
+		second, err := store.Rotate("ES256", 2048, retention, delay, now.Add(time.Minute))
+		if err != nil {
+			t.Fatalf("\t%s\tUnexpected error occurred: %v", failed, err)
+		}
+
+		if second.RetiredKeyId != first.ActiveKeyId {
+			t.Fatalf("\t%s\tPrevious active key %s must be retired, but got %s", failed, first.ActiveKeyId, second.RetiredKeyId)
+		}
+
+		t.Logf("\tTest 1:\tWhen key is rotated and activation delay hasn't passed")
+		{
+			at := now.Add(2 * time.Minute)
+			kr, err := store.Load(at)
+			if err != nil {
+				t.Fatalf("\t%s\tUnexpected error occurred: %v", failed, err)
+			}
+
+			if kr.Active().KeyId() != first.ActiveKeyId {
+				t.Fatalf("\t%s\tPrevious key must keep signing until new key is activated", failed)
+			}
+
+			if _, ok := kr.VerificationKey(second.ActiveKeyId, at); !ok {
+				t.Fatalf("\t%s\tNew key must be published for verification before activation", failed)
+			}
+			t.Logf("\t%s\tPrevious key must sign and new key must be published for verification", success)
+		}
+
+		t.Logf("\tTest 2:\tWhen activation delay passed")
+		{
+			at := now.Add(7 * time.Minute)
+			kr, err := store.Load(at)
+			if err != nil {
+				t.Fatalf("\t%s\tUnexpected error occurred: %v", failed, err)
+			}
+
+			if kr.Active().KeyId() != second.ActiveKeyId {
+				t.Fatalf("\t%s\tNew key must be active", failed)
+			}
+
+			if _, ok := kr.VerificationKey(first.ActiveKeyId, at); !ok {
+				t.Fatalf("\t%s\tRetired key must be accepted for verification", failed)
+			}
+
+			if _, ok := kr.VerificationKey(first.ActiveKeyId, now.Add(time.Hour)); ok {
+				t.Fatalf("\t%s\tRetired key must be rejected once removal time passed, even if keyring isn't reloaded", failed)
+			}
+			t.Logf("\t%s\tNew key must be active and retired key must be accepted until its removal time", success)
+		}
+
+		t.Logf("\tTest 3:\tWhen retention of retired key passed")
+		{
+			kr, err := store.Load(now.Add(time.Hour))
+			if err != nil {
+				t.Fatalf("\t%s\tUnexpected error occurred: %v", failed, err)
+			}
+
+			if _, ok := kr.VerificationKey(first.ActiveKeyId, now.Add(time.Hour)); ok {
+				t.Fatalf("\t%s\tExpired key must not be loaded", failed)
+			}
+
+			third, err := store.Rotate("EdDSA", 2048, retention, delay, now.Add(time.Hour))
+			if err != nil {
+				t.Fatalf("\t%s\tUnexpected error occurred: %v", failed, err)
+			}
+
+			if len(third.RemovedKeyIds) != 1 || third.RemovedKeyIds[0] != first.ActiveKeyId {
+				t.Fatalf("\t%s\tExpired key %s must be removed on rotation, but got %v", failed, first.ActiveKeyId, third.RemovedKeyIds)
+			}
+			t.Logf("\t%s\tExpired key must be ignored on load and removed on rotation", success)
+		}
+	}
+}
+
+func TestWatcherReload(t *testing.T) {
+	store := New(t.TempDir())
+	logger := log.New(io.Discard, "", 0)
+
+	t.Log("Given the need to test keyring reload while server is running")
+	{
+		first, err := store.Rotate("RS256", 2048, time.Hour, time.Minute, time.Now().UTC())
+		if err != nil {
+			t.Fatalf("\t%s\tUnexpected error occurred: %v", failed, err)
+		}
+
+		watcher, err := NewWatcher(store, time.Minute, logger)
+		if err != nil {
+			t.Fatalf("\t%s\tUnexpected error occurred: %v", failed, err)
+		}
+
+		t.Logf("\tTest 1:\tWhen key is rotated after keyring is loaded")
+		{
+			now := time.Now().UTC()
+			second, err := store.Rotate("ES256", 2048, time.Hour, time.Minute, now)
+			if err != nil {
+				t.Fatalf("\t%s\tUnexpected error occurred: %v", failed, err)
+			}
+
+			if err := watcher.Reload(now); err != nil {
+				t.Fatalf("\t%s\tUnexpected error occurred: %v", failed, err)
+			}
+
+			if _, ok := watcher.Keyring().VerificationKey(second.ActiveKeyId, now); !ok {
+				t.Fatalf("\t%s\tRotated key must be published on reload", failed)
+			}
+
+			if watcher.Keyring().Active().KeyId() != first.ActiveKeyId {
+				t.Fatalf("\t%s\tRotated key must not sign before activation", failed)
+			}
+
+			if err := watcher.Reload(second.ActivateAt); err != nil {
+				t.Fatalf("\t%s\tUnexpected error occurred: %v", failed, err)
+			}
+
+			if watcher.Keyring().Active().KeyId() != second.ActiveKeyId {
+				t.Fatalf("\t%s\tRotated key must sign once activated", failed)
+			}
+			t.Logf("\t%s\tRotated key must be published first and signing after activation", success)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package keystore
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/pkg/errors"
+)
+
+const (
+	KeyTypeRsa     = "rsa"
+	KeyTypeEc      = "ec"
+	KeyTypeEd25519 = "ed25519"
+)
+
+var curves = map[string]elliptic.Curve{
+	"P-256": elliptic.P256(),
+	"P-384": elliptic.P384(),
+	"P-521": elliptic.P521(),
+}
+
+// GenerateKey generates private key of the type, bits are used for RSA keys and curve for EC keys only
+func GenerateKey(keyType string, bits int, curve string) (crypto.Signer, error) {
+	var key crypto.Signer
+	var err error
+
+	switch keyType {
+	case KeyTypeRsa:
+		key, err = rsa.GenerateKey(rand.Reader, bits)
+	case KeyTypeEc:
+		c, ok := curves[curve]
+		if !ok {
+			return nil, errors.Errorf("curve %s is not supported, use P-256, P-384 or P-521", curve)
+		}
+		key, err = ecdsa.GenerateKey(c, rand.Reader)
+	case KeyTypeEd25519:
+		_, key, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, errors.Errorf("key type %s is not supported, use rsa, ec or ed25519", keyType)
+	}
+
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate key")
+	}
+	return key, nil
+}
+
+// GenerateKeyForAlgorithm generates private key suitable for JWS algorithm
+func GenerateKeyForAlgorithm(alg string, bits int) (crypto.Signer, error) {
+	switch method := jwt.GetSigningMethod(alg).(type) {
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+		return GenerateKey(KeyTypeRsa, bits, "")
+	case *jwt.SigningMethodECDSA:
+		for name, c := range curves {
+			if c.Params().BitSize == method.CurveBits {
+				return GenerateKey(KeyTypeEc, 0, name)
+			}
+		}
+		return nil, errors.Errorf("no curve found for algorithm %s", alg)
+	case *jwt.SigningMethodEd25519:
+		return GenerateKey(KeyTypeEd25519, 0, "")
+	default:
+		return nil, errors.Errorf("%s is not supported algorithm for JWT signing", alg)
+	}
+}
+
+// WriteKeyPair writes private key in PKCS#8 and public key in PKIX PEM encoding
+func WriteKeyPair(privateFile string, publicFile string, key crypto.Signer) error {
+	privateKeyBytes, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal private key")
+	}
+
+	privateBlock := &pem.Block{
+		Type:  "PRIVATE KEY",
+		Bytes: privateKeyBytes,
+	}
+	if err := os.WriteFile(privateFile, pem.EncodeToMemory(privateBlock), 0600); err != nil {
+		return errors.Wrap(err, "failed to write private key file")
+	}
+
+	publicKeyBytes, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal public key")
+	}
+
+	publicBlock := &pem.Block{
+		Type:  "PUBLIC KEY",
+		Bytes: publicKeyBytes,
+	}
+	if err := os.WriteFile(publicFile, pem.EncodeToMemory(publicBlock), 0644); err != nil {
+		return errors.Wrap(err, "failed to write public key file")
+	}
+
+	return nil
+}
+
+func ReadPrivateKey(file string) (crypto.Signer, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read private key file")
+	}
+	return ParsePrivateKeyPem(data)
+}
+
+func ReadPublicKey(file string) (crypto.PublicKey, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read public key file")
+	}
+	return ParsePublicKeyPem(data)
+}
+
+// ParsePrivateKeyPem parses PKCS#8 keys of any supported type as well as legacy PKCS#1 RSA and SEC 1 EC keys
+func ParsePrivateKeyPem(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("private key must be PEM encoded")
+	}
+
+	var key any
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse private key from PEM")
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, errors.Errorf("private key of type %T can't be used for signing", key)
+	}
+	return signer, nil
+}
+
+// ParsePublicKeyPem parses PKIX public key, PKCS#1 RSA public key or certificate
+func ParsePublicKeyPem(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("public key must be PEM encoded")
+	}
+
+	if key, err := x509.ParsePKIXPublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse public key from PEM")
+	}
+	return cert.PublicKey, nil
+}
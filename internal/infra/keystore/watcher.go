@@ -0,0 +1,75 @@
+package keystore
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	valueobj "github.com/umalmyha/authsrv/internal/business/value-object"
+)
+
+// Watcher reloads keyring periodically, so rotated keys are published and activated without restart.
+// Keyring loaded last is kept if reload fails.
+type Watcher struct {
+	store    *Store
+	interval time.Duration
+	logger   *log.Logger
+	mu       sync.RWMutex
+	keyring  valueobj.Keyring
+}
+
+// NewWatcher loads keyring right away, so watcher always has keyring to serve
+func NewWatcher(store *Store, interval time.Duration, logger *log.Logger) (*Watcher, error) {
+	kr, err := store.Load(time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	return &Watcher{
+		store:    store,
+		interval: interval,
+		logger:   logger,
+		keyring:  kr,
+	}, nil
+}
+
+func (w *Watcher) Keyring() valueobj.Keyring {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.keyring
+}
+
+// Run reloads keyring every interval until context is done
+func (w *Watcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := w.Reload(now.UTC()); err != nil {
+				w.logger.Printf("failed to reload jwt keyring: %v", err)
+			}
+		}
+	}
+}
+
+// Reload replaces keyring with keyring valid at given moment
+func (w *Watcher) Reload(now time.Time) error {
+	kr, err := w.store.Load(now)
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if kr.Active().KeyId() != w.keyring.Active().KeyId() {
+		w.logger.Printf("jwt signing key %s is activated", kr.Active().KeyId())
+	}
+	w.keyring = kr
+	return nil
+}
@@ -3,32 +3,70 @@ package service
 import (
 	"context"
 	"database/sql"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
 
 	"github.com/go-redis/redis/v8"
 	"github.com/jmoiron/sqlx"
+	"github.com/umalmyha/authsrv/internal/business/denylist"
+	"github.com/umalmyha/authsrv/internal/business/lockout"
+	"github.com/umalmyha/authsrv/internal/business/mfa"
 	"github.com/umalmyha/authsrv/internal/business/refresh"
 	"github.com/umalmyha/authsrv/internal/business/user"
 	valueobj "github.com/umalmyha/authsrv/internal/business/value-object"
 )
 
+var InvalidCredentialsErr = errors.New("invalid username or password")
+
+var EmailNotVerifiedErr = errors.New("email must be verified before sign in")
+
+type flusher interface {
+	Flush(context.Context) error
+}
+
 type AuthService struct {
 	db         *sqlx.DB
 	rdb        *redis.Client
 	jwtCfg     valueobj.JwtConfig
 	passCfg    valueobj.PasswordConfig
 	refreshCfg valueobj.RefreshTokenConfig
+	mfaCfg     valueobj.MfaConfig
+	emailCfg   valueobj.EmailVerificationConfig
+	guard      *lockout.Guard
+}
+
+// SigninResultDto holds either issued tokens or MFA challenge if second sign in step is required
+type SigninResultDto struct {
+	AccessToken  valueobj.Jwt
+	RefreshToken *refresh.RefreshToken
+	Challenge    *mfa.Challenge
+}
+
+func (res SigninResultDto) MfaRequired() bool {
+	return res.Challenge != nil
 }
 
-func NewAuthService(db *sqlx.DB, rdb *redis.Client, jwtCfg valueobj.JwtConfig, rfrCfg valueobj.RefreshTokenConfig, passCfg valueobj.PasswordConfig) *AuthService {
+func NewAuthService(
+	db *sqlx.DB,
+	rdb *redis.Client,
+	jwtCfg valueobj.JwtConfig,
+	rfrCfg valueobj.RefreshTokenConfig,
+	passCfg valueobj.PasswordConfig,
+	mfaCfg valueobj.MfaConfig,
+	lockoutCfg valueobj.LockoutConfig,
+	emailCfg valueobj.EmailVerificationConfig,
+) *AuthService {
 	return &AuthService{
 		db:         db,
 		rdb:        rdb,
 		jwtCfg:     jwtCfg,
 		refreshCfg: rfrCfg,
 		passCfg:    passCfg,
+		mfaCfg:     mfaCfg,
+		emailCfg:   emailCfg,
+		guard:      lockout.NewGuard(rdb, lockoutCfg),
 	}
 }
 
@@ -46,7 +84,11 @@ func (srv *AuthService) Signup(ctx context.Context, u user.NewUserDto) error {
 		return true, nil
 	}
 
-	user, err := user.FromNewUserDto(u, srv.passCfg, existUsernameFn)
+	existEmailFn := func(email string) (bool, error) {
+		return user.NewUserDao(srv.db).ExistsByEmail(ctx, email, "")
+	}
+
+	user, err := user.FromNewUserDto(u, srv.passCfg, existUsernameFn, existEmailFn)
 	if err != nil {
 		return errors.Wrap(err, "failed to create user from DTO")
 	}
@@ -58,59 +100,60 @@ func (srv *AuthService) Signup(ctx context.Context, u user.NewUserDto) error {
 	return uow.Flush(ctx)
 }
 
-func (srv *AuthService) Signin(ctx context.Context, signin user.SigninDto) (valueobj.Jwt, *refresh.RefreshToken, error) {
-	var accessToken valueobj.Jwt
-	var refreshToken *refresh.RefreshToken
+// Signin verifies password, tokens are issued right away only if user has no MFA enabled
+func (srv *AuthService) Signin(ctx context.Context, signin user.SigninDto) (SigninResultDto, error) {
+	var result SigninResultDto
 
 	uow := user.NewUnitOfWork(srv.db, srv.rdb)
 	repo := user.NewRepository(uow)
 
-	user, err := repo.FindByUsername(ctx, signin.Username)
-	if err != nil {
-		return accessToken, refreshToken, errors.Wrap(err, "failed to find user in repository")
-	}
-
-	if user == nil {
-		return accessToken, refreshToken, errors.Errorf("user %s doesn't exist", signin.Username)
-	}
-
-	verified, err := user.VerifyPassword(signin.Password)
+	usr, err := authenticateUser(ctx, repo, srv.guard, signin.Username, signin.Password, signin.Client.IpAddress, srv.passCfg, srv.emailCfg)
 	if err != nil {
-		return accessToken, refreshToken, errors.Wrap(err, "failed to verify user password")
+		return result, flushOnLockout(ctx, uow, err)
 	}
 
-	if !verified {
-		return accessToken, refreshToken, errors.New("password is incorrect")
+	if signin.Organization != "" && !usr.IsMemberOf(signin.Organization) {
+		return result, user.NotOrganizationMemberErr
 	}
 
 	issuedAt := time.Now().UTC()
 
-	accessToken, err = user.GenerateJwt(issuedAt, srv.jwtCfg)
-	if err != nil {
-		return accessToken, refreshToken, errors.Wrap(err, "failed to generate access token")
-	}
+	if usr.MfaEnabled() {
+		challenge, err := mfa.NewChallenge(usr.Id(), signin.Fingerprint, signin.Organization, signin.Client, issuedAt, srv.mfaCfg)
+		if err != nil {
+			return result, errors.Wrap(err, "failed to create mfa challenge")
+		}
 
-	refreshToken, err = user.GenerateRefreshToken(signin.Fingerprint, issuedAt, srv.refreshCfg)
-	if err != nil {
-		return accessToken, refreshToken, errors.Wrap(err, "failed to generate refresh token")
+		if err := mfa.NewChallengeDao(srv.rdb).Save(ctx, challenge.Token(), challenge.Dto()); err != nil {
+			return result, errors.Wrap(err, "failed to save mfa challenge")
+		}
+		result.Challenge = challenge
+	} else {
+		if err := completeAuthentication(ctx, srv.guard, usr); err != nil {
+			return result, err
+		}
+
+		result.AccessToken, result.RefreshToken, err = issueSession(usr, signin.Fingerprint, signin.Organization, signin.Client, []string{valueobj.AmrPassword}, issuedAt, srv.jwtCfg, srv.refreshCfg)
+		if err != nil {
+			return result, err
+		}
 	}
 
-	if err := repo.Update(user); err != nil {
-		return accessToken, refreshToken, errors.Wrap(err, "failed to update user in repository")
+	if err := repo.Update(usr); err != nil {
+		return result, errors.Wrap(err, "failed to update user in repository")
 	}
 
 	if err := uow.Flush(ctx); err != nil {
-		return accessToken, refreshToken, errors.Wrap(err, "failed to flush changes")
+		return result, errors.Wrap(err, "failed to flush changes")
 	}
 
-	return accessToken, refreshToken, nil
+	return result, nil
 }
 
 func (srv *AuthService) Logout(ctx context.Context, logout user.LogoutDto) error {
 	uow := user.NewUnitOfWork(srv.db, srv.rdb)
-	defer uow.Dispose()
-
 	repo := user.NewRepository(uow)
+
 	user, err := repo.FindByUsername(ctx, logout.Username)
 	if err != nil {
 		return errors.Wrap(err, "failed to find user in repository")
@@ -120,31 +163,260 @@ func (srv *AuthService) Logout(ctx context.Context, logout user.LogoutDto) error
 		return errors.Errorf("user %s doesn't exist", logout.Username)
 	}
 
-	return user.DiscardRefreshToken(logout)
+	if err := user.DiscardRefreshToken(logout); err != nil {
+		return err
+	}
+
+	if err := repo.Update(user); err != nil {
+		return errors.Wrap(err, "failed to update user in repository")
+	}
+
+	if err := uow.Flush(ctx); err != nil {
+		return err
+	}
+
+	// access token used for logout could be issued for other session, so it is denied explicitly
+	return denylist.NewDenylistDao(srv.rdb).Deny(ctx, logout.AccessTokenId, logout.AccessTokenExpiresAt, time.Now())
 }
 
-func (srv *AuthService) RefreshSession(ctx context.Context, rfr user.RefreshDto) (valueobj.Jwt, error) {
+// RefreshSession rotates refresh token, on token reuse or expiration revoked tokens are persisted before error is returned
+func (srv *AuthService) RefreshSession(ctx context.Context, rfr user.RefreshDto) (valueobj.Jwt, *refresh.RefreshToken, error) {
 	uow := user.NewUnitOfWork(srv.db, srv.rdb)
 	repo := user.NewRepository(uow)
 
 	usr, err := repo.FindByUsername(ctx, rfr.Username)
 	if err != nil {
-		return valueobj.Jwt{}, errors.Wrap(err, "failed to find user in repository")
+		return valueobj.Jwt{}, nil, errors.Wrap(err, "failed to find user in repository")
 	}
 
 	if usr == nil {
-		return valueobj.Jwt{}, errors.Errorf("user %s doesn't exist", rfr.Username)
+		return valueobj.Jwt{}, nil, errors.Errorf("user %s doesn't exist", rfr.Username)
 	}
 
 	now := time.Now().UTC()
-	jwt, err := usr.RefreshSession(rfr, now, srv.jwtCfg)
-	if err != nil && !errors.Is(err, refresh.RefreshTokenExpiredErr) {
-		return jwt, errors.Wrap(err, "failed to refresh session")
+	jwt, rotated, err := rotateRefreshToken(ctx, srv.rdb, usr, rfr, now, srv.jwtCfg)
+	if err != nil && !isRevokingRefreshErr(err) {
+		return jwt, rotated, errors.Wrap(err, "failed to refresh session")
+	}
+
+	if updErr := repo.Update(usr); updErr != nil {
+		return valueobj.Jwt{}, nil, errors.Wrap(updErr, "failed to update user in repository")
 	}
 
 	if flushErr := uow.Flush(ctx); flushErr != nil {
-		return valueobj.Jwt{}, flushErr
+		return valueobj.Jwt{}, nil, flushErr
+	}
+
+	return jwt, rotated, err
+}
+
+// issueSession generates access and refresh token pair, new refresh token is registered on user only.
+// Non-empty organization limits session to roles user has in that organization.
+func issueSession(
+	usr *user.User,
+	fgrprint string,
+	org string,
+	client refresh.ClientInfo,
+	amr []string,
+	issuedAt time.Time,
+	jwtCfg valueobj.JwtConfig,
+	rfrCfg valueobj.RefreshTokenConfig,
+) (valueobj.Jwt, *refresh.RefreshToken, error) {
+	accessToken, err := usr.GenerateOrganizationJwt(issuedAt, org, amr, jwtCfg)
+	if err != nil {
+		return accessToken, nil, errors.Wrap(err, "failed to generate access token")
+	}
+
+	refreshToken, err := usr.GenerateRefreshToken(fgrprint, nil, client, issuedAt, rfrCfg)
+	if err != nil {
+		return accessToken, nil, errors.Wrap(err, "failed to generate refresh token")
 	}
+	refreshToken.BindAccessToken(accessToken)
+
+	return accessToken, refreshToken, nil
+}
+
+// rotateRefreshToken rotates refresh token of user and claims its rotation, so only one of concurrent refreshes
+// with the same token wins and others revoke the session as reused. Reuse of token pruned after rotation is
+// detected by its rotation mark. Claim isn't released if changes fail to flush, so retry is treated as reuse.
+func rotateRefreshToken(ctx context.Context, rdb *redis.Client, usr *user.User, rfr user.RefreshDto, now time.Time, cfg valueobj.JwtConfig) (valueobj.Jwt, *refresh.RefreshToken, error) {
+	dao := refresh.NewRefreshTokenDao(rdb)
+
+	if usr.RefreshToken(rfr.RefreshTokenId) == nil {
+		rotation, err := dao.FindRotation(ctx, rfr.RefreshTokenId)
+		if err != nil {
+			return valueobj.Jwt{}, nil, err
+		}
 
-	return jwt, err
+		if rotation.IsPresent() && rotation.UserId == usr.Id() {
+			usr.RevokeReusedSession(rotation)
+			return valueobj.Jwt{}, nil, refresh.RefreshTokenReusedErr
+		}
+	}
+
+	jwt, rotated, err := usr.RefreshSession(rfr, now, cfg)
+	if err != nil {
+		return jwt, rotated, err
+	}
+
+	rotation := refresh.RotationDto{
+		TokenId:   rotated.ParentId(),
+		UserId:    usr.Id(),
+		FamilyId:  rotated.FamilyId(),
+		ExpiresAt: rotated.ExpiresAt(),
+	}
+
+	claimed, err := dao.ClaimRotation(ctx, rotation, now)
+	if err != nil {
+		return valueobj.Jwt{}, nil, err
+	}
+
+	if !claimed {
+		usr.RevokeReusedSession(rotation)
+		return valueobj.Jwt{}, nil, refresh.RefreshTokenReusedErr
+	}
+	return jwt, rotated, nil
+}
+
+// isRevokingRefreshErr reports refresh errors which revoke tokens, so changes must be flushed anyway
+func isRevokingRefreshErr(err error) bool {
+	return errors.Is(err, refresh.RefreshTokenExpiredErr) || errors.Is(err, refresh.RefreshTokenReusedErr)
+}
+
+// authenticateUser verifies user credentials and upgrades password hash if required,
+// changes are registered in repository unit of work, so caller is responsible for flush.
+// Errors and response time don't reveal whether username exists, unknown usernames are throttled the same way.
+// Failures are counted per login until user is found and per user afterwards, so username and email logins
+// share them. Failures are not forgotten until caller verifies all factors, see completeAuthentication.
+func authenticateUser(
+	ctx context.Context,
+	repo *user.Repository,
+	guard *lockout.Guard,
+	username string,
+	password string,
+	ip string,
+	passCfg valueobj.PasswordConfig,
+	emailCfg valueobj.EmailVerificationConfig,
+) (*user.User, error) {
+	if username == "" || password == "" {
+		return nil, errors.Wrap(InvalidCredentialsErr, "username and password must be provided")
+	}
+
+	if err := guard.Check(ctx, username, ip); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+
+	usr, err := findByLogin(ctx, repo, username)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			passCfg.VerifyDummy(password)
+			return nil, failAuthentication(ctx, repo, guard, nil, username, ip, now)
+		}
+		return nil, errors.Wrap(err, "failed to find user in repository")
+	}
+
+	if err := guard.Check(ctx, usr.Id(), ""); err != nil {
+		return nil, err
+	}
+
+	if lockedFor := usr.LockedFor(now); lockedFor > 0 {
+		return nil, lockout.NewThrottledErr(lockedFor)
+	}
+
+	verified, err := usr.VerifyPassword(password)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to verify user password")
+	}
+
+	if !verified {
+		return nil, failAuthentication(ctx, repo, guard, usr, usr.Id(), ip, now)
+	}
+
+	// status of account and email is revealed only to caller who knows password
+	if usr.Disabled() {
+		return nil, user.UserDisabledErr
+	}
+
+	if emailCfg.Required() && !usr.EmailVerified() {
+		return nil, EmailNotVerifiedErr
+	}
+
+	if _, err := usr.UpgradePasswordHash(password, passCfg); err != nil {
+		return nil, errors.Wrap(err, "failed to upgrade password hash")
+	}
+
+	if err := repo.Update(usr); err != nil {
+		return nil, errors.Wrap(err, "failed to update user in repository")
+	}
+
+	return usr, nil
+}
+
+// findByLogin finds user by username, verified email is accepted in place of username
+func findByLogin(ctx context.Context, repo *user.Repository, login string) (*user.User, error) {
+	usr, err := repo.FindByUsername(ctx, login)
+	if err == nil || !errors.Is(err, sql.ErrNoRows) || !strings.Contains(login, "@") {
+		return usr, err
+	}
+	return repo.FindByVerifiedEmail(ctx, login)
+}
+
+// completeAuthentication forgets failed attempts of user once all factors are verified
+func completeAuthentication(ctx context.Context, guard *lockout.Guard, usr *user.User) error {
+	if err := guard.Reset(ctx, usr.Id()); err != nil {
+		return errors.Wrap(err, "failed to reset sign in failures")
+	}
+	usr.Unlock()
+	return nil
+}
+
+// failSecondFactor counts wrong second factor code as failed sign in, so code can't be brute forced with fresh
+// challenges, caller gets InvalidMfaCodeErr until user is locked
+func failSecondFactor(ctx context.Context, repo *user.Repository, guard *lockout.Guard, usr *user.User, ip string, now time.Time) error {
+	err := failAuthentication(ctx, repo, guard, usr, usr.Id(), ip, now)
+	if errors.Is(err, InvalidCredentialsErr) {
+		return user.InvalidMfaCodeErr
+	}
+	return err
+}
+
+// failAuthentication counts failed attempt, user is locked once max failures are reached
+func failAuthentication(
+	ctx context.Context,
+	repo *user.Repository,
+	guard *lockout.Guard,
+	usr *user.User,
+	account string,
+	ip string,
+	now time.Time,
+) error {
+	lockFor, err := guard.Fail(ctx, account, ip)
+	if err != nil {
+		return errors.Wrap(err, "failed to record sign in failure")
+	}
+
+	if lockFor == 0 {
+		return InvalidCredentialsErr
+	}
+
+	if usr != nil {
+		usr.Lock(now.Add(lockFor))
+		if err := repo.Update(usr); err != nil {
+			return errors.Wrap(err, "failed to update user in repository")
+		}
+	}
+	return lockout.NewThrottledErr(lockFor)
+}
+
+// flushOnLockout persists account lock registered by failed authentication before error is returned
+func flushOnLockout(ctx context.Context, uow flusher, err error) error {
+	var throttled *lockout.ThrottledErr
+	if errors.As(err, &throttled) {
+		if flushErr := uow.Flush(ctx); flushErr != nil {
+			return errors.Wrap(flushErr, "failed to flush changes")
+		}
+	}
+	return err
 }
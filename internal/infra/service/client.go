@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pkg/errors"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/umalmyha/authsrv/internal/business/client"
+	"github.com/umalmyha/authsrv/internal/business/scope"
+	valueobj "github.com/umalmyha/authsrv/internal/business/value-object"
+)
+
+type ClientService struct {
+	db      *sqlx.DB
+	passCfg valueobj.PasswordConfig
+}
+
+func NewClientService(db *sqlx.DB, passCfg valueobj.PasswordConfig) *ClientService {
+	return &ClientService{
+		db:      db,
+		passCfg: passCfg,
+	}
+}
+
+// CreateClient registers new client and returns its id and plain secret (empty for public clients)
+func (srv *ClientService) CreateClient(ctx context.Context, nc client.NewClientDto) (string, string, error) {
+	uow := client.NewUnitOfWork(srv.db)
+	repo := client.NewRepository(uow)
+
+	existFn := func(name string) (bool, error) {
+		if _, err := client.NewClientDao(srv.db).FindByName(ctx, name); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return false, nil
+			}
+			return false, err
+		}
+		return true, nil
+	}
+
+	cl, secret, err := client.FromNewClientDto(nc, srv.passCfg.Hasher(), existFn, srv.findScopeByNameFn(ctx))
+	if err != nil {
+		return "", "", errors.Wrap(err, "failed to build client from DTO")
+	}
+
+	if err := repo.Add(cl); err != nil {
+		return "", "", errors.Wrap(err, "failed to add client to repository")
+	}
+
+	if err := uow.Flush(ctx); err != nil {
+		return "", "", err
+	}
+
+	return cl.Id(), secret, nil
+}
+
+// RotateSecret replaces secret of confidential client, previous secret stops working immediately
+func (srv *ClientService) RotateSecret(ctx context.Context, name string) (string, error) {
+	uow := client.NewUnitOfWork(srv.db)
+	repo := client.NewRepository(uow)
+
+	cl, err := repo.FindByName(ctx, name)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to find client by name")
+	}
+
+	if !cl.IsConfidential() {
+		return "", errors.Errorf("client %s is public and has no secret", name)
+	}
+
+	secret, err := cl.RotateSecret(srv.passCfg.Hasher())
+	if err != nil {
+		return "", errors.Wrap(err, "failed to rotate client secret")
+	}
+
+	if err := repo.Update(cl); err != nil {
+		return "", errors.Wrap(err, "failed to update client in repository")
+	}
+
+	if err := uow.Flush(ctx); err != nil {
+		return "", err
+	}
+
+	return secret, nil
+}
+
+func (srv *ClientService) findScopeByNameFn(ctx context.Context) client.ScopeFinderByNameFn {
+	return func(name string) (scope.ScopeDto, error) {
+		dto, err := scope.NewScopeDao(srv.db).FindByName(ctx, name)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return dto, nil
+			}
+			return dto, err
+		}
+		return dto, nil
+	}
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/umalmyha/authsrv/internal/business/denylist"
+	valueobj "github.com/umalmyha/authsrv/internal/business/value-object"
+	"github.com/umalmyha/authsrv/pkg/cache"
+)
+
+// DenylistService checks access tokens against denylist. Revocation is permanent, so denied tokens are cached
+// until the longest possible token expiration, while allowed tokens are cached for short configured time only
+type DenylistService struct {
+	rdb    *redis.Client
+	cfg    valueobj.DenylistConfig
+	jwtTtl time.Duration
+	cache  *cache.Cache[string, bool]
+}
+
+func NewDenylistService(rdb *redis.Client, cfg valueobj.DenylistConfig, jwtCfg valueobj.JwtConfig) *DenylistService {
+	return &DenylistService{
+		rdb:    rdb,
+		cfg:    cfg,
+		jwtTtl: jwtCfg.TimeToLive(),
+		cache:  cache.New[string, bool](cfg.CacheSize()),
+	}
+}
+
+func (srv *DenylistService) IsDenied(ctx context.Context, tokenId string) (bool, error) {
+	now := time.Now()
+	if denied, ok := srv.cache.Get(tokenId, now); ok {
+		return denied, nil
+	}
+
+	denied, err := denylist.NewDenylistDao(srv.rdb).IsDenied(ctx, tokenId)
+	if err != nil {
+		return false, err
+	}
+
+	if denied {
+		srv.cache.Set(tokenId, true, now.Add(srv.jwtTtl), now)
+	} else if srv.cfg.CacheTimeToLive() > 0 {
+		srv.cache.Set(tokenId, false, now.Add(srv.cfg.CacheTimeToLive()), now)
+	}
+	return denied, nil
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/jmoiron/sqlx"
+	"github.com/umalmyha/authsrv/internal/business/user"
+	valueobj "github.com/umalmyha/authsrv/internal/business/value-object"
+	"github.com/umalmyha/authsrv/internal/business/verification"
+	"github.com/umalmyha/authsrv/pkg/mail"
+)
+
+var VerificationTokenInvalidErr = errors.New("email verification token is invalid or expired")
+
+type EmailService struct {
+	db       *sqlx.DB
+	rdb      *redis.Client
+	emailCfg valueobj.EmailVerificationConfig
+	mailer   mail.Mailer
+}
+
+func NewEmailService(db *sqlx.DB, rdb *redis.Client, emailCfg valueobj.EmailVerificationConfig, mailer mail.Mailer) *EmailService {
+	return &EmailService{
+		db:       db,
+		rdb:      rdb,
+		emailCfg: emailCfg,
+		mailer:   mailer,
+	}
+}
+
+// RequestVerification emails verification link to user. Unknown users and users without unverified email
+// are ignored silently, so response doesn't reveal which users exist.
+func (srv *EmailService) RequestVerification(ctx context.Context, username string) error {
+	if username == "" {
+		return nil
+	}
+
+	uow := user.NewUnitOfWork(srv.db, srv.rdb)
+	defer uow.Dispose()
+
+	usr, err := byUsername(username)(ctx, user.NewRepository(uow))
+	if err != nil {
+		if errors.Is(err, UserNotFoundErr) {
+			return nil
+		}
+		return err
+	}
+
+	if usr.Email() == "" || usr.EmailVerified() {
+		return nil
+	}
+	return srv.sendVerification(ctx, usr)
+}
+
+// VerifyEmail marks email as verified, token is spent even if email was changed since it was sent
+func (srv *EmailService) VerifyEmail(ctx context.Context, token string) error {
+	if token == "" {
+		return VerificationTokenInvalidErr
+	}
+
+	tokenDto, err := verification.NewTokenDao(srv.rdb).Consume(ctx, token)
+	if err != nil {
+		return errors.Wrap(err, "failed to read email verification token")
+	}
+
+	now := time.Now().UTC()
+	if !tokenDto.IsPresent() || tokenDto.ToToken().IsExpired(now) {
+		return VerificationTokenInvalidErr
+	}
+
+	uow := user.NewUnitOfWork(srv.db, srv.rdb)
+	repo := user.NewRepository(uow)
+
+	usr, err := byUserId(tokenDto.UserId)(ctx, repo)
+	if err != nil {
+		if errors.Is(err, UserNotFoundErr) {
+			return VerificationTokenInvalidErr
+		}
+		return err
+	}
+
+	existEmailFn := func(email string) (bool, error) {
+		return user.NewUserDao(srv.db).ExistsByEmail(ctx, email, usr.Id())
+	}
+
+	if err := usr.VerifyEmail(tokenDto.Email, now, existEmailFn); err != nil {
+		return err
+	}
+
+	if err := repo.Update(usr); err != nil {
+		return errors.Wrap(err, "failed to update user in repository")
+	}
+
+	return uow.Flush(ctx)
+}
+
+// ChangeEmail replaces email of user and sends verification link to new address, empty email removes it
+func (srv *EmailService) ChangeEmail(ctx context.Context, username string, email string, currentPassword string) error {
+	uow := user.NewUnitOfWork(srv.db, srv.rdb)
+	repo := user.NewRepository(uow)
+
+	usr, err := byUsername(username)(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	existEmailFn := func(email string) (bool, error) {
+		return user.NewUserDao(srv.db).ExistsByEmail(ctx, email, usr.Id())
+	}
+
+	if err := usr.ChangeOwnEmail(email, currentPassword, existEmailFn); err != nil {
+		return err
+	}
+
+	if err := repo.Update(usr); err != nil {
+		return errors.Wrap(err, "failed to update user in repository")
+	}
+
+	if err := uow.Flush(ctx); err != nil {
+		return errors.Wrap(err, "failed to flush changes")
+	}
+
+	if usr.Email() == "" || usr.EmailVerified() {
+		return nil
+	}
+	return srv.sendVerification(ctx, usr)
+}
+
+func (srv *EmailService) sendVerification(ctx context.Context, usr *user.User) error {
+	token, err := verification.NewToken(usr.Id(), usr.Email(), time.Now().UTC(), srv.emailCfg)
+	if err != nil {
+		return errors.Wrap(err, "failed to issue email verification token")
+	}
+
+	if err := verification.NewTokenDao(srv.rdb).Save(ctx, token.Token(), token.Dto()); err != nil {
+		return errors.Wrap(err, "failed to save email verification token")
+	}
+
+	instruction := fmt.Sprintf("Use this token to verify your email: %s", token.Token())
+	if link := srv.emailCfg.VerifyLink(token.Token()); link != "" {
+		instruction = fmt.Sprintf("Follow this link to verify your email: %s", link)
+	}
+
+	msg := mail.Message{
+		To:      []string{usr.Email()},
+		Subject: "Verify your email",
+		Body: fmt.Sprintf(
+			"Hello %s,\n\nPlease confirm that this email belongs to you.\n%s\n\nIt expires in %s. If you didn't sign up, just ignore this email.\n",
+			usr.Username(),
+			instruction,
+			humanDuration(srv.emailCfg.TokenTimeToLive()),
+		),
+	}
+
+	if err := srv.mailer.Send(ctx, msg); err != nil {
+		return errors.Wrap(err, "failed to send email verification")
+	}
+	return nil
+}
+
+// humanDuration formats token lifetime for emails, e.g. "30 minutes" or "24 hours"
+func humanDuration(d time.Duration) string {
+	if d >= time.Hour && d%time.Hour == 0 {
+		return fmt.Sprintf("%d hours", int(d.Hours()))
+	}
+	return fmt.Sprintf("%d minutes", int(d.Minutes()))
+}
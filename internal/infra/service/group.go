@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pkg/errors"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/umalmyha/authsrv/internal/business/group"
+	"github.com/umalmyha/authsrv/internal/business/role"
+	"github.com/umalmyha/authsrv/internal/business/user"
+	"github.com/umalmyha/authsrv/pkg/helpers"
+)
+
+var GroupNotFoundErr = errors.New("group not found")
+
+type GroupService struct {
+	db *sqlx.DB
+}
+
+func NewGroupService(db *sqlx.DB) *GroupService {
+	return &GroupService{
+		db: db,
+	}
+}
+
+func (srv *GroupService) CreateGroup(ctx context.Context, ng group.NewGroupDto) (group.GroupDetailsDto, error) {
+	uow := group.NewUnitOfWork(srv.db)
+	repo := group.NewRepository(uow)
+
+	existFn := func(name string) (bool, error) {
+		if _, err := group.NewGroupDao(srv.db).FindByName(ctx, name); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return false, nil
+			}
+			return false, err
+		}
+		return true, nil
+	}
+
+	g, err := group.FromNewGroupDto(ng, existFn)
+	if err != nil {
+		return group.GroupDetailsDto{}, errors.Wrap(err, "failed to build group from DTO")
+	}
+
+	if err := repo.Add(g); err != nil {
+		return group.GroupDetailsDto{}, errors.Wrap(err, "failed to add group to repository")
+	}
+
+	if err := uow.Flush(ctx); err != nil {
+		return group.GroupDetailsDto{}, errors.Wrap(err, "failed to flush changes")
+	}
+	return g.ToDto().Details(nil, nil), nil
+}
+
+func (srv *GroupService) Groups(ctx context.Context) ([]group.GroupDetailsDto, error) {
+	groups, err := group.NewGroupDao(srv.db).FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return srv.details(ctx, groups)
+}
+
+func (srv *GroupService) Group(ctx context.Context, name string) (group.GroupDetailsDto, error) {
+	uow := group.NewUnitOfWork(srv.db)
+	defer uow.Dispose()
+
+	g, err := groupByName(ctx, group.NewRepository(uow), name)
+	if err != nil {
+		return group.GroupDetailsDto{}, err
+	}
+	return srv.groupDetails(ctx, g)
+}
+
+func (srv *GroupService) UpdateGroup(ctx context.Context, name string, update group.UpdateGroupDto) (group.GroupDetailsDto, error) {
+	uow := group.NewUnitOfWork(srv.db)
+	repo := group.NewRepository(uow)
+
+	g, err := groupByName(ctx, repo, name)
+	if err != nil {
+		return group.GroupDetailsDto{}, err
+	}
+
+	if update.Description != nil {
+		g.ChangeDescription(*update.Description)
+	}
+
+	if err := repo.Update(g); err != nil {
+		return group.GroupDetailsDto{}, errors.Wrap(err, "failed to update group in repository")
+	}
+
+	if err := uow.Flush(ctx); err != nil {
+		return group.GroupDetailsDto{}, errors.Wrap(err, "failed to flush changes")
+	}
+	return srv.groupDetails(ctx, g)
+}
+
+// DeleteGroup deletes group together with its memberships and role assignments, members lose roles granted by group
+func (srv *GroupService) DeleteGroup(ctx context.Context, name string) error {
+	uow := group.NewUnitOfWork(srv.db)
+	repo := group.NewRepository(uow)
+
+	g, err := groupByName(ctx, repo, name)
+	if err != nil {
+		return err
+	}
+
+	if err := repo.Remove(g); err != nil {
+		return errors.Wrap(err, "failed to remove group from repository")
+	}
+
+	return uow.Flush(ctx)
+}
+
+func (srv *GroupService) AddMember(ctx context.Context, name string, username string) error {
+	return srv.amend(ctx, name, func(g *group.Group) error {
+		return g.AddMember(username, srv.findUserByNameFn(ctx))
+	})
+}
+
+func (srv *GroupService) RemoveMember(ctx context.Context, name string, username string) error {
+	return srv.amend(ctx, name, func(g *group.Group) error {
+		return g.RemoveMember(username, srv.findUserByNameFn(ctx))
+	})
+}
+
+// AssignRole assigns role to group, so role becomes effective for all members of group
+func (srv *GroupService) AssignRole(ctx context.Context, name string, roleName string) error {
+	return srv.amend(ctx, name, func(g *group.Group) error {
+		return g.AssignRole(roleName, srv.findRoleByNameFn(ctx))
+	})
+}
+
+func (srv *GroupService) UnassignRole(ctx context.Context, name string, roleName string) error {
+	return srv.amend(ctx, name, func(g *group.Group) error {
+		return g.UnassignRole(roleName, srv.findRoleByNameFn(ctx))
+	})
+}
+
+func (srv *GroupService) groupDetails(ctx context.Context, g *group.Group) (group.GroupDetailsDto, error) {
+	details, err := srv.details(ctx, []group.GroupDto{g.ToDto()})
+	if err != nil {
+		return group.GroupDetailsDto{}, err
+	}
+	return details[0], nil
+}
+
+func (srv *GroupService) details(ctx context.Context, groups []group.GroupDto) ([]group.GroupDetailsDto, error) {
+	groupIds := helpers.Map(groups, func(g group.GroupDto, _ int, _ []group.GroupDto) string {
+		return g.Id
+	})
+
+	members, err := group.NewMemberDao(srv.db).FindUsernamesWhereGroupIdsIn(ctx, groupIds)
+	if err != nil {
+		return nil, err
+	}
+
+	roles, err := group.NewRoleAssignmentDao(srv.db).FindRoleNamesWhereGroupIdsIn(ctx, groupIds)
+	if err != nil {
+		return nil, err
+	}
+
+	groupMembers := helpers.GroupBy(members, func(member group.GroupMemberNameDto, _ int, _ []group.GroupMemberNameDto) (string, string) {
+		return member.GroupId, member.Username
+	})
+
+	groupRoles := helpers.GroupBy(roles, func(assigned group.GroupRoleNameDto, _ int, _ []group.GroupRoleNameDto) (string, string) {
+		return assigned.GroupId, assigned.RoleName
+	})
+
+	return helpers.Map(groups, func(g group.GroupDto, _ int, _ []group.GroupDto) group.GroupDetailsDto {
+		return g.Details(groupMembers[g.Id], groupRoles[g.Id])
+	}), nil
+}
+
+func (srv *GroupService) amend(ctx context.Context, name string, amendFn func(*group.Group) error) error {
+	uow := group.NewUnitOfWork(srv.db)
+	repo := group.NewRepository(uow)
+
+	g, err := groupByName(ctx, repo, name)
+	if err != nil {
+		return err
+	}
+
+	if err := amendFn(g); err != nil {
+		return err
+	}
+
+	if err := repo.Update(g); err != nil {
+		return errors.Wrap(err, "failed to update group in repository")
+	}
+
+	return uow.Flush(ctx)
+}
+
+func (srv *GroupService) findUserByNameFn(ctx context.Context) group.UserFinderByNameFn {
+	return func(username string) (user.UserDto, error) {
+		var dto user.UserDto
+		dto, err := user.NewUserDao(srv.db).FindByUsername(ctx, username)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return dto, nil
+			}
+			return dto, err
+		}
+		return dto, nil
+	}
+}
+
+func (srv *GroupService) findRoleByNameFn(ctx context.Context) group.RoleFinderByNameFn {
+	return func(name string) (role.RoleDto, error) {
+		var dto role.RoleDto
+		dto, err := role.NewRoleDao(srv.db).FindByName(ctx, name)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return dto, nil
+			}
+			return dto, err
+		}
+		return dto, nil
+	}
+}
+
+func groupByName(ctx context.Context, repo *group.Repository, name string) (*group.Group, error) {
+	g, err := repo.FindByName(ctx, name)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.Wrapf(GroupNotFoundErr, "group %s doesn't exist", name)
+		}
+		return nil, errors.Wrap(err, "failed to find group in repository")
+	}
+	return g, nil
+}
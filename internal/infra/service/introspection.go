@@ -0,0 +1,230 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/umalmyha/authsrv/internal/business/client"
+	"github.com/umalmyha/authsrv/internal/business/denylist"
+	"github.com/umalmyha/authsrv/internal/business/refresh"
+	"github.com/umalmyha/authsrv/internal/business/user"
+	valueobj "github.com/umalmyha/authsrv/internal/business/value-object"
+)
+
+const (
+	TokenTypeHintAccessToken  = "access_token"
+	TokenTypeHintRefreshToken = "refresh_token"
+)
+
+type IntrospectDto struct {
+	ClientId      string
+	ClientSecret  string
+	Token         string
+	TokenTypeHint string
+}
+
+// IntrospectionDto is RFC 7662 introspection response, only active flag is set for inactive tokens
+type IntrospectionDto struct {
+	Active    bool     `json:"active"`
+	Scope     string   `json:"scope,omitempty"`
+	ClientId  string   `json:"client_id,omitempty"`
+	Username  string   `json:"username,omitempty"`
+	TokenType string   `json:"token_type,omitempty"`
+	Exp       int64    `json:"exp,omitempty"`
+	Iat       int64    `json:"iat,omitempty"`
+	Sub       string   `json:"sub,omitempty"`
+	Iss       string   `json:"iss,omitempty"`
+	Jti       string   `json:"jti,omitempty"`
+	Org       string   `json:"org,omitempty"`
+	Roles     []string `json:"roles,omitempty"`
+	Superuser bool     `json:"superuser,omitempty"`
+}
+
+type RevokeDto struct {
+	ClientId      string
+	ClientSecret  string
+	Token         string
+	TokenTypeHint string
+}
+
+// Introspect describes access token or refresh token id, introspection is available for confidential clients only
+func (srv *OAuthService) Introspect(ctx context.Context, intr IntrospectDto) (IntrospectionDto, error) {
+	inactive := IntrospectionDto{Active: false}
+
+	cl, err := srv.authenticateClient(ctx, intr.ClientId, intr.ClientSecret)
+	if err != nil {
+		return inactive, err
+	}
+
+	if !cl.IsConfidential() {
+		return inactive, newOAuthErr(OAuthErrUnauthorizedClient, "introspection is allowed for confidential clients only")
+	}
+
+	if intr.Token == "" {
+		return inactive, newOAuthErr(OAuthErrInvalidRequest, "token is required")
+	}
+
+	introspectFns := []func(context.Context, string) (IntrospectionDto, error){srv.introspectAccessToken, srv.introspectRefreshToken}
+	if intr.TokenTypeHint == TokenTypeHintRefreshToken {
+		introspectFns[0], introspectFns[1] = introspectFns[1], introspectFns[0]
+	}
+
+	for _, introspectFn := range introspectFns {
+		resp, err := introspectFn(ctx, intr.Token)
+		if err != nil {
+			return inactive, err
+		}
+
+		if resp.Active {
+			return resp, nil
+		}
+	}
+
+	return inactive, nil
+}
+
+// Revoke revokes access token or refresh token together with its session (RFC 7009), unknown tokens
+// and tokens issued to other clients are ignored, so their existence is not disclosed
+func (srv *OAuthService) Revoke(ctx context.Context, rvk RevokeDto) error {
+	cl, err := srv.authenticateClient(ctx, rvk.ClientId, rvk.ClientSecret)
+	if err != nil {
+		return err
+	}
+
+	if rvk.Token == "" {
+		return newOAuthErr(OAuthErrInvalidRequest, "token is required")
+	}
+
+	if rvk.TokenTypeHint != TokenTypeHintAccessToken {
+		revoked, err := srv.revokeRefreshToken(ctx, cl, rvk.Token)
+		if err != nil || revoked {
+			return err
+		}
+	}
+
+	claims, err := valueobj.ParseJwt(rvk.Token, srv.jwtCfg)
+	if err != nil || claims.ClientId() != cl.Id() {
+		return nil
+	}
+
+	return denylist.NewDenylistDao(srv.rdb).Deny(ctx, claims.TokenId(), claims.Expiry(), time.Now())
+}
+
+func (srv *OAuthService) introspectAccessToken(ctx context.Context, token string) (IntrospectionDto, error) {
+	claims, err := valueobj.ParseJwt(token, srv.jwtCfg)
+	if err != nil {
+		return IntrospectionDto{Active: false}, nil
+	}
+
+	denied, err := denylist.NewDenylistDao(srv.rdb).IsDenied(ctx, claims.TokenId())
+	if err != nil {
+		return IntrospectionDto{}, err
+	}
+
+	if denied {
+		return IntrospectionDto{Active: false}, nil
+	}
+
+	resp := IntrospectionDto{
+		Active:    true,
+		Scope:     strings.Join(claims.Scopes(), " "),
+		ClientId:  claims.ClientId(),
+		Username:  claims.Username(),
+		TokenType: "Bearer",
+		Exp:       claims.Expiry().Unix(),
+		Sub:       claims.Subject,
+		Iss:       claims.Issuer,
+		Jti:       claims.TokenId(),
+		Org:       claims.Organization(),
+		Roles:     claims.Roles(),
+		Superuser: claims.IsSuperuser(),
+	}
+
+	if claims.IssuedAt != nil {
+		resp.Iat = claims.IssuedAt.Unix()
+	}
+	return resp, nil
+}
+
+func (srv *OAuthService) introspectRefreshToken(ctx context.Context, tokenId string) (IntrospectionDto, error) {
+	usr, token, err := srv.findRefreshToken(ctx, tokenId)
+	if err != nil || token == nil {
+		return IntrospectionDto{Active: false}, err
+	}
+
+	if token.IsRotated() || token.VerifyNotExpired(time.Now().UTC()) != nil {
+		return IntrospectionDto{Active: false}, nil
+	}
+
+	return IntrospectionDto{
+		Active:    true,
+		Scope:     strings.Join(usr.SessionScopes(token), " "),
+		ClientId:  token.ClientId(),
+		Username:  usr.Username(),
+		TokenType: TokenTypeHintRefreshToken,
+		Exp:       token.ExpiresAt().Unix(),
+		Iat:       token.IssuedAt().Unix(),
+		Sub:       usr.Username(),
+		Iss:       srv.jwtCfg.Issuer(),
+	}, nil
+}
+
+func (srv *OAuthService) revokeRefreshToken(ctx context.Context, cl *client.Client, tokenId string) (bool, error) {
+	uow := user.NewUnitOfWork(srv.db, srv.rdb)
+	repo := user.NewRepository(uow)
+
+	usr, token, err := srv.findRefreshTokenInRepository(ctx, repo, tokenId)
+	if err != nil || token == nil {
+		return false, err
+	}
+
+	if token.ClientId() != cl.Id() {
+		return false, nil
+	}
+
+	if err := usr.RevokeSession(token.FamilyId()); err != nil {
+		return false, err
+	}
+
+	if err := repo.Update(usr); err != nil {
+		return false, errors.Wrap(err, "failed to update user in repository")
+	}
+
+	if err := uow.Flush(ctx); err != nil {
+		return false, errors.Wrap(err, "failed to flush changes")
+	}
+	return true, nil
+}
+
+func (srv *OAuthService) findRefreshToken(ctx context.Context, tokenId string) (*user.User, *refresh.RefreshToken, error) {
+	uow := user.NewUnitOfWork(srv.db, srv.rdb)
+	defer uow.Dispose()
+
+	return srv.findRefreshTokenInRepository(ctx, user.NewRepository(uow), tokenId)
+}
+
+// findRefreshTokenInRepository returns nil token if it is unknown or its owner doesn't exist anymore
+func (srv *OAuthService) findRefreshTokenInRepository(ctx context.Context, repo *user.Repository, tokenId string) (*user.User, *refresh.RefreshToken, error) {
+	userId, err := refresh.NewRefreshTokenDao(srv.rdb).FindUserIdByTokenId(ctx, tokenId)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to find refresh token owner")
+	}
+
+	if userId == "" {
+		return nil, nil, nil
+	}
+
+	usr, err := repo.FindById(ctx, userId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, nil
+		}
+		return nil, nil, errors.Wrap(err, "failed to find user in repository")
+	}
+
+	return usr, usr.RefreshToken(tokenId), nil
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/umalmyha/authsrv/internal/business/lockout"
+	"github.com/umalmyha/authsrv/internal/business/mfa"
+	"github.com/umalmyha/authsrv/internal/business/refresh"
+	"github.com/umalmyha/authsrv/internal/business/user"
+	valueobj "github.com/umalmyha/authsrv/internal/business/value-object"
+)
+
+var MfaChallengeInvalidErr = errors.New("mfa challenge is invalid or was already used")
+
+// amr of sessions completed with second factor, recovery code is one-time password as well
+var mfaAmr = []string{valueobj.AmrPassword, valueobj.AmrOtp, valueobj.AmrMfa}
+
+type TotpEnrollmentDto struct {
+	Secret     string `json:"secret"`
+	OtpAuthUri string `json:"otpauthUri"`
+}
+
+// EnrollTotp starts TOTP enrollment, MFA is not enabled until first code is confirmed
+func (srv *AuthService) EnrollTotp(ctx context.Context, username string) (TotpEnrollmentDto, error) {
+	var enrollment TotpEnrollmentDto
+
+	err := srv.amendUser(ctx, username, func(usr *user.User) error {
+		secret, err := usr.EnrollTotp()
+		if err != nil {
+			return err
+		}
+
+		enrollment.Secret = secret.String()
+		enrollment.OtpAuthUri = secret.Uri(srv.jwtCfg.Issuer(), usr.Username())
+		return nil
+	})
+
+	return enrollment, err
+}
+
+// ConfirmTotp enables MFA and returns recovery codes, they are shown to user only once
+func (srv *AuthService) ConfirmTotp(ctx context.Context, username string, code string) ([]string, error) {
+	var recoveryCodes []string
+
+	err := srv.amendUser(ctx, username, func(usr *user.User) error {
+		codes, err := usr.ConfirmTotp(code, time.Now().UTC(), srv.mfaCfg)
+		if err != nil {
+			return err
+		}
+
+		recoveryCodes = codes
+		return nil
+	})
+
+	return recoveryCodes, err
+}
+
+func (srv *AuthService) DisableMfa(ctx context.Context, username string, code string) error {
+	err := srv.amendUser(ctx, username, func(usr *user.User) error {
+		return usr.DisableMfa(code, time.Now().UTC(), srv.mfaCfg)
+	})
+
+	if errors.Is(err, user.RecoveryCodeUsedErr) {
+		return user.InvalidMfaCodeErr
+	}
+	return err
+}
+
+// VerifyMfa completes sign in started with password, challenge is dropped once attempts are exhausted.
+// Wrong codes count as failed sign in of user, so they lead to lockout the same way as wrong passwords.
+func (srv *AuthService) VerifyMfa(ctx context.Context, verify user.MfaVerifyDto) (valueobj.Jwt, *refresh.RefreshToken, error) {
+	if verify.ChallengeToken == "" || verify.Code == "" {
+		return valueobj.Jwt{}, nil, errors.New("challenge token and code must be provided")
+	}
+
+	challengeDao := mfa.NewChallengeDao(srv.rdb)
+
+	challengeDto, err := challengeDao.Consume(ctx, verify.ChallengeToken)
+	if err != nil {
+		return valueobj.Jwt{}, nil, errors.Wrap(err, "failed to read mfa challenge")
+	}
+
+	if !challengeDto.IsPresent() {
+		return valueobj.Jwt{}, nil, MfaChallengeInvalidErr
+	}
+
+	now := time.Now().UTC()
+	challenge := challengeDto.ToChallenge()
+	if err := challenge.VerifyNotExpired(now); err != nil {
+		return valueobj.Jwt{}, nil, err
+	}
+
+	uow := user.NewUnitOfWork(srv.db, srv.rdb)
+	repo := user.NewRepository(uow)
+
+	usr, err := repo.FindById(ctx, challenge.UserId())
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return valueobj.Jwt{}, nil, MfaChallengeInvalidErr
+		}
+		return valueobj.Jwt{}, nil, errors.Wrap(err, "failed to find user in repository")
+	}
+
+	if lockedFor := usr.LockedFor(now); lockedFor > 0 {
+		return valueobj.Jwt{}, nil, lockout.NewThrottledErr(lockedFor)
+	}
+
+	ip := challenge.Client().IpAddress
+	if err := srv.guard.Check(ctx, usr.Id(), ip); err != nil {
+		return valueobj.Jwt{}, nil, err
+	}
+
+	if err := usr.VerifyMfa(verify.Code, now, srv.mfaCfg); err != nil {
+		if !errors.Is(err, user.InvalidMfaCodeErr) {
+			return valueobj.Jwt{}, nil, err
+		}
+
+		if challenge.Fail() {
+			if saveErr := challengeDao.Save(ctx, verify.ChallengeToken, challenge.Dto()); saveErr != nil {
+				return valueobj.Jwt{}, nil, errors.Wrap(saveErr, "failed to save mfa challenge")
+			}
+		}
+		return valueobj.Jwt{}, nil, flushOnLockout(ctx, uow, failSecondFactor(ctx, repo, srv.guard, usr, ip, now))
+	}
+
+	if err := completeAuthentication(ctx, srv.guard, usr); err != nil {
+		return valueobj.Jwt{}, nil, err
+	}
+
+	accessToken, refreshToken, err := issueSession(usr, challenge.Fingerprint(), challenge.Organization(), challenge.Client(), mfaAmr, now, srv.jwtCfg, srv.refreshCfg)
+	if err != nil {
+		return valueobj.Jwt{}, nil, err
+	}
+
+	if err := repo.Update(usr); err != nil {
+		return valueobj.Jwt{}, nil, errors.Wrap(err, "failed to update user in repository")
+	}
+
+	// recovery code consumed by concurrent request fails flush, so session isn't issued twice for it
+	if err := uow.Flush(ctx); err != nil {
+		if errors.Is(err, user.RecoveryCodeUsedErr) {
+			return valueobj.Jwt{}, nil, user.InvalidMfaCodeErr
+		}
+		return valueobj.Jwt{}, nil, errors.Wrap(err, "failed to flush changes")
+	}
+
+	return accessToken, refreshToken, nil
+}
+
+func (srv *AuthService) amendUser(ctx context.Context, username string, amendFn func(*user.User) error) error {
+	uow := user.NewUnitOfWork(srv.db, srv.rdb)
+	repo := user.NewRepository(uow)
+
+	usr, err := byUsername(username)(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	if err := amendFn(usr); err != nil {
+		return err
+	}
+
+	if err := repo.Update(usr); err != nil {
+		return errors.Wrap(err, "failed to update user in repository")
+	}
+
+	return uow.Flush(ctx)
+}
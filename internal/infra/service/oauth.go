@@ -0,0 +1,729 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/umalmyha/authsrv/internal/business/authcode"
+	"github.com/umalmyha/authsrv/internal/business/client"
+	"github.com/umalmyha/authsrv/internal/business/lockout"
+	"github.com/umalmyha/authsrv/internal/business/refresh"
+	"github.com/umalmyha/authsrv/internal/business/signin"
+	"github.com/umalmyha/authsrv/internal/business/user"
+	valueobj "github.com/umalmyha/authsrv/internal/business/value-object"
+)
+
+const (
+	OAuthErrInvalidRequest          = "invalid_request"
+	OAuthErrInvalidClient           = "invalid_client"
+	OAuthErrInvalidGrant            = "invalid_grant"
+	OAuthErrUnauthorizedClient      = "unauthorized_client"
+	OAuthErrUnsupportedGrantType    = "unsupported_grant_type"
+	OAuthErrUnsupportedResponseType = "unsupported_response_type"
+	OAuthErrInvalidScope            = "invalid_scope"
+	OAuthErrAccessDenied            = "access_denied"
+)
+
+// SignInRequiredErr is returned when authorization is requested by user without valid sign in session,
+// user must sign in on authorization page first
+var SignInRequiredErr = errors.New("sign in is required")
+
+const (
+	ResponseTypeCode = "code"
+	ScopeOpenId      = "openid"
+)
+
+// OAuthErr is error defined by RFC 6749, it is either rendered as JSON or delivered to client via redirect
+type OAuthErr struct {
+	code        string
+	description string
+	redirectUri string
+	state       string
+}
+
+func newOAuthErr(code string, description string) *OAuthErr {
+	return &OAuthErr{
+		code:        code,
+		description: description,
+	}
+}
+
+func (e *OAuthErr) Error() string {
+	return fmt.Sprintf("%s: %s", e.code, e.description)
+}
+
+func (e *OAuthErr) Code() string {
+	return e.code
+}
+
+func (e *OAuthErr) Description() string {
+	return e.description
+}
+
+func (e *OAuthErr) Status() int {
+	if e.code == OAuthErrInvalidClient {
+		return http.StatusUnauthorized
+	}
+	return http.StatusBadRequest
+}
+
+// RedirectUri returns client uri with error details, empty string means that redirect uri is not trusted
+// and error must be shown to resource owner instead
+func (e *OAuthErr) RedirectUri() string {
+	if e.redirectUri == "" {
+		return ""
+	}
+
+	params := url.Values{}
+	params.Set("error", e.code)
+	params.Set("error_description", e.description)
+	if e.state != "" {
+		params.Set("state", e.state)
+	}
+
+	return appendQuery(e.redirectUri, params)
+}
+
+func (e *OAuthErr) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Error       string `json:"error"`
+		Description string `json:"error_description,omitempty"`
+	}{
+		Error:       e.code,
+		Description: e.description,
+	})
+}
+
+func (e *OAuthErr) withRedirect(redirectUri string, state string) *OAuthErr {
+	return &OAuthErr{
+		code:        e.code,
+		description: e.description,
+		redirectUri: redirectUri,
+		state:       state,
+	}
+}
+
+type AuthorizeDto struct {
+	ResponseType        string
+	ClientId            string
+	RedirectUri         string
+	Scope               string
+	State               string
+	Nonce               string
+	CodeChallenge       string
+	CodeChallengeMethod string
+	// SessionId identifies sign in session of user on authorization page
+	SessionId string
+	// Approved is consent of user, code is issued only if user approved authorization
+	Approved bool
+}
+
+// SignInDto is sign in on authorization page
+type SignInDto struct {
+	Username string
+	Password string
+	// MfaCode is second factor, it is mandatory for users with enabled MFA
+	MfaCode  string
+	ClientIp string
+}
+
+// ConsentDto is shown to user, so user knows which client gets which access
+type ConsentDto struct {
+	ClientName string
+	Username   string
+	Scopes     []string
+}
+
+type TokenDto struct {
+	GrantType    string
+	ClientId     string
+	ClientSecret string
+	Scope        string
+	Code         string
+	RedirectUri  string
+	CodeVerifier string
+	RefreshToken string
+	Client       refresh.ClientInfo
+}
+
+type TokenResponseDto struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+	IdToken      string `json:"id_token,omitempty"`
+}
+
+type OAuthService struct {
+	db         *sqlx.DB
+	rdb        *redis.Client
+	jwtCfg     valueobj.JwtConfig
+	passCfg    valueobj.PasswordConfig
+	mfaCfg     valueobj.MfaConfig
+	refreshCfg valueobj.RefreshTokenConfig
+	oauthCfg   valueobj.OAuthConfig
+	emailCfg   valueobj.EmailVerificationConfig
+	guard      *lockout.Guard
+}
+
+func NewOAuthService(
+	db *sqlx.DB,
+	rdb *redis.Client,
+	jwtCfg valueobj.JwtConfig,
+	rfrCfg valueobj.RefreshTokenConfig,
+	passCfg valueobj.PasswordConfig,
+	mfaCfg valueobj.MfaConfig,
+	oauthCfg valueobj.OAuthConfig,
+	lockoutCfg valueobj.LockoutConfig,
+	emailCfg valueobj.EmailVerificationConfig,
+) *OAuthService {
+	return &OAuthService{
+		db:         db,
+		rdb:        rdb,
+		jwtCfg:     jwtCfg,
+		refreshCfg: rfrCfg,
+		passCfg:    passCfg,
+		mfaCfg:     mfaCfg,
+		oauthCfg:   oauthCfg,
+		emailCfg:   emailCfg,
+		guard:      lockout.NewGuard(rdb, lockoutCfg),
+	}
+}
+
+// authorizationRequest is validated authorization request, redirect uri is trusted
+type authorizationRequest struct {
+	client      *client.Client
+	redirectUri string
+	scopes      []string
+}
+
+// SignIn authenticates user on authorization page, second factor is mandatory for users with enabled MFA.
+// Returned session lets user authorize clients without entering credentials again until it expires.
+func (srv *OAuthService) SignIn(ctx context.Context, in SignInDto) (*signin.Session, error) {
+	uow := user.NewUnitOfWork(srv.db, srv.rdb)
+	repo := user.NewRepository(uow)
+
+	usr, err := authenticateUser(ctx, repo, srv.guard, in.Username, in.Password, in.ClientIp, srv.passCfg, srv.emailCfg)
+	if err != nil {
+		return nil, flushOnLockout(ctx, uow, err)
+	}
+
+	authTime := time.Now().UTC()
+
+	amr := []string{valueobj.AmrPassword}
+	if usr.MfaEnabled() {
+		if err := usr.VerifyMfa(in.MfaCode, authTime, srv.mfaCfg); err != nil {
+			if !errors.Is(err, user.InvalidMfaCodeErr) {
+				return nil, errors.Wrap(InvalidCredentialsErr, err.Error())
+			}
+
+			err = failSecondFactor(ctx, repo, srv.guard, usr, in.ClientIp, authTime)
+			if errors.Is(err, user.InvalidMfaCodeErr) {
+				err = errors.Wrap(InvalidCredentialsErr, err.Error())
+			}
+			return nil, flushOnLockout(ctx, uow, err)
+		}
+		amr = mfaAmr
+	}
+
+	if err := completeAuthentication(ctx, srv.guard, usr); err != nil {
+		return nil, err
+	}
+
+	// user was registered in unit of work before, so unlock, consumed recovery code or used TOTP step must be registered too
+	if err := repo.Update(usr); err != nil {
+		return nil, errors.Wrap(err, "failed to update user in repository")
+	}
+
+	session, err := signin.NewSession(usr.Id(), amr, authTime, srv.oauthCfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create sign in session")
+	}
+
+	// session is saved only once recovery code is consumed, so concurrent request can't use the same recovery code
+	if err := uow.Flush(ctx); err != nil {
+		if errors.Is(err, user.RecoveryCodeUsedErr) {
+			return nil, errors.Wrap(InvalidCredentialsErr, user.InvalidMfaCodeErr.Error())
+		}
+		return nil, errors.Wrap(err, "failed to flush changes")
+	}
+
+	if err := signin.NewSessionDao(srv.rdb).Save(ctx, session.Id(), session.Dto()); err != nil {
+		return nil, errors.Wrap(err, "failed to save sign in session")
+	}
+	return session, nil
+}
+
+// SignOut ends sign in session, unknown session is ignored
+func (srv *OAuthService) SignOut(ctx context.Context, sessionId string) error {
+	if sessionId == "" {
+		return nil
+	}
+	return signin.NewSessionDao(srv.rdb).Delete(ctx, sessionId)
+}
+
+// RequestConsent validates authorization request of signed in user and returns details user must consent to,
+// SignInRequiredErr is returned if user isn't signed in
+func (srv *OAuthService) RequestConsent(ctx context.Context, authz AuthorizeDto) (ConsentDto, error) {
+	req, err := srv.authorizationRequest(ctx, authz)
+	if err != nil {
+		return ConsentDto{}, err
+	}
+
+	_, usr, err := srv.signedInUser(ctx, authz.SessionId)
+	if err != nil {
+		return ConsentDto{}, err
+	}
+
+	return ConsentDto{
+		ClientName: req.client.Name(),
+		Username:   usr.Username(),
+		Scopes:     req.scopes,
+	}, nil
+}
+
+// Authorize issues authorization code to signed in user once user approved authorization,
+// returns uri client must be redirected to
+func (srv *OAuthService) Authorize(ctx context.Context, authz AuthorizeDto) (string, error) {
+	req, err := srv.authorizationRequest(ctx, authz)
+	if err != nil {
+		return "", err
+	}
+
+	session, usr, err := srv.signedInUser(ctx, authz.SessionId)
+	if err != nil {
+		return "", err
+	}
+
+	if !authz.Approved {
+		return "", newOAuthErr(OAuthErrAccessDenied, "user denied authorization").withRedirect(req.redirectUri, authz.State)
+	}
+
+	code, err := authcode.NewAuthorizationCode(authcode.NewAuthorizationCodeDto{
+		ClientId:            req.client.Id(),
+		RedirectUri:         req.redirectUri,
+		RedirectUriProvided: authz.RedirectUri != "",
+		UserId:              usr.Id(),
+		Username:            usr.Username(),
+		Scopes:              req.scopes,
+		Nonce:               authz.Nonce,
+		CodeChallenge:       authz.CodeChallenge,
+		CodeChallengeMethod: authz.CodeChallengeMethod,
+		AuthTime:            session.AuthTime(),
+		Amr:                 session.Amr(),
+	}, time.Now().UTC(), srv.oauthCfg)
+	if err != nil {
+		return "", newOAuthErr(OAuthErrInvalidRequest, errors.Cause(err).Error()).withRedirect(req.redirectUri, authz.State)
+	}
+
+	if err := authcode.NewAuthorizationCodeDao(srv.rdb).Save(ctx, code.Code(), code.Dto()); err != nil {
+		return "", errors.Wrap(err, "failed to save authorization code")
+	}
+
+	params := url.Values{}
+	params.Set("code", code.Code())
+	if authz.State != "" {
+		params.Set("state", authz.State)
+	}
+
+	return appendQuery(req.redirectUri, params), nil
+}
+
+// authorizationRequest validates client, redirect uri and requested scopes, errors after redirect uri is resolved
+// are delivered to client
+func (srv *OAuthService) authorizationRequest(ctx context.Context, authz AuthorizeDto) (authorizationRequest, error) {
+	var req authorizationRequest
+
+	cl, err := srv.findClient(ctx, authz.ClientId)
+	if err != nil {
+		return req, err
+	}
+
+	redirectUri, err := cl.ResolveRedirectUri(authz.RedirectUri)
+	if err != nil {
+		return req, newOAuthErr(OAuthErrInvalidRequest, err.Error())
+	}
+
+	// redirect uri is trusted from here, so all errors are delivered to client
+	if authz.ResponseType != ResponseTypeCode {
+		return req, newOAuthErr(OAuthErrUnsupportedResponseType, "only code response type is supported").withRedirect(redirectUri, authz.State)
+	}
+
+	if !cl.AllowsGrantType(client.GrantTypeAuthorizationCode) {
+		return req, newOAuthErr(OAuthErrUnauthorizedClient, "client is not allowed to use authorization code grant").withRedirect(redirectUri, authz.State)
+	}
+
+	if authz.CodeChallenge == "" {
+		return req, newOAuthErr(OAuthErrInvalidRequest, "code challenge is required").withRedirect(redirectUri, authz.State)
+	}
+
+	// client never gets scopes it isn't allowed to request, openid is granted to every client using code grant
+	apiScopes, openId := splitOpenIdScope(strings.Fields(authz.Scope))
+	scopes, err := cl.GrantScopes(apiScopes)
+	if err != nil {
+		return req, newOAuthErr(OAuthErrInvalidScope, err.Error()).withRedirect(redirectUri, authz.State)
+	}
+
+	if openId {
+		scopes = append(scopes, ScopeOpenId)
+	}
+
+	return authorizationRequest{
+		client:      cl,
+		redirectUri: redirectUri,
+		scopes:      scopes,
+	}, nil
+}
+
+// signedInUser finds user of sign in session, session of user who was deleted or disabled since is ended
+func (srv *OAuthService) signedInUser(ctx context.Context, sessionId string) (*signin.Session, *user.User, error) {
+	if sessionId == "" {
+		return nil, nil, SignInRequiredErr
+	}
+
+	dao := signin.NewSessionDao(srv.rdb)
+
+	dto, err := dao.Find(ctx, sessionId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !dto.IsPresent() {
+		return nil, nil, SignInRequiredErr
+	}
+
+	session := dto.ToSession()
+	if session.IsExpired(time.Now().UTC()) {
+		return nil, nil, SignInRequiredErr
+	}
+
+	usr, err := user.NewRepository(user.NewUnitOfWork(srv.db, srv.rdb)).FindById(ctx, session.UserId())
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, errors.Wrap(err, "failed to find user in repository")
+	}
+
+	if err != nil || usr.Disabled() {
+		if err := dao.Delete(ctx, sessionId); err != nil {
+			return nil, nil, err
+		}
+		return nil, nil, SignInRequiredErr
+	}
+
+	return session, usr, nil
+}
+
+func (srv *OAuthService) Token(ctx context.Context, tkn TokenDto) (TokenResponseDto, error) {
+	if tkn.GrantType == "" {
+		return TokenResponseDto{}, newOAuthErr(OAuthErrInvalidRequest, "grant type is required")
+	}
+
+	cl, err := srv.authenticateClient(ctx, tkn.ClientId, tkn.ClientSecret)
+	if err != nil {
+		return TokenResponseDto{}, err
+	}
+
+	switch tkn.GrantType {
+	case client.GrantTypeAuthorizationCode, client.GrantTypeRefreshToken, client.GrantTypeClientCredentials:
+		if !cl.AllowsGrantType(tkn.GrantType) {
+			return TokenResponseDto{}, newOAuthErr(OAuthErrUnauthorizedClient, fmt.Sprintf("client is not allowed to use %s grant", tkn.GrantType))
+		}
+	default:
+		return TokenResponseDto{}, newOAuthErr(OAuthErrUnsupportedGrantType, fmt.Sprintf("grant type %s is not supported", tkn.GrantType))
+	}
+
+	switch tkn.GrantType {
+	case client.GrantTypeAuthorizationCode:
+		return srv.exchangeAuthorizationCode(ctx, cl, tkn)
+	case client.GrantTypeRefreshToken:
+		return srv.exchangeRefreshToken(ctx, cl, tkn)
+	default:
+		return srv.clientCredentials(cl, tkn)
+	}
+}
+
+func (srv *OAuthService) exchangeAuthorizationCode(ctx context.Context, cl *client.Client, tkn TokenDto) (TokenResponseDto, error) {
+	var resp TokenResponseDto
+
+	if tkn.Code == "" {
+		return resp, newOAuthErr(OAuthErrInvalidRequest, "code is required")
+	}
+
+	codeDto, err := authcode.NewAuthorizationCodeDao(srv.rdb).Consume(ctx, tkn.Code)
+	if err != nil {
+		return resp, errors.Wrap(err, "failed to read authorization code")
+	}
+
+	if !codeDto.IsPresent() {
+		return resp, newOAuthErr(OAuthErrInvalidGrant, "authorization code is invalid or was already used")
+	}
+
+	now := time.Now().UTC()
+	code := codeDto.ToAuthorizationCode()
+	if err := code.Redeem(cl.Id(), tkn.RedirectUri, tkn.CodeVerifier, now); err != nil {
+		return resp, newOAuthErr(OAuthErrInvalidGrant, err.Error())
+	}
+
+	uow := user.NewUnitOfWork(srv.db, srv.rdb)
+	repo := user.NewRepository(uow)
+
+	usr, err := repo.FindById(ctx, code.UserId())
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return resp, newOAuthErr(OAuthErrInvalidGrant, "resource owner doesn't exist anymore")
+		}
+		return resp, errors.Wrap(err, "failed to find user in repository")
+	}
+
+	// client scopes could be narrowed after code was issued
+	scopes, openId := splitOpenIdScope(code.Scopes())
+	scopes, err = cl.GrantScopes(scopes)
+	if err != nil {
+		return resp, newOAuthErr(OAuthErrInvalidScope, err.Error())
+	}
+
+	resp, err = srv.issueTokens(usr, cl.Id(), scopes, code.Amr(), tkn.Client, now)
+	if err != nil {
+		if errors.Is(err, user.UserDisabledErr) {
+			return resp, newOAuthErr(OAuthErrInvalidGrant, "resource owner is disabled")
+		}
+		return resp, err
+	}
+
+	if openId {
+		idToken, err := valueobj.NewIdToken(usr.Username(), cl.Id(), code.Nonce(), now, code.AuthTime(), code.Amr(), srv.jwtCfg)
+		if err != nil {
+			return resp, errors.Wrap(err, "failed to generate ID token")
+		}
+		resp.IdToken = idToken.String()
+		resp.Scope = strings.TrimSpace(ScopeOpenId + " " + resp.Scope)
+	}
+
+	if err := repo.Update(usr); err != nil {
+		return resp, errors.Wrap(err, "failed to update user in repository")
+	}
+
+	if err := uow.Flush(ctx); err != nil {
+		return resp, errors.Wrap(err, "failed to flush changes")
+	}
+
+	return resp, nil
+}
+
+func (srv *OAuthService) exchangeRefreshToken(ctx context.Context, cl *client.Client, tkn TokenDto) (TokenResponseDto, error) {
+	var resp TokenResponseDto
+
+	if tkn.RefreshToken == "" {
+		return resp, newOAuthErr(OAuthErrInvalidRequest, "refresh token is required")
+	}
+
+	tokenDao := refresh.NewRefreshTokenDao(srv.rdb)
+
+	userId, err := tokenDao.FindUserIdByTokenId(ctx, tkn.RefreshToken)
+	if err != nil {
+		return resp, errors.Wrap(err, "failed to find refresh token owner")
+	}
+
+	// token pruned after rotation has no index anymore, but its reuse must still revoke the session
+	if userId == "" {
+		rotation, err := tokenDao.FindRotation(ctx, tkn.RefreshToken)
+		if err != nil {
+			return resp, errors.Wrap(err, "failed to find refresh token rotation")
+		}
+		userId = rotation.UserId
+	}
+
+	if userId == "" {
+		return resp, newOAuthErr(OAuthErrInvalidGrant, "refresh token is invalid or expired")
+	}
+
+	uow := user.NewUnitOfWork(srv.db, srv.rdb)
+	repo := user.NewRepository(uow)
+
+	usr, err := repo.FindById(ctx, userId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return resp, newOAuthErr(OAuthErrInvalidGrant, "resource owner doesn't exist anymore")
+		}
+		return resp, errors.Wrap(err, "failed to find user in repository")
+	}
+
+	rfr := user.RefreshDto{
+		Username:       usr.Username(),
+		RefreshTokenId: tkn.RefreshToken,
+		Client:         tkn.Client,
+	}
+
+	token := usr.RefreshToken(tkn.RefreshToken)
+	if token != nil {
+		if token.ClientId() != cl.Id() {
+			return resp, newOAuthErr(OAuthErrInvalidGrant, "refresh token is invalid or was issued to another client")
+		}
+		rfr.Fingerprint = token.Fingerprint()
+	}
+
+	now := time.Now().UTC()
+	accessToken, rotated, err := rotateRefreshToken(ctx, srv.rdb, usr, rfr, now, srv.jwtCfg)
+	if errors.Is(err, user.UserDisabledErr) {
+		return resp, newOAuthErr(OAuthErrInvalidGrant, "resource owner is disabled")
+	}
+
+	if err != nil && !isRevokingRefreshErr(err) {
+		if token == nil {
+			return resp, newOAuthErr(OAuthErrInvalidGrant, "refresh token is invalid or was issued to another client")
+		}
+		return resp, errors.Wrap(err, "failed to refresh session")
+	}
+
+	if err == nil {
+		resp = TokenResponseDto{
+			AccessToken:  accessToken.String(),
+			TokenType:    accessToken.TokenType(),
+			ExpiresIn:    accessToken.ExpiresAt() - now.Unix(),
+			RefreshToken: rotated.Id(),
+			Scope:        strings.Join(usr.SessionScopes(rotated), " "),
+		}
+	} else {
+		err = newOAuthErr(OAuthErrInvalidGrant, "refresh token is invalid, expired or was already used")
+	}
+
+	if updErr := repo.Update(usr); updErr != nil {
+		return resp, errors.Wrap(updErr, "failed to update user in repository")
+	}
+
+	if flushErr := uow.Flush(ctx); flushErr != nil {
+		return resp, errors.Wrap(flushErr, "failed to flush changes")
+	}
+
+	return resp, err
+}
+
+// clientCredentials issues access token to the client itself, client id is used as subject
+func (srv *OAuthService) clientCredentials(cl *client.Client, tkn TokenDto) (TokenResponseDto, error) {
+	var resp TokenResponseDto
+
+	var requested []string
+	if tkn.Scope != "" {
+		requested = strings.Fields(tkn.Scope)
+	}
+
+	scopes, err := cl.GrantScopes(requested)
+	if err != nil {
+		return resp, newOAuthErr(OAuthErrInvalidScope, err.Error())
+	}
+
+	issuedAt := time.Now().UTC()
+	accessToken, err := valueobj.NewJwt(cl.Id(), issuedAt, "", cl.Id(), nil, scopes, false, nil, srv.jwtCfg)
+	if err != nil {
+		return resp, errors.Wrap(err, "failed to generate access token")
+	}
+
+	return TokenResponseDto{
+		AccessToken: accessToken.String(),
+		TokenType:   accessToken.TokenType(),
+		ExpiresIn:   accessToken.ExpiresAt() - issuedAt.Unix(),
+		Scope:       strings.Join(scopes, " "),
+	}, nil
+}
+
+// issueTokens generates access and refresh token pair, new refresh token is registered on user only
+func (srv *OAuthService) issueTokens(usr *user.User, clientId string, scopes []string, amr []string, client refresh.ClientInfo, issuedAt time.Time) (TokenResponseDto, error) {
+	var resp TokenResponseDto
+
+	accessToken, err := usr.GenerateScopedJwt(issuedAt, clientId, scopes, amr, srv.jwtCfg)
+	if err != nil {
+		return resp, errors.Wrap(err, "failed to generate access token")
+	}
+
+	refreshToken, err := usr.GenerateRefreshToken(refresh.NewDelegatedFingerprint(clientId), scopes, client, issuedAt, srv.refreshCfg)
+	if err != nil {
+		return resp, errors.Wrap(err, "failed to generate refresh token")
+	}
+	refreshToken.BindAccessToken(accessToken)
+
+	return TokenResponseDto{
+		AccessToken:  accessToken.String(),
+		TokenType:    accessToken.TokenType(),
+		ExpiresIn:    accessToken.ExpiresAt() - issuedAt.Unix(),
+		RefreshToken: refreshToken.Id(),
+		Scope:        strings.Join(usr.GrantedScopes(scopes), " "),
+	}, nil
+}
+
+func (srv *OAuthService) findClient(ctx context.Context, clientId string) (*client.Client, error) {
+	if clientId == "" {
+		return nil, newOAuthErr(OAuthErrInvalidRequest, "client id is required")
+	}
+
+	if _, err := uuid.Parse(clientId); err != nil {
+		return nil, newOAuthErr(OAuthErrInvalidClient, "client is unknown")
+	}
+
+	cl, err := client.NewRepository(client.NewUnitOfWork(srv.db)).FindById(ctx, clientId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, newOAuthErr(OAuthErrInvalidClient, "client is unknown")
+		}
+		return nil, errors.Wrap(err, "failed to find client")
+	}
+
+	return cl, nil
+}
+
+// authenticateClient finds client and verifies its secret, public clients are identified by id only
+func (srv *OAuthService) authenticateClient(ctx context.Context, clientId string, secret string) (*client.Client, error) {
+	cl, err := srv.findClient(ctx, clientId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !cl.IsConfidential() {
+		return cl, nil
+	}
+
+	verified, err := cl.VerifySecret(secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to verify client secret")
+	}
+
+	if !verified {
+		return nil, newOAuthErr(OAuthErrInvalidClient, "client authentication failed")
+	}
+
+	return cl, nil
+}
+
+// splitOpenIdScope separates openid scope from API scopes, empty scopes are returned if no API scopes are requested
+func splitOpenIdScope(requested []string) ([]string, bool) {
+	scopes := make([]string, 0)
+	openId := false
+
+	for _, scope := range requested {
+		if scope == ScopeOpenId {
+			openId = true
+			continue
+		}
+		scopes = append(scopes, scope)
+	}
+
+	return scopes, openId
+}
+
+func appendQuery(uri string, params url.Values) string {
+	separator := "?"
+	if strings.Contains(uri, "?") {
+		separator = "&"
+	}
+	return uri + separator + params.Encode()
+}
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pkg/errors"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/umalmyha/authsrv/internal/business/organization"
+	"github.com/umalmyha/authsrv/internal/business/role"
+	"github.com/umalmyha/authsrv/internal/business/user"
+)
+
+var OrganizationNotFoundErr = errors.New("organization not found")
+
+type OrganizationService struct {
+	db *sqlx.DB
+}
+
+func NewOrganizationService(db *sqlx.DB) *OrganizationService {
+	return &OrganizationService{
+		db: db,
+	}
+}
+
+func (srv *OrganizationService) CreateOrganization(ctx context.Context, no organization.NewOrganizationDto) (organization.OrganizationDto, error) {
+	uow := organization.NewUnitOfWork(srv.db)
+	repo := organization.NewRepository(uow)
+
+	existFn := func(name string) (bool, error) {
+		if _, err := organization.NewOrganizationDao(srv.db).FindByName(ctx, name); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return false, nil
+			}
+			return false, err
+		}
+		return true, nil
+	}
+
+	org, err := organization.FromNewOrganizationDto(no, existFn)
+	if err != nil {
+		return organization.OrganizationDto{}, errors.Wrap(err, "failed to build organization from DTO")
+	}
+
+	if err := repo.Add(org); err != nil {
+		return organization.OrganizationDto{}, errors.Wrap(err, "failed to add organization to repository")
+	}
+
+	if err := uow.Flush(ctx); err != nil {
+		return organization.OrganizationDto{}, errors.Wrap(err, "failed to flush changes")
+	}
+	return org.ToDto(), nil
+}
+
+func (srv *OrganizationService) Organizations(ctx context.Context) ([]organization.OrganizationDto, error) {
+	return organization.NewOrganizationDao(srv.db).FindAll(ctx)
+}
+
+func (srv *OrganizationService) Organization(ctx context.Context, name string) (organization.OrganizationDto, error) {
+	uow := organization.NewUnitOfWork(srv.db)
+	defer uow.Dispose()
+
+	org, err := organizationByName(ctx, organization.NewRepository(uow), name)
+	if err != nil {
+		return organization.OrganizationDto{}, err
+	}
+	return org.ToDto(), nil
+}
+
+// UserOrganizations lists organizations user is member of
+func (srv *OrganizationService) UserOrganizations(ctx context.Context, username string) ([]organization.OrganizationDto, error) {
+	usr, err := user.NewUserDao(srv.db).FindByUsername(ctx, username)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.Wrapf(UserNotFoundErr, "user %s doesn't exist", username)
+		}
+		return nil, err
+	}
+	return organization.NewOrganizationDao(srv.db).FindAllForUser(ctx, usr.Id)
+}
+
+// DeleteOrganization deletes organization together with its members and roles assigned to them
+func (srv *OrganizationService) DeleteOrganization(ctx context.Context, name string) error {
+	uow := organization.NewUnitOfWork(srv.db)
+	repo := organization.NewRepository(uow)
+
+	org, err := organizationByName(ctx, repo, name)
+	if err != nil {
+		return err
+	}
+
+	if err := repo.Remove(org); err != nil {
+		return errors.Wrap(err, "failed to remove organization from repository")
+	}
+
+	return uow.Flush(ctx)
+}
+
+// Members lists members of organization with roles assigned to them within organization
+func (srv *OrganizationService) Members(ctx context.Context, name string) ([]organization.MemberDetailsDto, error) {
+	org, err := srv.Organization(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	names, err := organization.NewMemberDao(srv.db).FindNamesForOrganization(ctx, org.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	// names are ordered by username, so rows of the same member are adjacent
+	members := make([]organization.MemberDetailsDto, 0)
+	for _, name := range names {
+		if len(members) == 0 || members[len(members)-1].Username != name.Username {
+			members = append(members, organization.MemberDetailsDto{Username: name.Username, Roles: make([]string, 0)})
+		}
+
+		if name.RoleName != "" {
+			last := &members[len(members)-1]
+			last.Roles = append(last.Roles, name.RoleName)
+		}
+	}
+	return members, nil
+}
+
+func (srv *OrganizationService) AddMember(ctx context.Context, name string, username string) error {
+	return srv.amend(ctx, name, func(org *organization.Organization) error {
+		return org.AddMember(username, srv.findUserByNameFn(ctx))
+	})
+}
+
+// RemoveMember removes user from organization, roles assigned to user within organization are removed as well
+func (srv *OrganizationService) RemoveMember(ctx context.Context, name string, username string) error {
+	return srv.amend(ctx, name, func(org *organization.Organization) error {
+		return org.RemoveMember(username, srv.findUserByNameFn(ctx))
+	})
+}
+
+func (srv *OrganizationService) AssignRole(ctx context.Context, name string, username string, roleName string) error {
+	return srv.amend(ctx, name, func(org *organization.Organization) error {
+		return org.AssignRole(username, roleName, srv.findUserByNameFn(ctx), srv.findRoleByNameFn(ctx))
+	})
+}
+
+func (srv *OrganizationService) UnassignRole(ctx context.Context, name string, username string, roleName string) error {
+	return srv.amend(ctx, name, func(org *organization.Organization) error {
+		return org.UnassignRole(username, roleName, srv.findUserByNameFn(ctx), srv.findRoleByNameFn(ctx))
+	})
+}
+
+func (srv *OrganizationService) amend(ctx context.Context, name string, amendFn func(*organization.Organization) error) error {
+	uow := organization.NewUnitOfWork(srv.db)
+	repo := organization.NewRepository(uow)
+
+	org, err := organizationByName(ctx, repo, name)
+	if err != nil {
+		return err
+	}
+
+	if err := amendFn(org); err != nil {
+		return err
+	}
+
+	if err := repo.Update(org); err != nil {
+		return errors.Wrap(err, "failed to update organization in repository")
+	}
+
+	return uow.Flush(ctx)
+}
+
+func (srv *OrganizationService) findUserByNameFn(ctx context.Context) organization.UserFinderByNameFn {
+	return func(username string) (user.UserDto, error) {
+		var dto user.UserDto
+		dto, err := user.NewUserDao(srv.db).FindByUsername(ctx, username)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return dto, nil
+			}
+			return dto, err
+		}
+		return dto, nil
+	}
+}
+
+func (srv *OrganizationService) findRoleByNameFn(ctx context.Context) organization.RoleFinderByNameFn {
+	return func(name string) (role.RoleDto, error) {
+		var dto role.RoleDto
+		dto, err := role.NewRoleDao(srv.db).FindByName(ctx, name)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return dto, nil
+			}
+			return dto, err
+		}
+		return dto, nil
+	}
+}
+
+func organizationByName(ctx context.Context, repo *organization.Repository, name string) (*organization.Organization, error) {
+	org, err := repo.FindByName(ctx, name)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.Wrapf(OrganizationNotFoundErr, "organization %s doesn't exist", name)
+		}
+		return nil, errors.Wrap(err, "failed to find organization in repository")
+	}
+	return org, nil
+}
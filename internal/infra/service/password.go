@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/jmoiron/sqlx"
+	"github.com/umalmyha/authsrv/internal/business/reset"
+	"github.com/umalmyha/authsrv/internal/business/user"
+	valueobj "github.com/umalmyha/authsrv/internal/business/value-object"
+	"github.com/umalmyha/authsrv/pkg/mail"
+)
+
+var ResetTokenInvalidErr = errors.New("password reset token is invalid or expired")
+
+type PasswordService struct {
+	db       *sqlx.DB
+	rdb      *redis.Client
+	passCfg  valueobj.PasswordConfig
+	resetCfg valueobj.PasswordResetConfig
+	mailer   mail.Mailer
+}
+
+func NewPasswordService(
+	db *sqlx.DB,
+	rdb *redis.Client,
+	passCfg valueobj.PasswordConfig,
+	resetCfg valueobj.PasswordResetConfig,
+	mailer mail.Mailer,
+) *PasswordService {
+	return &PasswordService{
+		db:       db,
+		rdb:      rdb,
+		passCfg:  passCfg,
+		resetCfg: resetCfg,
+		mailer:   mailer,
+	}
+}
+
+// ForgotPassword emails reset token to user. Unknown users and users without email are ignored silently,
+// so response doesn't reveal which users exist, mailer is expected to deliver in background for the same reason.
+func (srv *PasswordService) ForgotPassword(ctx context.Context, username string) error {
+	if username == "" {
+		return nil
+	}
+
+	uow := user.NewUnitOfWork(srv.db, srv.rdb)
+	defer uow.Dispose()
+
+	usr, err := byUsername(username)(ctx, user.NewRepository(uow))
+	if err != nil {
+		if errors.Is(err, UserNotFoundErr) {
+			return nil
+		}
+		return err
+	}
+
+	// unverified email may belong to anyone, so reset link is sent only to email user proved to own
+	if !usr.EmailVerified() {
+		return nil
+	}
+
+	token, err := reset.NewToken(usr.Id(), time.Now().UTC(), srv.resetCfg)
+	if err != nil {
+		return errors.Wrap(err, "failed to issue password reset token")
+	}
+
+	if err := reset.NewTokenDao(srv.rdb).Save(ctx, token.Token(), token.Dto()); err != nil {
+		return errors.Wrap(err, "failed to save password reset token")
+	}
+
+	if err := srv.mailer.Send(ctx, srv.resetMessage(usr, token)); err != nil {
+		return errors.Wrap(err, "failed to send password reset email")
+	}
+	return nil
+}
+
+// ResetPassword sets new password with emailed token and revokes all refresh tokens of user
+func (srv *PasswordService) ResetPassword(ctx context.Context, resetDto user.PasswordResetDto) error {
+	if resetDto.Token == "" {
+		return ResetTokenInvalidErr
+	}
+
+	tokenDao := reset.NewTokenDao(srv.rdb)
+
+	tokenDto, err := tokenDao.Consume(ctx, resetDto.Token)
+	if err != nil {
+		return errors.Wrap(err, "failed to read password reset token")
+	}
+
+	if !tokenDto.IsPresent() || tokenDto.ToToken().IsExpired(time.Now().UTC()) {
+		return ResetTokenInvalidErr
+	}
+
+	uow := user.NewUnitOfWork(srv.db, srv.rdb)
+	repo := user.NewRepository(uow)
+
+	usr, err := byUserId(tokenDto.UserId)(ctx, repo)
+	if err != nil {
+		if errors.Is(err, UserNotFoundErr) {
+			return ResetTokenInvalidErr
+		}
+		return err
+	}
+
+	if err := usr.SetPassword(resetDto.Password, resetDto.ConfirmPassword, srv.passCfg); err != nil {
+		// rejected password doesn't spend token, so user can retry with the same email
+		if errors.Is(err, user.InvalidPasswordErr) {
+			if saveErr := tokenDao.Save(ctx, resetDto.Token, tokenDto); saveErr != nil {
+				return errors.Wrap(saveErr, "failed to restore password reset token")
+			}
+		}
+		return err
+	}
+	usr.RevokeAllSessions()
+
+	if err := repo.Update(usr); err != nil {
+		return errors.Wrap(err, "failed to update user in repository")
+	}
+
+	return uow.Flush(ctx)
+}
+
+// ChangePassword replaces password of signed in user, session of current refresh token is kept on revocation
+func (srv *PasswordService) ChangePassword(ctx context.Context, username string, change user.PasswordChangeDto, currentTokenId string) error {
+	uow := user.NewUnitOfWork(srv.db, srv.rdb)
+	repo := user.NewRepository(uow)
+
+	usr, err := byUsername(username)(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	if err := usr.ChangePassword(change.CurrentPassword, change.Password, change.ConfirmPassword, srv.passCfg); err != nil {
+		return err
+	}
+
+	if change.RevokeOtherSessions {
+		usr.RevokeOtherSessions(currentTokenId)
+	}
+
+	if err := repo.Update(usr); err != nil {
+		return errors.Wrap(err, "failed to update user in repository")
+	}
+
+	return uow.Flush(ctx)
+}
+
+func (srv *PasswordService) resetMessage(usr *user.User, token *reset.Token) mail.Message {
+	instruction := fmt.Sprintf("Use this token to set a new password: %s", token.Token())
+	if link := srv.resetCfg.ResetLink(token.Token()); link != "" {
+		instruction = fmt.Sprintf("Follow this link to set a new password: %s", link)
+	}
+
+	return mail.Message{
+		To:      []string{usr.Email()},
+		Subject: "Password reset",
+		Body: fmt.Sprintf(
+			"Hello %s,\n\nPassword reset was requested for your account.\n%s\n\nIt expires in %s. If you didn't request it, just ignore this email.\n",
+			usr.Username(),
+			instruction,
+			humanDuration(srv.resetCfg.TokenTimeToLive()),
+		),
+	}
+}
@@ -9,8 +9,18 @@ import (
 	"github.com/jmoiron/sqlx"
 	"github.com/umalmyha/authsrv/internal/business/role"
 	"github.com/umalmyha/authsrv/internal/business/scope"
+	valueobj "github.com/umalmyha/authsrv/internal/business/value-object"
+	"github.com/umalmyha/authsrv/pkg/helpers"
 )
 
+var (
+	RoleNotFoundErr = errors.New("role not found")
+	RoleInUseErr    = errors.New("role is assigned to users or groups or inherited by roles")
+	RoleBuiltinErr  = errors.New("built-in role can't be deleted")
+)
+
+type roleFinderFn func(context.Context, *role.Repository) (*role.Role, error)
+
 type RoleService struct {
 	db *sqlx.DB
 }
@@ -87,6 +97,199 @@ func (srv *RoleService) UnassignScope(ctx context.Context, roleName string, scop
 	return uow.Flush(ctx)
 }
 
+// InheritRole makes role inherit scopes of parent role, cycles in hierarchy are rejected with role.HierarchyCycleErr
+func (srv *RoleService) InheritRole(ctx context.Context, roleName string, parentName string) error {
+	ancestorsFn := func(roleId string) ([]string, error) {
+		return role.NewRoleDao(srv.db).FindAncestorIds(ctx, roleId)
+	}
+
+	return srv.amend(ctx, roleByName(roleName), func(r *role.Role) error {
+		return r.InheritFrom(parentName, srv.findRoleByNameFn(ctx), ancestorsFn)
+	})
+}
+
+func (srv *RoleService) DisinheritRole(ctx context.Context, roleName string, parentName string) error {
+	return srv.amend(ctx, roleByName(roleName), func(r *role.Role) error {
+		return r.DisinheritFrom(parentName, srv.findRoleByNameFn(ctx))
+	})
+}
+
+func (srv *RoleService) Roles(ctx context.Context) ([]role.RoleDetailsDto, error) {
+	roles, err := role.NewRoleDao(srv.db).FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return srv.details(ctx, roles)
+}
+
+func (srv *RoleService) Role(ctx context.Context, roleId string) (role.RoleDetailsDto, error) {
+	uow := role.NewUnitOfWork(srv.db)
+	defer uow.Dispose()
+
+	r, err := roleById(roleId)(ctx, role.NewRepository(uow))
+	if err != nil {
+		return role.RoleDetailsDto{}, err
+	}
+	return srv.roleDetails(ctx, r)
+}
+
+func (srv *RoleService) UpdateRole(ctx context.Context, roleId string, update role.UpdateRoleDto) (role.RoleDetailsDto, error) {
+	uow := role.NewUnitOfWork(srv.db)
+	repo := role.NewRepository(uow)
+
+	r, err := roleById(roleId)(ctx, repo)
+	if err != nil {
+		return role.RoleDetailsDto{}, err
+	}
+
+	if update.Description != nil {
+		r.ChangeDescription(*update.Description)
+	}
+
+	if err := repo.Update(r); err != nil {
+		return role.RoleDetailsDto{}, errors.Wrap(err, "failed to update role in repository")
+	}
+
+	if err := uow.Flush(ctx); err != nil {
+		return role.RoleDetailsDto{}, errors.Wrap(err, "failed to flush changes")
+	}
+	return srv.roleDetails(ctx, r)
+}
+
+// DeleteRole deletes role with its scope assignments. Role assigned to users or groups is deleted only if policy cascades,
+// otherwise RoleInUseErr is returned. Report lists affected users and groups in both cases.
+func (srv *RoleService) DeleteRole(ctx context.Context, roleId string, policy valueobj.DeletePolicy) (role.DeletionReportDto, error) {
+	return srv.delete(ctx, roleById(roleId), policy)
+}
+
+func (srv *RoleService) DeleteRoleByName(ctx context.Context, name string, policy valueobj.DeletePolicy) (role.DeletionReportDto, error) {
+	return srv.delete(ctx, roleByName(name), policy)
+}
+
+func (srv *RoleService) delete(ctx context.Context, finderFn roleFinderFn, policy valueobj.DeletePolicy) (role.DeletionReportDto, error) {
+	uow := role.NewUnitOfWork(srv.db)
+	repo := role.NewRepository(uow)
+
+	r, err := finderFn(ctx, repo)
+	if err != nil {
+		return role.DeletionReportDto{}, err
+	}
+
+	if role.IsBuiltin(r.Name()) {
+		return role.DeletionReportDto{}, errors.Wrapf(RoleBuiltinErr, "role %s is built-in", r.Name())
+	}
+
+	roleDao := role.NewRoleDao(srv.db)
+
+	users, err := roleDao.FindAssignedUsernames(ctx, r.Id())
+	if err != nil {
+		return role.DeletionReportDto{}, err
+	}
+
+	groups, err := roleDao.FindAssignedGroupNames(ctx, r.Id())
+	if err != nil {
+		return role.DeletionReportDto{}, err
+	}
+
+	children, err := roleDao.FindChildNames(ctx, r.Id())
+	if err != nil {
+		return role.DeletionReportDto{}, err
+	}
+
+	report := role.DeletionReportDto{
+		Role:     r.Name(),
+		Cascaded: policy.IsCascade(),
+		Users:    users,
+		Groups:   groups,
+		Children: children,
+	}
+
+	if report.IsRejected() {
+		return report, errors.Wrapf(
+			RoleInUseErr,
+			"role %s is assigned to %d users and %d groups and inherited by %d roles",
+			r.Name(),
+			len(users),
+			len(groups),
+			len(children),
+		)
+	}
+
+	if err := repo.Remove(r, policy); err != nil {
+		return report, errors.Wrap(err, "failed to remove role from repository")
+	}
+
+	if err := uow.Flush(ctx); err != nil {
+		return report, errors.Wrap(err, "failed to flush changes")
+	}
+	return report, nil
+}
+
+func (srv *RoleService) roleDetails(ctx context.Context, r *role.Role) (role.RoleDetailsDto, error) {
+	details, err := srv.details(ctx, []role.RoleDto{r.ToDto()})
+	if err != nil {
+		return role.RoleDetailsDto{}, err
+	}
+	return details[0], nil
+}
+
+func (srv *RoleService) details(ctx context.Context, roles []role.RoleDto) ([]role.RoleDetailsDto, error) {
+	roleIds := helpers.Map(roles, func(r role.RoleDto, _ int, _ []role.RoleDto) string {
+		return r.Id
+	})
+
+	scopesDao := role.NewScopeAssignmentDao(srv.db)
+
+	scopes, err := scopesDao.FindScopeNamesWhereRoleIdsIn(ctx, roleIds)
+	if err != nil {
+		return nil, err
+	}
+
+	effectiveScopes, err := scopesDao.FindEffectiveScopeNamesWhereRoleIdsIn(ctx, roleIds)
+	if err != nil {
+		return nil, err
+	}
+
+	parents, err := role.NewParentAssignmentDao(srv.db).FindParentNamesWhereRoleIdsIn(ctx, roleIds)
+	if err != nil {
+		return nil, err
+	}
+
+	scopeNameFn := func(sc role.RoleScopeNameDto, _ int, _ []role.RoleScopeNameDto) (string, string) {
+		return sc.RoleId, sc.ScopeName
+	}
+	roleScopes := helpers.GroupBy(scopes, scopeNameFn)
+	roleEffectiveScopes := helpers.GroupBy(effectiveScopes, scopeNameFn)
+
+	roleParents := helpers.GroupBy(parents, func(parent role.RoleParentNameDto, _ int, _ []role.RoleParentNameDto) (string, string) {
+		return parent.RoleId, parent.ParentName
+	})
+
+	return helpers.Map(roles, func(r role.RoleDto, _ int, _ []role.RoleDto) role.RoleDetailsDto {
+		return r.Details(roleScopes[r.Id], roleParents[r.Id], roleEffectiveScopes[r.Id])
+	}), nil
+}
+
+func (srv *RoleService) amend(ctx context.Context, finderFn roleFinderFn, amendFn func(*role.Role) error) error {
+	uow := role.NewUnitOfWork(srv.db)
+	repo := role.NewRepository(uow)
+
+	r, err := finderFn(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	if err := amendFn(r); err != nil {
+		return err
+	}
+
+	if err := repo.Update(r); err != nil {
+		return errors.Wrap(err, "failed to update role in repository")
+	}
+
+	return uow.Flush(ctx)
+}
+
 func (srv *RoleService) findScopeByNameFn(ctx context.Context) role.ScopeFinderByNameFn {
 	return func(name string) (scope.ScopeDto, error) {
 		var dto scope.ScopeDto
@@ -100,3 +303,43 @@ func (srv *RoleService) findScopeByNameFn(ctx context.Context) role.ScopeFinderB
 		return dto, nil
 	}
 }
+
+func (srv *RoleService) findRoleByNameFn(ctx context.Context) role.RoleFinderByNameFn {
+	return func(name string) (role.RoleDto, error) {
+		var dto role.RoleDto
+		dto, err := role.NewRoleDao(srv.db).FindByName(ctx, name)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return dto, nil
+			}
+			return dto, err
+		}
+		return dto, nil
+	}
+}
+
+func roleById(roleId string) roleFinderFn {
+	return func(ctx context.Context, repo *role.Repository) (*role.Role, error) {
+		r, err := repo.FindById(ctx, roleId)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, errors.Wrapf(RoleNotFoundErr, "role with id %s doesn't exist", roleId)
+			}
+			return nil, errors.Wrap(err, "failed to find role in repository")
+		}
+		return r, nil
+	}
+}
+
+func roleByName(name string) roleFinderFn {
+	return func(ctx context.Context, repo *role.Repository) (*role.Role, error) {
+		r, err := repo.FindByName(ctx, name)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, errors.Wrapf(RoleNotFoundErr, "role %s doesn't exist", name)
+			}
+			return nil, errors.Wrap(err, "failed to find role in repository")
+		}
+		return r, nil
+	}
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	"github.com/umalmyha/authsrv/internal/business/user"
+)
+
+// RoleExpiredListenerFn is notified about every role assignment removed by sweeper
+type RoleExpiredListenerFn func(user.ExpiredRoleAssignmentDto)
+
+// RoleExpirySweeper periodically deletes role assignments which validity ended. Expired assignments
+// grant nothing even before they are swept, sweeper only keeps assignments table clean and emits events.
+type RoleExpirySweeper struct {
+	db         *sqlx.DB
+	interval   time.Duration
+	listenerFn RoleExpiredListenerFn
+	logger     *log.Logger
+}
+
+func NewRoleExpirySweeper(db *sqlx.DB, interval time.Duration, listenerFn RoleExpiredListenerFn, logger *log.Logger) *RoleExpirySweeper {
+	return &RoleExpirySweeper{
+		db:         db,
+		interval:   interval,
+		listenerFn: listenerFn,
+		logger:     logger,
+	}
+}
+
+// Run sweeps expired assignments every interval until context is done
+func (s *RoleExpirySweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if _, err := s.Sweep(ctx, now); err != nil {
+				s.logger.Printf("failed to sweep expired role assignments: %v", err)
+			}
+		}
+	}
+}
+
+// Sweep deletes assignments expired by given moment and emits event for each of them
+func (s *RoleExpirySweeper) Sweep(ctx context.Context, now time.Time) ([]user.ExpiredRoleAssignmentDto, error) {
+	expired, err := user.NewRoleAssignmentDao(s.db).DeleteExpired(ctx, now)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to delete expired role assignments")
+	}
+
+	for _, assignment := range expired {
+		s.listenerFn(assignment)
+	}
+	return expired, nil
+}
@@ -8,8 +8,17 @@ import (
 
 	"github.com/jmoiron/sqlx"
 	"github.com/umalmyha/authsrv/internal/business/scope"
+	valueobj "github.com/umalmyha/authsrv/internal/business/value-object"
 )
 
+var (
+	ScopeNotFoundErr = errors.New("scope not found")
+	ScopeInUseErr    = errors.New("scope is assigned to roles or clients")
+	ScopeBuiltinErr  = errors.New("built-in scope can't be deleted")
+)
+
+type scopeFinderFn func(context.Context, *scope.Repository) (*scope.Scope, error)
+
 type ScopeService struct {
 	db *sqlx.DB
 }
@@ -38,3 +47,116 @@ func (srv *ScopeService) CreateScope(ctx context.Context, ns scope.NewScopeDto)
 
 	return scope.NewRepository(srv.db).Create(ctx, sc)
 }
+
+func (srv *ScopeService) Scopes(ctx context.Context) ([]scope.ScopeDto, error) {
+	return scope.NewScopeDao(srv.db).FindAll(ctx)
+}
+
+func (srv *ScopeService) Scope(ctx context.Context, scopeId string) (scope.ScopeDto, error) {
+	sc, err := scopeById(scopeId)(ctx, scope.NewRepository(srv.db))
+	if err != nil {
+		return scope.ScopeDto{}, err
+	}
+	return sc.Dto(), nil
+}
+
+func (srv *ScopeService) UpdateScope(ctx context.Context, scopeId string, update scope.UpdateScopeDto) (scope.ScopeDto, error) {
+	repo := scope.NewRepository(srv.db)
+
+	sc, err := scopeById(scopeId)(ctx, repo)
+	if err != nil {
+		return scope.ScopeDto{}, err
+	}
+
+	if update.Description != nil {
+		sc.ChangeDescription(*update.Description)
+	}
+
+	if err := repo.Update(ctx, sc); err != nil {
+		return scope.ScopeDto{}, err
+	}
+	return sc.Dto(), nil
+}
+
+// DeleteScope deletes scope. Scope assigned to roles or OAuth clients is deleted only if policy cascades, otherwise
+// ScopeInUseErr is returned. Report lists affected roles, clients and users granted with scope in both cases.
+func (srv *ScopeService) DeleteScope(ctx context.Context, scopeId string, policy valueobj.DeletePolicy) (scope.DeletionReportDto, error) {
+	return srv.delete(ctx, scopeById(scopeId), policy)
+}
+
+func (srv *ScopeService) DeleteScopeByName(ctx context.Context, name string, policy valueobj.DeletePolicy) (scope.DeletionReportDto, error) {
+	return srv.delete(ctx, scopeByName(name), policy)
+}
+
+func (srv *ScopeService) delete(ctx context.Context, finderFn scopeFinderFn, policy valueobj.DeletePolicy) (scope.DeletionReportDto, error) {
+	repo := scope.NewRepository(srv.db)
+
+	sc, err := finderFn(ctx, repo)
+	if err != nil {
+		return scope.DeletionReportDto{}, err
+	}
+
+	if scope.IsBuiltin(sc.Name()) {
+		return scope.DeletionReportDto{}, errors.Wrapf(ScopeBuiltinErr, "scope %s is built-in", sc.Name())
+	}
+
+	dao := scope.NewScopeDao(srv.db)
+
+	roles, err := dao.FindAssignedRoleNames(ctx, sc.Id())
+	if err != nil {
+		return scope.DeletionReportDto{}, err
+	}
+
+	clients, err := dao.FindAllowedClientNames(ctx, sc.Id())
+	if err != nil {
+		return scope.DeletionReportDto{}, err
+	}
+
+	users, err := dao.FindGrantedUsernames(ctx, sc.Id())
+	if err != nil {
+		return scope.DeletionReportDto{}, err
+	}
+
+	report := scope.DeletionReportDto{
+		Scope:    sc.Name(),
+		Cascaded: policy.IsCascade(),
+		Roles:    roles,
+		Clients:  clients,
+		Users:    users,
+	}
+
+	if report.IsRejected() {
+		return report, errors.Wrapf(ScopeInUseErr, "scope %s is assigned to %d roles and %d clients", sc.Name(), len(roles), len(clients))
+	}
+
+	if err := repo.Remove(ctx, sc, policy); err != nil {
+		return report, errors.Wrap(err, "failed to remove scope from repository")
+	}
+	return report, nil
+}
+
+func scopeById(scopeId string) scopeFinderFn {
+	return func(ctx context.Context, repo *scope.Repository) (*scope.Scope, error) {
+		sc, err := repo.FindById(ctx, scopeId)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, errors.Wrapf(ScopeNotFoundErr, "scope with id %s doesn't exist", scopeId)
+			}
+			return nil, errors.Wrap(err, "failed to find scope in repository")
+		}
+		return sc, nil
+	}
+}
+
+func scopeByName(name string) scopeFinderFn {
+	return func(ctx context.Context, repo *scope.Repository) (*scope.Scope, error) {
+		sc, err := repo.FindByName(ctx, name)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, errors.Wrapf(ScopeNotFoundErr, "scope %s doesn't exist", name)
+			}
+			return nil, errors.Wrap(err, "failed to find scope in repository")
+		}
+		return sc, nil
+	}
+}
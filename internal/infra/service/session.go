@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pkg/errors"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/jmoiron/sqlx"
+	"github.com/umalmyha/authsrv/internal/business/user"
+)
+
+var UserNotFoundErr = errors.New("user not found")
+
+type userFinderFn func(context.Context, *user.Repository) (*user.User, error)
+
+// SessionService manages sign in sessions, each method has variant for user found by username (self-service)
+// and by id (administration)
+type SessionService struct {
+	db  *sqlx.DB
+	rdb *redis.Client
+}
+
+func NewSessionService(db *sqlx.DB, rdb *redis.Client) *SessionService {
+	return &SessionService{
+		db:  db,
+		rdb: rdb,
+	}
+}
+
+func (srv *SessionService) Sessions(ctx context.Context, username string) ([]user.SessionDto, error) {
+	return srv.sessions(ctx, byUsername(username))
+}
+
+func (srv *SessionService) UserSessions(ctx context.Context, userId string) ([]user.SessionDto, error) {
+	return srv.sessions(ctx, byUserId(userId))
+}
+
+func (srv *SessionService) RevokeSession(ctx context.Context, username string, sessionId string) error {
+	return srv.revoke(ctx, byUsername(username), func(usr *user.User) error {
+		return usr.RevokeSession(sessionId)
+	})
+}
+
+func (srv *SessionService) RevokeUserSession(ctx context.Context, userId string, sessionId string) error {
+	return srv.revoke(ctx, byUserId(userId), func(usr *user.User) error {
+		return usr.RevokeSession(sessionId)
+	})
+}
+
+func (srv *SessionService) RevokeAllSessions(ctx context.Context, username string) error {
+	return srv.revoke(ctx, byUsername(username), func(usr *user.User) error {
+		usr.RevokeAllSessions()
+		return nil
+	})
+}
+
+func (srv *SessionService) RevokeAllUserSessions(ctx context.Context, userId string) error {
+	return srv.revoke(ctx, byUserId(userId), func(usr *user.User) error {
+		usr.RevokeAllSessions()
+		return nil
+	})
+}
+
+func (srv *SessionService) sessions(ctx context.Context, finderFn userFinderFn) ([]user.SessionDto, error) {
+	uow := user.NewUnitOfWork(srv.db, srv.rdb)
+	defer uow.Dispose()
+
+	usr, err := finderFn(ctx, user.NewRepository(uow))
+	if err != nil {
+		return nil, err
+	}
+	return usr.Sessions(), nil
+}
+
+func (srv *SessionService) revoke(ctx context.Context, finderFn userFinderFn, revokeFn func(*user.User) error) error {
+	uow := user.NewUnitOfWork(srv.db, srv.rdb)
+	repo := user.NewRepository(uow)
+
+	usr, err := finderFn(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	if err := revokeFn(usr); err != nil {
+		return err
+	}
+
+	if err := repo.Update(usr); err != nil {
+		return errors.Wrap(err, "failed to update user in repository")
+	}
+
+	return uow.Flush(ctx)
+}
+
+func byUsername(username string) userFinderFn {
+	return func(ctx context.Context, repo *user.Repository) (*user.User, error) {
+		usr, err := repo.FindByUsername(ctx, username)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, errors.Wrapf(UserNotFoundErr, "user %s doesn't exist", username)
+			}
+			return nil, errors.Wrap(err, "failed to find user in repository")
+		}
+		return usr, nil
+	}
+}
+
+func byUserId(userId string) userFinderFn {
+	return func(ctx context.Context, repo *user.Repository) (*user.User, error) {
+		usr, err := repo.FindById(ctx, userId)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, errors.Wrapf(UserNotFoundErr, "user with id %s doesn't exist", userId)
+			}
+			return nil, errors.Wrap(err, "failed to find user in repository")
+		}
+		return usr, nil
+	}
+}
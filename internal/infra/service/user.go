@@ -3,13 +3,17 @@ package service
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/pkg/errors"
 
 	"github.com/go-redis/redis/v8"
 	"github.com/jmoiron/sqlx"
+	"github.com/umalmyha/authsrv/internal/business/lockout"
 	"github.com/umalmyha/authsrv/internal/business/role"
 	"github.com/umalmyha/authsrv/internal/business/user"
+	valueobj "github.com/umalmyha/authsrv/internal/business/value-object"
+	"github.com/umalmyha/authsrv/pkg/helpers"
 )
 
 type UserService struct {
@@ -24,7 +28,13 @@ func NewUserService(db *sqlx.DB, rdb *redis.Client) *UserService {
 	}
 }
 
-func (srv *UserService) AssignRole(ctx context.Context, username string, roleName string) error {
+// AssignRole assigns role to user, missing bounds of validity window mean role is effective without time limit
+func (srv *UserService) AssignRole(ctx context.Context, username string, roleName string, validFrom *time.Time, validUntil *time.Time) error {
+	validity, err := valueobj.NewValidity(validFrom, validUntil)
+	if err != nil {
+		return err
+	}
+
 	uow := user.NewUnitOfWork(srv.db, srv.rdb)
 	repo := user.NewRepository(uow)
 
@@ -37,7 +47,7 @@ func (srv *UserService) AssignRole(ctx context.Context, username string, roleNam
 		return errors.Errorf("user %s doesn't exist", username)
 	}
 
-	if err := user.AssignRole(roleName, srv.findRoleByNameFn(ctx)); err != nil {
+	if err := user.AssignRole(roleName, validity, time.Now().UTC(), srv.findRoleByNameFn(ctx)); err != nil {
 		return errors.Wrap(err, "failed to assign role")
 	}
 
@@ -72,6 +82,203 @@ func (srv *UserService) UnassignRole(ctx context.Context, username string, roleN
 	return uow.Flush(ctx)
 }
 
+func (srv *UserService) Profile(ctx context.Context, username string) (user.ProfileDto, error) {
+	uow := user.NewUnitOfWork(srv.db, srv.rdb)
+	defer uow.Dispose()
+
+	usr, err := byUsername(username)(ctx, user.NewRepository(uow))
+	if err != nil {
+		return user.ProfileDto{}, err
+	}
+	return usr.Profile(), nil
+}
+
+// UpdateProfile applies self-service changes, returned flag reports that email was changed and must be verified
+func (srv *UserService) UpdateProfile(ctx context.Context, username string, update user.ProfileUpdateDto) (user.ProfileDto, bool, error) {
+	uow := user.NewUnitOfWork(srv.db, srv.rdb)
+	repo := user.NewRepository(uow)
+
+	usr, err := byUsername(username)(ctx, repo)
+	if err != nil {
+		return user.ProfileDto{}, false, err
+	}
+
+	existEmailFn := func(email string) (bool, error) {
+		return user.NewUserDao(srv.db).ExistsByEmail(ctx, email, usr.Id())
+	}
+
+	emailChanged, err := usr.UpdateOwnProfile(update, existEmailFn)
+	if err != nil {
+		return user.ProfileDto{}, false, err
+	}
+
+	if err := repo.Update(usr); err != nil {
+		return user.ProfileDto{}, false, errors.Wrap(err, "failed to update user in repository")
+	}
+
+	if err := uow.Flush(ctx); err != nil {
+		return user.ProfileDto{}, false, errors.Wrap(err, "failed to flush changes")
+	}
+
+	return usr.Profile(), emailChanged, nil
+}
+
+// Users reads page of users for administration together with total number of users matching filter
+func (srv *UserService) Users(ctx context.Context, filter user.UserFilterDto) (user.UserPageDto, error) {
+	userDao := user.NewUserDao(srv.db)
+
+	total, err := userDao.Count(ctx, filter)
+	if err != nil {
+		return user.UserPageDto{}, err
+	}
+
+	users, err := userDao.FindAll(ctx, filter)
+	if err != nil {
+		return user.UserPageDto{}, err
+	}
+
+	userIds := helpers.Map(users, func(usr user.UserDto, _ int, _ []user.UserDto) string {
+		return usr.Id
+	})
+
+	// tenant sees only roles its members have in it, global and other tenants' roles aren't revealed
+	roleDao := user.NewRoleAssignmentDao(srv.db)
+	var roles []user.UserRoleNameDto
+	if filter.Organization != "" {
+		roles, err = roleDao.FindOrganizationRoleNamesWhereUserIdsIn(ctx, filter.Organization, userIds)
+	} else {
+		roles, err = roleDao.FindRoleNamesWhereUserIdsIn(ctx, userIds)
+	}
+	if err != nil {
+		return user.UserPageDto{}, err
+	}
+
+	userRoles := helpers.GroupBy(roles, func(role user.UserRoleNameDto, _ int, _ []user.UserRoleNameDto) (string, string) {
+		return role.UserId, role.RoleName
+	})
+
+	return user.UserPageDto{
+		Users: helpers.Map(users, func(usr user.UserDto, _ int, _ []user.UserDto) user.UserSummaryDto {
+			return usr.Summary(userRoles[usr.Id])
+		}),
+		Total:  total,
+		Limit:  filter.Limit,
+		Offset: filter.Offset,
+	}, nil
+}
+
+func (srv *UserService) User(ctx context.Context, userId string) (user.UserSummaryDto, error) {
+	uow := user.NewUnitOfWork(srv.db, srv.rdb)
+	defer uow.Dispose()
+
+	usr, err := byUserId(userId)(ctx, user.NewRepository(uow))
+	if err != nil {
+		return user.UserSummaryDto{}, err
+	}
+	return usr.Summary(), nil
+}
+
+// UpdateUser applies profile changes on behalf of user, returned flag reports that email was changed and must be verified
+func (srv *UserService) UpdateUser(ctx context.Context, userId string, update user.ProfileUpdateDto) (user.UserSummaryDto, bool, error) {
+	var summary user.UserSummaryDto
+	emailChanged := false
+
+	err := srv.amend(ctx, byUserId(userId), func(usr *user.User) error {
+		existEmailFn := func(email string) (bool, error) {
+			return user.NewUserDao(srv.db).ExistsByEmail(ctx, email, usr.Id())
+		}
+
+		changed, err := usr.UpdateProfile(update, existEmailFn)
+		if err != nil {
+			return err
+		}
+
+		emailChanged = changed
+		summary = usr.Summary()
+		return nil
+	})
+	return summary, emailChanged, err
+}
+
+// DisableUser blocks sign in of user and revokes all sessions
+func (srv *UserService) DisableUser(ctx context.Context, userId string) error {
+	return srv.amend(ctx, byUserId(userId), func(usr *user.User) error {
+		usr.Disable(time.Now().UTC())
+		return nil
+	})
+}
+
+func (srv *UserService) EnableUser(ctx context.Context, userId string) error {
+	return srv.amend(ctx, byUserId(userId), func(usr *user.User) error {
+		usr.Enable()
+		return nil
+	})
+}
+
+// DeleteUser removes user with all assigned roles, recovery codes, passkeys and sessions
+func (srv *UserService) DeleteUser(ctx context.Context, userId string) error {
+	uow := user.NewUnitOfWork(srv.db, srv.rdb)
+	repo := user.NewRepository(uow)
+
+	usr, err := byUserId(userId)(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	if err := repo.Remove(usr); err != nil {
+		return errors.Wrap(err, "failed to remove user from repository")
+	}
+
+	if err := uow.Flush(ctx); err != nil {
+		return err
+	}
+
+	return lockout.NewAttemptDao(srv.rdb).Reset(ctx, lockout.AccountSubject(usr.Id()))
+}
+
+// UnlockUser lifts lock applied after failed sign in attempts, failures counted so far are forgotten as well
+func (srv *UserService) UnlockUser(ctx context.Context, userId string) error {
+	return srv.unlock(ctx, byUserId(userId))
+}
+
+func (srv *UserService) UnlockUserByUsername(ctx context.Context, username string) error {
+	return srv.unlock(ctx, byUsername(username))
+}
+
+func (srv *UserService) unlock(ctx context.Context, finderFn userFinderFn) error {
+	var userId string
+	err := srv.amend(ctx, finderFn, func(usr *user.User) error {
+		usr.Unlock()
+		userId = usr.Id()
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return lockout.NewAttemptDao(srv.rdb).Reset(ctx, lockout.AccountSubject(userId))
+}
+
+func (srv *UserService) amend(ctx context.Context, finderFn userFinderFn, amendFn func(*user.User) error) error {
+	uow := user.NewUnitOfWork(srv.db, srv.rdb)
+	repo := user.NewRepository(uow)
+
+	usr, err := finderFn(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	if err := amendFn(usr); err != nil {
+		return err
+	}
+
+	if err := repo.Update(usr); err != nil {
+		return errors.Wrap(err, "failed to update user in repository")
+	}
+
+	return uow.Flush(ctx)
+}
+
 func (srv *UserService) findRoleByNameFn(ctx context.Context) user.RoleFinderByNameFn {
 	return func(name string) (role.RoleDto, error) {
 		var dto role.RoleDto
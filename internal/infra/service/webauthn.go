@@ -0,0 +1,268 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/jmoiron/sqlx"
+	"github.com/umalmyha/authsrv/internal/business/refresh"
+	"github.com/umalmyha/authsrv/internal/business/user"
+	valueobj "github.com/umalmyha/authsrv/internal/business/value-object"
+	"github.com/umalmyha/authsrv/internal/business/webauthn"
+	"github.com/umalmyha/authsrv/pkg/helpers"
+)
+
+var WebauthnSessionInvalidErr = errors.New("webauthn session is invalid or was already used")
+
+// user verification is required on passkey sign in, so possession and biometrics/PIN are both proven
+var passkeyAmr = []string{valueobj.AmrHardware, valueobj.AmrUser, valueobj.AmrMfa}
+
+type WebauthnService struct {
+	db          *sqlx.DB
+	rdb         *redis.Client
+	jwtCfg      valueobj.JwtConfig
+	refreshCfg  valueobj.RefreshTokenConfig
+	webauthnCfg valueobj.WebauthnConfig
+	emailCfg    valueobj.EmailVerificationConfig
+}
+
+func NewWebauthnService(
+	db *sqlx.DB,
+	rdb *redis.Client,
+	jwtCfg valueobj.JwtConfig,
+	rfrCfg valueobj.RefreshTokenConfig,
+	webauthnCfg valueobj.WebauthnConfig,
+	emailCfg valueobj.EmailVerificationConfig,
+) *WebauthnService {
+	return &WebauthnService{
+		db:          db,
+		rdb:         rdb,
+		jwtCfg:      jwtCfg,
+		refreshCfg:  rfrCfg,
+		webauthnCfg: webauthnCfg,
+		emailCfg:    emailCfg,
+	}
+}
+
+// BeginRegistration starts passkey registration for signed in user, already registered credentials are excluded
+func (srv *WebauthnService) BeginRegistration(ctx context.Context, username string) (webauthn.CreationOptionsDto, error) {
+	var options webauthn.CreationOptionsDto
+
+	uow := user.NewUnitOfWork(srv.db, srv.rdb)
+	defer uow.Dispose()
+	repo := user.NewRepository(uow)
+
+	usr, err := byUsername(username)(ctx, repo)
+	if err != nil {
+		return options, err
+	}
+
+	session, err := webauthn.NewRegistrationSession(usr.Id(), time.Now().UTC(), srv.webauthnCfg)
+	if err != nil {
+		return options, errors.Wrap(err, "failed to start webauthn registration")
+	}
+
+	if err := webauthn.NewSessionDao(srv.rdb).Save(ctx, session.Dto()); err != nil {
+		return options, errors.Wrap(err, "failed to save webauthn session")
+	}
+
+	return session.CreationOptions(usr.Username(), credentialDescriptors(usr), srv.webauthnCfg), nil
+}
+
+func (srv *WebauthnService) FinishRegistration(ctx context.Context, username string, resp webauthn.RegistrationResponseDto) (webauthn.CredentialInfoDto, error) {
+	var info webauthn.CredentialInfoDto
+
+	session, err := srv.consumeSession(ctx, resp.Response.ClientDataJson)
+	if err != nil {
+		return info, err
+	}
+
+	uow := user.NewUnitOfWork(srv.db, srv.rdb)
+	repo := user.NewRepository(uow)
+
+	usr, err := byUsername(username)(ctx, repo)
+	if err != nil {
+		return info, err
+	}
+
+	// session is bound to user who started registration, so response can't be replayed by another account
+	if session.UserId() != usr.Id() {
+		return info, WebauthnSessionInvalidErr
+	}
+
+	cred, err := webauthn.VerifyRegistration(session, resp, srv.webauthnCfg, time.Now().UTC())
+	if err != nil {
+		return info, err
+	}
+
+	if err := usr.RegisterCredential(cred); err != nil {
+		return info, err
+	}
+
+	if err := repo.Update(usr); err != nil {
+		return info, errors.Wrap(err, "failed to update user in repository")
+	}
+
+	if err := uow.Flush(ctx); err != nil {
+		return info, errors.Wrap(err, "failed to flush changes")
+	}
+
+	return cred.Info(), nil
+}
+
+// BeginLogin starts passkey sign in, without username discoverable credentials are requested.
+// Unknown usernames get the same discoverable options, so response doesn't reveal which users exist.
+func (srv *WebauthnService) BeginLogin(ctx context.Context, username string) (webauthn.RequestOptionsDto, error) {
+	var options webauthn.RequestOptionsDto
+
+	userId := ""
+	allow := make([]webauthn.CredentialDescriptorDto, 0)
+
+	if username != "" {
+		uow := user.NewUnitOfWork(srv.db, srv.rdb)
+		defer uow.Dispose()
+
+		usr, err := byUsername(username)(ctx, user.NewRepository(uow))
+		if err != nil && !errors.Is(err, UserNotFoundErr) {
+			return options, err
+		}
+
+		if usr != nil && len(usr.Credentials()) > 0 {
+			userId = usr.Id()
+			allow = credentialDescriptors(usr)
+		}
+	}
+
+	allowedIds := helpers.Map(allow, func(cred webauthn.CredentialDescriptorDto, _ int, _ []webauthn.CredentialDescriptorDto) string {
+		return cred.Id
+	})
+
+	session, err := webauthn.NewAssertionSession(userId, allowedIds, time.Now().UTC(), srv.webauthnCfg)
+	if err != nil {
+		return options, errors.Wrap(err, "failed to start webauthn sign in")
+	}
+
+	if err := webauthn.NewSessionDao(srv.rdb).Save(ctx, session.Dto()); err != nil {
+		return options, errors.Wrap(err, "failed to save webauthn session")
+	}
+
+	return session.RequestOptions(allow, srv.webauthnCfg), nil
+}
+
+// FinishLogin verifies assertion and issues the same tokens as password sign in
+func (srv *WebauthnService) FinishLogin(ctx context.Context, signin user.PasskeySigninDto) (valueobj.Jwt, *refresh.RefreshToken, error) {
+	session, err := srv.consumeSession(ctx, signin.Credential.Response.ClientDataJson)
+	if err != nil {
+		return valueobj.Jwt{}, nil, err
+	}
+
+	userId := session.UserId()
+	if userId == "" {
+		credId, err := webauthn.CredentialIdFromResponse(signin.Credential)
+		if err != nil {
+			return valueobj.Jwt{}, nil, err
+		}
+
+		if userId, err = webauthn.NewCredentialDao(srv.db).FindUserIdById(ctx, credId); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return valueobj.Jwt{}, nil, user.CredentialNotFoundErr
+			}
+			return valueobj.Jwt{}, nil, err
+		}
+	}
+
+	uow := user.NewUnitOfWork(srv.db, srv.rdb)
+	repo := user.NewRepository(uow)
+
+	usr, err := byUserId(userId)(ctx, repo)
+	if err != nil {
+		return valueobj.Jwt{}, nil, err
+	}
+
+	now := time.Now().UTC()
+	if err := usr.VerifyAssertion(session, signin.Credential, srv.webauthnCfg, now); err != nil {
+		return valueobj.Jwt{}, nil, err
+	}
+
+	// checked only after assertion, so verification status isn't revealed to anyone without the passkey
+	if srv.emailCfg.Required() && !usr.EmailVerified() {
+		return valueobj.Jwt{}, nil, EmailNotVerifiedErr
+	}
+
+	accessToken, refreshToken, err := issueSession(usr, signin.Fingerprint, "", signin.Client, passkeyAmr, now, srv.jwtCfg, srv.refreshCfg)
+	if err != nil {
+		return valueobj.Jwt{}, nil, err
+	}
+
+	if err := repo.Update(usr); err != nil {
+		return valueobj.Jwt{}, nil, errors.Wrap(err, "failed to update user in repository")
+	}
+
+	if err := uow.Flush(ctx); err != nil {
+		return valueobj.Jwt{}, nil, errors.Wrap(err, "failed to flush changes")
+	}
+
+	return accessToken, refreshToken, nil
+}
+
+func (srv *WebauthnService) Credentials(ctx context.Context, username string) ([]webauthn.CredentialInfoDto, error) {
+	uow := user.NewUnitOfWork(srv.db, srv.rdb)
+	defer uow.Dispose()
+
+	usr, err := byUsername(username)(ctx, user.NewRepository(uow))
+	if err != nil {
+		return nil, err
+	}
+
+	return helpers.Map(usr.Credentials(), func(cred *webauthn.Credential, _ int, _ []*webauthn.Credential) webauthn.CredentialInfoDto {
+		return cred.Info()
+	}), nil
+}
+
+func (srv *WebauthnService) RemoveCredential(ctx context.Context, username string, id string) error {
+	uow := user.NewUnitOfWork(srv.db, srv.rdb)
+	repo := user.NewRepository(uow)
+
+	usr, err := byUsername(username)(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	if err := usr.RemoveCredential(id); err != nil {
+		return err
+	}
+
+	if err := repo.Update(usr); err != nil {
+		return errors.Wrap(err, "failed to update user in repository")
+	}
+
+	return uow.Flush(ctx)
+}
+
+// consumeSession finds ceremony session by challenge signed in client data, every session can be used only once
+func (srv *WebauthnService) consumeSession(ctx context.Context, clientDataJson string) (*webauthn.Session, error) {
+	challenge, err := webauthn.ChallengeFromClientData(clientDataJson)
+	if err != nil {
+		return nil, errors.Wrap(WebauthnSessionInvalidErr, err.Error())
+	}
+
+	sessionDto, err := webauthn.NewSessionDao(srv.rdb).Consume(ctx, challenge)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read webauthn session")
+	}
+
+	if !sessionDto.IsPresent() {
+		return nil, WebauthnSessionInvalidErr
+	}
+	return sessionDto.ToSession(), nil
+}
+
+func credentialDescriptors(usr *user.User) []webauthn.CredentialDescriptorDto {
+	return helpers.Map(usr.Credentials(), func(cred *webauthn.Credential, _ int, _ []*webauthn.Credential) webauthn.CredentialDescriptorDto {
+		return cred.Descriptor()
+	})
+}
@@ -0,0 +1 @@
+ALTER TABLE USERS ALTER COLUMN PASSWORD_HASH TYPE VARCHAR(128);
\ No newline at end of file
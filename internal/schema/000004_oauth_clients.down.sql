@@ -0,0 +1,3 @@
+DROP TABLE OAUTH_CLIENT_REDIRECT_URIS;
+
+DROP TABLE OAUTH_CLIENTS;
\ No newline at end of file
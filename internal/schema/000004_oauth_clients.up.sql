@@ -0,0 +1,12 @@
+CREATE TABLE OAUTH_CLIENTS(
+    ID UUID DEFAULT uuid_generate_v4(),
+    NAME VARCHAR(200) NOT NULL UNIQUE,
+    PRIMARY KEY(ID)
+);
+
+CREATE TABLE OAUTH_CLIENT_REDIRECT_URIS(
+    CLIENT_ID UUID NOT NULL,
+    URI VARCHAR(2000) NOT NULL,
+    PRIMARY KEY(CLIENT_ID, URI),
+    CONSTRAINT FK_CLIENT FOREIGN KEY(CLIENT_ID) REFERENCES OAUTH_CLIENTS(ID)
+);
\ No newline at end of file
@@ -0,0 +1,5 @@
+DROP TABLE OAUTH_CLIENT_SCOPES;
+
+DROP TABLE OAUTH_CLIENT_GRANT_TYPES;
+
+ALTER TABLE OAUTH_CLIENTS DROP COLUMN SECRET_HASH;
\ No newline at end of file
@@ -0,0 +1,21 @@
+ALTER TABLE OAUTH_CLIENTS ADD COLUMN SECRET_HASH VARCHAR(255);
+
+CREATE TABLE OAUTH_CLIENT_GRANT_TYPES(
+    CLIENT_ID UUID NOT NULL,
+    GRANT_TYPE VARCHAR(50) NOT NULL,
+    PRIMARY KEY(CLIENT_ID, GRANT_TYPE),
+    CONSTRAINT FK_CLIENT FOREIGN KEY(CLIENT_ID) REFERENCES OAUTH_CLIENTS(ID)
+);
+
+CREATE TABLE OAUTH_CLIENT_SCOPES(
+    CLIENT_ID UUID NOT NULL,
+    SCOPE_ID UUID NOT NULL,
+    PRIMARY KEY(CLIENT_ID, SCOPE_ID),
+    CONSTRAINT FK_CLIENT FOREIGN KEY(CLIENT_ID) REFERENCES OAUTH_CLIENTS(ID),
+    CONSTRAINT FK_SCOPE FOREIGN KEY(SCOPE_ID) REFERENCES SCOPES(ID)
+);
+
+INSERT INTO OAUTH_CLIENT_GRANT_TYPES(CLIENT_ID, GRANT_TYPE)
+SELECT ID, 'authorization_code' FROM OAUTH_CLIENTS
+UNION ALL
+SELECT ID, 'refresh_token' FROM OAUTH_CLIENTS;
\ No newline at end of file
@@ -0,0 +1,5 @@
+DROP TABLE USER_RECOVERY_CODES;
+
+ALTER TABLE USERS DROP COLUMN TOTP_LAST_STEP;
+ALTER TABLE USERS DROP COLUMN TOTP_SECRET;
+ALTER TABLE USERS DROP COLUMN MFA_ENABLED;
\ No newline at end of file
@@ -0,0 +1,11 @@
+ALTER TABLE USERS ADD COLUMN MFA_ENABLED BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE USERS ADD COLUMN TOTP_SECRET VARCHAR(64);
+ALTER TABLE USERS ADD COLUMN TOTP_LAST_STEP BIGINT NOT NULL DEFAULT 0;
+
+CREATE TABLE USER_RECOVERY_CODES(
+    ID UUID NOT NULL,
+    USER_ID UUID NOT NULL,
+    CODE_HASH VARCHAR(255) NOT NULL,
+    PRIMARY KEY(ID),
+    CONSTRAINT FK_USER FOREIGN KEY(USER_ID) REFERENCES USERS(ID)
+);
\ No newline at end of file
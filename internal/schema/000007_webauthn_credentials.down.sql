@@ -0,0 +1 @@
+DROP TABLE USER_WEBAUTHN_CREDENTIALS;
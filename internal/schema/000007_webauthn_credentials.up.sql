@@ -0,0 +1,16 @@
+CREATE TABLE USER_WEBAUTHN_CREDENTIALS(
+    ID VARCHAR(1400) NOT NULL,
+    USER_ID UUID NOT NULL,
+    NAME VARCHAR(64) NOT NULL,
+    PUBLIC_KEY BYTEA NOT NULL,
+    SIGN_COUNT BIGINT NOT NULL DEFAULT 0,
+    AAGUID BYTEA,
+    ATTESTATION_FORMAT VARCHAR(32) NOT NULL,
+    TRANSPORTS VARCHAR(255) NOT NULL DEFAULT '',
+    CREATED_AT TIMESTAMP WITH TIME ZONE NOT NULL,
+    LAST_USED_AT TIMESTAMP WITH TIME ZONE,
+    PRIMARY KEY(ID),
+    CONSTRAINT FK_USER FOREIGN KEY(USER_ID) REFERENCES USERS(ID)
+);
+
+CREATE INDEX USER_WEBAUTHN_CREDENTIALS_USER_ID_IDX ON USER_WEBAUTHN_CREDENTIALS(USER_ID);
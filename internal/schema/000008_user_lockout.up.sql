@@ -0,0 +1 @@
+ALTER TABLE USERS ADD COLUMN LOCKED_UNTIL TIMESTAMP WITH TIME ZONE;
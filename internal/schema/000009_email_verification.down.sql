@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS USERS_EMAIL_NORMALIZED_UNIQUE;
+ALTER TABLE USERS DROP COLUMN EMAIL_VERIFIED_AT;
@@ -0,0 +1,5 @@
+ALTER TABLE USERS ADD COLUMN EMAIL_VERIFIED_AT TIMESTAMP WITH TIME ZONE;
+
+-- only verified email reserves address, so nobody can block owner by signing up with its email first.
+-- Column is added right above, so no email is verified yet and existing duplicates can't conflict.
+CREATE UNIQUE INDEX USERS_EMAIL_NORMALIZED_UNIQUE ON USERS (LOWER(TRIM(EMAIL))) WHERE EMAIL_VERIFIED_AT IS NOT NULL;
@@ -0,0 +1 @@
+ALTER TABLE USERS ADD COLUMN DISABLED_AT TIMESTAMP WITH TIME ZONE;
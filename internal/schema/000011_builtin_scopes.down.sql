@@ -0,0 +1,25 @@
+DELETE FROM USER_ROLES WHERE ROLE_ID IN (SELECT ID FROM ROLES WHERE NAME = 'authsrv:admin');
+
+DELETE FROM ROLES_SCOPES
+ WHERE ROLE_ID IN (SELECT ID FROM ROLES WHERE NAME = 'authsrv:admin')
+    OR SCOPE_ID IN (SELECT ID FROM SCOPES WHERE NAME IN (
+        'authsrv:users:read',
+        'authsrv:users:manage',
+        'authsrv:sessions:manage',
+        'authsrv:roles:read',
+        'authsrv:roles:write',
+        'authsrv:scopes:read',
+        'authsrv:scopes:write'
+    ));
+
+DELETE FROM ROLES WHERE NAME = 'authsrv:admin';
+
+DELETE FROM SCOPES WHERE NAME IN (
+    'authsrv:users:read',
+    'authsrv:users:manage',
+    'authsrv:sessions:manage',
+    'authsrv:roles:read',
+    'authsrv:roles:write',
+    'authsrv:scopes:read',
+    'authsrv:scopes:write'
+);
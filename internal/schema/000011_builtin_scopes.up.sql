@@ -0,0 +1,28 @@
+INSERT INTO SCOPES(NAME, DESCRIPTION) VALUES
+    ('authsrv:users:read', 'Read users'),
+    ('authsrv:users:manage', 'Update, disable, unlock and delete users, assign roles to users'),
+    ('authsrv:sessions:manage', 'Read and revoke sessions of any user'),
+    ('authsrv:roles:read', 'Read roles'),
+    ('authsrv:roles:write', 'Create, update and delete roles, assign scopes to roles'),
+    ('authsrv:scopes:read', 'Read scopes'),
+    ('authsrv:scopes:write', 'Create, update and delete scopes')
+ON CONFLICT (NAME) DO NOTHING;
+
+INSERT INTO ROLES(NAME, DESCRIPTION) VALUES ('authsrv:admin', 'Administrator of authsrv')
+ON CONFLICT (NAME) DO NOTHING;
+
+INSERT INTO ROLES_SCOPES(ROLE_ID, SCOPE_ID)
+SELECT R.ID, S.ID
+  FROM ROLES AS R
+ CROSS JOIN SCOPES AS S
+ WHERE R.NAME = 'authsrv:admin'
+   AND S.NAME IN (
+       'authsrv:users:read',
+       'authsrv:users:manage',
+       'authsrv:sessions:manage',
+       'authsrv:roles:read',
+       'authsrv:roles:write',
+       'authsrv:scopes:read',
+       'authsrv:scopes:write'
+   )
+ON CONFLICT DO NOTHING;
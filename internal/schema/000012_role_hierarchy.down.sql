@@ -0,0 +1,13 @@
+DROP VIEW USER_AUTH;
+
+CREATE VIEW USER_AUTH
+    AS SELECT URD.USER_ID AS USER_ID,
+              URD.ROLE_ID AS ROLE_ID,
+              URD.ROLE_NAME AS ROLE_NAME,
+              RSD.SCOPE_ID AS SCOPE_ID,
+              RSD.SCOPE_NAME AS SCOPE_NAME
+    FROM USERS_ROLES_DETAILS AS URD
+    LEFT JOIN ROLES_SCOPES_DETAILS AS RSD ON URD.ROLE_ID = RSD.ROLE_ID;
+
+DROP VIEW ROLES_ANCESTORS;
+DROP TABLE ROLES_PARENTS;
@@ -0,0 +1,31 @@
+CREATE TABLE ROLES_PARENTS(
+    ROLE_ID UUID NOT NULL,
+    PARENT_ID UUID NOT NULL,
+    PRIMARY KEY(ROLE_ID, PARENT_ID),
+    CONSTRAINT FK_ROLE FOREIGN KEY(ROLE_ID) REFERENCES ROLES(ID),
+    CONSTRAINT FK_PARENT FOREIGN KEY(PARENT_ID) REFERENCES ROLES(ID),
+    CONSTRAINT CHK_NOT_SELF_PARENT CHECK(ROLE_ID <> PARENT_ID)
+);
+
+-- every role is its own ancestor, UNION stops recursion even if cycle slipped into hierarchy
+CREATE VIEW ROLES_ANCESTORS
+    AS WITH RECURSIVE ANCESTORS(ROLE_ID, ANCESTOR_ID) AS (
+        SELECT ID, ID FROM ROLES
+        UNION
+        SELECT A.ROLE_ID, RP.PARENT_ID
+        FROM ANCESTORS AS A
+        INNER JOIN ROLES_PARENTS AS RP ON RP.ROLE_ID = A.ANCESTOR_ID
+    )
+    SELECT ROLE_ID, ANCESTOR_ID FROM ANCESTORS;
+
+DROP VIEW USER_AUTH;
+
+CREATE VIEW USER_AUTH
+    AS SELECT URD.USER_ID AS USER_ID,
+              URD.ROLE_ID AS ROLE_ID,
+              URD.ROLE_NAME AS ROLE_NAME,
+              RSD.SCOPE_ID AS SCOPE_ID,
+              RSD.SCOPE_NAME AS SCOPE_NAME
+    FROM USERS_ROLES_DETAILS AS URD
+    INNER JOIN ROLES_ANCESTORS AS RA ON RA.ROLE_ID = URD.ROLE_ID
+    LEFT JOIN ROLES_SCOPES_DETAILS AS RSD ON RSD.ROLE_ID = RA.ANCESTOR_ID;
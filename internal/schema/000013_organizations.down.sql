@@ -0,0 +1,9 @@
+DELETE FROM ROLES_SCOPES
+ WHERE SCOPE_ID IN (SELECT ID FROM SCOPES WHERE NAME IN ('authsrv:organizations:read', 'authsrv:organizations:write'));
+
+DELETE FROM SCOPES WHERE NAME IN ('authsrv:organizations:read', 'authsrv:organizations:write');
+
+DROP VIEW ORGANIZATION_USER_AUTH;
+DROP TABLE ORGANIZATION_USER_ROLES;
+DROP TABLE ORGANIZATION_MEMBERS;
+DROP TABLE ORGANIZATIONS;
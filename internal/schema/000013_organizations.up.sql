@@ -0,0 +1,53 @@
+CREATE TABLE ORGANIZATIONS(
+    ID UUID DEFAULT uuid_generate_v4(),
+    NAME VARCHAR(200) NOT NULL UNIQUE,
+    DESCRIPTION VARCHAR(500),
+    PRIMARY KEY(ID)
+);
+
+CREATE TABLE ORGANIZATION_MEMBERS(
+    ORGANIZATION_ID UUID NOT NULL,
+    USER_ID UUID NOT NULL,
+    PRIMARY KEY(ORGANIZATION_ID, USER_ID),
+    CONSTRAINT FK_ORGANIZATION FOREIGN KEY(ORGANIZATION_ID) REFERENCES ORGANIZATIONS(ID),
+    CONSTRAINT FK_USER FOREIGN KEY(USER_ID) REFERENCES USERS(ID)
+);
+
+-- roles are defined globally, but assigned to user within organization
+CREATE TABLE ORGANIZATION_USER_ROLES(
+    ORGANIZATION_ID UUID NOT NULL,
+    USER_ID UUID NOT NULL,
+    ROLE_ID UUID NOT NULL,
+    PRIMARY KEY(ORGANIZATION_ID, USER_ID, ROLE_ID),
+    CONSTRAINT FK_MEMBER FOREIGN KEY(ORGANIZATION_ID, USER_ID) REFERENCES ORGANIZATION_MEMBERS(ORGANIZATION_ID, USER_ID),
+    CONSTRAINT FK_ROLE FOREIGN KEY(ROLE_ID) REFERENCES ROLES(ID)
+);
+
+-- member without roles has single row without role and scope columns
+CREATE VIEW ORGANIZATION_USER_AUTH
+    AS SELECT OM.ORGANIZATION_ID AS ORGANIZATION_ID,
+              ORG.NAME AS ORGANIZATION_NAME,
+              OM.USER_ID AS USER_ID,
+              RL.ID AS ROLE_ID,
+              RL.NAME AS ROLE_NAME,
+              RSD.SCOPE_ID AS SCOPE_ID,
+              RSD.SCOPE_NAME AS SCOPE_NAME
+    FROM ORGANIZATION_MEMBERS AS OM
+    INNER JOIN ORGANIZATIONS AS ORG ON ORG.ID = OM.ORGANIZATION_ID
+    LEFT JOIN ORGANIZATION_USER_ROLES AS OUR ON OUR.ORGANIZATION_ID = OM.ORGANIZATION_ID AND OUR.USER_ID = OM.USER_ID
+    LEFT JOIN ROLES AS RL ON RL.ID = OUR.ROLE_ID
+    LEFT JOIN ROLES_ANCESTORS AS RA ON RA.ROLE_ID = OUR.ROLE_ID
+    LEFT JOIN ROLES_SCOPES_DETAILS AS RSD ON RSD.ROLE_ID = RA.ANCESTOR_ID;
+
+INSERT INTO SCOPES(NAME, DESCRIPTION) VALUES
+    ('authsrv:organizations:read', 'Read organizations and their members'),
+    ('authsrv:organizations:write', 'Create and delete organizations, manage members and their roles')
+ON CONFLICT (NAME) DO NOTHING;
+
+INSERT INTO ROLES_SCOPES(ROLE_ID, SCOPE_ID)
+SELECT R.ID, S.ID
+  FROM ROLES AS R
+ CROSS JOIN SCOPES AS S
+ WHERE R.NAME = 'authsrv:admin'
+   AND S.NAME IN ('authsrv:organizations:read', 'authsrv:organizations:write')
+ON CONFLICT DO NOTHING;
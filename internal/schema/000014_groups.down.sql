@@ -0,0 +1,20 @@
+DELETE FROM ROLES_SCOPES
+ WHERE SCOPE_ID IN (SELECT ID FROM SCOPES WHERE NAME IN ('authsrv:groups:read', 'authsrv:groups:write'));
+
+DELETE FROM SCOPES WHERE NAME IN ('authsrv:groups:read', 'authsrv:groups:write');
+
+DROP VIEW USER_AUTH;
+
+CREATE VIEW USER_AUTH
+    AS SELECT URD.USER_ID AS USER_ID,
+              URD.ROLE_ID AS ROLE_ID,
+              URD.ROLE_NAME AS ROLE_NAME,
+              RSD.SCOPE_ID AS SCOPE_ID,
+              RSD.SCOPE_NAME AS SCOPE_NAME
+    FROM USERS_ROLES_DETAILS AS URD
+    INNER JOIN ROLES_ANCESTORS AS RA ON RA.ROLE_ID = URD.ROLE_ID
+    LEFT JOIN ROLES_SCOPES_DETAILS AS RSD ON RSD.ROLE_ID = RA.ANCESTOR_ID;
+
+DROP TABLE GROUPS_ROLES;
+DROP TABLE GROUPS_MEMBERS;
+DROP TABLE GROUPS;
@@ -0,0 +1,57 @@
+CREATE TABLE GROUPS(
+    ID UUID DEFAULT uuid_generate_v4(),
+    NAME VARCHAR(200) NOT NULL UNIQUE,
+    DESCRIPTION VARCHAR(500),
+    PRIMARY KEY(ID)
+);
+
+CREATE TABLE GROUPS_MEMBERS(
+    GROUP_ID UUID NOT NULL,
+    USER_ID UUID NOT NULL,
+    PRIMARY KEY(GROUP_ID, USER_ID),
+    CONSTRAINT FK_GROUP FOREIGN KEY(GROUP_ID) REFERENCES GROUPS(ID),
+    CONSTRAINT FK_USER FOREIGN KEY(USER_ID) REFERENCES USERS(ID)
+);
+
+CREATE TABLE GROUPS_ROLES(
+    GROUP_ID UUID NOT NULL,
+    ROLE_ID UUID NOT NULL,
+    PRIMARY KEY(GROUP_ID, ROLE_ID),
+    CONSTRAINT FK_GROUP FOREIGN KEY(GROUP_ID) REFERENCES GROUPS(ID),
+    CONSTRAINT FK_ROLE FOREIGN KEY(ROLE_ID) REFERENCES ROLES(ID)
+);
+
+DROP VIEW USER_AUTH;
+
+-- roles are assigned to user directly or through group, GROUP_ID is empty for direct assignments
+CREATE VIEW USER_AUTH
+    AS SELECT UR.USER_ID AS USER_ID,
+              UR.ROLE_ID AS ROLE_ID,
+              UR.ROLE_NAME AS ROLE_NAME,
+              UR.GROUP_ID AS GROUP_ID,
+              RSD.SCOPE_ID AS SCOPE_ID,
+              RSD.SCOPE_NAME AS SCOPE_NAME
+    FROM (
+        SELECT URD.USER_ID, URD.ROLE_ID, URD.ROLE_NAME, NULL::UUID AS GROUP_ID
+        FROM USERS_ROLES_DETAILS AS URD
+        UNION ALL
+        SELECT GM.USER_ID, RL.ID, RL.NAME, GM.GROUP_ID
+        FROM GROUPS_MEMBERS AS GM
+        INNER JOIN GROUPS_ROLES AS GR ON GR.GROUP_ID = GM.GROUP_ID
+        INNER JOIN ROLES AS RL ON RL.ID = GR.ROLE_ID
+    ) AS UR
+    INNER JOIN ROLES_ANCESTORS AS RA ON RA.ROLE_ID = UR.ROLE_ID
+    LEFT JOIN ROLES_SCOPES_DETAILS AS RSD ON RSD.ROLE_ID = RA.ANCESTOR_ID;
+
+INSERT INTO SCOPES(NAME, DESCRIPTION) VALUES
+    ('authsrv:groups:read', 'Read groups, their members and roles'),
+    ('authsrv:groups:write', 'Create and delete groups, manage their members and roles')
+ON CONFLICT (NAME) DO NOTHING;
+
+INSERT INTO ROLES_SCOPES(ROLE_ID, SCOPE_ID)
+SELECT R.ID, S.ID
+  FROM ROLES AS R
+ CROSS JOIN SCOPES AS S
+ WHERE R.NAME = 'authsrv:admin'
+   AND S.NAME IN ('authsrv:groups:read', 'authsrv:groups:write')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,33 @@
+DROP VIEW USER_AUTH;
+DROP VIEW USERS_ROLES_DETAILS;
+
+CREATE VIEW USERS_ROLES_DETAILS
+    AS SELECT UR.USER_ID AS USER_ID,
+              RL.ID      AS ROLE_ID,
+              RL.NAME    AS ROLE_NAME
+    FROM USER_ROLES AS UR
+    INNER JOIN ROLES AS RL ON UR.ROLE_ID = RL.ID;
+
+CREATE VIEW USER_AUTH
+    AS SELECT UR.USER_ID AS USER_ID,
+              UR.ROLE_ID AS ROLE_ID,
+              UR.ROLE_NAME AS ROLE_NAME,
+              UR.GROUP_ID AS GROUP_ID,
+              RSD.SCOPE_ID AS SCOPE_ID,
+              RSD.SCOPE_NAME AS SCOPE_NAME
+    FROM (
+        SELECT URD.USER_ID, URD.ROLE_ID, URD.ROLE_NAME, NULL::UUID AS GROUP_ID
+        FROM USERS_ROLES_DETAILS AS URD
+        UNION ALL
+        SELECT GM.USER_ID, RL.ID, RL.NAME, GM.GROUP_ID
+        FROM GROUPS_MEMBERS AS GM
+        INNER JOIN GROUPS_ROLES AS GR ON GR.GROUP_ID = GM.GROUP_ID
+        INNER JOIN ROLES AS RL ON RL.ID = GR.ROLE_ID
+    ) AS UR
+    INNER JOIN ROLES_ANCESTORS AS RA ON RA.ROLE_ID = UR.ROLE_ID
+    LEFT JOIN ROLES_SCOPES_DETAILS AS RSD ON RSD.ROLE_ID = RA.ANCESTOR_ID;
+
+DROP INDEX IDX_USER_ROLES_VALID_UNTIL;
+ALTER TABLE USER_ROLES DROP CONSTRAINT CHK_VALIDITY;
+ALTER TABLE USER_ROLES DROP COLUMN VALID_UNTIL;
+ALTER TABLE USER_ROLES DROP COLUMN VALID_FROM;
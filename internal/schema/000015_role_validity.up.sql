@@ -0,0 +1,39 @@
+ALTER TABLE USER_ROLES ADD COLUMN VALID_FROM TIMESTAMP WITH TIME ZONE;
+ALTER TABLE USER_ROLES ADD COLUMN VALID_UNTIL TIMESTAMP WITH TIME ZONE;
+ALTER TABLE USER_ROLES ADD CONSTRAINT CHK_VALIDITY CHECK (VALID_FROM IS NULL OR VALID_UNTIL IS NULL OR VALID_UNTIL > VALID_FROM);
+
+-- expired assignments are looked up by sweeper
+CREATE INDEX IDX_USER_ROLES_VALID_UNTIL ON USER_ROLES(VALID_UNTIL) WHERE VALID_UNTIL IS NOT NULL;
+
+DROP VIEW USER_AUTH;
+
+CREATE OR REPLACE VIEW USERS_ROLES_DETAILS
+    AS SELECT UR.USER_ID     AS USER_ID,
+              RL.ID          AS ROLE_ID,
+              RL.NAME        AS ROLE_NAME,
+              UR.VALID_FROM  AS VALID_FROM,
+              UR.VALID_UNTIL AS VALID_UNTIL
+    FROM USER_ROLES AS UR
+    INNER JOIN ROLES AS RL ON UR.ROLE_ID = RL.ID;
+
+-- validity is set for direct assignments only, roles of groups are effective while user is member
+CREATE VIEW USER_AUTH
+    AS SELECT UR.USER_ID AS USER_ID,
+              UR.ROLE_ID AS ROLE_ID,
+              UR.ROLE_NAME AS ROLE_NAME,
+              UR.GROUP_ID AS GROUP_ID,
+              RSD.SCOPE_ID AS SCOPE_ID,
+              RSD.SCOPE_NAME AS SCOPE_NAME,
+              UR.VALID_FROM AS VALID_FROM,
+              UR.VALID_UNTIL AS VALID_UNTIL
+    FROM (
+        SELECT URD.USER_ID, URD.ROLE_ID, URD.ROLE_NAME, NULL::UUID AS GROUP_ID, URD.VALID_FROM, URD.VALID_UNTIL
+        FROM USERS_ROLES_DETAILS AS URD
+        UNION ALL
+        SELECT GM.USER_ID, RL.ID, RL.NAME, GM.GROUP_ID, NULL::TIMESTAMP WITH TIME ZONE, NULL::TIMESTAMP WITH TIME ZONE
+        FROM GROUPS_MEMBERS AS GM
+        INNER JOIN GROUPS_ROLES AS GR ON GR.GROUP_ID = GM.GROUP_ID
+        INNER JOIN ROLES AS RL ON RL.ID = GR.ROLE_ID
+    ) AS UR
+    INNER JOIN ROLES_ANCESTORS AS RA ON RA.ROLE_ID = UR.ROLE_ID
+    LEFT JOIN ROLES_SCOPES_DETAILS AS RSD ON RSD.ROLE_ID = RA.ANCESTOR_ID;
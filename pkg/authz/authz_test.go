@@ -0,0 +1,129 @@
+package authz
+
+import (
+	"testing"
+)
+
+const (
+	success = "\u2713"
+	failed  = "\u2717"
+)
+
+func TestImplies(t *testing.T) {
+	cases := []struct {
+		granted  string
+		required string
+		implied  bool
+	}{
+		{"orders:read", "orders:read", true},
+		{"orders:read", "orders:write", false},
+		{"orders:*", "orders:read", true},
+		{"orders:*", "orders:items:read", true},
+		{"orders:*", "orders", false},
+		{"orders:*", "billing:read", false},
+		{"*:read", "orders:read", true},
+		{"*:read", "orders:write", false},
+		{"*", "orders:read", true},
+		{"orders", "orders:read", false},
+		{"orders:read", "orders:*", false},
+		{"orders:*", "orders:*", true},
+	}
+
+	t.Log("Given the need to test scope implication")
+	{
+		for i, c := range cases {
+			t.Logf("\tTest %d:\tWhen %s is checked against %s", i+1, c.granted, c.required)
+			{
+				if Implies(c.granted, c.required) != c.implied {
+					t.Fatalf("\t%s\tImplication must be %t", failed, c.implied)
+				}
+				t.Logf("\t%s\tImplication must be %t", success, c.implied)
+			}
+		}
+	}
+}
+
+func TestValidateScope(t *testing.T) {
+	t.Log("Given the need to test scope grammar")
+	{
+		t.Logf("\tTest 1:\tWhen valid scopes are validated")
+		{
+			for _, scope := range []string{"admin", "orders:read", "orders:*", "*:read"} {
+				if err := ValidateScope(scope); err != nil {
+					t.Fatalf("\t%s\tScope %s must be valid, got %v", failed, scope, err)
+				}
+			}
+			t.Logf("\t%s\tScopes must be valid", success)
+		}
+
+		t.Logf("\tTest 2:\tWhen invalid scopes are validated")
+		{
+			for _, scope := range []string{"", "orders:", ":read", "orders::read", "orders:re*d", "orders read"} {
+				if err := ValidateScope(scope); err == nil {
+					t.Fatalf("\t%s\tScope %q must be rejected", failed, scope)
+				}
+			}
+			t.Logf("\t%s\tScopes must be rejected", success)
+		}
+	}
+}
+
+func TestRequirement(t *testing.T) {
+	req := MustCompile("orders:read && (billing:read || admin)")
+
+	cases := []struct {
+		granted   []string
+		satisfied bool
+	}{
+		{[]string{"orders:read", "billing:read"}, true},
+		{[]string{"orders:read", "admin"}, true},
+		{[]string{"orders:*", "billing:*"}, true},
+		{[]string{"orders:read"}, false},
+		{[]string{"billing:read", "admin"}, false},
+		{nil, false},
+	}
+
+	t.Log("Given the need to test scope expressions")
+	{
+		for i, c := range cases {
+			t.Logf("\tTest %d:\tWhen %s is checked against %v", i+1, req, c.granted)
+			{
+				if req.Satisfied(c.granted) != c.satisfied {
+					t.Fatalf("\t%s\tRequirement must be satisfied: %t", failed, c.satisfied)
+				}
+				t.Logf("\t%s\tRequirement must be satisfied: %t", success, c.satisfied)
+			}
+		}
+
+		t.Logf("\tTest %d:\tWhen && and || are mixed without parentheses", len(cases)+1)
+		{
+			mixed := MustCompile("admin || orders:read && billing:read")
+			if !mixed.Satisfied([]string{"admin"}) || mixed.Satisfied([]string{"orders:read"}) {
+				t.Fatalf("\t%s\t&& must bind tighter than ||", failed)
+			}
+			t.Logf("\t%s\t&& must bind tighter than ||", success)
+		}
+
+		t.Logf("\tTest %d:\tWhen malformed expressions are compiled", len(cases)+2)
+		{
+			for _, expr := range []string{"", "orders:read &&", "(orders:read", "orders:read)", "orders:read & admin", "|| admin", "orders:read admin"} {
+				if _, err := Compile(expr); err == nil {
+					t.Fatalf("\t%s\tExpression %q must be rejected", failed, expr)
+				}
+			}
+			t.Logf("\t%s\tMalformed expressions must be rejected", success)
+		}
+
+		t.Logf("\tTest %d:\tWhen requirement is built from scope lists", len(cases)+3)
+		{
+			if !AnyOf("orders:read", "admin").Satisfied([]string{"admin"}) || AllOf("orders:read", "admin").Satisfied([]string{"admin"}) {
+				t.Fatalf("\t%s\tAnyOf and AllOf must combine scopes", failed)
+			}
+
+			if !AllOf().Satisfied(nil) {
+				t.Fatalf("\t%s\tEmpty requirement must be satisfied", failed)
+			}
+			t.Logf("\t%s\tAnyOf and AllOf must combine scopes", success)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package authz
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+type node interface {
+	eval(granted []string) bool
+}
+
+type scopeNode string
+
+func (n scopeNode) eval(granted []string) bool {
+	return Granted(granted, string(n))
+}
+
+type andNode struct {
+	left, right node
+}
+
+func (n andNode) eval(granted []string) bool {
+	return n.left.eval(granted) && n.right.eval(granted)
+}
+
+type orNode struct {
+	left, right node
+}
+
+func (n orNode) eval(granted []string) bool {
+	return n.left.eval(granted) || n.right.eval(granted)
+}
+
+// Requirement is compiled boolean expression over scopes, e.g. orders:read && (billing:read || admin).
+// Operator && binds tighter than ||, parentheses group subexpressions.
+type Requirement struct {
+	expr string
+	root node
+}
+
+func Compile(expr string) (Requirement, error) {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return Requirement{}, err
+	}
+
+	if len(tokens) == 0 {
+		return Requirement{}, errors.New("scope expression can't be empty")
+	}
+
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return Requirement{}, errors.Wrapf(err, "invalid scope expression %q", expr)
+	}
+
+	if !p.done() {
+		return Requirement{}, errors.Errorf("invalid scope expression %q: unexpected %s", expr, p.peek())
+	}
+
+	return Requirement{expr: expr, root: root}, nil
+}
+
+// MustCompile is like Compile, but panics on invalid expression, it is meant for route definitions
+func MustCompile(expr string) Requirement {
+	req, err := Compile(expr)
+	if err != nil {
+		panic(err)
+	}
+	return req
+}
+
+// AllOf requires every scope, it is equivalent of scopes joined with &&
+func AllOf(scopes ...string) Requirement {
+	if len(scopes) == 0 {
+		return Requirement{}
+	}
+	return MustCompile(strings.Join(scopes, " && "))
+}
+
+// AnyOf requires at least one scope, it is equivalent of scopes joined with ||
+func AnyOf(scopes ...string) Requirement {
+	if len(scopes) == 0 {
+		return Requirement{}
+	}
+	return MustCompile(strings.Join(scopes, " || "))
+}
+
+// Satisfied reports if granted scopes fulfil requirement, zero requirement is always satisfied
+func (req Requirement) Satisfied(granted []string) bool {
+	if req.root == nil {
+		return true
+	}
+	return req.root.eval(granted)
+}
+
+func (req Requirement) String() string {
+	return req.expr
+}
+
+const (
+	tokenAnd    = "&&"
+	tokenOr     = "||"
+	tokenLParen = "("
+	tokenRParen = ")"
+)
+
+func tokenize(expr string) ([]string, error) {
+	tokens := make([]string, 0)
+	for i := 0; i < len(expr); {
+		switch c := expr[i]; {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			i++
+		case c == '(' || c == ')':
+			tokens = append(tokens, string(c))
+			i++
+		case c == '&' || c == '|':
+			if i+1 >= len(expr) || expr[i+1] != c {
+				return nil, errors.Errorf("invalid scope expression %q: expected %c%c at position %d", expr, c, c, i)
+			}
+			tokens = append(tokens, expr[i:i+2])
+			i += 2
+		default:
+			start := i
+			for i < len(expr) && !strings.ContainsRune(" \t\r\n()&|", rune(expr[i])) {
+				i++
+			}
+
+			scope := expr[start:i]
+			if err := ValidateScope(scope); err != nil {
+				return nil, errors.Wrapf(err, "invalid scope expression %q", expr)
+			}
+			tokens = append(tokens, scope)
+		}
+	}
+	return tokens, nil
+}
+
+type parser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *parser) peek() string {
+	if p.done() {
+		return "end of expression"
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek() == tokenOr {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek() == tokenAnd {
+		p.pos++
+		right, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	if p.done() {
+		return nil, errors.New("unexpected end of expression")
+	}
+
+	token := p.tokens[p.pos]
+	switch token {
+	case tokenLParen:
+		p.pos++
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if p.peek() != tokenRParen {
+			return nil, errors.Errorf("expected ) but got %s", p.peek())
+		}
+		p.pos++
+		return inner, nil
+	case tokenRParen, tokenAnd, tokenOr:
+		return nil, errors.Errorf("unexpected %s", token)
+	}
+
+	p.pos++
+	return scopeNode(token), nil
+}
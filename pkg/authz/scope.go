@@ -0,0 +1,77 @@
+package authz
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	SegmentSeparator = ":"
+	Wildcard         = "*"
+)
+
+// ValidateScope checks scope grammar: non-empty segments separated by colon, wildcard must be a whole segment
+func ValidateScope(scope string) error {
+	if scope == "" {
+		return errors.New("scope can't be empty")
+	}
+
+	for _, segment := range strings.Split(scope, SegmentSeparator) {
+		if segment == "" {
+			return errors.Errorf("scope %s has empty segment", scope)
+		}
+
+		if segment != Wildcard && strings.Contains(segment, Wildcard) {
+			return errors.Errorf("scope %s has wildcard mixed with other characters in segment", scope)
+		}
+
+		if strings.ContainsAny(segment, " \t\r\n()&|") {
+			return errors.Errorf("scope %s has whitespace or reserved characters", scope)
+		}
+	}
+	return nil
+}
+
+// Implies reports if granted scope grants required one. Wildcard segment matches any single segment and trailing
+// wildcard matches any number of remaining segments, so orders:* grants orders:read and orders:items:read.
+func Implies(granted string, required string) bool {
+	grantedSegments := strings.Split(granted, SegmentSeparator)
+	requiredSegments := strings.Split(required, SegmentSeparator)
+
+	for i, segment := range grantedSegments {
+		if segment == Wildcard && i == len(grantedSegments)-1 {
+			return len(requiredSegments) >= len(grantedSegments)
+		}
+
+		if i >= len(requiredSegments) {
+			return false
+		}
+
+		if segment != Wildcard && segment != requiredSegments[i] {
+			return false
+		}
+	}
+	return len(grantedSegments) == len(requiredSegments)
+}
+
+// Granted reports if required scope is implied by any of granted scopes
+func Granted(granted []string, required string) bool {
+	for _, scope := range granted {
+		if Implies(scope, required) {
+			return true
+		}
+	}
+	return false
+}
+
+// Missing returns required scopes which aren't implied by any of granted scopes
+func Missing(required []string, granted []string) []string {
+	missing := make([]string, 0)
+	for _, scope := range required {
+		if !Granted(granted, scope) {
+			missing = append(missing, scope)
+		}
+	}
+	return missing
+}
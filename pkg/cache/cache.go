@@ -0,0 +1,74 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+type entry[V any] struct {
+	value     V
+	expiresAt time.Time
+}
+
+// Cache is size-limited in-memory cache with per entry expiration, it is safe for concurrent use
+type Cache[K comparable, V any] struct {
+	mu      sync.Mutex
+	entries map[K]entry[V]
+	maxSize int
+}
+
+func New[K comparable, V any](maxSize int) *Cache[K, V] {
+	return &Cache[K, V]{
+		entries: make(map[K]entry[V]),
+		maxSize: maxSize,
+	}
+}
+
+func (c *Cache[K, V]) Get(key K, now time.Time) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok || !e.expiresAt.After(now) {
+		var zero V
+		return zero, false
+	}
+	return e.value, true
+}
+
+// Set stores value until expiration, if cache is full expired entries are evicted first and random entry otherwise
+func (c *Cache[K, V]) Set(key K, value V, expiresAt time.Time, now time.Time) {
+	if c.maxSize <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.maxSize {
+		c.evict(now)
+	}
+	c.entries[key] = entry[V]{value: value, expiresAt: expiresAt}
+}
+
+func (c *Cache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+func (c *Cache[K, V]) evict(now time.Time) {
+	for key, e := range c.entries {
+		if !e.expiresAt.After(now) {
+			delete(c.entries, key)
+		}
+	}
+
+	// map iteration order is random, so arbitrary entry is dropped
+	for key := range c.entries {
+		if len(c.entries) < c.maxSize {
+			break
+		}
+		delete(c.entries, key)
+	}
+}
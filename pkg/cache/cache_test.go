@@ -0,0 +1,65 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+const (
+	success = "\u2713"
+	failed  = "\u2717"
+)
+
+func TestCache(t *testing.T) {
+	now := time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Log("Given the need to test cache with expiration")
+	{
+		t.Logf("\tTest 1:\tWhen entry is read before and after expiration")
+		{
+			c := New[string, bool](10)
+			c.Set("jti", true, now.Add(time.Minute), now)
+
+			if v, ok := c.Get("jti", now.Add(30*time.Second)); !ok || !v {
+				t.Fatalf("\t%s\tEntry must be returned before expiration", failed)
+			}
+
+			if _, ok := c.Get("jti", now.Add(time.Minute)); ok {
+				t.Fatalf("\t%s\tEntry must not be returned after expiration", failed)
+			}
+			t.Logf("\t%s\tEntry must be available until expiration only", success)
+		}
+
+		t.Logf("\tTest 2:\tWhen entry is added to full cache")
+		{
+			c := New[string, bool](2)
+			c.Set("expired", true, now.Add(time.Second), now)
+			c.Set("alive", true, now.Add(time.Hour), now)
+			c.Set("new", true, now.Add(time.Hour), now.Add(time.Minute))
+
+			if c.Len() != 2 {
+				t.Fatalf("\t%s\tCache size must not exceed 2, but got %d", failed, c.Len())
+			}
+
+			if _, ok := c.Get("alive", now.Add(time.Minute)); !ok {
+				t.Fatalf("\t%s\tExpired entry must be evicted first", failed)
+			}
+
+			if _, ok := c.Get("new", now.Add(time.Minute)); !ok {
+				t.Fatalf("\t%s\tNew entry must be stored", failed)
+			}
+			t.Logf("\t%s\tExpired entries must be evicted first", success)
+		}
+
+		t.Logf("\tTest 3:\tWhen cache size is zero")
+		{
+			c := New[string, bool](0)
+			c.Set("jti", true, now.Add(time.Minute), now)
+
+			if _, ok := c.Get("jti", now); ok {
+				t.Fatalf("\t%s\tNothing must be cached", failed)
+			}
+			t.Logf("\t%s\tNothing must be cached", success)
+		}
+	}
+}
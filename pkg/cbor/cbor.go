@@ -0,0 +1,328 @@
+// Package cbor implements subset of CBOR (RFC 8949) used by WebAuthn: definite length items only,
+// integers are decoded to int64, maps to map[any]any with int64 or string keys
+package cbor
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+const maxDepth = 16
+
+const (
+	majorUint = iota
+	majorNegInt
+	majorBytes
+	majorText
+	majorArray
+	majorMap
+	majorTag
+	majorSimple
+)
+
+var ErrUnexpectedEnd = errors.New("cbor: unexpected end of data")
+
+// Decode decodes the first item of data and returns number of consumed bytes, so data may have trailing bytes
+func Decode(data []byte) (any, int, error) {
+	d := decoder{data: data}
+	v, err := d.decode(0)
+	if err != nil {
+		return nil, 0, err
+	}
+	return v, d.pos, nil
+}
+
+// Unmarshal decodes single item, trailing bytes are treated as error
+func Unmarshal(data []byte) (any, error) {
+	v, n, err := Decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if n != len(data) {
+		return nil, errors.New("cbor: trailing data after item")
+	}
+	return v, nil
+}
+
+type decoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *decoder) decode(depth int) (any, error) {
+	if depth > maxDepth {
+		return nil, errors.New("cbor: maximum nesting depth exceeded")
+	}
+
+	if d.pos >= len(d.data) {
+		return nil, ErrUnexpectedEnd
+	}
+
+	initial := d.data[d.pos]
+	d.pos++
+	major, info := initial>>5, initial&0x1f
+
+	if major == majorSimple {
+		return d.decodeSimple(info)
+	}
+
+	arg, err := d.argument(info)
+	if err != nil {
+		return nil, err
+	}
+
+	switch major {
+	case majorUint:
+		if arg > math.MaxInt64 {
+			return nil, errors.New("cbor: integer overflows int64")
+		}
+		return int64(arg), nil
+	case majorNegInt:
+		if arg > math.MaxInt64 {
+			return nil, errors.New("cbor: integer overflows int64")
+		}
+		return -1 - int64(arg), nil
+	case majorBytes, majorText:
+		raw, err := d.take(arg)
+		if err != nil {
+			return nil, err
+		}
+
+		if major == majorText {
+			return string(raw), nil
+		}
+		return append([]byte(nil), raw...), nil
+	case majorArray:
+		// every item takes at least one byte, so length can't exceed remaining data
+		if arg > uint64(len(d.data)-d.pos) {
+			return nil, ErrUnexpectedEnd
+		}
+
+		arr := make([]any, 0, arg)
+		for i := uint64(0); i < arg; i++ {
+			item, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, item)
+		}
+		return arr, nil
+	case majorMap:
+		if arg > uint64(len(d.data)-d.pos)/2 {
+			return nil, ErrUnexpectedEnd
+		}
+
+		m := make(map[any]any, arg)
+		for i := uint64(0); i < arg; i++ {
+			key, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+
+			switch key.(type) {
+			case int64, string:
+			default:
+				return nil, errors.Errorf("cbor: unsupported map key type %T", key)
+			}
+
+			if _, exists := m[key]; exists {
+				return nil, errors.Errorf("cbor: duplicate map key %v", key)
+			}
+
+			value, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = value
+		}
+		return m, nil
+	default:
+		// tags carry no meaning for WebAuthn structures, so tagged item is returned as is
+		return d.decode(depth + 1)
+	}
+}
+
+func (d *decoder) decodeSimple(info byte) (any, error) {
+	switch info {
+	case 20:
+		return false, nil
+	case 21:
+		return true, nil
+	case 22, 23:
+		return nil, nil
+	case 26:
+		raw, err := d.take(4)
+		if err != nil {
+			return nil, err
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(raw))), nil
+	case 27:
+		raw, err := d.take(8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(raw)), nil
+	default:
+		return nil, errors.Errorf("cbor: unsupported simple value %d", info)
+	}
+}
+
+func (d *decoder) argument(info byte) (uint64, error) {
+	switch {
+	case info < 24:
+		return uint64(info), nil
+	case info == 24:
+		raw, err := d.take(1)
+		if err != nil {
+			return 0, err
+		}
+		return uint64(raw[0]), nil
+	case info == 25:
+		raw, err := d.take(2)
+		if err != nil {
+			return 0, err
+		}
+		return uint64(binary.BigEndian.Uint16(raw)), nil
+	case info == 26:
+		raw, err := d.take(4)
+		if err != nil {
+			return 0, err
+		}
+		return uint64(binary.BigEndian.Uint32(raw)), nil
+	case info == 27:
+		raw, err := d.take(8)
+		if err != nil {
+			return 0, err
+		}
+		return binary.BigEndian.Uint64(raw), nil
+	default:
+		return 0, errors.New("cbor: indefinite length items are not supported")
+	}
+}
+
+func (d *decoder) take(n uint64) ([]byte, error) {
+	if n > uint64(len(d.data)-d.pos) {
+		return nil, ErrUnexpectedEnd
+	}
+
+	raw := d.data[d.pos : d.pos+int(n)]
+	d.pos += int(n)
+	return raw, nil
+}
+
+// Marshal encodes value in CTAP2 canonical form, map keys are sorted by their encoding
+func Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := encode(&buf, v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func encode(buf *bytes.Buffer, v any) error {
+	switch value := v.(type) {
+	case nil:
+		buf.WriteByte(0xf6)
+	case bool:
+		if value {
+			buf.WriteByte(0xf5)
+		} else {
+			buf.WriteByte(0xf4)
+		}
+	case int:
+		encodeInt(buf, int64(value))
+	case int64:
+		encodeInt(buf, value)
+	case uint64:
+		writeHead(buf, majorUint, value)
+	case []byte:
+		writeHead(buf, majorBytes, uint64(len(value)))
+		buf.Write(value)
+	case string:
+		writeHead(buf, majorText, uint64(len(value)))
+		buf.WriteString(value)
+	case []any:
+		writeHead(buf, majorArray, uint64(len(value)))
+		for _, item := range value {
+			if err := encode(buf, item); err != nil {
+				return err
+			}
+		}
+	case map[any]any:
+		return encodeMap(buf, value)
+	default:
+		return errors.Errorf("cbor: unsupported type %T", v)
+	}
+	return nil
+}
+
+func encodeMap(buf *bytes.Buffer, m map[any]any) error {
+	type entry struct {
+		key   []byte
+		value any
+	}
+
+	entries := make([]entry, 0, len(m))
+	for k, v := range m {
+		key, err := Marshal(k)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, entry{key: key, value: v})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if len(entries[i].key) != len(entries[j].key) {
+			return len(entries[i].key) < len(entries[j].key)
+		}
+		return bytes.Compare(entries[i].key, entries[j].key) < 0
+	})
+
+	writeHead(buf, majorMap, uint64(len(entries)))
+	for _, e := range entries {
+		buf.Write(e.key)
+		if err := encode(buf, e.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func encodeInt(buf *bytes.Buffer, n int64) {
+	if n >= 0 {
+		writeHead(buf, majorUint, uint64(n))
+		return
+	}
+	writeHead(buf, majorNegInt, uint64(-1-n))
+}
+
+func writeHead(buf *bytes.Buffer, major byte, arg uint64) {
+	head := major << 5
+	switch {
+	case arg < 24:
+		buf.WriteByte(head | byte(arg))
+	case arg <= math.MaxUint8:
+		buf.WriteByte(head | 24)
+		buf.WriteByte(byte(arg))
+	case arg <= math.MaxUint16:
+		raw := make([]byte, 2)
+		binary.BigEndian.PutUint16(raw, uint16(arg))
+		buf.WriteByte(head | 25)
+		buf.Write(raw)
+	case arg <= math.MaxUint32:
+		raw := make([]byte, 4)
+		binary.BigEndian.PutUint32(raw, uint32(arg))
+		buf.WriteByte(head | 26)
+		buf.Write(raw)
+	default:
+		raw := make([]byte, 8)
+		binary.BigEndian.PutUint64(raw, arg)
+		buf.WriteByte(head | 27)
+		buf.Write(raw)
+	}
+}
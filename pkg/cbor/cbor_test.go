@@ -0,0 +1,87 @@
+package cbor
+
+import (
+	"encoding/hex"
+	"reflect"
+	"testing"
+)
+
+const (
+	success = "\u2713"
+	failed  = "\u2717"
+)
+
+// RFC 8949 appendix A examples
+var rfcVectors = []struct {
+	value   any
+	encoded string
+}{
+	{int64(0), "00"},
+	{int64(23), "17"},
+	{int64(24), "1818"},
+	{int64(1000), "1903e8"},
+	{int64(1000000), "1a000f4240"},
+	{int64(-1), "20"},
+	{int64(-1000), "3903e7"},
+	{[]byte{1, 2, 3, 4}, "4401020304"},
+	{"IETF", "6449455446"},
+	{[]any{int64(1), []any{int64(2), int64(3)}, []any{int64(4), int64(5)}}, "8301820203820405"},
+	{map[any]any{int64(1): int64(2), int64(3): int64(4)}, "a201020304"},
+	{map[any]any{"a": int64(1), "b": []any{int64(2), int64(3)}}, "a26161016162820203"},
+	{true, "f5"},
+	{nil, "f6"},
+}
+
+func TestCbor(t *testing.T) {
+	t.Log("Given the need to test CBOR encoding and decoding")
+	{
+		t.Logf("\tTest 1:\tWhen RFC 8949 examples are encoded and decoded")
+		{
+			for _, v := range rfcVectors {
+				encoded, err := Marshal(v.value)
+				if err != nil {
+					t.Fatalf("\t%s\tUnexpected error occurred: %v", failed, err)
+				}
+
+				if hex.EncodeToString(encoded) != v.encoded {
+					t.Fatalf("\t%s\t%v must be encoded to %s, but got %x", failed, v.value, v.encoded, encoded)
+				}
+
+				decoded, err := Unmarshal(encoded)
+				if err != nil {
+					t.Fatalf("\t%s\tUnexpected error occurred: %v", failed, err)
+				}
+
+				if !reflect.DeepEqual(decoded, v.value) {
+					t.Fatalf("\t%s\t%s must be decoded to %v, but got %v", failed, v.encoded, v.value, decoded)
+				}
+			}
+			t.Logf("\t%s\tEncoding must match RFC 8949 examples", success)
+		}
+
+		t.Logf("\tTest 2:\tWhen item is followed by trailing data")
+		{
+			data, _ := hex.DecodeString("a2010203040506")
+			_, n, err := Decode(data)
+			if err != nil || n != 5 {
+				t.Fatalf("\t%s\tFirst item of 5 bytes must be decoded, but got n=%d, err=%v", failed, n, err)
+			}
+
+			if _, err := Unmarshal(data); err == nil {
+				t.Fatalf("\t%s\tTrailing data must be rejected on unmarshal", failed)
+			}
+			t.Logf("\t%s\tConsumed length must be reported", success)
+		}
+
+		t.Logf("\tTest 3:\tWhen malformed data is decoded")
+		{
+			for _, malformed := range []string{"5f44aabbccddff", "44aabb", "9bffffffffffffffff", "a20102"} {
+				data, _ := hex.DecodeString(malformed)
+				if _, err := Unmarshal(data); err == nil {
+					t.Fatalf("\t%s\t%s must be rejected", failed, malformed)
+				}
+			}
+			t.Logf("\t%s\tIndefinite, truncated and oversized items must be rejected", success)
+		}
+	}
+}
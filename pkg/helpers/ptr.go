@@ -13,10 +13,10 @@ func EqualValues[V constraints.Ordered](a, b *V) bool {
 }
 
 func CopyValue[V constraints.Ordered](from *V) *V {
-	var copy *V
 	if from == nil {
 		return nil
 	}
+	copy := new(V)
 	*copy = *from
 	return copy
 }
@@ -0,0 +1,106 @@
+package mail
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const asyncSendTimeout = time.Minute
+
+var MailerClosedErr = errors.New("mailer is closed")
+
+// AsyncMailer delivers messages in background, so caller neither waits for delivery nor reveals by response time
+// whether message was sent at all. Messages are queued for fixed number of workers, message which doesn't fit
+// into queue is dropped. Delivery failures are only logged.
+type AsyncMailer struct {
+	mailer  Mailer
+	logger  *log.Logger
+	queue   chan Message
+	mu      sync.RWMutex
+	closed  bool
+	workers sync.WaitGroup
+}
+
+func NewAsyncMailer(mailer Mailer, workers int, queueSize int, logger *log.Logger) *AsyncMailer {
+	if workers < 1 {
+		workers = 1
+	}
+
+	m := &AsyncMailer{
+		mailer: mailer,
+		logger: logger,
+		queue:  make(chan Message, queueSize),
+	}
+
+	m.workers.Add(workers)
+	for i := 0; i < workers; i++ {
+		go m.work()
+	}
+	return m
+}
+
+// Send validates message and queues it for delivery, context of caller isn't used for delivery,
+// since it is usually canceled once request is served
+func (m *AsyncMailer) Send(_ context.Context, msg Message) error {
+	if err := msg.validate(); err != nil {
+		return err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.closed {
+		return MailerClosedErr
+	}
+
+	select {
+	case m.queue <- msg:
+	default:
+		m.logger.Printf("mail queue is full, mail to %d recipient(s) with subject %q is dropped", len(msg.To), msg.Subject)
+	}
+	return nil
+}
+
+// Close stops accepting messages and waits until queued ones are delivered or context is done
+func (m *AsyncMailer) Close(ctx context.Context) error {
+	m.mu.Lock()
+	if !m.closed {
+		m.closed = true
+		close(m.queue)
+	}
+	m.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		m.workers.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return errors.Wrapf(ctx.Err(), "mail queue isn't drained, %d message(s) are lost", len(m.queue))
+	}
+}
+
+func (m *AsyncMailer) work() {
+	defer m.workers.Done()
+
+	for msg := range m.queue {
+		m.deliver(msg)
+	}
+}
+
+func (m *AsyncMailer) deliver(msg Message) {
+	ctx, cancel := context.WithTimeout(context.Background(), asyncSendTimeout)
+	defer cancel()
+
+	if err := m.mailer.Send(ctx, msg); err != nil {
+		m.logger.Printf("failed to deliver mail to %d recipient(s) with subject %q: %v", len(msg.To), msg.Subject, err)
+	}
+}
@@ -0,0 +1,86 @@
+package mail
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// FileMailer stores every message as .eml file in directory instead of delivering it, it is intended for development
+type FileMailer struct {
+	from string
+	dir  string
+}
+
+func NewFileMailer(from string, dir string) (*FileMailer, error) {
+	if _, err := mail.ParseAddress(from); err != nil {
+		return nil, errors.Wrap(err, "invalid sender address")
+	}
+
+	if dir == "" {
+		return nil, errors.New("mail directory must be provided")
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, errors.Wrap(err, "failed to create mail directory")
+	}
+
+	return &FileMailer{
+		from: from,
+		dir:  dir,
+	}, nil
+}
+
+func (m *FileMailer) Send(_ context.Context, msg Message) error {
+	if err := msg.validate(); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	data, err := encode(m.from, msg, now)
+	if err != nil {
+		return err
+	}
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return errors.Wrap(err, "failed to generate message file name")
+	}
+
+	name := strconv.FormatInt(now.UnixNano(), 10) + "-" + hex.EncodeToString(suffix) + ".eml"
+	if err := os.WriteFile(filepath.Join(m.dir, name), data, 0o600); err != nil {
+		return errors.Wrap(err, "failed to write message file")
+	}
+	return nil
+}
+
+// LogMailer writes messages to log, message bodies may hold secrets, so it must never be used in production
+type LogMailer struct {
+	from   string
+	logger *log.Logger
+}
+
+func NewLogMailer(from string, logger *log.Logger) *LogMailer {
+	return &LogMailer{
+		from:   from,
+		logger: logger,
+	}
+}
+
+func (m *LogMailer) Send(_ context.Context, msg Message) error {
+	if err := msg.validate(); err != nil {
+		return err
+	}
+
+	m.logger.Printf("mail from %s to %s, subject %q:\n%s", m.from, strings.Join(msg.To, ", "), msg.Subject, msg.Body)
+	return nil
+}
@@ -0,0 +1,88 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"mime"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Mailer delivers messages, sender address is configured per mailer
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Message is plain text email
+type Message struct {
+	To      []string
+	Subject string
+	Body    string
+}
+
+func (msg Message) validate() error {
+	if len(msg.To) == 0 {
+		return errors.New("message must have at least one recipient")
+	}
+
+	for _, to := range msg.To {
+		if _, err := mail.ParseAddress(to); err != nil {
+			return errors.Wrapf(err, "invalid recipient address %q", to)
+		}
+	}
+
+	// line breaks in headers would allow to inject arbitrary headers
+	if strings.ContainsAny(msg.Subject, "\r\n") {
+		return errors.New("message subject can't contain line breaks")
+	}
+	return nil
+}
+
+// encode builds RFC 5322 message, body is sent as quoted-printable UTF-8 text
+func encode(from string, msg Message, now time.Time) ([]byte, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, errors.Wrap(err, "failed to generate message id")
+	}
+
+	domain := "localhost"
+	if at := strings.LastIndex(from, "@"); at != -1 {
+		domain = strings.TrimSuffix(from[at+1:], ">")
+	}
+
+	var buf bytes.Buffer
+	writeHeader(&buf, "From", from)
+	writeHeader(&buf, "To", strings.Join(msg.To, ", "))
+	writeHeader(&buf, "Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	writeHeader(&buf, "Date", now.Format(time.RFC1123Z))
+	writeHeader(&buf, "Message-Id", "<"+hex.EncodeToString(id)+"@"+domain+">")
+	writeHeader(&buf, "MIME-Version", "1.0")
+	writeHeader(&buf, "Content-Type", `text/plain; charset="utf-8"`)
+	writeHeader(&buf, "Content-Transfer-Encoding", "quoted-printable")
+	buf.WriteString("\r\n")
+
+	qp := quotedprintable.NewWriter(&buf)
+	if _, err := qp.Write([]byte(strings.ReplaceAll(strings.ReplaceAll(msg.Body, "\r\n", "\n"), "\n", "\r\n"))); err != nil {
+		return nil, errors.Wrap(err, "failed to encode message body")
+	}
+
+	if err := qp.Close(); err != nil {
+		return nil, errors.Wrap(err, "failed to encode message body")
+	}
+	buf.WriteString("\r\n")
+
+	return buf.Bytes(), nil
+}
+
+func writeHeader(buf *bytes.Buffer, name string, value string) {
+	buf.WriteString(name)
+	buf.WriteString(": ")
+	buf.WriteString(value)
+	buf.WriteString("\r\n")
+}
@@ -0,0 +1,263 @@
+package mail
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	success = "\u2713"
+	failed  = "\u2717"
+)
+
+// smtpStandIn accepts single session and records envelope and message data
+type smtpStandIn struct {
+	listener net.Listener
+	from     string
+	to       []string
+	data     string
+	done     chan struct{}
+}
+
+func startSmtpStandIn(t *testing.T) *smtpStandIn {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("\t%s\tShould be able to start smtp stand-in: %v", failed, err)
+	}
+
+	s := &smtpStandIn{listener: l, done: make(chan struct{})}
+	go s.serve()
+	return s
+}
+
+func (s *smtpStandIn) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *smtpStandIn) serve() {
+	defer close(s.done)
+
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	tp := textproto.NewConn(conn)
+	tp.PrintfLine("220 localhost ESMTP stand-in")
+
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+
+		cmd := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			tp.PrintfLine("250 localhost")
+		case strings.HasPrefix(cmd, "MAIL FROM:"):
+			s.from = strings.Trim(line[len("MAIL FROM:"):], "<>")
+			tp.PrintfLine("250 OK")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			s.to = append(s.to, strings.Trim(line[len("RCPT TO:"):], "<>"))
+			tp.PrintfLine("250 OK")
+		case cmd == "DATA":
+			tp.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+			data, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			s.data = string(data)
+			tp.PrintfLine("250 OK")
+		case cmd == "QUIT":
+			tp.PrintfLine("221 Bye")
+			return
+		default:
+			tp.PrintfLine("502 Command not implemented")
+		}
+	}
+}
+
+func TestSmtpMailer(t *testing.T) {
+	t.Log("Given the need to test message delivery over smtp")
+	{
+		t.Logf("\tTest 1:\tWhen message is sent to local smtp server")
+		{
+			server := startSmtpStandIn(t)
+			defer server.listener.Close()
+
+			mailer, err := NewSmtpMailer(SmtpConfig{Host: "127.0.0.1", Port: server.port(), From: "Auth Server <noreply@example.com>"})
+			if err != nil {
+				t.Fatalf("\t%s\tShould be able to build smtp mailer: %v", failed, err)
+			}
+
+			msg := Message{To: []string{"john@example.com"}, Subject: "Password reset", Body: "Your token is abc\nIt expires soon"}
+			if err := mailer.Send(context.Background(), msg); err != nil {
+				t.Fatalf("\t%s\tShould be able to send message: %v", failed, err)
+			}
+			<-server.done
+
+			if server.from != "noreply@example.com" || len(server.to) != 1 || server.to[0] != "john@example.com" {
+				t.Fatalf("\t%s\tEnvelope must hold bare addresses, got from %q to %v", failed, server.from, server.to)
+			}
+
+			if !strings.Contains(server.data, "Subject: Password reset\n") || !strings.Contains(server.data, "Your token is abc\nIt expires soon") {
+				t.Fatalf("\t%s\tMessage must hold subject and body, got %q", failed, server.data)
+			}
+			t.Logf("\t%s\tMessage must be delivered to smtp server", success)
+		}
+
+		t.Logf("\tTest 2:\tWhen tls is required, but server doesn't offer it")
+		{
+			server := startSmtpStandIn(t)
+			defer server.listener.Close()
+
+			mailer, _ := NewSmtpMailer(SmtpConfig{Host: "127.0.0.1", Port: server.port(), From: "noreply@example.com", RequireTls: true})
+			if err := mailer.Send(context.Background(), Message{To: []string{"john@example.com"}, Subject: "Hi", Body: "Hi"}); err == nil {
+				t.Fatalf("\t%s\tMessage must not be sent without tls", failed)
+			}
+			t.Logf("\t%s\tMessage must not be sent over plain connection", success)
+		}
+	}
+}
+
+func TestFileMailer(t *testing.T) {
+	t.Log("Given the need to test message storing in files")
+	{
+		t.Logf("\tTest 1:\tWhen message is sent")
+		{
+			dir := t.TempDir()
+			mailer, err := NewFileMailer("noreply@example.com", dir)
+			if err != nil {
+				t.Fatalf("\t%s\tShould be able to build file mailer: %v", failed, err)
+			}
+
+			if err := mailer.Send(context.Background(), Message{To: []string{"john@example.com"}, Subject: "Hi", Body: "Hello"}); err != nil {
+				t.Fatalf("\t%s\tShould be able to send message: %v", failed, err)
+			}
+
+			files, _ := filepath.Glob(filepath.Join(dir, "*.eml"))
+			if len(files) != 1 {
+				t.Fatalf("\t%s\tSingle message file must be written, got %d", failed, len(files))
+			}
+
+			data, _ := os.ReadFile(files[0])
+			if !strings.Contains(string(data), "To: john@example.com\r\n") {
+				t.Fatalf("\t%s\tMessage file must hold headers, got %q", failed, string(data))
+			}
+			t.Logf("\t%s\tMessage must be stored as file", success)
+		}
+
+		t.Logf("\tTest 2:\tWhen subject contains line breaks")
+		{
+			mailer, _ := NewFileMailer("noreply@example.com", t.TempDir())
+			msg := Message{To: []string{"john@example.com"}, Subject: "Hi\r\nBcc: eve@example.com", Body: "Hello"}
+
+			if err := mailer.Send(context.Background(), msg); err == nil {
+				t.Fatalf("\t%s\tMessage with header injection must be rejected", failed)
+			}
+			t.Logf("\t%s\tMessage with header injection must be rejected", success)
+		}
+	}
+}
+
+// failingMailer reports every delivery attempt and fails it
+type failingMailer struct {
+	sent chan Message
+}
+
+func (m *failingMailer) Send(_ context.Context, msg Message) error {
+	m.sent <- msg
+	return errors.New("smtp server is unavailable")
+}
+
+// blockingMailer holds every delivery until released
+type blockingMailer struct {
+	sent    chan Message
+	release chan struct{}
+}
+
+func (m *blockingMailer) Send(_ context.Context, msg Message) error {
+	m.sent <- msg
+	<-m.release
+	return nil
+}
+
+func TestAsyncMailer(t *testing.T) {
+	t.Log("Given the need to test background message delivery")
+	{
+		t.Logf("\tTest 1:\tWhen underlying mailer fails to deliver message")
+		{
+			inner := &failingMailer{sent: make(chan Message, 1)}
+			mailer := NewAsyncMailer(inner, 1, 1, log.New(io.Discard, "", 0))
+
+			if err := mailer.Send(context.Background(), Message{To: []string{"john@example.com"}, Subject: "Hi", Body: "Hello"}); err != nil {
+				t.Fatalf("\t%s\tDelivery failure must not be reported to caller, got %v", failed, err)
+			}
+
+			select {
+			case <-inner.sent:
+			case <-time.After(time.Second):
+				t.Fatalf("\t%s\tMessage must be handed over to underlying mailer", failed)
+			}
+			t.Logf("\t%s\tMessage must be delivered in background", success)
+		}
+
+		t.Logf("\tTest 2:\tWhen message is invalid")
+		{
+			mailer := NewAsyncMailer(&failingMailer{sent: make(chan Message, 1)}, 1, 1, log.New(io.Discard, "", 0))
+			if err := mailer.Send(context.Background(), Message{Subject: "Hi", Body: "Hello"}); err == nil {
+				t.Fatalf("\t%s\tMessage without recipients must be rejected", failed)
+			}
+			t.Logf("\t%s\tInvalid message must be rejected right away", success)
+		}
+
+		t.Logf("\tTest 3:\tWhen queue is full and mailer is closed")
+		{
+			inner := &blockingMailer{sent: make(chan Message, 3), release: make(chan struct{})}
+			mailer := NewAsyncMailer(inner, 1, 1, log.New(io.Discard, "", 0))
+			msg := Message{To: []string{"john@example.com"}, Subject: "Hi", Body: "Hello"}
+
+			// first message occupies the only worker, second one waits in queue
+			if err := mailer.Send(context.Background(), msg); err != nil {
+				t.Fatalf("\t%s\tMessage must be accepted, got %v", failed, err)
+			}
+			<-inner.sent
+
+			for i := 0; i < 2; i++ {
+				if err := mailer.Send(context.Background(), msg); err != nil {
+					t.Fatalf("\t%s\tMessage which doesn't fit into queue must be dropped silently, got %v", failed, err)
+				}
+			}
+			t.Logf("\t%s\tMessage which doesn't fit into queue must be dropped silently", success)
+
+			close(inner.release)
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+
+			if err := mailer.Close(ctx); err != nil {
+				t.Fatalf("\t%s\tQueue must be drained on close, got %v", failed, err)
+			}
+
+			if delivered := len(inner.sent); delivered != 1 {
+				t.Fatalf("\t%s\tOnly queued message must be delivered on close, got %d", failed, delivered)
+			}
+			t.Logf("\t%s\tQueued message must be delivered on close", success)
+
+			if err := mailer.Send(context.Background(), msg); !errors.Is(err, MailerClosedErr) {
+				t.Fatalf("\t%s\tClosed mailer must reject message, got %v", failed, err)
+			}
+			t.Logf("\t%s\tClosed mailer must reject message", success)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const defaultSmtpTimeout = 30 * time.Second
+
+type SmtpConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	// RequireTls fails delivery if server doesn't offer STARTTLS, otherwise TLS is used whenever it is offered
+	RequireTls bool
+}
+
+type SmtpMailer struct {
+	cfg  SmtpConfig
+	from *mail.Address
+}
+
+func NewSmtpMailer(cfg SmtpConfig) (*SmtpMailer, error) {
+	if cfg.Host == "" {
+		return nil, errors.New("smtp host must be provided")
+	}
+
+	if cfg.Port <= 0 {
+		return nil, errors.New("smtp port must be positive")
+	}
+
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid sender address")
+	}
+
+	return &SmtpMailer{
+		cfg:  cfg,
+		from: from,
+	}, nil
+}
+
+func (m *SmtpMailer) Send(ctx context.Context, msg Message) error {
+	if err := msg.validate(); err != nil {
+		return err
+	}
+
+	data, err := encode(m.cfg.From, msg, time.Now())
+	if err != nil {
+		return err
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(m.cfg.Host, strconv.Itoa(m.cfg.Port)))
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to smtp server")
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(defaultSmtpTimeout)
+	}
+
+	if err := conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return errors.Wrap(err, "failed to set smtp connection deadline")
+	}
+
+	client, err := smtp.NewClient(conn, m.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return errors.Wrap(err, "failed to start smtp session")
+	}
+	defer client.Close()
+
+	if err := m.deliver(client, msg, data); err != nil {
+		return err
+	}
+
+	if err := client.Quit(); err != nil {
+		return errors.Wrap(err, "failed to finish smtp session")
+	}
+	return nil
+}
+
+func (m *SmtpMailer) deliver(client *smtp.Client, msg Message, data []byte) error {
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: m.cfg.Host, MinVersion: tls.VersionTLS12}); err != nil {
+			return errors.Wrap(err, "failed to start tls")
+		}
+	} else if m.cfg.RequireTls {
+		return errors.New("smtp server doesn't support STARTTLS")
+	}
+
+	if m.cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, m.cfg.Host)); err != nil {
+			return errors.Wrap(err, "failed to authenticate on smtp server")
+		}
+	}
+
+	if err := client.Mail(m.from.Address); err != nil {
+		return errors.Wrap(err, "smtp server rejected sender")
+	}
+
+	for _, to := range msg.To {
+		addr, _ := mail.ParseAddress(to)
+		if err := client.Rcpt(addr.Address); err != nil {
+			return errors.Wrapf(err, "smtp server rejected recipient %s", addr.Address)
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return errors.Wrap(err, "failed to start message data")
+	}
+
+	if _, err := w.Write(data); err != nil {
+		return errors.Wrap(err, "failed to write message data")
+	}
+
+	if err := w.Close(); err != nil {
+		return errors.Wrap(err, "smtp server rejected message")
+	}
+	return nil
+}
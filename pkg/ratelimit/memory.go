@@ -0,0 +1,112 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/umalmyha/authsrv/pkg/cache"
+)
+
+type windowState struct {
+	window   int64
+	current  int64
+	previous int64
+}
+
+type bucketState struct {
+	tokens float64
+	last   int64
+}
+
+// MemoryLimiter keeps counters in process memory, it suits single instance deployments and tests only
+type MemoryLimiter struct {
+	mu        sync.Mutex
+	algorithm Algorithm
+	limit     Limit
+	windows   *cache.Cache[string, windowState]
+	buckets   *cache.Cache[string, bucketState]
+}
+
+// NewMemoryLimiter builds in-memory limiter which tracks at most maxKeys keys at once
+func NewMemoryLimiter(algorithm Algorithm, limit Limit, maxKeys int) (*MemoryLimiter, error) {
+	if _, err := ParseAlgorithm(string(algorithm)); err != nil {
+		return nil, err
+	}
+
+	if err := limit.validate(); err != nil {
+		return nil, err
+	}
+
+	return &MemoryLimiter{
+		algorithm: algorithm,
+		limit:     limit,
+		windows:   cache.New[string, windowState](maxKeys),
+		buckets:   cache.New[string, bucketState](maxKeys),
+	}, nil
+}
+
+func (l *MemoryLimiter) Allow(_ context.Context, key string, now time.Time) (Result, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.algorithm == TokenBucket {
+		return l.takeToken(key, now), nil
+	}
+	return l.countRequest(key, now), nil
+}
+
+func (l *MemoryLimiter) countRequest(key string, now time.Time) Result {
+	period := l.limit.Period.Milliseconds()
+	nowMs := now.UnixMilli()
+	window := nowMs / period
+
+	state, ok := l.windows.Get(key, now)
+	if !ok {
+		state = windowState{window: window}
+	}
+
+	switch state.window {
+	case window:
+	case window - 1:
+		state = windowState{window: window, previous: state.current}
+	default:
+		state = windowState{window: window}
+	}
+
+	elapsed := nowMs - window*period
+	allowed := slidingWindowAllowed(l.limit, state.current, state.previous, elapsed)
+	res := slidingWindowResult(l.limit, state.current, state.previous, elapsed, allowed)
+
+	if allowed {
+		state.current++
+	}
+
+	// counter of current window is still needed while it is previous one
+	expiresAt := time.UnixMilli((window + 2) * period)
+	l.windows.Set(key, state, expiresAt, now)
+
+	return res
+}
+
+func (l *MemoryLimiter) takeToken(key string, now time.Time) Result {
+	nowMs := now.UnixMilli()
+
+	state, ok := l.buckets.Get(key, now)
+	if !ok {
+		state = bucketState{tokens: float64(l.limit.capacity()), last: nowMs}
+	}
+
+	tokens := refill(l.limit, state.tokens, state.last, nowMs)
+	allowed := tokens >= 1
+	if allowed {
+		tokens--
+	}
+
+	res := tokenBucketResult(l.limit, tokens, allowed)
+
+	// bucket which is full again is the same as missing one
+	l.buckets.Set(key, bucketState{tokens: tokens, last: nowMs}, now.Add(res.ResetAfter+time.Millisecond), now)
+
+	return res
+}
@@ -0,0 +1,164 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type Algorithm string
+
+const (
+	SlidingWindow Algorithm = "sliding_window"
+	TokenBucket   Algorithm = "token_bucket"
+)
+
+func ParseAlgorithm(value string) (Algorithm, error) {
+	switch a := Algorithm(value); a {
+	case SlidingWindow, TokenBucket:
+		return a, nil
+	}
+	return "", errors.Errorf("unknown rate limit algorithm %q", value)
+}
+
+// Limit allows requests per period, token bucket additionally accepts bursts up to burst requests (requests by default)
+type Limit struct {
+	Requests int
+	Period   time.Duration
+	Burst    int
+}
+
+func (l Limit) validate() error {
+	if l.Requests <= 0 {
+		return errors.New("rate limit requests must be positive")
+	}
+
+	if l.Period < time.Millisecond {
+		return errors.New("rate limit period must be at least one millisecond")
+	}
+
+	if l.Burst < 0 {
+		return errors.New("rate limit burst can't be negative")
+	}
+	return nil
+}
+
+func (l Limit) capacity() int {
+	if l.Burst > 0 {
+		return l.Burst
+	}
+	return l.Requests
+}
+
+// tokens refilled per millisecond
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / float64(l.Period.Milliseconds())
+}
+
+// Result is decision on a single request, retry after is set for rejected requests only
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	ResetAfter time.Duration
+	RetryAfter time.Duration
+}
+
+type Limiter interface {
+	Allow(ctx context.Context, key string, now time.Time) (Result, error)
+}
+
+// slidingWindowAllowed approximates requests in sliding window as weighted sum of previous and current fixed windows,
+// it is computed in integer milliseconds, so redis script and in-memory limiter always agree
+func slidingWindowAllowed(l Limit, current int64, previous int64, elapsed int64) bool {
+	period := l.Period.Milliseconds()
+	return previous*(period-elapsed)+(current+1)*period <= int64(l.Requests)*period
+}
+
+// slidingWindowResult builds result from counters observed before request was counted
+func slidingWindowResult(l Limit, current int64, previous int64, elapsed int64, allowed bool) Result {
+	period := l.Period.Milliseconds()
+	limit := int64(l.Requests)
+
+	if allowed {
+		current++
+	}
+
+	used := previous*(period-elapsed) + current*period
+	remaining := (limit*period - used) / period
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	res := Result{
+		Allowed:    allowed,
+		Limit:      l.Requests,
+		Remaining:  int(remaining),
+		ResetAfter: time.Duration(period-elapsed) * time.Millisecond,
+	}
+
+	if !allowed {
+		res.RetryAfter = time.Duration(slidingWindowRetryAfter(period, limit, current, previous, elapsed)) * time.Millisecond
+	}
+	return res
+}
+
+// slidingWindowRetryAfter is time until previous window weight decays enough for one more request,
+// if it isn't enough within current window, current window becomes previous one and decays the same way
+func slidingWindowRetryAfter(period int64, limit int64, current int64, previous int64, elapsed int64) int64 {
+	left := period - elapsed
+
+	if previous > 0 {
+		excess := previous*left + (current+1)*period - limit*period
+		if wait := ceilDiv(excess, previous); wait <= left {
+			return wait
+		}
+	}
+
+	if current > 0 && current+1 > limit {
+		return left + ceilDiv((current+1-limit)*period, current)
+	}
+	return left
+}
+
+// tokenBucketResult builds result from tokens left after request was handled
+func tokenBucketResult(l Limit, tokens float64, allowed bool) Result {
+	rate := l.rate()
+	capacity := l.capacity()
+
+	res := Result{
+		Allowed:    allowed,
+		Limit:      capacity,
+		Remaining:  int(math.Floor(tokens)),
+		ResetAfter: millis((float64(capacity) - tokens) / rate),
+	}
+
+	if !allowed {
+		res.RetryAfter = millis((1 - tokens) / rate)
+	}
+	return res
+}
+
+// refill adds tokens accumulated since last update, bucket never holds more than its capacity
+func refill(l Limit, tokens float64, last int64, now int64) float64 {
+	if now > last {
+		tokens += float64(now-last) * l.rate()
+	}
+	return math.Min(float64(l.capacity()), tokens)
+}
+
+func ceilDiv(a int64, b int64) int64 {
+	if a <= 0 {
+		return 0
+	}
+	return (a + b - 1) / b
+}
+
+func millis(ms float64) time.Duration {
+	if ms <= 0 {
+		return 0
+	}
+	return time.Duration(math.Ceil(ms)) * time.Millisecond
+}
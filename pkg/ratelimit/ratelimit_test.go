@@ -0,0 +1,110 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+const (
+	success = "\u2713"
+	failed  = "\u2717"
+)
+
+func TestSlidingWindow(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)
+	limit := Limit{Requests: 3, Period: time.Minute}
+
+	t.Log("Given the need to test sliding window rate limiting")
+	{
+		t.Logf("\tTest 1:\tWhen requests exceed limit within window")
+		{
+			limiter, err := NewMemoryLimiter(SlidingWindow, limit, 10)
+			if err != nil {
+				t.Fatalf("\t%s\tShould be able to build limiter: %v", failed, err)
+			}
+
+			for i := 0; i < 3; i++ {
+				res, _ := limiter.Allow(ctx, "ip:10.0.0.1", start.Add(time.Duration(i)*time.Second))
+				if !res.Allowed || res.Remaining != 2-i {
+					t.Fatalf("\t%s\tRequest %d must be allowed with %d remaining, got %+v", failed, i+1, 2-i, res)
+				}
+			}
+
+			res, _ := limiter.Allow(ctx, "ip:10.0.0.1", start.Add(3*time.Second))
+			if res.Allowed || res.RetryAfter != 77*time.Second {
+				t.Fatalf("\t%s\tRequest over limit must be rejected until counted requests decay, got %+v", failed, res)
+			}
+
+			if res, _ := limiter.Allow(ctx, "ip:10.0.0.2", start.Add(3*time.Second)); !res.Allowed {
+				t.Fatalf("\t%s\tRequests with another key must be counted separately", failed)
+			}
+			t.Logf("\t%s\tRequests over limit must be rejected per key", success)
+		}
+
+		t.Logf("\tTest 2:\tWhen previous window weight decays")
+		{
+			limiter, _ := NewMemoryLimiter(SlidingWindow, limit, 10)
+			for i := 0; i < 3; i++ {
+				limiter.Allow(ctx, "key", start.Add(50*time.Second))
+			}
+
+			res, _ := limiter.Allow(ctx, "key", start.Add(70*time.Second))
+			if res.Allowed || res.RetryAfter != 10*time.Second {
+				t.Fatalf("\t%s\tRequest must be rejected while previous window weighs too much, got %+v", failed, res)
+			}
+
+			if res, _ := limiter.Allow(ctx, "key", start.Add(80*time.Second)); !res.Allowed {
+				t.Fatalf("\t%s\tRequest must be allowed once previous window weight decays, got %+v", failed, res)
+			}
+			t.Logf("\t%s\tRetry after must point to moment request is allowed again", success)
+		}
+	}
+}
+
+func TestTokenBucket(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)
+	limit := Limit{Requests: 1, Period: time.Second, Burst: 2}
+
+	t.Log("Given the need to test token bucket rate limiting")
+	{
+		t.Logf("\tTest 1:\tWhen burst is spent and bucket is refilled")
+		{
+			limiter, err := NewMemoryLimiter(TokenBucket, limit, 10)
+			if err != nil {
+				t.Fatalf("\t%s\tShould be able to build limiter: %v", failed, err)
+			}
+
+			for i := 0; i < 2; i++ {
+				if res, _ := limiter.Allow(ctx, "key", start); !res.Allowed || res.Limit != 2 {
+					t.Fatalf("\t%s\tBurst request %d must be allowed, got %+v", failed, i+1, res)
+				}
+			}
+
+			res, _ := limiter.Allow(ctx, "key", start.Add(500*time.Millisecond))
+			if res.Allowed || res.RetryAfter != 500*time.Millisecond {
+				t.Fatalf("\t%s\tRequest must be rejected until token is refilled, got %+v", failed, res)
+			}
+
+			res, _ = limiter.Allow(ctx, "key", start.Add(time.Second))
+			if !res.Allowed || res.Remaining != 0 || res.ResetAfter != 2*time.Second {
+				t.Fatalf("\t%s\tRequest must be allowed once token is refilled, got %+v", failed, res)
+			}
+			t.Logf("\t%s\tTokens must be refilled at configured rate", success)
+		}
+
+		t.Logf("\tTest 2:\tWhen limit is misconfigured")
+		{
+			if _, err := NewMemoryLimiter(TokenBucket, Limit{Requests: 0, Period: time.Second}, 10); err == nil {
+				t.Fatalf("\t%s\tLimiter without requests must be rejected", failed)
+			}
+
+			if _, err := NewMemoryLimiter("fixed_window", limit, 10); err == nil {
+				t.Fatalf("\t%s\tUnknown algorithm must be rejected", failed)
+			}
+			t.Logf("\t%s\tInvalid configuration must be rejected", success)
+		}
+	}
+}
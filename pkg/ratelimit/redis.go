@@ -0,0 +1,149 @@
+package ratelimit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+)
+
+const keyPrefix = "ratelimit:"
+
+// KEYS[1] - counter of current window, KEYS[2] - counter of previous window
+// ARGV[1] - limit, ARGV[2] - period ms, ARGV[3] - elapsed ms of current window
+var slidingWindowScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local period = tonumber(ARGV[2])
+local elapsed = tonumber(ARGV[3])
+
+local current = tonumber(redis.call('GET', KEYS[1]) or '0')
+local previous = tonumber(redis.call('GET', KEYS[2]) or '0')
+
+local allowed = 0
+if previous * (period - elapsed) + (current + 1) * period <= limit * period then
+	allowed = 1
+	redis.call('INCR', KEYS[1])
+	redis.call('PEXPIRE', KEYS[1], 2 * period - elapsed)
+end
+
+return {allowed, current, previous}
+`)
+
+// KEYS[1] - bucket hash
+// ARGV[1] - capacity, ARGV[2] - tokens per ms, ARGV[3] - now ms
+var tokenBucketScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'last')
+local tokens = tonumber(state[1])
+local last = tonumber(state[2])
+if tokens == nil or last == nil then
+	tokens = capacity
+	last = now
+end
+
+if now > last then
+	tokens = tokens + (now - last) * rate
+	last = now
+end
+tokens = math.min(capacity, tokens)
+
+local allowed = 0
+if tokens >= 1 then
+	allowed = 1
+	tokens = tokens - 1
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'last', tostring(last))
+redis.call('PEXPIRE', KEYS[1], math.ceil((capacity - tokens) / rate) + 1)
+
+return {tostring(allowed), tostring(tokens)}
+`)
+
+// RedisLimiter keeps counters in redis, every decision is made by single lua script, so it is atomic across instances
+type RedisLimiter struct {
+	rdb       *redis.Client
+	name      string
+	algorithm Algorithm
+	limit     Limit
+}
+
+// NewRedisLimiter builds limiter, name separates counters of limiters sharing the same redis
+func NewRedisLimiter(rdb *redis.Client, name string, algorithm Algorithm, limit Limit) (*RedisLimiter, error) {
+	if _, err := ParseAlgorithm(string(algorithm)); err != nil {
+		return nil, err
+	}
+
+	if err := limit.validate(); err != nil {
+		return nil, err
+	}
+
+	return &RedisLimiter{
+		rdb:       rdb,
+		name:      name,
+		algorithm: algorithm,
+		limit:     limit,
+	}, nil
+}
+
+func (l *RedisLimiter) Allow(ctx context.Context, key string, now time.Time) (Result, error) {
+	if l.algorithm == TokenBucket {
+		return l.takeToken(ctx, key, now)
+	}
+	return l.countRequest(ctx, key, now)
+}
+
+func (l *RedisLimiter) countRequest(ctx context.Context, key string, now time.Time) (Result, error) {
+	period := l.limit.Period.Milliseconds()
+	nowMs := now.UnixMilli()
+	window := nowMs / period
+	elapsed := nowMs - window*period
+
+	base := l.key(key)
+	keys := []string{
+		base + ":" + strconv.FormatInt(window, 10),
+		base + ":" + strconv.FormatInt(window-1, 10),
+	}
+
+	values, err := slidingWindowScript.Run(ctx, l.rdb, keys, l.limit.Requests, period, elapsed).Int64Slice()
+	if err != nil {
+		return Result{}, errors.Wrap(err, "failed to run sliding window rate limit script")
+	}
+
+	if len(values) != 3 {
+		return Result{}, errors.Errorf("unexpected sliding window rate limit script result %v", values)
+	}
+	return slidingWindowResult(l.limit, values[1], values[2], elapsed, values[0] == 1), nil
+}
+
+func (l *RedisLimiter) takeToken(ctx context.Context, key string, now time.Time) (Result, error) {
+	keys := []string{l.key(key)}
+	rate := strconv.FormatFloat(l.limit.rate(), 'g', -1, 64)
+
+	values, err := tokenBucketScript.Run(ctx, l.rdb, keys, l.limit.capacity(), rate, now.UnixMilli()).StringSlice()
+	if err != nil {
+		return Result{}, errors.Wrap(err, "failed to run token bucket rate limit script")
+	}
+
+	if len(values) != 2 {
+		return Result{}, errors.Errorf("unexpected token bucket rate limit script result %v", values)
+	}
+
+	tokens, err := strconv.ParseFloat(values[1], 64)
+	if err != nil {
+		return Result{}, errors.Wrap(err, "failed to parse tokens left in bucket")
+	}
+	return tokenBucketResult(l.limit, tokens, values[0] == "1"), nil
+}
+
+// keys are stored by hash, so addresses and usernames don't appear in redis
+func (l *RedisLimiter) key(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return keyPrefix + l.name + ":" + hex.EncodeToString(sum[:])
+}
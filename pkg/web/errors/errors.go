@@ -5,10 +5,11 @@ import (
 )
 
 var (
-	HttpNotFoundErr       = NewHttpErr(http.StatusNotFound, "Not Found")
-	HttpUnauthorizedErr   = NewHttpErr(http.StatusUnauthorized, "Unauthorized")
-	HttpForbiddenErr      = NewHttpErr(http.StatusForbidden, "Forbidden")
-	HttpInternalServerErr = NewHttpErr(http.StatusInternalServerError, "Internal Server Error")
+	HttpNotFoundErr        = NewHttpErr(http.StatusNotFound, "Not Found")
+	HttpUnauthorizedErr    = NewHttpErr(http.StatusUnauthorized, "Unauthorized")
+	HttpForbiddenErr       = NewHttpErr(http.StatusForbidden, "Forbidden")
+	HttpTooManyRequestsErr = NewHttpErr(http.StatusTooManyRequests, "Too Many Requests")
+	HttpInternalServerErr  = NewHttpErr(http.StatusInternalServerError, "Internal Server Error")
 )
 
 func HttpBadRequestErr(cType string, body any) error {
@@ -23,6 +24,18 @@ func HttpBadRequestJsonErr(body any) error {
 	return HttpBadRequestErr("application/json", body)
 }
 
+func HttpConflictErr(cType string, body any) error {
+	return &HttpErrWithBody{
+		HttpErr:     NewHttpErr(http.StatusConflict, "Conflict"),
+		contentType: cType,
+		body:        body,
+	}
+}
+
+func HttpConflictJsonErr(body any) error {
+	return HttpConflictErr("application/json", body)
+}
+
 type HttpErr struct {
 	status  int
 	message string
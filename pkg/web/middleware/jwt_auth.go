@@ -4,9 +4,11 @@ import (
 	"context"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 
+	"github.com/umalmyha/authsrv/pkg/authz"
 	webErrs "github.com/umalmyha/authsrv/pkg/web/errors"
 	"github.com/umalmyha/authsrv/pkg/web/request"
 )
@@ -14,11 +16,30 @@ import (
 type JwtValidatorFn func(string) (AuthClaimsProvider, error)
 
 type AuthClaimsProvider interface {
+	TokenId() string
+	Expiry() time.Time
 	Username() string
+	Organization() string
 	Roles() []string
 	Scopes() []string
+	IsSuperuser() bool
 }
 
+// Permission is declarative route requirement, caller must have all listed roles and scopes and satisfy
+// scope expression if it is set.
+// Tokens issued for organization are rejected unless route belongs to the same organization (OrganizationParam
+// names path parameter holding it) or route narrows data to organization of token itself (OrganizationScoped).
+type Permission struct {
+	Roles              []string
+	Scopes             []string
+	ScopeExpr          string
+	OrganizationParam  string
+	OrganizationScoped bool
+}
+
+// TokenRevokedFn reports if token with provided id was revoked before its expiration
+type TokenRevokedFn func(context.Context, string) (bool, error)
+
 type ctxClaimsKey string
 type ctxUsernameKey string
 
@@ -26,6 +47,11 @@ const CtxClaims ctxClaimsKey = "claims"
 const CtxUsername ctxUsernameKey = "username"
 
 func JwtAuthentication(validatorFn JwtValidatorFn) MiddlewareFn {
+	return JwtAuthenticationWithRevocation(validatorFn, nil)
+}
+
+// JwtAuthenticationWithRevocation additionally rejects revoked tokens, check is skipped if revokedFn is nil
+func JwtAuthenticationWithRevocation(validatorFn JwtValidatorFn, revokedFn TokenRevokedFn) MiddlewareFn {
 	return func(nextFn HttpHandlerFn) HttpHandlerFn {
 		return func(w http.ResponseWriter, r *http.Request) error {
 			h := request.GetHeader(r, "Authorization")
@@ -44,6 +70,17 @@ func JwtAuthentication(validatorFn JwtValidatorFn) MiddlewareFn {
 			}
 
 			ctx := r.Context()
+			if revokedFn != nil {
+				revoked, err := revokedFn(ctx, jwtAuth.TokenId())
+				if err != nil {
+					return errors.Wrap(err, "failed to check token revocation")
+				}
+
+				if revoked {
+					return errors.Wrap(webErrs.HttpUnauthorizedErr, "token is revoked")
+				}
+			}
+
 			ctx = context.WithValue(ctx, CtxUsername, jwtAuth.Username())
 			ctx = context.WithValue(ctx, CtxClaims, jwtAuth)
 
@@ -52,6 +89,80 @@ func JwtAuthentication(validatorFn JwtValidatorFn) MiddlewareFn {
 	}
 }
 
+// AuthClaims returns claims of authenticated caller
+func AuthClaims(r *http.Request) (AuthClaimsProvider, bool) {
+	claims, ok := r.Context().Value(CtxClaims).(AuthClaimsProvider)
+	return claims, ok
+}
+
+// AuthUsername returns username of authenticated caller, empty string is returned if request isn't authenticated
+func AuthUsername(r *http.Request) string {
+	username, _ := r.Context().Value(CtxUsername).(string)
+	return username
+}
+
+// AuthOrganization returns organization session of authenticated caller is issued for,
+// empty string is returned for global sessions
+func AuthOrganization(r *http.Request) string {
+	claims, ok := AuthClaims(r)
+	if !ok {
+		return ""
+	}
+	return claims.Organization()
+}
+
+// Authorize rejects callers missing any privilege of permission, superuser is authorized regardless of roles and scopes,
+// but never outside of organization its token is issued for
+func Authorize(perm Permission) MiddlewareFn {
+	var expr authz.Requirement
+	if perm.ScopeExpr != "" {
+		expr = authz.MustCompile(perm.ScopeExpr)
+	}
+
+	return func(nextFn HttpHandlerFn) HttpHandlerFn {
+		return func(w http.ResponseWriter, r *http.Request) error {
+			claims, ok := AuthClaims(r)
+			if !ok {
+				return errors.Wrap(webErrs.HttpInternalServerErr, "claims are missing in context, is jwt authentication middleware was applied?")
+			}
+
+			if err := authorizeOrganization(r, perm, claims.Organization()); err != nil {
+				return err
+			}
+
+			if !claims.IsSuperuser() {
+				if m := findMissingPrivileges(perm.Roles, claims.Roles()); len(m) > 0 {
+					return errors.Wrapf(webErrs.HttpForbiddenErr, "authorization failed, missing roles %v", m)
+				}
+
+				if m := authz.Missing(perm.Scopes, claims.Scopes()); len(m) > 0 {
+					return errors.Wrapf(webErrs.HttpForbiddenErr, "authorization failed, missing scopes %v", m)
+				}
+
+				if !expr.Satisfied(claims.Scopes()) {
+					return errors.Wrapf(webErrs.HttpForbiddenErr, "authorization failed, scopes don't satisfy %s", expr)
+				}
+			}
+			return nextFn(w, r)
+		}
+	}
+}
+
+func authorizeOrganization(r *http.Request, perm Permission, org string) error {
+	if org == "" || perm.OrganizationScoped {
+		return nil
+	}
+
+	if perm.OrganizationParam == "" {
+		return errors.Wrapf(webErrs.HttpForbiddenErr, "authorization failed, token of organization %s can't access global resources", org)
+	}
+
+	if target := request.PathParam(r, perm.OrganizationParam); target != org {
+		return errors.Wrapf(webErrs.HttpForbiddenErr, "authorization failed, token of organization %s can't access organization %s", org, target)
+	}
+	return nil
+}
+
 func HasRoles(roles ...string) MiddlewareFn {
 	return func(nextFn HttpHandlerFn) HttpHandlerFn {
 		return func(w http.ResponseWriter, r *http.Request) error {
@@ -72,6 +183,7 @@ func HasRoles(roles ...string) MiddlewareFn {
 	}
 }
 
+// HasScopes requires all scopes, wildcard scopes of caller are honored
 func HasScopes(scopes ...string) MiddlewareFn {
 	return func(nextFn HttpHandlerFn) HttpHandlerFn {
 		return func(w http.ResponseWriter, r *http.Request) error {
@@ -82,7 +194,7 @@ func HasScopes(scopes ...string) MiddlewareFn {
 					return errors.Wrap(webErrs.HttpForbiddenErr, "claims are missing in context, is jwt authentication middleware were applied?")
 				}
 
-				m := findMissingPrivileges(scopes, claims.Scopes())
+				m := authz.Missing(scopes, claims.Scopes())
 				if len(m) > 0 {
 					return errors.Wrapf(webErrs.HttpForbiddenErr, "authorization failed, missing scopes %v", m)
 				}
@@ -92,6 +204,28 @@ func HasScopes(scopes ...string) MiddlewareFn {
 	}
 }
 
+// HasAnyScope requires at least one of scopes, wildcard scopes of caller are honored
+func HasAnyScope(scopes ...string) MiddlewareFn {
+	return HasScopeExpr(authz.AnyOf(scopes...))
+}
+
+// HasScopeExpr requires caller scopes to satisfy compiled expression, see authz.Compile for syntax
+func HasScopeExpr(req authz.Requirement) MiddlewareFn {
+	return func(nextFn HttpHandlerFn) HttpHandlerFn {
+		return func(w http.ResponseWriter, r *http.Request) error {
+			claims, ok := AuthClaims(r)
+			if !ok {
+				return errors.Wrap(webErrs.HttpForbiddenErr, "claims are missing in context, is jwt authentication middleware were applied?")
+			}
+
+			if !req.Satisfied(claims.Scopes()) {
+				return errors.Wrapf(webErrs.HttpForbiddenErr, "authorization failed, scopes don't satisfy %s", req)
+			}
+			return nextFn(w, r)
+		}
+	}
+}
+
 func findMissingPrivileges(required []string, actual []string) []string {
 	m := make([]string, 0)
 	for _, r := range required {
@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/umalmyha/authsrv/pkg/ratelimit"
+	webErrs "github.com/umalmyha/authsrv/pkg/web/errors"
+	"github.com/umalmyha/authsrv/pkg/web/request"
+	"github.com/umalmyha/authsrv/pkg/web/response"
+)
+
+// RateLimitKeyFn returns key request is counted by, requests with empty key aren't limited
+type RateLimitKeyFn func(*http.Request) string
+
+func RateLimitByIp(r *http.Request) string {
+	return "ip:" + request.ClientIp(r)
+}
+
+// RateLimitByUsername counts requests by authenticated username, anonymous requests are counted by ip
+func RateLimitByUsername(r *http.Request) string {
+	if username := AuthUsername(r); username != "" {
+		return "user:" + username
+	}
+	return RateLimitByIp(r)
+}
+
+// RateLimitPerRoute counts requests of route group separately, so traffic of one group doesn't exhaust budget of another
+func RateLimitPerRoute(route string, keyFn RateLimitKeyFn) RateLimitKeyFn {
+	return func(r *http.Request) string {
+		key := keyFn(r)
+		if key == "" {
+			return ""
+		}
+		return route + ":" + key
+	}
+}
+
+// RateLimit rejects requests exceeding limit with 429, quota is reported in RateLimit-* headers on every response
+func RateLimit(limiter ratelimit.Limiter, keyFn RateLimitKeyFn) MiddlewareFn {
+	return func(nextFn HttpHandlerFn) HttpHandlerFn {
+		return func(w http.ResponseWriter, r *http.Request) error {
+			key := keyFn(r)
+			if key == "" {
+				return nextFn(w, r)
+			}
+
+			res, err := limiter.Allow(r.Context(), key, time.Now().UTC())
+			if err != nil {
+				return errors.Wrap(err, "failed to check rate limit")
+			}
+
+			response.SetHeader(w, "RateLimit-Limit", strconv.Itoa(res.Limit))
+			response.SetHeader(w, "RateLimit-Remaining", strconv.Itoa(res.Remaining))
+			response.SetHeader(w, "RateLimit-Reset", strconv.Itoa(ceilSeconds(res.ResetAfter)))
+
+			if !res.Allowed {
+				response.SetHeader(w, "Retry-After", strconv.Itoa(ceilSeconds(res.RetryAfter)))
+				return errors.Wrap(webErrs.HttpTooManyRequestsErr, "rate limit exceeded")
+			}
+			return nextFn(w, r)
+		}
+	}
+}
+
+// header values are whole seconds, rounding up never invites client to retry too early
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -2,8 +2,10 @@ package request
 
 import (
 	"encoding/json"
+	"net"
 	"net/http"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/pkg/errors"
 )
 
@@ -19,6 +21,24 @@ func UrlParam(r *http.Request, name string) string {
 	return r.URL.Query().Get(name)
 }
 
+func PathParam(r *http.Request, name string) string {
+	return chi.URLParam(r, name)
+}
+
+// ClientIp returns address of the remote peer, proxies must be trusted on router level (e.g. via RealIP middleware)
+func ClientIp(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// FormValue returns value from query or urlencoded body, body takes precedence
+func FormValue(r *http.Request, name string) string {
+	return r.FormValue(name)
+}
+
 func JsonReqBody(r *http.Request, to interface{}) error {
 	return json.NewDecoder(r.Body).Decode(to)
 }
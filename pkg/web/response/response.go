@@ -15,8 +15,8 @@ func RespondJson(w http.ResponseWriter, statusCode int, data any) error {
 		return err
 	}
 
-	w.WriteHeader(statusCode)
 	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
 
 	if _, err := w.Write(response); err != nil {
 		return err
@@ -26,8 +26,18 @@ func RespondJson(w http.ResponseWriter, statusCode int, data any) error {
 }
 
 func RespondTextPlain(w http.ResponseWriter, statusCode int, data []byte) error {
-	w.WriteHeader(statusCode)
 	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(statusCode)
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func RespondHtml(w http.ResponseWriter, statusCode int, data []byte) error {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(statusCode)
 	if _, err := w.Write(data); err != nil {
 		return err
 	}
@@ -43,6 +53,10 @@ func SetHeader(w http.ResponseWriter, header string, value string) {
 	w.Header().Set(header, value)
 }
 
+func Redirect(w http.ResponseWriter, r *http.Request, url string, statusCode int) {
+	http.Redirect(w, r, url, statusCode)
+}
+
 func SetCookie(w http.ResponseWriter, cookie *http.Cookie) {
 	http.SetCookie(w, cookie)
 }
@@ -24,14 +24,19 @@ func HttpFuncWithErrHandler(handlerFn HttpHandlerFn, errHandlerFn HttpErrorHandl
 }
 
 func DefaultErrorHandler(w http.ResponseWriter, r *http.Request, err error) {
-	switch e := err.(type) {
-	case *webErrs.HttpErrWithBody:
-		handleHttpErrWithBody(w, e)
-	case *webErrs.HttpErr:
-		response.RespondStatus(w, e.Status())
-	default:
-		response.RespondStatus(w, http.StatusInternalServerError)
+	var errWithBody *webErrs.HttpErrWithBody
+	if errors.As(err, &errWithBody) {
+		handleHttpErrWithBody(w, errWithBody)
+		return
+	}
+
+	var httpErr *webErrs.HttpErr
+	if errors.As(err, &httpErr) {
+		response.RespondStatus(w, httpErr.Status())
+		return
 	}
+
+	response.RespondStatus(w, http.StatusInternalServerError)
 }
 
 func handleHttpErrWithBody(w http.ResponseWriter, httpErr *webErrs.HttpErrWithBody) {